@@ -19,9 +19,11 @@ package acceptance
 import (
 	"context"
 	"flag"
+	"fmt"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"testing"
 
 	"github.com/cucumber/godog"
@@ -55,6 +57,13 @@ var noColors = flag.Bool("no-colors", false, "disable colored output")
 // specify a subset of scenarios to run filtering by given tags
 var tags = flag.String("tags", "", "select scenarios to run based on tags")
 
+// number of scenarios to run in parallel
+var concurrency = flag.Int("concurrency", runtime.NumCPU(), "number of scenarios to run in parallel")
+
+// write reports of the scenario outcomes, e.g. for CI dashboards, in addition
+// to the output of the test
+var report = flag.String("report", os.Getenv("EC_ACCEPTANCE_REPORT"), "write reports of the scenario outcomes, comma separated junit:<path> or cucumber:<path>")
+
 // initializeScenario adds all steps and registers all hooks to the
 // provided godog.ScenarioContext
 func initializeScenario(sc *godog.ScenarioContext) {
@@ -101,10 +110,47 @@ func setupContext(t *testing.T) context.Context {
 	return ctx
 }
 
+// formats returns the godog formats, the pretty format to the standard output
+// followed by the formats of the reports, each given as <format>:<path>. The
+// paths are made absolute so that they are not relative to the repository
+// root, and their directories are created.
+func formats(report string) (string, error) {
+	formats := []string{"pretty"}
+	for _, r := range strings.Split(report, ",") {
+		r = strings.TrimSpace(r)
+		if r == "" {
+			continue
+		}
+
+		format, path, ok := strings.Cut(r, ":")
+		if !ok || path == "" || (format != "junit" && format != "cucumber") {
+			return "", fmt.Errorf("invalid report %q, expecting junit:<path> or cucumber:<path>", r)
+		}
+
+		path, err := filepath.Abs(path)
+		if err != nil {
+			return "", err
+		}
+
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return "", err
+		}
+
+		formats = append(formats, format+":"+path)
+	}
+
+	return strings.Join(formats, ","), nil
+}
+
 // TestFeatures launches all acceptance test scenarios running them
-// in random order in parallel threads equal to the number of available
-// cores
+// in random order in parallel threads, by default equal to the number of
+// available cores
 func TestFeatures(t *testing.T) {
+	format, err := formats(*report)
+	if err != nil {
+		t.Fatal(err)
+	}
+
 	// change the directory to repository root, makes for easier paths
 	if err := os.Chdir(".."); err != nil {
 		t.Error(err)
@@ -118,10 +164,10 @@ func TestFeatures(t *testing.T) {
 	ctx := setupContext(t)
 
 	opts := godog.Options{
-		Format:         "pretty",
+		Format:         format,
 		Paths:          []string{featuresDir},
 		Randomize:      -1,
-		Concurrency:    runtime.NumCPU(),
+		Concurrency:    *concurrency,
 		TestingT:       t,
 		DefaultContext: ctx,
 		Tags:           *tags,
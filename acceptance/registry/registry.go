@@ -59,7 +59,11 @@ func (g registryState) Up() bool {
 	return g.HostAndPort != ""
 }
 
-// startStubRegistry creates and starts the stub image registry
+// startStubRegistry creates and starts the stub image registry. Each scenario
+// gets its own registry, listening on a random port and with its own storage,
+// so that scenarios running in parallel do not interfere with each other. The
+// address of the registry is held in the scenario's Context, see StubRegistry.
+// When restoring a persisted environment, the persisted registry is used.
 func startStubRegistry(ctx context.Context) (context.Context, error) {
 	var state *registryState
 	ctx, err := testenv.SetupState(ctx, &state)
@@ -0,0 +1,78 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package registry
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImageReferenceInStubRegistryPerScenario(t *testing.T) {
+	const scenarios = 10
+
+	var wg sync.WaitGroup
+	refs := make([]string, scenarios)
+	errs := make([]error, scenarios)
+	for i := 0; i < scenarios; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			// each scenario starts from a fresh Context
+			ctx, err := Register(context.Background(), fmt.Sprintf("localhost:%d", 5000+i))
+			if err != nil {
+				errs[i] = err
+				return
+			}
+
+			ref, err := ImageReferenceInStubRegistry(ctx, "acceptance/image:%d", i)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			refs[i] = ref.String()
+		}(i)
+	}
+	wg.Wait()
+
+	for i := 0; i < scenarios; i++ {
+		require.NoError(t, errs[i])
+		assert.Equal(t, fmt.Sprintf("localhost:%d/acceptance/image:%d", 5000+i, i), refs[i])
+	}
+}
+
+func TestStubRegistryNotRunning(t *testing.T) {
+	ctx := context.Background()
+	assert.False(t, IsRunning(ctx))
+
+	_, err := Url(ctx)
+	assert.EqualError(t, err, "no state setup, did you start the registry stub server?")
+
+	ctx, err = Register(ctx, "localhost:5000")
+	require.NoError(t, err)
+	assert.True(t, IsRunning(ctx))
+
+	_, err = Register(ctx, "localhost:5001")
+	assert.EqualError(t, err, "A registry has already been stubbed in this context")
+}
@@ -0,0 +1,180 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package check
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	hd "github.com/MakeNowJust/heredoc"
+	"github.com/sigstore/cosign/v2/pkg/cosign"
+	"github.com/spf13/cobra"
+
+	"github.com/enterprise-contract/ec-cli/internal/check"
+	"github.com/enterprise-contract/ec-cli/internal/completion"
+	"github.com/enterprise-contract/ec-cli/internal/format"
+	"github.com/enterprise-contract/ec-cli/internal/policy"
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+	validate_utils "github.com/enterprise-contract/ec-cli/internal/validate"
+)
+
+var CheckCmd *cobra.Command
+
+func init() {
+	CheckCmd = checkCmd(check.Run)
+}
+
+type runFn func(context.Context, check.Options) check.Report
+
+func checkCmd(run runFn) *cobra.Command {
+	var data = struct {
+		policyConfiguration         string
+		publicKey                   string
+		rekorURL                    string
+		ignoreRekor                 bool
+		certificateIdentity         string
+		certificateIdentityRegExp   string
+		certificateOIDCIssuer       string
+		certificateOIDCIssuerRegExp string
+		policyName                  string
+		registries                  []string
+		output                      []string
+	}{}
+
+	cmd := &cobra.Command{
+		Use:   "check",
+		Short: "Check the connectivity and the configuration ec depends on",
+		Long: hd.Doc(`
+			Check the connectivity and the configuration ec depends on, without validating any
+			image.
+
+			Probes each of the subsystems used when validating images: the policy configuration
+			loads, the policy and data sources can be fetched, the public key loads or, with
+			keyless verification, the Fulcio root certificates load, the Rekor transparency log
+			and the given registries can be reached. Subsystems not used with the given
+			configuration are skipped.
+
+			The outcome of each probe is reported in a table, and the command exits with a non-zero
+			exit code if any of the probes failed.
+		`),
+		Example: hd.Doc(`
+			Check the policy, its sources and the public key in the policy configuration:
+
+			  ec check --policy policy.yaml
+
+			Check keyless verification can be used, and that the registry can be reached:
+
+			  ec check --policy policy.yaml --certificate-identity-regexp '.*' \
+			    --certificate-oidc-issuer https://token.actions.githubusercontent.com \
+			    --registry quay.io
+
+			Write the outcome in JSON:
+
+			  ec check --policy policy.yaml --output json
+		`),
+		Args: cobra.NoArgs,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if data.policyConfiguration == "" {
+				return errors.New("the --policy flag is required")
+			}
+
+			policyConfiguration, err := validate_utils.GetPolicyConfig(cmd.Context(), data.policyConfiguration)
+			if err != nil {
+				return err
+			}
+			data.policyName = data.policyConfiguration
+			data.policyConfiguration = policyConfiguration
+
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			report := run(cmd.Context(), check.Options{
+				Policy: policy.Options{
+					EffectiveTime: policy.Now,
+					Identity: cosign.Identity{
+						Issuer:        data.certificateOIDCIssuer,
+						IssuerRegExp:  data.certificateOIDCIssuerRegExp,
+						Subject:       data.certificateIdentity,
+						SubjectRegExp: data.certificateIdentityRegExp,
+					},
+					IgnoreRekor: data.ignoreRekor,
+					PolicyRef:   data.policyConfiguration,
+					PublicKey:   data.publicKey,
+					RekorURL:    data.rekorURL,
+				},
+				PolicyName: data.policyName,
+				Registries: data.registries,
+			})
+
+			p := format.NewTargetParser(check.Text, format.Options{}, cmd.OutOrStdout(), utils.FS(cmd.Context())).WithContext(cmd.Context())
+			if err := report.WriteAll(data.output, p); err != nil {
+				return err
+			}
+
+			if failed := report.Failed(); failed > 0 {
+				return fmt.Errorf("%d of %d probes failed", failed, len(report.Probes))
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&data.policyConfiguration, "policy", "p", data.policyConfiguration, hd.Doc(`
+		Policy configuration as:
+		  * Kubernetes reference ([<namespace>/]<name>)
+		  * file (policy.yaml)
+		  * git reference (github.com/user/repo//default?ref=main),
+		  * builtin, the policy bundle embedded into ec at build time, or
+		  * inline JSON ('{sources: {...}, configuration: {...}}')")`))
+
+	cmd.Flags().StringVarP(&data.publicKey, "public-key", "k", data.publicKey, hd.Doc(`
+		path to the public key, or a reference to a public key, e.g. k8s://namespace/secret,
+		awskms://, gcpkms://, azurekms:// or hashivault://. Overrides publicKey from
+		EnterpriseContractPolicy`))
+
+	cmd.Flags().StringVarP(&data.rekorURL, "rekor-url", "r", data.rekorURL,
+		"Rekor URL. Overrides rekorURL from EnterpriseContractPolicy")
+
+	cmd.Flags().BoolVar(&data.ignoreRekor, "ignore-rekor", data.ignoreRekor,
+		"Skip probing the Rekor transparency log.")
+
+	cmd.Flags().StringVar(&data.certificateIdentity, "certificate-identity", data.certificateIdentity,
+		"URL of the certificate identity for keyless verification")
+
+	cmd.Flags().StringVar(&data.certificateIdentityRegExp, "certificate-identity-regexp", data.certificateIdentityRegExp,
+		"Regular expression for the URL of the certificate identity for keyless verification, when given with --certificate-identity matching either suffices")
+
+	cmd.Flags().StringVar(&data.certificateOIDCIssuer, "certificate-oidc-issuer", data.certificateOIDCIssuer,
+		"URL of the certificate OIDC issuer for keyless verification")
+
+	cmd.Flags().StringVar(&data.certificateOIDCIssuerRegExp, "certificate-oidc-issuer-regexp", data.certificateOIDCIssuerRegExp,
+		"Regular expresssion for the URL of the certificate OIDC issuer for keyless verification, when given with --certificate-oidc-issuer matching either suffices")
+
+	cmd.Flags().StringArrayVar(&data.registries, "registry", data.registries, hd.Doc(`
+		registry host to check can be reached, e.g. quay.io, using the credentials for the
+		registry, if any. Can be repeated.`))
+
+	cmd.Flags().StringSliceVarP(&data.output, "output", "o", data.output, hd.Doc(`
+		write the outcome in the given format, optionally to a file, as <format>[=<file>]. Can be
+		repeated. One of: `+strings.Join(check.Formats, ", ")+`. Defaults to text.`))
+
+	completion.Register(cmd, "output", completion.Formats(check.Formats))
+
+	return cmd
+}
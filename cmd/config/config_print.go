@@ -0,0 +1,396 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Define the `ec config print` command
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	hd "github.com/MakeNowJust/heredoc"
+	ecc "github.com/enterprise-contract/enterprise-contract-controller/api/v1alpha1"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"golang.org/x/exp/slices"
+	"sigs.k8s.io/yaml"
+
+	"github.com/enterprise-contract/ec-cli/internal/completion"
+	"github.com/enterprise-contract/ec-cli/internal/image"
+	"github.com/enterprise-contract/ec-cli/internal/policy"
+	"github.com/enterprise-contract/ec-cli/internal/policy/source"
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+	validate_utils "github.com/enterprise-contract/ec-cli/internal/validate"
+)
+
+// Origins of configuration values
+const (
+	originDefault = "default"
+	originFlag    = "flag"
+	originEnv     = "env"
+	originFile    = "file"
+	originURL     = "url"
+	originCluster = "cluster"
+)
+
+const redacted = "[REDACTED]"
+
+// environment lists the environment variables affecting the behavior of ec
+var environment = []string{
+	"AWS_ENDPOINT_URL_S3",
+	"COSIGN_PASSWORD",
+	"DOCKER_CONFIG",
+	"EC_CACHE",
+	"EC_CACHE_DIR",
+	"EC_DEBUG",
+	"EC_DEFAULT_DATA_SOURCES",
+	"EC_DEFAULT_POLICY_SOURCES",
+	"EC_EXPERIMENTAL",
+	"EC_FORCE_COLOR",
+	"EC_FORCE_COLOUR",
+	"EC_NO_COLOR",
+	"EC_NO_COLOUR",
+	"FORCE_COLOR",
+	"FORCE_COLOUR",
+	"GIT_CONFIG_COUNT",
+	"GIT_SSL_NO_VERIFY",
+	"KUBECONFIG",
+	"NETRC",
+	"NO_COLOR",
+	"NO_COLOUR",
+	"OTEL_EXPORTER_OTLP_ENDPOINT",
+	"OTEL_EXPORTER_OTLP_TRACES_ENDPOINT",
+	"OTEL_SDK_DISABLED",
+	"SIGSTORE_CT_LOG_PUBLIC_KEY_FILE",
+	"SIGSTORE_REKOR_PUBLIC_KEY",
+	"SIGSTORE_ROOT_FILE",
+	"TRACEPARENT",
+	"TUF_ROOT",
+	"USEGOGATHER",
+	"VAULT_ADDR",
+	"VAULT_TOKEN",
+	"XDG_CACHE_HOME",
+}
+
+// secretEnvironment lists the environment variables holding secrets, their
+// values are redacted
+var secretEnvironment = []string{
+	"COSIGN_PASSWORD",
+	"VAULT_TOKEN",
+}
+
+// setting is a configuration value along with where it originates from
+type setting struct {
+	Value  any    `json:"value"`
+	Origin string `json:"origin"`
+}
+
+// sourceConfig is a source group of the policy configuration along with where
+// it originates from
+type sourceConfig struct {
+	Origin string `json:"origin"`
+	ecc.Source
+}
+
+type policyConfig struct {
+	Origin    string         `json:"origin"`
+	Sources   []sourceConfig `json:"sources"`
+	PublicKey *setting       `json:"publicKey,omitempty"`
+	RekorURL  *setting       `json:"rekorUrl,omitempty"`
+}
+
+type effectiveConfig struct {
+	EffectiveTime setting            `json:"effectiveTime"`
+	Policy        policyConfig       `json:"policy"`
+	Settings      map[string]setting `json:"settings"`
+	Flags         map[string]setting `json:"flags"`
+	Environment   map[string]setting `json:"environment,omitempty"`
+}
+
+func configPrintCmd() *cobra.Command {
+	var (
+		policyConfiguration string
+		publicKey           string
+		rekorURL            string
+		effectiveTime       string
+		outputFormat        string
+	)
+
+	validFormats := []string{"json", "yaml"}
+
+	cmd := &cobra.Command{
+		Use:   "print",
+		Short: "Print the effective configuration",
+
+		Long: hd.Doc(`
+			Print the effective configuration.
+
+			Resolves the configuration layered from the policy configuration, the
+			environment variables and the command line flags, and prints it along with
+			the origin of each value, one of: flag, env, file, url, cluster or default.
+			The policy sources include the default sources given by the environment, the
+			default data sources are used by every source group. The settings hold the
+			values resolved from the environment, e.g. whether caching is enabled. Key
+			material and secrets are redacted.
+
+			Note that this command is not typically required to verify the Enterprise
+			Contract. It has been made available for troubleshooting and debugging purposes.
+		`),
+
+		Example: hd.Doc(`
+			Print the effective configuration for a policy configuration file:
+
+			  ec config print --policy my-policy.yaml
+
+			Print the effective configuration in YAML format, overriding the public key:
+
+			  ec config print --policy my-policy.yaml --public-key key.pub --output yaml
+		`),
+
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !slices.Contains(validFormats, outputFormat) {
+				return fmt.Errorf("invalid value for --output '%s'. accepted values: %s", outputFormat, strings.Join(validFormats, ", "))
+			}
+
+			ctx := cmd.Context()
+
+			policyContent, err := validate_utils.GetPolicyConfig(ctx, policyConfiguration)
+			if err != nil {
+				return err
+			}
+
+			p, err := policy.NewInputPolicy(ctx, policyContent, effectiveTime)
+			if err != nil {
+				return err
+			}
+
+			spec := p.Spec()
+			origin := policyOrigin(ctx, policyConfiguration)
+			config := effectiveConfig{
+				EffectiveTime: setting{Value: p.EffectiveTime().Format(time.RFC3339), Origin: flagOrigin(cmd.Flags().Lookup("effective-time"))},
+				Policy: policyConfig{
+					Origin:    origin,
+					Sources:   sources(spec.Sources, origin),
+					PublicKey: override(spec.PublicKey, publicKey, origin),
+					RekorURL:  override(spec.RekorUrl, rekorURL, origin),
+				},
+				Settings:    resolvedSettings(cmd),
+				Flags:       flags(cmd),
+				Environment: environmentSettings(),
+			}
+			if config.Policy.PublicKey != nil {
+				config.Policy.PublicKey.Value = redact(config.Policy.PublicKey.Value)
+			}
+
+			out := cmd.OutOrStdout()
+			if outputFormat == "yaml" {
+				yamlOutput, err := yaml.Marshal(config)
+				if err != nil {
+					return err
+				}
+				_, err = fmt.Fprint(out, string(yamlOutput))
+				return err
+			}
+
+			encoder := json.NewEncoder(out)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(config)
+		},
+	}
+
+	cmd.Flags().StringVarP(&policyConfiguration, "policy", "p", "", hd.Doc(`
+		Policy configuration as:
+		  * Kubernetes reference ([<namespace>/]<name>)
+		  * file (policy.yaml)
+		  * git reference (github.com/user/repo//default?ref=main), or
+		  * inline JSON ('{sources: {...}, identity: {...}}')`))
+	cmd.Flags().StringVarP(&publicKey, "public-key", "k", "", "path to the public key, or a reference to it, overriding the one in the policy configuration")
+	cmd.Flags().StringVarP(&rekorURL, "rekor-url", "r", "", "Rekor URL, overriding the one in the policy configuration")
+	cmd.Flags().StringVar(&effectiveTime, "effective-time", policy.Now, hd.Doc(`
+		Run policy checks with the provided time. Useful for testing rules with
+		effective dates in the future. The value can be "now" (default) - for
+		current time, or a RFC3339 formatted value, e.g. 2022-11-18T00:00:00Z.`))
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "json", fmt.Sprintf("output format. one of: %s", strings.Join(validFormats, ", ")))
+
+	completion.Register(cmd, "output", completion.Formats(validFormats))
+
+	return cmd
+}
+
+// policyOrigin determines where the policy configuration is read from, see
+// validate_utils.GetPolicyConfig.
+func policyOrigin(ctx context.Context, policyConfiguration string) string {
+	switch {
+	case policyConfiguration == "":
+		return originDefault
+	case source.SourceIsGit(policyConfiguration) && !source.SourceIsFile(policyConfiguration) || source.SourceIsHttp(policyConfiguration):
+		return originURL
+	case source.SourceIsFile(policyConfiguration) && utils.HasJsonOrYamlExt(policyConfiguration):
+		return originFile
+	}
+
+	if _, ok := source.LocalPolicyDirectory(ctx, policyConfiguration); ok {
+		return originFile
+	}
+
+	// Inline policy configuration, as opposed to a reference to a cluster resource
+	if strings.Contains(policyConfiguration, ":") {
+		return originFlag
+	}
+
+	return originCluster
+}
+
+// sources returns the source groups of the policy configuration, the source
+// group of the default policy sources originates from the environment.
+func sources(groups []ecc.Source, origin string) []sourceConfig {
+	configs := make([]sourceConfig, 0, len(groups))
+	for _, g := range groups {
+		o := origin
+		if g.Name == policy.DefaultSourcesName {
+			o = originEnv
+		}
+		configs = append(configs, sourceConfig{Origin: o, Source: g})
+	}
+
+	return configs
+}
+
+// resolvedSettings returns the settings resolved from the environment and the
+// global flags, as applied when validating.
+func resolvedSettings(cmd *cobra.Command) map[string]setting {
+	settings := map[string]setting{}
+
+	cache := setting{Value: true, Origin: originDefault}
+	if v, err := strconv.ParseBool(os.Getenv("EC_CACHE")); err == nil {
+		cache = setting{Value: v, Origin: originEnv}
+	}
+	settings["cache"] = cache
+
+	resultCache := setting{Value: "", Origin: originDefault}
+	if dir := os.Getenv(image.ResultCacheDirEnv); dir != "" {
+		resultCache = setting{Value: dir, Origin: originEnv}
+	}
+	settings["resultCacheDir"] = resultCache
+
+	settings["experimental"] = envSetting(utils.Experimental(), "EC_EXPERIMENTAL")
+	settings["goGather"] = envSetting(utils.UseGoGather(), "USEGOGATHER")
+
+	policies, data := source.DefaultSources()
+	defaultsOrigin := func(env string) string {
+		if f := cmd.Flags().Lookup("no-default-sources"); f != nil && f.Changed {
+			return originFlag
+		}
+		if _, ok := os.LookupEnv(env); ok {
+			return originEnv
+		}
+		return originDefault
+	}
+	if policies == nil {
+		policies = []string{}
+	}
+	if data == nil {
+		data = []string{}
+	}
+	settings["defaultPolicySources"] = setting{Value: policies, Origin: defaultsOrigin(source.DefaultPolicySourcesEnv)}
+	settings["defaultDataSources"] = setting{Value: data, Origin: defaultsOrigin(source.DefaultDataSourcesEnv)}
+
+	return settings
+}
+
+// envSetting returns the value resolved from the environment variable, the
+// origin is the environment when the variable is set.
+func envSetting(value any, env string) setting {
+	if _, ok := os.LookupEnv(env); ok {
+		return setting{Value: value, Origin: originEnv}
+	}
+
+	return setting{Value: value, Origin: originDefault}
+}
+
+// override returns the value set via the flag, or the value of the policy
+// configuration, or nil when neither is set.
+func override(configured, flag, origin string) *setting {
+	if flag != "" {
+		return &setting{Value: flag, Origin: originFlag}
+	}
+
+	if configured != "" {
+		return &setting{Value: configured, Origin: origin}
+	}
+
+	return nil
+}
+
+func flagOrigin(f *pflag.Flag) string {
+	if f != nil && f.Changed {
+		return originFlag
+	}
+
+	return originDefault
+}
+
+// flags returns the values of all the flags, including the global ones.
+func flags(cmd *cobra.Command) map[string]setting {
+	settings := map[string]setting{}
+	add := func(f *pflag.Flag) {
+		if f.Name == "help" || f.Name == "output" {
+			return
+		}
+
+		var value any = f.Value.String()
+		if f.Name == "public-key" {
+			value = redact(value)
+		}
+
+		settings[f.Name] = setting{Value: value, Origin: flagOrigin(f)}
+	}
+
+	cmd.Flags().VisitAll(add)
+	cmd.InheritedFlags().VisitAll(add)
+
+	return settings
+}
+
+func environmentSettings() map[string]setting {
+	settings := map[string]setting{}
+	for _, name := range environment {
+		if value, ok := os.LookupEnv(name); ok {
+			if slices.Contains(secretEnvironment, name) {
+				value = redacted
+			}
+			settings[name] = setting{Value: value, Origin: originEnv}
+		}
+	}
+
+	return settings
+}
+
+// redact hides key material, references to keys, e.g. file paths or
+// k8s://namespace/secret, are kept.
+func redact(value any) any {
+	if s, ok := value.(string); ok && strings.Contains(s, "-----BEGIN") {
+		return redacted
+	}
+
+	return value
+}
@@ -0,0 +1,164 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/cmd/root"
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+func setUpCobra() *cobra.Command {
+	configCmd := NewConfigCmd()
+	configCmd.AddCommand(configPrintCmd())
+	cmd := root.NewRootCmd()
+	cmd.AddCommand(configCmd)
+	return cmd
+}
+
+func TestConfigPrint(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	policyYAML := fmt.Sprintf(`
+publicKey: %s
+rekorUrl: https://rekor.example.org
+sources:
+- name: default
+  policy:
+  - github.com/org/policy
+`, utils.TestPublicKeyJSON)
+	require.NoError(t, afero.WriteFile(fs, "/policy.yaml", []byte(policyYAML), 0400))
+	t.Setenv("EC_CACHE", "false")
+
+	cases := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			name: "from policy file",
+			args: []string{"--policy", "/policy.yaml", "--effective-time", "2024-01-01T00:00:00Z"},
+			expected: `{
+				"effectiveTime": {"value": "2024-01-01T00:00:00Z", "origin": "flag"},
+				"policy": {
+					"origin": "file",
+					"sources": [{"origin": "file", "name": "default", "policy": ["github.com/org/policy"]}],
+					"publicKey": {"value": "[REDACTED]", "origin": "file"},
+					"rekorUrl": {"value": "https://rekor.example.org", "origin": "file"}
+				}
+			}`,
+		},
+		{
+			name: "overridden by flags",
+			args: []string{"--policy", "/policy.yaml", "--effective-time", "2024-01-01T00:00:00Z", "--public-key", "k8s://ns/secret", "--rekor-url", "https://rekor.sigstore.dev"},
+			expected: `{
+				"effectiveTime": {"value": "2024-01-01T00:00:00Z", "origin": "flag"},
+				"policy": {
+					"origin": "file",
+					"sources": [{"origin": "file", "name": "default", "policy": ["github.com/org/policy"]}],
+					"publicKey": {"value": "k8s://ns/secret", "origin": "flag"},
+					"rekorUrl": {"value": "https://rekor.sigstore.dev", "origin": "flag"}
+				}
+			}`,
+		},
+		{
+			name: "inline",
+			args: []string{"--policy", `{"sources": []}`, "--effective-time", "2024-01-01T00:00:00Z"},
+			expected: `{
+				"effectiveTime": {"value": "2024-01-01T00:00:00Z", "origin": "flag"},
+				"policy": {"origin": "flag", "sources": []}
+			}`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cmd := setUpCobra()
+			cmd.SetContext(utils.WithFS(context.Background(), fs))
+			cmd.SetArgs(append([]string{"config", "print"}, c.args...))
+
+			var out bytes.Buffer
+			cmd.SetOut(&out)
+
+			require.NoError(t, cmd.Execute())
+
+			var config map[string]any
+			require.NoError(t, json.Unmarshal(out.Bytes(), &config))
+
+			flags := config["flags"].(map[string]any)
+			assert.Equal(t, map[string]any{"value": "5m0s", "origin": "default"}, flags["timeout"])
+			assert.Equal(t, map[string]any{"value": "2024-01-01T00:00:00Z", "origin": "flag"}, flags["effective-time"])
+			assert.Equal(t, map[string]any{"EC_CACHE": map[string]any{"value": "false", "origin": "env"}}, config["environment"])
+
+			settings := config["settings"].(map[string]any)
+			assert.Equal(t, map[string]any{"value": false, "origin": "env"}, settings["cache"])
+			assert.Equal(t, map[string]any{"value": "", "origin": "default"}, settings["resultCacheDir"])
+			assert.Equal(t, map[string]any{"value": []any{}, "origin": "default"}, settings["defaultPolicySources"])
+
+			delete(config, "flags")
+			delete(config, "settings")
+			delete(config, "environment")
+			actual, err := json.Marshal(config)
+			require.NoError(t, err)
+			assert.JSONEq(t, c.expected, string(actual))
+		})
+	}
+}
+
+func TestConfigPrintEnvironment(t *testing.T) {
+	t.Setenv("EC_CACHE", "false")
+	t.Setenv("EC_DEFAULT_POLICY_SOURCES", "oci::registry.io/baseline:latest")
+	t.Setenv("EC_DEFAULT_DATA_SOURCES", "git::https://git.io/baseline//data")
+	t.Setenv("VAULT_TOKEN", "s3cr3t")
+
+	cmd := setUpCobra()
+	cmd.SetContext(utils.WithFS(context.Background(), afero.NewMemMapFs()))
+	cmd.SetArgs([]string{"config", "print", "--policy", `{"sources": [{"name": "custom", "policy": ["github.com/org/policy"]}]}`})
+
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+
+	require.NoError(t, cmd.Execute())
+
+	var config struct {
+		Policy struct {
+			Sources []map[string]any `json:"sources"`
+		} `json:"policy"`
+		Settings    map[string]setting `json:"settings"`
+		Environment map[string]setting `json:"environment"`
+	}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &config))
+
+	assert.Equal(t, []map[string]any{
+		{"origin": "env", "name": "default-sources", "policy": []any{"oci::registry.io/baseline:latest"}, "data": []any{"git::https://git.io/baseline//data"}},
+		{"origin": "flag", "name": "custom", "policy": []any{"github.com/org/policy"}, "data": []any{"git::https://git.io/baseline//data"}},
+	}, config.Policy.Sources)
+	assert.Equal(t, setting{Value: []any{"oci::registry.io/baseline:latest"}, Origin: "env"}, config.Settings["defaultPolicySources"])
+	assert.Equal(t, setting{Value: []any{"git::https://git.io/baseline//data"}, Origin: "env"}, config.Settings["defaultDataSources"])
+	assert.Equal(t, setting{Value: "[REDACTED]", Origin: "env"}, config.Environment["VAULT_TOKEN"])
+}
@@ -26,6 +26,8 @@ func init() {
 	InspectCmd = NewInspectCmd()
 	InspectCmd.AddCommand(inspectPolicyCmd())
 	InspectCmd.AddCommand(inspectPolicyDataCmd())
+	InspectCmd.AddCommand(inspectExceptionsCmd())
+	InspectCmd.AddCommand(inspectCoverageCmd())
 }
 
 func NewInspectCmd() *cobra.Command {
@@ -0,0 +1,144 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Define the `ec inspect coverage` command
+package inspect
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	hd "github.com/MakeNowJust/heredoc"
+	"github.com/spf13/cobra"
+	"golang.org/x/exp/slices"
+	"sigs.k8s.io/yaml"
+
+	"github.com/enterprise-contract/ec-cli/internal/completion"
+	"github.com/enterprise-contract/ec-cli/internal/opa"
+	"github.com/enterprise-contract/ec-cli/internal/policy/source"
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+func inspectCoverageCmd() *cobra.Command {
+	var (
+		sourceUrls   []string
+		policyRef    string
+		outputFormat string
+	)
+
+	validFormats := []string{"text", "json", "yaml"}
+
+	cmd := &cobra.Command{
+		Use:   "coverage --policy <policy>",
+		Short: "Report the paths within the input the policy rules reference",
+
+		Long: hd.Doc(`
+			Report the paths within the input the policy rules reference.
+
+			The Rego of the policy sources is analyzed statically, no image is needed. For
+			each path within the input, e.g. input.image.ref, the deny and warn rules
+			referencing it are reported, either directly or through the rules and the
+			functions they reference. Variables bound to a path within the input are
+			followed, e.g. when iterating over the attestations, with the variable parts of
+			the paths given as [_]. The rules referencing no path within the input are
+			reported too.
+
+			The coverage is a rough map of which parts of the input, e.g. which fields of
+			the provenance, are checked by the policy. Paths within values computed by
+			other rules, e.g. the fields of the attestations returned by a library rule,
+			are not followed.
+		`),
+
+		Example: hd.Doc(`
+			Report the coverage of the input by the rules of a policy configuration:
+
+			  ec inspect coverage --policy policy.yaml
+
+			Report the coverage of the input by the rules of a policy source in JSON format:
+
+			  ec inspect coverage --source quay.io/enterprise-contract/ec-release-policy --output json
+		`),
+
+		Args: cobra.NoArgs,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if policyRef == "" {
+				return nil
+			}
+
+			var err error
+			sourceUrls, err = policySources(cmd.Context(), policyRef)
+
+			return err
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !slices.Contains(validFormats, outputFormat) {
+				return fmt.Errorf("invalid value for --output '%s'. accepted values: %s", outputFormat, strings.Join(validFormats, ", "))
+			}
+
+			ctx := cmd.Context()
+			fs := utils.FS(ctx)
+
+			workDir, err := utils.CreateWorkDir(fs)
+			if err != nil {
+				return err
+			}
+			defer utils.CleanupWorkDir(fs, workDir)
+
+			policyDirs := make([]string, 0, len(sourceUrls))
+			for _, url := range sourceUrls {
+				s := &source.PolicyUrl{Url: url, Kind: source.PolicyKind}
+				dir, err := s.GetPolicy(ctx, workDir, false)
+				if err != nil {
+					return err
+				}
+				policyDirs = append(policyDirs, dir)
+			}
+
+			coverage, err := opa.InputCoverage(fs, policyDirs)
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			switch outputFormat {
+			case "json":
+				return json.NewEncoder(out).Encode(coverage)
+			case "yaml":
+				yamlOutput, err := yaml.Marshal(coverage)
+				if err != nil {
+					return err
+				}
+				_, err = out.Write(yamlOutput)
+				return err
+			}
+
+			return coverage.WriteText(out)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVarP(&policyRef, "policy", "p", "", "reference to the policy configuration, either EnterpriseContractPolicy Kubernetes custom resource reference [<namespace>/]<name>, or inline JSON or YAML of the `spec` part")
+	flags.StringArrayVarP(&sourceUrls, "source", "s", []string{}, "policy source url. multiple values are allowed")
+	flags.StringVarP(&outputFormat, "output", "o", "text", fmt.Sprintf("output format. one of: %s", strings.Join(validFormats, ", ")))
+
+	cmd.MarkFlagsMutuallyExclusive("policy", "source")
+	cmd.MarkFlagsOneRequired("policy", "source")
+
+	completion.Register(cmd, "output", completion.Formats(validFormats))
+
+	return cmd
+}
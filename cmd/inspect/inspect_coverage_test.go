@@ -0,0 +1,119 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package inspect
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	hd "github.com/MakeNowJust/heredoc"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/enterprise-contract/ec-cli/internal/policy/source"
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+func TestInspectCoverage(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	ctx := utils.WithFS(context.Background(), fs)
+
+	downloader := mockDownloader{}
+	ctx = context.WithValue(ctx, source.DownloaderFuncKey, &downloader)
+
+	downloader.On("Download", mock.Anything, "policy", false).Return(nil).Run(func(args mock.Arguments) {
+		dir := args.String(0)
+		if err := fs.MkdirAll(dir, 0755); err != nil {
+			panic(err)
+		}
+		if err := afero.WriteFile(fs, dir+"/foo.rego", []byte(hd.Doc(`
+			package foo
+
+			import rego.v1
+
+			deny contains "bad" if {
+				some att in input.attestations
+				att.statement.predicateType != "https://slsa.dev/provenance/v0.2"
+			}
+
+			warn contains "never" if {
+				false
+			}
+		`)), 0644); err != nil {
+			panic(err)
+		}
+	})
+
+	cases := []struct {
+		name     string
+		format   []string
+		expected string
+	}{
+		{
+			name: "text",
+			expected: hd.Doc(`
+				input.attestations[_].statement.predicateType
+				  data.foo.deny
+				Rules referencing no input:
+				  data.foo.warn
+			`),
+		},
+		{
+			name:     "json",
+			format:   []string{"--output", "json"},
+			expected: `{"paths":[{"path":"input.attestations[_].statement.predicateType","rules":["data.foo.deny"]}],"unreferenced":["data.foo.warn"]}` + "\n",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cmd := setUpCobra(inspectCoverageCmd())
+			cmd.SetContext(ctx)
+			buffy := bytes.Buffer{}
+			cmd.SetOut(&buffy)
+
+			cmd.SetArgs(append([]string{
+				"inspect",
+				"coverage",
+				"--policy",
+				`{"sources":[{"policy":["policy"]}]}`,
+			}, c.format...))
+
+			err := cmd.Execute()
+			assert.NoError(t, err)
+			assert.Equal(t, c.expected, buffy.String())
+		})
+	}
+}
+
+func TestInspectCoverageRequiresPolicy(t *testing.T) {
+	cmd := setUpCobra(inspectCoverageCmd())
+	cmd.SetContext(utils.WithFS(context.Background(), afero.NewMemMapFs()))
+	cmd.SetOut(&bytes.Buffer{})
+
+	cmd.SetArgs([]string{
+		"inspect",
+		"coverage",
+	})
+
+	err := cmd.Execute()
+	assert.EqualError(t, err, "at least one of the flags in the group [policy source] is required")
+}
@@ -0,0 +1,206 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Define the `ec inspect exceptions` command
+package inspect
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	hd "github.com/MakeNowJust/heredoc"
+	"github.com/spf13/cobra"
+	"golang.org/x/exp/slices"
+	"sigs.k8s.io/yaml"
+
+	"github.com/enterprise-contract/ec-cli/internal/completion"
+	"github.com/enterprise-contract/ec-cli/internal/evaluator"
+	"github.com/enterprise-contract/ec-cli/internal/policy"
+	validate_utils "github.com/enterprise-contract/ec-cli/internal/validate"
+)
+
+// Status of an exception at the effective time
+const (
+	exceptionActive   = "active"
+	exceptionExpiring = "expiring"
+	exceptionExpired  = "expired"
+	exceptionPending  = "pending"
+)
+
+type exception struct {
+	Source         string `json:"source"`
+	Value          string `json:"value"`
+	ImageRef       string `json:"imageRef,omitempty"`
+	EffectiveOn    string `json:"effectiveOn,omitempty"`
+	EffectiveUntil string `json:"effectiveUntil,omitempty"`
+	Status         string `json:"status"`
+}
+
+func inspectExceptionsCmd() *cobra.Command {
+	var (
+		policyConfiguration string
+		effectiveTime       string
+		expiringWithin      time.Duration
+		outputFormat        string
+	)
+
+	validFormats := []string{"text", "json", "yaml"}
+
+	cmd := &cobra.Command{
+		Use:   "exceptions --policy <policy>",
+		Short: "List the volatile exclusions of a policy and when they expire",
+
+		Long: hd.Doc(`
+			List the volatile exclusions of a policy and when they expire.
+
+			Reads the volatile exclusions of each source of the policy configuration and
+			reports the window in which each of them is effective. At the effective time,
+			each exclusion is reported to be one of:
+
+			  active:   the exclusion is effective
+			  expiring: the exclusion is effective, but expires within the horizon given
+			            by --expiring-within
+			  expired:  the exclusion is no longer effective
+			  pending:  the exclusion is not yet effective
+
+			The exclusions are considered effective the same way they are when validating,
+			see the --effective-time flag of the 'ec validate image' command.
+		`),
+
+		Example: hd.Doc(`
+			List the volatile exclusions of a policy configuration file:
+
+			  ec inspect exceptions --policy my-policy.yaml
+
+			List the volatile exclusions expiring within the next week in JSON format:
+
+			  ec inspect exceptions --policy my-policy.yaml --expiring-within 168h --output json
+		`),
+
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !slices.Contains(validFormats, outputFormat) {
+				return fmt.Errorf("invalid value for --output '%s'. accepted values: %s", outputFormat, strings.Join(validFormats, ", "))
+			}
+
+			ctx := cmd.Context()
+
+			policyContent, err := validate_utils.GetPolicyConfig(ctx, policyConfiguration)
+			if err != nil {
+				return err
+			}
+
+			p, err := policy.NewInputPolicy(ctx, policyContent, effectiveTime)
+			if err != nil {
+				return err
+			}
+
+			at := p.EffectiveTime()
+			exceptions := []exception{}
+			for i, src := range p.Spec().Sources {
+				if src.VolatileConfig == nil {
+					continue
+				}
+
+				name := src.Name
+				if name == "" {
+					name = fmt.Sprintf("%d", i)
+				}
+
+				for _, c := range src.VolatileConfig.Exclude {
+					from, until := evaluator.VolatileCriteriaWindow(c)
+
+					status := exceptionActive
+					switch {
+					case !from.IsZero() && from.After(at):
+						status = exceptionPending
+					case !until.IsZero() && until.Before(at):
+						status = exceptionExpired
+					case !until.IsZero() && until.Before(at.Add(expiringWithin)):
+						status = exceptionExpiring
+					}
+
+					exceptions = append(exceptions, exception{
+						Source:         name,
+						Value:          c.Value,
+						ImageRef:       c.ImageRef,
+						EffectiveOn:    c.EffectiveOn,
+						EffectiveUntil: c.EffectiveUntil,
+						Status:         status,
+					})
+				}
+			}
+
+			out := cmd.OutOrStdout()
+			switch outputFormat {
+			case "json":
+				return json.NewEncoder(out).Encode(exceptions)
+			case "yaml":
+				yamlOutput, err := yaml.Marshal(exceptions)
+				if err != nil {
+					return err
+				}
+				_, err = out.Write(yamlOutput)
+				return err
+			}
+
+			if len(exceptions) == 0 {
+				fmt.Fprintln(out, "No volatile exclusions found")
+				return nil
+			}
+
+			w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "STATUS\tSOURCE\tVALUE\tIMAGE\tEFFECTIVE ON\tEFFECTIVE UNTIL")
+			for _, e := range exceptions {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", e.Status, e.Source, e.Value, orNone(e.ImageRef), orNone(e.EffectiveOn), orNone(e.EffectiveUntil))
+			}
+
+			return w.Flush()
+		},
+	}
+
+	cmd.Flags().StringVarP(&policyConfiguration, "policy", "p", "", hd.Doc(`
+		Policy configuration as:
+		  * Kubernetes reference ([<namespace>/]<name>)
+		  * file (policy.yaml)
+		  * git reference (github.com/user/repo//default?ref=main), or
+		  * inline JSON ('{sources: {...}}')`))
+	cmd.Flags().StringVar(&effectiveTime, "effective-time", policy.Now, hd.Doc(`
+		Run the report as if at the given time. Possible values are RFC3339 formatted
+		timestamps and "now"`))
+	cmd.Flags().DurationVar(&expiringWithin, "expiring-within", 30*24*time.Hour,
+		"report the exclusions expiring within the given duration from the effective time as expiring")
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "text", fmt.Sprintf("output format. one of: %s", strings.Join(validFormats, ", ")))
+
+	if err := cmd.MarkFlagRequired("policy"); err != nil {
+		panic(err)
+	}
+
+	completion.Register(cmd, "output", completion.Formats(validFormats))
+
+	return cmd
+}
+
+func orNone(value string) string {
+	if value == "" {
+		return "-"
+	}
+
+	return value
+}
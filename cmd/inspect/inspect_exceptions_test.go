@@ -0,0 +1,119 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package inspect
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+const exceptionsPolicy = `{
+	"sources": [
+		{
+			"name": "release",
+			"volatileConfig": {
+				"exclude": [
+					{"value": "cve.deprecated", "effectiveUntil": "2024-01-10T00:00:00Z"},
+					{"value": "tasks.required", "effectiveUntil": "2024-06-01T00:00:00Z"},
+					{"value": "test.no_skipped", "effectiveUntil": "2023-12-01T00:00:00Z"},
+					{"value": "sbom.found", "effectiveOn": "2024-02-01T00:00:00Z"},
+					{"value": "labels.required", "imageRef": "sha256:0000000000000000000000000000000000000000000000000000000000000000"}
+				],
+				"include": [
+					{"value": "cve.strict", "effectiveUntil": "2024-01-10T00:00:00Z"}
+				]
+			}
+		},
+		{
+			"policy": ["github.com/org/policy"]
+		}
+	]
+}`
+
+func TestInspectExceptions(t *testing.T) {
+	cases := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			name: "text",
+			args: []string{},
+			expected: "STATUS    SOURCE   VALUE            IMAGE                                                                    EFFECTIVE ON          EFFECTIVE UNTIL\n" +
+				"expiring  release  cve.deprecated   -                                                                        -                     2024-01-10T00:00:00Z\n" +
+				"active    release  tasks.required   -                                                                        -                     2024-06-01T00:00:00Z\n" +
+				"expired   release  test.no_skipped  -                                                                        -                     2023-12-01T00:00:00Z\n" +
+				"pending   release  sbom.found       -                                                                        2024-02-01T00:00:00Z  -\n" +
+				"active    release  labels.required  sha256:0000000000000000000000000000000000000000000000000000000000000000  -                     -\n",
+		},
+		{
+			name:     "shorter horizon",
+			args:     []string{"--expiring-within", "24h", "--output", "json"},
+			expected: `[{"source":"release","value":"cve.deprecated","effectiveUntil":"2024-01-10T00:00:00Z","status":"active"},{"source":"release","value":"tasks.required","effectiveUntil":"2024-06-01T00:00:00Z","status":"active"},{"source":"release","value":"test.no_skipped","effectiveUntil":"2023-12-01T00:00:00Z","status":"expired"},{"source":"release","value":"sbom.found","effectiveOn":"2024-02-01T00:00:00Z","status":"pending"},{"source":"release","value":"labels.required","imageRef":"sha256:0000000000000000000000000000000000000000000000000000000000000000","status":"active"}]` + "\n",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			ctx := utils.WithFS(context.Background(), fs)
+			require.NoError(t, afero.WriteFile(fs, "/policy.json", []byte(exceptionsPolicy), 0400))
+
+			cmd := setUpCobra(inspectExceptionsCmd())
+			cmd.SetContext(ctx)
+			buffy := bytes.Buffer{}
+			cmd.SetOut(&buffy)
+
+			cmd.SetArgs(append([]string{"inspect", "exceptions", "--policy", "/policy.json", "--effective-time", "2024-01-01T00:00:00Z"}, c.args...))
+
+			require.NoError(t, cmd.Execute())
+			assert.Equal(t, c.expected, buffy.String())
+		})
+	}
+}
+
+func TestInspectExceptionsNone(t *testing.T) {
+	cmd := setUpCobra(inspectExceptionsCmd())
+	cmd.SetContext(context.Background())
+	buffy := bytes.Buffer{}
+	cmd.SetOut(&buffy)
+
+	cmd.SetArgs([]string{"inspect", "exceptions", "--policy", `{"sources":[{"policy":["github.com/org/policy"]}]}`})
+
+	require.NoError(t, cmd.Execute())
+	assert.Equal(t, "No volatile exclusions found\n", buffy.String())
+}
+
+func TestInspectExceptionsInvalidOutput(t *testing.T) {
+	cmd := setUpCobra(inspectExceptionsCmd())
+	cmd.SetContext(context.Background())
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+
+	cmd.SetArgs([]string{"inspect", "exceptions", "--policy", `{"sources":[]}`, "--output", "csv"})
+
+	assert.EqualError(t, cmd.Execute(), "invalid value for --output 'csv'. accepted values: text, json, yaml")
+}
@@ -18,6 +18,7 @@
 package inspect
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"strings"
@@ -28,6 +29,7 @@ import (
 	"github.com/spf13/cobra"
 	"golang.org/x/exp/slices"
 
+	"github.com/enterprise-contract/ec-cli/internal/completion"
 	"github.com/enterprise-contract/ec-cli/internal/opa"
 	opaRule "github.com/enterprise-contract/ec-cli/internal/opa/rule"
 	"github.com/enterprise-contract/ec-cli/internal/policy"
@@ -38,15 +40,18 @@ import (
 func inspectPolicyCmd() *cobra.Command {
 	var (
 		sourceUrls       []string
+		dataUrls         []string
 		policyRef        string
 		destDir          string
 		outputFormat     string
 		ruleFilter       string
 		packageFilter    string
 		collectionFilter string
+		graph            bool
 	)
 
 	validFormats := []string{"json", "text", "names", "short-names"}
+	validGraphFormats := []string{"json", "text"}
 
 	cmd := &cobra.Command{
 		Use:   "policy --source <source-url>",
@@ -63,6 +68,11 @@ func inspectPolicyCmd() *cobra.Command {
 			including the rule annotations which include the rule's title and description
 			and custom fields used by ec to filter the results produced by conftest.
 
+			With --graph the Rego is analyzed to show which rules reference other rules and
+			which data, as a graph in the DOT format, or in JSON with --output json. When
+			the policy configuration is given with --policy, the data sources are fetched
+			too and the data keys no rule references are highlighted as unused.
+
 			Note that this command is not typically required to verify the Enterprise
 			Contract. It has been made available for troubleshooting and debugging purposes.
 		`),
@@ -75,6 +85,10 @@ func inspectPolicyCmd() *cobra.Command {
 			Display details about the latest Enterprise Contract release policy in json format:
 
 			  ec inspect policy --source quay.io/enterprise-contract/ec-release-policy -o json | jq
+
+			Render the graph of the references of the rules and the data of a policy configuration:
+
+			  ec inspect policy --policy policy.yaml --graph | dot -Tsvg > policy.svg
 		`),
 
 		Args: cobra.NoArgs,
@@ -83,25 +97,25 @@ func inspectPolicyCmd() *cobra.Command {
 				return nil
 			}
 
-			p, err := policy.NewInertPolicy(cmd.Context(), policyRef)
-			if err != nil {
+			var err error
+			sourceUrls, err = policySources(cmd.Context(), policyRef)
+			if err != nil || !graph {
 				return err
 			}
 
-			// clear the sourceUrls slice
-			sourceUrls = make([]string, 0, 10)
+			dataUrls, err = dataSources(cmd.Context(), policyRef)
 
-			for _, s := range p.Spec().Sources {
-				sourceUrls = append(sourceUrls, s.Policy...)
-			}
-
-			return nil
+			return err
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if !slices.Contains(validFormats, outputFormat) {
 				return fmt.Errorf("invalid value for --output '%s'. accepted values: %s", outputFormat, strings.Join(validFormats, ", "))
 			}
 
+			if graph && !slices.Contains(validGraphFormats, outputFormat) {
+				return fmt.Errorf("invalid value for --output '%s' with --graph. accepted values: %s", outputFormat, strings.Join(validGraphFormats, ", "))
+			}
+
 			ctx := cmd.Context()
 			fs := utils.FS(ctx)
 
@@ -116,6 +130,10 @@ func inspectPolicyCmd() *cobra.Command {
 				defer utils.CleanupWorkDir(fs, workDir)
 			}
 
+			if graph {
+				return outputGraph(cmd, sourceUrls, dataUrls, destDir, outputFormat)
+			}
+
 			allResults := make(map[string][]*ast.AnnotationsRef)
 			for _, url := range sourceUrls {
 				s := &source.PolicyUrl{Url: url, Kind: source.PolicyKind}
@@ -159,12 +177,93 @@ func inspectPolicyCmd() *cobra.Command {
 	flags.StringVar(&ruleFilter, "rule", ruleFilter, "display results matching rule name")
 	flags.StringVar(&packageFilter, "package", packageFilter, "display results matching package name")
 	flags.StringVar(&collectionFilter, "collection", collectionFilter, "display rules included in given collection")
+	flags.BoolVar(&graph, "graph", graph, "display the graph of the references of the rules to other rules and to data, in the DOT format for the text output format")
 
 	cmd.MarkFlagsMutuallyExclusive("policy", "source")
+	cmd.MarkFlagsMutuallyExclusive("graph", "rule")
+	cmd.MarkFlagsMutuallyExclusive("graph", "package")
+	cmd.MarkFlagsMutuallyExclusive("graph", "collection")
+
+	completion.Register(cmd, "output", completion.Formats(validFormats))
+	completion.Register(cmd, "collection", completion.Collections(func(cmd *cobra.Command) []string {
+		urls, _ := cmd.Flags().GetStringArray("source")
+		if policyRef, _ := cmd.Flags().GetString("policy"); policyRef != "" {
+			urls, _ = policySources(cmd.Context(), policyRef)
+		}
+		return urls
+	}))
 
 	return cmd
 }
 
+// policySources returns the policy source URLs of the policy configuration
+func policySources(ctx context.Context, policyRef string) ([]string, error) {
+	p, err := policy.NewInertPolicy(ctx, policyRef)
+	if err != nil {
+		return nil, err
+	}
+
+	sourceUrls := make([]string, 0, 10)
+	for _, s := range p.Spec().Sources {
+		sourceUrls = append(sourceUrls, s.Policy...)
+	}
+
+	return sourceUrls, nil
+}
+
+// dataSources returns the data source URLs of the policy configuration
+func dataSources(ctx context.Context, policyRef string) ([]string, error) {
+	p, err := policy.NewInertPolicy(ctx, policyRef)
+	if err != nil {
+		return nil, err
+	}
+
+	dataUrls := make([]string, 0, 10)
+	for _, s := range p.Spec().Sources {
+		dataUrls = append(dataUrls, s.Data...)
+	}
+
+	return dataUrls, nil
+}
+
+// outputGraph fetches the policy and data sources and outputs the graph of
+// the references of the rules
+func outputGraph(cmd *cobra.Command, sourceUrls, dataUrls []string, destDir, outputFormat string) error {
+	ctx := cmd.Context()
+
+	policyDirs := make([]string, 0, len(sourceUrls))
+	for _, url := range sourceUrls {
+		s := &source.PolicyUrl{Url: url, Kind: source.PolicyKind}
+		dir, err := s.GetPolicy(ctx, destDir, false)
+		if err != nil {
+			return err
+		}
+		policyDirs = append(policyDirs, dir)
+	}
+
+	dataDirs := make([]string, 0, len(dataUrls))
+	for _, url := range dataUrls {
+		s := &source.PolicyUrl{Url: url, Kind: source.DataKind}
+		dir, err := s.GetPolicy(ctx, destDir, false)
+		if err != nil {
+			return err
+		}
+		dataDirs = append(dataDirs, dir)
+	}
+
+	g, err := opa.PolicyGraph(utils.FS(ctx), policyDirs, dataDirs)
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	if outputFormat == "json" {
+		return json.NewEncoder(out).Encode(g)
+	}
+
+	return g.WriteDOT(out)
+}
+
 func filterResults(results map[string][]*ast.AnnotationsRef, rule, pkg, collection string) (map[string][]*ast.AnnotationsRef, error) {
 	if rule == "" && pkg == "" && collection == "" {
 		return results, nil
@@ -31,6 +31,7 @@ import (
 	"golang.org/x/exp/slices"
 	"sigs.k8s.io/yaml"
 
+	"github.com/enterprise-contract/ec-cli/internal/completion"
 	"github.com/enterprise-contract/ec-cli/internal/policy/source"
 	"github.com/enterprise-contract/ec-cli/internal/utils"
 )
@@ -165,5 +166,7 @@ func inspectPolicyDataCmd() *cobra.Command {
 		panic(err)
 	}
 
+	completion.Register(cmd, "output", completion.Formats(validFormats))
+
 	return cmd
 }
@@ -23,6 +23,7 @@ import (
 	"fmt"
 	"testing"
 
+	hd "github.com/MakeNowJust/heredoc"
 	"github.com/spf13/afero"
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
@@ -132,6 +133,107 @@ func TestFetchSources(t *testing.T) {
 	assert.Equal(t, "# Source: one\n\n# Source: three\n\n# Source: two\n\n", buffy.String())
 }
 
+func TestInspectPolicyGraph(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	ctx := utils.WithFS(context.Background(), fs)
+
+	downloader := mockDownloader{}
+	ctx = context.WithValue(ctx, source.DownloaderFuncKey, &downloader)
+
+	createFile := func(name, content string) func(mock.Arguments) {
+		return func(args mock.Arguments) {
+			dir := args.String(0)
+
+			if err := fs.MkdirAll(dir, 0755); err != nil {
+				panic(err)
+			}
+			if err := afero.WriteFile(fs, fmt.Sprintf("%s/%s", dir, name), []byte(content), 0644); err != nil {
+				panic(err)
+			}
+		}
+	}
+
+	downloader.On("Download", mock.Anything, "policy", false).Return(nil).Run(createFile("foo.rego", hd.Doc(`
+		package foo
+
+		import rego.v1
+
+		deny contains "bad" if {
+			not data.allowed[input.name]
+		}
+	`)))
+	downloader.On("Download", mock.Anything, "data", false).Return(nil).Run(createFile("data.yml", hd.Doc(`
+		allowed:
+		  good: true
+		unused: []
+	`)))
+
+	cases := []struct {
+		name     string
+		format   []string
+		expected string
+	}{
+		{
+			name: "dot",
+			expected: hd.Doc(`
+				digraph policy {
+				  rankdir=LR;
+				  "data.foo.deny";
+				  "data.allowed" [shape=box];
+				  "data.unused" [shape=box, color=red, label="data.unused (unused)"];
+				  "data.foo.deny" -> "data.allowed" [style=dashed];
+				}
+			`),
+		},
+		{
+			name:     "json",
+			format:   []string{"--output", "json"},
+			expected: `{"rules":["data.foo.deny"],"data":["data.allowed","data.unused"],"references":[{"from":"data.foo.deny","to":"data.allowed","kind":"data"}],"unusedData":["data.unused"]}` + "\n",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			inspectPolicyCmd := inspectPolicyCmd()
+			cmd := setUpCobra(inspectPolicyCmd)
+			cmd.SetContext(ctx)
+			buffy := bytes.Buffer{}
+			cmd.SetOut(&buffy)
+
+			cmd.SetArgs(append([]string{
+				"inspect",
+				"policy",
+				"--policy",
+				`{"sources":[{"policy":["policy"],"data":["data"]}]}`,
+				"--graph",
+			}, c.format...))
+
+			err := cmd.Execute()
+			assert.NoError(t, err)
+			assert.Equal(t, c.expected, buffy.String())
+		})
+	}
+}
+
+func TestInspectPolicyGraphInvalidFormat(t *testing.T) {
+	inspectPolicyCmd := inspectPolicyCmd()
+	cmd := setUpCobra(inspectPolicyCmd)
+	cmd.SetContext(utils.WithFS(context.Background(), afero.NewMemMapFs()))
+
+	cmd.SetArgs([]string{
+		"inspect",
+		"policy",
+		"--source",
+		"one",
+		"--graph",
+		"--output",
+		"names",
+	})
+
+	err := cmd.Execute()
+	assert.EqualError(t, err, "invalid value for --output 'names' with --graph. accepted values: json, text")
+}
+
 func TestSourcesAndPolicyCantBeBothProvided(t *testing.T) {
 	fs := afero.NewMemMapFs()
 	ctx := utils.WithFS(context.Background(), fs)
@@ -179,3 +281,50 @@ func setUpCobra(command *cobra.Command) *cobra.Command {
 	cmd.AddCommand(inspectCmd)
 	return cmd
 }
+
+func TestInspectPolicyCollectionCompletion(t *testing.T) {
+	t.Setenv("EC_CACHE", "false")
+
+	fs := afero.NewMemMapFs()
+	ctx := utils.WithFS(context.Background(), fs)
+
+	downloader := mockDownloader{}
+	ctx = context.WithValue(ctx, source.DownloaderFuncKey, &downloader)
+
+	downloader.On("Download", mock.Anything, "one", false).Return(nil).Run(func(args mock.Arguments) {
+		if err := afero.WriteFile(fs, fmt.Sprintf("%s/foo.rego", args.String(0)), []byte(hd.Doc(`
+			package foo
+
+			import rego.v1
+
+			# METADATA
+			# custom:
+			#   collections:
+			#   - minimal
+			deny contains "never" if {
+				false
+			}
+		`)), 0644); err != nil {
+			panic(err)
+		}
+	})
+
+	cmd := setUpCobra(inspectPolicyCmd())
+	cmd.SetContext(ctx)
+	buffy := bytes.Buffer{}
+	cmd.SetOut(&buffy)
+
+	cmd.SetArgs([]string{
+		"__complete",
+		"inspect",
+		"policy",
+		"--policy",
+		`{"sources":[{"policy":["one"]}]}`,
+		"--collection",
+		"",
+	})
+
+	err := cmd.Execute()
+	assert.NoError(t, err)
+	assert.Equal(t, "minimal\n:4\n", buffy.String())
+}
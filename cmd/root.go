@@ -20,6 +20,8 @@ import (
 	"context"
 	"os"
 
+	"github.com/enterprise-contract/ec-cli/cmd/check"
+	"github.com/enterprise-contract/ec-cli/cmd/config"
 	"github.com/enterprise-contract/ec-cli/cmd/fetch"
 	"github.com/enterprise-contract/ec-cli/cmd/initialize"
 	"github.com/enterprise-contract/ec-cli/cmd/inspect"
@@ -29,6 +31,7 @@ import (
 	"github.com/enterprise-contract/ec-cli/cmd/test"
 	"github.com/enterprise-contract/ec-cli/cmd/track"
 	"github.com/enterprise-contract/ec-cli/cmd/validate"
+	"github.com/enterprise-contract/ec-cli/cmd/verify"
 	"github.com/enterprise-contract/ec-cli/cmd/version"
 	"github.com/enterprise-contract/ec-cli/internal/utils"
 )
@@ -41,17 +44,22 @@ var RootCmd = root.NewRootCmd()
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
-	if err := RootCmd.ExecuteContext(context.Background()); err != nil {
-		os.Exit(1)
+	err := RootCmd.ExecuteContext(context.Background())
+	root.FinishTelemetry(err)
+	if err != nil {
+		os.Exit(root.ExitCode(err))
 	}
 }
 
 func init() {
+	RootCmd.AddCommand(check.CheckCmd)
+	RootCmd.AddCommand(config.ConfigCmd)
 	RootCmd.AddCommand(fetch.FetchCmd)
 	RootCmd.AddCommand(initialize.InitCmd)
 	RootCmd.AddCommand(inspect.InspectCmd)
 	RootCmd.AddCommand(track.TrackCmd)
 	RootCmd.AddCommand(validate.ValidateCmd)
+	RootCmd.AddCommand(verify.VerifyCmd)
 	RootCmd.AddCommand(version.VersionCmd)
 	RootCmd.AddCommand(opa.OPACmd)
 	RootCmd.AddCommand(sigstore.SigstoreCmd)
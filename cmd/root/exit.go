@@ -0,0 +1,54 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package root
+
+import "errors"
+
+// Exit codes of the commands. Any error not carrying an exit code, see
+// ExitError, exits with ExitFailure.
+const (
+	// ExitFailure is used when the validation failed, e.g. on policy
+	// violations, or on any other error
+	ExitFailure = 1
+	// ExitError is used when the validation could not be completed, e.g. the
+	// images could not be fetched or verified
+	ExitError = 2
+)
+
+// ExitCodeError is an error exiting the command with the given exit code.
+type ExitCodeError struct {
+	Code int
+	Err  error
+}
+
+func (e ExitCodeError) Error() string {
+	return e.Err.Error()
+}
+
+func (e ExitCodeError) Unwrap() error {
+	return e.Err
+}
+
+// ExitCode returns the exit code for the given error returned by a command.
+func ExitCode(err error) int {
+	var exitErr ExitCodeError
+	if errors.As(err, &exitErr) {
+		return exitErr.Code
+	}
+
+	return ExitFailure
+}
@@ -24,13 +24,21 @@ import (
 	hd "github.com/MakeNowJust/heredoc"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	oteltrace "go.opentelemetry.io/otel/trace"
 
 	"github.com/enterprise-contract/ec-cli/internal/kubernetes"
 	"github.com/enterprise-contract/ec-cli/internal/logging"
+	"github.com/enterprise-contract/ec-cli/internal/policy/source"
+	"github.com/enterprise-contract/ec-cli/internal/telemetry"
 )
 
 var cancel context.CancelFunc
 
+var (
+	span              oteltrace.Span
+	shutdownTelemetry func(context.Context) error
+)
+
 var (
 	quiet         bool = false
 	verbose       bool = false
@@ -38,6 +46,7 @@ var (
 	trace         bool = false
 	globalTimeout      = 5 * time.Minute
 	logfile       string
+	otelEndpoint  string
 )
 
 func NewRootCmd() *cobra.Command {
@@ -59,6 +68,14 @@ func NewRootCmd() *cobra.Command {
 			// Create a new context now that flags have been parsed so a custom timeout can be used.
 			ctx := cmd.Context()
 			ctx, cancel = context.WithTimeout(ctx, globalTimeout)
+
+			ctx, shutdown, err := telemetry.Init(ctx, otelEndpoint)
+			if err != nil {
+				logrus.Warnf("Unable to export the traces: %v", err)
+			}
+			shutdownTelemetry = shutdown
+			ctx, span = telemetry.Start(ctx, cmd.CommandPath())
+
 			cmd.SetContext(ctx)
 		},
 
@@ -84,5 +101,28 @@ func setFlags(rootCmd *cobra.Command) {
 	rootCmd.PersistentFlags().BoolVar(&trace, "trace", trace, "enable trace logging")
 	rootCmd.PersistentFlags().DurationVar(&globalTimeout, "timeout", globalTimeout, "max overall execution duration")
 	rootCmd.PersistentFlags().StringVar(&logfile, "logfile", "", "file to write the logging output. If not specified logging output will be written to stderr")
+	rootCmd.PersistentFlags().StringVar(&otelEndpoint, "otel-endpoint", "", hd.Doc(`
+		export OpenTelemetry traces of the major phases, e.g. fetching the policy sources or
+		evaluating the policy, to the given OTLP gRPC endpoint, e.g. http://collector:4317.
+		The OTEL_* environment variables are respected, e.g. OTEL_EXPORTER_OTLP_ENDPOINT also
+		enables the export, and TRACEPARENT sets the parent trace`))
 	kubernetes.AddKubeconfigFlag(rootCmd)
+	source.AddGitTokenFileFlag(rootCmd)
+	source.AddNoDefaultSourcesFlag(rootCmd)
+}
+
+// FinishTelemetry ends the span of the command with the outcome given by the
+// error returned by the command, and flushes the spans.
+func FinishTelemetry(err error) {
+	if span == nil {
+		return
+	}
+
+	telemetry.End(span, telemetry.OutcomeOf(err == nil), err)
+
+	ctx, done := context.WithTimeout(context.Background(), 5*time.Second)
+	defer done()
+	if err := shutdownTelemetry(ctx); err != nil {
+		logrus.Warnf("Unable to export the traces: %v", err)
+	}
 }
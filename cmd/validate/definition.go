@@ -24,6 +24,7 @@ import (
 	"github.com/hashicorp/go-multierror"
 	"github.com/spf13/cobra"
 
+	"github.com/enterprise-contract/ec-cli/internal/completion"
 	"github.com/enterprise-contract/ec-cli/internal/definition"
 	"github.com/enterprise-contract/ec-cli/internal/evaluator"
 	"github.com/enterprise-contract/ec-cli/internal/format"
@@ -86,13 +87,19 @@ func validateDefinitionCmd(validate definitionValidationFn) *cobra.Command {
 			var allErrors error
 			report := definition.NewReport()
 			showSuccesses, _ := cmd.Flags().GetBool("show-successes")
+			// the default policy sources given by the environment come
+			// first, the default data sources are added after the given data
+			// sources, as with the source groups of a policy configuration
+			defaultPolicyURLs, defaultDataURLs := source.DefaultSources()
+			policyURLs := append(defaultPolicyURLs, data.policyURLs...)
+			dataURLs := source.MergeSources(data.dataURLs, defaultDataURLs)
 			for i := range data.filePaths {
 				fpath := data.filePaths[i]
 				var sources []source.PolicySource
-				for _, url := range data.policyURLs {
+				for _, url := range policyURLs {
 					sources = append(sources, &source.PolicyUrl{Url: url, Kind: source.PolicyKind})
 				}
-				for _, url := range data.dataURLs {
+				for _, url := range dataURLs {
 					sources = append(sources, &source.PolicyUrl{Url: url, Kind: source.DataKind})
 				}
 				ctx := cmd.Context()
@@ -107,7 +114,8 @@ func validateDefinitionCmd(validate definitionValidationFn) *cobra.Command {
 					report.Add(*out)
 				}
 			}
-			p := format.NewTargetParser(definition.JSONReport, format.Options{ShowSuccesses: showSuccesses}, cmd.OutOrStdout(), utils.FS(cmd.Context()))
+			jsonCompact, _ := cmd.Flags().GetBool("json-compact")
+			p := format.NewTargetParser(definition.JSONReport, format.Options{ShowSuccesses: showSuccesses, JSONCompact: jsonCompact}, cmd.OutOrStdout(), utils.FS(cmd.Context())).WithContext(cmd.Context())
 			for _, target := range data.output {
 				if err := report.Write(target, p); err != nil {
 					allErrors = multierror.Append(allErrors, err)
@@ -145,5 +153,11 @@ func validateDefinitionCmd(validate definitionValidationFn) *cobra.Command {
 		panic(err)
 	}
 
+	completion.Register(cmd, "output", completion.Formats([]string{definition.JSONReport, definition.YAMLReport}))
+	completion.Register(cmd, "namespace", completion.Namespaces(func(cmd *cobra.Command) []string {
+		urls, _ := cmd.Flags().GetStringSlice("policy")
+		return urls
+	}))
+
 	return cmd
 }
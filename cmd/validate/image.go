@@ -21,58 +21,200 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"net"
+	"os"
+	"path"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	hd "github.com/MakeNowJust/heredoc"
+	ecc "github.com/enterprise-contract/enterprise-contract-controller/api/v1alpha1"
+	"github.com/google/go-containerregistry/pkg/name"
 	"github.com/hashicorp/go-multierror"
 	app "github.com/konflux-ci/application-api/api/v1alpha1"
 	"github.com/sigstore/cosign/v2/pkg/cosign"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 
+	"github.com/enterprise-contract/ec-cli/cmd/root"
 	"github.com/enterprise-contract/ec-cli/internal/applicationsnapshot"
+	"github.com/enterprise-contract/ec-cli/internal/attestation"
+	"github.com/enterprise-contract/ec-cli/internal/completion"
 	"github.com/enterprise-contract/ec-cli/internal/evaluator"
+	"github.com/enterprise-contract/ec-cli/internal/fetchers/oci/config"
 	"github.com/enterprise-contract/ec-cli/internal/format"
+	"github.com/enterprise-contract/ec-cli/internal/image"
 	"github.com/enterprise-contract/ec-cli/internal/output"
 	"github.com/enterprise-contract/ec-cli/internal/policy"
 	"github.com/enterprise-contract/ec-cli/internal/policy/source"
+	"github.com/enterprise-contract/ec-cli/internal/signature"
 	"github.com/enterprise-contract/ec-cli/internal/utils"
+	"github.com/enterprise-contract/ec-cli/internal/utils/oci"
 	validate_utils "github.com/enterprise-contract/ec-cli/internal/validate"
+	"github.com/enterprise-contract/ec-cli/internal/version"
 )
 
 type imageValidationFunc func(context.Context, app.SnapshotComponent, *app.SnapshotSpec, policy.Policy, []evaluator.Evaluator, bool) (*output.Output, error)
 
 var newConftestEvaluator = evaluator.NewConftestEvaluator
 
+// watchInterval is how often the local policy sources are checked for changes
+// when --watch-policy is used.
+var watchInterval = time.Second
+
+// isInteractive reports whether both the standard input and output are a
+// terminal, required to browse the results with --tui.
+var isInteractive = func() bool {
+	return utils.IsTerminal(os.Stdin) && utils.IsTerminal(os.Stdout)
+}
+
 func validateImageCmd(validate imageValidationFunc) *cobra.Command {
 	data := struct {
+		allowedBaseImages           []string
+		allowedBuilderIDs           []string
+		allowNetworkBuiltins        bool
+		builtinAllowedHosts         []string
+		builtinTimeout              time.Duration
+		allowedMediaTypes           []string
+		allowedPayloadTypes         []string
+		attestationCosignerKeys     []string
+		requiredAttestationSigners  int
+		allowedSignatureAlgorithms  []string
 		certificateIdentity         string
 		certificateIdentityRegExp   string
 		certificateOIDCIssuer       string
 		certificateOIDCIssuerRegExp string
+		componentPolicies           applicationsnapshot.ComponentPolicies
+		allowPolicyReplace          bool
+		dataMergeStrategy           string
+		strictRego                  bool
+		deniedMediaTypes            []string
+		dumpInput                   string
 		effectiveTime               string
+		evalMemoryLimit             string
+		maxInputSize                string
+		maxInputSizeBytes           int64
+		evalTimeout                 time.Duration
+		evalBudget                  evaluator.EvaluationBudget
+		failOn                      string
+		failOnSeverity              string
 		extraRuleData               []string
+		expectPolicyDigest          []string
+		expectedPolicyDigests       source.ExpectedDigests
+		failOnDuplicate             bool
+		failOnEmptySnapshot         bool
+		failOnUnsigned              bool
 		filePath                    string // Deprecated: images replaced this
 		imageRef                    string
+		imageTagConstraints         []string
 		info                        bool
 		input                       string // Deprecated: images replaced this
+		introspect                  bool
+		tui                         bool
+		knownViolationsFile         string
+		knownViolations             applicationsnapshot.KnownViolations
+		updateKnownViolations       bool
+		redact                      []string
+		redactions                  applicationsnapshot.Redactions
+		messageOverrideFile         string
+		messageOverrides            applicationsnapshot.MessageOverrides
+		normalizeDigests            bool
+		minSLSALevel                int
+		minKeySize                  int
 		ignoreRekor                 bool
+		rekorTimeWindow             time.Duration
+		noResultCache               bool
+		resultCacheTTL              time.Duration
+		retryBudget                 time.Duration
+		verifySBOMConsistency       bool
+		reuseImageMetadata          bool
+		failOnAttestationConflict   bool
+		verifySubjectConsistency    bool
 		output                      []string
+		formatterPlugins            []string
 		outputFile                  string
 		policy                      policy.Policy
+		preflight                   bool
+		preflightOnly               bool
 		policyConfiguration         string
+		policyConfig                []string
+		policyConfigNames           []string
+		policyConfigs               map[string][]ecc.Source
+		policyLabelConfig           string
+		labelPolicies               *policy.LabelPolicies
+		logCollector                string
+		logCollectorCA              string
+		logCollectorRequired        bool
+		signOutput                  bool
+		outputChecksums             bool
+		onlyFailures                bool
+		outputSigningKey            string
+		outputSigner                *applicationsnapshot.OutputSigner
+		changedSince                string
+		collector                   *applicationsnapshot.Collector
+		pinDigest                   []string
+		pinnedDigests               []image.PinnedDigest
+		signatureDigest             []string
+		signatureDigests            []image.SignatureDigest
+		denyDigest                  []string
+		allowDigest                 []string
+		operatorDigests             image.OperatorDigestOptions
+		attestationPolicy           string
 		publicKey                   string
 		rekorURL                    string
+		registryRewrite             []string
+		registryRewrites            oci.RegistryRewrites
+		reportUnsigned              bool
+		requireAll                  bool
+		requiredLabel               []string
+		requiredLabels              []image.RequiredLabel
+		maxImageAge                 string
+		maxImageAgeDuration         time.Duration
+		requirePolicyLabel          bool
+		requireRuleCode             []string
+		resources                   string
+		slsaBuilderIDs              []string
 		snapshot                    string
 		spec                        *app.SnapshotSpec
 		strict                      bool
-		images                      string
+		traceFile                   string
+		traceImages                 []string
+		traceRules                  []string
+		tsaCertChain                string
+		watchPolicy                 bool
+		images                      []string
+		mergeSnapshots              string
+		missingSource               string
+		digestFile                  string
+		helmChart                   string
+		helmValues                  []string
 		noColor                     bool
+		noProvenance                bool
+		normalizeAttestations       bool
+		parallelPolicyEval          bool
+		previousSnapshot            *app.SnapshotSpec
 		forceColor                  bool
+		workers                     int
+		fetchConcurrency            int
 	}{
-		strict: true,
+		strict:              true,
+		attestationPolicy:   image.AttestationsRequired,
+		failOn:              applicationsnapshot.FailOnViolation,
+		resultCacheTTL:      image.DefaultResultCacheTTL,
+		builtinTimeout:      evaluator.DefaultNetworkBuiltinsTimeout,
+		dataMergeStrategy:   string(evaluator.DeepMerge),
+		allowedPayloadTypes: attestation.DefaultPayloadTypes,
+		missingSource:       source.MissingSourceError,
+		workers:             defaultWorkers,
+		reuseImageMetadata:  true,
+		fetchConcurrency:    source.DefaultFetchConcurrency,
 	}
 
 	validOutputFormats := applicationsnapshot.OutputFormats
@@ -101,6 +243,15 @@ func validateImageCmd(validate imageValidationFunc) *cobra.Command {
 
 			Validation advances each stage as much as possible for each image in order to
 			capture all issues in a single execution.
+
+			Each image is reported with the status pass, fail, error or skipped. An image
+			with the status error could not be evaluated, e.g. because it could not be
+			fetched or its signatures could not be verified, while an image with the status
+			fail was evaluated and violates the policy. When the validation fails, the exit
+			code is 2 if any image could not be evaluated, and 1 otherwise. The images that
+			were not validated are also listed in the skipped field of the output, each with
+			a reason code, e.g. unchanged, so that the coverage of the validation can be
+			audited.
 		`),
 
 		Example: hd.Doc(`
@@ -114,14 +265,52 @@ func validateImageCmd(validate imageValidationFunc) *cobra.Command {
 
 			  ec validate image --images my-app.yaml
 
+			Validate the images of multiple ApplicationSnapshot Spec files as one, keeping the
+			component policy of the last file for images listed with different policies:
+
+			  ec validate image --images app-a.yaml --images app-b.yaml --merge-snapshots last
+
+			Validate multiple images from a file listing one image reference pinned by digest per
+			line:
+
+			  ec validate image --digest-file digests.txt
+
+			Validate the image with the newest 1.x tag, from version 1.2 onwards:
+
+			  ec validate image --image-tag-constraint 'registry/name:>=1.2,<2.0'
+
+			Validate the images of the containers of a Helm chart, rendered with the given values:
+
+			  ec validate image --helm-chart ./chart --values values.yaml
+
 			Validate attestation of images from an inline ApplicationSnapshot Spec:
 
 			  ec validate image --images '{"components":[{"containerImage":"<image url>"}]}'
 
+			Choose the policy sources by the value of the "ec.policy" label of each image, the
+			label-policies.yaml file maps the label values to the policy sources:
+
+			  ec validate image --images my-app.yaml --policy my-policy \
+			    --policy-label-config label-policies.yaml --require-policy-label
+
+			Validate some components of the snapshot with additional, or different, policy and data
+			sources. The per-component "policy" has a "mode" of either "merge", the default, adding
+			its sources to the sources of the policy, or "replace", using only its own sources,
+			which needs to be allowed with --allow-policy-replace:
+
+			  ec validate image --policy my-policy --images '{"components":[
+			    {"containerImage":"<image url>"},
+			    {"containerImage":"<other image url>","policy":{"mode":"merge",
+			      "sources":[{"policy":["github.com/org/extra-rules//policy"]}]}}]}'
+
 			Use a different public key than the one from the EnterpriseContractPolicy resource:
 
 			  ec validate image --image registry/name:tag --public-key <path/to/public/key>
 
+			Use a public key stored in a Kubernetes secret, or in a KMS, without writing it to disk:
+
+			  ec validate image --image registry/name:tag --public-key k8s://my-namespace/my-secret
+
 			Use a different Rekor URL than the one from the EnterpriseContractPolicy resource:
 
 			  ec validate image --image registry/name:tag --rekor-url https://rekor.example.org
@@ -134,6 +323,10 @@ func validateImageCmd(validate imageValidationFunc) *cobra.Command {
 
 			  ec validate image --image registry/name:tag --strict=false
 
+			Return a non-zero status code also if there are warnings:
+
+			  ec validate image --image registry/name:tag --fail-on warning
+
 			Use an EnterpriseContractPolicy resource from the currently active kubernetes context:
 
 			  ec validate image --image registry/name:tag --policy my-policy
@@ -159,6 +352,103 @@ func validateImageCmd(validate imageValidationFunc) *cobra.Command {
 
 			  ec validate image --image registry/name:tag --policy github.com/user/repo
 
+			Use the rego files from a local directory as the policy, read in place without
+			copying, and validate again whenever any file in the directory changes. Use the
+			global --timeout flag to extend the time spent watching:
+
+			  ec validate image --image registry/name:tag --policy ./local/policy/dir \
+			    --watch-policy --timeout 1h
+
+			Produce no output at all, only the exit code reports the outcome of the validation.
+			Unlike --quiet, which only reduces the logging, this suppresses the report itself:
+
+			  ec validate image --image registry/name:tag --output none
+
+			Require the attestations to be recorded in Rekor within an hour of the build finishing:
+
+			  ec validate image --image registry/name:tag --policy my-policy --rekor-time-window 1h
+
+			Fail images whose policy evaluation grows the heap by more than 512MiB or takes
+			longer than a minute:
+
+			  ec validate image --image registry/name:tag --policy my-policy \
+			    --eval-memory-limit 512Mi --eval-timeout 1m
+
+			Write OPA's evaluation trace of the rules of one package for one image to a file,
+			to debug an unexpected result:
+
+			  ec validate image --images my-app.yaml --policy my-policy \
+			    --trace-rule attestation_type.known_attestation_type \
+			    --trace-image registry/name@sha256:<digest> --trace-file trace.txt
+
+			Check that all images are accessible before evaluating any policy, reporting all
+			inaccessible images at once, or only run that check:
+
+			  ec validate image --images my-app.yaml --policy my-policy --preflight
+			  ec validate image --images my-app.yaml --preflight-only
+
+			Report on the manifests of the images, and the signatures and attestations attached
+			to them, without evaluating the policy:
+
+			  ec validate image --images my-app.yaml --introspect --output text
+
+			Validate an image with the policy bundle embedded into ec at build time, without
+			fetching any policy source:
+
+			  ec validate image --image registry/name:tag --policy builtin --public-key key.pub
+
+			Fail if the content of the policy source changed since it was reviewed:
+
+			  ec validate image --image registry/name:tag --policy my-policy \
+			    --expect-policy-digest github.com/org/policy//release=sha256:<hex>
+
+			Validate only the images that changed since the snapshot of the previous release:
+
+			  ec validate image --images my-app.yaml --policy my-policy \
+			    --changed-since previous-snapshot.yaml
+
+			Fetch the images, their signatures and attestations from a pull-through cache while
+			reporting and verifying the upstream image references:
+
+			  ec validate image --image quay.io/org/name:tag --policy my-policy \
+			    --registry-rewrite quay.io=mirror.example.com
+
+			Fail only on violations and warnings of rules annotated with a high or critical severity:
+
+			  ec validate image --image registry/name:tag --policy my-policy --fail-on-severity high
+
+			Fail only on new violations, recording the current violations as known the first time:
+
+			  ec validate image --images my-app.yaml --policy my-policy \
+			    --known-violations known.yaml --update-known-violations
+			  ec validate image --images my-app.yaml --policy my-policy --known-violations known.yaml
+
+			Forward a JSON summary of the results to a remote log collector over TLS:
+
+			  ec validate image --image registry/name:tag --policy my-policy \
+			    --log-collector tcp://collector.example.com:6514 --log-collector-ca /path/to/ca.pem
+
+			Require the SBOM attestations to describe the image and its layers:
+
+			  ec validate image --image registry/name:tag --policy my-policy --verify-sbom-consistency
+
+			Require the images to be built from an approved base image:
+
+			  ec validate image --image registry/name:tag --policy my-policy.yaml \
+			    --allowed-base-image 'registry.access.redhat.com/ubi9/*'
+
+			Allow only OCI image manifests and layers, rejecting images with any other media type:
+
+			  ec validate image --image registry/name:tag --policy my-policy.yaml \
+			    --allowed-media-type application/vnd.oci.image.manifest.v1+json \
+			    --allowed-media-type application/vnd.oci.image.config.v1+json \
+			    --allowed-media-type 'application/vnd.oci.image.layer.v1.*'
+
+			Take an inventory of the images in a snapshot lacking signatures or attestations, without
+			failing the command:
+
+			  ec validate image --images my-app.yaml --report-unsigned --output text
+
 			Write output in JSON format to a file
 
 			  ec validate image --image registry/name:tag --output json=<path>
@@ -167,10 +457,32 @@ func validateImageCmd(validate imageValidationFunc) *cobra.Command {
 
 			  ec validate image --image registry/name:tag --output yaml --output appstudio=<path>
 
+			Write output in JSON format to both stdout and a file
+
+			  ec validate image --image registry/name:tag --output json --output json=<path>
+
 			Write the data used in the policy evaluation to a file in YAML format
 
 			  ec validate image --image registry/name:tag --output data=<path>
 
+			Write the CycloneDX SBOMs, embedded in the verified attestations of the images, to a
+			directory, one file per image named after the image digest, e.g. sha256-<hex>.json.
+			Use the spdx format for SBOMs in the SPDX format. Without a directory the SBOMs are
+			written to the standard output, one SBOM per line
+
+			  ec validate image --image registry/name:tag --output cyclonedx=<directory>
+
+			Write the violations and warnings to a file in CSV format, one row per result. Cells
+			starting with =, +, - or @ are prefixed with ' so that spreadsheets do not evaluate
+			them as formulas:
+
+			  ec validate image --image registry/name:tag --output csv=<path>
+
+			Write the report in a custom format implemented by an external program
+
+			  ec validate image --image registry/name:tag --formatter-plugin custom=/usr/local/bin/custom-report \
+			    --output custom=<path>
+
 			Validate a single image with keyless workflow.
 
 			  ec validate image --image registry/name:tag --policy my-policy \
@@ -188,16 +500,369 @@ func validateImageCmd(validate imageValidationFunc) *cobra.Command {
 
 		PreRunE: func(cmd *cobra.Command, args []string) (allErrors error) {
 			ctx := cmd.Context()
-			if s, err := applicationsnapshot.DetermineInputSpec(ctx, applicationsnapshot.Input{
-				File:     data.filePath,
-				JSON:     data.input,
-				Image:    data.imageRef,
-				Snapshot: data.snapshot,
-				Images:   data.images,
+			if data.mergeSnapshots != "" {
+				if err := applicationsnapshot.ValidateMergeSnapshots(data.mergeSnapshots); err != nil {
+					allErrors = multierror.Append(allErrors, err)
+				}
+			}
+			if s, p, err := applicationsnapshot.DetermineInput(ctx, applicationsnapshot.Input{
+				File:                data.filePath,
+				JSON:                data.input,
+				Image:               data.imageRef,
+				ImageTagConstraints: data.imageTagConstraints,
+				Snapshot:            data.snapshot,
+				Images:              data.images,
+				DigestFile:          data.digestFile,
+				HelmChart:           data.helmChart,
+				HelmValues:          data.helmValues,
+				MergeSnapshots:      data.mergeSnapshots,
+				FailOnDuplicate:     data.failOnDuplicate,
 			}); err != nil {
 				allErrors = multierror.Append(allErrors, err)
 			} else {
 				data.spec = s
+				data.componentPolicies = p
+			}
+
+			// Replacing the sources of the policy discards the policy rules, the
+			// snapshot may not be as trusted as the policy, so it is opt-in
+			if !data.allowPolicyReplace {
+				images := make([]string, 0, len(data.componentPolicies))
+				for image, p := range data.componentPolicies {
+					if p.Mode == applicationsnapshot.PolicyReplace {
+						images = append(images, image)
+					}
+				}
+				sort.Strings(images)
+				for _, image := range images {
+					allErrors = multierror.Append(allErrors, fmt.Errorf("the policy of the component with image %s replaces the policy sources, which requires --allow-policy-replace", image))
+				}
+			}
+
+			if _, err := evaluator.ParseDataMergeStrategy(data.dataMergeStrategy); err != nil {
+				allErrors = multierror.Append(allErrors, err)
+			}
+
+			if data.logCollector != "" {
+				if c, err := applicationsnapshot.ParseCollector(data.logCollector, data.logCollectorCA); err != nil {
+					allErrors = multierror.Append(allErrors, err)
+				} else {
+					data.collector = &c
+				}
+			} else if data.logCollectorCA != "" || data.logCollectorRequired {
+				allErrors = multierror.Append(allErrors, errors.New("--log-collector-ca and --log-collector-required require --log-collector"))
+			}
+
+			if data.signOutput {
+				if data.outputSigningKey == "" {
+					allErrors = multierror.Append(allErrors, errors.New("--sign-output requires --output-signing-key"))
+				} else if s, err := applicationsnapshot.NewOutputSigner(ctx, data.outputSigningKey); err != nil {
+					allErrors = multierror.Append(allErrors, err)
+				} else {
+					data.outputSigner = s
+				}
+			} else if data.outputSigningKey != "" {
+				allErrors = multierror.Append(allErrors, errors.New("--output-signing-key requires --sign-output"))
+			}
+
+			if data.resources != "" {
+				if p, ok := resourceProfiles[data.resources]; !ok {
+					allErrors = multierror.Append(allErrors, fmt.Errorf("unknown resources profile %q, expecting one of: %s", data.resources, strings.Join(resourceProfileNames(), ", ")))
+				} else {
+					// The individual flags take precedence over the profile
+					if !cmd.Flags().Changed("workers") {
+						data.workers = p.workers
+					}
+					if !cmd.Flags().Changed("fetch-concurrency") {
+						data.fetchConcurrency = p.fetchConcurrency
+					}
+					if !cmd.Flags().Changed("eval-memory-limit") {
+						data.evalMemoryLimit = p.evalMemoryLimit
+					}
+					if !cmd.Flags().Changed("max-input-size") {
+						data.maxInputSize = p.maxInputSize
+					}
+				}
+			}
+			if data.workers < 1 {
+				allErrors = multierror.Append(allErrors, fmt.Errorf("invalid number of workers %d, expecting at least 1", data.workers))
+			}
+			if data.fetchConcurrency < 1 {
+				allErrors = multierror.Append(allErrors, fmt.Errorf("invalid fetch concurrency %d, expecting at least 1", data.fetchConcurrency))
+			}
+			if data.evalMemoryLimit != "" {
+				if q, err := resource.ParseQuantity(data.evalMemoryLimit); err != nil || q.Sign() <= 0 {
+					allErrors = multierror.Append(allErrors, fmt.Errorf("invalid evaluation memory limit %q, expecting a positive quantity, e.g. 512Mi", data.evalMemoryLimit))
+				} else {
+					data.evalBudget.MemoryLimit = q.Value()
+				}
+			}
+			if data.maxInputSize != "" {
+				if q, err := resource.ParseQuantity(data.maxInputSize); err != nil || q.Sign() <= 0 {
+					allErrors = multierror.Append(allErrors, fmt.Errorf("invalid maximum input size %q, expecting a positive quantity, e.g. 64Mi", data.maxInputSize))
+				} else {
+					data.maxInputSizeBytes = q.Value()
+				}
+			}
+			if data.evalTimeout < 0 {
+				allErrors = multierror.Append(allErrors, fmt.Errorf("invalid evaluation timeout %s, expecting a positive duration", data.evalTimeout))
+			}
+			data.evalBudget.Timeout = data.evalTimeout
+
+			if err := source.ValidateMissingSourceMode(data.missingSource); err != nil {
+				allErrors = multierror.Append(allErrors, err)
+			}
+
+			if len(data.helmValues) > 0 && data.helmChart == "" {
+				allErrors = multierror.Append(allErrors, errors.New("--values requires --helm-chart"))
+			}
+
+			if data.tui && data.watchPolicy {
+				allErrors = multierror.Append(allErrors, errors.New("--tui cannot be used with --watch-policy"))
+			}
+
+			if data.updateKnownViolations {
+				if data.knownViolationsFile == "" {
+					allErrors = multierror.Append(allErrors, errors.New("--update-known-violations requires --known-violations"))
+				}
+			} else if data.knownViolationsFile != "" {
+				if k, err := applicationsnapshot.ReadKnownViolations(utils.FS(ctx), data.knownViolationsFile); err != nil {
+					allErrors = multierror.Append(allErrors, err)
+				} else {
+					data.knownViolations = k
+				}
+			}
+
+			if data.messageOverrideFile != "" {
+				if m, err := applicationsnapshot.ReadMessageOverrides(utils.FS(ctx), data.messageOverrideFile); err != nil {
+					allErrors = multierror.Append(allErrors, err)
+				} else {
+					data.messageOverrides = m
+				}
+			}
+
+			if r, err := applicationsnapshot.ParseRedactions(data.redact); err != nil {
+				allErrors = multierror.Append(allErrors, err)
+			} else {
+				data.redactions = r
+			}
+
+			if d, err := source.ParseExpectedDigests(data.expectPolicyDigest); err != nil {
+				allErrors = multierror.Append(allErrors, err)
+			} else {
+				data.expectedPolicyDigests = d
+			}
+
+			if data.changedSince != "" {
+				if p, err := applicationsnapshot.ReadPreviousSnapshot(utils.FS(ctx), data.changedSince); err != nil {
+					allErrors = multierror.Append(allErrors, err)
+				} else {
+					data.previousSnapshot = &p
+				}
+			}
+
+			if r, err := oci.ParseRegistryRewrites(data.registryRewrite); err != nil {
+				allErrors = multierror.Append(allErrors, err)
+			} else {
+				data.registryRewrites = r
+			}
+
+			if data.traceFile != "" && len(data.traceRules) == 0 && len(data.traceImages) == 0 {
+				allErrors = multierror.Append(allErrors, errors.New("--trace-file requires --trace-rule or --trace-image"))
+			}
+
+			// --strict=false is the same as --fail-on never
+			if err := applicationsnapshot.ValidateFailOn(data.failOn); err != nil {
+				allErrors = multierror.Append(allErrors, err)
+			} else if !data.strict {
+				if cmd.Flags().Changed("fail-on") && data.failOn != applicationsnapshot.FailOnNever {
+					allErrors = multierror.Append(allErrors, fmt.Errorf("--fail-on %s cannot be used with --strict=false", data.failOn))
+				}
+				data.failOn = applicationsnapshot.FailOnNever
+			}
+
+			if data.failOnSeverity != "" {
+				if err := evaluator.ValidateSeverity(data.failOnSeverity); err != nil {
+					allErrors = multierror.Append(allErrors, fmt.Errorf("invalid --fail-on-severity value: %w", err))
+				}
+				if cmd.Flags().Changed("fail-on") || !data.strict {
+					allErrors = multierror.Append(allErrors, errors.New("--fail-on-severity cannot be used with --fail-on or --strict=false"))
+				}
+			}
+
+			for _, mt := range append(append([]string{}, data.allowedMediaTypes...), data.deniedMediaTypes...) {
+				if _, err := path.Match(mt, ""); err != nil {
+					allErrors = multierror.Append(allErrors, fmt.Errorf("invalid media type pattern %q: %w", mt, err))
+				}
+			}
+
+			for _, b := range data.allowedBaseImages {
+				if _, err := path.Match(b, ""); err != nil {
+					allErrors = multierror.Append(allErrors, fmt.Errorf("invalid base image pattern %q: %w", b, err))
+				}
+			}
+
+			for _, ids := range [][]string{data.slsaBuilderIDs, data.allowedBuilderIDs} {
+				for _, b := range ids {
+					if _, err := image.CompileBuilderIDPattern(b); err != nil {
+						allErrors = multierror.Append(allErrors, fmt.Errorf("invalid builder ID pattern %q: %w", b, err))
+					}
+				}
+			}
+
+			for _, l := range data.requiredLabel {
+				if r, err := image.ParseRequiredLabel(l); err != nil {
+					allErrors = multierror.Append(allErrors, fmt.Errorf("invalid required label %q: %w", l, err))
+				} else {
+					data.requiredLabels = append(data.requiredLabels, r)
+				}
+			}
+
+			if data.maxImageAge != "" {
+				if age, err := image.ParseMaxImageAge(data.maxImageAge); err != nil {
+					allErrors = multierror.Append(allErrors, err)
+				} else {
+					data.maxImageAgeDuration = age
+				}
+			}
+
+			for _, p := range data.pinDigest {
+				if pin, err := image.ParsePinnedDigest(p); err != nil {
+					allErrors = multierror.Append(allErrors, err)
+				} else {
+					data.pinnedDigests = append(data.pinnedDigests, pin)
+				}
+			}
+
+			for _, d := range data.signatureDigest {
+				if sig, err := image.ParseSignatureDigest(d, data.imageRef); err != nil {
+					allErrors = multierror.Append(allErrors, err)
+				} else {
+					data.signatureDigests = append(data.signatureDigests, sig)
+				}
+			}
+
+			if denied, err := image.ParseOperatorDigests(ctx, data.denyDigest); err != nil {
+				allErrors = multierror.Append(allErrors, fmt.Errorf("invalid --deny-digest: %w", err))
+			} else {
+				data.operatorDigests.Denied = denied
+			}
+
+			if allowed, err := image.ParseOperatorDigests(ctx, data.allowDigest); err != nil {
+				allErrors = multierror.Append(allErrors, fmt.Errorf("invalid --allow-digest: %w", err))
+			} else {
+				data.operatorDigests.Allowed = allowed
+			}
+
+			if err := image.ValidateAttestationPolicy(data.attestationPolicy); err != nil {
+				allErrors = multierror.Append(allErrors, err)
+			}
+
+			for _, spec := range data.formatterPlugins {
+				name, f, err := applicationsnapshot.ParseFormatterPlugin(spec)
+				if err == nil {
+					err = applicationsnapshot.RegisterFormatter(name, f)
+				}
+				if err != nil {
+					allErrors = multierror.Append(allErrors, err)
+				}
+			}
+
+			if data.policyLabelConfig != "" {
+				if l, err := policy.ReadLabelPolicies(ctx, data.policyLabelConfig); err != nil {
+					allErrors = multierror.Append(allErrors, err)
+				} else {
+					data.labelPolicies = l
+				}
+			} else if data.requirePolicyLabel {
+				allErrors = multierror.Append(allErrors, errors.New("--require-policy-label requires --policy-label-config"))
+			}
+
+			if data.rekorTimeWindow < 0 {
+				allErrors = multierror.Append(allErrors, fmt.Errorf("invalid transparency log time window %s, expecting a positive duration", data.rekorTimeWindow))
+			} else if data.rekorTimeWindow > 0 && data.ignoreRekor {
+				allErrors = multierror.Append(allErrors, errors.New("--rekor-time-window cannot be used with --ignore-rekor"))
+			}
+
+			if data.minSLSALevel < 0 || data.minSLSALevel > image.MaxSLSALevel {
+				allErrors = multierror.Append(allErrors, fmt.Errorf("invalid minimum SLSA level %d, expecting a level between 0 and %d", data.minSLSALevel, image.MaxSLSALevel))
+			}
+
+			if data.resultCacheTTL <= 0 {
+				allErrors = multierror.Append(allErrors, fmt.Errorf("invalid result cache time to live %s, expecting a positive duration", data.resultCacheTTL))
+			}
+
+			if data.retryBudget < 0 {
+				allErrors = multierror.Append(allErrors, fmt.Errorf("invalid retry budget %s, expecting a positive duration", data.retryBudget))
+			}
+
+			if cosigners, err := attestation.LoadCosigners(ctx, data.attestationCosignerKeys); err != nil {
+				allErrors = multierror.Append(allErrors, err)
+			} else if data.requiredAttestationSigners < 0 || data.requiredAttestationSigners > len(cosigners)+1 {
+				allErrors = multierror.Append(allErrors, fmt.Errorf("invalid number of required attestation signers %d, expecting 0 to %d", data.requiredAttestationSigners, len(cosigners)+1))
+			} else {
+				cmd.SetContext(attestation.WithCosignerOptions(cmd.Context(), attestation.CosignerOptions{
+					Cosigners: cosigners,
+					Required:  data.requiredAttestationSigners,
+				}))
+			}
+
+			if data.allowNetworkBuiltins {
+				if len(data.builtinAllowedHosts) == 0 {
+					allErrors = multierror.Append(allErrors, errors.New("--allow-network-builtins requires --builtin-allowed-host"))
+				}
+				// OPA matches only the host name of a request against the
+				// allowed hosts, a port would never match
+				for _, h := range data.builtinAllowedHosts {
+					if _, _, err := net.SplitHostPort(h); err == nil || strings.Contains(h, "/") {
+						allErrors = multierror.Append(allErrors, fmt.Errorf("invalid built-in allowed host %q, expecting a host name without a scheme, port or path", h))
+					}
+				}
+				if data.builtinTimeout <= 0 {
+					allErrors = multierror.Append(allErrors, fmt.Errorf("invalid built-in timeout %s, expecting a positive duration", data.builtinTimeout))
+				}
+			} else if len(data.builtinAllowedHosts) > 0 {
+				allErrors = multierror.Append(allErrors, errors.New("--builtin-allowed-host requires --allow-network-builtins"))
+			}
+
+			if data.minKeySize < 0 {
+				allErrors = multierror.Append(allErrors, fmt.Errorf("invalid minimum key size %d, expecting a positive number of bits", data.minKeySize))
+			}
+
+			knownAlgorithms := signature.SignatureAlgorithms()
+			for _, a := range data.allowedSignatureAlgorithms {
+				if !slices.ContainsFunc(knownAlgorithms, func(k string) bool { return strings.EqualFold(k, a) }) {
+					allErrors = multierror.Append(allErrors, fmt.Errorf("unknown signature algorithm %q, expecting one of: %s", a, strings.Join(knownAlgorithms, ", ")))
+				}
+			}
+
+			for _, c := range data.policyConfig {
+				name, config, err := applicationsnapshot.ParsePolicyConfig(c)
+				if err != nil {
+					allErrors = multierror.Append(allErrors, err)
+					continue
+				}
+				if _, ok := data.policyConfigs[name]; ok {
+					allErrors = multierror.Append(allErrors, fmt.Errorf("the policy configuration %q is given more than once", name))
+					continue
+				}
+
+				config, err = validate_utils.GetPolicyConfig(ctx, config)
+				if err != nil {
+					allErrors = multierror.Append(allErrors, fmt.Errorf("unable to load the policy configuration %q: %w", name, err))
+					continue
+				}
+				p, err := policy.NewInertPolicy(ctx, config)
+				if err != nil {
+					allErrors = multierror.Append(allErrors, fmt.Errorf("unable to load the policy configuration %q: %w", name, err))
+					continue
+				}
+
+				if data.policyConfigs == nil {
+					data.policyConfigs = map[string][]ecc.Source{}
+				}
+				data.policyConfigs[name] = p.Spec().Sources
+				data.policyConfigNames = append(data.policyConfigNames, name)
 			}
 
 			policyConfiguration, err := validate_utils.GetPolicyConfig(ctx, data.policyConfiguration)
@@ -215,10 +880,11 @@ func validateImageCmd(validate imageValidationFunc) *cobra.Command {
 					Subject:       data.certificateIdentity,
 					SubjectRegExp: data.certificateIdentityRegExp,
 				},
-				IgnoreRekor: data.ignoreRekor,
-				PolicyRef:   data.policyConfiguration,
-				PublicKey:   data.publicKey,
-				RekorURL:    data.rekorURL,
+				IgnoreRekor:  data.ignoreRekor,
+				PolicyRef:    data.policyConfiguration,
+				PublicKey:    data.publicKey,
+				RekorURL:     data.rekorURL,
+				TSACertChain: data.tsaCertChain,
 			}); err != nil {
 				allErrors = multierror.Append(allErrors, err)
 			} else {
@@ -287,138 +953,577 @@ func validateImageCmd(validate imageValidationFunc) *cobra.Command {
 			}
 
 			appComponents := data.spec.Components
-			evaluators := []evaluator.Evaluator{}
 
-			// Return an evaluator for each of these
-			for _, sourceGroup := range data.policy.Spec().Sources {
-				// Todo: Make each fetch run concurrently
-				log.Debugf("Fetching policy source group '%s'", sourceGroup.Name)
-				policySources, err := source.FetchPolicySources(sourceGroup)
-				if err != nil {
-					log.Debugf("Failed to fetch policy source group '%s'!", sourceGroup.Name)
-					return err
+			// Validated in PreRunE
+			mergeStrategy, _ := evaluator.ParseDataMergeStrategy(data.dataMergeStrategy)
+			cmd.SetContext(evaluator.WithDataMergeStrategy(cmd.Context(), mergeStrategy))
+			cmd.SetContext(evaluator.WithStrictRego(cmd.Context(), data.strictRego))
+			cmd.SetContext(evaluator.WithEvaluationBudget(cmd.Context(), data.evalBudget))
+			cmd.SetContext(evaluator.WithParallelEvaluation(cmd.Context(), data.parallelPolicyEval))
+			cmd.SetContext(source.WithMissingSources(cmd.Context(), data.missingSource))
+			cmd.SetContext(source.WithFetchConcurrency(cmd.Context(), data.fetchConcurrency))
+			// The sources fetched over HTTPS are cached alongside the results,
+			// and fetched with conditional requests
+			if dir := os.Getenv(image.ResultCacheDirEnv); dir != "" {
+				if v, err := strconv.ParseBool(os.Getenv("EC_CACHE")); err != nil || v {
+					cmd.SetContext(source.WithHTTPCache(cmd.Context(), path.Join(dir, "http")))
 				}
-
-				for _, policySource := range policySources {
-					log.Debugf("policySource: %#v", policySource)
+			}
+			cmd.SetContext(oci.WithRegistryRewrites(cmd.Context(), data.registryRewrites))
+			if data.allowNetworkBuiltins {
+				log.Warnf("Network built-in functions enabled, the policies can send requests to: %s", strings.Join(data.builtinAllowedHosts, ", "))
+				cmd.SetContext(evaluator.WithNetworkBuiltins(cmd.Context(), evaluator.NetworkBuiltins{
+					AllowedHosts: data.builtinAllowedHosts,
+					Timeout:      data.builtinTimeout,
+				}))
+			}
+			if len(data.traceRules) > 0 || len(data.traceImages) > 0 {
+				var w io.Writer = cmd.ErrOrStderr()
+				if data.traceFile != "" {
+					f, err := utils.FS(cmd.Context()).Create(data.traceFile)
+					if err != nil {
+						return fmt.Errorf("unable to create the trace file: %w", err)
+					}
+					defer f.Close()
+					w = f
 				}
+				cmd.SetContext(evaluator.WithTraceOptions(cmd.Context(), evaluator.TraceOptions{
+					Rules:  data.traceRules,
+					Images: data.traceImages,
+					Writer: w,
+				}))
+			}
+			cmd.SetContext(image.WithSLSAOptions(cmd.Context(), image.SLSAOptions{
+				MinLevel:   data.minSLSALevel,
+				BuilderIDs: data.slsaBuilderIDs,
+			}))
+
+			cmd.SetContext(image.WithBaseImageOptions(cmd.Context(), image.BaseImageOptions{
+				Allowed: data.allowedBaseImages,
+			}))
+			cmd.SetContext(image.WithBuilderIDOptions(cmd.Context(), image.BuilderIDOptions{
+				Allowed: data.allowedBuilderIDs,
+			}))
+			cmd.SetContext(image.WithFetchOptions(cmd.Context(), image.FetchOptions{
+				NoMetadataReuse: !data.reuseImageMetadata,
+			}))
+			cmd.SetContext(image.WithSigningKeyOptions(cmd.Context(), image.SigningKeyOptions{
+				MinRSAKeySize:     data.minKeySize,
+				AllowedAlgorithms: data.allowedSignatureAlgorithms,
+			}))
+			cmd.SetContext(image.WithRekorTimeOptions(cmd.Context(), image.RekorTimeOptions{
+				MaxDelta: data.rekorTimeWindow,
+			}))
+			cmd.SetContext(image.WithSBOMConsistencyOptions(cmd.Context(), image.SBOMConsistencyOptions{
+				Enabled: data.verifySBOMConsistency,
+			}))
+			cmd.SetContext(image.WithAttestationConflictOptions(cmd.Context(), image.AttestationConflictOptions{
+				Enabled: data.failOnAttestationConflict,
+			}))
+			cmd.SetContext(image.WithSubjectConsistencyOptions(cmd.Context(), image.SubjectConsistencyOptions{
+				Enabled: data.verifySubjectConsistency,
+			}))
+			cmd.SetContext(image.WithRequiredLabelOptions(cmd.Context(), image.RequiredLabelOptions{
+				Labels: data.requiredLabels,
+			}))
+			cmd.SetContext(image.WithInputSizeOptions(cmd.Context(), image.InputSizeOptions{
+				MaxSize: data.maxInputSizeBytes,
+			}))
+			cmd.SetContext(image.WithImageAgeOptions(cmd.Context(), image.ImageAgeOptions{
+				MaxAge: data.maxImageAgeDuration,
+			}))
+			cmd.SetContext(image.WithMediaTypeOptions(cmd.Context(), image.MediaTypeOptions{
+				Allowed: data.allowedMediaTypes,
+				Denied:  data.deniedMediaTypes,
+			}))
+			cmd.SetContext(image.WithPinnedDigestOptions(cmd.Context(), image.PinnedDigestOptions{
+				Pins: data.pinnedDigests,
+			}))
+			cmd.SetContext(image.WithSignatureDigestOptions(cmd.Context(), image.SignatureDigestOptions{
+				Digests: data.signatureDigests,
+			}))
+			cmd.SetContext(image.WithOperatorDigestOptions(cmd.Context(), data.operatorDigests))
+			cmd.SetContext(image.WithAttestationPolicy(cmd.Context(), data.attestationPolicy))
+			cmd.SetContext(attestation.WithAllowedPayloadTypes(cmd.Context(), data.allowedPayloadTypes))
+			cmd.SetContext(attestation.WithNormalization(cmd.Context(), data.normalizeAttestations))
+
+			// Only the images changed since the previous snapshot are validated,
+			// the unchanged ones are reported as skipped
+			var unchanged []app.SnapshotComponent
+			filters := []applicationsnapshot.FilterStep{{Name: "snapshot", Images: len(appComponents)}}
+			if data.previousSnapshot != nil {
+				appComponents, unchanged = applicationsnapshot.Changed(appComponents, *data.previousSnapshot)
+				log.Infof("Validating %d changed images, skipping %d unchanged images", len(appComponents), len(unchanged))
+				filters = append(filters, applicationsnapshot.FilterStep{Name: "--changed-since " + data.changedSince, Images: len(appComponents)})
+			}
 
-				c, err := newConftestEvaluator(cmd.Context(), policySources, data.policy, sourceGroup)
-				if err != nil {
-					log.Debug("Failed to initialize the conftest evaluator!")
+			if data.failOnEmptySnapshot && len(appComponents) == 0 {
+				return root.ExitCodeError{Code: root.ExitError, Err: applicationsnapshot.EmptySnapshotError{Steps: filters}}
+			}
+
+			if data.preflight || data.preflightOnly {
+				if err := applicationsnapshot.Preflight(cmd.Context(), appComponents); err != nil {
 					return err
 				}
+				log.Infof("All %d images are accessible", len(appComponents))
+				if data.preflightOnly {
+					fmt.Fprintf(cmd.OutOrStdout(), "All %d images are accessible\n", len(appComponents))
+					return nil
+				}
+			}
 
-				evaluators = append(evaluators, c)
-				defer c.Destroy()
+			if len(data.outputFile) > 0 {
+				data.output = append(data.output, fmt.Sprintf("%s=%s", applicationsnapshot.JSON, data.outputFile))
 			}
 
-			showSuccesses, _ := cmd.Flags().GetBool("show-successes")
+			if data.introspect {
+				var report image.IntrospectionReport
+				for _, c := range appComponents {
+					report.Images = append(report.Images, image.Introspect(cmd.Context(), c.ContainerImage))
+				}
+				jsonCompact, _ := cmd.Flags().GetBool("json-compact")
+				p := format.NewTargetParser(applicationsnapshot.JSON, format.Options{JSONCompact: jsonCompact}, cmd.OutOrStdout(), utils.FS(cmd.Context())).WithContext(cmd.Context())
+				return report.WriteAll(data.output, p)
+			}
 
-			// worker is responsible for processing one component at a time from the jobs channel,
-			// and for emitting a corresponding result for the component on the results channel.
-			worker := func(id int, jobs <-chan app.SnapshotComponent, results chan<- result) {
-				log.Debugf("Starting worker %d", id)
-				for comp := range jobs {
-					log.Debugf("Worker %d got a component %q", id, comp.ContainerImage)
-					ctx := cmd.Context()
-					out, err := validate(ctx, comp, data.spec, data.policy, evaluators, data.info)
-					res := result{
-						err: err,
-						component: applicationsnapshot.Component{
-							SnapshotComponent: comp,
-							Success:           err == nil,
-						},
+			// run evaluates the policy against all the components and writes the
+			// report. It is invoked again on every change when watching the policy.
+			run := func() error {
+				// The evaluators, and the policy sources downloaded to their
+				// work directories, do not outlive the run
+				var created []evaluator.Evaluator
+				defer func() {
+					for _, e := range created {
+						e.Destroy()
 					}
+					source.ClearDownloadCache()
+					source.ResetSkippedSources()
+				}()
+
+				// The retry budget is shared by all requests of a run
+				var retryBudget *oci.RetryBudget
+				if data.retryBudget > 0 {
+					retryBudget = oci.NewRetryBudget(data.retryBudget)
+					cmd.SetContext(oci.WithRetryBudget(cmd.Context(), retryBudget))
+				}
+
+				// newEvaluators returns an evaluator for each of the source groups,
+				// the results of which are attributed to the named policy
+				// configuration when validating with multiple ones
+				newEvaluators := func(name string, sourceGroups []ecc.Source) ([]evaluator.Evaluator, error) {
+					evaluators := []evaluator.Evaluator{}
+					for _, sourceGroup := range sourceGroups {
+						// Todo: Make each fetch run concurrently
+						log.Debugf("Fetching policy source group '%s'", sourceGroup.Name)
+						policySources, err := source.FetchPolicySources(sourceGroup)
+						if err != nil {
+							log.Debugf("Failed to fetch policy source group '%s'!", sourceGroup.Name)
+							return nil, err
+						}
 
-					// Skip on err to not panic. Error is return on routine completion.
-					if err == nil {
-						res.component.Violations = out.Violations()
-						res.component.Warnings = out.Warnings()
+						for _, policySource := range policySources {
+							log.Debugf("policySource: %#v", policySource)
+						}
 
-						successes := out.Successes()
-						res.component.SuccessCount = len(successes)
-						if showSuccesses {
-							res.component.Successes = successes
+						c, err := newConftestEvaluator(cmd.Context(), policySources, data.policy, sourceGroup)
+						if err != nil {
+							log.Debug("Failed to initialize the conftest evaluator!")
+							return nil, err
 						}
 
-						res.component.Signatures = out.Signatures
-						res.component.Attestations = out.Attestations
-						res.component.ContainerImage = out.ImageURL
-						res.data = out.Data
-						res.component.Attestations = out.Attestations
-						res.policyInput = out.PolicyInput
+						created = append(created, c)
+						if len(data.policyConfigNames) > 0 {
+							c = evaluator.WithPolicyConfig(c, name)
+						}
+						evaluators = append(evaluators, c)
 					}
-					res.component.Success = err == nil && len(res.component.Violations) == 0
 
-					results <- res
+					return evaluators, nil
 				}
-				log.Debugf("Done with worker %d", id)
-			}
 
-			numComponents := len(appComponents)
-			// TODO: Eventually, this should either be set as a parameter or adjusted based on the
-			// available resources. The main constraint seems to be memory.
-			numWorkers := 5
+				evaluators, err := newEvaluators(applicationsnapshot.DefaultPolicyConfig, data.policy.Spec().Sources)
+				if err != nil {
+					return err
+				}
 
-			jobs := make(chan app.SnapshotComponent, numComponents)
-			results := make(chan result, numComponents)
-			// Initialize each worker. They will wait patiently until a job is sent to the jobs
-			// channel, or the jobs channel is closed.
-			for i := 0; i <= numWorkers; i++ {
-				go worker(i, jobs, results)
-			}
-			// Initialize all the jobs. Each worker will pick a job from the channel when the worker
-			// is ready to consume a new job.
-			for _, c := range appComponents {
-				jobs <- c
-			}
-			close(jobs)
+				// The sources of the named policy configurations are evaluated
+				// for all images in addition to the sources of the policy
+				var policyConfigEvaluators []evaluator.Evaluator
+				for _, name := range data.policyConfigNames {
+					e, err := newEvaluators(name, data.policyConfigs[name])
+					if err != nil {
+						return err
+					}
+					policyConfigEvaluators = append(policyConfigEvaluators, e...)
+				}
 
-			var components []applicationsnapshot.Component
-			var manyData [][]evaluator.Data
-			var manyPolicyInput [][]byte
-			var allErrors error = nil
-			for i := 0; i < numComponents; i++ {
-				r := <-results
-				if r.err != nil {
-					e := fmt.Errorf("error validating image %s of component %s: %w", r.component.ContainerImage, r.component.Name, r.err)
-					allErrors = multierror.Append(allErrors, e)
+				// The results are cached only when all images are validated
+				// using the same policy sources, and not when the policies can
+				// fetch data at evaluation time, nor when the age of the images
+				// changes the outcome over time
+				resultCacheDir := os.Getenv(image.ResultCacheDirEnv)
+				useResultCache := resultCacheDir != "" && !data.noResultCache && data.labelPolicies == nil && len(data.componentPolicies) == 0 && len(data.policyConfigNames) == 0 && !data.allowNetworkBuiltins && data.maxImageAgeDuration == 0
+				if v, err := strconv.ParseBool(os.Getenv("EC_CACHE")); err == nil && !v {
+					useResultCache = false
+				}
+
+				// The policy sources are fetched up front to verify their content,
+				// to check the required rules are defined, and to key the cached
+				// results, before any image is validated
+				validateImage := validate
+				if !data.expectedPolicyDigests.Empty() || len(data.requireRuleCode) > 0 || useResultCache {
+					fs := utils.FS(cmd.Context())
+					workDir, err := utils.CreateWorkDir(fs)
+					if err != nil {
+						return err
+					}
+					defer utils.CleanupWorkDir(fs, workDir)
+
+					if !data.expectedPolicyDigests.Empty() {
+						if err := data.expectedPolicyDigests.Verify(cmd.Context(), data.policy.Spec().Sources, workDir); err != nil {
+							return err
+						}
+					}
+
+					if err := evaluator.CheckRequiredRules(cmd.Context(), data.policy.Spec().Sources, workDir, data.requireRuleCode); err != nil {
+						return err
+					}
+
+					if useResultCache {
+						resultCache, err := newResultCache(cmd, data.policy, resultCacheDir, data.resultCacheTTL, workDir)
+						if err != nil {
+							return err
+						}
+						validateImage = resultCache.Wrap(validate)
+					}
+				}
+
+				// Components with their own policy are validated with their own
+				// evaluators, see applicationsnapshot.ComponentPolicy
+				componentEvaluators := map[string][]evaluator.Evaluator{}
+				for image, p := range data.componentPolicies {
+					log.Debugf("Using the %s component policy for image %s", p.Mode, image)
+					if componentEvaluators[image], err = newEvaluators(applicationsnapshot.DefaultPolicyConfig, p.Apply(data.policy.Spec().Sources)); err != nil {
+						return err
+					}
+				}
+
+				// Images are validated with the policy sources selected by their
+				// label, unless having their own component policy
+				labelErrors := map[string]error{}
+				if data.labelPolicies != nil {
+					labelEvaluators := map[string][]evaluator.Evaluator{}
+					for _, c := range appComponents {
+						if _, ok := componentEvaluators[c.ContainerImage]; ok {
+							continue
+						}
+
+						value, sources, err := selectLabelSources(cmd.Context(), c.ContainerImage, *data.labelPolicies)
+						if err != nil {
+							if data.requirePolicyLabel {
+								labelErrors[c.ContainerImage] = err
+							} else {
+								log.Debugf("Using the policy sources for image %s: %s", c.ContainerImage, err)
+							}
+							continue
+						}
+
+						if _, ok := labelEvaluators[value]; !ok {
+							log.Debugf("Using the policy sources for label value %q", value)
+							if labelEvaluators[value], err = newEvaluators(applicationsnapshot.DefaultPolicyConfig, sources); err != nil {
+								return err
+							}
+						}
+						componentEvaluators[c.ContainerImage] = labelEvaluators[value]
+					}
+				}
+
+				showSuccesses, _ := cmd.Flags().GetBool("show-successes")
+
+				// worker is responsible for processing one component at a time from the jobs channel,
+				// and for emitting a corresponding result for the component on the results channel.
+				worker := func(id int, jobs <-chan app.SnapshotComponent, results chan<- result) {
+					log.Debugf("Starting worker %d", id)
+					for comp := range jobs {
+						log.Debugf("Worker %d got a component %q", id, comp.ContainerImage)
+						if err, ok := labelErrors[comp.ContainerImage]; ok {
+							results <- result{component: applicationsnapshot.Component{
+								SnapshotComponent: comp,
+								Violations: []evaluator.Result{{
+									Message:  fmt.Sprintf("Policy label check failed: %s", err),
+									Metadata: map[string]any{"code": "builtin.image.policy_label"},
+								}},
+							}}
+							continue
+						}
+
+						ctx := cmd.Context()
+						e := evaluators
+						if ce, ok := componentEvaluators[comp.ContainerImage]; ok {
+							e = ce
+						}
+						if len(policyConfigEvaluators) > 0 {
+							e = append(append([]evaluator.Evaluator{}, e...), policyConfigEvaluators...)
+						}
+						out, err := validateImage(ctx, comp, data.spec, data.policy, e, data.info)
+						res := result{
+							err: err,
+							component: applicationsnapshot.Component{
+								SnapshotComponent: comp,
+								Success:           err == nil,
+							},
+						}
+
+						// A rate limited image is reported, rather than failing the
+						// whole run, so that the validation can be rerun
+						if err != nil && oci.IsRateLimited(err) {
+							log.Warnf("The registry rate limited the validation of image %s: %s", comp.ContainerImage, err)
+							res.err = nil
+							res.component.RateLimited = true
+							res.component.Success = false
+							results <- res
+							continue
+						}
+
+						// Skip on err to not panic. Error is return on routine completion.
+						if err == nil {
+							res.component.Violations = out.Violations()
+							res.component.Warnings = out.Warnings()
+
+							successes := out.Successes()
+							res.component.SuccessCount = len(successes)
+							if showSuccesses {
+								res.component.Successes = successes
+							}
+
+							res.component.Signatures = out.Signatures
+							res.component.Attestations = out.Attestations
+							res.component.ContainerImage = out.ImageURL
+							res.component.ImageURL = out.ImageURL
+							res.data = out.Data
+							res.component.Attestations = out.Attestations
+							res.policyInput = out.PolicyInput
+							res.component.SLSALevel = out.SLSALevel
+							res.component.BaseImage = out.BaseImage
+							res.component.BuilderIDs = out.BuilderIDs
+							res.component.RekorIntegratedTime = out.RekorIntegratedTime
+							res.component.BuildFinishedOn = out.BuildFinishedOn
+							res.component.SigningKeys = out.SigningKeys
+							res.component.SignerIdentities = out.SignerIdentities
+							res.component.SigningTimes = out.SigningTimes
+							res.component.MissingLabels = out.MissingLabels
+							res.component.DigestPin = out.DigestPin
+							res.component.AttestationConflicts = out.AttestationConflicts
+							res.component.SubjectDigests = out.SubjectDigests
+							res.component.OperatorDecision = out.OperatorDecision
+							res.component.AttestationsPresent = out.AttestationsPresent
+							res.component.ImageCreated = out.ImageCreated
+							res.component.PolicyInputSize = out.PolicyInputSize
+							if out.Verification != (output.Verification{}) {
+								res.component.Verification = &out.Verification
+							}
+							res.component.RateLimited = out.Verification.RateLimited()
+						}
+						res.component.Success = err == nil && len(res.component.Violations) == 0
+
+						results <- res
+					}
+					log.Debugf("Done with worker %d", id)
+				}
+
+				numComponents := len(appComponents)
+				numWorkers := data.workers
+
+				jobs := make(chan app.SnapshotComponent, numComponents)
+				results := make(chan result, numComponents)
+				// Initialize each worker. They will wait patiently until a job is sent to the jobs
+				// channel, or the jobs channel is closed.
+				for i := 0; i < numWorkers; i++ {
+					go worker(i, jobs, results)
+				}
+				// Initialize all the jobs. Each worker will pick a job from the channel when the worker
+				// is ready to consume a new job.
+				for _, c := range appComponents {
+					jobs <- c
+				}
+				close(jobs)
+
+				var components []applicationsnapshot.Component
+				var manyData [][]evaluator.Data
+				var manyPolicyInput [][]byte
+				policyInputs := map[string][]byte{}
+				var allErrors error = nil
+				for i := 0; i < numComponents; i++ {
+					r := <-results
+					if r.err != nil {
+						e := fmt.Errorf("error validating image %s of component %s: %w", r.component.ContainerImage, r.component.Name, r.err)
+						allErrors = multierror.Append(allErrors, e)
+					} else {
+						components = append(components, r.component)
+						manyData = append(manyData, r.data)
+						manyPolicyInput = append(manyPolicyInput, r.policyInput)
+						if r.policyInput != nil {
+							policyInputs[r.component.ContainerImage] = r.policyInput
+						}
+					}
+				}
+				close(results)
+				if allErrors != nil {
+					return allErrors
+				}
+
+				for _, c := range unchanged {
+					components = append(components, applicationsnapshot.Component{
+						SnapshotComponent: c,
+						Success:           true,
+						Skipped:           applicationsnapshot.SkippedUnchanged,
+					})
+				}
+
+				// Ensure some consistency in output.
+				sort.Slice(components, func(i, j int) bool {
+					return components[i].ContainerImage > components[j].ContainerImage
+				})
+
+				// Updating the known violations records all current violations
+				// as known
+				if data.updateKnownViolations {
+					data.knownViolations = applicationsnapshot.NewKnownViolations(components)
+					if err := data.knownViolations.Write(utils.FS(cmd.Context()), data.knownViolationsFile); err != nil {
+						return fmt.Errorf("unable to write the known violations: %w", err)
+					}
+					log.Infof("Recorded %d known violations in %s", len(data.knownViolations), data.knownViolationsFile)
+				}
+				data.knownViolations.Apply(components)
+				if len(data.policyConfigNames) > 0 {
+					applicationsnapshot.ApplyPolicyConfigs(components, append([]string{applicationsnapshot.DefaultPolicyConfig}, data.policyConfigNames...), data.requireAll)
+				}
+				data.messageOverrides.Apply(components)
+				redacted := data.redactions.Apply(components)
+				if data.normalizeDigests {
+					applicationsnapshot.NormalizeReferences(components)
+				}
+
+				if data.dumpInput != "" {
+					if err := applicationsnapshot.DumpInputs(utils.FS(cmd.Context()), data.dumpInput, policyInputs, data.redactions); err != nil {
+						return fmt.Errorf("unable to write the input documents: %w", err)
+					}
+					log.Infof("Wrote the input documents of %d image(s) to %s", len(policyInputs), data.dumpInput)
+				}
+
+				report, err := applicationsnapshot.NewReport(data.snapshot, components, data.policy, manyData, manyPolicyInput, showSuccesses)
+				if err != nil {
+					return err
+				}
+				if !data.noProvenance {
+					if report.Provenance, err = applicationsnapshot.NewProvenance(components, data.policy); err != nil {
+						return err
+					}
+				}
+				if data.reportUnsigned || data.failOnUnsigned {
+					report.SignatureCoverage = applicationsnapshot.NewSignatureCoverage(components)
+					log.Infof("Signature coverage: %s", report.SignatureCoverage.Summary)
+				}
+
+				report.Redacted = redacted
+				report.SkippedSources = source.SkippedSources()
+				report.OutputSigner = data.outputSigner
+				report.OutputChecksums = data.outputChecksums
+				report.RetryBudgetExhausted = retryBudget.Exhausted()
+
+				if data.failOnSeverity != "" {
+					report.FailOnSeverity = data.failOnSeverity
 				} else {
-					components = append(components, r.component)
-					manyData = append(manyData, r.data)
-					manyPolicyInput = append(manyPolicyInput, r.policyInput)
+					report.FailOn = data.failOn
 				}
-			}
-			close(results)
-			if allErrors != nil {
-				return allErrors
-			}
 
-			// Ensure some consistency in output.
-			sort.Slice(components, func(i, j int) bool {
-				return components[i].ContainerImage > components[j].ContainerImage
-			})
+				jsonCompact, _ := cmd.Flags().GetBool("json-compact")
+				p := format.NewTargetParser(applicationsnapshot.JSON, format.Options{ShowSuccesses: showSuccesses, JSONCompact: jsonCompact}, cmd.OutOrStdout(), utils.FS(cmd.Context())).WithContext(cmd.Context())
+				utils.SetColorEnabled(data.noColor, data.forceColor)
 
-			if len(data.outputFile) > 0 {
-				data.output = append(data.output, fmt.Sprintf("%s=%s", applicationsnapshot.JSON, data.outputFile))
-			}
+				// Browsing requires a terminal, otherwise the results are
+				// written in the text format, unless another output is given
+				browse := data.tui && isInteractive()
+				outputs := data.output
+				if data.tui && !browse && len(outputs) == 0 {
+					outputs = []string{applicationsnapshot.Text}
+				}
+				// Only the failing images are written, the outcome of the
+				// validation is decided by all of them
+				written := report
+				if data.onlyFailures {
+					written = report.OnlyFailures()
+				}
+				if !browse || len(outputs) > 0 {
+					if err := written.WriteAll(outputs, p); err != nil {
+						return err
+					}
+				}
+				if browse {
+					if err := written.Browse(cmd.InOrStdin(), cmd.OutOrStdout()); err != nil {
+						return err
+					}
+				}
 
-			report, err := applicationsnapshot.NewReport(data.snapshot, components, data.policy, manyData, manyPolicyInput, showSuccesses)
-			if err != nil {
-				return err
+				// Delivering the results to the log collector is best-effort,
+				// unless required
+				if data.collector != nil {
+					if err := data.collector.Send(cmd.Context(), &written); err != nil {
+						if data.logCollectorRequired {
+							return fmt.Errorf("unable to deliver the results to the log collector: %w", err)
+						}
+						log.Warnf("Unable to deliver the results to the log collector: %s", err)
+					}
+				}
+
+				// Gating on unsigned images is in addition to the other gates
+				if data.failOnUnsigned && report.SignatureCoverage != nil && !report.SignatureCoverage.Complete() {
+					return fmt.Errorf("not all images are signed and attested: %s", report.SignatureCoverage.Summary)
+				}
+
+				failed := report.Failed(data.failOn)
+				if data.failOnSeverity != "" {
+					failed = report.FailedSeverity(data.failOnSeverity)
+				}
+				if failed {
+					// Images that could not be evaluated take precedence over
+					// images violating the policy
+					if report.Errored() {
+						return root.ExitCodeError{Code: root.ExitError, Err: errors.New("success criteria not met")}
+					}
+					return errors.New("success criteria not met")
+				}
+
+				return nil
 			}
-			p := format.NewTargetParser(applicationsnapshot.JSON, format.Options{ShowSuccesses: showSuccesses}, cmd.OutOrStdout(), utils.FS(cmd.Context()))
-			utils.SetColorEnabled(data.noColor, data.forceColor)
-			if err := report.WriteAll(data.output, p); err != nil {
-				return err
+
+			if !data.watchPolicy {
+				return run()
 			}
 
-			if data.strict && !report.Success {
-				return errors.New("success criteria not met")
+			var policySources []source.PolicySource
+			for _, sourceGroup := range data.policy.Spec().Sources {
+				sources, err := source.FetchPolicySources(sourceGroup)
+				if err != nil {
+					return err
+				}
+				policySources = append(policySources, sources...)
 			}
 
-			return nil
+			for {
+				if err := run(); err != nil {
+					log.Error(err)
+				}
+
+				log.Info("Watching the local policy sources for changes")
+				if err := source.WatchLocalSources(cmd.Context(), policySources, watchInterval); err != nil {
+					if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+						return nil
+					}
+					return err
+				}
+				log.Info("Change detected in the local policy sources, validating again")
+			}
 		},
 	}
 
@@ -426,13 +1531,30 @@ func validateImageCmd(validate imageValidationFunc) *cobra.Command {
 		Policy configuration as:
 		  * Kubernetes reference ([<namespace>/]<name>)
 		  * file (policy.yaml)
-		  * git reference (github.com/user/repo//default?ref=main), or
+		  * git reference (github.com/user/repo//default?ref=main),
+		  * builtin, the policy bundle embedded into ec at build time, or
 		  * inline JSON ('{sources: {...}, configuration: {...}}')")`))
 
+	cmd.Flags().StringArrayVar(&data.policyConfig, "policy-config", data.policyConfig, hd.Doc(`
+		Additional policy configuration to validate the images with, given as
+		name=<policy configuration> with the policy configuration given as for --policy. Can be
+		repeated. The sources of each of the policy configurations are evaluated in addition to
+		the sources of --policy, named "default", with the images fetched and verified once.
+		The outcome of each of the policy configurations is included in the output for each
+		image, and the results name the policy configuration in their metadata.
+	`))
+
+	cmd.Flags().BoolVar(&data.requireAll, "require-all", true, hd.Doc(`
+		Require the images to pass all of the policy configurations given by --policy and
+		--policy-config. When false, passing any one of them suffices.
+	`))
+
 	cmd.Flags().StringVarP(&data.imageRef, "image", "i", data.imageRef, "OCI image reference")
 
-	cmd.Flags().StringVarP(&data.publicKey, "public-key", "k", data.publicKey,
-		"path to the public key. Overrides publicKey from EnterpriseContractPolicy")
+	cmd.Flags().StringVarP(&data.publicKey, "public-key", "k", data.publicKey, hd.Doc(`
+		path to the public key, or a reference to a public key, e.g. k8s://namespace/secret,
+		awskms://, gcpkms://, azurekms:// or hashivault://. Overrides publicKey from
+		EnterpriseContractPolicy`))
 
 	cmd.Flags().StringVarP(&data.rekorURL, "rekor-url", "r", data.rekorURL,
 		"Rekor URL. Overrides rekorURL from EnterpriseContractPolicy")
@@ -440,17 +1562,100 @@ func validateImageCmd(validate imageValidationFunc) *cobra.Command {
 	cmd.Flags().BoolVar(&data.ignoreRekor, "ignore-rekor", data.ignoreRekor,
 		"Skip Rekor transparency log checks during validation.")
 
+	cmd.Flags().StringVar(&data.tsaCertChain, "timestamp-certificate-chain", data.tsaCertChain, hd.Doc(`
+		path to the PEM encoded certificate chain of the trusted timestamp authority. The
+		validity of the signing certificates is checked at the time of the trusted timestamps
+		of the signatures, when present, otherwise at the time the signatures were integrated
+		into the Rekor transparency log, and only at the current time if neither is
+		available. The time used is included in the output as signingTimes.`))
+
+	cmd.Flags().StringSliceVar(&data.allowedPayloadTypes, "allowed-payload-type", data.allowedPayloadTypes, hd.Doc(`
+		DSSE payload type allowed for the attestations, e.g. for custom predicates not
+		wrapped in in-toto statements. Can be repeated. Attestations declaring any other
+		payload type fail the validation.`))
+
+	cmd.Flags().StringArrayVar(&data.attestationCosignerKeys, "attestation-cosigner-key", data.attestationCosignerKeys, hd.Doc(`
+		public key of a co-signer of the DSSE envelopes of the attestations, given as for
+		--public-key. Can be repeated. The signatures of the co-signers are counted towards
+		--required-attestation-signers. Only keys are supported, keyless co-signers cannot be
+		verified since the signatures of a DSSE envelope do not carry the certificates of
+		their signers.`))
+
+	cmd.Flags().IntVar(&data.requiredAttestationSigners, "required-attestation-signers", data.requiredAttestationSigners, hd.Doc(`
+		number of distinct signers that must have signed the DSSE envelope of each attestation,
+		counting the public key, or the certificate, the attestation is verified with and the
+		keys given by --attestation-cosigner-key. Each of the signatures of the envelopes is
+		verified, and whether it verified, and the signer it verified with, is included in its
+		metadata as verified and signer. By default an envelope is accepted on its first valid
+		signature.`))
+
+	cmd.Flags().BoolVar(&data.noResultCache, "no-result-cache", data.noResultCache, hd.Doc(`
+		Do not use the result cache. The results of validating images are cached in the
+		directory given by the `+image.ResultCacheDirEnv+` environment variable, when set,
+		keyed by the image digest, the content of the policy sources, the policy, the
+		public key, the effective time and the flags given. Images are validated again
+		when any of those change, or when the cached result expired, see
+		--result-cache-ttl. With --effective-time now, the effective time is taken at the
+		hour. The cached results are not signed, the directory must only be writable by
+		trusted users, anyone able to write to it can make images pass. Policy and data
+		sources fetched over HTTPS are also kept in that directory, and fetched again only
+		when modified, using their ETag and Last-Modified headers, regardless of this flag.
+	`))
+
+	cmd.Flags().DurationVar(&data.resultCacheTTL, "result-cache-ttl", data.resultCacheTTL, hd.Doc(`
+		Time the cached results are used for, bounding the staleness of the results, e.g.
+		of the signatures verified with a key since revoked.
+	`))
+
+	cmd.Flags().DurationVar(&data.retryBudget, "retry-budget", data.retryBudget, hd.Doc(`
+		Cumulative delay, e.g. 30s, that the retries of all the requests to the registries
+		can wait for, when the requests are rate limited or fail due to the registry or the
+		network. Once the budget is spent, failed requests are no longer retried, so that
+		the validation fails promptly during a sustained outage rather than each request
+		retrying in isolation. 0, the default, does not limit the retries. The output
+		reports when the budget was exhausted.
+	`))
+
+	cmd.Flags().DurationVar(&data.rekorTimeWindow, "rekor-time-window", data.rekorTimeWindow, hd.Doc(`
+		Fail images whose SLSA Provenance attestation was not integrated into the Rekor
+		transparency log within the given duration, e.g. 1h, of the time the build finished
+		as claimed by the attestation. Guards against replayed or backdated attestations.
+		Both times are included in the output.
+	`))
+
+	cmd.Flags().BoolVar(&data.verifySBOMConsistency, "verify-sbom-consistency", data.verifySBOMConsistency, hd.Doc(`
+		Fail images whose SPDX or CycloneDX SBOM attestation does not describe the image,
+		or refers to layers not found in the image manifest. Images without an SBOM
+		attestation fail as well. For an image index, the SBOM can describe the index or the
+		image of any platform, including those of nested indexes, and refer to their layers.
+	`))
+
+	cmd.Flags().BoolVar(&data.failOnAttestationConflict, "fail-on-attestation-conflict", data.failOnAttestationConflict, hd.Doc(`
+		Fail images whose verified attestations of the same predicate type disagree on the
+		key fields of the predicate, i.e. the builder ID or the build type of the SLSA
+		Provenance. The conflicting values are included in the output. All the verified
+		attestations are given to the policy regardless.
+	`))
+
+	cmd.Flags().BoolVar(&data.verifySubjectConsistency, "verify-subject-consistency", data.verifySubjectConsistency, hd.Doc(`
+		Fail images whose verified attestations, e.g. the SLSA Provenance and the SBOM, do
+		not all have the image digest among their subject digests, catching attestations of
+		different builds mixed together. Attestations may describe other subjects too, e.g.
+		the image index. The distinct subject digests found are included in the output when
+		they disagree.
+	`))
+
 	cmd.Flags().StringVar(&data.certificateIdentity, "certificate-identity", data.certificateIdentity,
 		"URL of the certificate identity for keyless verification")
 
 	cmd.Flags().StringVar(&data.certificateIdentityRegExp, "certificate-identity-regexp", data.certificateIdentityRegExp,
-		"Regular expression for the URL of the certificate identity for keyless verification")
+		"Regular expression for the URL of the certificate identity for keyless verification, when given with --certificate-identity matching either suffices")
 
 	cmd.Flags().StringVar(&data.certificateOIDCIssuer, "certificate-oidc-issuer", data.certificateOIDCIssuer,
 		"URL of the certificate OIDC issuer for keyless verification")
 
 	cmd.Flags().StringVar(&data.certificateOIDCIssuerRegExp, "certificate-oidc-issuer-regexp", data.certificateOIDCIssuerRegExp,
-		"Regular expresssion for the URL of the certificate OIDC issuer for keyless verification")
+		"Regular expresssion for the URL of the certificate OIDC issuer for keyless verification, when given with --certificate-oidc-issuer matching either suffices")
 
 	// Deprecated: images replaced this
 	cmd.Flags().StringVarP(&data.filePath, "file-path", "f", data.filePath,
@@ -460,15 +1665,64 @@ func validateImageCmd(validate imageValidationFunc) *cobra.Command {
 	cmd.Flags().StringVarP(&data.input, "json-input", "j", data.input,
 		"DEPRECATED - use --images: JSON representation of an ApplicationSnapshot Spec")
 
-	cmd.Flags().StringVar(&data.images, "images", data.images,
-		"path to ApplicationSnapshot Spec JSON file or JSON representation of an ApplicationSnapshot Spec")
+	cmd.Flags().StringArrayVar(&data.images, "images", data.images, hd.Doc(`
+		path to ApplicationSnapshot Spec JSON file or JSON representation of an ApplicationSnapshot Spec.
+		Can be repeated to validate the images of multiple snapshots as one, images pinned to the same
+		digest are validated once`))
+
+	cmd.Flags().StringVar(&data.mergeSnapshots, "merge-snapshots", data.mergeSnapshots, hd.Doc(`
+		resolve conflicting component policies of the same image given by multiple snapshots,
+		one of: first, last. By default conflicting component policies are an error`))
+
+	cmd.Flags().StringVar(&data.digestFile, "digest-file", data.digestFile, hd.Doc(`
+		path to a file listing the images to validate, one image reference pinned by digest,
+		e.g. registry/name@sha256:<digest>, per line. Blank lines and lines starting with #
+		are ignored`))
+
+	cmd.Flags().StringArrayVar(&data.imageTagConstraints, "image-tag-constraint", data.imageTagConstraints, hd.Doc(`
+		validate the image with the highest semantic version tag satisfying the constraint,
+		given as <repository>:<comparison>[,<comparison>...], e.g. registry/name:>=1.2,<2.0.
+		Each comparison is one of the >=, <=, !=, >, < or = operators followed by a, possibly
+		partial, semantic version. The tags of the repository are listed, tags with
+		pre-release versions are considered only when compared with a pre-release version.
+		The image is named after the selected tag and validated pinned to its digest. Can be
+		repeated`))
+
+	cmd.Flags().StringVar(&data.helmChart, "helm-chart", data.helmChart, hd.Doc(`
+		path to a Helm chart, a directory or an archive, to validate the images of the
+		containers in the Kubernetes manifests rendered from it. The chart is rendered with
+		"helm template", the helm program must be on the PATH. The manifests of the subcharts
+		are rendered too, the subcharts must be present in the charts directory of the chart,
+		e.g. using "helm dependency build". Images are taken as rendered, so overrides of the
+		image repository, tag or digest in the values apply. Each distinct image is validated
+		once, named after its container`))
+
+	cmd.Flags().StringArrayVar(&data.helmValues, "values", data.helmValues, hd.Doc(`
+		path to a values file to render the Helm chart given by --helm-chart with. Can be
+		repeated, values in later files take precedence`))
 
 	cmd.Flags().StringSliceVar(&data.output, "output", data.output, hd.Doc(`
 		write output to a file in a specific format. Use empty string path for stdout.
-		May be used multiple times. Possible formats are:
+		May be used multiple times, also with the same format and different destinations,
+		e.g. --output json --output json=archive.json writes to stdout and to the file.
+		Possible formats are:
 		`+strings.Join(validOutputFormats, ", ")+`. In following format and file path
 		additional options can be provided in key=value form following the question
-		mark (?) sign, for example: --output text=output.txt?show-successes=false
+		mark (?) sign, for example: --output text=output.txt?show-successes=false. Given as
+		<format>+=<path>, e.g. --output json+=results.jsonl, the output is appended to the
+		file instead of replacing its content, one line per validation with the json
+		format, e.g. when using --watch-policy. Given as <format>=s3://<bucket>/<object> or
+		<format>=gs://<bucket>/<object> the output is uploaded to the S3 or Google Cloud
+		Storage bucket using the ambient cloud credentials, S3-compatible services are used
+		when AWS_ENDPOINT_URL_S3 or AWS_ENDPOINT_URL is set. Failing to upload fails the
+		command, unless the best-effort=true option is given.
+	`))
+
+	cmd.Flags().StringSliceVar(&data.formatterPlugins, "formatter-plugin", data.formatterPlugins, hd.Doc(`
+		Make an output format available, given as name=path, implemented by the program at
+		the given path. The program is given the report in JSON format on its standard input
+		and is expected to write the report in its format to its standard output. Can be
+		repeated. The format can then be used with --output, e.g. --output <name>=<path>.
 	`))
 
 	cmd.Flags().StringVarP(&data.outputFile, "output-file", "o", data.outputFile,
@@ -477,11 +1731,458 @@ func validateImageCmd(validate imageValidationFunc) *cobra.Command {
 	cmd.Flags().BoolVarP(&data.strict, "strict", "s", data.strict,
 		"Return non-zero status on non-successful validation. Defaults to true. Use --strict=false to return a zero status code.")
 
+	cmd.Flags().StringVar(&data.failOn, "fail-on", data.failOn, hd.Doc(`
+		Severity of the results that return a non-zero status: "violation" fails on any
+		violation, "warning" fails on any violation or warning, and "never" always returns
+		a zero status and only reports the results. --strict=false is the same as "never".
+		The effective value is included in the output as failOn.
+	`))
+
+	cmd.Flags().StringVar(&data.failOnSeverity, "fail-on-severity", data.failOnSeverity, hd.Doc(`
+		Return a non-zero status if any violation or warning has at least the given severity,
+		one of: `+strings.Join(evaluator.Severities, ", ")+`. The severity is read from the
+		"severity" annotation of the rule, or from the "severity" metadata of the result.
+		Without a severity, violations are high, violations of the built-in checks are
+		critical and warnings are low. Images that could not be validated, e.g. that could
+		not be fetched, always fail. Replaces --fail-on, the value is included in the output
+		as failOnSeverity.
+	`))
+
+	cmd.Flags().IntVar(&data.minSLSALevel, "min-slsa-level", data.minSLSALevel, hd.Doc(`
+		Fail images whose verified SLSA Provenance does not meet the given SLSA level,
+		between 1 and 4, or 0, the default, to not check the level. The level determined
+		for each image is included in the output.
+		Level 2 requires the builder to be identified, level 3 requires a trusted
+		builder, see --slsa-builder-id, and an identified build invocation, level 4
+		requires a hermetic and reproducible build. Policy rules are evaluated as usual.
+	`))
+
+	cmd.Flags().StringSliceVar(&data.slsaBuilderIDs, "slsa-builder-id", data.slsaBuilderIDs, hd.Doc(`
+		Builder ID trusted when determining the SLSA level of an image with
+		--min-slsa-level. Glob patterns are supported as by --allowed-builder-id. Can be
+		repeated. When not provided, any builder is trusted. The builder ID is read from
+		builder.id of the SLSA Provenance v0.2 and from runDetails.builder.id of the SLSA
+		Provenance v1, the build invocation from metadata.buildInvocationID and from
+		runDetails.metadata.invocationID, and the hermetic parameter from
+		invocation.parameters and from buildDefinition.externalParameters. The SLSA
+		Provenance v1 does not record the build as reproducible, its level is at most 3.
+	`))
+
+	cmd.Flags().IntVar(&data.minKeySize, "min-key-size", data.minKeySize, hd.Doc(`
+		Fail images whose signatures or attestations are signed with a RSA key smaller than
+		the given size in bits, e.g. 3072. The algorithm and the key size of the signing
+		material of each image are included in the output.
+	`))
+
+	cmd.Flags().StringSliceVar(&data.allowedSignatureAlgorithms, "allowed-signature-algorithm", data.allowedSignatureAlgorithms, hd.Doc(`
+		Signature algorithm allowed for the signatures and the attestations of the images,
+		and for the signing certificates, e.g. ECDSA-SHA256 or SHA256-RSA. Can be repeated.
+		The algorithms are named as in the Go x509 package, using SHA1-RSA for instance
+		for RSA with SHA-1. The algorithm and the key size of the signing material of each
+		image are included in the output.
+	`))
+
+	cmd.Flags().StringSliceVar(&data.allowedBaseImages, "allowed-base-image", data.allowedBaseImages, hd.Doc(`
+		Base image the images are allowed to be built from. Shell patterns are supported and
+		matched against the base image reference and its repository, e.g.
+		"registry.access.redhat.com/ubi9/*". Can be repeated. The base image is taken from
+		the org.opencontainers.image.base.name annotation or label, or from the container
+		image material of the SLSA Provenance. Task bundles and step images recorded as
+		materials are not considered, and the base image is not determined when more than
+		one container image material remains. The detected base image is included in the
+		output.
+	`))
+
+	cmd.Flags().StringSliceVar(&data.allowedBuilderIDs, "allowed-builder-id", data.allowedBuilderIDs, hd.Doc(`
+		Builder ID allowed in the verified SLSA Provenance attestations of the images, e.g.
+		"https://tekton.dev/chains/v2". Glob patterns are supported, * and ? also match /,
+		e.g. "https://tekton.dev/chains/*". Can be repeated. The builder ID is read from
+		builder.id of the SLSA Provenance v0.2 and from runDetails.builder.id of the SLSA
+		Provenance v1. Images without a SLSA Provenance identifying the builder, or with a
+		provenance of any other builder, fail the validation. The builder IDs found are
+		included in the output.
+	`))
+
+	cmd.Flags().StringArrayVar(&data.requiredLabel, "required-label", data.requiredLabel, hd.Doc(`
+		Label the config of the images needs to set, e.g. "org.opencontainers.image.source".
+		Given as key=regex, the value of the label also needs to match the regular expression,
+		e.g. "org.opencontainers.image.revision=[0-9a-f]{40}". The regular expression needs to
+		match the whole value. Can be repeated. Images missing any of the labels, or with a
+		value not matching, fail the validation. The missing labels are included in the output.
+	`))
+
+	cmd.Flags().StringVar(&data.maxImageAge, "max-image-age", data.maxImageAge, hd.Doc(`
+		Fail images created longer ago than the given age, as a number of days, e.g. 30d, or
+		as a duration, e.g. 12h. The creation time is read from the "created" field of the
+		image config and included in the output. The age is measured at the effective time,
+		see --effective-time, with --effective-time attestation at the time the build of each
+		image finished. Images not recording their creation time fail the validation. The
+		results are not cached when checking the image age.
+	`))
+
+	cmd.Flags().StringArrayVar(&data.pinDigest, "pin-digest", data.pinDigest, hd.Doc(`
+		Digest the tag of an image is expected to resolve to, given as repository:tag@digest,
+		e.g. "registry/name:tag@sha256:...", with the digest the tag resolved to earlier. Can
+		be repeated, once per tag. The tag of the image, as given, is resolved again and the
+		image fails the validation if the tag moved to a different digest, or if the image is
+		given with a different digest. The expected and the actual digest are included in
+		the output as digestPin.
+	`))
+
+	cmd.Flags().StringArrayVar(&data.signatureDigest, "signature-digest", data.signatureDigest, hd.Doc(`
+		Signature artifact to verify the signatures of an image from, instead of discovering
+		it using the cosign signature tag, e.g. in registries with unusual layouts. Given as
+		image=digest, or as the digest alone for the image given by --image. The digest is
+		that of the signature artifact within the repository of the image, e.g. sha256:...,
+		or the digest reference of the signature artifact, e.g. registry/name@sha256:....
+		Can be repeated, once per image. The signatures of other images are discovered.
+	`))
+
+	cmd.Flags().StringArrayVar(&data.denyDigest, "deny-digest", data.denyDigest, hd.Doc(`
+		digest of an image to fail outright, e.g. sha256:..., or a file listing such digests,
+		one per line. Can be repeated. Meant for blocking an image at once, without rolling
+		out a policy. A denied image is neither verified nor evaluated, and it is reported as
+		denied by the operator. Takes precedence over --allow-digest.
+	`))
+
+	cmd.Flags().StringArrayVar(&data.allowDigest, "allow-digest", data.allowDigest, hd.Doc(`
+		digest of an image to pass outright, e.g. sha256:..., or a file listing such digests,
+		one per line. Can be repeated. Meant for breaking the glass in an emergency. An
+		allowed image is neither verified nor evaluated, and it is reported as allowed by the
+		operator.
+	`))
+
+	cmd.Flags().StringVar(&data.attestationPolicy, "attestation-policy", data.attestationPolicy, hd.Doc(`
+		Whether images without attestations fail, one of: `+strings.Join(image.AttestationPolicies, ", ")+`.
+		With "optional", the policy is evaluated against the images without attestations,
+		the policy input including "attestations_present" for the rules depending on the
+		attestations to be skipped, and the output reports for each image whether it has
+		any attestations. Meant for snapshots only partially attested yet.
+	`))
+
+	cmd.Flags().StringSliceVar(&data.allowedMediaTypes, "allowed-media-type", data.allowedMediaTypes, hd.Doc(`
+		Media type allowed for the image manifest, config and layers, or for the manifests
+		of an image index. Shell patterns are supported, e.g.
+		"application/vnd.oci.image.layer.v1.*". Can be repeated. Images with any other
+		media type fail the validation, reporting the offending media type.
+	`))
+
+	cmd.Flags().StringSliceVar(&data.deniedMediaTypes, "denied-media-type", data.deniedMediaTypes, hd.Doc(`
+		Media type not allowed for the image manifest, config and layers, or for the
+		manifests of an image index. Shell patterns are supported. Can be repeated. Takes
+		precedence over --allowed-media-type.
+	`))
+
+	cmd.Flags().StringVar(&data.policyLabelConfig, "policy-label-config", data.policyLabelConfig, hd.Doc(`
+		Path to a YAML or JSON file mapping the values of an image label, "ec.policy" by
+		default, to the policy sources used to validate the images with that label.
+		Images without the label, or with a value that is not mapped, are validated
+		with the sources of the policy, see --require-policy-label.
+	`))
+
+	cmd.Flags().BoolVar(&data.allowPolicyReplace, "allow-policy-replace", data.allowPolicyReplace, hd.Doc(`
+		Allow the per-component "policy" of the snapshot to use the "replace" mode,
+		validating the component only with its own sources instead of the sources of
+		the policy. Without it such components are an error.
+	`))
+
+	cmd.Flags().BoolVar(&data.requirePolicyLabel, "require-policy-label", data.requirePolicyLabel, hd.Doc(`
+		Fail the images for which no policy sources are selected by their label
+		with --policy-label-config, instead of validating them with the sources of
+		the policy.
+	`))
+
+	cmd.Flags().BoolVar(&data.reportUnsigned, "report-unsigned", data.reportUnsigned, hd.Doc(`
+		Record which images have a verified signature and a verified attestation and
+		summarize the coverage of the snapshot, e.g. "18/30 signed". The outcome of the
+		validation is still decided by --fail-on and --fail-on-severity, use --fail-on never
+		to take a census without failing the command. See also --fail-on-unsigned.
+	`))
+
+	cmd.Flags().BoolVar(&data.failOnUnsigned, "fail-on-unsigned", data.failOnUnsigned, hd.Doc(`
+		Like --report-unsigned, but also return a non-zero status code if any of the images
+		lacks a verified signature or a verified attestation, regardless of --fail-on.
+	`))
+
+	cmd.Flags().StringVar(&data.logCollector, "log-collector", data.logCollector, hd.Doc(`
+		Forward a JSON summary of the results, the same as the summary output format, to the
+		log collector at the given tcp://host:port URL. Independent of the --output targets.
+		Delivery is best-effort, unless --log-collector-required is used.
+	`))
+
+	cmd.Flags().StringVar(&data.logCollectorCA, "log-collector-ca", data.logCollectorCA, hd.Doc(`
+		Path to the PEM encoded certificate authorities signing the certificate of the log
+		collector. When set, the results are forwarded over TLS.
+	`))
+
+	cmd.Flags().BoolVar(&data.logCollectorRequired, "log-collector-required", data.logCollectorRequired,
+		"Fail the validation if the results cannot be delivered to the log collector.")
+
+	cmd.Flags().BoolVar(&data.signOutput, "sign-output", data.signOutput, hd.Doc(`
+		Sign the JSON outputs written to files, e.g. --output json=report.json, or uploaded
+		to a bucket, so that the verdict can be verified not to have been altered. The output
+		is canonicalized, see RFC 8785, and a DSSE envelope with the canonical output as its
+		payload is written next to the output file, or object, with the .sig suffix appended
+		to its name. Requires --output-signing-key.
+	`))
+
+	cmd.Flags().StringVar(&data.outputSigningKey, "output-signing-key", data.outputSigningKey, hd.Doc(`
+		Private key to sign the outputs with, see --sign-output, given as a file path, a KMS
+		URI or a Kubernetes secret reference, e.g. k8s://namespace/name. The password of the
+		key is read from the COSIGN_PASSWORD environment variable.
+	`))
+
+	cmd.Flags().BoolVar(&data.outputChecksums, "output-checksums", data.outputChecksums, hd.Doc(`
+		Write the SHA-256 checksum of each output written to a file, e.g. --output
+		json=report.json, or uploaded to a bucket, next to the output file, or object, with
+		the .sha256 suffix appended to its name. The checksum is computed over the bytes
+		written, in the format of sha256sum, so that the output can be verified with
+		"sha256sum --check report.json.sha256" from the directory of the output file.
+		Outputs appended to a file have no checksum.
+	`))
+
+	cmd.Flags().BoolVar(&data.onlyFailures, "only-failures", data.onlyFailures, hd.Doc(`
+		List only the images that failed the validation or could not be evaluated in the
+		output, e.g. to keep alert payloads small. The totals of the output, included as
+		totals, count all the images and their results. The exit code is not affected.
+	`))
+
+	cmd.Flags().BoolVar(&data.noProvenance, "no-provenance", data.noProvenance, hd.Doc(`
+		Do not include the provenance block, recording the EC version, the effective time,
+		the policy sources with their resolved revisions, the signing key or identity and
+		the resolved image references, in the output.
+	`))
+
+	cmd.Flags().BoolVar(&data.watchPolicy, "watch-policy", data.watchPolicy, hd.Doc(`
+		Keep watching the policy sources referring to local directories and validate
+		again whenever their content changes. Validation errors are logged instead of
+		ending the command, which ends once the global --timeout is reached.
+	`))
+
 	cmd.Flags().StringVar(&data.effectiveTime, "effective-time", policy.Now, hd.Doc(`
 		Run policy checks with the provided time. Useful for testing rules with
 		effective dates in the future. The value can be "now" (default) - for
 		current time, "attestation" - for time from the youngest attestation, or
-		a RFC3339 formatted value, e.g. 2022-11-18T00:00:00Z.
+		a RFC3339 formatted value, e.g. 2022-11-18T00:00:00Z. Violations of rules
+		annotated with an enforce_after date later than the effective time are
+		reported as warnings, with the reason in the demotion metadata field.
+	`))
+
+	cmd.Flags().StringVar(&data.dataMergeStrategy, "data-merge-strategy", data.dataMergeStrategy, hd.Doc(`
+		Strategy used to combine the data documents from all data sources into a
+		single data namespace. With "deep" objects are merged recursively and, on
+		conflicting values, the value from the last data source wins. With "replace"
+		top level keys are replaced wholesale by the last data source defining them.
+		Possible values are: `+strings.Join(evaluator.DataMergeStrategies, ", ")+`.
+		Conflicts are logged at debug level.
+	`))
+
+	cmd.Flags().BoolVar(&data.strictRego, "strict-rego", data.strictRego, hd.Doc(`
+		Compile the policy rules in OPA's strict mode, failing the validation on unused
+		variables and imports, on shadowing the input and data documents, and on deprecated
+		built-in functions. By default the rules are compiled as OPA does, references to
+		missing data or to missing attributes of the input are undefined, as are built-in
+		functions that fail, and the rules using them do not apply. Unknowns are not handled
+		by OPA partial evaluation in either case, the rules are always fully evaluated.
+	`))
+
+	cmd.Flags().StringVar(&data.evalMemoryLimit, "eval-memory-limit", data.evalMemoryLimit, hd.Doc(`
+		Fail images whose policy evaluation grows the heap by more than the given quantity,
+		e.g. 512Mi. The evaluation is interrupted once the limit is exceeded. The heap is
+		shared by the images validated in parallel.
+	`))
+
+	cmd.Flags().StringVar(&data.maxInputSize, "max-input-size", data.maxInputSize, hd.Doc(`
+		Fail images whose policy input, including the attestations, is larger than the given
+		quantity, e.g. 64Mi, without evaluating the policy, guarding against bloated or
+		untrusted attestations exhausting the memory. The size of the policy input of each
+		image is included in the output.
+	`))
+
+	cmd.Flags().IntVar(&data.workers, "workers", data.workers, hd.Doc(`
+		Number of images validated concurrently. Each image is evaluated with its own policy
+		engine, so the memory used grows with the number of workers.
+	`))
+
+	cmd.Flags().BoolVar(&data.reuseImageMetadata, "reuse-image-metadata", data.reuseImageMetadata, hd.Doc(`
+		Fetch the manifest and the config of each image once and share them between the
+		checks of the image, the layers are fetched only by the checks reading them. Use
+		--reuse-image-metadata=false to fetch them for each check instead, e.g. to rule out
+		the reuse when troubleshooting registry issues. The results are the same either way.
+	`))
+
+	cmd.Flags().IntVar(&data.fetchConcurrency, "fetch-concurrency", data.fetchConcurrency, hd.Doc(`
+		Number of policy and data sources fetched concurrently.
+	`))
+
+	cmd.Flags().StringVar(&data.resources, "resources", data.resources, hd.Doc(`
+		Preset of the resources used by the validation, one of "low", "default" or "high".
+		Each preset sets the --workers, --fetch-concurrency, --eval-memory-limit and
+		--max-input-size flags, unless the flag is given explicitly:
+		  low:     --workers 1 --fetch-concurrency 1 --eval-memory-limit 512Mi --max-input-size 32Mi
+		  default: --workers 5 --fetch-concurrency 4, with no evaluation memory limit and no maximum input size
+		  high:    --workers 16 --fetch-concurrency 8, with no evaluation memory limit and no maximum input size
+	`))
+
+	cmd.Flags().DurationVar(&data.evalTimeout, "eval-timeout", data.evalTimeout, hd.Doc(`
+		Fail images whose policy evaluation takes longer than the given duration, e.g. 1m.
+		The evaluation is interrupted once the limit is exceeded.
+	`))
+
+	cmd.Flags().StringVar(&data.missingSource, "missing-source", data.missingSource, hd.Doc(`
+		How to handle policy and data sources that cannot be fetched because they do not
+		exist or their host is not reachable. With "error" the validation fails, with "warn"
+		a warning is logged and the source is excluded from the evaluation. Other errors,
+		e.g. failing to authenticate, always fail the validation. The skipped sources are
+		listed in the skippedSources field of the output.
+	`))
+
+	cmd.Flags().BoolVar(&data.normalizeAttestations, "normalize-attestations", data.normalizeAttestations, hd.Doc(`
+		Include the predicate of the attestations of a recognized predicate type in the
+		policy input in a canonical shape, as the normalized attribute next to the statement.
+		SLSA Provenance v0.2 and v1 are recognized, with their field names unified. The
+		statements are included unchanged.
+	`))
+
+	cmd.Flags().BoolVar(&data.parallelPolicyEval, "parallel-policy-eval", data.parallelPolicyEval, hd.Doc(`
+		Evaluate the policy namespaces of each image concurrently, each with its own policy
+		engine, speeding up the validation of a single image against many independent
+		namespaces. The results are the same as when evaluating the namespaces serially.
+		Increases the memory used, as the policy is loaded once per namespace.
+	`))
+
+	cmd.Flags().BoolVar(&data.preflight, "preflight", data.preflight, hd.Doc(`
+		Check that all images are accessible before evaluating the policy. All inaccessible
+		images, e.g. because of missing credentials, are reported at once.
+	`))
+
+	cmd.Flags().BoolVar(&data.preflightOnly, "preflight-only", data.preflightOnly, hd.Doc(`
+		Only check that all images are accessible, see --preflight, without evaluating the
+		policy.
+	`))
+
+	cmd.Flags().StringVar(&data.knownViolationsFile, "known-violations", data.knownViolationsFile, hd.Doc(`
+		Path to a YAML file listing the known violations, each with the image, or a glob
+		matching images, and the rule code, e.g. "- {image: registry/name*, code: pkg.rule}".
+		Known violations are reported as such and do not fail the validation, only new
+		violations do. The violations of the built-in checks, with codes starting with
+		"builtin.", e.g. of the image signature, cannot be known violations.
+	`))
+
+	cmd.Flags().BoolVar(&data.updateKnownViolations, "update-known-violations", data.updateKnownViolations, hd.Doc(`
+		Write the current violations to the file given by --known-violations, replacing its
+		content, to bootstrap or refresh the known violations. The violations of the
+		built-in checks are not written.
+	`))
+
+	cmd.Flags().StringArrayVar(&data.redact, "redact", data.redact, hd.Doc(`
+		Replace the matches of the given regular expression in the messages of the violations
+		and warnings with "***", e.g. to hide internal hostnames in shared reports. Applies
+		to all output formats. Can be repeated. The number of redacted messages is included
+		in the output as redacted.
+	`))
+
+	cmd.Flags().StringVar(&data.messageOverrideFile, "message-override", data.messageOverrideFile, hd.Doc(`
+		Path to a YAML file mapping rule codes to replacement messages, e.g. to localize the
+		messages shown to developers. The replacement messages are Go templates with access
+		to the original message as {{ .Message }}, the rule code as {{ .Code }} and the
+		metadata of the result, e.g. {{ .Metadata.title }}. Applies to all output formats,
+		before --redact. The rule codes are not changed. Results without an override keep
+		the original message.
+	`))
+
+	cmd.Flags().BoolVar(&data.normalizeDigests, "normalize-digests", data.normalizeDigests, hd.Doc(`
+		Show the image references in the output in a canonical form, instead of as given, so
+		that outputs of validating the same images referred to differently, e.g. by other
+		tools, compare equal. The registry hostname is lowercased, docker.io and its other
+		aliases are replaced with index.docker.io, and the default registry, namespace and tag
+		are added. The tag is not added to references pinned by digest.
+	`))
+
+	cmd.Flags().StringVar(&data.dumpInput, "dump-input", data.dumpInput, hd.Doc(`
+		Write the input documents the policies are evaluated with, including the image
+		manifest, the attestations and the image config, to the given JSON file, keyed by
+		the image reference. The --redact expressions are applied to the documents.
+	`))
+
+	cmd.Flags().BoolVar(&data.allowNetworkBuiltins, "allow-network-builtins", data.allowNetworkBuiltins, hd.Doc(`
+		Allow the policies to use OPA's network built-in functions, http.send and
+		net.lookup_ip_addr, to fetch data at evaluation time from the hosts given by
+		--builtin-allowed-host. Disabled by default, the results of the validation depend
+		on the responses of those hosts. The results are not cached.
+	`))
+
+	cmd.Flags().StringSliceVar(&data.builtinAllowedHosts, "builtin-allowed-host", data.builtinAllowedHosts, hd.Doc(`
+		Host name, without a port, e.g. allow-list.example.com, the network built-in
+		functions can reach, on any port, when enabled with --allow-network-builtins.
+		Can be repeated.
+	`))
+
+	cmd.Flags().DurationVar(&data.builtinTimeout, "builtin-timeout", data.builtinTimeout, hd.Doc(`
+		Fail the policy evaluation if a call of a network built-in function takes longer
+		than the given duration, e.g. 10s.
+	`))
+
+	cmd.Flags().StringSliceVar(&data.traceRules, "trace-rule", data.traceRules, hd.Doc(`
+		Capture OPA's evaluation trace of the given rule, e.g. package.rule, or of all the rules
+		of the given package. The trace covers all the rules of the rule's package. Can be
+		repeated or combined with --trace-image. Unlike the --trace logging, only the
+		evaluations in scope are traced.
+	`))
+
+	cmd.Flags().StringSliceVar(&data.traceImages, "trace-image", data.traceImages, hd.Doc(`
+		Capture OPA's evaluation trace of the given image, an image reference pinned by digest
+		or just the digest. Can be repeated or combined with --trace-rule.
+	`))
+
+	cmd.Flags().StringVar(&data.traceFile, "trace-file", data.traceFile, hd.Doc(`
+		Write the evaluation trace captured with --trace-rule or --trace-image to the given
+		file instead of the standard error.
+	`))
+
+	cmd.Flags().StringArrayVar(&data.expectPolicyDigest, "expect-policy-digest", data.expectPolicyDigest, hd.Doc(`
+		Fail if the content of the fetched policy and data sources does not match the expected
+		digest, given as sha256:<hex> for the aggregate digest of all sources or as
+		<url>=sha256:<hex> for a single source. The actual digest is reported on mismatch. The
+		digest of a source covers the paths and the content of its files, excluding the .git
+		directory. Local directories are copied when digested, the copy is evaluated. Can be
+		repeated.
+	`))
+
+	cmd.Flags().StringArrayVar(&data.requireRuleCode, "require-rule-code", data.requireRuleCode, hd.Doc(`
+		Fail before validating any image if the rule with the given code, e.g.
+		test.required_tests_passed, is not defined by any of the policy sources, guarding
+		against rules dropped by misconfigured sources. All the missing codes are reported.
+		Can be repeated.
+	`))
+
+	cmd.Flags().BoolVar(&data.failOnDuplicate, "fail-on-duplicate", data.failOnDuplicate, hd.Doc(`
+		Fail if the snapshot has duplicate components, i.e. components with the same name but
+		different images, or images of the same repository pinned to different digests,
+		instead of warning about them.
+	`))
+
+	cmd.Flags().BoolVar(&data.failOnEmptySnapshot, "fail-on-empty-snapshot", data.failOnEmptySnapshot, hd.Doc(`
+		Fail with the exit code 2 if no images remain to be validated, e.g. because the
+		snapshot is empty or all of its images are unchanged, see --changed-since, instead of
+		succeeding without validating anything. The error lists the number of images after
+		each step narrowing down the images.
+	`))
+
+	cmd.Flags().StringVar(&data.changedSince, "changed-since", data.changedSince, hd.Doc(`
+		Validate only the images that changed since the given snapshot, e.g. of the previous
+		release, in the same format as --images. A component is unchanged if the previous
+		snapshot has a component of the same name with the same image digest. The unchanged
+		components are reported as skipped and do not affect the outcome of the validation.
+	`))
+
+	cmd.Flags().StringArrayVar(&data.registryRewrite, "registry-rewrite", data.registryRewrite, hd.Doc(`
+		Fetch the images of the upstream registry from the mirror registry, e.g. a pull-through
+		cache, given as upstream=mirror with both being registry hosts, e.g.
+		docker.io=mirror.example.com:5000. The image references are not rewritten, the upstream
+		references are reported, given to the policy and used to look up the signatures and
+		attestations, which are also fetched from the mirror. The credentials of the mirror are
+		used. Can be repeated.
 	`))
 
 	cmd.Flags().StringSliceVar(&data.extraRuleData, "extra-rule-data", data.extraRuleData, hd.Doc(`
@@ -497,6 +2198,20 @@ func validateImageCmd(validate imageValidationFunc) *cobra.Command {
 		violations, include the title and the description of the failed policy
 		rule.`))
 
+	cmd.Flags().BoolVar(&data.introspect, "introspect", data.introspect, hd.Doc(`
+		Only report on the manifest of each image, without evaluating the policy: the size,
+		the number of layers, the architectures, the labels, and the signatures, attestations
+		and referrers attached to the image. Written in the format given by --output, one of:
+		`+strings.Join(image.IntrospectionFormats, ", ")+`.`))
+
+	cmd.Flags().BoolVar(&data.tui, "tui", data.tui, hd.Doc(`
+		After validating, browse the results interactively in the terminal with a
+		prompt-driven browser, reading one command per line, e.g. "status violations": filter
+		the results by status and severity, search the rule codes, and show the details of
+		each result. Type "help" for the commands. Only when both the standard input and
+		output are a terminal, otherwise the results are written in the text format, or in
+		the formats given by --output.`))
+
 	cmd.Flags().BoolVar(&data.noColor, "no-color", data.info, hd.Doc(`
 		Disable color when using text output even when the current terminal supports it`))
 
@@ -509,5 +2224,143 @@ func validateImageCmd(validate imageValidationFunc) *cobra.Command {
 		}
 	}
 
+	completion.Register(cmd, "output", completion.Formats(validOutputFormats))
+
 	return cmd
 }
+
+// selectLabelSources selects the policy sources for the image by its labels.
+func selectLabelSources(ctx context.Context, url string, l policy.LabelPolicies) (string, []ecc.Source, error) {
+	ref, err := name.ParseReference(url)
+	if err != nil {
+		return "", nil, fmt.Errorf("unable to parse the image reference %s: %w", url, err)
+	}
+
+	labels, err := config.FetchImageLabels(ctx, ref)
+	if err != nil {
+		return "", nil, fmt.Errorf("unable to fetch the labels of image %s: %w", url, err)
+	}
+
+	return l.Select(labels)
+}
+
+// defaultWorkers is the number of images validated concurrently by default
+const defaultWorkers = 5
+
+// resourceProfile is a preset of the resources used by the validation, see the
+// --resources flag
+type resourceProfile struct {
+	workers          int
+	fetchConcurrency int
+	evalMemoryLimit  string
+	maxInputSize     string
+}
+
+var resourceProfiles = map[string]resourceProfile{
+	"low": {
+		workers:          1,
+		fetchConcurrency: 1,
+		evalMemoryLimit:  "512Mi",
+		maxInputSize:     "32Mi",
+	},
+	"default": {
+		workers:          defaultWorkers,
+		fetchConcurrency: source.DefaultFetchConcurrency,
+	},
+	"high": {
+		workers:          16,
+		fetchConcurrency: 8,
+	},
+}
+
+func resourceProfileNames() []string {
+	names := make([]string, 0, len(resourceProfiles))
+	for n := range resourceProfiles {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// resultCacheIgnoredFlags are the flags not affecting the outcome of validating
+// an image, not part of the key of the cached results
+var resultCacheIgnoredFlags = map[string]bool{
+	"color":                  true,
+	"debug":                  true,
+	"dump-input":             true,
+	"fetch-concurrency":      true,
+	"json-compact":           true,
+	"log-collector":          true,
+	"log-collector-ca":       true,
+	"log-collector-required": true,
+	"logfile":                true,
+	"message-override":       true,
+	"no-color":               true,
+	"no-result-cache":        true,
+	"only-failures":          true,
+	"normalize-digests":      true,
+	"output":                 true,
+	"output-checksums":       true,
+	"otel-endpoint":          true,
+	"output-file":            true,
+	"output-signing-key":     true,
+	"parallel-policy-eval":   true,
+	"quiet":                  true,
+	"require-rule-code":      true,
+	"result-cache-ttl":       true,
+	"retry-budget":           true,
+	"show-successes":         true,
+	"sign-output":            true,
+	"strict":                 true,
+	"timeout":                true,
+	"trace":                  true,
+	"tui":                    true,
+	"verbose":                true,
+	"workers":                true,
+}
+
+// resultCacheEffectiveTimeGranularity is the granularity of the effective
+// time "now" in the key of the cached results. Cached results are used within
+// it, a policy rule becoming effective is considered at its end at the latest.
+const resultCacheEffectiveTimeGranularity = time.Hour
+
+// newResultCache creates the result cache keyed by the content digest of the
+// policy sources, fetched into the work directory, the policy, the public key,
+// the effective time and the flags given on the command line.
+func newResultCache(cmd *cobra.Command, p policy.Policy, dir string, ttl time.Duration, workDir string) (*image.ResultCache, error) {
+	policyDigest, err := source.FetchAggregateDigest(cmd.Context(), p.Spec().Sources, workDir)
+	if err != nil {
+		return nil, err
+	}
+
+	// The public key might be a reference to the key, e.g. in a Kubernetes
+	// secret, the key itself is part of the key of the cached results
+	var publicKey string
+	if !p.Keyless() {
+		if pem, err := p.PublicKeyPEM(); err == nil {
+			publicKey = string(pem)
+		}
+	}
+
+	flags := map[string]string{}
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		if !resultCacheIgnoredFlags[f.Name] {
+			flags[f.Name] = f.Value.String()
+		}
+	})
+
+	effectiveTime := p.EffectiveTime().UTC()
+	if f := cmd.Flags().Lookup("effective-time"); f == nil || f.Value.String() == policy.Now {
+		effectiveTime = effectiveTime.Truncate(resultCacheEffectiveTimeGranularity)
+	}
+
+	return image.NewResultCache(utils.FS(cmd.Context()), dir, ttl, map[string]any{
+		"version":       version.Version,
+		"policy":        p.Spec(),
+		"policyDigest":  policyDigest,
+		"publicKey":     publicKey,
+		"effectiveTime": effectiveTime,
+		"flags":         flags,
+	})
+}
@@ -24,23 +24,38 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net"
+	"os"
+	"path"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	hd "github.com/MakeNowJust/heredoc"
+	ecc "github.com/enterprise-contract/enterprise-contract-controller/api/v1alpha1"
 	"github.com/gkampitakis/go-snaps/snaps"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
 	app "github.com/konflux-ci/application-api/api/v1alpha1"
 	"github.com/sigstore/cosign/v2/pkg/cosign"
 	log "github.com/sirupsen/logrus"
 	"github.com/sirupsen/logrus/hooks/test"
 	"github.com/spf13/afero"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 
+	"github.com/enterprise-contract/ec-cli/cmd/root"
 	"github.com/enterprise-contract/ec-cli/internal/applicationsnapshot"
 	"github.com/enterprise-contract/ec-cli/internal/evaluator"
 	"github.com/enterprise-contract/ec-cli/internal/output"
 	"github.com/enterprise-contract/ec-cli/internal/policy"
+	"github.com/enterprise-contract/ec-cli/internal/policy/source"
+	"github.com/enterprise-contract/ec-cli/internal/signature"
 	"github.com/enterprise-contract/ec-cli/internal/utils"
 	"github.com/enterprise-contract/ec-cli/internal/utils/oci"
 	"github.com/enterprise-contract/ec-cli/internal/utils/oci/fake"
@@ -243,7 +258,7 @@ func Test_determineInputSpec(t *testing.T) {
 				File:   c.arguments.filePath,
 				JSON:   c.arguments.input,
 				Image:  c.arguments.imageRef,
-				Images: c.arguments.images,
+				Images: []string{c.arguments.images},
 			})
 			if c.err != "" {
 				assert.EqualError(t, err, c.err)
@@ -320,19 +335,27 @@ func Test_ValidateImageCommand(t *testing.T) {
 		"success": true,
 		"ec-version": "development",
 		"effective-time": %q,
+		"failOn": "violation",
 		"key": %s,
+		"provenance": {
+			"ec_version": "development",
+			"effective_time": %q,
+			"policy": {"sources": [], "public_key": %s},
+			"images": ["registry/image:tag"]
+		},
 		"components": [
 		  {
 			"name": "Unnamed",
 			"containerImage": "registry/image:tag",
 			"source": {},
-			"success": true
+			"success": true,
+			"status": "pass"
 		  }
 		],
 		"policy": {
 			"publicKey": %s
 		}
-	  }`, effectiveTimeTest, utils.TestPublicKeyJSON, utils.TestPublicKeyJSON), out.String())
+	  }`, effectiveTimeTest, utils.TestPublicKeyJSON, effectiveTimeTest, utils.TestPublicKeyJSON, utils.TestPublicKeyJSON), out.String())
 }
 
 func Test_ValidateImageCommandImages(t *testing.T) {
@@ -425,7 +448,14 @@ func Test_ValidateImageCommandImages(t *testing.T) {
 		"success": true,
 		"ec-version": "development",
 		"effective-time": %q,
+		"failOn": "violation",
 		"key": %s,
+		"provenance": {
+			"ec_version": "development",
+			"effective_time": %q,
+			"policy": {"sources": [], "public_key": %s},
+			"images": ["registry.localhost/spam:v1.0", "registry.localhost/bacon:v2.0"]
+		},
 		"components": [
 			{
 				"name": "spam",
@@ -436,7 +466,8 @@ func Test_ValidateImageCommandImages(t *testing.T) {
 						"revision": "ded982e702e07bb7b6effafdc353db3fe172c83f"
 					}
 				},
-				"success": true
+				"success": true,
+				"status": "pass"
 			},
 			{
 				"name": "bacon",
@@ -447,13 +478,14 @@ func Test_ValidateImageCommandImages(t *testing.T) {
 						"revision": "8abf15bef376e0e21f1f9e9c3d74483d5018f3d5"
 					}
 				},
-				"success": true
+				"success": true,
+				"status": "pass"
 			}
 		],
 		"policy": {
 			"publicKey": %s
 		}
-	  }`, effectiveTimeTest, utils.TestPublicKeyJSON, utils.TestPublicKeyJSON), out.String())
+	  }`, effectiveTimeTest, utils.TestPublicKeyJSON, effectiveTimeTest, utils.TestPublicKeyJSON, utils.TestPublicKeyJSON), out.String())
 }
 
 func Test_ValidateImageCommandKeyless(t *testing.T) {
@@ -1137,7 +1169,14 @@ func Test_FailureImageAccessibility(t *testing.T) {
 		"success": false,
 		"ec-version": "development",
 		"effective-time": %q,
+		"failOn": "violation",
 		"key": %s,
+		"provenance": {
+			"ec_version": "development",
+			"effective_time": %q,
+			"policy": {"sources": [], "public_key": %s},
+			"images": ["registry/image:tag"]
+		},
 		"components": [
 		  {
 			"name": "Unnamed",
@@ -1148,13 +1187,14 @@ func Test_FailureImageAccessibility(t *testing.T) {
 			  {"msg": "skipped due to inaccessible image ref"},
 			  {"msg": "skipped due to inaccessible image ref"}
 			],
-			"success": false
+			"success": false,
+			"status": "fail"
 		  }
 		],
 		"policy": {
 			"publicKey": %s
 		}
-	  }`, effectiveTimeTest, utils.TestPublicKeyJSON, utils.TestPublicKeyJSON), out.String())
+	  }`, effectiveTimeTest, utils.TestPublicKeyJSON, effectiveTimeTest, utils.TestPublicKeyJSON, utils.TestPublicKeyJSON), out.String())
 }
 
 func Test_FailureOutput(t *testing.T) {
@@ -1207,7 +1247,14 @@ func Test_FailureOutput(t *testing.T) {
 		"success": false,
 		"ec-version": "development",
 		"effective-time": %q,
+		"failOn": "violation",
 		"key": %s,
+		"provenance": {
+			"ec_version": "development",
+			"effective_time": %q,
+			"policy": {"sources": [], "public_key": %s},
+			"images": ["registry/image:tag"]
+		},
 		"components": [
 		  {
 			"name": "Unnamed",
@@ -1217,13 +1264,14 @@ func Test_FailureOutput(t *testing.T) {
 			  {"msg": "failed attestation signature check"},
 			  {"msg": "failed image signature check"}
 			],
-			"success": false
+			"success": false,
+			"status": "fail"
 		  }
 		],
 		"policy": {
 			"publicKey": %s
 		}
-	  }`, effectiveTimeTest, utils.TestPublicKeyJSON, utils.TestPublicKeyJSON), out.String())
+	  }`, effectiveTimeTest, utils.TestPublicKeyJSON, effectiveTimeTest, utils.TestPublicKeyJSON, utils.TestPublicKeyJSON), out.String())
 }
 
 func Test_WarningOutput(t *testing.T) {
@@ -1281,7 +1329,14 @@ func Test_WarningOutput(t *testing.T) {
 		"success": true,
 		"ec-version": "development",
 		"effective-time": %q,
+		"failOn": "violation",
 		"key": %s,
+		"provenance": {
+			"ec_version": "development",
+			"effective_time": %q,
+			"policy": {"sources": [], "public_key": %s},
+			"images": ["registry/image:tag"]
+		},
 		"components": [
 		  {
 			"name": "Unnamed",
@@ -1291,13 +1346,14 @@ func Test_WarningOutput(t *testing.T) {
 				{"msg": "warning for policy check 1"},
 				{"msg": "warning for policy check 2"}
 			],
-			"success": true
+			"success": true,
+			"status": "pass"
 		  }
 		],
 		"policy": {
 			"publicKey": %s
 		}
-	  }`, effectiveTimeTest, utils.TestPublicKeyJSON, utils.TestPublicKeyJSON), out.String())
+	  }`, effectiveTimeTest, utils.TestPublicKeyJSON, effectiveTimeTest, utils.TestPublicKeyJSON, utils.TestPublicKeyJSON), out.String())
 }
 
 func Test_FailureImageAccessibilityNonStrict(t *testing.T) {
@@ -1350,7 +1406,14 @@ func Test_FailureImageAccessibilityNonStrict(t *testing.T) {
 		"success": false,
 		"ec-version": "development",
 		"effective-time": %q,
+		"failOn": "violation",
 		"key": %s,
+		"provenance": {
+			"ec_version": "development",
+			"effective_time": %q,
+			"policy": {"sources": [], "public_key": %s},
+			"images": ["registry/image:tag"]
+		},
 		"components": [
 		  {
 			"name": "Unnamed",
@@ -1359,13 +1422,14 @@ func Test_FailureImageAccessibilityNonStrict(t *testing.T) {
 			"violations": [
 			  {"msg": "Image URL is not accessible: HEAD registry/image:tag: unexpected status code 404 Not Found (HEAD responses have no body, use GET for details)"}
 			],
-			"success": false
+			"success": false,
+			"status": "fail"
 		  }
 		],
 		"policy": {
 			"publicKey": %s
 		}
-	  }`, effectiveTimeTest, utils.TestPublicKeyJSON, utils.TestPublicKeyJSON), out.String())
+	  }`, effectiveTimeTest, utils.TestPublicKeyJSON, effectiveTimeTest, utils.TestPublicKeyJSON, utils.TestPublicKeyJSON), out.String())
 }
 
 func TestValidateImageCommand_RunE(t *testing.T) {
@@ -1433,13 +1497,77 @@ func TestValidateImageCommand_RunE(t *testing.T) {
 		"success": true,
 		"ec-version": "development",
 		"effective-time": %q,
+		"failOn": "violation",
+		"key": %s,
+		"provenance": {
+			"ec_version": "development",
+			"effective_time": %q,
+			"policy": {"sources": [], "public_key": %s},
+			"images": ["registry/image:tag"]
+		},
+		"components": [
+		  {
+			"name": "Unnamed",
+			"containerImage": "registry/image:tag",
+			"source": {},
+			"success": true,
+			"status": "pass"
+		  }
+		],
+		"policy": {
+			"publicKey": %s
+		}
+	  }`, effectiveTimeTest, utils.TestPublicKeyJSON, effectiveTimeTest, utils.TestPublicKeyJSON, utils.TestPublicKeyJSON), out.String())
+}
+
+func Test_ValidateImageCommandNoProvenance(t *testing.T) {
+	validate := func(_ context.Context, component app.SnapshotComponent, _ *app.SnapshotSpec, _ policy.Policy, _ []evaluator.Evaluator, _ bool) (*output.Output, error) {
+		return &output.Output{
+			ImageURL: component.ContainerImage,
+		}, nil
+	}
+
+	validateImageCmd := validateImageCmd(validate)
+	cmd := setUpCobra(validateImageCmd)
+
+	client := fake.FakeClient{}
+	commonMockClient(&client)
+	ctx := utils.WithFS(context.Background(), afero.NewMemMapFs())
+	ctx = oci.WithClient(ctx, &client)
+	cmd.SetContext(ctx)
+
+	effectiveTimeTest := time.Now().UTC().Format(time.RFC3339Nano)
+
+	cmd.SetArgs(append(rootArgs, []string{
+		"--image",
+		"registry/image:tag",
+		"--policy",
+		fmt.Sprintf(`{"publicKey": %s}`, utils.TestPublicKeyJSON),
+		"--effective-time",
+		effectiveTimeTest,
+		"--no-provenance",
+	}...))
+
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+
+	utils.SetTestRekorPublicKey(t)
+
+	err := cmd.Execute()
+	assert.NoError(t, err)
+	utils.AssertOutputEqual[applicationsnapshot.Report](t, fmt.Sprintf(`{
+		"success": true,
+		"ec-version": "development",
+		"effective-time": %q,
+		"failOn": "violation",
 		"key": %s,
 		"components": [
 		  {
 			"name": "Unnamed",
 			"containerImage": "registry/image:tag",
 			"source": {},
-			"success": true
+			"success": true,
+			"status": "pass"
 		  }
 		],
 		"policy": {
@@ -1447,3 +1575,1739 @@ func TestValidateImageCommand_RunE(t *testing.T) {
 		}
 	  }`, effectiveTimeTest, utils.TestPublicKeyJSON, utils.TestPublicKeyJSON), out.String())
 }
+
+func Test_ValidateImageCommandReportUnsigned(t *testing.T) {
+	validate := func(_ context.Context, component app.SnapshotComponent, _ *app.SnapshotSpec, _ policy.Policy, _ []evaluator.Evaluator, _ bool) (*output.Output, error) {
+		out := &output.Output{ImageURL: component.ContainerImage}
+		if component.ContainerImage == "registry/signed:tag" {
+			out.SetImageSignatureCheckFromError(nil)
+			out.SetAttestationSignatureCheckFromError(nil)
+		} else {
+			out.SetImageSignatureCheckFromError(errors.New("no signatures found"))
+		}
+		return out, nil
+	}
+
+	cases := []struct {
+		name   string
+		flags  []string
+		images string
+		err    string
+	}{
+		{
+			name:   "report only",
+			flags:  []string{"--report-unsigned", "--fail-on", "never"},
+			images: `{"components":[{"containerImage":"registry/signed:tag"},{"containerImage":"registry/unsigned:tag"}]}`,
+		},
+		{
+			name:   "report with the other gates",
+			flags:  []string{"--report-unsigned"},
+			images: `{"components":[{"containerImage":"registry/signed:tag"},{"containerImage":"registry/unsigned:tag"}]}`,
+			err:    "success criteria not met",
+		},
+		{
+			name:   "report with severity gate",
+			flags:  []string{"--report-unsigned", "--fail-on-severity", "critical"},
+			images: `{"components":[{"containerImage":"registry/signed:tag"},{"containerImage":"registry/unsigned:tag"}]}`,
+			err:    "success criteria not met",
+		},
+		{
+			name:   "gate on unsigned",
+			flags:  []string{"--fail-on-unsigned"},
+			images: `{"components":[{"containerImage":"registry/signed:tag"},{"containerImage":"registry/unsigned:tag"}]}`,
+			err:    "not all images are signed and attested: 1/2 signed, 1/2 attested",
+		},
+		{
+			name:   "gate on all signed",
+			flags:  []string{"--fail-on-unsigned"},
+			images: `{"components":[{"containerImage":"registry/signed:tag"}]}`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cmd := setUpCobra(validateImageCmd(validate))
+
+			client := fake.FakeClient{}
+			commonMockClient(&client)
+			ctx := utils.WithFS(context.Background(), afero.NewMemMapFs())
+			ctx = oci.WithClient(ctx, &client)
+			cmd.SetContext(ctx)
+
+			cmd.SetArgs(append(append(rootArgs,
+				"--images",
+				c.images,
+				"--policy",
+				fmt.Sprintf(`{"publicKey": %s}`, utils.TestPublicKeyJSON),
+				"--output",
+				"text",
+			), c.flags...))
+
+			var out bytes.Buffer
+			cmd.SetOut(&out)
+
+			utils.SetTestRekorPublicKey(t)
+
+			err := cmd.Execute()
+			if c.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, c.err)
+			}
+			assert.Contains(t, out.String(), "Signature coverage: ")
+		})
+	}
+}
+
+func Test_ValidateImageCommandRateLimited(t *testing.T) {
+	validate := func(_ context.Context, component app.SnapshotComponent, _ *app.SnapshotSpec, _ policy.Policy, _ []evaluator.Evaluator, _ bool) (*output.Output, error) {
+		if component.ContainerImage == "registry/limited:tag" {
+			return nil, errors.New("HEAD https://registry/v2/limited/manifests/tag: unexpected status code 429 Too Many Requests (HEAD responses have no body, use GET for details)")
+		}
+		return &output.Output{ImageURL: component.ContainerImage}, nil
+	}
+
+	cmd := setUpCobra(validateImageCmd(validate))
+
+	client := fake.FakeClient{}
+	commonMockClient(&client)
+	ctx := utils.WithFS(context.Background(), afero.NewMemMapFs())
+	ctx = oci.WithClient(ctx, &client)
+	cmd.SetContext(ctx)
+
+	cmd.SetArgs(append(rootArgs, []string{
+		"--images",
+		`{"components":[{"name":"limited","containerImage":"registry/limited:tag"},{"name":"ok","containerImage":"registry/ok:tag"}]}`,
+		"--policy",
+		fmt.Sprintf(`{"publicKey": %s}`, utils.TestPublicKeyJSON),
+		"--no-provenance",
+		"--strict=false",
+	}...))
+
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+
+	utils.SetTestRekorPublicKey(t)
+
+	err := cmd.Execute()
+	assert.NoError(t, err)
+
+	var report applicationsnapshot.Report
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &report))
+	assert.False(t, report.Success)
+	assert.Equal(t, 1, report.RateLimited)
+	assert.Len(t, report.Components, 2)
+	for _, c := range report.Components {
+		assert.Equal(t, c.Name == "limited", c.RateLimited, c.Name)
+		assert.Equal(t, c.Name == "ok", c.Success, c.Name)
+	}
+}
+
+func Test_ValidateImageCommandComponentPolicies(t *testing.T) {
+	var mu sync.Mutex
+	urls := map[evaluator.Evaluator]string{}
+	newConftestEvaluator = func(_ context.Context, s []source.PolicySource, _ evaluator.ConfigProvider, _ ecc.Source) (evaluator.Evaluator, error) {
+		e := &mockEvaluator{}
+		e.On("Destroy")
+		mu.Lock()
+		defer mu.Unlock()
+		urls[e] = s[0].PolicyUrl()
+		return e, nil
+	}
+	t.Cleanup(func() {
+		newConftestEvaluator = evaluator.NewConftestEvaluator
+	})
+
+	used := map[string][]string{}
+	validate := func(_ context.Context, component app.SnapshotComponent, _ *app.SnapshotSpec, _ policy.Policy, evaluators []evaluator.Evaluator, _ bool) (*output.Output, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, e := range evaluators {
+			used[component.Name] = append(used[component.Name], urls[e])
+		}
+		return &output.Output{ImageURL: component.ContainerImage}, nil
+	}
+
+	cmd := setUpCobra(validateImageCmd(validate))
+
+	client := fake.FakeClient{}
+	commonMockClient(&client)
+	ctx := utils.WithFS(context.Background(), afero.NewMemMapFs())
+	ctx = oci.WithClient(ctx, &client)
+	cmd.SetContext(ctx)
+
+	cmd.SetArgs(append(rootArgs, []string{
+		"--images",
+		`{"components":[
+			{"name":"shared","containerImage":"registry/shared:tag"},
+			{"name":"merged","containerImage":"registry/merged:tag","policy":{"sources":[{"policy":["git::https://example.com/extra"]}]}},
+			{"name":"replaced","containerImage":"registry/replaced:tag","policy":{"mode":"replace","sources":[{"policy":["git::https://example.com/other"]}]}}
+		]}`,
+		"--policy",
+		fmt.Sprintf(`{"publicKey": %s, "sources": [{"policy": ["git::https://example.com/global"]}]}`, utils.TestPublicKeyJSON),
+		"--allow-policy-replace",
+	}...))
+
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+
+	utils.SetTestRekorPublicKey(t)
+
+	err := cmd.Execute()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]string{
+		"shared":   {"git::https://example.com/global"},
+		"merged":   {"git::https://example.com/global", "git::https://example.com/extra"},
+		"replaced": {"git::https://example.com/other"},
+	}, used)
+	for e := range urls {
+		e.(*mockEvaluator).AssertCalled(t, "Destroy")
+	}
+}
+
+func Test_ValidateImageCommandComponentPolicyReplaceNotAllowed(t *testing.T) {
+	cmd := setUpCobra(validateImageCmd(nil))
+
+	client := fake.FakeClient{}
+	commonMockClient(&client)
+	ctx := utils.WithFS(context.Background(), afero.NewMemMapFs())
+	ctx = oci.WithClient(ctx, &client)
+	cmd.SetContext(ctx)
+
+	cmd.SetArgs(append(rootArgs, []string{
+		"--images",
+		`{"components":[
+			{"name":"merged","containerImage":"registry/merged:tag","policy":{"sources":[{"policy":["git::https://example.com/extra"]}]}},
+			{"name":"replaced","containerImage":"registry/replaced:tag","policy":{"mode":"replace","sources":[{"policy":["git::https://example.com/other"]}]}}
+		]}`,
+		"--policy",
+		fmt.Sprintf(`{"publicKey": %s, "sources": [{"policy": ["git::https://example.com/global"]}]}`, utils.TestPublicKeyJSON),
+	}...))
+
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+
+	utils.SetTestRekorPublicKey(t)
+
+	err := cmd.Execute()
+	assert.ErrorContains(t, err, "the policy of the component with image registry/replaced:tag replaces the policy sources, which requires --allow-policy-replace")
+}
+
+func Test_ValidateImageCommandInvalidMergeSnapshots(t *testing.T) {
+	cmd := setUpCobra(validateImageCmd(nil))
+
+	client := fake.FakeClient{}
+	commonMockClient(&client)
+	ctx := utils.WithFS(context.Background(), afero.NewMemMapFs())
+	ctx = oci.WithClient(ctx, &client)
+	cmd.SetContext(ctx)
+
+	cmd.SetArgs(append(rootArgs, []string{
+		"--images",
+		`{"components":[{"name":"replaced","containerImage":"registry/replaced:tag","policy":{"mode":"replace","sources":[{"policy":["git::https://example.com/other"]}]}}]}`,
+		"--merge-snapshots",
+		"union",
+		"--policy",
+		fmt.Sprintf(`{"publicKey": %s, "sources": [{"policy": ["git::https://example.com/global"]}]}`, utils.TestPublicKeyJSON),
+	}...))
+
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+
+	utils.SetTestRekorPublicKey(t)
+
+	// All the errors are reported, not only the first one
+	err := cmd.Execute()
+	assert.ErrorContains(t, err, `invalid --merge-snapshots value "union", expecting one of: first, last`)
+	assert.ErrorContains(t, err, "the policy of the component with image registry/replaced:tag replaces the policy sources, which requires --allow-policy-replace")
+}
+
+func Test_ValidateImageCommandInvalidMinSLSALevel(t *testing.T) {
+	for _, level := range []string{"-1", "5"} {
+		t.Run(level, func(t *testing.T) {
+			cmd := setUpCobra(validateImageCmd(nil))
+
+			client := fake.FakeClient{}
+			commonMockClient(&client)
+			ctx := utils.WithFS(context.Background(), afero.NewMemMapFs())
+			ctx = oci.WithClient(ctx, &client)
+			cmd.SetContext(ctx)
+
+			cmd.SetArgs(append(rootArgs, "--image", "registry/image:tag", "--min-slsa-level", level, "--policy",
+				fmt.Sprintf(`{"publicKey": %s, "sources": [{"policy": ["git::https://example.com/global"]}]}`, utils.TestPublicKeyJSON)))
+
+			var out bytes.Buffer
+			cmd.SetOut(&out)
+
+			utils.SetTestRekorPublicKey(t)
+
+			err := cmd.Execute()
+			assert.ErrorContains(t, err, "invalid minimum SLSA level "+level+", expecting a level between 0 and 4")
+		})
+	}
+}
+
+func Test_ValidateImageCommandInvalidBuilderIDPattern(t *testing.T) {
+	for _, flag := range []string{"--slsa-builder-id", "--allowed-builder-id"} {
+		t.Run(flag, func(t *testing.T) {
+			cmd := setUpCobra(validateImageCmd(nil))
+
+			client := fake.FakeClient{}
+			commonMockClient(&client)
+			ctx := utils.WithFS(context.Background(), afero.NewMemMapFs())
+			ctx = oci.WithClient(ctx, &client)
+			cmd.SetContext(ctx)
+
+			cmd.SetArgs(append(rootArgs, "--image", "registry/image:tag", flag, "https://tekton.dev/[", "--policy",
+				fmt.Sprintf(`{"publicKey": %s, "sources": [{"policy": ["git::https://example.com/global"]}]}`, utils.TestPublicKeyJSON)))
+
+			var out bytes.Buffer
+			cmd.SetOut(&out)
+
+			utils.SetTestRekorPublicKey(t)
+
+			err := cmd.Execute()
+			assert.ErrorContains(t, err, `invalid builder ID pattern "https://tekton.dev/["`)
+		})
+	}
+}
+
+func Test_ValidateImageCommandPolicyLabel(t *testing.T) {
+	var mu sync.Mutex
+	urls := map[evaluator.Evaluator]string{}
+	newConftestEvaluator = func(_ context.Context, s []source.PolicySource, _ evaluator.ConfigProvider, _ ecc.Source) (evaluator.Evaluator, error) {
+		e := &mockEvaluator{}
+		e.On("Destroy")
+		mu.Lock()
+		defer mu.Unlock()
+		urls[e] = s[0].PolicyUrl()
+		return e, nil
+	}
+	t.Cleanup(func() {
+		newConftestEvaluator = evaluator.NewConftestEvaluator
+	})
+
+	used := map[string][]string{}
+	validate := func(_ context.Context, component app.SnapshotComponent, _ *app.SnapshotSpec, _ policy.Policy, evaluators []evaluator.Evaluator, _ bool) (*output.Output, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, e := range evaluators {
+			used[component.Name] = append(used[component.Name], urls[e])
+		}
+		return &output.Output{ImageURL: component.ContainerImage}, nil
+	}
+
+	labeled := func(labels map[string]string) v1.Image {
+		img, err := mutate.Config(empty.Image, v1.Config{Labels: labels})
+		require.NoError(t, err)
+		return img
+	}
+
+	client := fake.FakeClient{}
+	commonMockClient(&client)
+	client.On("Image", name.MustParseReference("registry/frontend:tag")).Return(labeled(map[string]string{"ec.policy": "frontend"}), nil)
+	client.On("Image", name.MustParseReference("registry/unknown:tag")).Return(labeled(map[string]string{"ec.policy": "unknown"}), nil)
+	client.On("Image", name.MustParseReference("registry/unlabeled:tag")).Return(labeled(nil), nil)
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/labels.yaml", []byte(`
+sources:
+  frontend:
+  - policy: [git::https://example.com/frontend]
+`), 0400))
+
+	images := `{"components":[
+		{"name":"frontend","containerImage":"registry/frontend:tag"},
+		{"name":"unknown","containerImage":"registry/unknown:tag"},
+		{"name":"unlabeled","containerImage":"registry/unlabeled:tag"}
+	]}`
+
+	cases := []struct {
+		name     string
+		args     []string
+		used     map[string][]string
+		failures map[string]string
+	}{
+		{
+			name: "fall back to the policy",
+			used: map[string][]string{
+				"frontend":  {"git::https://example.com/frontend"},
+				"unknown":   {"git::https://example.com/global"},
+				"unlabeled": {"git::https://example.com/global"},
+			},
+		},
+		{
+			name: "required",
+			args: []string{"--require-policy-label"},
+			used: map[string][]string{
+				"frontend": {"git::https://example.com/frontend"},
+			},
+			failures: map[string]string{
+				"unknown":   `Policy label check failed: no policy sources selected by the image labels: no policy sources for the ec.policy label value "unknown"`,
+				"unlabeled": "Policy label check failed: no policy sources selected by the image labels: the image does not have the ec.policy label",
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			used = map[string][]string{}
+
+			cmd := setUpCobra(validateImageCmd(validate))
+			ctx := utils.WithFS(context.Background(), fs)
+			ctx = oci.WithClient(ctx, &client)
+			cmd.SetContext(ctx)
+
+			cmd.SetArgs(append(append(rootArgs, []string{
+				"--images",
+				images,
+				"--policy",
+				fmt.Sprintf(`{"publicKey": %s, "sources": [{"policy": ["git::https://example.com/global"]}]}`, utils.TestPublicKeyJSON),
+				"--policy-label-config",
+				"/labels.yaml",
+				"--strict=false",
+			}...), c.args...))
+
+			var out bytes.Buffer
+			cmd.SetOut(&out)
+
+			utils.SetTestRekorPublicKey(t)
+
+			err := cmd.Execute()
+			assert.NoError(t, err)
+			assert.Equal(t, c.used, used)
+
+			var report applicationsnapshot.Report
+			require.NoError(t, json.Unmarshal(out.Bytes(), &report))
+			failures := map[string]string{}
+			for _, comp := range report.Components {
+				for _, v := range comp.Violations {
+					failures[comp.Name] = v.Message
+				}
+			}
+			if c.failures == nil {
+				c.failures = map[string]string{}
+			}
+			assert.Equal(t, c.failures, failures)
+		})
+	}
+}
+
+func TestValidateImageCommandPolicyConfigs(t *testing.T) {
+	newConftestEvaluator = func(_ context.Context, s []source.PolicySource, _ evaluator.ConfigProvider, _ ecc.Source) (evaluator.Evaluator, error) {
+		var outcomes []evaluator.Outcome
+		if s[0].PolicyUrl() == "git::https://example.com/internal" {
+			outcomes = []evaluator.Outcome{{Failures: []evaluator.Result{{Message: "Internal failure", Metadata: map[string]any{"code": "internal.failure"}}}}}
+		}
+
+		e := &mockEvaluator{}
+		e.On("Destroy")
+		e.On("Evaluate", mock.Anything, mock.Anything).Return(outcomes, evaluator.Data{}, nil)
+		return e, nil
+	}
+	t.Cleanup(func() {
+		newConftestEvaluator = evaluator.NewConftestEvaluator
+	})
+
+	validate := func(ctx context.Context, component app.SnapshotComponent, _ *app.SnapshotSpec, _ policy.Policy, evaluators []evaluator.Evaluator, _ bool) (*output.Output, error) {
+		out := &output.Output{
+			ImageURL:                  component.ContainerImage,
+			ImageSignatureCheck:       output.VerificationStatus{Passed: true},
+			ImageAccessibleCheck:      output.VerificationStatus{Passed: true},
+			AttestationSignatureCheck: output.VerificationStatus{Passed: true},
+		}
+		var outcomes []evaluator.Outcome
+		for _, e := range evaluators {
+			o, _, err := e.Evaluate(ctx, evaluator.EvaluationTarget{})
+			if err != nil {
+				return nil, err
+			}
+			outcomes = append(outcomes, o...)
+		}
+		out.SetPolicyCheck(outcomes)
+		return out, nil
+	}
+
+	cases := []struct {
+		name    string
+		args    []string
+		success bool
+	}{
+		{name: "all required"},
+		{name: "any", args: []string{"--require-all=false"}, success: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cmd := setUpCobra(validateImageCmd(validate))
+
+			client := fake.FakeClient{}
+			commonMockClient(&client)
+			ctx := utils.WithFS(context.Background(), afero.NewMemMapFs())
+			ctx = oci.WithClient(ctx, &client)
+			cmd.SetContext(ctx)
+
+			cmd.SetArgs(append(append(rootArgs, []string{
+				"--image",
+				"registry/image:tag",
+				"--policy",
+				fmt.Sprintf(`{"publicKey": %s, "sources": [{"policy": ["git::https://example.com/minimum"]}]}`, utils.TestPublicKeyJSON),
+				"--policy-config",
+				`internal={"sources": [{"policy": ["git::https://example.com/internal"]}]}`,
+			}...), c.args...))
+
+			var out bytes.Buffer
+			cmd.SetOut(&out)
+
+			utils.SetTestRekorPublicKey(t)
+
+			err := cmd.Execute()
+			if c.success {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+
+			var report applicationsnapshot.Report
+			require.NoError(t, json.Unmarshal(out.Bytes(), &report))
+			assert.Equal(t, c.success, report.Success)
+			require.Len(t, report.Components, 1)
+			assert.Equal(t, []applicationsnapshot.PolicyConfigResult{
+				{Name: "default", Success: true},
+				{Name: "internal", Success: false, Violations: 1},
+			}, report.Components[0].PolicyConfigs)
+			require.Len(t, report.Components[0].Violations, 1)
+			assert.Equal(t, "internal", report.Components[0].Violations[0].Metadata["policy_config"])
+		})
+	}
+}
+
+func TestValidateImageCommandInvalidPolicyConfig(t *testing.T) {
+	cmd := setUpCobra(validateImageCmd(nil))
+	cmd.SetContext(utils.WithFS(context.Background(), afero.NewMemMapFs()))
+
+	cmd.SetArgs(append(rootArgs, []string{
+		"--image",
+		"registry/image:tag",
+		"--policy",
+		fmt.Sprintf(`{"publicKey": %s}`, utils.TestPublicKeyJSON),
+		"--policy-config",
+		`default={"sources": []}`,
+		"--policy-config",
+		"internal",
+	}...))
+
+	utils.SetTestRekorPublicKey(t)
+
+	err := cmd.Execute()
+	assert.ErrorContains(t, err, `the name "default" is reserved for the policy configuration given by --policy`)
+	assert.ErrorContains(t, err, `invalid policy configuration "internal", expecting name=<policy configuration>`)
+}
+
+func TestValidateImageCommandFailOn(t *testing.T) {
+	validate := func(_ context.Context, component app.SnapshotComponent, _ *app.SnapshotSpec, _ policy.Policy, _ []evaluator.Evaluator, _ bool) (*output.Output, error) {
+		return &output.Output{
+			ImageSignatureCheck:       output.VerificationStatus{Passed: true},
+			ImageAccessibleCheck:      output.VerificationStatus{Passed: true},
+			AttestationSignatureCheck: output.VerificationStatus{Passed: true},
+			PolicyCheck: []evaluator.Outcome{
+				{
+					Failures: []evaluator.Result{{Message: "violation for policy check"}},
+					Warnings: []evaluator.Result{{Message: "warning for policy check"}},
+				},
+			},
+			ImageURL: component.ContainerImage,
+		}, nil
+	}
+	warnOnly := func(_ context.Context, component app.SnapshotComponent, _ *app.SnapshotSpec, _ policy.Policy, _ []evaluator.Evaluator, _ bool) (*output.Output, error) {
+		out, err := validate(context.Background(), component, nil, nil, nil, false)
+		out.PolicyCheck[0].Failures = nil
+		return out, err
+	}
+
+	cases := []struct {
+		name     string
+		validate imageValidationFunc
+		args     []string
+		failOn   string
+		err      string
+	}{
+		{name: "default", validate: validate, failOn: "violation", err: "success criteria not met"},
+		{name: "warnings pass by default", validate: warnOnly, failOn: "violation"},
+		{name: "warning", validate: warnOnly, args: []string{"--fail-on", "warning"}, failOn: "warning", err: "success criteria not met"},
+		{name: "never", validate: validate, args: []string{"--fail-on", "never"}, failOn: "never"},
+		{name: "not strict", validate: validate, args: []string{"--strict=false"}, failOn: "never"},
+		{name: "not strict and never", validate: validate, args: []string{"--strict=false", "--fail-on", "never"}, failOn: "never"},
+		{name: "not strict and warning", validate: validate, args: []string{"--strict=false", "--fail-on", "warning"}, err: "1 error occurred:\n\t* --fail-on warning cannot be used with --strict=false\n\n"},
+		{name: "invalid", validate: validate, args: []string{"--fail-on", "error"}, err: "1 error occurred:\n\t* invalid --fail-on value \"error\", expecting one of: violation, warning, never\n\n"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cmd := setUpCobra(validateImageCmd(c.validate))
+			cmd.SilenceUsage = true
+
+			client := fake.FakeClient{}
+			commonMockClient(&client)
+			ctx := utils.WithFS(context.Background(), afero.NewMemMapFs())
+			ctx = oci.WithClient(ctx, &client)
+			cmd.SetContext(ctx)
+
+			cmd.SetArgs(append(append(rootArgs,
+				"--image",
+				"registry/image:tag",
+				"--policy",
+				fmt.Sprintf(`{"publicKey": %s}`, utils.TestPublicKeyJSON),
+			), c.args...))
+
+			var out bytes.Buffer
+			cmd.SetOut(&out)
+
+			utils.SetTestRekorPublicKey(t)
+
+			err := cmd.Execute()
+			if c.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, c.err)
+			}
+
+			if c.failOn != "" {
+				var report map[string]any
+				require.NoError(t, json.Unmarshal(out.Bytes(), &report))
+				assert.Equal(t, c.failOn, report["failOn"])
+			}
+		})
+	}
+}
+
+func TestValidateImageCommandFailOnSeverity(t *testing.T) {
+	validate := func(_ context.Context, component app.SnapshotComponent, _ *app.SnapshotSpec, _ policy.Policy, _ []evaluator.Evaluator, _ bool) (*output.Output, error) {
+		return &output.Output{
+			ImageSignatureCheck:       output.VerificationStatus{Passed: true},
+			ImageAccessibleCheck:      output.VerificationStatus{Passed: true},
+			AttestationSignatureCheck: output.VerificationStatus{Passed: true},
+			PolicyCheck: []evaluator.Outcome{
+				{
+					Failures: []evaluator.Result{{Message: "violation", Metadata: map[string]any{"code": "pkg.rule"}, Severity: evaluator.SeverityMedium}},
+				},
+			},
+			ImageURL: component.ContainerImage,
+		}, nil
+	}
+
+	cases := []struct {
+		name string
+		args []string
+		err  string
+	}{
+		{name: "below threshold", args: []string{"--fail-on-severity", "high"}},
+		{name: "at threshold", args: []string{"--fail-on-severity", "medium"}, err: "success criteria not met"},
+		{name: "with fail-on", args: []string{"--fail-on-severity", "high", "--fail-on", "warning"}, err: "1 error occurred:\n\t* --fail-on-severity cannot be used with --fail-on or --strict=false\n\n"},
+		{name: "invalid", args: []string{"--fail-on-severity", "blocker"}, err: "1 error occurred:\n\t* invalid --fail-on-severity value: invalid severity \"blocker\", expecting one of: info, low, medium, high, critical\n\n"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cmd := setUpCobra(validateImageCmd(validate))
+			cmd.SilenceUsage = true
+
+			client := fake.FakeClient{}
+			commonMockClient(&client)
+			ctx := utils.WithFS(context.Background(), afero.NewMemMapFs())
+			ctx = oci.WithClient(ctx, &client)
+			cmd.SetContext(ctx)
+
+			cmd.SetArgs(append(append(rootArgs,
+				"--image",
+				"registry/image:tag",
+				"--policy",
+				fmt.Sprintf(`{"publicKey": %s}`, utils.TestPublicKeyJSON),
+			), c.args...))
+
+			var out bytes.Buffer
+			cmd.SetOut(&out)
+
+			utils.SetTestRekorPublicKey(t)
+
+			err := cmd.Execute()
+			if c.err == "" {
+				assert.NoError(t, err)
+				var report map[string]any
+				require.NoError(t, json.Unmarshal(out.Bytes(), &report))
+				assert.Equal(t, "high", report["failOnSeverity"])
+				assert.Nil(t, report["failOn"])
+			} else {
+				assert.EqualError(t, err, c.err)
+			}
+		})
+	}
+}
+
+func TestValidateImageCommandLogCollector(t *testing.T) {
+	validate := func(_ context.Context, component app.SnapshotComponent, _ *app.SnapshotSpec, _ policy.Policy, _ []evaluator.Evaluator, _ bool) (*output.Output, error) {
+		return &output.Output{
+			ImageSignatureCheck:       output.VerificationStatus{Passed: true},
+			ImageAccessibleCheck:      output.VerificationStatus{Passed: true},
+			AttestationSignatureCheck: output.VerificationStatus{Passed: true},
+			ImageURL:                  component.ContainerImage,
+		}, nil
+	}
+
+	// A listener that was closed leaves an address nothing is listening on
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	closed := "tcp://" + l.Addr().String()
+	require.NoError(t, l.Close())
+
+	cases := []struct {
+		name string
+		args []string
+		err  string
+	}{
+		{name: "best-effort", args: []string{"--log-collector", closed}},
+		{name: "required", args: []string{"--log-collector", closed, "--log-collector-required"}, err: "unable to deliver the results to the log collector: unable to connect to the log collector"},
+		{name: "invalid", args: []string{"--log-collector", "udp://collector:514"}, err: `invalid log collector URL "udp://collector:514", expecting tcp://host:port`},
+		{name: "no collector", args: []string{"--log-collector-required"}, err: "--log-collector-ca and --log-collector-required require --log-collector"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cmd := setUpCobra(validateImageCmd(validate))
+			cmd.SilenceUsage = true
+
+			client := fake.FakeClient{}
+			commonMockClient(&client)
+			ctx := utils.WithFS(context.Background(), afero.NewMemMapFs())
+			ctx = oci.WithClient(ctx, &client)
+			cmd.SetContext(ctx)
+
+			cmd.SetArgs(append(append(rootArgs,
+				"--image",
+				"registry/image:tag",
+				"--policy",
+				fmt.Sprintf(`{"publicKey": %s}`, utils.TestPublicKeyJSON),
+			), c.args...))
+
+			var out bytes.Buffer
+			cmd.SetOut(&out)
+
+			utils.SetTestRekorPublicKey(t)
+
+			err := cmd.Execute()
+			if c.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, c.err)
+			}
+		})
+	}
+}
+
+func TestValidateImageCommandTrace(t *testing.T) {
+	validate := func(_ context.Context, component app.SnapshotComponent, _ *app.SnapshotSpec, _ policy.Policy, _ []evaluator.Evaluator, _ bool) (*output.Output, error) {
+		return &output.Output{
+			ImageSignatureCheck:       output.VerificationStatus{Passed: true},
+			ImageAccessibleCheck:      output.VerificationStatus{Passed: true},
+			AttestationSignatureCheck: output.VerificationStatus{Passed: true},
+			ImageURL:                  component.ContainerImage,
+		}, nil
+	}
+
+	cases := []struct {
+		name string
+		args []string
+		err  string
+	}{
+		{name: "rule", args: []string{"--trace-rule", "pkg.rule", "--trace-file", "/trace.txt"}},
+		{name: "image", args: []string{"--trace-image", "sha256:abc", "--trace-file", "/trace.txt"}},
+		{name: "unscoped", args: []string{"--trace-file", "/trace.txt"}, err: "--trace-file requires --trace-rule or --trace-image"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cmd := setUpCobra(validateImageCmd(validate))
+			cmd.SilenceUsage = true
+
+			client := fake.FakeClient{}
+			commonMockClient(&client)
+			fs := afero.NewMemMapFs()
+			ctx := utils.WithFS(context.Background(), fs)
+			ctx = oci.WithClient(ctx, &client)
+			cmd.SetContext(ctx)
+
+			cmd.SetArgs(append(append(rootArgs,
+				"--image",
+				"registry/image:tag",
+				"--policy",
+				fmt.Sprintf(`{"publicKey": %s}`, utils.TestPublicKeyJSON),
+			), c.args...))
+
+			var out bytes.Buffer
+			cmd.SetOut(&out)
+
+			utils.SetTestRekorPublicKey(t)
+
+			err := cmd.Execute()
+			exists, _ := afero.Exists(fs, "/trace.txt")
+			if c.err == "" {
+				assert.NoError(t, err)
+				assert.True(t, exists)
+			} else {
+				assert.ErrorContains(t, err, c.err)
+				assert.False(t, exists)
+			}
+		})
+	}
+}
+
+func TestValidateImageCommandKnownViolations(t *testing.T) {
+	validate := func(_ context.Context, component app.SnapshotComponent, _ *app.SnapshotSpec, _ policy.Policy, _ []evaluator.Evaluator, _ bool) (*output.Output, error) {
+		return &output.Output{
+			ImageSignatureCheck:       output.VerificationStatus{Passed: true},
+			ImageAccessibleCheck:      output.VerificationStatus{Passed: true},
+			AttestationSignatureCheck: output.VerificationStatus{Passed: true},
+			ImageURL:                  component.ContainerImage,
+			PolicyCheck: []evaluator.Outcome{{
+				Failures: []evaluator.Result{{Message: "Denied", Metadata: map[string]any{"code": "pkg.rule"}}},
+			}},
+		}, nil
+	}
+
+	fs := afero.NewMemMapFs()
+
+	run := func(args ...string) (map[string]any, error) {
+		cmd := setUpCobra(validateImageCmd(validate))
+		cmd.SilenceUsage = true
+
+		client := fake.FakeClient{}
+		commonMockClient(&client)
+		ctx := utils.WithFS(context.Background(), fs)
+		ctx = oci.WithClient(ctx, &client)
+		cmd.SetContext(ctx)
+
+		cmd.SetArgs(append(append(rootArgs,
+			"--image",
+			"registry/image:tag",
+			"--policy",
+			fmt.Sprintf(`{"publicKey": %s}`, utils.TestPublicKeyJSON),
+		), args...))
+
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+
+		utils.SetTestRekorPublicKey(t)
+
+		err := cmd.Execute()
+		var report map[string]any
+		_ = json.Unmarshal(out.Bytes(), &report)
+
+		return report, err
+	}
+
+	_, err := run()
+	assert.EqualError(t, err, "success criteria not met")
+
+	_, err = run("--update-known-violations")
+	assert.ErrorContains(t, err, "--update-known-violations requires --known-violations")
+
+	_, err = run("--known-violations", "/known.yaml")
+	assert.ErrorContains(t, err, "unable to read the known violations")
+
+	report, err := run("--known-violations", "/known.yaml", "--update-known-violations")
+	require.NoError(t, err)
+	assert.Equal(t, true, report["success"])
+
+	known, err := afero.ReadFile(fs, "/known.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, "- code: pkg.rule\n  image: registry/image:tag\n", string(known))
+
+	report, err = run("--known-violations", "/known.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, true, report["success"])
+	component := report["components"].([]any)[0].(map[string]any)
+	assert.Nil(t, component["violations"])
+	assert.Len(t, component["knownViolations"], 1)
+}
+
+func TestValidateImageCommandPreflight(t *testing.T) {
+	validated := false
+	validate := func(_ context.Context, component app.SnapshotComponent, _ *app.SnapshotSpec, _ policy.Policy, _ []evaluator.Evaluator, _ bool) (*output.Output, error) {
+		validated = true
+		return &output.Output{
+			ImageSignatureCheck:       output.VerificationStatus{Passed: true},
+			ImageAccessibleCheck:      output.VerificationStatus{Passed: true},
+			AttestationSignatureCheck: output.VerificationStatus{Passed: true},
+			ImageURL:                  component.ContainerImage,
+		}, nil
+	}
+
+	cases := []struct {
+		name      string
+		args      []string
+		validated bool
+		out       string
+		err       string
+	}{
+		{name: "preflight", args: []string{"--preflight"}, validated: true},
+		{name: "preflight only", args: []string{"--preflight-only"}, out: "All 2 images are accessible\n"},
+		{name: "inaccessible", args: []string{"--preflight", "--image", "registry/unauthorized:tag"}, err: "1 of 3 images are not accessible:\n  registry/unauthorized:tag: UNAUTHORIZED"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			validated = false
+			cmd := setUpCobra(validateImageCmd(validate))
+			cmd.SilenceUsage = true
+
+			client := fake.FakeClient{}
+			client.On("Head", mock.MatchedBy(func(ref name.Reference) bool {
+				return ref.String() == "registry/unauthorized:tag"
+			})).Return(nil, errors.New("UNAUTHORIZED"))
+			commonMockClient(&client)
+			ctx := utils.WithFS(context.Background(), afero.NewMemMapFs())
+			ctx = oci.WithClient(ctx, &client)
+			cmd.SetContext(ctx)
+
+			cmd.SetArgs(append(append(rootArgs,
+				"--images",
+				`{"components":[{"containerImage":"registry/image:tag"},{"containerImage":"registry/other:tag"}]}`,
+				"--policy",
+				fmt.Sprintf(`{"publicKey": %s}`, utils.TestPublicKeyJSON),
+			), c.args...))
+
+			var out bytes.Buffer
+			cmd.SetOut(&out)
+
+			utils.SetTestRekorPublicKey(t)
+
+			err := cmd.Execute()
+			if c.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, c.err)
+			}
+			assert.Equal(t, c.validated, validated)
+			if c.out != "" {
+				assert.Equal(t, c.out, out.String())
+			}
+		})
+	}
+}
+
+func TestValidateImageCommandIntrospect(t *testing.T) {
+	validated := false
+	validate := func(_ context.Context, component app.SnapshotComponent, _ *app.SnapshotSpec, _ policy.Policy, _ []evaluator.Evaluator, _ bool) (*output.Output, error) {
+		validated = true
+		return &output.Output{ImageURL: component.ContainerImage}, nil
+	}
+
+	cmd := setUpCobra(validateImageCmd(validate))
+
+	client := fake.FakeClient{}
+	client.On("Head", mock.Anything).Return(nil, errors.New("UNAUTHORIZED"))
+	ctx := utils.WithFS(context.Background(), afero.NewMemMapFs())
+	ctx = oci.WithClient(ctx, &client)
+	cmd.SetContext(ctx)
+
+	cmd.SetArgs(append(rootArgs,
+		"--image",
+		"registry/image:tag",
+		"--policy",
+		fmt.Sprintf(`{"publicKey": %s}`, utils.TestPublicKeyJSON),
+		"--introspect",
+		"--output",
+		"text",
+	))
+
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+
+	utils.SetTestRekorPublicKey(t)
+
+	require.NoError(t, cmd.Execute())
+	assert.False(t, validated)
+	assert.Equal(t, "Image: registry/image:tag\nError: unable to fetch the descriptor: UNAUTHORIZED\n", out.String())
+}
+
+func TestValidateImageCommandRegistryRewrite(t *testing.T) {
+	var rewritten bool
+	validate := func(ctx context.Context, component app.SnapshotComponent, _ *app.SnapshotSpec, _ policy.Policy, _ []evaluator.Evaluator, _ bool) (*output.Output, error) {
+		rewritten = len(oci.RegistryRewriteOptions(ctx)) > 0
+		return &output.Output{
+			ImageSignatureCheck:       output.VerificationStatus{Passed: true},
+			ImageAccessibleCheck:      output.VerificationStatus{Passed: true},
+			AttestationSignatureCheck: output.VerificationStatus{Passed: true},
+			ImageURL:                  component.ContainerImage,
+		}, nil
+	}
+
+	cases := []struct {
+		name      string
+		rewrite   string
+		rewritten bool
+		err       string
+	}{
+		{name: "rewrite", rewrite: "registry.io=mirror.example.com", rewritten: true},
+		{name: "invalid", rewrite: "registry.io", err: `invalid registry rewrite "registry.io", expecting upstream=mirror`},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			rewritten = false
+			cmd := setUpCobra(validateImageCmd(validate))
+			cmd.SilenceUsage = true
+
+			client := fake.FakeClient{}
+			commonMockClient(&client)
+			ctx := utils.WithFS(context.Background(), afero.NewMemMapFs())
+			ctx = oci.WithClient(ctx, &client)
+			cmd.SetContext(ctx)
+
+			cmd.SetArgs(append(rootArgs,
+				"--image",
+				"registry.io/image:tag",
+				"--policy",
+				fmt.Sprintf(`{"publicKey": %s}`, utils.TestPublicKeyJSON),
+				"--registry-rewrite",
+				c.rewrite,
+				"--output",
+				"json",
+			))
+
+			var out bytes.Buffer
+			cmd.SetOut(&out)
+
+			utils.SetTestRekorPublicKey(t)
+
+			err := cmd.Execute()
+			if c.err != "" {
+				assert.ErrorContains(t, err, c.err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, c.rewritten, rewritten)
+			assert.Contains(t, out.String(), `"containerImage":"registry.io/image:tag"`, "the upstream reference is reported")
+		})
+	}
+}
+
+func TestValidateImageCommandChangedSince(t *testing.T) {
+	var validated []string
+	validate := func(_ context.Context, component app.SnapshotComponent, _ *app.SnapshotSpec, _ policy.Policy, _ []evaluator.Evaluator, _ bool) (*output.Output, error) {
+		validated = append(validated, component.ContainerImage)
+		return &output.Output{
+			ImageSignatureCheck:       output.VerificationStatus{Passed: true},
+			ImageAccessibleCheck:      output.VerificationStatus{Passed: true},
+			AttestationSignatureCheck: output.VerificationStatus{Passed: true},
+			ImageURL:                  component.ContainerImage,
+			PolicyCheck: []evaluator.Outcome{{
+				Failures: []evaluator.Result{{Message: "Denied"}},
+			}},
+		}, nil
+	}
+
+	unchanged := "registry/a@sha256:" + strings.Repeat("a", 64)
+	changed := "registry/b@sha256:" + strings.Repeat("c", 64)
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/previous.yaml", []byte(hd.Doc(`
+		components:
+		- name: a
+		  containerImage: registry/a:v1@sha256:`+strings.Repeat("a", 64)+`
+		- name: b
+		  containerImage: registry/b@sha256:`+strings.Repeat("b", 64)+`
+	`)), 0644))
+
+	run := func(args ...string) (*bytes.Buffer, error) {
+		validated = nil
+		cmd := setUpCobra(validateImageCmd(validate))
+		cmd.SilenceUsage = true
+
+		client := fake.FakeClient{}
+		commonMockClient(&client)
+		ctx := utils.WithFS(context.Background(), fs)
+		ctx = oci.WithClient(ctx, &client)
+		cmd.SetContext(ctx)
+
+		cmd.SetArgs(append(append(rootArgs,
+			"--images",
+			fmt.Sprintf(`{"components":[{"name":"a","containerImage":%q},{"name":"b","containerImage":%q}]}`, unchanged, changed),
+			"--policy",
+			fmt.Sprintf(`{"publicKey": %s}`, utils.TestPublicKeyJSON),
+		), args...))
+
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+
+		utils.SetTestRekorPublicKey(t)
+
+		return &out, cmd.Execute()
+	}
+
+	_, err := run("--changed-since", "/missing.yaml")
+	assert.ErrorContains(t, err, "unable to read the previous snapshot")
+
+	out, err := run("--changed-since", "/previous.yaml", "--output", "json")
+	assert.EqualError(t, err, "success criteria not met")
+	assert.Equal(t, []string{changed}, validated)
+
+	var report map[string]any
+	require.NoError(t, json.Unmarshal(out.Bytes(), &report))
+	assert.Equal(t, []any{map[string]any{"name": "a", "containerImage": unchanged, "reason": "unchanged"}}, report["skipped"])
+	components := report["components"].([]any)
+	require.Len(t, components, 2)
+	assert.Nil(t, components[0].(map[string]any)["skipped"])
+	assert.Equal(t, "unchanged", components[1].(map[string]any)["skipped"])
+	assert.Equal(t, true, components[1].(map[string]any)["success"])
+
+	out, _ = run("--changed-since", "/previous.yaml", "--output", "text")
+	assert.Contains(t, out.String(), "Skipped: 1 image(s) were not validated\n")
+	assert.Contains(t, out.String(), "  Status: skipped (unchanged)\n")
+}
+
+func TestValidateImageCommandFailOnEmptySnapshot(t *testing.T) {
+	validate := func(_ context.Context, component app.SnapshotComponent, _ *app.SnapshotSpec, _ policy.Policy, _ []evaluator.Evaluator, _ bool) (*output.Output, error) {
+		return nil, fmt.Errorf("unexpected validation of %s", component.ContainerImage)
+	}
+
+	image := "registry/a@sha256:" + strings.Repeat("a", 64)
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/previous.yaml", []byte(hd.Doc(`
+		components:
+		- name: a
+		  containerImage: `+image+`
+	`)), 0644))
+
+	run := func(args ...string) error {
+		cmd := setUpCobra(validateImageCmd(validate))
+		cmd.SilenceUsage = true
+
+		client := fake.FakeClient{}
+		commonMockClient(&client)
+		ctx := utils.WithFS(context.Background(), fs)
+		ctx = oci.WithClient(ctx, &client)
+		cmd.SetContext(ctx)
+
+		cmd.SetArgs(append(append(rootArgs,
+			"--policy",
+			fmt.Sprintf(`{"publicKey": %s}`, utils.TestPublicKeyJSON),
+		), args...))
+		cmd.SetOut(&bytes.Buffer{})
+
+		utils.SetTestRekorPublicKey(t)
+
+		return cmd.Execute()
+	}
+
+	assert.NoError(t, run("--images", `{"components":[]}`))
+
+	err := run("--images", `{"components":[]}`, "--fail-on-empty-snapshot")
+	assert.EqualError(t, err, "empty snapshot, no images to validate: snapshot: 0 image(s)")
+	assert.Equal(t, root.ExitError, root.ExitCode(err))
+
+	err = run("--images", fmt.Sprintf(`{"components":[{"name":"a","containerImage":%q}]}`, image),
+		"--changed-since", "/previous.yaml", "--fail-on-empty-snapshot")
+	assert.EqualError(t, err, "empty snapshot, no images to validate: snapshot: 1 image(s) -> --changed-since /previous.yaml: 0 image(s)")
+}
+
+func TestValidateImageCommandExpectPolicyDigest(t *testing.T) {
+	cmd := setUpCobra(validateImageCmd(nil))
+	cmd.SilenceUsage = true
+
+	client := fake.FakeClient{}
+	commonMockClient(&client)
+	ctx := utils.WithFS(context.Background(), afero.NewMemMapFs())
+	ctx = oci.WithClient(ctx, &client)
+	cmd.SetContext(ctx)
+
+	cmd.SetArgs(append(rootArgs,
+		"--image",
+		"registry/image:tag",
+		"--policy",
+		fmt.Sprintf(`{"publicKey": %s}`, utils.TestPublicKeyJSON),
+		"--expect-policy-digest",
+		"sha256:abc",
+	))
+
+	utils.SetTestRekorPublicKey(t)
+
+	err := cmd.Execute()
+	assert.ErrorContains(t, err, `invalid expected policy digest "sha256:abc", expecting sha256:<hex> or <url>=sha256:<hex>`)
+}
+
+func TestValidateImageCommandRequireRuleCode(t *testing.T) {
+	cmd := setUpCobra(validateImageCmd(nil))
+	cmd.SilenceUsage = true
+
+	client := fake.FakeClient{}
+	commonMockClient(&client)
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(path.Join(dir, "rules.rego"), []byte(hd.Doc(`
+		package tests
+
+		# METADATA
+		# title: Defined
+		# custom:
+		#   short_name: defined
+		deny[result] {
+			result := {"code": "tests.defined", "msg": "Defined"}
+		}
+	`)), 0600))
+	ctx := utils.WithFS(context.Background(), afero.NewOsFs())
+	ctx = oci.WithClient(ctx, &client)
+	cmd.SetContext(ctx)
+
+	cmd.SetArgs(append(rootArgs,
+		"--image",
+		"registry/image:tag",
+		"--policy",
+		fmt.Sprintf(`{"publicKey": %s, "sources": [{"policy": [%q]}]}`, utils.TestPublicKeyJSON, dir),
+		"--require-rule-code",
+		"tests.defined",
+		"--require-rule-code",
+		"tests.missing",
+	))
+
+	utils.SetTestRekorPublicKey(t)
+
+	err := cmd.Execute()
+	assert.EqualError(t, err, "required rule codes not defined by the policy sources: tests.missing")
+}
+
+func TestValidateImageCommandSigningKey(t *testing.T) {
+	validate := func(ctx context.Context, component app.SnapshotComponent, _ *app.SnapshotSpec, _ policy.Policy, _ []evaluator.Evaluator, _ bool) (*output.Output, error) {
+		out := &output.Output{
+			ImageSignatureCheck:       output.VerificationStatus{Passed: true},
+			ImageAccessibleCheck:      output.VerificationStatus{Passed: true},
+			AttestationSignatureCheck: output.VerificationStatus{Passed: true},
+			ImageURL:                  component.ContainerImage,
+		}
+		out.SetSigningKeyCheckFromError([]signature.SigningKey{{Algorithm: "SHA256-RSA", KeySize: 1024}}, errors.New("the RSA key size of 1024 bits is below the minimum of 2048 bits"))
+
+		return out, nil
+	}
+
+	cases := []struct {
+		name string
+		args []string
+		err  string
+	}{
+		{name: "weak key", args: []string{"--min-key-size", "2048", "--allowed-signature-algorithm", "sha256-rsa"}, err: "success criteria not met"},
+		{name: "invalid key size", args: []string{"--min-key-size", "-1"}, err: "invalid minimum key size -1, expecting a positive number of bits"},
+		{name: "unknown algorithm", args: []string{"--allowed-signature-algorithm", "SHA3-RSA"}, err: `unknown signature algorithm "SHA3-RSA", expecting one of: `},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cmd := setUpCobra(validateImageCmd(validate))
+			cmd.SilenceUsage = true
+
+			client := fake.FakeClient{}
+			commonMockClient(&client)
+			ctx := utils.WithFS(context.Background(), afero.NewMemMapFs())
+			ctx = oci.WithClient(ctx, &client)
+			cmd.SetContext(ctx)
+
+			cmd.SetArgs(append(append(rootArgs,
+				"--image",
+				"registry.io/image:tag",
+				"--policy",
+				fmt.Sprintf(`{"publicKey": %s}`, utils.TestPublicKeyJSON),
+				"--output",
+				"json",
+			), c.args...))
+
+			var out bytes.Buffer
+			cmd.SetOut(&out)
+
+			utils.SetTestRekorPublicKey(t)
+
+			err := cmd.Execute()
+			assert.ErrorContains(t, err, c.err)
+			if c.err == "success criteria not met" {
+				assert.Contains(t, out.String(), `"signingKeys":[{"algorithm":"SHA256-RSA","keySize":1024}]`)
+				assert.Contains(t, out.String(), `"msg":"Signing key check failed: the RSA key size of 1024 bits is below the minimum of 2048 bits"`)
+			}
+		})
+	}
+}
+
+func TestValidateImageCommandResultCache(t *testing.T) {
+	calls := 0
+	validate := func(ctx context.Context, component app.SnapshotComponent, _ *app.SnapshotSpec, _ policy.Policy, _ []evaluator.Evaluator, _ bool) (*output.Output, error) {
+		calls++
+		return &output.Output{
+			ImageSignatureCheck:       output.VerificationStatus{Passed: true},
+			ImageAccessibleCheck:      output.VerificationStatus{Passed: true},
+			AttestationSignatureCheck: output.VerificationStatus{Passed: true},
+			ImageURL:                  component.ContainerImage,
+		}, nil
+	}
+
+	t.Setenv("EC_CACHE_DIR", "/cache")
+
+	fs := afero.NewMemMapFs()
+	run := func(args ...string) {
+		cmd := setUpCobra(validateImageCmd(validate))
+		cmd.SilenceUsage = true
+
+		client := fake.FakeClient{}
+		commonMockClient(&client)
+		ctx := utils.WithFS(context.Background(), fs)
+		ctx = oci.WithClient(ctx, &client)
+		cmd.SetContext(ctx)
+
+		cmd.SetArgs(append(append(rootArgs,
+			"--image",
+			"registry.io/repository/image@sha256:4e388ab32b10dc8dbc7e28144f552830adc74787c1e2c0824032078a79f227fb",
+			"--policy",
+			fmt.Sprintf(`{"publicKey": %s}`, utils.TestPublicKeyJSON),
+			"--output",
+			"json",
+		), args...))
+
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+
+		utils.SetTestRekorPublicKey(t)
+
+		require.NoError(t, cmd.Execute())
+		assert.Contains(t, out.String(), `"success":true`)
+	}
+
+	run()
+	run()
+	assert.Equal(t, 1, calls, "the cached result is used")
+
+	run("--info")
+	assert.Equal(t, 2, calls, "different flags invalidate the cached result")
+
+	run("--no-result-cache")
+	assert.Equal(t, 3, calls, "the cache is not used")
+
+	run("--max-image-age", "30d")
+	run("--max-image-age", "30d")
+	assert.Equal(t, 5, calls, "the cache is not used when checking the image age")
+
+	run("--effective-time", "2024-01-01T00:00:00Z")
+	run("--effective-time", "2024-01-01T00:00:00Z")
+	assert.Equal(t, 6, calls, "the cached result at the effective time is used")
+
+	run("--effective-time", "2024-06-01T00:00:00Z")
+	assert.Equal(t, 7, calls, "a different effective time invalidates the cached result")
+
+	entries, err := afero.ReadDir(fs, "/cache/results")
+	require.NoError(t, err)
+	assert.Len(t, entries, 4)
+}
+
+func TestValidateImageCommandNetworkBuiltins(t *testing.T) {
+	validate := func(_ context.Context, component app.SnapshotComponent, _ *app.SnapshotSpec, _ policy.Policy, _ []evaluator.Evaluator, _ bool) (*output.Output, error) {
+		return &output.Output{
+			ImageSignatureCheck:       output.VerificationStatus{Passed: true},
+			ImageAccessibleCheck:      output.VerificationStatus{Passed: true},
+			AttestationSignatureCheck: output.VerificationStatus{Passed: true},
+			ImageURL:                  component.ContainerImage,
+		}, nil
+	}
+
+	cases := []struct {
+		name string
+		args []string
+		err  string
+	}{
+		{name: "allowed", args: []string{"--allow-network-builtins", "--builtin-allowed-host", "allow-list.example.com"}},
+		{name: "without hosts", args: []string{"--allow-network-builtins"}, err: "--allow-network-builtins requires --builtin-allowed-host"},
+		{name: "host with port", args: []string{"--allow-network-builtins", "--builtin-allowed-host", "allow-list.example.com:8443"}, err: `invalid built-in allowed host "allow-list.example.com:8443", expecting a host name without a scheme, port or path`},
+		{name: "host with scheme", args: []string{"--allow-network-builtins", "--builtin-allowed-host", "https://allow-list.example.com"}, err: `invalid built-in allowed host "https://allow-list.example.com", expecting a host name without a scheme, port or path`},
+		{name: "without allowing", args: []string{"--builtin-allowed-host", "allow-list.example.com"}, err: "--builtin-allowed-host requires --allow-network-builtins"},
+		{name: "invalid timeout", args: []string{"--allow-network-builtins", "--builtin-allowed-host", "allow-list.example.com", "--builtin-timeout", "0s"}, err: "invalid built-in timeout 0s, expecting a positive duration"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cmd := setUpCobra(validateImageCmd(validate))
+			cmd.SilenceUsage = true
+
+			client := fake.FakeClient{}
+			commonMockClient(&client)
+			ctx := utils.WithFS(context.Background(), afero.NewMemMapFs())
+			ctx = oci.WithClient(ctx, &client)
+			cmd.SetContext(ctx)
+
+			cmd.SetArgs(append(append(rootArgs,
+				"--image",
+				"registry/image:tag",
+				"--policy",
+				fmt.Sprintf(`{"publicKey": %s}`, utils.TestPublicKeyJSON),
+			), c.args...))
+
+			var out bytes.Buffer
+			cmd.SetOut(&out)
+
+			utils.SetTestRekorPublicKey(t)
+
+			err := cmd.Execute()
+			if c.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, c.err)
+			}
+		})
+	}
+}
+
+func TestValidateImageCommandErrorExitCode(t *testing.T) {
+	validate := func(_ context.Context, component app.SnapshotComponent, _ *app.SnapshotSpec, _ policy.Policy, _ []evaluator.Evaluator, _ bool) (*output.Output, error) {
+		out := &output.Output{ImageURL: component.ContainerImage}
+		out.SetImageAccessibleCheckFromError(errors.New("404 Not Found"))
+
+		return out, nil
+	}
+
+	cmd := setUpCobra(validateImageCmd(validate))
+	cmd.SilenceUsage = true
+
+	client := fake.FakeClient{}
+	commonMockClient(&client)
+	ctx := utils.WithFS(context.Background(), afero.NewMemMapFs())
+	ctx = oci.WithClient(ctx, &client)
+	cmd.SetContext(ctx)
+
+	cmd.SetArgs(append(rootArgs,
+		"--image",
+		"registry/image:tag",
+		"--policy",
+		fmt.Sprintf(`{"publicKey": %s}`, utils.TestPublicKeyJSON),
+	))
+
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+
+	utils.SetTestRekorPublicKey(t)
+
+	err := cmd.Execute()
+	assert.EqualError(t, err, "success criteria not met")
+	assert.Equal(t, root.ExitError, root.ExitCode(err))
+	assert.Contains(t, out.String(), `"status":"error"`)
+	assert.Contains(t, out.String(), `"errors":1`)
+}
+
+func TestValidateImageCommandDumpInput(t *testing.T) {
+	validate := func(_ context.Context, component app.SnapshotComponent, _ *app.SnapshotSpec, _ policy.Policy, _ []evaluator.Evaluator, _ bool) (*output.Output, error) {
+		return &output.Output{
+			ImageSignatureCheck:       output.VerificationStatus{Passed: true},
+			ImageAccessibleCheck:      output.VerificationStatus{Passed: true},
+			AttestationSignatureCheck: output.VerificationStatus{Passed: true},
+			ImageURL:                  component.ContainerImage,
+			PolicyInput:               []byte(`{"image":{"ref":"registry/image:tag","source":"git.internal.example.com"}}`),
+		}, nil
+	}
+
+	fs := afero.NewMemMapFs()
+
+	cmd := setUpCobra(validateImageCmd(validate))
+	cmd.SilenceUsage = true
+
+	client := fake.FakeClient{}
+	commonMockClient(&client)
+	ctx := utils.WithFS(context.Background(), fs)
+	ctx = oci.WithClient(ctx, &client)
+	cmd.SetContext(ctx)
+
+	cmd.SetArgs(append(rootArgs,
+		"--image",
+		"registry/image:tag",
+		"--policy",
+		fmt.Sprintf(`{"publicKey": %s}`, utils.TestPublicKeyJSON),
+		"--dump-input",
+		"/input.json",
+		"--redact",
+		`internal\.example\.com`,
+	))
+
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+
+	utils.SetTestRekorPublicKey(t)
+
+	require.NoError(t, cmd.Execute())
+
+	dump, err := afero.ReadFile(fs, "/input.json")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"registry/image:tag": {"image": {"ref": "registry/image:tag", "source": "git.***"}}}`, string(dump))
+}
+
+func TestValidateImageCommandRetryBudget(t *testing.T) {
+	validate := func(_ context.Context, component app.SnapshotComponent, _ *app.SnapshotSpec, _ policy.Policy, _ []evaluator.Evaluator, _ bool) (*output.Output, error) {
+		return &output.Output{
+			ImageSignatureCheck:       output.VerificationStatus{Passed: true},
+			ImageAccessibleCheck:      output.VerificationStatus{Passed: true},
+			AttestationSignatureCheck: output.VerificationStatus{Passed: true},
+			ImageURL:                  component.ContainerImage,
+		}, nil
+	}
+
+	cases := []struct {
+		name string
+		args []string
+		err  string
+	}{
+		{name: "budget", args: []string{"--retry-budget", "30s"}},
+		{name: "invalid budget", args: []string{"--retry-budget", "-1s"}, err: "invalid retry budget -1s, expecting a positive duration"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cmd := setUpCobra(validateImageCmd(validate))
+			cmd.SilenceUsage = true
+
+			client := fake.FakeClient{}
+			commonMockClient(&client)
+			ctx := utils.WithFS(context.Background(), afero.NewMemMapFs())
+			ctx = oci.WithClient(ctx, &client)
+			cmd.SetContext(ctx)
+
+			cmd.SetArgs(append(append(rootArgs,
+				"--image",
+				"registry/image:tag",
+				"--policy",
+				fmt.Sprintf(`{"publicKey": %s}`, utils.TestPublicKeyJSON),
+			), c.args...))
+
+			var out bytes.Buffer
+			cmd.SetOut(&out)
+
+			utils.SetTestRekorPublicKey(t)
+
+			err := cmd.Execute()
+			if c.err == "" {
+				assert.NoError(t, err)
+				assert.NotContains(t, out.String(), "retryBudgetExhausted")
+			} else {
+				assert.ErrorContains(t, err, c.err)
+			}
+		})
+	}
+}
+
+func TestValidateImageCommandTUI(t *testing.T) {
+	validate := func(_ context.Context, component app.SnapshotComponent, _ *app.SnapshotSpec, _ policy.Policy, _ []evaluator.Evaluator, _ bool) (*output.Output, error) {
+		return &output.Output{
+			ImageSignatureCheck:       output.VerificationStatus{Passed: true},
+			ImageAccessibleCheck:      output.VerificationStatus{Passed: true},
+			AttestationSignatureCheck: output.VerificationStatus{Passed: true},
+			ImageURL:                  component.ContainerImage,
+			PolicyCheck: []evaluator.Outcome{
+				{
+					Warnings: []evaluator.Result{
+						{Message: "consider this", Metadata: map[string]any{"code": "pkg.rule"}},
+					},
+				},
+			},
+		}, nil
+	}
+
+	cases := []struct {
+		name        string
+		interactive bool
+		expected    string
+	}{
+		{name: "not a terminal", expected: "Results:"},
+		{name: "terminal", interactive: true, expected: "1 of 1 results"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			interactive := isInteractive
+			t.Cleanup(func() { isInteractive = interactive })
+			isInteractive = func() bool { return c.interactive }
+
+			cmd := setUpCobra(validateImageCmd(validate))
+			cmd.SilenceUsage = true
+
+			client := fake.FakeClient{}
+			commonMockClient(&client)
+			ctx := utils.WithFS(context.Background(), afero.NewMemMapFs())
+			ctx = oci.WithClient(ctx, &client)
+			cmd.SetContext(ctx)
+
+			cmd.SetArgs(append(rootArgs,
+				"--image",
+				"registry/image:tag",
+				"--policy",
+				fmt.Sprintf(`{"publicKey": %s}`, utils.TestPublicKeyJSON),
+				"--tui",
+			))
+
+			var out bytes.Buffer
+			cmd.SetOut(&out)
+			cmd.SetIn(strings.NewReader("quit\n"))
+
+			utils.SetTestRekorPublicKey(t)
+
+			require.NoError(t, cmd.Execute())
+			assert.Contains(t, out.String(), c.expected)
+			assert.NotContains(t, out.String(), `"success":true`)
+		})
+	}
+}
+
+func TestValidateImageCommandNormalizeDigests(t *testing.T) {
+	validate := func(_ context.Context, component app.SnapshotComponent, _ *app.SnapshotSpec, _ policy.Policy, _ []evaluator.Evaluator, _ bool) (*output.Output, error) {
+		return &output.Output{
+			ImageSignatureCheck:       output.VerificationStatus{Passed: true},
+			ImageAccessibleCheck:      output.VerificationStatus{Passed: true},
+			AttestationSignatureCheck: output.VerificationStatus{Passed: true},
+			ImageURL:                  component.ContainerImage,
+		}, nil
+	}
+
+	cases := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{name: "original", expected: `"containerImage":"docker.io/user/image:tag"`},
+		{name: "normalized", args: []string{"--normalize-digests"}, expected: `"containerImage":"index.docker.io/user/image:tag"`},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cmd := setUpCobra(validateImageCmd(validate))
+			cmd.SilenceUsage = true
+
+			client := fake.FakeClient{}
+			commonMockClient(&client)
+			ctx := utils.WithFS(context.Background(), afero.NewMemMapFs())
+			ctx = oci.WithClient(ctx, &client)
+			cmd.SetContext(ctx)
+
+			cmd.SetArgs(append(append(rootArgs,
+				"--image",
+				"docker.io/user/image:tag",
+				"--policy",
+				fmt.Sprintf(`{"publicKey": %s}`, utils.TestPublicKeyJSON),
+			), c.args...))
+
+			var out bytes.Buffer
+			cmd.SetOut(&out)
+
+			utils.SetTestRekorPublicKey(t)
+
+			require.NoError(t, cmd.Execute())
+			assert.Contains(t, out.String(), c.expected)
+		})
+	}
+}
+
+func TestValidateImageCommandOnlyFailures(t *testing.T) {
+	validate := func(_ context.Context, component app.SnapshotComponent, _ *app.SnapshotSpec, _ policy.Policy, _ []evaluator.Evaluator, _ bool) (*output.Output, error) {
+		return &output.Output{
+			ImageSignatureCheck:       output.VerificationStatus{Passed: true},
+			ImageAccessibleCheck:      output.VerificationStatus{Passed: true},
+			AttestationSignatureCheck: output.VerificationStatus{Passed: true},
+			ImageURL:                  component.ContainerImage,
+		}, nil
+	}
+
+	cmd := setUpCobra(validateImageCmd(validate))
+	cmd.SilenceUsage = true
+
+	client := fake.FakeClient{}
+	commonMockClient(&client)
+	ctx := utils.WithFS(context.Background(), afero.NewMemMapFs())
+	ctx = oci.WithClient(ctx, &client)
+	cmd.SetContext(ctx)
+
+	cmd.SetArgs(append(rootArgs,
+		"--image",
+		"registry/image:tag",
+		"--policy",
+		fmt.Sprintf(`{"publicKey": %s}`, utils.TestPublicKeyJSON),
+		"--only-failures",
+	))
+
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+
+	utils.SetTestRekorPublicKey(t)
+
+	require.NoError(t, cmd.Execute())
+	assert.Contains(t, out.String(), `"components":[]`)
+	assert.Contains(t, out.String(), `"totals":{"images":1,"passed":1,"failed":0,"errored":0,"violations":0,"warnings":0,"successes":`)
+}
+
+func TestValidateImageCommandResources(t *testing.T) {
+	cases := []struct {
+		name        string
+		args        []string
+		maxParallel int32
+		err         string
+	}{
+		{name: "default", maxParallel: defaultWorkers},
+		{name: "low", args: []string{"--resources", "low"}, maxParallel: 1},
+		{name: "high", args: []string{"--resources", "high"}, maxParallel: 16},
+		{name: "workers override profile", args: []string{"--resources", "high", "--workers", "1"}, maxParallel: 1},
+		{name: "unknown profile", args: []string{"--resources", "huge"}, err: `unknown resources profile "huge", expecting one of: default, high, low`},
+		{name: "invalid workers", args: []string{"--workers", "0"}, err: "invalid number of workers 0, expecting at least 1"},
+		{name: "invalid fetch concurrency", args: []string{"--fetch-concurrency", "0"}, err: "invalid fetch concurrency 0, expecting at least 1"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var running, maxRunning atomic.Int32
+			validate := func(_ context.Context, component app.SnapshotComponent, _ *app.SnapshotSpec, _ policy.Policy, _ []evaluator.Evaluator, _ bool) (*output.Output, error) {
+				n := running.Add(1)
+				defer running.Add(-1)
+				for m := maxRunning.Load(); n > m && !maxRunning.CompareAndSwap(m, n); m = maxRunning.Load() {
+				}
+				time.Sleep(10 * time.Millisecond)
+
+				return &output.Output{
+					ImageSignatureCheck:       output.VerificationStatus{Passed: true},
+					ImageAccessibleCheck:      output.VerificationStatus{Passed: true},
+					AttestationSignatureCheck: output.VerificationStatus{Passed: true},
+					ImageURL:                  component.ContainerImage,
+				}, nil
+			}
+
+			cmd := setUpCobra(validateImageCmd(validate))
+			cmd.SilenceUsage = true
+
+			client := fake.FakeClient{}
+			commonMockClient(&client)
+			ctx := utils.WithFS(context.Background(), afero.NewMemMapFs())
+			ctx = oci.WithClient(ctx, &client)
+			cmd.SetContext(ctx)
+
+			components := make([]string, 0, 3)
+			for i := 1; i <= 3; i++ {
+				components = append(components, fmt.Sprintf(`{"name": "c%d", "containerImage": "registry/image%d:tag"}`, i, i))
+			}
+
+			cmd.SetArgs(append(append(rootArgs,
+				"--images",
+				fmt.Sprintf(`{"components": [%s]}`, strings.Join(components, ", ")),
+				"--policy",
+				fmt.Sprintf(`{"publicKey": %s}`, utils.TestPublicKeyJSON),
+			), c.args...))
+
+			var out bytes.Buffer
+			cmd.SetOut(&out)
+
+			utils.SetTestRekorPublicKey(t)
+
+			err := cmd.Execute()
+			if c.err != "" {
+				assert.ErrorContains(t, err, c.err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.LessOrEqual(t, maxRunning.Load(), c.maxParallel)
+		})
+	}
+}
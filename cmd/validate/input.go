@@ -29,6 +29,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/enterprise-contract/ec-cli/internal/applicationsnapshot"
+	"github.com/enterprise-contract/ec-cli/internal/completion"
 	"github.com/enterprise-contract/ec-cli/internal/evaluator"
 	"github.com/enterprise-contract/ec-cli/internal/format"
 	"github.com/enterprise-contract/ec-cli/internal/input"
@@ -49,6 +50,7 @@ func validateInputCmd(validate InputValidationFunc) *cobra.Command {
 		output              []string
 		policy              policy.Policy
 		policyConfiguration string
+		resolveBundles      bool
 		strict              bool
 	}{
 		strict: true,
@@ -84,6 +86,11 @@ func validateInputCmd(validate InputValidationFunc) *cobra.Command {
 
 			  ec validate input --file /path/to/file.yaml --policy github.com/user/repo
 
+			Resolve the Tekton bundle references of a pipeline definition to digests, allowing the
+			policy rules to require bundles pinned by digest and to flag drift:
+
+			  ec validate input --file /path/to/pipeline.yaml --policy my-policy.yaml --resolve-bundles
+
 `),
 		PreRunE: func(cmd *cobra.Command, args []string) (allErrors error) {
 			ctx := cmd.Context()
@@ -103,6 +110,10 @@ func validateInputCmd(validate InputValidationFunc) *cobra.Command {
 			return
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SetContext(input.WithBundleResolutionOptions(cmd.Context(), input.BundleResolutionOptions{
+				Enabled: data.resolveBundles,
+			}))
+
 			type result struct {
 				err         error
 				input       input.Input
@@ -179,7 +190,8 @@ func validateInputCmd(validate InputValidationFunc) *cobra.Command {
 				return err
 			}
 
-			p := format.NewTargetParser(input.JSON, format.Options{ShowSuccesses: showSuccesses}, cmd.OutOrStdout(), utils.FS(cmd.Context()))
+			jsonCompact, _ := cmd.Flags().GetBool("json-compact")
+			p := format.NewTargetParser(input.JSON, format.Options{ShowSuccesses: showSuccesses, JSONCompact: jsonCompact}, cmd.OutOrStdout(), utils.FS(cmd.Context())).WithContext(cmd.Context())
 			if err := report.WriteAll(data.output, p); err != nil {
 				return err
 			}
@@ -197,7 +209,8 @@ func validateInputCmd(validate InputValidationFunc) *cobra.Command {
 	cmd.Flags().StringVarP(&data.policyConfiguration, "policy", "p", data.policyConfiguration, hd.Doc(`
 		Policy configuration as:
 		* file (policy.yaml)
-		* git reference (github.com/user/repo//default?ref=main), or
+		* git reference (github.com/user/repo//default?ref=main),
+		* builtin, the policy bundle embedded into ec at build time, or
 		* inline JSON ('{sources: {...}, configuration: {...}}')")`))
 
 	validOutputFormats := applicationsnapshot.OutputFormats
@@ -212,6 +225,13 @@ func validateInputCmd(validate InputValidationFunc) *cobra.Command {
 	cmd.Flags().BoolVarP(&data.strict, "strict", "s", data.strict,
 		"Return non-zero status on non-successful validation")
 
+	cmd.Flags().BoolVar(&data.resolveBundles, "resolve-bundles", data.resolveBundles, hd.Doc(`
+		Resolve the tag of each Tekton bundles resolver reference in the input files to a digest
+		and add the outcome as resolvedBundle, holding the reference, tag, pinnedDigest and digest,
+		next to the params of the reference. Bundles that cannot be resolved are reported as
+		warnings.
+	`))
+
 	cmd.Flags().StringVar(&data.effectiveTime, "effective-time", policy.Now, hd.Doc(`
 		Run policy checks with the provided time. Useful for testing rules with
 		effective dates in the future. The value can be "now" (default) - for
@@ -230,5 +250,7 @@ func validateInputCmd(validate InputValidationFunc) *cobra.Command {
 		panic(err)
 	}
 
+	completion.Register(cmd, "output", completion.Formats(validOutputFormats))
+
 	return cmd
 }
@@ -0,0 +1,181 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package validate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	hd "github.com/MakeNowJust/heredoc"
+	"github.com/hashicorp/go-multierror"
+	"github.com/spf13/cobra"
+
+	"github.com/enterprise-contract/ec-cli/internal/completion"
+	"github.com/enterprise-contract/ec-cli/internal/evaluator"
+	"github.com/enterprise-contract/ec-cli/internal/format"
+	"github.com/enterprise-contract/ec-cli/internal/input"
+	"github.com/enterprise-contract/ec-cli/internal/output"
+	"github.com/enterprise-contract/ec-cli/internal/policy"
+	"github.com/enterprise-contract/ec-cli/internal/repository"
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+	validate_utils "github.com/enterprise-contract/ec-cli/internal/validate"
+)
+
+type SourceValidationFunc func(context.Context, string, string, policy.Policy, bool) (*output.Output, error)
+
+func validateSourceCmd(validate SourceValidationFunc) *cobra.Command {
+	data := struct {
+		effectiveTime       string
+		info                bool
+		output              []string
+		policy              policy.Policy
+		policyConfiguration string
+		ref                 string
+		repo                string
+		strict              bool
+	}{
+		ref:    "HEAD",
+		repo:   ".",
+		strict: true,
+	}
+	cmd := &cobra.Command{
+		Use:   "source",
+		Short: "Validate conformance of a Git repository with the Enterprise Contract",
+		Long: hd.Doc(`
+			Validate conformance of a Git repository with the Enterprise Contract
+
+			Gathers the metadata of the Git repository at the given revision and validates it
+			against the rego policies in the ` + repository.Namespace + ` namespace of the policy sources
+			defined in the EnterpriseContractPolicy. This allows gating a commit before any image
+			is built from it.
+
+			The policy input is a document with the following keys:
+
+			  repo:       the path to the repository
+			  ref:        the revision as given
+			  commit:     the commit the revision resolves to: sha, parents, author, committer,
+			              message and whether it is signed
+			  files:      the paths of the files in the commit
+			  attributes: the attributes declared in the .gitattributes files
+			  codeowners: the rules of the CODEOWNERS file, if any
+			`),
+		Example: hd.Doc(`
+			Validate the current commit of the repository in the current directory
+
+			  ec validate source --policy my-policy.yaml
+
+			Validate a tag of a repository
+
+			  ec validate source --repo /path/to/repo --ref v1.0.0 --policy my-policy.yaml
+		`),
+		Args: cobra.NoArgs,
+		PreRunE: func(cmd *cobra.Command, args []string) (allErrors error) {
+			ctx := cmd.Context()
+
+			policyConfiguration, err := validate_utils.GetPolicyConfig(ctx, data.policyConfiguration)
+			if err != nil {
+				allErrors = multierror.Append(allErrors, err)
+				return
+			}
+			data.policyConfiguration = policyConfiguration
+
+			if p, err := policy.NewInputPolicy(cmd.Context(), data.policyConfiguration, data.effectiveTime); err != nil {
+				allErrors = multierror.Append(allErrors, err)
+			} else {
+				data.policy = p
+			}
+			return
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			showSuccesses, _ := cmd.Flags().GetBool("show-successes")
+
+			out, err := validate(cmd.Context(), data.repo, data.ref, data.policy, data.info)
+			if err != nil {
+				return fmt.Errorf("error validating the revision %s of the repository %s: %w", data.ref, data.repo, err)
+			}
+
+			result := input.Input{
+				FilePath:   fmt.Sprintf("%s@%s", data.repo, data.ref),
+				Violations: out.Violations(),
+				Warnings:   out.Warnings(),
+			}
+			successes := out.Successes()
+			result.SuccessCount = len(successes)
+			if showSuccesses {
+				result.Successes = successes
+			}
+			result.Success = len(result.Violations) == 0
+
+			report, err := input.NewReport([]input.Input{result}, data.policy, [][]evaluator.Data{out.Data}, [][]byte{out.PolicyInput})
+			if err != nil {
+				return err
+			}
+
+			jsonCompact, _ := cmd.Flags().GetBool("json-compact")
+			p := format.NewTargetParser(input.JSON, format.Options{ShowSuccesses: showSuccesses, JSONCompact: jsonCompact}, cmd.OutOrStdout(), utils.FS(cmd.Context())).WithContext(cmd.Context())
+			if err := report.WriteAll(data.output, p); err != nil {
+				return err
+			}
+
+			if data.strict && !report.Success {
+				return errors.New("success criteria not met")
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&data.repo, "repo", data.repo, "path to the root of the Git repository")
+
+	cmd.Flags().StringVar(&data.ref, "ref", data.ref, "revision to validate, e.g. HEAD, a branch, a tag or a commit SHA")
+
+	cmd.Flags().StringVarP(&data.policyConfiguration, "policy", "p", data.policyConfiguration, hd.Doc(`
+		Policy configuration as:
+		* file (policy.yaml)
+		* git reference (github.com/user/repo//default?ref=main), or
+		* inline JSON ('{sources: {...}, configuration: {...}}')")`))
+
+	validOutputFormats := []string{input.JSON, input.YAML, input.Summary, input.None}
+	cmd.Flags().StringSliceVarP(&data.output, "output", "o", data.output, hd.Doc(`
+		Write output to a file in a specific format, e.g. yaml=/tmp/output.yaml. Use empty string
+		path for stdout, e.g. yaml. May be used multiple times. Possible formats are:
+		`+strings.Join(validOutputFormats, ", ")+`.
+	`))
+
+	cmd.Flags().BoolVarP(&data.strict, "strict", "s", data.strict,
+		"Return non-zero status on non-successful validation")
+
+	cmd.Flags().StringVar(&data.effectiveTime, "effective-time", policy.Now, hd.Doc(`
+		Run policy checks with the provided time. Useful for testing rules with
+		effective dates in the future. The value can be "now" (default) - for
+		current time, or a RFC3339 formatted value, e.g. 2022-11-18T00:00:00Z.`))
+
+	cmd.Flags().BoolVar(&data.info, "info", data.info, hd.Doc(`
+		Include additional information on the failures. For instance for policy
+		violations, include the title and the description of the failed policy
+		rule.`))
+
+	if err := cmd.MarkFlagRequired("policy"); err != nil {
+		panic(err)
+	}
+
+	completion.Register(cmd, "output", completion.Formats(validOutputFormats))
+
+	return cmd
+}
@@ -0,0 +1,113 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package validate
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/evaluator"
+	"github.com/enterprise-contract/ec-cli/internal/output"
+	"github.com/enterprise-contract/ec-cli/internal/policy"
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+func TestValidateSourceCommand(t *testing.T) {
+	cases := []struct {
+		name     string
+		args     []string
+		outcome  evaluator.Outcome
+		repo     string
+		ref      string
+		expected string
+		err      string
+	}{
+		{
+			name: "defaults",
+			outcome: evaluator.Outcome{
+				Successes: []evaluator.Result{{Message: "Pass", Metadata: map[string]any{"code": "source.main.codeowners"}}},
+			},
+			repo: ".",
+			ref:  "HEAD",
+			expected: `{
+				"success": true,
+				"filepaths": [{"filepath": ".@HEAD", "violations": [], "warnings": [], "successes": null, "success": true, "success-count": 1}],
+				"policy": {"sources": [{"policy": ["github.com/org/policy"]}]},
+				"ec-version": "development",
+				"effective-time": "2024-01-01T00:00:00Z"
+			}`,
+		},
+		{
+			name: "violation",
+			args: []string{"--repo", "/repo", "--ref", "v1.0.0"},
+			outcome: evaluator.Outcome{
+				Failures: []evaluator.Result{{Message: "No CODEOWNERS file", Metadata: map[string]any{"code": "source.main.codeowners"}}},
+			},
+			repo: "/repo",
+			ref:  "v1.0.0",
+			expected: `{
+				"success": false,
+				"filepaths": [{"filepath": "/repo@v1.0.0", "violations": [{"msg": "No CODEOWNERS file", "metadata": {"code": "source.main.codeowners"}}], "warnings": [], "successes": null, "success": false, "success-count": 0}],
+				"policy": {"sources": [{"policy": ["github.com/org/policy"]}]},
+				"ec-version": "development",
+				"effective-time": "2024-01-01T00:00:00Z"
+			}`,
+			err: "success criteria not met",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			validate := func(_ context.Context, repo, ref string, _ policy.Policy, _ bool) (*output.Output, error) {
+				assert.Equal(t, c.repo, repo)
+				assert.Equal(t, c.ref, ref)
+
+				return &output.Output{PolicyCheck: []evaluator.Outcome{c.outcome}}, nil
+			}
+
+			cmd := setUpCobra(validateSourceCmd(validate))
+			cmd.SetContext(utils.WithFS(context.Background(), afero.NewMemMapFs()))
+
+			var out bytes.Buffer
+			cmd.SetOut(&out)
+
+			cmd.SetArgs(append([]string{
+				"validate",
+				"source",
+				"--policy",
+				`{"sources":[{"policy":["github.com/org/policy"]}]}`,
+				"--effective-time",
+				"2024-01-01T00:00:00Z",
+			}, c.args...))
+
+			err := cmd.Execute()
+			if c.err != "" {
+				assert.EqualError(t, err, c.err)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.JSONEq(t, c.expected, out.String())
+		})
+	}
+}
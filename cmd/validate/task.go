@@ -0,0 +1,235 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package validate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	hd "github.com/MakeNowJust/heredoc"
+	"github.com/hashicorp/go-multierror"
+	"github.com/spf13/cobra"
+
+	"github.com/enterprise-contract/ec-cli/internal/completion"
+	"github.com/enterprise-contract/ec-cli/internal/evaluator"
+	"github.com/enterprise-contract/ec-cli/internal/format"
+	"github.com/enterprise-contract/ec-cli/internal/input"
+	"github.com/enterprise-contract/ec-cli/internal/output"
+	"github.com/enterprise-contract/ec-cli/internal/policy"
+	"github.com/enterprise-contract/ec-cli/internal/tekton"
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+	validate_utils "github.com/enterprise-contract/ec-cli/internal/validate"
+)
+
+type TaskValidationFunc func(context.Context, string, string, policy.Policy, bool) (*output.Output, error)
+
+func validateTaskCmd(validate TaskValidationFunc) *cobra.Command {
+	data := struct {
+		effectiveTime       string
+		info                bool
+		output              []string
+		policy              policy.Policy
+		policyConfiguration string
+		stepActionFiles     []string
+		strict              bool
+		taskFiles           []string
+	}{
+		strict: true,
+	}
+	cmd := &cobra.Command{
+		Use:   "task",
+		Short: "Validate conformance of Tekton Tasks and StepActions with the Enterprise Contract",
+		Long: hd.Doc(`
+			Validate conformance of Tekton Tasks and StepActions with the Enterprise Contract
+
+			Each Task is validated against the rego policies in the ` + tekton.Namespace(tekton.TaskKind) + ` namespace,
+			and each StepAction against the rego policies in the ` + tekton.Namespace(tekton.StepActionKind) + ` namespace
+			of the policy sources defined in the EnterpriseContractPolicy. A file can hold multiple
+			resources as multiple YAML documents, all of the kind given by the flag. The results are
+			reported for each file.
+			`),
+		Example: hd.Doc(`
+			Validate a Task using an EnterpriseContractPolicy spec from a local YAML file
+
+			  ec validate task --task-file /path/to/task.yaml --policy my-policy.yaml
+
+			Validate Tasks and StepActions, the flags can be repeated for multiple files
+
+			  ec validate task --task-file /path/to/task.yaml --stepaction-file /path/to/stepactions.yaml \
+			    --stepaction-file /path/to/other-stepaction.yaml --policy my-policy.yaml
+		`),
+		Args: cobra.NoArgs,
+		PreRunE: func(cmd *cobra.Command, args []string) (allErrors error) {
+			ctx := cmd.Context()
+
+			policyConfiguration, err := validate_utils.GetPolicyConfig(ctx, data.policyConfiguration)
+			if err != nil {
+				allErrors = multierror.Append(allErrors, err)
+				return
+			}
+			data.policyConfiguration = policyConfiguration
+
+			if p, err := policy.NewInputPolicy(cmd.Context(), data.policyConfiguration, data.effectiveTime); err != nil {
+				allErrors = multierror.Append(allErrors, err)
+			} else {
+				data.policy = p
+			}
+			return
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			type file struct {
+				path string
+				kind string
+			}
+			var files []file
+			for _, f := range data.taskFiles {
+				files = append(files, file{f, tekton.TaskKind})
+			}
+			for _, f := range data.stepActionFiles {
+				files = append(files, file{f, tekton.StepActionKind})
+			}
+
+			type result struct {
+				err   error
+				input input.Input
+				data  []evaluator.Data
+			}
+
+			ch := make(chan result, len(files))
+
+			var lock sync.WaitGroup
+
+			showSuccesses, _ := cmd.Flags().GetBool("show-successes")
+
+			for _, f := range files {
+				lock.Add(1)
+				go func(f file) {
+					defer lock.Done()
+
+					out, err := validate(cmd.Context(), f.path, f.kind, data.policy, data.info)
+					res := result{
+						err: err,
+						input: input.Input{
+							FilePath: f.path,
+						},
+					}
+					// Skip on err to not panic. Error is return on routine completion.
+					if err == nil {
+						res.input.Violations = out.Violations()
+						res.input.Warnings = out.Warnings()
+
+						successes := out.Successes()
+						res.input.SuccessCount = len(successes)
+						if showSuccesses {
+							res.input.Successes = successes
+						}
+						res.data = out.Data
+					}
+					res.input.Success = err == nil && len(res.input.Violations) == 0
+					ch <- res
+				}(f)
+			}
+
+			lock.Wait()
+			close(ch)
+
+			var inputs []input.Input
+			var manyData [][]evaluator.Data
+			var allErrors error = nil
+
+			for r := range ch {
+				if r.err != nil {
+					e := fmt.Errorf("error validating file %s: %w", r.input.FilePath, r.err)
+					allErrors = multierror.Append(allErrors, e)
+				} else {
+					inputs = append(inputs, r.input)
+					manyData = append(manyData, r.data)
+				}
+			}
+			if allErrors != nil {
+				return allErrors
+			}
+
+			// Ensure some consistency in output.
+			sort.Slice(inputs, func(i, j int) bool {
+				return inputs[i].FilePath > inputs[j].FilePath
+			})
+
+			report, err := input.NewReport(inputs, data.policy, manyData, nil)
+			if err != nil {
+				return err
+			}
+
+			jsonCompact, _ := cmd.Flags().GetBool("json-compact")
+			p := format.NewTargetParser(input.JSON, format.Options{ShowSuccesses: showSuccesses, JSONCompact: jsonCompact}, cmd.OutOrStdout(), utils.FS(cmd.Context())).WithContext(cmd.Context())
+			if err := report.WriteAll(data.output, p); err != nil {
+				return err
+			}
+
+			if data.strict && !report.Success {
+				return errors.New("success criteria not met")
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&data.taskFiles, "task-file", data.taskFiles, "path to a YAML/JSON file with Tasks, may be repeated")
+
+	cmd.Flags().StringSliceVar(&data.stepActionFiles, "stepaction-file", data.stepActionFiles, "path to a YAML/JSON file with StepActions, may be repeated")
+
+	cmd.Flags().StringVarP(&data.policyConfiguration, "policy", "p", data.policyConfiguration, hd.Doc(`
+		Policy configuration as:
+		* file (policy.yaml)
+		* git reference (github.com/user/repo//default?ref=main),
+		* builtin, the policy bundle embedded into ec at build time, or
+		* inline JSON ('{sources: {...}, configuration: {...}}')")`))
+
+	validOutputFormats := []string{input.JSON, input.YAML, input.Summary, input.None}
+	cmd.Flags().StringSliceVarP(&data.output, "output", "o", data.output, hd.Doc(`
+		Write output to a file in a specific format, e.g. yaml=/tmp/output.yaml. Use empty string
+		path for stdout, e.g. yaml. May be used multiple times. Possible formats are:
+		`+strings.Join(validOutputFormats, ", ")+`.
+	`))
+
+	cmd.Flags().BoolVarP(&data.strict, "strict", "s", data.strict,
+		"Return non-zero status on non-successful validation")
+
+	cmd.Flags().StringVar(&data.effectiveTime, "effective-time", policy.Now, hd.Doc(`
+		Run policy checks with the provided time. Useful for testing rules with
+		effective dates in the future. The value can be "now" (default) - for
+		current time, or a RFC3339 formatted value, e.g. 2022-11-18T00:00:00Z.`))
+
+	cmd.Flags().BoolVar(&data.info, "info", data.info, hd.Doc(`
+		Include additional information on the failures. For instance for policy
+		violations, include the title and the description of the failed policy
+		rule.`))
+
+	cmd.MarkFlagsOneRequired("task-file", "stepaction-file")
+
+	if err := cmd.MarkFlagRequired("policy"); err != nil {
+		panic(err)
+	}
+
+	completion.Register(cmd, "output", completion.Formats(validOutputFormats))
+
+	return cmd
+}
@@ -0,0 +1,128 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package validate
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/evaluator"
+	"github.com/enterprise-contract/ec-cli/internal/output"
+	"github.com/enterprise-contract/ec-cli/internal/policy"
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+func TestValidateTaskCommand(t *testing.T) {
+	cases := []struct {
+		name     string
+		args     []string
+		expected string
+		err      string
+	}{
+		{
+			name: "task and step action",
+			args: []string{"--task-file", "/task.yaml", "--stepaction-file", "/stepaction.yaml"},
+			expected: `{
+				"success": false,
+				"filepaths": [
+					{"filepath": "/task.yaml", "violations": [{"msg": "Step image not pinned", "metadata": {"code": "task.main.pinned_images"}}], "warnings": [], "successes": null, "success": false, "success-count": 0},
+					{"filepath": "/stepaction.yaml", "violations": [], "warnings": [], "successes": null, "success": true, "success-count": 1}
+				],
+				"policy": {"sources": [{"policy": ["github.com/org/policy"]}]},
+				"ec-version": "development",
+				"effective-time": "2024-01-01T00:00:00Z"
+			}`,
+			err: "success criteria not met",
+		},
+		{
+			name: "non strict",
+			args: []string{"--task-file", "/task.yaml", "--strict=false"},
+			expected: `{
+				"success": false,
+				"filepaths": [
+					{"filepath": "/task.yaml", "violations": [{"msg": "Step image not pinned", "metadata": {"code": "task.main.pinned_images"}}], "warnings": [], "successes": null, "success": false, "success-count": 0}
+				],
+				"policy": {"sources": [{"policy": ["github.com/org/policy"]}]},
+				"ec-version": "development",
+				"effective-time": "2024-01-01T00:00:00Z"
+			}`,
+		},
+		{
+			name: "invalid file",
+			args: []string{"--stepaction-file", "/invalid.yaml"},
+			err:  "1 error occurred:\n\t* error validating file /invalid.yaml: expected error\n\n",
+		},
+		{
+			name: "no files",
+			err:  "at least one of the flags in the group [task-file stepaction-file] is required",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			validate := func(_ context.Context, fpath, kind string, _ policy.Policy, _ bool) (*output.Output, error) {
+				switch fpath {
+				case "/task.yaml":
+					assert.Equal(t, "Task", kind)
+					return &output.Output{PolicyCheck: []evaluator.Outcome{{
+						Failures: []evaluator.Result{{Message: "Step image not pinned", Metadata: map[string]any{"code": "task.main.pinned_images"}}},
+					}}}, nil
+				case "/stepaction.yaml":
+					assert.Equal(t, "StepAction", kind)
+					return &output.Output{PolicyCheck: []evaluator.Outcome{{
+						Successes: []evaluator.Result{{Message: "Pass", Metadata: map[string]any{"code": "stepaction.main.pinned_image"}}},
+					}}}, nil
+				}
+
+				return nil, errors.New("expected error")
+			}
+
+			cmd := setUpCobra(validateTaskCmd(validate))
+			cmd.SetContext(utils.WithFS(context.Background(), afero.NewMemMapFs()))
+
+			var out bytes.Buffer
+			cmd.SetOut(&out)
+
+			cmd.SetArgs(append([]string{
+				"validate",
+				"task",
+				"--policy",
+				`{"sources":[{"policy":["github.com/org/policy"]}]}`,
+				"--effective-time",
+				"2024-01-01T00:00:00Z",
+			}, c.args...))
+
+			err := cmd.Execute()
+			if c.err != "" {
+				assert.EqualError(t, err, c.err)
+			} else {
+				require.NoError(t, err)
+			}
+			if c.expected != "" {
+				assert.JSONEq(t, c.expected, out.String())
+			}
+		})
+	}
+}
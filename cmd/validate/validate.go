@@ -17,6 +17,7 @@
 package validate
 
 import (
+	hd "github.com/MakeNowJust/heredoc"
 	"github.com/spf13/cobra"
 
 	"github.com/enterprise-contract/ec-cli/internal/definition"
@@ -24,6 +25,8 @@ import (
 	"github.com/enterprise-contract/ec-cli/internal/input"
 	"github.com/enterprise-contract/ec-cli/internal/policy"
 	_ "github.com/enterprise-contract/ec-cli/internal/rego"
+	"github.com/enterprise-contract/ec-cli/internal/repository"
+	"github.com/enterprise-contract/ec-cli/internal/tekton"
 )
 
 var ValidateCmd *cobra.Command
@@ -36,6 +39,8 @@ func init() {
 	ValidateCmd.AddCommand(validateImageCmd(image.ValidateImage))
 	ValidateCmd.AddCommand(validateDefinitionCmd(definition.ValidateDefinition))
 	ValidateCmd.AddCommand(validateInputCmd(input.ValidateInput))
+	ValidateCmd.AddCommand(validateSourceCmd(repository.ValidateSource))
+	ValidateCmd.AddCommand(validateTaskCmd(tekton.ValidateResource))
 	ValidateCmd.AddCommand(ValidatePolicyCmd(policy.ValidatePolicy))
 }
 
@@ -44,6 +49,12 @@ func NewValidateCmd() *cobra.Command {
 		Use:   "validate",
 		Short: "Validate conformance with the Enterprise Contract",
 	}
-	validateCmd.PersistentFlags().Bool("show-successes", false, "")
+	validateCmd.PersistentFlags().Bool("show-successes", false, hd.Doc(`
+		include the policy rules and the built-in checks that passed in the output, each with
+		its code and metadata, e.g. as evidence of the controls checked. Considerably enlarges
+		the output.`))
+	validateCmd.PersistentFlags().Bool("json-compact", false, hd.Doc(`
+		write the JSON output on a single line. By default JSON written to a terminal is
+		indented, and JSON written elsewhere, e.g. to a file or a pipe, is on a single line.`))
 	return validateCmd
 }
@@ -0,0 +1,302 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package verify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	hd "github.com/MakeNowJust/heredoc"
+	"github.com/hashicorp/go-multierror"
+	app "github.com/konflux-ci/application-api/api/v1alpha1"
+	"github.com/sigstore/cosign/v2/pkg/cosign"
+	"github.com/spf13/cobra"
+
+	"github.com/enterprise-contract/ec-cli/internal/applicationsnapshot"
+	"github.com/enterprise-contract/ec-cli/internal/attestation"
+	"github.com/enterprise-contract/ec-cli/internal/completion"
+	"github.com/enterprise-contract/ec-cli/internal/format"
+	"github.com/enterprise-contract/ec-cli/internal/output"
+	"github.com/enterprise-contract/ec-cli/internal/policy"
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+type imageVerificationFunc func(context.Context, app.SnapshotComponent, policy.Policy, bool) (*output.Output, error)
+
+func verifyImageCmd(verify imageVerificationFunc) *cobra.Command {
+	data := struct {
+		allowedPayloadTypes         []string
+		attestationCosignerKeys     []string
+		requiredAttestationSigners  int
+		certificateIdentity         string
+		certificateIdentityRegExp   string
+		certificateOIDCIssuer       string
+		certificateOIDCIssuerRegExp string
+		ignoreRekor                 bool
+		images                      []string
+		info                        bool
+		output                      []string
+		policy                      policy.Policy
+		publicKey                   string
+		rekorURL                    string
+		strict                      bool
+		tsaCertChain                string
+		workers                     int
+	}{
+		allowedPayloadTypes: attestation.DefaultPayloadTypes,
+		strict:              true,
+		workers:             defaultWorkers,
+	}
+
+	validOutputFormats := []string{applicationsnapshot.JSON, applicationsnapshot.YAML, applicationsnapshot.Text, applicationsnapshot.Summary, applicationsnapshot.None}
+
+	cmd := &cobra.Command{
+		Use:   "image",
+		Short: "Verify the signature and the attestations of container images",
+		Long: hd.Doc(`
+			Verify the signature and the attestations of container images
+
+			Only verifies that each image is signed, and that its attestations are signed and
+			syntactically valid. No policy is evaluated, making this a faster check for gates
+			that only care about signatures. The verification is the same as the one performed
+			by "ec validate image".
+		`),
+		Example: hd.Doc(`
+			Verify an image signed with a long-lived key:
+
+			  ec verify image --image registry/name:tag --public-key key.pub
+
+			Verify multiple images signed keyless:
+
+			  ec verify image --image registry/name:tag --image registry/other:tag \
+			    --certificate-identity https://github.com/org/repo/.github/workflows/release.yaml@refs/heads/main \
+			    --certificate-oidc-issuer https://token.actions.githubusercontent.com
+		`),
+		Args: cobra.NoArgs,
+		PreRunE: func(cmd *cobra.Command, args []string) (allErrors error) {
+			if p, err := policy.NewPolicy(cmd.Context(), policy.Options{
+				EffectiveTime: policy.Now,
+				Identity: cosign.Identity{
+					Issuer:        data.certificateOIDCIssuer,
+					IssuerRegExp:  data.certificateOIDCIssuerRegExp,
+					Subject:       data.certificateIdentity,
+					SubjectRegExp: data.certificateIdentityRegExp,
+				},
+				IgnoreRekor:  data.ignoreRekor,
+				PublicKey:    data.publicKey,
+				RekorURL:     data.rekorURL,
+				TSACertChain: data.tsaCertChain,
+			}); err != nil {
+				allErrors = multierror.Append(allErrors, err)
+			} else {
+				data.policy = p
+			}
+
+			cmd.SetContext(attestation.WithAllowedPayloadTypes(cmd.Context(), data.allowedPayloadTypes))
+
+			if cosigners, err := attestation.LoadCosigners(cmd.Context(), data.attestationCosignerKeys); err != nil {
+				allErrors = multierror.Append(allErrors, err)
+			} else if data.requiredAttestationSigners < 0 || data.requiredAttestationSigners > len(cosigners)+1 {
+				allErrors = multierror.Append(allErrors, fmt.Errorf("invalid number of required attestation signers %d, expecting 0 to %d", data.requiredAttestationSigners, len(cosigners)+1))
+			} else {
+				cmd.SetContext(attestation.WithCosignerOptions(cmd.Context(), attestation.CosignerOptions{
+					Cosigners: cosigners,
+					Required:  data.requiredAttestationSigners,
+				}))
+			}
+
+			if data.workers < 1 {
+				allErrors = multierror.Append(allErrors, fmt.Errorf("invalid number of workers %d, expecting at least 1", data.workers))
+			}
+
+			return
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			showSuccesses, _ := cmd.Flags().GetBool("show-successes")
+
+			type result struct {
+				err       error
+				component applicationsnapshot.Component
+			}
+
+			results := make([]result, len(data.images))
+			verifyImage := func(i int, comp app.SnapshotComponent) {
+				out, err := verify(cmd.Context(), comp, data.policy, data.info)
+				if err != nil {
+					results[i] = result{err: fmt.Errorf("error verifying image %s: %w", comp.ContainerImage, err)}
+					return
+				}
+
+				c := applicationsnapshot.Component{
+					SnapshotComponent: comp,
+					Violations:        out.Violations(),
+					Warnings:          out.Warnings(),
+					Signatures:        out.Signatures,
+					Attestations:      out.Attestations,
+					SignerIdentities:  out.SignerIdentities,
+					SigningTimes:      out.SigningTimes,
+				}
+				c.ContainerImage = out.ImageURL
+				successes := out.Successes()
+				c.SuccessCount = len(successes)
+				if showSuccesses {
+					c.Successes = successes
+				}
+				if out.Verification != (output.Verification{}) {
+					c.Verification = &out.Verification
+				}
+				c.Success = len(c.Violations) == 0
+				results[i] = result{component: c}
+			}
+
+			// The workers pick the index of the next image to verify from the
+			// jobs channel until it is closed
+			jobs := make(chan int, len(data.images))
+			for i := range data.images {
+				jobs <- i
+			}
+			close(jobs)
+
+			var wg sync.WaitGroup
+			for w := 0; w < min(data.workers, len(data.images)); w++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for i := range jobs {
+						verifyImage(i, app.SnapshotComponent{Name: "Unnamed", ContainerImage: data.images[i]})
+					}
+				}()
+			}
+			wg.Wait()
+
+			var allErrors error
+			components := make([]applicationsnapshot.Component, 0, len(results))
+			for _, r := range results {
+				if r.err != nil {
+					allErrors = multierror.Append(allErrors, r.err)
+					continue
+				}
+				components = append(components, r.component)
+			}
+			if allErrors != nil {
+				return allErrors
+			}
+
+			// Ensure some consistency in output.
+			sort.Slice(components, func(i, j int) bool {
+				return components[i].ContainerImage > components[j].ContainerImage
+			})
+
+			report, err := applicationsnapshot.NewReport("", components, data.policy, nil, nil, showSuccesses)
+			if err != nil {
+				return err
+			}
+
+			jsonCompact, _ := cmd.Flags().GetBool("json-compact")
+			p := format.NewTargetParser(applicationsnapshot.JSON, format.Options{ShowSuccesses: showSuccesses, JSONCompact: jsonCompact}, cmd.OutOrStdout(), utils.FS(cmd.Context())).WithContext(cmd.Context())
+			if err := report.WriteAll(data.output, p); err != nil {
+				return err
+			}
+
+			if data.strict && !report.Success {
+				return errors.New("success criteria not met")
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringArrayVarP(&data.images, "image", "i", data.images, "OCI image reference. May be used multiple times")
+
+	cmd.Flags().IntVar(&data.workers, "workers", data.workers, "Number of images verified concurrently")
+
+	cmd.Flags().StringVarP(&data.publicKey, "public-key", "k", data.publicKey, hd.Doc(`
+		path to the public key, or a reference to a public key, e.g. k8s://namespace/secret,
+		awskms://, gcpkms://, azurekms:// or hashivault://`))
+
+	cmd.Flags().StringVarP(&data.rekorURL, "rekor-url", "r", data.rekorURL, "Rekor URL")
+
+	cmd.Flags().BoolVar(&data.ignoreRekor, "ignore-rekor", data.ignoreRekor,
+		"Skip Rekor transparency log checks during verification.")
+
+	cmd.Flags().StringVar(&data.tsaCertChain, "timestamp-certificate-chain", data.tsaCertChain, hd.Doc(`
+		path to the PEM encoded certificate chain of the trusted timestamp authority. The
+		validity of the signing certificates is checked at the time of the trusted timestamps
+		of the signatures, when present, otherwise at the time the signatures were integrated
+		into the Rekor transparency log, and only at the current time if neither is
+		available. The time used is included in the output as signingTimes.`))
+
+	cmd.Flags().StringSliceVar(&data.allowedPayloadTypes, "allowed-payload-type", data.allowedPayloadTypes, hd.Doc(`
+		DSSE payload type allowed for the attestations, e.g. for custom predicates not
+		wrapped in in-toto statements. Can be repeated. Attestations declaring any other
+		payload type fail the verification.`))
+
+	cmd.Flags().StringArrayVar(&data.attestationCosignerKeys, "attestation-cosigner-key", data.attestationCosignerKeys, hd.Doc(`
+		public key of a co-signer of the DSSE envelopes of the attestations, given as for
+		--public-key. Can be repeated. The signatures of the co-signers are counted towards
+		--required-attestation-signers. Only keys are supported, keyless co-signers cannot be
+		verified since the signatures of a DSSE envelope do not carry the certificates of
+		their signers.`))
+
+	cmd.Flags().IntVar(&data.requiredAttestationSigners, "required-attestation-signers", data.requiredAttestationSigners, hd.Doc(`
+		number of distinct signers that must have signed the DSSE envelope of each attestation,
+		counting the public key, or the certificate, the attestation is verified with and the
+		keys given by --attestation-cosigner-key. Each of the signatures of the envelopes is
+		verified, and whether it verified, and the signer it verified with, is included in its
+		metadata as verified and signer. By default an envelope is accepted on its first valid
+		signature.`))
+
+	cmd.Flags().StringVar(&data.certificateIdentity, "certificate-identity", data.certificateIdentity,
+		"URL of the certificate identity for keyless verification")
+
+	cmd.Flags().StringVar(&data.certificateIdentityRegExp, "certificate-identity-regexp", data.certificateIdentityRegExp,
+		"Regular expression for the URL of the certificate identity for keyless verification, when given with --certificate-identity matching either suffices")
+
+	cmd.Flags().StringVar(&data.certificateOIDCIssuer, "certificate-oidc-issuer", data.certificateOIDCIssuer,
+		"URL of the certificate OIDC issuer for keyless verification")
+
+	cmd.Flags().StringVar(&data.certificateOIDCIssuerRegExp, "certificate-oidc-issuer-regexp", data.certificateOIDCIssuerRegExp,
+		"Regular expresssion for the URL of the certificate OIDC issuer for keyless verification, when given with --certificate-oidc-issuer matching either suffices")
+
+	cmd.Flags().StringSliceVarP(&data.output, "output", "o", data.output, hd.Doc(`
+		Write output to a file in a specific format, e.g. yaml=/tmp/output.yaml. Use empty string
+		path for stdout, e.g. yaml. May be used multiple times. Possible formats are:
+		`+strings.Join(validOutputFormats, ", ")+`.
+	`))
+
+	cmd.Flags().BoolVarP(&data.strict, "strict", "s", data.strict,
+		"Return non-zero status on failed verification. Use --strict=false to return a zero status code.")
+
+	cmd.Flags().BoolVar(&data.info, "info", data.info, hd.Doc(`
+		Include additional information on the failures, e.g. the title and the description
+		of the failed check.`))
+
+	if err := cmd.MarkFlagRequired("image"); err != nil {
+		panic(err)
+	}
+
+	completion.Register(cmd, "output", completion.Formats(validOutputFormats))
+
+	return cmd
+}
+
+// defaultWorkers is the number of images verified concurrently by default
+const defaultWorkers = 5
@@ -0,0 +1,163 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package verify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	app "github.com/konflux-ci/application-api/api/v1alpha1"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/cmd/root"
+	"github.com/enterprise-contract/ec-cli/internal/applicationsnapshot"
+	"github.com/enterprise-contract/ec-cli/internal/evaluator"
+	"github.com/enterprise-contract/ec-cli/internal/output"
+	"github.com/enterprise-contract/ec-cli/internal/policy"
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+func setUpCobra(verify imageVerificationFunc) *cobra.Command {
+	verifyCmd := NewVerifyCmd()
+	verifyCmd.AddCommand(verifyImageCmd(verify))
+	cmd := root.NewRootCmd()
+	cmd.AddCommand(verifyCmd)
+	return cmd
+}
+
+func TestVerifyImage(t *testing.T) {
+	verify := func(_ context.Context, comp app.SnapshotComponent, _ policy.Policy, _ bool) (*output.Output, error) {
+		out := &output.Output{
+			ImageURL:                  comp.ContainerImage + "@sha256:digest",
+			ImageAccessibleCheck:      output.VerificationStatus{Passed: true},
+			ImageSignatureCheck:       output.VerificationStatus{Passed: true},
+			AttestationSignatureCheck: output.VerificationStatus{Passed: true},
+			AttestationSyntaxCheck:    output.VerificationStatus{Passed: true},
+		}
+		if comp.ContainerImage == "registry/unsigned" {
+			out.ImageSignatureCheck = output.VerificationStatus{Result: &evaluator.Result{Message: "no signatures found"}}
+		}
+		return out, nil
+	}
+
+	cases := []struct {
+		name    string
+		images  []string
+		success bool
+		err     string
+	}{
+		{name: "signed", images: []string{"registry/signed"}, success: true},
+		{name: "unsigned", images: []string{"registry/signed", "registry/unsigned"}, err: "success criteria not met"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cmd := setUpCobra(verify)
+			cmd.SetContext(utils.WithFS(context.Background(), afero.NewMemMapFs()))
+
+			args := []string{"verify", "image", "--public-key", utils.TestPublicKey, "--ignore-rekor"}
+			for _, i := range c.images {
+				args = append(args, "--image", i)
+			}
+			cmd.SetArgs(args)
+
+			var out bytes.Buffer
+			cmd.SetOut(&out)
+
+			err := cmd.Execute()
+			if c.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, c.err)
+			}
+
+			var report applicationsnapshot.Report
+			require.NoError(t, json.Unmarshal(out.Bytes(), &report))
+			assert.Equal(t, c.success, report.Success)
+			require.Len(t, report.Components, len(c.images))
+			for _, comp := range report.Components {
+				assert.Contains(t, comp.ContainerImage, "@sha256:digest")
+				assert.Equal(t, comp.ContainerImage != "registry/unsigned@sha256:digest", comp.Success)
+			}
+		})
+	}
+}
+
+func TestVerifyImageError(t *testing.T) {
+	cmd := setUpCobra(func(context.Context, app.SnapshotComponent, policy.Policy, bool) (*output.Output, error) {
+		return nil, errors.New("boom")
+	})
+	cmd.SetContext(utils.WithFS(context.Background(), afero.NewMemMapFs()))
+	cmd.SetArgs([]string{"verify", "image", "--public-key", utils.TestPublicKey, "--ignore-rekor", "--image", "registry/image"})
+	cmd.SetOut(&bytes.Buffer{})
+
+	assert.ErrorContains(t, cmd.Execute(), "error verifying image registry/image: boom")
+}
+
+func TestVerifyImageWorkers(t *testing.T) {
+	var mu sync.Mutex
+	running, maxRunning := 0, 0
+	cmd := setUpCobra(func(_ context.Context, comp app.SnapshotComponent, _ policy.Policy, _ bool) (*output.Output, error) {
+		mu.Lock()
+		running++
+		maxRunning = max(maxRunning, running)
+		mu.Unlock()
+
+		time.Sleep(10 * time.Millisecond)
+
+		mu.Lock()
+		running--
+		mu.Unlock()
+
+		return &output.Output{ImageURL: comp.ContainerImage}, nil
+	})
+	cmd.SetContext(utils.WithFS(context.Background(), afero.NewMemMapFs()))
+	args := []string{"verify", "image", "--public-key", utils.TestPublicKey, "--ignore-rekor", "--workers", "2"}
+	for i := 0; i < 6; i++ {
+		args = append(args, "--image", fmt.Sprintf("registry/image-%d", i))
+	}
+	cmd.SetArgs(args)
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+
+	require.NoError(t, cmd.Execute())
+	assert.LessOrEqual(t, maxRunning, 2)
+
+	var report applicationsnapshot.Report
+	require.NoError(t, json.Unmarshal(out.Bytes(), &report))
+	assert.Len(t, report.Components, 6)
+}
+
+func TestVerifyImageInvalidWorkers(t *testing.T) {
+	cmd := setUpCobra(nil)
+	cmd.SetContext(utils.WithFS(context.Background(), afero.NewMemMapFs()))
+	cmd.SetArgs([]string{"verify", "image", "--public-key", utils.TestPublicKey, "--ignore-rekor", "--image", "registry/image", "--workers", "0"})
+	cmd.SetOut(&bytes.Buffer{})
+
+	assert.ErrorContains(t, cmd.Execute(), "invalid number of workers 0, expecting at least 1")
+}
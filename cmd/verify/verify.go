@@ -0,0 +1,44 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package verify
+
+import (
+	hd "github.com/MakeNowJust/heredoc"
+	"github.com/spf13/cobra"
+
+	"github.com/enterprise-contract/ec-cli/internal/image"
+)
+
+var VerifyCmd *cobra.Command
+
+func init() {
+	VerifyCmd = NewVerifyCmd()
+	VerifyCmd.AddCommand(verifyImageCmd(image.VerifyImage))
+}
+
+func NewVerifyCmd() *cobra.Command {
+	verifyCmd := &cobra.Command{
+		Use:   "verify",
+		Short: "Verify signatures and attestations without evaluating any policy",
+	}
+	verifyCmd.PersistentFlags().Bool("show-successes", false, hd.Doc(`
+		include the checks that passed in the output, each with its code and metadata.`))
+	verifyCmd.PersistentFlags().Bool("json-compact", false, hd.Doc(`
+		write the JSON output on a single line. By default JSON written to a terminal is
+		indented, and JSON written elsewhere, e.g. to a file or a pipe, is on a single line.`))
+	return verifyCmd
+}
@@ -19,8 +19,15 @@ package applicationsnapshot
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
+	"strings"
 
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/hashicorp/go-multierror"
 	"github.com/in-toto/in-toto-golang/in_toto"
+
+	"github.com/enterprise-contract/ec-cli/internal/attestation"
+	"github.com/enterprise-contract/ec-cli/internal/format"
 )
 
 func (r *Report) renderAttestations() ([]byte, error) {
@@ -35,6 +42,95 @@ func (r *Report) renderAttestations() ([]byte, error) {
 	return bytes.Join(byts, []byte{'\n'}), nil
 }
 
+// sbomFormats are the output formats of the SBOMs, by the predicate type of the
+// attestations embedding them
+var sbomFormats = map[string]string{
+	CycloneDX: attestation.PredicateCycloneDXBOM,
+	SPDX:      attestation.PredicateSpdxDocument,
+}
+
+// renderSBOMs extracts the SBOM documents embedded as the predicate of the
+// verified attestations matching the given predicate type. Each SBOM is
+// rendered on its own line, in the order of the components.
+func (r *Report) renderSBOMs(predicateType string) ([]byte, error) {
+	byts := make([][]byte, 0, len(r.Components))
+
+	for _, c := range r.Components {
+		sboms, err := componentSBOMs(c, predicateType)
+		if err != nil {
+			return nil, err
+		}
+		byts = append(byts, sboms...)
+	}
+
+	return bytes.Join(byts, []byte{'\n'}), nil
+}
+
+// writeSBOMs writes the SBOM documents extracted as by renderSBOMs each to a
+// file within the directory of the target, named after the digest of the
+// image, e.g. sha256-<hex>.json. Additional SBOMs of the same image are
+// numbered, e.g. sha256-<hex>-1.json.
+func (r *Report) writeSBOMs(target *format.Target, predicateType string) (allErrors error) {
+	for _, c := range r.Components {
+		sboms, err := componentSBOMs(c, predicateType)
+		if err != nil {
+			allErrors = multierror.Append(allErrors, err)
+			continue
+		}
+		if len(sboms) == 0 {
+			continue
+		}
+
+		digest, err := name.NewDigest(c.ContainerImage)
+		if err != nil {
+			allErrors = multierror.Append(allErrors, fmt.Errorf("unable to name the SBOM of %s after the image digest: %w", c.ContainerImage, err))
+			continue
+		}
+		base := strings.Replace(digest.DigestStr(), ":", "-", 1)
+
+		for i, sbom := range sboms {
+			file := base + ".json"
+			if i > 0 {
+				file = fmt.Sprintf("%s-%d.json", base, i)
+			}
+
+			w, _ := target.Within(file)
+			if _, err := w.Write(append(sbom, '\n')); err != nil {
+				allErrors = multierror.Append(allErrors, err)
+			}
+		}
+	}
+
+	return
+}
+
+// componentSBOMs extracts the SBOM documents embedded in the attestations of
+// the component matching the given predicate type.
+func componentSBOMs(c Component, predicateType string) ([][]byte, error) {
+	var sboms [][]byte
+	for _, a := range c.Attestations {
+		if a.PredicateType() != predicateType {
+			continue
+		}
+
+		var statement struct {
+			Predicate json.RawMessage `json:"predicate"`
+		}
+		if err := json.Unmarshal(a.Statement(), &statement); err != nil {
+			return nil, fmt.Errorf("unable to extract the SBOM from the attestation of %s: %w", c.ContainerImage, err)
+		}
+
+		var sbom bytes.Buffer
+		if err := json.Compact(&sbom, statement.Predicate); err != nil {
+			return nil, fmt.Errorf("unable to extract the SBOM from the attestation of %s: %w", c.ContainerImage, err)
+		}
+
+		sboms = append(sboms, sbom.Bytes())
+	}
+
+	return sboms, nil
+}
+
 func (r *Report) attestations() ([]in_toto.Statement, error) {
 	var statements []in_toto.Statement
 	for _, c := range r.Components {
@@ -20,15 +20,20 @@ package applicationsnapshot
 
 import (
 	"encoding/json"
+	"io"
+	"strings"
 	"testing"
 
 	"github.com/gkampitakis/go-snaps/snaps"
 	"github.com/in-toto/in-toto-golang/in_toto"
 	app "github.com/konflux-ci/application-api/api/v1alpha1"
+	"github.com/spf13/afero"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/enterprise-contract/ec-cli/internal/attestation"
 	"github.com/enterprise-contract/ec-cli/internal/evaluator"
+	"github.com/enterprise-contract/ec-cli/internal/format"
 	"github.com/enterprise-contract/ec-cli/internal/signature"
 )
 
@@ -159,6 +164,105 @@ func TestAttestations(t *testing.T) {
 	assert.Equal(t, []in_toto.Statement{statement}, att)
 }
 
+func TestSBOMReport(t *testing.T) {
+	cyclonedx := sbomAttestation{
+		predicateType: attestation.PredicateCycloneDXBOM,
+		data:          `{"_type": "https://in-toto.io/Statement/v0.1", "predicateType": "https://cyclonedx.org/bom", "predicate": {"bomFormat": "CycloneDX", "specVersion": "1.5"}}`,
+	}
+	spdx := sbomAttestation{
+		predicateType: attestation.PredicateSpdxDocument,
+		data:          `{"_type": "https://in-toto.io/Statement/v0.1", "predicateType": "https://spdx.dev/Document", "predicate": {"spdxVersion": "SPDX-2.3"}}`,
+	}
+
+	r := Report{
+		Components: []Component{
+			{
+				SnapshotComponent: app.SnapshotComponent{ContainerImage: "registry.io/repository/image1:tag"},
+				Attestations:      []attestation.Attestation{att("provenance"), cyclonedx, spdx},
+			},
+			{
+				SnapshotComponent: app.SnapshotComponent{ContainerImage: "registry.io/repository/image2:tag"},
+			},
+			{
+				SnapshotComponent: app.SnapshotComponent{ContainerImage: "registry.io/repository/image3:tag"},
+				Attestations:      []attestation.Attestation{cyclonedx},
+			},
+		},
+	}
+
+	b, err := r.renderSBOMs(attestation.PredicateCycloneDXBOM)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"bomFormat":"CycloneDX","specVersion":"1.5"}`+"\n"+`{"bomFormat":"CycloneDX","specVersion":"1.5"}`, string(b))
+
+	b, err = r.toFormat(SPDX)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"spdxVersion":"SPDX-2.3"}`, string(b))
+
+	r.Components[0].Attestations = []attestation.Attestation{sbomAttestation{predicateType: attestation.PredicateSpdxDocument, data: "{"}}
+	_, err = r.toFormat(SPDX)
+	assert.ErrorContains(t, err, "unable to extract the SBOM from the attestation of registry.io/repository/image1:tag")
+}
+
+func TestSBOMReportFiles(t *testing.T) {
+	cyclonedx := sbomAttestation{
+		predicateType: attestation.PredicateCycloneDXBOM,
+		data:          `{"_type": "https://in-toto.io/Statement/v0.1", "predicateType": "https://cyclonedx.org/bom", "predicate": {"bomFormat": "CycloneDX", "specVersion": "1.5"}}`,
+	}
+	digest1 := "sha256:" + strings.Repeat("1", 64)
+	digest2 := "sha256:" + strings.Repeat("2", 64)
+
+	r := Report{
+		Components: []Component{
+			{
+				SnapshotComponent: app.SnapshotComponent{ContainerImage: "registry.io/repository/image1@" + digest1},
+				Attestations:      []attestation.Attestation{att("provenance"), cyclonedx, cyclonedx},
+			},
+			{
+				SnapshotComponent: app.SnapshotComponent{ContainerImage: "registry.io/repository/image2@" + digest2},
+				Attestations:      []attestation.Attestation{cyclonedx},
+			},
+			{
+				SnapshotComponent: app.SnapshotComponent{ContainerImage: "registry.io/repository/image3:tag"},
+			},
+		},
+	}
+
+	fs := afero.NewMemMapFs()
+	p := format.NewTargetParser(JSON, format.Options{}, io.Discard, fs)
+	require.NoError(t, r.WriteAll([]string{"cyclonedx=/sboms"}, p))
+
+	files, err := afero.Glob(fs, "/sboms/*")
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"/sboms/sha256-" + strings.Repeat("1", 64) + "-1.json",
+		"/sboms/sha256-" + strings.Repeat("1", 64) + ".json",
+		"/sboms/sha256-" + strings.Repeat("2", 64) + ".json",
+	}, files)
+
+	content, err := afero.ReadFile(fs, files[2])
+	require.NoError(t, err)
+	assert.Equal(t, `{"bomFormat":"CycloneDX","specVersion":"1.5"}`+"\n", string(content))
+
+	// An image without a digest cannot be named
+	r.Components[2].Attestations = []attestation.Attestation{cyclonedx}
+	err = r.WriteAll([]string{"cyclonedx=/other"}, p)
+	assert.ErrorContains(t, err, "unable to name the SBOM of registry.io/repository/image3:tag after the image digest")
+}
+
+type sbomAttestation struct {
+	mockAttestation
+	predicateType string
+	data          string
+}
+
+func (a sbomAttestation) PredicateType() string {
+	return a.predicateType
+}
+
+func (a sbomAttestation) Statement() []byte {
+	return []byte(a.data)
+}
+
 type mockAttestation struct {
 	data string
 }
@@ -0,0 +1,190 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package applicationsnapshot
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/enterprise-contract/ec-cli/internal/evaluator"
+)
+
+const browseHelp = `Commands:
+  <n>                 show the details of the nth result
+  status <status>     show only violations, warnings or successes, or all
+  severity <level>    show only results of the severity level, or all
+  search <text>       show only results with the text in the rule code, empty to clear
+  list                list the results matching the filters
+  help                show this help
+  quit                leave
+`
+
+// browseStatuses maps the statuses accepted by the status command, singular
+// or plural, to the status of the results.
+var browseStatuses = map[string]string{
+	"":           "",
+	"violation":  csvViolation,
+	"violations": csvViolation,
+	"warning":    csvWarning,
+	"warnings":   csvWarning,
+	"success":    csvSuccess,
+	"successes":  csvSuccess,
+}
+
+// browseEntry is a result of a component as listed when browsing.
+type browseEntry struct {
+	image    string
+	status   string
+	severity string
+	code     string
+	result   evaluator.Result
+}
+
+// browseFilter holds the filters applied to the results when browsing, empty
+// values match any result.
+type browseFilter struct {
+	status   string
+	severity string
+	search   string
+}
+
+func (f browseFilter) matches(e browseEntry) bool {
+	return (f.status == "" || f.status == e.status) &&
+		(f.severity == "" || f.severity == e.severity) &&
+		(f.search == "" || strings.Contains(strings.ToLower(e.code), strings.ToLower(f.search)))
+}
+
+// Browse lets the user triage the results of the report interactively, reading
+// commands from in, one per line, and writing to out, until the input ends or
+// the user quits. It is a prompt-driven browser rather than a full screen
+// terminal UI, so that it works with any terminal and can be scripted.
+// Successes are included only if showing successes.
+func (r *Report) Browse(in io.Reader, out io.Writer) error {
+	entries := r.browseEntries()
+
+	var filter browseFilter
+	var listed []browseEntry
+	list := func() {
+		listed = listed[:0]
+		for _, e := range entries {
+			if filter.matches(e) {
+				listed = append(listed, e)
+			}
+		}
+		for i, e := range listed {
+			fmt.Fprintf(out, "%3d  %-9s  %-8s  %s  %s\n", i+1, e.status, e.severity, e.code, e.image)
+		}
+		fmt.Fprintf(out, "%d of %d results\n", len(listed), len(entries))
+	}
+
+	fmt.Fprint(out, browseHelp)
+	list()
+
+	scanner := bufio.NewScanner(in)
+	for {
+		fmt.Fprint(out, "> ")
+		if !scanner.Scan() {
+			fmt.Fprintln(out)
+			return scanner.Err()
+		}
+
+		command, arg, _ := strings.Cut(strings.TrimSpace(scanner.Text()), " ")
+		arg = strings.TrimSpace(arg)
+		if arg == "all" {
+			arg = ""
+		}
+
+		switch command {
+		case "":
+			continue
+		case "quit", "q", "exit":
+			return nil
+		case "help", "?":
+			fmt.Fprint(out, browseHelp)
+		case "list", "l":
+			list()
+		case "status", "s":
+			status, ok := browseStatuses[strings.ToLower(arg)]
+			if !ok {
+				fmt.Fprintf(out, "Unknown status %q, expecting violations, warnings, successes or all\n", arg)
+				continue
+			}
+			filter.status = status
+			list()
+		case "severity", "sev":
+			filter.severity = strings.ToLower(arg)
+			list()
+		case "search", "/":
+			filter.search = arg
+			list()
+		default:
+			n, err := strconv.Atoi(command)
+			if err != nil {
+				fmt.Fprintf(out, "Unknown command %q, type help for the commands\n", command)
+				continue
+			}
+			if n < 1 || n > len(listed) {
+				fmt.Fprintf(out, "No result %d, expecting 1 to %d\n", n, len(listed))
+				continue
+			}
+			writeBrowseDetails(out, listed[n-1])
+		}
+	}
+}
+
+func (r *Report) browseEntries() []browseEntry {
+	var entries []browseEntry
+	for _, c := range r.Components {
+		add := func(status string, results []evaluator.Result) {
+			for i, row := range csvRows(c, status, results) {
+				entries = append(entries, browseEntry{image: row[0], code: row[1], severity: row[2], status: status, result: results[i]})
+			}
+		}
+		add(csvViolation, c.Violations)
+		add(csvWarning, c.Warnings)
+		if r.ShowSuccesses {
+			add(csvSuccess, c.Successes)
+		}
+	}
+
+	return entries
+}
+
+func writeBrowseDetails(out io.Writer, e browseEntry) {
+	fmt.Fprintf(out, "Image: %s\n", e.image)
+	fmt.Fprintf(out, "Status: %s\n", e.status)
+	fmt.Fprintf(out, "Severity: %s\n", e.severity)
+	if e.code != "" {
+		fmt.Fprintf(out, "Code: %s\n", e.code)
+	}
+	fmt.Fprintf(out, "Message: %s\n", e.result.Message)
+
+	keys := make([]string, 0, len(e.result.Metadata))
+	for k := range e.result.Metadata {
+		if k != "code" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(out, "  %s: %v\n", k, e.result.Metadata[k])
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package applicationsnapshot
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	app "github.com/konflux-ci/application-api/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/evaluator"
+)
+
+func TestBrowse(t *testing.T) {
+	report := Report{
+		Components: []Component{
+			{
+				SnapshotComponent: app.SnapshotComponent{ContainerImage: "registry.io/one"},
+				Violations: []evaluator.Result{
+					{Message: "tasks missing", Severity: "high", Metadata: map[string]any{"code": "tasks.required", "term": "buildah"}},
+				},
+				Warnings: []evaluator.Result{
+					{Message: "labels missing", Metadata: map[string]any{"code": "labels.required"}},
+				},
+				Successes: []evaluator.Result{
+					{Message: "Pass", Metadata: map[string]any{"code": "attestation.type"}},
+				},
+			},
+		},
+	}
+
+	browse := func(t *testing.T, r Report, commands ...string) string {
+		var out bytes.Buffer
+		require.NoError(t, r.Browse(strings.NewReader(strings.Join(commands, "\n")), &out))
+		return out.String()
+	}
+
+	t.Run("list", func(t *testing.T) {
+		out := browse(t, report)
+		assert.Contains(t, out, "  1  violation  high      tasks.required  registry.io/one\n")
+		assert.Contains(t, out, "  2  warning    warning   labels.required  registry.io/one\n")
+		assert.Contains(t, out, "2 of 2 results\n")
+		assert.NotContains(t, out, "attestation.type")
+	})
+
+	t.Run("successes", func(t *testing.T) {
+		r := report
+		r.ShowSuccesses = true
+		assert.Contains(t, browse(t, r, "status successes"), "  1  success    info      attestation.type  registry.io/one\n1 of 3 results\n")
+	})
+
+	t.Run("filters", func(t *testing.T) {
+		assert.Contains(t, browse(t, report, "status warnings"), "1 of 2 results\n")
+		assert.Contains(t, browse(t, report, "severity HIGH"), "  1  violation  high")
+		assert.Contains(t, browse(t, report, "severity high", "severity all"), "2 of 2 results\n> ")
+		assert.Contains(t, browse(t, report, "search LABELS"), "  1  warning    warning   labels.required")
+		assert.Contains(t, browse(t, report, "status spam"), `Unknown status "spam"`)
+	})
+
+	t.Run("details", func(t *testing.T) {
+		out := browse(t, report, "search tasks", "1")
+		assert.Contains(t, out, "Image: registry.io/one\nStatus: violation\nSeverity: high\nCode: tasks.required\nMessage: tasks missing\n  term: buildah\n")
+
+		assert.Contains(t, browse(t, report, "3"), "No result 3, expecting 1 to 2")
+		assert.Contains(t, browse(t, report, "spam"), `Unknown command "spam"`)
+	})
+
+	t.Run("quit", func(t *testing.T) {
+		assert.NotContains(t, browse(t, report, "quit", "spam"), "Unknown command")
+	})
+}
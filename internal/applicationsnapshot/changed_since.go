@@ -0,0 +1,66 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package applicationsnapshot
+
+import (
+	"fmt"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	app "github.com/konflux-ci/application-api/api/v1alpha1"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+)
+
+// SkippedUnchanged is the reason for not validating the image of a component
+// that did not change since the previous snapshot
+const SkippedUnchanged = "unchanged"
+
+// ReadPreviousSnapshot reads the snapshot, e.g. of the previous release, to
+// determine the changed components against.
+func ReadPreviousSnapshot(fs afero.Fs, file string) (app.SnapshotSpec, error) {
+	content, err := afero.ReadFile(fs, file)
+	if err != nil {
+		return app.SnapshotSpec{}, fmt.Errorf("unable to read the previous snapshot: %w", err)
+	}
+
+	return readSnapshotSource(content)
+}
+
+// Changed splits the components into the ones with an image that changed since
+// the previous snapshot and the unchanged ones. A component is unchanged if the
+// previous snapshot has a component of the same name with the same image
+// digest. Images not pinned by digest always count as changed, as the image
+// the tag points to might have changed.
+func Changed(components []app.SnapshotComponent, previous app.SnapshotSpec) (changed, unchanged []app.SnapshotComponent) {
+	type key struct{ name, image string }
+
+	previousImages := map[key]bool{}
+	for _, c := range previous.Components {
+		previousImages[key{c.Name, imageKey(c.ContainerImage)}] = true
+	}
+
+	for _, c := range components {
+		if _, err := name.NewDigest(c.ContainerImage); err == nil && previousImages[key{c.Name, imageKey(c.ContainerImage)}] {
+			log.Debugf("Image %s of component %s is unchanged", c.ContainerImage, c.Name)
+			unchanged = append(unchanged, c)
+			continue
+		}
+		changed = append(changed, c)
+	}
+
+	return changed, unchanged
+}
@@ -0,0 +1,64 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package applicationsnapshot
+
+import (
+	"testing"
+
+	app "github.com/konflux-ci/application-api/api/v1alpha1"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChanged(t *testing.T) {
+	digest := "@sha256:4e388ab32b10dc8dbc7e28144f552830adc74787c1e2c0824032078a79f227fb"
+	other := "@sha256:0000000000000000000000000000000000000000000000000000000000000000"
+
+	previous := app.SnapshotSpec{Components: []app.SnapshotComponent{
+		{Name: "unchanged", ContainerImage: "registry.io/repository/unchanged:v1" + digest},
+		{Name: "changed", ContainerImage: "registry.io/repository/changed" + other},
+		{Name: "tag", ContainerImage: "registry.io/repository/tag:latest"},
+		{Name: "renamed", ContainerImage: "registry.io/repository/renamed" + digest},
+	}}
+
+	components := []app.SnapshotComponent{
+		{Name: "unchanged", ContainerImage: "registry.io/repository/unchanged:v2" + digest},
+		{Name: "changed", ContainerImage: "registry.io/repository/changed" + digest},
+		{Name: "tag", ContainerImage: "registry.io/repository/tag:latest"},
+		{Name: "new-name", ContainerImage: "registry.io/repository/renamed" + digest},
+		{Name: "new", ContainerImage: "registry.io/repository/new" + digest},
+	}
+
+	changed, unchanged := Changed(components, previous)
+	assert.Equal(t, components[1:], changed)
+	assert.Equal(t, components[:1], unchanged)
+}
+
+func TestReadPreviousSnapshot(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/previous.yaml", []byte("components:\n- name: a\n  containerImage: registry.io/a:latest\n"), 0644))
+
+	previous, err := ReadPreviousSnapshot(fs, "/previous.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, []app.SnapshotComponent{{Name: "a", ContainerImage: "registry.io/a:latest"}}, previous.Components)
+
+	_, err = ReadPreviousSnapshot(fs, "/missing.yaml")
+	assert.ErrorContains(t, err, "unable to read the previous snapshot")
+}
@@ -0,0 +1,115 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package applicationsnapshot
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"time"
+
+	"github.com/spf13/afero"
+
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+// collectorTimeout limits the time spent connecting and delivering the
+// results to a log collector
+const collectorTimeout = 10 * time.Second
+
+// Collector is a remote log collector the result summary is forwarded to.
+type Collector struct {
+	// Address is the host:port of the collector
+	Address string
+	// CAFile is the path to the PEM encoded certificate authorities trusted to
+	// sign the certificate of the collector. When set, the connection uses TLS.
+	CAFile string
+}
+
+// ParseCollector parses the log collector URL, only tcp://host:port URLs are
+// supported.
+func ParseCollector(collectorURL, caFile string) (Collector, error) {
+	u, err := url.Parse(collectorURL)
+	if err != nil {
+		return Collector{}, fmt.Errorf("invalid log collector URL %q: %w", collectorURL, err)
+	}
+
+	if u.Scheme != "tcp" {
+		return Collector{}, fmt.Errorf("invalid log collector URL %q, expecting tcp://host:port", collectorURL)
+	}
+
+	if _, _, err := net.SplitHostPort(u.Host); err != nil {
+		return Collector{}, fmt.Errorf("invalid log collector URL %q, expecting tcp://host:port: %w", collectorURL, err)
+	}
+
+	return Collector{Address: u.Host, CAFile: caFile}, nil
+}
+
+// Send forwards the JSON summary of the report, terminated by a newline, to the
+// collector.
+func (c Collector) Send(ctx context.Context, r *Report) error {
+	summary, err := r.toFormat(Summary)
+	if err != nil {
+		return err
+	}
+
+	dialer := &net.Dialer{Timeout: collectorTimeout}
+
+	var conn net.Conn
+	if c.CAFile == "" {
+		conn, err = dialer.DialContext(ctx, "tcp", c.Address)
+	} else {
+		var config *tls.Config
+		if config, err = c.tlsConfig(ctx); err != nil {
+			return err
+		}
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: config}).DialContext(ctx, "tcp", c.Address)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to connect to the log collector %s: %w", c.Address, err)
+	}
+	defer conn.Close()
+
+	if err := conn.SetWriteDeadline(time.Now().Add(collectorTimeout)); err != nil {
+		return err
+	}
+
+	if _, err := conn.Write(append(bytes.TrimSpace(summary), '\n')); err != nil {
+		return fmt.Errorf("unable to send the results to the log collector %s: %w", c.Address, err)
+	}
+
+	return nil
+}
+
+func (c Collector) tlsConfig(ctx context.Context) (*tls.Config, error) {
+	ca, err := afero.ReadFile(utils.FS(ctx), c.CAFile)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the log collector CA %s: %w", c.CAFile, err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, errors.New("no certificates found in the log collector CA " + c.CAFile)
+	}
+
+	return &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}, nil
+}
@@ -0,0 +1,95 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package applicationsnapshot
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+func TestParseCollector(t *testing.T) {
+	c, err := ParseCollector("tcp://collector.example.com:6514", "/ca.pem")
+	require.NoError(t, err)
+	assert.Equal(t, Collector{Address: "collector.example.com:6514", CAFile: "/ca.pem"}, c)
+
+	_, err = ParseCollector("udp://collector.example.com:514", "")
+	assert.EqualError(t, err, `invalid log collector URL "udp://collector.example.com:514", expecting tcp://host:port`)
+
+	_, err = ParseCollector("tcp://collector.example.com", "")
+	assert.EqualError(t, err, `invalid log collector URL "tcp://collector.example.com", expecting tcp://host:port: address collector.example.com: missing port in address`)
+}
+
+func TestCollectorSend(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { l.Close() })
+
+	received := make(chan string, 1)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		line, _ := bufio.NewReader(conn).ReadString('\n')
+		received <- line
+	}()
+
+	r := &Report{Success: true, Snapshot: "snappy", Components: []Component{{Success: true}}}
+	require.NoError(t, Collector{Address: l.Addr().String()}.Send(context.Background(), r))
+
+	var summary map[string]any
+	require.NoError(t, json.Unmarshal([]byte(<-received), &summary))
+	assert.Equal(t, "snappy", summary["snapshot"])
+	assert.Len(t, summary["components"], 1)
+}
+
+func TestCollectorSendTLS(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	server.StartTLS()
+	t.Cleanup(server.Close)
+
+	fs := afero.NewMemMapFs()
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	require.NoError(t, afero.WriteFile(fs, "/ca.pem", ca, 0400))
+	require.NoError(t, afero.WriteFile(fs, "/other.pem", []byte("not a certificate"), 0400))
+	ctx := utils.WithFS(context.Background(), fs)
+
+	address := server.Listener.Addr().String()
+	r := &Report{Success: true}
+
+	assert.NoError(t, Collector{Address: address, CAFile: "/ca.pem"}.Send(ctx, r))
+	assert.EqualError(t, Collector{Address: address, CAFile: "/other.pem"}.Send(ctx, r), "no certificates found in the log collector CA /other.pem")
+	assert.ErrorContains(t, Collector{Address: address, CAFile: "/missing.pem"}.Send(ctx, r), "unable to read the log collector CA /missing.pem")
+}
@@ -0,0 +1,99 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package applicationsnapshot
+
+import (
+	"fmt"
+
+	ecc "github.com/enterprise-contract/enterprise-contract-controller/api/v1alpha1"
+	log "github.com/sirupsen/logrus"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// PolicyMerge adds the sources of the component policy to the sources of
+	// the policy.
+	PolicyMerge = "merge"
+	// PolicyReplace uses only the sources of the component policy, discarding
+	// the sources of the policy, and is only used when explicitly allowed.
+	PolicyReplace = "replace"
+)
+
+// ComponentPolicy holds the policy and data sources overriding the sources of
+// the policy for a single component of the snapshot, e.g.
+//
+//	components:
+//	- name: my-component
+//	  containerImage: registry.io/repository/image:tag
+//	  policy:
+//	    mode: merge
+//	    sources:
+//	    - policy:
+//	      - github.com/org/extra-rules//policy
+//	      data:
+//	      - github.com/org/extra-data
+type ComponentPolicy struct {
+	// Mode is either PolicyMerge, the default, or PolicyReplace.
+	Mode    string       `json:"mode,omitempty"`
+	Sources []ecc.Source `json:"sources"`
+}
+
+// ComponentPolicies holds the component policies keyed by the container image
+// of the component.
+type ComponentPolicies map[string]ComponentPolicy
+
+// Apply returns the sources used to validate the component given the sources
+// of the policy.
+func (p ComponentPolicy) Apply(sources []ecc.Source) []ecc.Source {
+	if p.Mode == PolicyReplace {
+		return p.Sources
+	}
+
+	return append(append([]ecc.Source{}, sources...), p.Sources...)
+}
+
+// readComponentPolicies reads the component policies from the snapshot
+// specification, components without a policy are omitted.
+func readComponentPolicies(input []byte) (ComponentPolicies, error) {
+	var spec struct {
+		Components []struct {
+			ContainerImage string           `json:"containerImage"`
+			Policy         *ComponentPolicy `json:"policy"`
+		} `json:"components"`
+	}
+	if err := yaml.Unmarshal(input, &spec); err != nil {
+		return nil, fmt.Errorf("unable to parse component policies from %s: %w", input, err)
+	}
+
+	policies := ComponentPolicies{}
+	for _, c := range spec.Components {
+		if c.Policy == nil {
+			continue
+		}
+
+		switch c.Policy.Mode {
+		case "", PolicyMerge, PolicyReplace:
+		default:
+			return nil, fmt.Errorf("unsupported policy mode %q of component with image %s, expecting %q or %q", c.Policy.Mode, c.ContainerImage, PolicyMerge, PolicyReplace)
+		}
+
+		log.Debugf("Read %s policy of component with image %s", c.Policy.Mode, c.ContainerImage)
+		policies[c.ContainerImage] = *c.Policy
+	}
+
+	return policies, nil
+}
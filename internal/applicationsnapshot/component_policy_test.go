@@ -0,0 +1,94 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package applicationsnapshot
+
+import (
+	"context"
+	"testing"
+
+	ecc "github.com/enterprise-contract/enterprise-contract-controller/api/v1alpha1"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	gcrfake "github.com/google/go-containerregistry/pkg/v1/fake"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/utils/oci"
+	"github.com/enterprise-contract/ec-cli/internal/utils/oci/fake"
+)
+
+func TestComponentPolicyApply(t *testing.T) {
+	global := []ecc.Source{{Name: "global"}}
+	extra := []ecc.Source{{Name: "extra"}}
+
+	assert.Equal(t, []ecc.Source{{Name: "global"}, {Name: "extra"}}, ComponentPolicy{Sources: extra}.Apply(global))
+	assert.Equal(t, []ecc.Source{{Name: "global"}, {Name: "extra"}}, ComponentPolicy{Mode: PolicyMerge, Sources: extra}.Apply(global))
+	assert.Equal(t, extra, ComponentPolicy{Mode: PolicyReplace, Sources: extra}.Apply(global))
+	assert.Equal(t, []ecc.Source{{Name: "global"}}, global, "the sources of the policy are not modified")
+}
+
+func TestReadComponentPolicies(t *testing.T) {
+	policies, err := readComponentPolicies([]byte(`
+components:
+- containerImage: registry.io/shared
+- containerImage: registry.io/extra
+  policy:
+    sources:
+    - policy: [github.com/org/extra]
+- containerImage: registry.io/other
+  policy:
+    mode: replace
+    sources:
+    - data: [github.com/org/data]
+`))
+	require.NoError(t, err)
+	assert.Equal(t, ComponentPolicies{
+		"registry.io/extra": {Sources: []ecc.Source{{Policy: []string{"github.com/org/extra"}}}},
+		"registry.io/other": {Mode: PolicyReplace, Sources: []ecc.Source{{Data: []string{"github.com/org/data"}}}},
+	}, policies)
+
+	_, err = readComponentPolicies([]byte(`{"components":[{"containerImage":"registry.io/image","policy":{"mode":"extend"}}]}`))
+	assert.EqualError(t, err, `unsupported policy mode "extend" of component with image registry.io/image, expecting "merge" or "replace"`)
+}
+
+func TestDetermineInputComponentPolicies(t *testing.T) {
+	client := fake.FakeClient{}
+	ref := name.MustParseReference("registry.io/repository/index:tag")
+	client.On("Head", ref).Return(&v1.Descriptor{MediaType: types.OCIImageIndex}, nil)
+	client.On("Head", name.MustParseReference("registry.io/repository/image:tag")).Return(&v1.Descriptor{MediaType: types.OCIManifestSchema1}, nil)
+
+	index := gcrfake.FakeImageIndex{}
+	index.IndexManifestReturns(&v1.IndexManifest{
+		Manifests: []v1.Descriptor{
+			{MediaType: types.OCIManifestSchema1, Platform: &v1.Platform{Architecture: "amd64"}, Digest: v1.Hash{Algorithm: "sha256", Hex: "digest1"}},
+		},
+	}, nil)
+	client.On("Index", ref).Return(&index, nil)
+	ctx := oci.WithClient(context.Background(), &client)
+
+	policy := ComponentPolicy{Mode: PolicyReplace, Sources: []ecc.Source{{Name: "own"}}}
+
+	_, policies, err := DetermineInput(ctx, Input{Images: []string{`{"components":[
+		{"name":"index","containerImage":"registry.io/repository/index:tag","policy":{"mode":"replace","sources":[{"name":"own"}]}},
+		{"name":"image","containerImage":"registry.io/repository/image:tag"}
+	]}`}})
+	require.NoError(t, err)
+	assert.Equal(t, ComponentPolicies{"registry.io/repository/index@sha256:digest1": policy}, policies)
+}
@@ -0,0 +1,65 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package applicationsnapshot
+
+import (
+	"fmt"
+
+	"github.com/enterprise-contract/ec-cli/internal/output"
+)
+
+// SignatureCoverage is an inventory of which images of the snapshot have a
+// verified signature and a verified attestation.
+type SignatureCoverage struct {
+	Total      int      `json:"total"`
+	Signed     int      `json:"signed"`
+	Attested   int      `json:"attested"`
+	Summary    string   `json:"summary"`
+	Unsigned   []string `json:"unsigned,omitempty"`
+	Unattested []string `json:"unattested,omitempty"`
+}
+
+// NewSignatureCoverage determines the signature coverage of the components
+// from the outcome of their verification stages. Components without any
+// verification information are counted as neither signed nor attested.
+func NewSignatureCoverage(components []Component) *SignatureCoverage {
+	coverage := SignatureCoverage{Total: len(components)}
+
+	for _, c := range components {
+		v := c.Verification
+		if v != nil && v.ImageSignature.Status == output.StageOK {
+			coverage.Signed++
+		} else {
+			coverage.Unsigned = append(coverage.Unsigned, c.ContainerImage)
+		}
+
+		if v != nil && v.AttestationSignature.Status == output.StageOK {
+			coverage.Attested++
+		} else {
+			coverage.Unattested = append(coverage.Unattested, c.ContainerImage)
+		}
+	}
+
+	coverage.Summary = fmt.Sprintf("%d/%d signed, %d/%d attested", coverage.Signed, coverage.Total, coverage.Attested, coverage.Total)
+
+	return &coverage
+}
+
+// Complete returns true if all images are signed and attested.
+func (c SignatureCoverage) Complete() bool {
+	return c.Signed == c.Total && c.Attested == c.Total
+}
@@ -0,0 +1,60 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package applicationsnapshot
+
+import (
+	"testing"
+
+	app "github.com/konflux-ci/application-api/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/enterprise-contract/ec-cli/internal/output"
+)
+
+func TestNewSignatureCoverage(t *testing.T) {
+	ok := output.Stage{Status: output.StageOK}
+	failed := output.Stage{Status: output.StageFailed}
+
+	components := []Component{
+		{
+			SnapshotComponent: app.SnapshotComponent{ContainerImage: "registry.io/signed"},
+			Verification:      &output.Verification{ImageSignature: ok, AttestationSignature: ok},
+		},
+		{
+			SnapshotComponent: app.SnapshotComponent{ContainerImage: "registry.io/unattested"},
+			Verification:      &output.Verification{ImageSignature: ok, AttestationSignature: failed},
+		},
+		{
+			SnapshotComponent: app.SnapshotComponent{ContainerImage: "registry.io/unknown"},
+		},
+	}
+
+	coverage := NewSignatureCoverage(components)
+	assert.Equal(t, &SignatureCoverage{
+		Total:      3,
+		Signed:     2,
+		Attested:   1,
+		Summary:    "2/3 signed, 1/3 attested",
+		Unsigned:   []string{"registry.io/unknown"},
+		Unattested: []string{"registry.io/unattested", "registry.io/unknown"},
+	}, coverage)
+	assert.False(t, coverage.Complete())
+
+	assert.True(t, NewSignatureCoverage(components[:1]).Complete())
+}
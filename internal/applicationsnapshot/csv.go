@@ -0,0 +1,114 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package applicationsnapshot
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+
+	"github.com/enterprise-contract/ec-cli/internal/evaluator"
+)
+
+// csvHeader lists the columns of the CSV report, the order must not change as
+// spreadsheets may refer to the columns by position.
+var csvHeader = []string{"image", "code", "severity", "namespace", "message", "status", "image_status"}
+
+// Status of the results in the CSV report
+const (
+	csvViolation = "violation"
+	csvWarning   = "warning"
+	csvSuccess   = "success"
+)
+
+// renderCSV renders a row for each violation and warning of each component,
+// and for each success if showing successes.
+func (r *Report) renderCSV() ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	if err := w.Write(csvHeader); err != nil {
+		return nil, err
+	}
+
+	for _, c := range r.Components {
+		rows := csvRows(c, csvViolation, c.Violations)
+		rows = append(rows, csvRows(c, csvWarning, c.Warnings)...)
+		if r.ShowSuccesses {
+			rows = append(rows, csvRows(c, csvSuccess, c.Successes)...)
+		}
+
+		if err := w.WriteAll(rows); err != nil {
+			return nil, err
+		}
+	}
+
+	w.Flush()
+
+	return buf.Bytes(), w.Error()
+}
+
+func csvRows(c Component, status string, results []evaluator.Result) [][]string {
+	rows := make([][]string, 0, len(results))
+	for _, result := range results {
+		code := evaluator.ExtractStringFromMetadata(result, "code")
+
+		severity := result.Severity
+		if severity == "" {
+			severity = evaluator.ExtractStringFromMetadata(result, "severity")
+		}
+		if severity == "" {
+			severity = defaultSeverity(status)
+		}
+
+		// The namespace of a rule is its code without the rule name
+		namespace := ""
+		if i := strings.LastIndex(code, "."); i > 0 {
+			namespace = code[:i]
+		}
+
+		row := []string{c.ContainerImage, code, severity, namespace, result.Message, status, string(c.Status)}
+		for i := range row {
+			row[i] = csvCell(row[i])
+		}
+		rows = append(rows, row)
+	}
+
+	return rows
+}
+
+// csvCell guards against formula injection, spreadsheets evaluate cells
+// starting with =, +, - or @ as formulas. Such cells, e.g. from the message of
+// a policy rule, are prefixed with ' to be read as text.
+func csvCell(value string) string {
+	if value != "" && strings.ContainsRune("=+-@", rune(value[0])) {
+		return "'" + value
+	}
+
+	return value
+}
+
+func defaultSeverity(status string) string {
+	switch status {
+	case csvViolation:
+		return "error"
+	case csvWarning:
+		return "warning"
+	}
+
+	return "info"
+}
@@ -0,0 +1,110 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package applicationsnapshot
+
+import (
+	"encoding/csv"
+	"strings"
+	"testing"
+
+	app "github.com/konflux-ci/application-api/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/evaluator"
+)
+
+func TestRenderCSV(t *testing.T) {
+	report := Report{
+		Components: []Component{
+			{
+				SnapshotComponent: app.SnapshotComponent{ContainerImage: "registry.io/one"},
+				Status:            StatusFail,
+				Violations: []evaluator.Result{
+					{Message: "a message, with a comma", Metadata: map[string]any{"code": "tasks.required"}},
+				},
+				Warnings: []evaluator.Result{
+					{Message: "a \"quoted\"\nmultiline message", Metadata: map[string]any{"code": "cve.deprecated", "severity": "low"}},
+				},
+				Successes: []evaluator.Result{
+					{Message: "Pass", Metadata: map[string]any{"code": "builtin.image.signature_check"}},
+				},
+			},
+			{
+				SnapshotComponent: app.SnapshotComponent{ContainerImage: "registry.io/two"},
+				Status:            StatusError,
+				Violations: []evaluator.Result{
+					{Message: "no code"},
+				},
+			},
+		},
+	}
+
+	data, err := report.renderCSV()
+	require.NoError(t, err)
+
+	assert.Equal(t, `image,code,severity,namespace,message,status,image_status
+registry.io/one,tasks.required,error,tasks,"a message, with a comma",violation,fail
+registry.io/one,cve.deprecated,low,cve,"a ""quoted""
+multiline message",warning,fail
+registry.io/two,,error,,no code,violation,error
+`, string(data))
+
+	report.ShowSuccesses = true
+	data, err = report.renderCSV()
+	require.NoError(t, err)
+
+	rows, err := csv.NewReader(strings.NewReader(string(data))).ReadAll()
+	require.NoError(t, err)
+	assert.Len(t, rows, 5)
+	assert.Equal(t, []string{"registry.io/one", "builtin.image.signature_check", "info", "builtin.image", "Pass", "success", "fail"}, rows[3])
+}
+
+func TestRenderCSVEmpty(t *testing.T) {
+	report := Report{}
+
+	data, err := report.renderCSV()
+	require.NoError(t, err)
+	assert.Equal(t, "image,code,severity,namespace,message,status,image_status\n", string(data))
+}
+
+func TestRenderCSVFormulaInjection(t *testing.T) {
+	report := Report{
+		Components: []Component{
+			{
+				SnapshotComponent: app.SnapshotComponent{ContainerImage: "registry.io/one"},
+				Violations: []evaluator.Result{
+					{Message: `=HYPERLINK("https://example.com")`, Metadata: map[string]any{"code": "@rule"}},
+					{Message: "+1", Metadata: map[string]any{"code": "pkg.rule", "severity": "-high"}},
+					{Message: "a message = with an equals sign", Metadata: map[string]any{"code": "pkg.rule"}},
+				},
+			},
+		},
+	}
+
+	data, err := report.renderCSV()
+	require.NoError(t, err)
+
+	rows, err := csv.NewReader(strings.NewReader(string(data))).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, rows, 4)
+	assert.Equal(t, []string{"registry.io/one", "'@rule", "error", "", `'=HYPERLINK("https://example.com")`, "violation", ""}, rows[1])
+	assert.Equal(t, []string{"registry.io/one", "pkg.rule", "'-high", "pkg", "'+1", "violation", ""}, rows[2])
+	assert.Equal(t, "a message = with an equals sign", rows[3][4])
+}
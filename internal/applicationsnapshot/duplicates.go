@@ -0,0 +1,110 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package applicationsnapshot
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	app "github.com/konflux-ci/application-api/api/v1alpha1"
+)
+
+// Duplicate lists the different images of the components sharing the same
+// name, or the same repository, usually a mistake when assembling the
+// snapshot.
+type Duplicate struct {
+	// Name of the components, empty if the components share the repository
+	Name string
+	// Repository of the images, empty if the components share the name
+	Repository string
+	Images     []string
+}
+
+func (d Duplicate) String() string {
+	if d.Name != "" {
+		return fmt.Sprintf("component %s has different images: %s", d.Name, strings.Join(d.Images, ", "))
+	}
+
+	return fmt.Sprintf("repository %s has images with different digests: %s", d.Repository, strings.Join(d.Images, ", "))
+}
+
+// DuplicatesError lists the duplicate components of the snapshot.
+type DuplicatesError struct {
+	Duplicates []Duplicate
+}
+
+func (e DuplicatesError) Error() string {
+	msg := fmt.Sprintf("%d duplicate components found in the snapshot:", len(e.Duplicates))
+	for _, d := range e.Duplicates {
+		msg += fmt.Sprintf("\n  %s", d)
+	}
+
+	return msg
+}
+
+// FindDuplicates finds the components sharing the name but differing in the
+// image, and the components with images pinned to different digests of the
+// same repository. Components sharing the same image are not duplicates.
+func FindDuplicates(components []app.SnapshotComponent) []Duplicate {
+	type group struct {
+		duplicate Duplicate
+		seen      map[string]bool
+	}
+
+	var groups []*group
+	byName := map[string]*group{}
+	byRepository := map[string]*group{}
+	add := func(index map[string]*group, key string, d Duplicate, image string) {
+		g, ok := index[key]
+		if !ok {
+			g = &group{duplicate: d, seen: map[string]bool{}}
+			index[key] = g
+			groups = append(groups, g)
+		}
+		if k := imageKey(image); !g.seen[k] {
+			g.seen[k] = true
+			g.duplicate.Images = append(g.duplicate.Images, image)
+		}
+	}
+
+	for _, c := range components {
+		// The components from a digest file are all unnamed
+		if c.Name != "" && c.Name != unnamed {
+			add(byName, c.Name, Duplicate{Name: c.Name}, c.ContainerImage)
+		}
+		if ref, err := name.NewDigest(c.ContainerImage); err == nil {
+			repository := ref.Context().Name()
+			add(byRepository, repository, Duplicate{Repository: repository}, c.ContainerImage)
+		}
+	}
+
+	// The same images might be duplicates both by name and by repository, they
+	// are reported once
+	reported := map[string]bool{}
+	var duplicates []Duplicate
+	for _, g := range groups {
+		images := strings.Join(g.duplicate.Images, " ")
+		if len(g.duplicate.Images) < 2 || reported[images] {
+			continue
+		}
+		reported[images] = true
+		duplicates = append(duplicates, g.duplicate)
+	}
+
+	return duplicates
+}
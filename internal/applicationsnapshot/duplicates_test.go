@@ -0,0 +1,95 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package applicationsnapshot
+
+import (
+	"strings"
+	"testing"
+
+	app "github.com/konflux-ci/application-api/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindDuplicates(t *testing.T) {
+	a := "@sha256:" + strings.Repeat("a", 64)
+	b := "@sha256:" + strings.Repeat("b", 64)
+
+	cases := []struct {
+		name       string
+		components []app.SnapshotComponent
+		expected   []Duplicate
+	}{
+		{
+			name: "no duplicates",
+			components: []app.SnapshotComponent{
+				{Name: "a", ContainerImage: "registry.io/repo/a" + a},
+				{Name: "b", ContainerImage: "registry.io/repo/b" + a},
+			},
+		},
+		{
+			name: "same image",
+			components: []app.SnapshotComponent{
+				{Name: "a", ContainerImage: "registry.io/repo/a" + a},
+				{Name: "a", ContainerImage: "registry.io/repo/a:v1" + a},
+			},
+		},
+		{
+			name: "same name and repository",
+			components: []app.SnapshotComponent{
+				{Name: "a", ContainerImage: "registry.io/repo/a" + a},
+				{Name: "a", ContainerImage: "registry.io/repo/a" + b},
+			},
+			expected: []Duplicate{{Name: "a", Images: []string{"registry.io/repo/a" + a, "registry.io/repo/a" + b}}},
+		},
+		{
+			name: "same name",
+			components: []app.SnapshotComponent{
+				{Name: "a", ContainerImage: "registry.io/repo/a:v1"},
+				{Name: "a", ContainerImage: "registry.io/repo/a:v2"},
+			},
+			expected: []Duplicate{{Name: "a", Images: []string{"registry.io/repo/a:v1", "registry.io/repo/a:v2"}}},
+		},
+		{
+			name: "same repository",
+			components: []app.SnapshotComponent{
+				{Name: "a", ContainerImage: "registry.io/repo/a" + a},
+				{Name: "b", ContainerImage: "registry.io/repo/a" + b},
+			},
+			expected: []Duplicate{{Repository: "registry.io/repo/a", Images: []string{"registry.io/repo/a" + a, "registry.io/repo/a" + b}}},
+		},
+		{
+			name: "unnamed",
+			components: []app.SnapshotComponent{
+				{Name: unnamed, ContainerImage: "registry.io/repo/a" + a},
+				{Name: unnamed, ContainerImage: "registry.io/repo/b" + b},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.expected, FindDuplicates(c.components))
+		})
+	}
+}
+
+func TestDuplicateString(t *testing.T) {
+	assert.Equal(t, "component a has different images: i1, i2", Duplicate{Name: "a", Images: []string{"i1", "i2"}}.String())
+	assert.Equal(t, "repository r has images with different digests: i1, i2", Duplicate{Repository: "r", Images: []string{"i1", "i2"}}.String())
+}
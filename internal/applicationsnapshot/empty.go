@@ -0,0 +1,48 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package applicationsnapshot
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FilterStep is a step narrowing down the images to validate, with the
+// number of images remaining after it.
+type FilterStep struct {
+	Name   string
+	Images int
+}
+
+func (f FilterStep) String() string {
+	return fmt.Sprintf("%s: %d image(s)", f.Name, f.Images)
+}
+
+// EmptySnapshotError is returned when no image remains to be validated,
+// listing the filter steps that produced the empty set of images.
+type EmptySnapshotError struct {
+	Steps []FilterStep
+}
+
+func (e EmptySnapshotError) Error() string {
+	steps := make([]string, 0, len(e.Steps))
+	for _, s := range e.Steps {
+		steps = append(steps, s.String())
+	}
+
+	return fmt.Sprintf("empty snapshot, no images to validate: %s", strings.Join(steps, " -> "))
+}
@@ -0,0 +1,105 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package applicationsnapshot
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/enterprise-contract/ec-cli/internal/evaluator"
+)
+
+// Severity gates deciding which results fail the validation
+const (
+	// FailOnViolation fails the validation on any violation
+	FailOnViolation = "violation"
+	// FailOnWarning fails the validation on any violation or warning
+	FailOnWarning = "warning"
+	// FailOnNever never fails the validation, the results are only reported
+	FailOnNever = "never"
+)
+
+// FailOnValues lists the supported severity gates
+var FailOnValues = []string{FailOnViolation, FailOnWarning, FailOnNever}
+
+// ValidateFailOn returns an error if the given severity gate is not supported.
+func ValidateFailOn(failOn string) error {
+	if !slices.Contains(FailOnValues, failOn) {
+		return fmt.Errorf("invalid --fail-on value %q, expecting one of: %s", failOn, strings.Join(FailOnValues, ", "))
+	}
+
+	return nil
+}
+
+// Failed returns true if the results of the report fail the validation under
+// the given severity gate.
+func (r *Report) Failed(failOn string) bool {
+	switch failOn {
+	case FailOnNever:
+		return false
+	case FailOnWarning:
+		if !r.Success {
+			return true
+		}
+		for _, c := range r.Components {
+			if len(c.Warnings) > 0 {
+				return true
+			}
+		}
+		return false
+	default:
+		return !r.Success
+	}
+}
+
+// Default severities of the results of rules not giving a severity. The
+// violations reported by the built-in checks, e.g. of the image signature, are
+// critical.
+const (
+	defaultViolationSeverity        = evaluator.SeverityHigh
+	defaultBuiltinViolationSeverity = evaluator.SeverityCritical
+	defaultWarningSeverity          = evaluator.SeverityLow
+)
+
+// FailedSeverity returns true if any violation or warning of the report has a
+// severity at or above the threshold, or if any of the images could not be
+// validated, see StatusError.
+func (r *Report) FailedSeverity(threshold string) bool {
+	for _, c := range r.Components {
+		if c.Status == StatusError || c.status() == StatusError {
+			return true
+		}
+		for _, v := range c.Violations {
+			severity := defaultViolationSeverity
+			if builtinCode(ruleCode(v)) {
+				severity = defaultBuiltinViolationSeverity
+			}
+			if v.SeverityAtLeast(threshold, severity) {
+				return true
+			}
+		}
+		for _, w := range c.Warnings {
+			if w.SeverityAtLeast(threshold, defaultWarningSeverity) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
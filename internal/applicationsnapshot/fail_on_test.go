@@ -0,0 +1,126 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package applicationsnapshot
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/enterprise-contract/ec-cli/internal/evaluator"
+	"github.com/enterprise-contract/ec-cli/internal/output"
+)
+
+func TestValidateFailOn(t *testing.T) {
+	for _, v := range FailOnValues {
+		assert.NoError(t, ValidateFailOn(v))
+	}
+	assert.EqualError(t, ValidateFailOn("error"), `invalid --fail-on value "error", expecting one of: violation, warning, never`)
+}
+
+func TestReportFailed(t *testing.T) {
+	passing := Report{Success: true, Components: []Component{{Success: true}}}
+	warning := Report{Success: true, Components: []Component{{Success: true}, {Success: true, Warnings: []evaluator.Result{{Message: "warning"}}}}}
+	failing := Report{Success: false, Components: []Component{{Success: false, Violations: []evaluator.Result{{Message: "violation"}}}}}
+
+	cases := []struct {
+		name     string
+		report   Report
+		expected map[string]bool
+	}{
+		{name: "passing", report: passing, expected: map[string]bool{FailOnViolation: false, FailOnWarning: false, FailOnNever: false}},
+		{name: "warning", report: warning, expected: map[string]bool{FailOnViolation: false, FailOnWarning: true, FailOnNever: false}},
+		{name: "failing", report: failing, expected: map[string]bool{FailOnViolation: true, FailOnWarning: true, FailOnNever: false}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			for failOn, expected := range c.expected {
+				assert.Equal(t, expected, c.report.Failed(failOn), failOn)
+			}
+		})
+	}
+}
+
+func TestReportFailedSeverity(t *testing.T) {
+	result := func(code, severity string) evaluator.Result {
+		return evaluator.Result{Metadata: map[string]any{"code": code}, Severity: severity}
+	}
+
+	cases := []struct {
+		name      string
+		component Component
+		expected  map[string]bool
+	}{
+		{
+			name:      "passing",
+			component: Component{Success: true},
+			expected:  map[string]bool{evaluator.SeverityInfo: false, evaluator.SeverityCritical: false},
+		},
+		{
+			name:      "low violation",
+			component: Component{Violations: []evaluator.Result{result("pkg.rule", evaluator.SeverityLow)}},
+			expected:  map[string]bool{evaluator.SeverityInfo: true, evaluator.SeverityLow: true, evaluator.SeverityMedium: false},
+		},
+		{
+			name:      "violation without severity",
+			component: Component{Violations: []evaluator.Result{result("pkg.rule", "")}},
+			expected:  map[string]bool{evaluator.SeverityHigh: true, evaluator.SeverityCritical: false},
+		},
+		{
+			name:      "built-in violation",
+			component: Component{Violations: []evaluator.Result{result("builtin.image.signature_check", "")}},
+			expected:  map[string]bool{evaluator.SeverityCritical: true},
+		},
+		{
+			name:      "warning without severity",
+			component: Component{Success: true, Warnings: []evaluator.Result{result("pkg.rule", "")}},
+			expected:  map[string]bool{evaluator.SeverityLow: true, evaluator.SeverityMedium: false},
+		},
+		{
+			name:      "critical warning",
+			component: Component{Success: true, Warnings: []evaluator.Result{result("pkg.rule", evaluator.SeverityCritical)}},
+			expected:  map[string]bool{evaluator.SeverityCritical: true},
+		},
+		{
+			name:      "rate limited",
+			component: Component{RateLimited: true},
+			expected:  map[string]bool{evaluator.SeverityCritical: true},
+		},
+		{
+			name:      "not verified",
+			component: Component{Verification: &output.Verification{ImageSignature: output.Stage{Status: output.StageFailed}}},
+			expected:  map[string]bool{evaluator.SeverityCritical: true},
+		},
+		{
+			name:      "errored",
+			component: Component{Success: true, Status: StatusError},
+			expected:  map[string]bool{evaluator.SeverityCritical: true},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			report := Report{Components: []Component{c.component}}
+			for threshold, expected := range c.expected {
+				assert.Equal(t, expected, report.FailedSeverity(threshold), threshold)
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package applicationsnapshot
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/enterprise-contract/ec-cli/internal/attestation"
+)
+
+// OutputFormatter renders the report in a particular format.
+type OutputFormatter interface {
+	Format(w io.Writer, r *Report) error
+}
+
+// formatterFunc adapts a function rendering the report to an OutputFormatter.
+type formatterFunc func(r *Report) ([]byte, error)
+
+func (f formatterFunc) Format(w io.Writer, r *Report) error {
+	data, err := f(r)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(data)
+
+	return err
+}
+
+func marshalJSON(v func(r *Report) any) formatterFunc {
+	return func(r *Report) ([]byte, error) {
+		return json.Marshal(v(r))
+	}
+}
+
+// builtinFormatters holds the formatters of the built-in formats, these cannot
+// be replaced by registered formatters.
+var builtinFormatters = map[string]OutputFormatter{
+	JSON:            marshalJSON(func(r *Report) any { return r }),
+	YAML:            formatterFunc(func(r *Report) ([]byte, error) { return yaml.Marshal(r) }),
+	Text:            formatterFunc(generateTextReport),
+	AppStudio:       marshalJSON(func(r *Report) any { return r.toAppstudioReport() }),
+	HACBS:           marshalJSON(func(r *Report) any { return r.toAppstudioReport() }),
+	Summary:         marshalJSON(func(r *Report) any { return r.toSummary() }),
+	SummaryMarkdown: formatterFunc(generateMarkdownSummary),
+	JUnit:           formatterFunc(func(r *Report) ([]byte, error) { return xml.Marshal(r.toJUnit()) }),
+	Data:            formatterFunc(func(r *Report) ([]byte, error) { return yaml.Marshal(r.Data) }),
+	Attestation:     formatterFunc((*Report).renderAttestations),
+	PolicyInput:     formatterFunc(func(r *Report) ([]byte, error) { return bytes.Join(r.PolicyInput, []byte("\n")), nil }),
+	VSA:             formatterFunc((*Report).toVSA),
+	CycloneDX:       formatterFunc(func(r *Report) ([]byte, error) { return r.renderSBOMs(attestation.PredicateCycloneDXBOM) }),
+	SPDX:            formatterFunc(func(r *Report) ([]byte, error) { return r.renderSBOMs(attestation.PredicateSpdxDocument) }),
+	CSV:             formatterFunc((*Report).renderCSV),
+}
+
+// jsonFormats are the built-in formats rendering the report as a single JSON
+// document, indented when written to a terminal.
+var jsonFormats = map[string]bool{
+	JSON:      true,
+	AppStudio: true,
+	HACBS:     true,
+	Summary:   true,
+	VSA:       true,
+}
+
+var (
+	formattersMu sync.RWMutex
+	formatters   = map[string]OutputFormatter{}
+)
+
+// RegisterFormatter makes the formatter available for writing the report in
+// the format with the given name, replacing any formatter previously
+// registered with the same name. The built-in formats cannot be replaced.
+func RegisterFormatter(name string, f OutputFormatter) error {
+	if _, ok := builtinFormatters[name]; ok || name == None {
+		return fmt.Errorf("the built-in %q format cannot be replaced", name)
+	}
+
+	formattersMu.Lock()
+	defer formattersMu.Unlock()
+	formatters[name] = f
+
+	return nil
+}
+
+// formatter returns the formatter for the format with the given name.
+func formatter(name string) (OutputFormatter, bool) {
+	if f, ok := builtinFormatters[name]; ok {
+		return f, true
+	}
+
+	formattersMu.RLock()
+	defer formattersMu.RUnlock()
+	f, ok := formatters[name]
+
+	return f, ok
+}
+
+// pluginFormatter is a formatter delegating to an external program. The
+// program is given the report in JSON format on its standard input and is
+// expected to write the formatted report to its standard output.
+type pluginFormatter struct {
+	path string
+}
+
+// NewPluginFormatter returns a formatter running the program at the given path
+// to format the report.
+func NewPluginFormatter(path string) OutputFormatter {
+	return pluginFormatter{path: path}
+}
+
+func (p pluginFormatter) Format(w io.Writer, r *Report) error {
+	input, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.Command(p.path) /* #nosec */
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = w
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("formatter plugin %s failed: %w: %s", p.path, err, msg)
+		}
+		return fmt.Errorf("formatter plugin %s failed: %w", p.path, err)
+	}
+
+	return nil
+}
+
+// ParseFormatterPlugin parses the name=path specification of a formatter
+// plugin.
+func ParseFormatterPlugin(spec string) (string, OutputFormatter, error) {
+	name, path, ok := strings.Cut(spec, "=")
+	if !ok || name == "" || path == "" {
+		return "", nil, fmt.Errorf("invalid formatter plugin %q, expecting name=path", spec)
+	}
+
+	return name, NewPluginFormatter(path), nil
+}
@@ -0,0 +1,111 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package applicationsnapshot
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/format"
+)
+
+type snapshotFormatter struct{}
+
+func (snapshotFormatter) Format(w io.Writer, r *Report) error {
+	_, err := w.Write([]byte("snapshot: " + r.Snapshot))
+	return err
+}
+
+func TestRegisterFormatter(t *testing.T) {
+	require.NoError(t, RegisterFormatter("snapshot-name", snapshotFormatter{}))
+
+	var out bytes.Buffer
+	p := format.NewTargetParser(JSON, format.Options{}, &out, afero.NewMemMapFs())
+	report := Report{Snapshot: "my-snapshot"}
+	require.NoError(t, report.WriteAll([]string{"snapshot-name"}, p))
+	assert.Equal(t, "snapshot: my-snapshot\n", out.String())
+
+	assert.EqualError(t, RegisterFormatter(JSON, snapshotFormatter{}), `the built-in "json" format cannot be replaced`)
+	assert.EqualError(t, RegisterFormatter(None, snapshotFormatter{}), `the built-in "none" format cannot be replaced`)
+
+	_, err := report.toFormat("unknown")
+	assert.EqualError(t, err, `"unknown" is not a valid report format`)
+}
+
+func TestBuiltinFormatters(t *testing.T) {
+	for _, f := range OutputFormats {
+		if f == None {
+			continue
+		}
+		_, ok := formatter(f)
+		assert.True(t, ok, "no formatter for the %q format", f)
+	}
+}
+
+func TestPluginFormatter(t *testing.T) {
+	dir := t.TempDir()
+
+	script := func(name, content string) string {
+		p := path.Join(dir, name)
+		require.NoError(t, os.WriteFile(p, []byte("#!/bin/sh\n"+content+"\n"), 0700))
+		return p
+	}
+
+	report := Report{Snapshot: "my-snapshot", Success: true}
+
+	t.Run("echo", func(t *testing.T) {
+		var out bytes.Buffer
+		require.NoError(t, NewPluginFormatter(script("echo", "cat")).Format(&out, &report))
+
+		var got map[string]any
+		require.NoError(t, json.Unmarshal(out.Bytes(), &got))
+		assert.Equal(t, "my-snapshot", got["snapshot"])
+		assert.Equal(t, true, got["success"])
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		err := NewPluginFormatter(script("fail", "echo boom >&2; exit 3")).Format(io.Discard, &report)
+		assert.ErrorContains(t, err, "formatter plugin "+path.Join(dir, "fail")+" failed: exit status 3: boom")
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		err := NewPluginFormatter(path.Join(dir, "missing")).Format(io.Discard, &report)
+		assert.ErrorContains(t, err, "formatter plugin "+path.Join(dir, "missing")+" failed")
+	})
+}
+
+func TestParseFormatterPlugin(t *testing.T) {
+	name, f, err := ParseFormatterPlugin("custom=/usr/bin/custom")
+	require.NoError(t, err)
+	assert.Equal(t, "custom", name)
+	assert.Equal(t, pluginFormatter{path: "/usr/bin/custom"}, f)
+
+	for _, spec := range []string{"custom", "=/usr/bin/custom", "custom="} {
+		_, _, err := ParseFormatterPlugin(spec)
+		assert.EqualError(t, err, `invalid formatter plugin "`+spec+`", expecting name=path`)
+	}
+}
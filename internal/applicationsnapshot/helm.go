@@ -0,0 +1,161 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package applicationsnapshot
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"slices"
+	"sort"
+	"strings"
+
+	app "github.com/konflux-ci/application-api/api/v1alpha1"
+	k8syaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/yaml"
+)
+
+// helmReleaseName is the name of the release the chart is rendered as, the
+// images do not depend on it for most charts
+const helmReleaseName = "ec"
+
+// containerKeys are the keys of the lists of containers in the Kubernetes
+// manifests, at any depth, e.g. in a Pod, a Deployment or a CronJob
+var containerKeys = []string{"containers", "initContainers", "ephemeralContainers"}
+
+// renderHelmChart renders the templates of the Helm chart, a directory or an
+// archive, with the values files given, using the helm program found on the
+// PATH. The helm program is used rather than the Helm SDK, which would add
+// Helm's own, and often conflicting, versions of the Kubernetes client
+// libraries to the dependencies. Rendering stops when the context is done.
+// Replaceable in tests.
+var renderHelmChart = func(ctx context.Context, chart string, values []string) ([]byte, error) {
+	args := []string{"template", helmReleaseName, chart}
+	for _, v := range values {
+		args = append(args, "--values", v)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "helm", args...) /* #nosec */
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("unable to render the Helm chart %s: %w: %s", chart, err, msg)
+		}
+		return nil, fmt.Errorf("unable to render the Helm chart %s: %w", chart, err)
+	}
+
+	return stdout.Bytes(), nil
+}
+
+// readHelmChart creates a component for each distinct image of the containers
+// in the manifests rendered from the Helm chart, including its subcharts, each
+// named after the container.
+func readHelmChart(ctx context.Context, chart string, values []string) ([]app.SnapshotComponent, error) {
+	rendered, err := renderHelmChart(ctx, chart, values)
+	if err != nil {
+		return nil, err
+	}
+
+	components, err := readManifestImages(rendered)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the manifests rendered from the Helm chart %s: %w", chart, err)
+	}
+
+	if len(components) == 0 {
+		return nil, fmt.Errorf("no images found in the manifests rendered from the Helm chart %s", chart)
+	}
+
+	return components, nil
+}
+
+// readManifestImages creates a component for each distinct image of the
+// containers in the Kubernetes manifests, given as a stream of YAML documents.
+func readManifestImages(content []byte) ([]app.SnapshotComponent, error) {
+	var components []app.SnapshotComponent
+	seen := map[string]bool{}
+
+	reader := k8syaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(content)))
+	for {
+		doc, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var manifest any
+		if err := yaml.Unmarshal(doc, &manifest); err != nil {
+			return nil, err
+		}
+
+		for _, c := range findContainers(manifest) {
+			if c.ContainerImage == "" || seen[c.ContainerImage] {
+				continue
+			}
+			seen[c.ContainerImage] = true
+			components = append(components, c)
+		}
+	}
+
+	return components, nil
+}
+
+// findContainers returns a component for each container found in the lists of
+// containers at any depth of the manifest.
+func findContainers(manifest any) []app.SnapshotComponent {
+	var components []app.SnapshotComponent
+	switch m := manifest.(type) {
+	case map[string]any:
+		// in the order of the keys for the components to be in a stable order
+		keys := make([]string, 0, len(m))
+		for k := range m {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			v := m[k]
+			if containers, ok := v.([]any); ok && slices.Contains(containerKeys, k) {
+				for _, c := range containers {
+					container, _ := c.(map[string]any)
+					image, _ := container["image"].(string)
+					name, _ := container["name"].(string)
+					if name == "" {
+						name = unnamed
+					}
+					components = append(components, app.SnapshotComponent{Name: name, ContainerImage: image})
+				}
+				continue
+			}
+			components = append(components, findContainers(v)...)
+		}
+	case []any:
+		for _, v := range m {
+			components = append(components, findContainers(v)...)
+		}
+	}
+
+	return components
+}
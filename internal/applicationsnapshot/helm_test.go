@@ -0,0 +1,123 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package applicationsnapshot
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	app "github.com/konflux-ci/application-api/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/utils/oci"
+	"github.com/enterprise-contract/ec-cli/internal/utils/oci/fake"
+)
+
+const renderedChart = `---
+# Source: app/templates/deployment.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: ec-app
+spec:
+  template:
+    spec:
+      initContainers:
+        - name: migrate
+          image: registry.io/app/migrate:1.0
+      containers:
+        - name: app
+          image: registry.io/app/app@sha256:0000000000000000000000000000000000000000000000000000000000000000
+        - name: sidecar
+          image: registry.io/app/sidecar:2.0
+---
+# Source: app/charts/db/templates/cronjob.yaml
+apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: ec-db-backup
+spec:
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          containers:
+            - name: backup
+              image: registry.io/app/sidecar:2.0
+---
+# Source: app/templates/service.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: ec-app
+`
+
+func TestReadManifestImages(t *testing.T) {
+	components, err := readManifestImages([]byte(renderedChart))
+	require.NoError(t, err)
+	assert.Equal(t, []app.SnapshotComponent{
+		{Name: "app", ContainerImage: "registry.io/app/app@sha256:0000000000000000000000000000000000000000000000000000000000000000"},
+		{Name: "sidecar", ContainerImage: "registry.io/app/sidecar:2.0"},
+		{Name: "migrate", ContainerImage: "registry.io/app/migrate:1.0"},
+	}, components)
+
+	_, err = readManifestImages([]byte("kind: [spam"))
+	assert.Error(t, err)
+}
+
+func TestReadHelmChart(t *testing.T) {
+	render := renderHelmChart
+	t.Cleanup(func() { renderHelmChart = render })
+
+	var given []string
+	renderHelmChart = func(_ context.Context, chart string, values []string) ([]byte, error) {
+		given = append([]string{chart}, values...)
+		switch chart {
+		case "empty":
+			return []byte("kind: ConfigMap\n"), nil
+		case "broken":
+			return nil, errors.New("unable to render the Helm chart broken: exit status 1")
+		}
+		return []byte(renderedChart), nil
+	}
+
+	components, err := readHelmChart(context.Background(), "./chart", []string{"a.yaml", "b.yaml"})
+	require.NoError(t, err)
+	assert.Len(t, components, 3)
+	assert.Equal(t, []string{"./chart", "a.yaml", "b.yaml"}, given)
+
+	_, err = readHelmChart(context.Background(), "empty", nil)
+	assert.EqualError(t, err, "no images found in the manifests rendered from the Helm chart empty")
+
+	_, err = readHelmChart(context.Background(), "broken", nil)
+	assert.EqualError(t, err, "unable to render the Helm chart broken: exit status 1")
+
+	client := fake.FakeClient{}
+	client.On("Head", mock.Anything).Return(&v1.Descriptor{MediaType: types.OCIManifestSchema1}, nil)
+	ctx := oci.WithClient(context.Background(), &client)
+
+	snap, _, err := DetermineInput(ctx, Input{HelmChart: "./chart"})
+	require.NoError(t, err)
+	assert.Len(t, snap.Components, 3)
+}
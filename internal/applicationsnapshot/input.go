@@ -20,6 +20,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"reflect"
+	"strings"
 
 	"github.com/google/go-containerregistry/pkg/name"
 	"github.com/hashicorp/go-multierror"
@@ -41,11 +43,99 @@ type Input struct {
 	JSON     string // Deprecated: replaced by images
 	Image    string
 	Snapshot string
-	Images   string
+	// ImageTagConstraints select the image to validate by the semantic
+	// version of its tag, see ParseTagConstraint
+	ImageTagConstraints []string
+	// Images are the ApplicationSnapshot Specs, each either a path to a file
+	// or the JSON representation, combined into a single snapshot
+	Images []string
+	// DigestFile is a file listing the images to validate, one pinned image
+	// reference per line
+	DigestFile string
+	// HelmChart is a Helm chart, a directory or an archive, rendered with the
+	// HelmValues files to find the images of its containers
+	HelmChart  string
+	HelmValues []string
+	// MergeSnapshots resolves conflicting component policies of the same
+	// image, one of MergeSnapshotsValues. When empty, conflicts are an error
+	MergeSnapshots string
+	// FailOnDuplicate fails on duplicate components, see FindDuplicates,
+	// instead of warning about them
+	FailOnDuplicate bool
+}
+
+// Resolutions of conflicting component policies of the same image
+const (
+	// MergeSnapshotsFirst keeps the component policy read first
+	MergeSnapshotsFirst = "first"
+	// MergeSnapshotsLast keeps the component policy read last
+	MergeSnapshotsLast = "last"
+)
+
+// MergeSnapshotsValues lists the supported resolutions of conflicting
+// component policies
+var MergeSnapshotsValues = []string{MergeSnapshotsFirst, MergeSnapshotsLast}
+
+// ValidateMergeSnapshots returns an error if the given resolution of
+// conflicting component policies is not supported.
+func ValidateMergeSnapshots(resolution string) error {
+	if !slices.Contains(MergeSnapshotsValues, resolution) {
+		return fmt.Errorf("invalid --merge-snapshots value %q, expecting one of: %s", resolution, strings.Join(MergeSnapshotsValues, ", "))
+	}
+
+	return nil
 }
 
 type snapshot struct {
 	app.SnapshotSpec
+	policies ComponentPolicies
+	// resolution of conflicting component policies, see Input.MergeSnapshots
+	resolution string
+}
+
+// imageKey identifies the image of a container image reference. References
+// pinned by digest identify the same image regardless of their tag.
+func imageKey(image string) string {
+	if ref, err := name.NewDigest(image); err == nil {
+		return fmt.Sprintf("%s@%s", ref.Context().Name(), ref.DigestStr())
+	}
+
+	return image
+}
+
+// mergePolicies adds the component policies of the components not yet having
+// one. A different policy for a component already having one is resolved
+// according to the resolution of the snapshot, or is an error if no
+// resolution was given.
+func (s *snapshot) mergePolicies(policies ComponentPolicies) error {
+	if s.policies == nil {
+		s.policies = ComponentPolicies{}
+	}
+
+	for image, p := range policies {
+		key := imageKey(image)
+		existing, ok := s.policies[key]
+		if !ok {
+			s.policies[key] = p
+			continue
+		}
+
+		if reflect.DeepEqual(existing, p) {
+			continue
+		}
+
+		switch s.resolution {
+		case MergeSnapshotsFirst:
+			log.Debugf("Keeping the first policy of the component with image %s", image)
+		case MergeSnapshotsLast:
+			log.Debugf("Keeping the last policy of the component with image %s", image)
+			s.policies[key] = p
+		default:
+			return fmt.Errorf("conflicting policies of the component with image %s, use --merge-snapshots to resolve", image)
+		}
+	}
+
+	return nil
 }
 
 func (s *snapshot) merge(snap app.SnapshotSpec) {
@@ -63,18 +153,19 @@ func (s *snapshot) merge(snap app.SnapshotSpec) {
 
 	images := map[string]string{}
 	for _, c := range s.Components {
-		images[c.ContainerImage] = c.Name
+		images[imageKey(c.ContainerImage)] = c.Name
 	}
 	for _, c := range snap.Components {
-		if name, ok := images[c.ContainerImage]; !ok || name == "" || name == unnamed {
+		key := imageKey(c.ContainerImage)
+		if name, ok := images[key]; !ok || name == "" || name == unnamed {
 			if ok {
-				images[c.ContainerImage] = c.Name
+				images[key] = c.Name
 				i := slices.IndexFunc(s.Components, func(x app.SnapshotComponent) bool {
-					return x.ContainerImage == c.ContainerImage
+					return imageKey(x.ContainerImage) == key
 				})
 				s.Components[i].Name = c.Name
 			} else {
-				images[c.ContainerImage] = c.Name
+				images[key] = c.Name
 				s.Components = append(s.Components, c)
 			}
 		}
@@ -82,25 +173,44 @@ func (s *snapshot) merge(snap app.SnapshotSpec) {
 }
 
 func DetermineInputSpec(ctx context.Context, input Input) (*app.SnapshotSpec, error) {
-	var snapshot snapshot
+	spec, _, err := DetermineInput(ctx, input)
+
+	return spec, err
+}
+
+// DetermineInput determines the snapshot specification to validate along with
+// the policies of its components, see ComponentPolicy.
+func DetermineInput(ctx context.Context, input Input) (*app.SnapshotSpec, ComponentPolicies, error) {
+	snapshot := snapshot{resolution: input.MergeSnapshots}
 	provided := false
 
-	if input.Images != "" {
+	for _, images := range input.Images {
+		if images == "" {
+			continue
+		}
+
 		var content []byte
 		var err error
 		fs := utils.FS(ctx)
-		content, err = afero.ReadFile(fs, input.Images)
+		content, err = afero.ReadFile(fs, images)
 		if err != nil {
 			log.Debugf("could not read images from file: %v", err)
 			// could not read as file so expecting string
-			content = []byte(input.Images)
+			content = []byte(images)
 		}
 
 		file, err := readSnapshotSource(content)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		snapshot.merge(file)
+		policies, err := readComponentPolicies(content)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := snapshot.mergePolicies(policies); err != nil {
+			return nil, nil, err
+		}
 		provided = true
 	}
 
@@ -109,13 +219,20 @@ func DetermineInputSpec(ctx context.Context, input Input) (*app.SnapshotSpec, er
 		fs := utils.FS(ctx)
 		content, err := afero.ReadFile(fs, input.File)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		file, err := readSnapshotSource(content)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		snapshot.merge(file)
+		policies, err := readComponentPolicies(content)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := snapshot.mergePolicies(policies); err != nil {
+			return nil, nil, err
+		}
 		provided = true
 	}
 
@@ -123,9 +240,16 @@ func DetermineInputSpec(ctx context.Context, input Input) (*app.SnapshotSpec, er
 	if input.JSON != "" {
 		json, err := readSnapshotSource([]byte(input.JSON))
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		snapshot.merge(json)
+		policies, err := readComponentPolicies([]byte(input.JSON))
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := snapshot.mergePolicies(policies); err != nil {
+			return nil, nil, err
+		}
 		provided = true
 	}
 
@@ -144,17 +268,53 @@ func DetermineInputSpec(ctx context.Context, input Input) (*app.SnapshotSpec, er
 		provided = true
 	}
 
+	for _, constraint := range input.ImageTagConstraints {
+		c, err := ParseTagConstraint(constraint)
+		if err != nil {
+			return nil, nil, err
+		}
+		component, err := c.Resolve(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		snapshot.merge(app.SnapshotSpec{Components: []app.SnapshotComponent{component}})
+		provided = true
+	}
+
+	if input.DigestFile != "" {
+		fs := utils.FS(ctx)
+		content, err := afero.ReadFile(fs, input.DigestFile)
+		if err != nil {
+			return nil, nil, err
+		}
+		components, err := readDigestFile(content)
+		if err != nil {
+			return nil, nil, fmt.Errorf("unable to read the digests from %s: %w", input.DigestFile, err)
+		}
+		snapshot.merge(app.SnapshotSpec{Components: components})
+		provided = true
+	}
+
+	if input.HelmChart != "" {
+		components, err := readHelmChart(ctx, input.HelmChart, input.HelmValues)
+		if err != nil {
+			return nil, nil, err
+		}
+		snapshot.merge(app.SnapshotSpec{Components: components})
+		provided = true
+	}
+
 	if input.Snapshot != "" {
 		client, err := kubernetes.NewClient(ctx)
 		if err != nil {
 			log.Debugf("Unable to initialize Kubernetes Client: %v", err)
-			return nil, err
+			return nil, nil, err
 		}
 
 		cluster, err := client.FetchSnapshot(ctx, input.Snapshot)
 		if err != nil {
 			log.Debugf("Unable to fetch snapshot %s from Kubernetes cluster: %v", input.Snapshot, err)
-			return nil, err
+			return nil, nil, err
 		}
 		snapshot.merge(cluster.Spec)
 		provided = true
@@ -162,11 +322,36 @@ func DetermineInputSpec(ctx context.Context, input Input) (*app.SnapshotSpec, er
 
 	if !provided {
 		log.Debug("No application snapshot available")
-		return nil, errors.New("neither Snapshot nor image reference provided to validate")
+		return nil, nil, errors.New("neither Snapshot nor image reference provided to validate")
+	}
+
+	// Checked before expanding image indexes, the components expanded from an
+	// image index share the repository
+	if duplicates := FindDuplicates(snapshot.Components); len(duplicates) > 0 {
+		if input.FailOnDuplicate {
+			return nil, nil, DuplicatesError{Duplicates: duplicates}
+		}
+		for _, d := range duplicates {
+			log.Warnf("Duplicate in the snapshot, %s", d)
+		}
+	}
+
+	expanded := expandImageIndex(ctx, &snapshot.SnapshotSpec)
+
+	// The components expanded from an image index share the policy of the
+	// image index
+	policies := ComponentPolicies{}
+	for _, c := range snapshot.Components {
+		image := c.ContainerImage
+		if index, ok := expanded[image]; ok {
+			image = index
+		}
+		if p, ok := snapshot.policies[imageKey(image)]; ok {
+			policies[c.ContainerImage] = p
+		}
 	}
-	expandImageIndex(ctx, &snapshot.SnapshotSpec)
 
-	return &snapshot.SnapshotSpec, nil
+	return &snapshot.SnapshotSpec, policies, nil
 }
 
 func readSnapshotSource(input []byte) (app.SnapshotSpec, error) {
@@ -181,8 +366,40 @@ func readSnapshotSource(input []byte) (app.SnapshotSpec, error) {
 	return file, nil
 }
 
-func expandImageIndex(ctx context.Context, snap *app.SnapshotSpec) {
+// readDigestFile creates a component for each image reference pinned by digest
+// in the content, one reference per line. Blank lines and lines starting with
+// # are ignored.
+func readDigestFile(content []byte) ([]app.SnapshotComponent, error) {
+	var components []app.SnapshotComponent
+	for i, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if _, err := name.NewDigest(line); err != nil {
+			return nil, fmt.Errorf("line %d is not an image reference pinned by digest: %w", i+1, err)
+		}
+
+		components = append(components, app.SnapshotComponent{
+			Name:           unnamed,
+			ContainerImage: line,
+		})
+	}
+
+	if len(components) == 0 {
+		return nil, errors.New("no image references found")
+	}
+
+	return components, nil
+}
+
+// expandImageIndex replaces each component referring to an image index with a
+// component for each of its image manifests, returning the container images of
+// the added components mapped to the container image of the image index.
+func expandImageIndex(ctx context.Context, snap *app.SnapshotSpec) map[string]string {
 	client := oci.NewClient(ctx)
+	expanded := map[string]string{}
 	// For an image index, remove the original component and replace it with an expanded component with all its image manifests
 	var components []app.SnapshotComponent
 	// Do not raise an error if the image is inaccessible, it will be handled as a violation when evaluated against the policy
@@ -232,6 +449,7 @@ func expandImageIndex(ctx context.Context, snap *app.SnapshotSpec) {
 			archComponent.Name = fmt.Sprintf("%s-%s-%s", component.Name, manifest.Digest, arch)
 			archComponent.ContainerImage = fmt.Sprintf("%s@%s", ref.Context().Name(), manifest.Digest)
 			components = append(components, archComponent)
+			expanded[archComponent.ContainerImage] = component.ContainerImage
 		}
 	}
 
@@ -241,4 +459,6 @@ func expandImageIndex(ctx context.Context, snap *app.SnapshotSpec) {
 		log.Warnf("Encountered error while checking for Image Index: %v", allErrors)
 	}
 	log.Debugf("Snap component after expanding the image index is %v", snap.Components)
+
+	return expanded
 }
@@ -0,0 +1,67 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package applicationsnapshot
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/afero"
+)
+
+// DumpInputs writes the input documents the policies were evaluated with to
+// the file, as a JSON object keyed by the image reference. The redactions are
+// applied to the string values of the input documents.
+func DumpInputs(fs afero.Fs, file string, inputs map[string][]byte, redactions Redactions) error {
+	documents := make(map[string]any, len(inputs))
+	for image, input := range inputs {
+		var doc any
+		if err := json.Unmarshal(input, &doc); err != nil {
+			return fmt.Errorf("unable to parse the input of image %s: %w", image, err)
+		}
+		documents[image] = redactions.redactValue(doc)
+	}
+
+	content, err := json.MarshalIndent(documents, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return afero.WriteFile(fs, file, content, 0644)
+}
+
+// redactValue applies the redactions to the strings within the value decoded
+// from JSON.
+func (r Redactions) redactValue(value any) any {
+	switch v := value.(type) {
+	case string:
+		for _, re := range r {
+			v = re.ReplaceAllLiteralString(v, redactedReplacement)
+		}
+		return v
+	case []any:
+		for i := range v {
+			v[i] = r.redactValue(v[i])
+		}
+	case map[string]any:
+		for k := range v {
+			v[k] = r.redactValue(v[k])
+		}
+	}
+
+	return value
+}
@@ -0,0 +1,60 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package applicationsnapshot
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDumpInputs(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	redactions, err := ParseRedactions([]string{`internal\.example\.com`})
+	require.NoError(t, err)
+
+	inputs := map[string][]byte{
+		"registry.io/repository/image:b": []byte(`{"image":{"ref":"registry.io/repository/image:b"}}`),
+		"registry.io/repository/image:a": []byte(`{"image":{"ref":"registry.io/repository/image:a"},"attestations":[{"builder":"https://internal.example.com/builder","count":1}]}`),
+	}
+
+	require.NoError(t, DumpInputs(fs, "input.json", inputs, redactions))
+
+	content, err := afero.ReadFile(fs, "input.json")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"registry.io/repository/image:a": {
+			"image": {"ref": "registry.io/repository/image:a"},
+			"attestations": [{"builder": "https://***/builder", "count": 1}]
+		},
+		"registry.io/repository/image:b": {
+			"image": {"ref": "registry.io/repository/image:b"}
+		}
+	}`, string(content))
+}
+
+func TestDumpInputsInvalid(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	err := DumpInputs(fs, "input.json", map[string][]byte{"registry.io/repository/image:tag": []byte("{")}, nil)
+	assert.ErrorContains(t, err, "unable to parse the input of image registry.io/repository/image:tag")
+}
@@ -26,6 +26,7 @@ import (
 	"strings"
 	"testing"
 
+	ecc "github.com/enterprise-contract/enterprise-contract-controller/api/v1alpha1"
 	"github.com/google/go-containerregistry/pkg/name"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
 	gcrfake "github.com/google/go-containerregistry/pkg/v1/fake"
@@ -91,12 +92,12 @@ func Test_DetermineInputSpec(t *testing.T) {
 		},
 		{
 			name:  "snapShotSource as a string",
-			input: Input{Images: string(testJson)},
+			input: Input{Images: []string{string(testJson)}},
 			want:  snapshot,
 		},
 		{
 			name:  "snapShotSource as a file",
-			input: Input{Images: "/home/list-of-images.json"},
+			input: Input{Images: []string{"/home/list-of-images.json"}},
 			want:  snapshot,
 		},
 		{
@@ -172,8 +173,8 @@ func Test_DetermineInputSpec(t *testing.T) {
 				}
 			}
 
-			if tc.input.Images == "/home/list-of-images.json" {
-				if err := afero.WriteFile(fs, tc.input.Images, []byte(testJson), 0400); err != nil {
+			if len(tc.input.Images) == 1 && tc.input.Images[0] == "/home/list-of-images.json" {
+				if err := afero.WriteFile(fs, tc.input.Images[0], []byte(testJson), 0400); err != nil {
 					panic(err)
 				}
 			}
@@ -235,6 +236,176 @@ func TestReadSnapshotFile(t *testing.T) {
 	})
 }
 
+func TestDetermineInputDigestFile(t *testing.T) {
+	digest1 := "registry.io/repository/image@sha256:" + strings.Repeat("a", 64)
+	digest2 := "registry.io/repository/other@sha256:" + strings.Repeat("b", 64)
+
+	cases := []struct {
+		name     string
+		content  string
+		expected []app.SnapshotComponent
+		err      string
+	}{
+		{
+			name:    "digests",
+			content: "# pinned images\n" + digest1 + "\n\n  " + digest2 + "  \n",
+			expected: []app.SnapshotComponent{
+				{Name: "Unnamed", ContainerImage: digest1},
+				{Name: "Unnamed", ContainerImage: digest2},
+			},
+		},
+		{
+			name:    "duplicates",
+			content: digest1 + "\n" + digest1,
+			expected: []app.SnapshotComponent{
+				{Name: "Unnamed", ContainerImage: digest1},
+			},
+		},
+		{
+			name:    "tag",
+			content: digest1 + "\nregistry.io/repository/image:tag\n",
+			err:     "unable to read the digests from /digests.txt: line 2 is not an image reference pinned by digest",
+		},
+		{
+			name:    "empty",
+			content: "# nothing\n\n",
+			err:     "unable to read the digests from /digests.txt: no image references found",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			ctx := utils.WithFS(context.Background(), fs)
+
+			client := fake.FakeClient{}
+			client.On("Head", mock.Anything).Return(&v1.Descriptor{MediaType: types.OCIManifestSchema1}, nil)
+			ctx = oci.WithClient(ctx, &client)
+
+			assert.NoError(t, afero.WriteFile(fs, "/digests.txt", []byte(c.content), 0400))
+
+			got, err := DetermineInputSpec(ctx, Input{DigestFile: "/digests.txt"})
+			if c.err != "" {
+				assert.ErrorContains(t, err, c.err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, &app.SnapshotSpec{Components: c.expected}, got)
+		})
+	}
+}
+
+func TestDetermineInputMultipleSnapshots(t *testing.T) {
+	digest := "sha256:" + strings.Repeat("a", 64)
+	image := "registry.io/repository/image@" + digest
+	tagged := "registry.io/repository/image:tag@" + digest
+	other := "registry.io/repository/other@sha256:" + strings.Repeat("b", 64)
+
+	first := ComponentPolicy{Sources: []ecc.Source{{Name: "first"}}}
+	last := ComponentPolicy{Sources: []ecc.Source{{Name: "last"}}}
+
+	snapshotA := `{"components":[
+		{"name":"a","containerImage":"` + image + `","policy":{"sources":[{"name":"first"}]}}
+	]}`
+	snapshotB := `{"components":[
+		{"name":"b","containerImage":"` + tagged + `","policy":{"sources":[{"name":"last"}]}},
+		{"name":"other","containerImage":"` + other + `"}
+	]}`
+	snapshotSame := `{"components":[
+		{"name":"same","containerImage":"` + tagged + `","policy":{"sources":[{"name":"first"}]}}
+	]}`
+
+	components := []app.SnapshotComponent{
+		{Name: "a", ContainerImage: image},
+		{Name: "other", ContainerImage: other},
+	}
+
+	cases := []struct {
+		name       string
+		images     []string
+		resolution string
+		expected   []app.SnapshotComponent
+		policies   ComponentPolicies
+		err        string
+	}{
+		{
+			name:     "same policy",
+			images:   []string{"/a.json", snapshotSame},
+			expected: []app.SnapshotComponent{{Name: "a", ContainerImage: image}},
+			policies: ComponentPolicies{image: first},
+		},
+		{
+			name:   "conflicting policies",
+			images: []string{"/a.json", snapshotB},
+			err:    "conflicting policies of the component with image " + tagged + ", use --merge-snapshots to resolve",
+		},
+		{
+			name:       "first policy",
+			images:     []string{"/a.json", snapshotB},
+			resolution: MergeSnapshotsFirst,
+			expected:   components,
+			policies:   ComponentPolicies{image: first},
+		},
+		{
+			name:       "last policy",
+			images:     []string{"/a.json", snapshotB},
+			resolution: MergeSnapshotsLast,
+			expected:   components,
+			policies:   ComponentPolicies{image: last},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			ctx := utils.WithFS(context.Background(), fs)
+
+			client := fake.FakeClient{}
+			client.On("Head", mock.Anything).Return(&v1.Descriptor{MediaType: types.OCIManifestSchema1}, nil)
+			ctx = oci.WithClient(ctx, &client)
+
+			assert.NoError(t, afero.WriteFile(fs, "/a.json", []byte(snapshotA), 0400))
+
+			spec, policies, err := DetermineInput(ctx, Input{Images: c.images, MergeSnapshots: c.resolution})
+			if c.err != "" {
+				assert.EqualError(t, err, c.err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, c.expected, spec.Components)
+			assert.Equal(t, c.policies, policies)
+		})
+	}
+}
+
+func TestDetermineInputDuplicates(t *testing.T) {
+	snapshot := `{"components":[
+		{"name":"a","containerImage":"registry.io/repository/image@sha256:` + strings.Repeat("a", 64) + `"},
+		{"name":"a","containerImage":"registry.io/repository/image@sha256:` + strings.Repeat("b", 64) + `"}
+	]}`
+
+	client := fake.FakeClient{}
+	client.On("Head", mock.Anything).Return(&v1.Descriptor{MediaType: types.OCIManifestSchema1}, nil)
+	ctx := oci.WithClient(utils.WithFS(context.Background(), afero.NewMemMapFs()), &client)
+
+	spec, _, err := DetermineInput(ctx, Input{Images: []string{snapshot}})
+	assert.NoError(t, err, "duplicates are only a warning")
+	assert.Len(t, spec.Components, 2)
+
+	_, _, err = DetermineInput(ctx, Input{Images: []string{snapshot}, FailOnDuplicate: true})
+	assert.EqualError(t, err, "1 duplicate components found in the snapshot:\n"+
+		"  component a has different images: registry.io/repository/image@sha256:"+strings.Repeat("a", 64)+
+		", registry.io/repository/image@sha256:"+strings.Repeat("b", 64))
+}
+
+func TestValidateMergeSnapshots(t *testing.T) {
+	assert.NoError(t, ValidateMergeSnapshots(MergeSnapshotsFirst))
+	assert.NoError(t, ValidateMergeSnapshots(MergeSnapshotsLast))
+	assert.EqualError(t, ValidateMergeSnapshots("union"), `invalid --merge-snapshots value "union", expecting one of: first, last`)
+}
+
 func TestExpandImageIndex(t *testing.T) {
 	client := fake.FakeClient{}
 	expectedRef := name.MustParseReference("registry.io/repository/image:tag")
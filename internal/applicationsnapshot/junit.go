@@ -78,9 +78,19 @@ func (r *Report) toJUnit() junit.Testsuites {
 			}, {
 				Name:  "success",
 				Value: fmt.Sprint(component.Success),
+			}, {
+				Name:  "status",
+				Value: string(component.Status),
 			},
 		}
 
+		if r.Redacted > 0 {
+			properties = append(properties, junit.Property{
+				Name:  "redacted",
+				Value: fmt.Sprint(r.Redacted),
+			})
+		}
+
 		for _, s := range component.Signatures {
 			properties = append(properties, junit.Property{
 				Name:  "keyId",
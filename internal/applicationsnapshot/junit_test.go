@@ -146,6 +146,7 @@ func TestToJunit(t *testing.T) {
 							},
 						},
 						Success: true,
+						Status:  StatusPass,
 					},
 				},
 				Key:     "key",
@@ -175,6 +176,10 @@ func TestToJunit(t *testing.T) {
 								Name:  "success",
 								Value: "true",
 							},
+							{
+								Name:  "status",
+								Value: "pass",
+							},
 							{
 								Name:  "keyId",
 								Value: "keyID1",
@@ -0,0 +1,166 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package applicationsnapshot
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"sigs.k8s.io/yaml"
+
+	"github.com/enterprise-contract/ec-cli/internal/evaluator"
+)
+
+// KnownViolation is a violation that is expected and does not fail the
+// validation.
+type KnownViolation struct {
+	// Image is the image reference, or a glob matching image references, of
+	// the images the violation is known for
+	Image string `json:"image"`
+	// Code is the code of the rule reporting the violation
+	Code string `json:"code"`
+}
+
+// KnownViolations is the baseline of violations that are expected, e.g. when
+// adopting a stricter policy, only new violations fail the validation. The
+// violations of the built-in checks, e.g. of the image signature, cannot be
+// known violations.
+type KnownViolations []KnownViolation
+
+// ReadKnownViolations reads the known violations from a YAML file listing the
+// image and rule code of each violation.
+func ReadKnownViolations(fs afero.Fs, file string) (KnownViolations, error) {
+	content, err := afero.ReadFile(fs, file)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the known violations: %w", err)
+	}
+
+	var known KnownViolations
+	if err := yaml.Unmarshal(content, &known); err != nil {
+		return nil, fmt.Errorf("unable to parse the known violations from %s: %w", file, err)
+	}
+
+	for i, k := range known {
+		if k.Image == "" || k.Code == "" {
+			return nil, fmt.Errorf("known violation %d in %s requires both the image and the code", i+1, file)
+		}
+		if builtinCode(k.Code) {
+			return nil, fmt.Errorf("known violation %d in %s is of the built-in check %s, which cannot be known", i+1, file, k.Code)
+		}
+		if _, err := path.Match(k.Image, ""); err != nil {
+			return nil, fmt.Errorf("invalid image glob %q of known violation %d in %s: %w", k.Image, i+1, file, err)
+		}
+	}
+
+	return known, nil
+}
+
+// NewKnownViolations lists the violations, including the already known ones,
+// of the components. Violations without a rule code are not listed as they
+// cannot be matched, nor are the violations of the built-in checks.
+func NewKnownViolations(components []Component) KnownViolations {
+	seen := map[KnownViolation]bool{}
+	known := KnownViolations{}
+	for _, c := range components {
+		for _, v := range append(append([]evaluator.Result{}, c.Violations...), c.KnownViolations...) {
+			k := KnownViolation{Image: c.ContainerImage, Code: ruleCode(v)}
+			if k.Code == "" || builtinCode(k.Code) || seen[k] {
+				continue
+			}
+			seen[k] = true
+			known = append(known, k)
+		}
+	}
+
+	sort.Slice(known, func(i, j int) bool {
+		if known[i].Image != known[j].Image {
+			return known[i].Image < known[j].Image
+		}
+		return known[i].Code < known[j].Code
+	})
+
+	return known
+}
+
+// Write writes the known violations to the YAML file.
+func (k KnownViolations) Write(fs afero.Fs, file string) error {
+	content, err := yaml.Marshal(k)
+	if err != nil {
+		return err
+	}
+
+	return afero.WriteFile(fs, file, content, 0644)
+}
+
+// Apply moves the known violations of each component from its violations to
+// its known violations. Components failing only on known violations succeed.
+func (k KnownViolations) Apply(components []Component) {
+	for i := range components {
+		c := &components[i]
+
+		var violations []evaluator.Result
+		for _, v := range c.Violations {
+			if k.matches(c.ContainerImage, ruleCode(v)) {
+				log.Debugf("Known violation %s of image %s", ruleCode(v), c.ContainerImage)
+				c.KnownViolations = append(c.KnownViolations, v)
+			} else {
+				violations = append(violations, v)
+			}
+		}
+
+		if len(violations) == len(c.Violations) {
+			continue
+		}
+
+		c.Violations = violations
+		c.Success = len(c.Violations) == 0 && !c.RateLimited
+	}
+}
+
+func (k KnownViolations) matches(image, code string) bool {
+	if code == "" || builtinCode(code) {
+		return false
+	}
+
+	for _, known := range k {
+		if known.Code != code {
+			continue
+		}
+		// Validated in ReadKnownViolations
+		if matched, _ := path.Match(known.Image, image); known.Image == image || matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+// builtinCode returns true if the code is of a built-in check, e.g.
+// builtin.image.signature_check.
+func builtinCode(code string) bool {
+	return strings.HasPrefix(code, "builtin.")
+}
+
+func ruleCode(r evaluator.Result) string {
+	code, _ := r.Metadata["code"].(string)
+
+	return code
+}
@@ -0,0 +1,166 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package applicationsnapshot
+
+import (
+	"testing"
+
+	app "github.com/konflux-ci/application-api/api/v1alpha1"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/evaluator"
+)
+
+func violation(code string) evaluator.Result {
+	return evaluator.Result{Message: code, Metadata: map[string]any{"code": code}}
+}
+
+func TestReadKnownViolations(t *testing.T) {
+	cases := []struct {
+		name     string
+		content  string
+		expected KnownViolations
+		err      string
+	}{
+		{
+			name: "valid",
+			content: `- image: registry.io/repository/*
+  code: pkg.rule
+- image: registry.io/other@sha256:abc
+  code: pkg.other
+`,
+			expected: KnownViolations{
+				{Image: "registry.io/repository/*", Code: "pkg.rule"},
+				{Image: "registry.io/other@sha256:abc", Code: "pkg.other"},
+			},
+		},
+		{
+			name:    "missing code",
+			content: `- image: registry.io/repository/*`,
+			err:     "known violation 1 in /known.yaml requires both the image and the code",
+		},
+		{
+			name:    "invalid glob",
+			content: `- {image: "registry.io/[", code: pkg.rule}`,
+			err:     `invalid image glob "registry.io/[" of known violation 1 in /known.yaml: syntax error in pattern`,
+		},
+		{
+			name:    "built-in check",
+			content: `- {image: "registry.io/repository/*", code: builtin.image.signature_check}`,
+			err:     "known violation 1 in /known.yaml is of the built-in check builtin.image.signature_check, which cannot be known",
+		},
+		{
+			name:    "invalid YAML",
+			content: `image: registry.io`,
+			err:     "unable to parse the known violations from /known.yaml",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			require.NoError(t, afero.WriteFile(fs, "/known.yaml", []byte(c.content), 0644))
+
+			known, err := ReadKnownViolations(fs, "/known.yaml")
+			if c.err != "" {
+				assert.ErrorContains(t, err, c.err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, c.expected, known)
+		})
+	}
+
+	_, err := ReadKnownViolations(afero.NewMemMapFs(), "/missing.yaml")
+	assert.ErrorContains(t, err, "unable to read the known violations")
+}
+
+func TestApplyKnownViolations(t *testing.T) {
+	components := []Component{
+		{
+			SnapshotComponent: app.SnapshotComponent{ContainerImage: "registry.io/repository/image@sha256:abc"},
+			Violations:        []evaluator.Result{violation("pkg.rule"), violation("pkg.new")},
+		},
+		{
+			SnapshotComponent: app.SnapshotComponent{ContainerImage: "registry.io/repository/other@sha256:def"},
+			Violations:        []evaluator.Result{violation("pkg.rule"), {Message: "no code"}},
+		},
+		{
+			SnapshotComponent: app.SnapshotComponent{ContainerImage: "registry.io/elsewhere/image@sha256:abc"},
+			Violations:        []evaluator.Result{violation("pkg.rule")},
+		},
+		{
+			SnapshotComponent: app.SnapshotComponent{ContainerImage: "registry.io/repository/unsigned@sha256:abc"},
+			Violations:        []evaluator.Result{violation("builtin.image.signature_check")},
+		},
+	}
+
+	known := KnownViolations{
+		{Image: "registry.io/repository/*", Code: "pkg.rule"},
+		{Image: "registry.io/repository/image@sha256:abc", Code: "pkg.new"},
+		{Image: "registry.io/repository/unsigned@sha256:abc", Code: "builtin.image.signature_check"},
+	}
+	known.Apply(components)
+
+	assert.Empty(t, components[0].Violations)
+	assert.Equal(t, []evaluator.Result{violation("pkg.rule"), violation("pkg.new")}, components[0].KnownViolations)
+	assert.True(t, components[0].Success)
+
+	assert.Equal(t, []evaluator.Result{{Message: "no code"}}, components[1].Violations)
+	assert.Equal(t, []evaluator.Result{violation("pkg.rule")}, components[1].KnownViolations)
+	assert.False(t, components[1].Success)
+
+	assert.Equal(t, []evaluator.Result{violation("pkg.rule")}, components[2].Violations)
+	assert.Empty(t, components[2].KnownViolations)
+	assert.False(t, components[2].Success)
+
+	assert.Equal(t, []evaluator.Result{violation("builtin.image.signature_check")}, components[3].Violations)
+	assert.Empty(t, components[3].KnownViolations)
+	assert.False(t, components[3].Success)
+}
+
+func TestNewKnownViolations(t *testing.T) {
+	components := []Component{
+		{
+			SnapshotComponent: app.SnapshotComponent{ContainerImage: "registry.io/repository/image@sha256:abc"},
+			Violations:        []evaluator.Result{violation("pkg.rule"), violation("pkg.rule"), {Message: "no code"}, violation("builtin.attestation.signature_check")},
+			KnownViolations:   []evaluator.Result{violation("pkg.known")},
+		},
+		{
+			SnapshotComponent: app.SnapshotComponent{ContainerImage: "registry.io/repository/aaa@sha256:def"},
+			Violations:        []evaluator.Result{violation("pkg.rule")},
+		},
+	}
+
+	known := NewKnownViolations(components)
+	assert.Equal(t, KnownViolations{
+		{Image: "registry.io/repository/aaa@sha256:def", Code: "pkg.rule"},
+		{Image: "registry.io/repository/image@sha256:abc", Code: "pkg.known"},
+		{Image: "registry.io/repository/image@sha256:abc", Code: "pkg.rule"},
+	}, known)
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, known.Write(fs, "/known.yaml"))
+	read, err := ReadKnownViolations(fs, "/known.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, known, read)
+}
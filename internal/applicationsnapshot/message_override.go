@@ -0,0 +1,123 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package applicationsnapshot
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"text/template"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"sigs.k8s.io/yaml"
+
+	"github.com/enterprise-contract/ec-cli/internal/evaluator"
+)
+
+// MessageOverrides replace the messages of the results of the rules, keyed by
+// the rule code, e.g. to localize or rephrase the messages for the readers of
+// the report. The rule codes are not changed.
+type MessageOverrides map[string]*template.Template
+
+// messageOverrideData is the data the message override templates are executed
+// with
+type messageOverrideData struct {
+	// Message is the original message of the result
+	Message string
+	// Code is the code of the rule
+	Code string
+	// Metadata is the metadata of the result, e.g. title or description
+	Metadata map[string]interface{}
+}
+
+// ReadMessageOverrides reads the message overrides from a YAML file mapping
+// rule codes to Go templates of the replacement messages. The templates have
+// access to the original message as {{ .Message }}, the rule code as
+// {{ .Code }} and the metadata of the result, e.g. {{ .Metadata.title }}.
+func ReadMessageOverrides(fs afero.Fs, file string) (MessageOverrides, error) {
+	content, err := afero.ReadFile(fs, file)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the message overrides: %w", err)
+	}
+
+	var messages map[string]string
+	if err := yaml.Unmarshal(content, &messages); err != nil {
+		return nil, fmt.Errorf("unable to parse the message overrides from %s: %w", file, err)
+	}
+
+	codes := make([]string, 0, len(messages))
+	for code := range messages {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	overrides := make(MessageOverrides, len(messages))
+	for _, code := range codes {
+		if code == "" {
+			return nil, fmt.Errorf("message override in %s without a rule code", file)
+		}
+
+		t, err := template.New(code).Option("missingkey=error").Parse(messages[code])
+		if err != nil {
+			return nil, fmt.Errorf("invalid message override of %s in %s: %w", code, file, err)
+		}
+		overrides[code] = t
+	}
+
+	return overrides, nil
+}
+
+// Apply replaces the messages of the violations, known violations, warnings
+// and successes of the components having an override for their rule code.
+// Results without an override, or failing to render their override, keep the
+// original message.
+func (m MessageOverrides) Apply(components []Component) {
+	if len(m) == 0 {
+		return
+	}
+
+	for i := range components {
+		c := &components[i]
+		m.overrideResults(c.Violations)
+		m.overrideResults(c.KnownViolations)
+		m.overrideResults(c.Warnings)
+		m.overrideResults(c.Successes)
+	}
+}
+
+func (m MessageOverrides) overrideResults(results []evaluator.Result) {
+	for i := range results {
+		code := ruleCode(results[i])
+		t, ok := m[code]
+		if !ok {
+			continue
+		}
+
+		var buf bytes.Buffer
+		if err := t.Execute(&buf, messageOverrideData{
+			Message:  results[i].Message,
+			Code:     code,
+			Metadata: results[i].Metadata,
+		}); err != nil {
+			log.Debugf("Unable to override the message of %s: %v", code, err)
+			continue
+		}
+
+		results[i].Message = buf.String()
+	}
+}
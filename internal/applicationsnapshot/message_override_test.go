@@ -0,0 +1,117 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package applicationsnapshot
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/evaluator"
+)
+
+func TestReadMessageOverrides(t *testing.T) {
+	cases := []struct {
+		name    string
+		content string
+		codes   []string
+		err     string
+	}{
+		{
+			name: "valid",
+			content: `pkg.rule: "Bitte beheben: {{ .Message }}"
+pkg.other: "{{ .Metadata.title }} ({{ .Code }})"
+`,
+			codes: []string{"pkg.other", "pkg.rule"},
+		},
+		{
+			name:    "invalid template",
+			content: `pkg.rule: "{{ .Message"`,
+			err:     "invalid message override of pkg.rule in /messages.yaml",
+		},
+		{
+			name:    "invalid YAML",
+			content: `- pkg.rule`,
+			err:     "unable to parse the message overrides from /messages.yaml",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			require.NoError(t, afero.WriteFile(fs, "/messages.yaml", []byte(c.content), 0644))
+
+			overrides, err := ReadMessageOverrides(fs, "/messages.yaml")
+			if c.err != "" {
+				assert.ErrorContains(t, err, c.err)
+				return
+			}
+			require.NoError(t, err)
+
+			codes := make([]string, 0, len(overrides))
+			for code := range overrides {
+				codes = append(codes, code)
+			}
+			assert.ElementsMatch(t, c.codes, codes)
+		})
+	}
+
+	_, err := ReadMessageOverrides(afero.NewMemMapFs(), "/missing.yaml")
+	assert.ErrorContains(t, err, "unable to read the message overrides")
+}
+
+func TestMessageOverridesApply(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/messages.yaml", []byte(`pkg.rule: "Bitte beheben: {{ .Message }}"
+pkg.other: "{{ .Metadata.title }} ({{ .Code }})"
+pkg.missing: "{{ .Metadata.nope }}"
+`), 0644))
+
+	overrides, err := ReadMessageOverrides(fs, "/messages.yaml")
+	require.NoError(t, err)
+
+	components := []Component{
+		{
+			Violations: []evaluator.Result{
+				{Message: "task missing", Metadata: map[string]any{"code": "pkg.rule"}},
+				{Message: "untouched", Metadata: map[string]any{"code": "pkg.unknown"}},
+				{Message: "no code"},
+			},
+			Warnings: []evaluator.Result{
+				{Message: "deprecated", Metadata: map[string]any{"code": "pkg.other", "title": "Veraltet"}},
+			},
+			Successes: []evaluator.Result{
+				{Message: "Pass", Metadata: map[string]any{"code": "pkg.missing"}},
+			},
+		},
+	}
+
+	overrides.Apply(components)
+
+	assert.Equal(t, []evaluator.Result{
+		{Message: "Bitte beheben: task missing", Metadata: map[string]any{"code": "pkg.rule"}},
+		{Message: "untouched", Metadata: map[string]any{"code": "pkg.unknown"}},
+		{Message: "no code"},
+	}, components[0].Violations)
+	assert.Equal(t, "Veraltet (pkg.other)", components[0].Warnings[0].Message)
+	assert.Equal(t, "pkg.other", components[0].Warnings[0].Metadata["code"])
+	assert.Equal(t, "Pass", components[0].Successes[0].Message, "failing to render keeps the original message")
+}
@@ -0,0 +1,35 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package applicationsnapshot
+
+import (
+	"github.com/enterprise-contract/ec-cli/internal/image"
+)
+
+// NormalizeReferences canonicalizes the references to the images of the
+// components, and to their base images, so that the outputs of validating the
+// same images referred to differently compare equal, see
+// image.NormalizeReference.
+func NormalizeReferences(components []Component) {
+	for i := range components {
+		c := &components[i]
+		c.ContainerImage = image.NormalizeReference(c.ContainerImage)
+		if c.BaseImage != "" {
+			c.BaseImage = image.NormalizeReference(c.BaseImage)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package applicationsnapshot
+
+import (
+	"testing"
+
+	app "github.com/konflux-ci/application-api/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeReferences(t *testing.T) {
+	components := []Component{
+		{SnapshotComponent: app.SnapshotComponent{ContainerImage: "docker.io/user/app:v1"}, BaseImage: "Registry.IO/base/ubi:9"},
+		{SnapshotComponent: app.SnapshotComponent{ContainerImage: "registry.io/ns/app:v2"}},
+	}
+
+	NormalizeReferences(components)
+
+	assert.Equal(t, "index.docker.io/user/app:v1", components[0].ContainerImage)
+	assert.Equal(t, "registry.io/base/ubi:9", components[0].BaseImage)
+	assert.Equal(t, "registry.io/ns/app:v2", components[1].ContainerImage)
+	assert.Empty(t, components[1].BaseImage)
+}
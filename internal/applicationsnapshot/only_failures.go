@@ -0,0 +1,65 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package applicationsnapshot
+
+// Totals counts the images of the validation by status, and their results.
+type Totals struct {
+	Images     int `json:"images"`
+	Passed     int `json:"passed"`
+	Failed     int `json:"failed"`
+	Errored    int `json:"errored"`
+	Skipped    int `json:"skipped,omitempty"`
+	Violations int `json:"violations"`
+	Warnings   int `json:"warnings"`
+	Successes  int `json:"successes"`
+}
+
+// OnlyFailures returns a copy of the report listing only the images that
+// failed the validation or could not be evaluated, e.g. to keep the payload
+// of alerts small. The Totals of the copy, and the counts of the results
+// derived from it, account for all the images of the report.
+func (r Report) OnlyFailures() Report {
+	t := r.toAppstudioReport()
+	totals := Totals{
+		Images:     len(r.Components),
+		Violations: t.Failures,
+		Warnings:   t.Warnings,
+		Successes:  t.Successes,
+	}
+
+	components := make([]Component, 0, len(r.Components))
+	for _, c := range r.Components {
+		switch c.Status {
+		case StatusPass:
+			totals.Passed++
+			continue
+		case StatusSkipped:
+			totals.Skipped++
+			continue
+		case StatusFail:
+			totals.Failed++
+		case StatusError:
+			totals.Errored++
+		}
+		components = append(components, c)
+	}
+
+	r.Components = components
+	r.Totals = &totals
+
+	return r
+}
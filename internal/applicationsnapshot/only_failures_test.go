@@ -0,0 +1,80 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package applicationsnapshot
+
+import (
+	"testing"
+
+	app "github.com/konflux-ci/application-api/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/enterprise-contract/ec-cli/internal/evaluator"
+)
+
+func TestOnlyFailures(t *testing.T) {
+	component := func(name string, status Status, violations, warnings int) Component {
+		c := Component{
+			SnapshotComponent: app.SnapshotComponent{Name: name, ContainerImage: "registry.io/" + name},
+			Success:           status == StatusPass || status == StatusSkipped,
+			Status:            status,
+		}
+		for i := 0; i < violations; i++ {
+			c.Violations = append(c.Violations, evaluator.Result{Message: "violation", Metadata: map[string]any{"code": "pkg.violation"}})
+		}
+		for i := 0; i < warnings; i++ {
+			c.Warnings = append(c.Warnings, evaluator.Result{Message: "warning", Metadata: map[string]any{"code": "pkg.warning"}})
+		}
+		return c
+	}
+
+	report := Report{
+		Success: false,
+		Components: []Component{
+			component("passing", StatusPass, 0, 2),
+			component("failing", StatusFail, 1, 1),
+			component("errored", StatusError, 1, 0),
+			component("skipped", StatusSkipped, 0, 0),
+		},
+	}
+
+	failures := report.OnlyFailures()
+
+	names := make([]string, 0, len(failures.Components))
+	for _, c := range failures.Components {
+		names = append(names, c.Name)
+	}
+	assert.Equal(t, []string{"failing", "errored"}, names)
+	assert.Len(t, report.Components, 4, "the report itself is not changed")
+	assert.Nil(t, report.Totals)
+
+	assert.Equal(t, &Totals{
+		Images:     4,
+		Passed:     1,
+		Failed:     1,
+		Errored:    1,
+		Skipped:    1,
+		Violations: 2,
+		Warnings:   3,
+	}, failures.Totals)
+
+	// The counts of the results account for all the images
+	appstudio := failures.toAppstudioReport()
+	assert.Equal(t, 2, appstudio.Failures)
+	assert.Equal(t, 3, appstudio.Warnings)
+}
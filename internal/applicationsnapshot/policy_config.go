@@ -0,0 +1,103 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package applicationsnapshot
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/enterprise-contract/ec-cli/internal/evaluator"
+)
+
+// DefaultPolicyConfig names the policy configuration given by --policy when
+// validating with multiple policy configurations.
+const DefaultPolicyConfig = "default"
+
+// PolicyConfigResult is the outcome of validating an image with one of the
+// policy configurations.
+type PolicyConfigResult struct {
+	Name       string `json:"name"`
+	Success    bool   `json:"success"`
+	Violations int    `json:"violations"`
+	Warnings   int    `json:"warnings"`
+}
+
+// ParsePolicyConfig parses the named policy configuration given as
+// name=<policy configuration>, returning the name and the policy
+// configuration.
+func ParsePolicyConfig(s string) (string, string, error) {
+	name, config, ok := strings.Cut(s, "=")
+	if !ok || name == "" || config == "" {
+		return "", "", fmt.Errorf("invalid policy configuration %q, expecting name=<policy configuration>", s)
+	}
+
+	if name == DefaultPolicyConfig {
+		return "", "", fmt.Errorf("the name %q is reserved for the policy configuration given by --policy", DefaultPolicyConfig)
+	}
+
+	return name, config, nil
+}
+
+// ApplyPolicyConfigs records the outcome of each of the named policy
+// configurations on the components. The violations and warnings of the
+// policies are attributed to the policy configuration named in their
+// metadata, others, e.g. of the built-in checks, to all of them. Unless all
+// policy configurations are required to pass, a component passes when any of
+// them passes.
+func ApplyPolicyConfigs(components []Component, names []string, requireAll bool) {
+	for i := range components {
+		c := &components[i]
+		if c.Skipped != "" {
+			continue
+		}
+
+		c.PolicyConfigs = make([]PolicyConfigResult, 0, len(names))
+		for _, name := range names {
+			r := PolicyConfigResult{
+				Name:       name,
+				Violations: countPolicyConfigResults(c.Violations, name),
+				Warnings:   countPolicyConfigResults(c.Warnings, name),
+			}
+			r.Success = r.Violations == 0 && !c.RateLimited
+			c.PolicyConfigs = append(c.PolicyConfigs, r)
+		}
+
+		if requireAll || c.RateLimited {
+			continue
+		}
+
+		c.Success = false
+		for _, r := range c.PolicyConfigs {
+			if r.Success {
+				c.Success = true
+				break
+			}
+		}
+	}
+}
+
+func countPolicyConfigResults(results []evaluator.Result, name string) int {
+	count := 0
+	for _, r := range results {
+		config, ok := r.Metadata[evaluator.PolicyConfigMetadataKey]
+		if !ok || config == name {
+			count++
+		}
+	}
+
+	return count
+}
@@ -0,0 +1,80 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package applicationsnapshot
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/evaluator"
+)
+
+func TestParsePolicyConfig(t *testing.T) {
+	name, config, err := ParsePolicyConfig("internal=github.com/org/policy//internal")
+	require.NoError(t, err)
+	assert.Equal(t, "internal", name)
+	assert.Equal(t, "github.com/org/policy//internal", config)
+
+	for _, s := range []string{"internal", "=policy.yaml", "internal="} {
+		_, _, err := ParsePolicyConfig(s)
+		assert.EqualError(t, err, `invalid policy configuration "`+s+`", expecting name=<policy configuration>`)
+	}
+
+	_, _, err = ParsePolicyConfig("default=policy.yaml")
+	assert.EqualError(t, err, `the name "default" is reserved for the policy configuration given by --policy`)
+}
+
+func TestApplyPolicyConfigs(t *testing.T) {
+	inConfig := func(name string) evaluator.Result {
+		return evaluator.Result{Message: name, Metadata: map[string]any{evaluator.PolicyConfigMetadataKey: name}}
+	}
+	names := []string{DefaultPolicyConfig, "internal"}
+
+	components := func() []Component {
+		return []Component{
+			{Violations: []evaluator.Result{inConfig("internal")}, Warnings: []evaluator.Result{inConfig("default")}},
+			{Violations: []evaluator.Result{{Message: "builtin"}}},
+			{Success: true},
+			{Success: true, Skipped: SkippedUnchanged},
+		}
+	}
+
+	required := components()
+	ApplyPolicyConfigs(required, names, true)
+	assert.Equal(t, []PolicyConfigResult{
+		{Name: "default", Success: true, Warnings: 1},
+		{Name: "internal", Violations: 1},
+	}, required[0].PolicyConfigs)
+	assert.Equal(t, []PolicyConfigResult{
+		{Name: "default", Violations: 1},
+		{Name: "internal", Violations: 1},
+	}, required[1].PolicyConfigs)
+	assert.Equal(t, []PolicyConfigResult{
+		{Name: "default", Success: true},
+		{Name: "internal", Success: true},
+	}, required[2].PolicyConfigs)
+	assert.Nil(t, required[3].PolicyConfigs)
+	assert.Equal(t, []bool{false, false, true, true}, []bool{required[0].Success, required[1].Success, required[2].Success, required[3].Success})
+
+	anyOf := components()
+	ApplyPolicyConfigs(anyOf, names, false)
+	assert.Equal(t, []bool{true, false, true, true}, []bool{anyOf[0].Success, anyOf[1].Success, anyOf[2].Success, anyOf[3].Success})
+}
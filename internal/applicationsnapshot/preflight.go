@@ -0,0 +1,97 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package applicationsnapshot
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	app "github.com/konflux-ci/application-api/api/v1alpha1"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/enterprise-contract/ec-cli/internal/utils/oci"
+)
+
+// preflightConcurrency is the number of images checked in parallel
+const preflightConcurrency = 5
+
+// PreflightError lists the images of the snapshot that are not accessible.
+type PreflightError struct {
+	Total        int
+	Inaccessible map[string]error
+	// order of the inaccessible images as in the snapshot
+	images []string
+}
+
+func (e PreflightError) Error() string {
+	msg := fmt.Sprintf("%d of %d images are not accessible:", len(e.Inaccessible), e.Total)
+	for _, image := range e.images {
+		msg += fmt.Sprintf("\n  %s: %s", image, e.Inaccessible[image])
+	}
+
+	return msg
+}
+
+// Preflight checks that the image of each component is accessible, fetching
+// the image descriptor, before any policy is evaluated. All images are
+// checked and a PreflightError lists all inaccessible images.
+func Preflight(ctx context.Context, components []app.SnapshotComponent) error {
+	client := oci.NewClient(ctx)
+	errs := make([]error, len(components))
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, preflightConcurrency)
+	for i, c := range components {
+		wg.Add(1)
+		go func(i int, image string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			ref, err := name.ParseReference(image)
+			if err != nil {
+				errs[i] = fmt.Errorf("unable to parse the image reference: %w", err)
+				return
+			}
+
+			if _, err := client.Head(ref); err != nil {
+				errs[i] = err
+			}
+		}(i, c.ContainerImage)
+	}
+	wg.Wait()
+
+	preflight := PreflightError{Total: len(components), Inaccessible: map[string]error{}}
+	for i, c := range components {
+		if errs[i] == nil {
+			log.Debugf("Image %s is accessible", c.ContainerImage)
+			continue
+		}
+		if _, ok := preflight.Inaccessible[c.ContainerImage]; !ok {
+			preflight.images = append(preflight.images, c.ContainerImage)
+		}
+		preflight.Inaccessible[c.ContainerImage] = errs[i]
+	}
+
+	if len(preflight.images) > 0 {
+		return preflight
+	}
+
+	return nil
+}
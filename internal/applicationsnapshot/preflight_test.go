@@ -0,0 +1,61 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package applicationsnapshot
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	app "github.com/konflux-ci/application-api/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/enterprise-contract/ec-cli/internal/utils/oci"
+	"github.com/enterprise-contract/ec-cli/internal/utils/oci/fake"
+)
+
+func TestPreflight(t *testing.T) {
+	client := fake.FakeClient{}
+	client.On("Head", mock.MatchedBy(func(ref name.Reference) bool {
+		return ref.String() == "registry.io/repository/unauthorized:tag"
+	})).Return(nil, errors.New("UNAUTHORIZED"))
+	client.On("Head", mock.Anything).Return(&v1.Descriptor{MediaType: types.OCIManifestSchema1}, nil)
+	ctx := oci.WithClient(context.Background(), &client)
+
+	assert.NoError(t, Preflight(ctx, []app.SnapshotComponent{
+		{ContainerImage: "registry.io/repository/image:tag"},
+	}))
+
+	err := Preflight(ctx, []app.SnapshotComponent{
+		{ContainerImage: "registry.io/repository/image:tag"},
+		{ContainerImage: "registry.io/repository/unauthorized:tag"},
+		{ContainerImage: "invalid reference"},
+	})
+	assert.EqualError(t, err, `2 of 3 images are not accessible:
+  registry.io/repository/unauthorized:tag: UNAUTHORIZED
+  invalid reference: unable to parse the image reference: could not parse reference: invalid reference`)
+
+	var preflight PreflightError
+	assert.ErrorAs(t, err, &preflight)
+	assert.Len(t, preflight.Inaccessible, 2)
+}
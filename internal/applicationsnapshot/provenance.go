@@ -0,0 +1,118 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package applicationsnapshot
+
+import (
+	"time"
+
+	"github.com/enterprise-contract/ec-cli/internal/policy"
+	"github.com/enterprise-contract/ec-cli/internal/policy/source"
+	"github.com/enterprise-contract/ec-cli/internal/version"
+)
+
+// Provenance records the inputs of a validation, making the report self
+// describing so that the validation can be reconstructed later on.
+type Provenance struct {
+	EcVersion     string           `json:"ec_version"`
+	EffectiveTime time.Time        `json:"effective_time"`
+	Policy        PolicyProvenance `json:"policy"`
+	Images        []string         `json:"images"`
+}
+
+// PolicyProvenance records the policy sources, at the revision used, and the
+// key material the signatures were verified with.
+type PolicyProvenance struct {
+	Sources   []SourceProvenance  `json:"sources"`
+	PublicKey string              `json:"public_key,omitempty"`
+	Identity  *IdentityProvenance `json:"identity,omitempty"`
+}
+
+// IdentityProvenance is the certificate identity used in the keyless workflow.
+type IdentityProvenance struct {
+	Issuer        string `json:"issuer,omitempty"`
+	IssuerRegExp  string `json:"issuer_regexp,omitempty"`
+	Subject       string `json:"subject,omitempty"`
+	SubjectRegExp string `json:"subject_regexp,omitempty"`
+}
+
+// SourceProvenance is a policy or data source and its resolved revision, i.e.
+// git commit SHA or OCI digest, when it could be determined, and the digest of
+// its content, see --expect-policy-digest.
+type SourceProvenance struct {
+	Name     string `json:"name,omitempty"`
+	Kind     string `json:"kind"`
+	Url      string `json:"url"`
+	Revision string `json:"revision,omitempty"`
+	Digest   string `json:"digest,omitempty"`
+}
+
+func newSourceProvenance(name, kind, url string) SourceProvenance {
+	return SourceProvenance{Name: name, Kind: kind, Url: url, Revision: source.Revision(url), Digest: source.ContentDigest(url)}
+}
+
+// NewProvenance creates the provenance of validating the given components
+// using the policy.
+func NewProvenance(components []Component, p policy.Policy) (*Provenance, error) {
+	info, _ := version.ComputeInfo()
+
+	prov := Provenance{
+		EcVersion:     info.Version,
+		EffectiveTime: p.EffectiveTime().UTC(),
+		Policy: PolicyProvenance{
+			Sources: []SourceProvenance{},
+		},
+		Images: make([]string, 0, len(components)),
+	}
+
+	for _, sourceGroup := range p.Spec().Sources {
+		for _, url := range sourceGroup.Policy {
+			prov.Policy.Sources = append(prov.Policy.Sources, newSourceProvenance(sourceGroup.Name, string(source.PolicyKind), url))
+		}
+		for _, url := range sourceGroup.Data {
+			prov.Policy.Sources = append(prov.Policy.Sources, newSourceProvenance(sourceGroup.Name, string(source.DataKind), url))
+		}
+	}
+
+	if p.Keyless() {
+		identity := p.Identity()
+		prov.Policy.Identity = &IdentityProvenance{
+			Issuer:        identity.Issuer,
+			IssuerRegExp:  identity.IssuerRegExp,
+			Subject:       identity.Subject,
+			SubjectRegExp: identity.SubjectRegExp,
+		}
+	} else {
+		key, err := p.PublicKeyPEM()
+		if err != nil {
+			return nil, err
+		}
+		prov.Policy.PublicKey = string(key)
+	}
+
+	// The images are recorded by the reference resolved to the digest they
+	// were validated by, the reference given is recorded when the image was
+	// not validated, e.g. when not accessible
+	for _, c := range components {
+		image := c.ImageURL
+		if image == "" {
+			image = c.ContainerImage
+		}
+		prov.Images = append(prov.Images, image)
+	}
+
+	return &prov, nil
+}
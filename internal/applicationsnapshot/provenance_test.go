@@ -0,0 +1,92 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package applicationsnapshot
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	ecc "github.com/enterprise-contract/enterprise-contract-controller/api/v1alpha1"
+	app "github.com/konflux-ci/application-api/api/v1alpha1"
+	"github.com/sigstore/cosign/v2/pkg/cosign"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/policy"
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+func TestNewProvenance(t *testing.T) {
+	ctx := context.Background()
+	utils.SetTestRekorPublicKey(t)
+	utils.SetTestFulcioRoots(t)
+	utils.SetTestCTLogPublicKey(t)
+
+	p, err := policy.NewPolicy(ctx, policy.Options{
+		EffectiveTime: "2024-01-01T00:00:00Z",
+		Identity:      cosign.Identity{Issuer: "my-issuer", SubjectRegExp: "^my-subject"},
+	})
+	require.NoError(t, err)
+
+	p = p.WithSpec(ecc.EnterpriseContractPolicySpec{
+		Sources: []ecc.Source{
+			{
+				Name:   "release",
+				Policy: []string{"github.com/org/policy//policy?ref=4f2f9a0b6b3c8d1e5a7f9c2b4d6e8f0a1b3c5d7e"},
+				Data:   []string{"quay.io/org/data@sha256:4a2c"},
+			},
+		},
+	})
+
+	components := []Component{
+		{SnapshotComponent: app.SnapshotComponent{ContainerImage: "registry.io/image@sha256:abc"}},
+		// the reference given is normalized, the resolved reference is recorded
+		{SnapshotComponent: app.SnapshotComponent{ContainerImage: "registry.io/other:latest@sha256:def"}, ImageURL: "registry.io/other@sha256:def"},
+	}
+
+	prov, err := NewProvenance(components, p)
+	require.NoError(t, err)
+
+	j, err := json.Marshal(prov)
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{
+		"ec_version": "development",
+		"effective_time": "2024-01-01T00:00:00Z",
+		"policy": {
+			"sources": [
+				{
+					"name": "release",
+					"kind": "policy",
+					"url": "github.com/org/policy//policy?ref=4f2f9a0b6b3c8d1e5a7f9c2b4d6e8f0a1b3c5d7e",
+					"revision": "4f2f9a0b6b3c8d1e5a7f9c2b4d6e8f0a1b3c5d7e"
+				},
+				{
+					"name": "release",
+					"kind": "data",
+					"url": "quay.io/org/data@sha256:4a2c",
+					"revision": "sha256:4a2c"
+				}
+			],
+			"identity": {"issuer": "my-issuer", "subject_regexp": "^my-subject"}
+		},
+		"images": ["registry.io/image@sha256:abc", "registry.io/other@sha256:def"]
+	}`, string(j))
+}
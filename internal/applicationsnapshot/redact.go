@@ -0,0 +1,80 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package applicationsnapshot
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/enterprise-contract/ec-cli/internal/evaluator"
+)
+
+// redactedReplacement replaces the redacted parts of the messages
+const redactedReplacement = "***"
+
+// Redactions are the patterns matching the sensitive parts, e.g. internal
+// hostnames, of the messages of the violations and warnings.
+type Redactions []*regexp.Regexp
+
+// ParseRedactions compiles the given regular expressions.
+func ParseRedactions(patterns []string) (Redactions, error) {
+	redactions := make(Redactions, 0, len(patterns))
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --redact value %q: %w", p, err)
+		}
+		redactions = append(redactions, re)
+	}
+
+	return redactions, nil
+}
+
+// Apply replaces the matches in the messages of the violations, known
+// violations and warnings of the components with "***". It returns the number
+// of redacted messages.
+func (r Redactions) Apply(components []Component) int {
+	if len(r) == 0 {
+		return 0
+	}
+
+	redacted := 0
+	for i := range components {
+		c := &components[i]
+		redacted += r.redactResults(c.Violations)
+		redacted += r.redactResults(c.KnownViolations)
+		redacted += r.redactResults(c.Warnings)
+	}
+
+	return redacted
+}
+
+func (r Redactions) redactResults(results []evaluator.Result) int {
+	redacted := 0
+	for i := range results {
+		message := results[i].Message
+		for _, re := range r {
+			message = re.ReplaceAllLiteralString(message, redactedReplacement)
+		}
+		if message != results[i].Message {
+			results[i].Message = message
+			redacted++
+		}
+	}
+
+	return redacted
+}
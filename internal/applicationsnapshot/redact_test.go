@@ -0,0 +1,69 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package applicationsnapshot
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/evaluator"
+)
+
+func TestParseRedactions(t *testing.T) {
+	r, err := ParseRedactions([]string{`[a-z]+\.corp\.example\.com`, `/srv/[^ ]+`})
+	require.NoError(t, err)
+	assert.Len(t, r, 2)
+
+	_, err = ParseRedactions([]string{`(`})
+	assert.ErrorContains(t, err, `invalid --redact value "("`)
+}
+
+func TestRedactionsApply(t *testing.T) {
+	r, err := ParseRedactions([]string{`[a-z]+\.corp\.example\.com`, `/srv/[^ ]+`})
+	require.NoError(t, err)
+
+	components := []Component{
+		{
+			Violations: []evaluator.Result{
+				{Message: "Pulled from build.corp.example.com and cache.corp.example.com"},
+				{Message: "Nothing to hide"},
+			},
+			KnownViolations: []evaluator.Result{
+				{Message: "Found /srv/builds/1 on disk"},
+			},
+			Warnings: []evaluator.Result{
+				{Message: "Mirror.corp.example.com at /srv/mirror"},
+			},
+			Successes: []evaluator.Result{
+				{Message: "Checked build.corp.example.com"},
+			},
+		},
+	}
+
+	assert.Equal(t, 3, r.Apply(components))
+	assert.Equal(t, "Pulled from *** and ***", components[0].Violations[0].Message)
+	assert.Equal(t, "Nothing to hide", components[0].Violations[1].Message)
+	assert.Equal(t, "Found *** on disk", components[0].KnownViolations[0].Message)
+	assert.Equal(t, "M*** at ***", components[0].Warnings[0].Message)
+	assert.Equal(t, "Checked build.corp.example.com", components[0].Successes[0].Message)
+
+	assert.Equal(t, 0, Redactions{}.Apply(components))
+}
@@ -20,19 +20,21 @@ import (
 	"bytes"
 	"embed"
 	"encoding/json"
-	"encoding/xml"
 	"fmt"
+	"sort"
 	"time"
 
 	ecc "github.com/enterprise-contract/enterprise-contract-controller/api/v1alpha1"
 	"github.com/hashicorp/go-multierror"
 	app "github.com/konflux-ci/application-api/api/v1alpha1"
-	"sigs.k8s.io/yaml"
+	log "github.com/sirupsen/logrus"
 
 	"github.com/enterprise-contract/ec-cli/internal/attestation"
 	"github.com/enterprise-contract/ec-cli/internal/evaluator"
 	"github.com/enterprise-contract/ec-cli/internal/format"
+	"github.com/enterprise-contract/ec-cli/internal/output"
 	"github.com/enterprise-contract/ec-cli/internal/policy"
+	"github.com/enterprise-contract/ec-cli/internal/policy/source"
 	"github.com/enterprise-contract/ec-cli/internal/signature"
 	"github.com/enterprise-contract/ec-cli/internal/utils"
 	"github.com/enterprise-contract/ec-cli/internal/version"
@@ -40,17 +42,50 @@ import (
 
 type Component struct {
 	app.SnapshotComponent
-	Violations   []evaluator.Result          `json:"violations,omitempty"`
-	Warnings     []evaluator.Result          `json:"warnings,omitempty"`
-	Successes    []evaluator.Result          `json:"successes,omitempty"`
-	Success      bool                        `json:"success"`
-	SuccessCount int                         `json:"-"`
-	Signatures   []signature.EntitySignature `json:"signatures,omitempty"`
-	Attestations []attestation.Attestation   `json:"attestations,omitempty"`
+	Violations           []evaluator.Result           `json:"violations,omitempty"`
+	KnownViolations      []evaluator.Result           `json:"knownViolations,omitempty"`
+	Warnings             []evaluator.Result           `json:"warnings,omitempty"`
+	Successes            []evaluator.Result           `json:"successes,omitempty"`
+	Success              bool                         `json:"success"`
+	Status               Status                       `json:"status"`
+	SuccessCount         int                          `json:"-"`
+	Signatures           []signature.EntitySignature  `json:"signatures,omitempty"`
+	Attestations         []attestation.Attestation    `json:"attestations,omitempty"`
+	Verification         *output.Verification         `json:"verification,omitempty"`
+	SLSALevel            *int                         `json:"slsaLevel,omitempty"`
+	BaseImage            string                       `json:"baseImage,omitempty"`
+	BuilderIDs           []string                     `json:"builderIds,omitempty"`
+	RekorIntegratedTime  *time.Time                   `json:"rekorIntegratedTime,omitempty"`
+	BuildFinishedOn      *time.Time                   `json:"buildFinishedOn,omitempty"`
+	SigningKeys          []signature.SigningKey       `json:"signingKeys,omitempty"`
+	SignerIdentities     []signature.Identity         `json:"signerIdentities,omitempty"`
+	SigningTimes         []signature.SigningTime      `json:"signingTimes,omitempty"`
+	MissingLabels        []string                     `json:"missingLabels,omitempty"`
+	DigestPin            *output.DigestPin            `json:"digestPin,omitempty"`
+	AttestationConflicts []output.AttestationConflict `json:"attestationConflicts,omitempty"`
+	SubjectDigests       []string                     `json:"subjectDigests,omitempty"`
+	ImageCreated         *time.Time                   `json:"imageCreated,omitempty"`
+	// PolicyInputSize is the size in bytes of the policy input
+	PolicyInputSize *int64 `json:"policyInputSize,omitempty"`
+	// OperatorDecision is set if the operator denied or allowed the image
+	// digest regardless of the policy, see output.OperatorDenied and
+	// output.OperatorAllowed
+	OperatorDecision string `json:"operatorDecision,omitempty"`
+	// AttestationsPresent is set when attestations are optional, telling if
+	// the image has any attestations
+	AttestationsPresent *bool                `json:"attestationsPresent,omitempty"`
+	PolicyConfigs       []PolicyConfigResult `json:"policyConfigs,omitempty"`
+	RateLimited         bool                 `json:"rateLimited,omitempty"`
+	// Skipped is the reason for not validating the image, e.g. SkippedUnchanged
+	Skipped string `json:"skipped,omitempty"`
+	// ImageURL is the reference the image was validated by, resolved to the
+	// image digest, see output.Output.ImageURL
+	ImageURL string `json:"-"`
 }
 
 type Report struct {
-	Success       bool `json:"success"`
+	Provenance    *Provenance `json:"provenance,omitempty"`
+	Success       bool        `json:"success"`
 	created       time.Time
 	Snapshot      string                           `json:"snapshot,omitempty"`
 	Components    []Component                      `json:"components"`
@@ -61,6 +96,40 @@ type Report struct {
 	EffectiveTime time.Time                        `json:"effective-time"`
 	PolicyInput   [][]byte                         `json:"-"`
 	ShowSuccesses bool                             `json:"-"`
+	// SignatureCoverage is set only when reporting on unsigned images
+	SignatureCoverage *SignatureCoverage `json:"signatureCoverage,omitempty"`
+	// RateLimited is the number of images that could not be validated because
+	// the registry rate limited the requests
+	RateLimited int `json:"rateLimited,omitempty"`
+	// Skipped lists the images that were not validated with the reason, see
+	// Component.Skipped
+	Skipped []SkippedImage `json:"skipped,omitempty"`
+	// SkippedSources lists the policy sources that could not be fetched and
+	// were excluded from the evaluation, see --missing-source
+	SkippedSources []source.SkippedSource `json:"skippedSources,omitempty"`
+	// Errors is the number of images that could not be evaluated, see
+	// StatusError
+	Errors int `json:"errors,omitempty"`
+	// FailOn is the severity gate that decided the exit code of the validation
+	FailOn string `json:"failOn,omitempty"`
+	// FailOnSeverity is the severity threshold that decided the exit code of
+	// the validation, if given instead of FailOn
+	FailOnSeverity string `json:"failOnSeverity,omitempty"`
+	// Redacted is the number of violation and warning messages redacted, see
+	// Redactions
+	Redacted int `json:"redacted,omitempty"`
+	// RetryBudgetExhausted is true if requests were not retried because the
+	// retry budget of the run was spent
+	RetryBudgetExhausted bool `json:"retryBudgetExhausted,omitempty"`
+	// OutputSigner, if set, signs the JSON outputs written to files, see
+	// WriteAll
+	OutputSigner *OutputSigner `json:"-"`
+	// OutputChecksums, if set, writes the checksum of each output written to
+	// a file next to it, see WriteAll
+	OutputChecksums bool `json:"-"`
+	// Totals is set when only some of the images are listed, counting all the
+	// images, see OnlyFailures
+	Totals *Totals `json:"totals,omitempty"`
 }
 
 type summary struct {
@@ -68,11 +137,29 @@ type summary struct {
 	Components []componentSummary `json:"components"`
 	Success    bool               `json:"success"`
 	Key        string             `json:"key"`
+	// WarningsSummary groups the warnings of all components by rule code
+	WarningsSummary []warningSummary `json:"warnings_summary,omitempty"`
+	// Redacted is the number of redacted messages, see Report.Redacted
+	Redacted int `json:"redacted,omitempty"`
+}
+
+// maxWarningSampleImages is the number of affected images listed for each
+// rule code in the warnings summary
+const maxWarningSampleImages = 3
+
+// warningSummary is a distinct warning, by rule code, with the number of
+// images it affects and a sample of those images.
+type warningSummary struct {
+	Code           string   `json:"code"`
+	Message        string   `json:"message"`
+	AffectedImages int      `json:"affected_images"`
+	SampleImages   []string `json:"sample_images"`
 }
 
 type componentSummary struct {
 	Name            string              `json:"name"`
 	Success         bool                `json:"success"`
+	Status          Status              `json:"status"`
 	Violations      map[string][]string `json:"violations"`
 	Warnings        map[string][]string `json:"warnings"`
 	Successes       map[string][]string `json:"successes"`
@@ -91,8 +178,10 @@ type TestReport struct {
 	Successes int    `json:"successes"`
 	Failures  int    `json:"failures"`
 	Warnings  int    `json:"warnings"`
-	Result    string `json:"result"`
-	Note      string `json:"note,omitempty"`
+	// Errors is the number of images that could not be evaluated
+	Errors int    `json:"errors,omitempty"`
+	Result string `json:"result"`
+	Note   string `json:"note,omitempty"`
 }
 
 // Possible formats the report can be written as.
@@ -108,6 +197,11 @@ const (
 	Attestation     = "attestation"
 	PolicyInput     = "policy-input"
 	VSA             = "vsa"
+	CycloneDX       = "cyclonedx"
+	SPDX            = "spdx"
+	CSV             = "csv"
+	// None produces no output, only the exit code of the command is relevant.
+	None = "none"
 	// Deprecated old version of appstudio. Remove some day.
 	HACBS = "hacbs"
 )
@@ -124,21 +218,38 @@ var OutputFormats = []string{
 	Attestation,
 	PolicyInput,
 	VSA,
+	CycloneDX,
+	SPDX,
+	CSV,
+	None,
 }
 
 // WriteReport returns a new instance of Report representing the state of
 // components from the snapshot.
 func NewReport(snapshot string, components []Component, policy policy.Policy, data any, policyInput [][]byte, showSuccesses bool) (Report, error) {
 	success := true
+	rateLimited := 0
+	errored := 0
 
 	// Set the report success, remains true if all components are successful
-	for _, component := range components {
+	for i := range components {
+		component := &components[i]
 		if !component.Success {
 			success = false
-			break
+		}
+		if component.RateLimited {
+			rateLimited++
+		}
+		component.Status = component.status()
+		if component.Status == StatusError {
+			errored++
 		}
 	}
 
+	if rateLimited > 0 {
+		log.Warnf("%d of %d images could not be validated because the registry rate limited the requests, rerun the validation", rateLimited, len(components))
+	}
+
 	key, err := policy.PublicKeyPEM()
 	if err != nil {
 		return Report{}, err
@@ -160,68 +271,142 @@ func NewReport(snapshot string, components []Component, policy policy.Policy, da
 		PolicyInput:   policyInput,
 		EffectiveTime: policy.EffectiveTime().UTC(),
 		ShowSuccesses: showSuccesses,
+		RateLimited:   rateLimited,
+		Skipped:       skippedImages(components),
+		Errors:        errored,
 	}, nil
 }
 
-// WriteAll writes the report to all the given targets.
+// WriteAll writes the report to all the given targets. The report is
+// converted only once into each format, and written to every destination of
+// that format. With an OutputSigner, the DSSE envelope signing each JSON
+// output written to a file is written next to it, see OutputSignatureSuffix.
+// With OutputChecksums, the checksum of each output written to a file is
+// written next to it, see OutputChecksumSuffix.
 func (r Report) WriteAll(targets []string, p format.TargetParser) (allErrors error) {
 	if len(targets) == 0 {
 		targets = append(targets, JSON)
 	}
-	for _, targetName := range targets {
-		target, err := p.Parse(targetName)
-		if err != nil {
-			allErrors = multierror.Append(allErrors, err)
+
+	parsed, err := p.ParseAll(targets)
+	if err != nil {
+		allErrors = multierror.Append(allErrors, err)
+	}
+
+	type conversion struct {
+		format  string
+		options format.Options
+	}
+	converted := map[conversion][]byte{}
+	for _, target := range parsed {
+		if target.Format == None {
 			continue
 		}
-		r.applyOptions(target.Options)
 
-		data, err := r.toFormat(target.Format)
-		if err != nil {
+		// Written to a directory, the SBOM of each image is written to a file
+		// of its own
+		if predicateType, ok := sbomFormats[target.Format]; ok {
+			if _, ok := target.Within(""); ok {
+				if err := r.writeSBOMs(target, predicateType); err != nil {
+					allErrors = multierror.Append(allErrors, err)
+				}
+				continue
+			}
+		}
+
+		c := conversion{target.Format, target.Options}
+		data, ok := converted[c]
+		if !ok {
+			r.applyOptions(target.Options)
+
+			data, err = r.toFormat(target.Format)
+			if err != nil {
+				allErrors = multierror.Append(allErrors, err)
+				continue
+			}
+
+			if !bytes.HasSuffix(data, []byte{'\n'}) {
+				data = append(data, "\n"...)
+			}
+			converted[c] = data
+		}
+
+		write := target.Write
+		if jsonFormats[target.Format] {
+			write = target.WriteJSON
+		}
+		if _, err := write(data); err != nil {
 			allErrors = multierror.Append(allErrors, err)
 			continue
 		}
 
-		if !bytes.HasSuffix(data, []byte{'\n'}) {
-			data = append(data, "\n"...)
+		if r.OutputSigner != nil && jsonFormats[target.Format] {
+			if err := r.signOutput(target, data); err != nil {
+				allErrors = multierror.Append(allErrors, err)
+			}
 		}
 
-		if _, err := target.Write(data); err != nil {
-			allErrors = multierror.Append(allErrors, err)
+		if r.OutputChecksums {
+			if err := writeChecksum(target); err != nil {
+				allErrors = multierror.Append(allErrors, err)
+			}
 		}
 	}
 	return
 }
 
+// OutputChecksumSuffix is appended to the name of the output file to name the
+// file holding its checksum
+const OutputChecksumSuffix = ".sha256"
+
+// writeChecksum writes the checksum of the output, over the bytes written to
+// the file the target is written to, next to it in the format of sha256sum.
+func writeChecksum(target *format.Target) error {
+	checksum, ok := target.Checksum()
+	if !ok {
+		log.Debugf("No checksum of the %s output, only outputs written to a file, and not appended to it, or uploaded to a bucket have a checksum", target.Format)
+		return nil
+	}
+
+	w, _ := target.Alongside(OutputChecksumSuffix)
+	if _, err := w.Write([]byte(checksum)); err != nil {
+		return fmt.Errorf("unable to write the checksum of the %s output: %w", target.Format, err)
+	}
+
+	return nil
+}
+
+// signOutput writes the signature of the output next to the file the target
+// is written to.
+func (r Report) signOutput(target *format.Target, data []byte) error {
+	w, ok := target.Alongside(OutputSignatureSuffix)
+	if !ok {
+		log.Warnf("The %s output is not signed, only outputs written to a file, and not appended to it, or uploaded to a bucket are signed", target.Format)
+		return nil
+	}
+
+	envelope, err := r.OutputSigner.Sign(data)
+	if err != nil {
+		return fmt.Errorf("unable to sign the %s output: %w", target.Format, err)
+	}
+
+	_, err = w.Write(append(envelope, '\n'))
+	return err
+}
+
 // toFormat converts the report into the given format.
-func (r *Report) toFormat(format string) (data []byte, err error) {
-	switch format {
-	case JSON:
-		data, err = json.Marshal(r)
-	case YAML:
-		data, err = yaml.Marshal(r)
-	case Text:
-		data, err = generateTextReport(r)
-	case AppStudio, HACBS:
-		data, err = json.Marshal(r.toAppstudioReport())
-	case Summary:
-		data, err = json.Marshal(r.toSummary())
-	case SummaryMarkdown:
-		data, err = generateMarkdownSummary(r)
-	case JUnit:
-		data, err = xml.Marshal(r.toJUnit())
-	case Data:
-		data, err = yaml.Marshal(r.Data)
-	case Attestation:
-		data, err = r.renderAttestations()
-	case PolicyInput:
-		data = bytes.Join(r.PolicyInput, []byte("\n"))
-	case VSA:
-		data, err = r.toVSA()
-	default:
+func (r *Report) toFormat(format string) ([]byte, error) {
+	f, ok := formatter(format)
+	if !ok {
 		return nil, fmt.Errorf("%q is not a valid report format", format)
 	}
-	return
+
+	var buf bytes.Buffer
+	if err := f.Format(&buf, r); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
 }
 
 func (r *Report) toVSA() ([]byte, error) {
@@ -250,6 +435,7 @@ func (r *Report) toSummary() summary {
 			TotalSuccesses: cmp.SuccessCount,
 
 			Success:    cmp.Success,
+			Status:     cmp.Status,
 			Name:       cmp.Name,
 			Violations: condensedMsg(cmp.Violations),
 			Warnings:   condensedMsg(cmp.Warnings),
@@ -258,9 +444,59 @@ func (r *Report) toSummary() summary {
 		pr.Components = append(pr.Components, c)
 	}
 	pr.Key = r.Key
+	pr.WarningsSummary = warningsSummary(r.Components)
+	pr.Redacted = r.Redacted
 	return pr
 }
 
+// warningsSummary groups the warnings of the components by rule code, the
+// warnings affecting the most images first. Warnings without a code are not
+// grouped.
+func warningsSummary(components []Component) []warningSummary {
+	byCode := map[string]*warningSummary{}
+	affected := map[string]map[string]bool{}
+	for _, c := range components {
+		for _, w := range c.Warnings {
+			code := ruleCode(w)
+			if code == "" {
+				continue
+			}
+
+			s, ok := byCode[code]
+			if !ok {
+				s = &warningSummary{Code: code, Message: w.Message, SampleImages: []string{}}
+				byCode[code] = s
+				affected[code] = map[string]bool{}
+			}
+			if affected[code][c.ContainerImage] {
+				continue
+			}
+			affected[code][c.ContainerImage] = true
+			s.AffectedImages++
+			if len(s.SampleImages) < maxWarningSampleImages {
+				s.SampleImages = append(s.SampleImages, c.ContainerImage)
+			}
+		}
+	}
+
+	if len(byCode) == 0 {
+		return nil
+	}
+
+	warnings := make([]warningSummary, 0, len(byCode))
+	for _, s := range byCode {
+		warnings = append(warnings, *s)
+	}
+	sort.Slice(warnings, func(i, j int) bool {
+		if warnings[i].AffectedImages != warnings[j].AffectedImages {
+			return warnings[i].AffectedImages > warnings[j].AffectedImages
+		}
+		return warnings[i].Code < warnings[j].Code
+	})
+
+	return warnings
+}
+
 func (r *Report) applyOptions(opts format.Options) {
 	r.ShowSuccesses = opts.ShowSuccesses
 }
@@ -313,6 +549,9 @@ func generateMarkdownSummary(r *Report) ([]byte, error) {
 	writeMarkdownField(&markdownBuffer, "Successes", totalSuccesses, writeIcon(totalSuccesses >= 1 && totalViolations == 0))
 	writeMarkdownField(&markdownBuffer, "Failures", totalViolations, writeIcon(totalViolations == 0))
 	writeMarkdownField(&markdownBuffer, "Warnings", totalWarnings, writeIcon(totalWarnings == 0))
+	if r.Errors > 0 {
+		writeMarkdownField(&markdownBuffer, "Errors", r.Errors, writeIcon(false))
+	}
 	writeMarkdownField(&markdownBuffer, "Result", "", writeIcon(r.Success))
 	return markdownBuffer.Bytes(), nil
 }
@@ -365,12 +604,23 @@ func (r *Report) toAppstudioReport() TestReport {
 		}
 	}
 
+	// Not all the images are listed, the totals account for them
+	if r.Totals != nil {
+		result.Failures = r.Totals.Violations
+		result.Warnings = r.Totals.Warnings
+		result.Successes = r.Totals.Successes
+	}
+
+	result.Errors = r.Errors
+
 	result.DeriveResult(hasFailures)
 	return result
 }
 
 func (r *TestReport) DeriveResult(hasFailures bool) {
 	switch {
+	case r.Errors > 0:
+		r.Result = "ERROR"
 	case r.Failures > 0 || hasFailures:
 		r.Result = "FAILURE"
 	case r.Warnings > 0:
@@ -387,6 +637,8 @@ func (r *TestReport) DeriveResult(hasFailures bool) {
 // e.g. by including an abbreviated list of failure or warning messages.
 func (r *TestReport) DeriveNote() {
 	switch {
+	case r.Result == "ERROR":
+		r.Note = "Errors detected"
 	case r.Result == "FAILURE":
 		r.Note = "Failures detected"
 	case r.Result == "WARNING":
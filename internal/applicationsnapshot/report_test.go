@@ -20,10 +20,13 @@ package applicationsnapshot
 
 import (
 	"bufio"
+	"bytes"
 	"context"
+	"crypto/sha256"
 	_ "embed"
 	"encoding/json"
 	"fmt"
+	"path"
 	"testing"
 	"time"
 
@@ -71,21 +74,24 @@ func Test_ReportJson(t *testing.T) {
           "violations": [{"msg": "violation1"}],
           "warnings": [{"msg": "warning1"}],
 		  "successes": [{"msg": "success1"}],
-          "success": false
+          "success": false,
+          "status": "fail"
         },
         {
           "name": "bacon",
           "containerImage": "quay.io/caf/bacon@sha256:234…",
 		  "source": {},
           "violations": [{"msg": "violation2"}],
-          "success": false
+          "success": false,
+          "status": "fail"
         },
         {
 			"name": "eggs",
 			"containerImage": "quay.io/caf/eggs@sha256:345…",
 			"source": {},
 			"successes": [{"msg": "success3"}],
-			"success": true
+			"success": true,
+			"status": "pass"
         }
       ],
 	  "policy": {
@@ -131,18 +137,21 @@ components:
     successes:
       - msg: success1
     success: false
+    status: fail
   - name: bacon
     containerImage: quay.io/caf/bacon@sha256:234…
     source: {}
     violations:
       - msg: violation2
     success: false
+    status: fail
   - name: eggs
     containerImage: quay.io/caf/eggs@sha256:345…
     source: {}
     successes:
       - msg: success3
     success: true
+    status: pass
 policy:
   publicKey: %s
 `, testEffectiveTime, utils.TestPublicKeyJSON, utils.TestPublicKeyJSON)
@@ -309,11 +318,15 @@ func Test_ReportSummary(t *testing.T) {
 						TotalSuccesses:  0,
 						TotalWarnings:   1,
 						Success:         false,
+						Status:          StatusFail,
 						Name:            "",
 					},
 				},
 				Success: false,
 				Key:     utils.TestPublicKey,
+				WarningsSummary: []warningSummary{
+					{Code: "short_name", Message: "short report", AffectedImages: 1, SampleImages: []string{""}},
+				},
 			},
 		},
 		{
@@ -340,6 +353,7 @@ func Test_ReportSummary(t *testing.T) {
 						TotalViolations: 1,
 						TotalWarnings:   1,
 						Success:         false,
+						Status:          StatusFail,
 						TotalSuccesses:  0,
 						Name:            "",
 					},
@@ -394,12 +408,16 @@ func Test_ReportSummary(t *testing.T) {
 						TotalViolations: 2,
 						TotalWarnings:   2,
 						Success:         false,
+						Status:          StatusFail,
 						TotalSuccesses:  0,
 						Name:            "",
 					},
 				},
 				Success: false,
 				Key:     utils.TestPublicKey,
+				WarningsSummary: []warningSummary{
+					{Code: "short_name", Message: "short report", AffectedImages: 1, SampleImages: []string{""}},
+				},
 			},
 		},
 		{
@@ -442,11 +460,15 @@ func Test_ReportSummary(t *testing.T) {
 						TotalWarnings:   1,
 						TotalSuccesses:  1,
 						Success:         false,
+						Status:          StatusFail,
 						Name:            "",
 					},
 				},
 				Success: false,
 				Key:     utils.TestPublicKey,
+				WarningsSummary: []warningSummary{
+					{Code: "warning", Message: "warning", AffectedImages: 1, SampleImages: []string{""}},
+				},
 			},
 		},
 		{
@@ -491,11 +513,15 @@ func Test_ReportSummary(t *testing.T) {
 						TotalWarnings:   1,
 						TotalSuccesses:  1,
 						Success:         false,
+						Status:          StatusFail,
 						Name:            "",
 					},
 				},
 				Success: false,
 				Key:     utils.TestPublicKey,
+				WarningsSummary: []warningSummary{
+					{Code: "warning", Message: "warning", AffectedImages: 1, SampleImages: []string{""}},
+				},
 			},
 		},
 	}
@@ -510,6 +536,28 @@ func Test_ReportSummary(t *testing.T) {
 	}
 }
 
+func TestWarningsSummary(t *testing.T) {
+	warning := func(code, message string) evaluator.Result {
+		return evaluator.Result{Message: message, Metadata: map[string]any{"code": code}}
+	}
+
+	components := []Component{
+		{SnapshotComponent: app.SnapshotComponent{ContainerImage: "registry.io/a"}, Warnings: []evaluator.Result{warning("pkg.common", "Common in a"), warning("pkg.common", "Common again in a"), warning("pkg.rare", "Rare")}},
+		{SnapshotComponent: app.SnapshotComponent{ContainerImage: "registry.io/b"}, Warnings: []evaluator.Result{warning("pkg.common", "Common in b"), {Message: "no code"}}},
+		{SnapshotComponent: app.SnapshotComponent{ContainerImage: "registry.io/c"}, Warnings: []evaluator.Result{warning("pkg.common", "Common in c")}},
+		{SnapshotComponent: app.SnapshotComponent{ContainerImage: "registry.io/d"}, Warnings: []evaluator.Result{warning("pkg.common", "Common in d"), warning("pkg.other", "Other")}},
+		{SnapshotComponent: app.SnapshotComponent{ContainerImage: "registry.io/e"}},
+	}
+
+	assert.Equal(t, []warningSummary{
+		{Code: "pkg.common", Message: "Common in a", AffectedImages: 4, SampleImages: []string{"registry.io/a", "registry.io/b", "registry.io/c"}},
+		{Code: "pkg.other", Message: "Other", AffectedImages: 1, SampleImages: []string{"registry.io/d"}},
+		{Code: "pkg.rare", Message: "Rare", AffectedImages: 1, SampleImages: []string{"registry.io/a"}},
+	}, warningsSummary(components))
+
+	assert.Nil(t, warningsSummary(components[4:]))
+}
+
 func Test_ReportAppstudio(t *testing.T) {
 	cases := []struct {
 		name       string
@@ -830,6 +878,45 @@ func Test_ReportPolicyInput(t *testing.T) {
 	matchesJSONLFile(t, fs, policyInput, "default")
 }
 
+func Test_ReportNone(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	var defaultWriter bytes.Buffer
+
+	ctx := context.Background()
+	report, err := NewReport("snapshot", nil, createTestPolicy(t, ctx), nil, nil, true)
+	require.NoError(t, err)
+
+	p := format.NewTargetParser(JSON, format.Options{}, &defaultWriter, fs)
+	require.NoError(t, report.WriteAll([]string{"none", "none=report.json"}, p))
+
+	assert.Empty(t, defaultWriter.String())
+	exists, err := afero.Exists(fs, "report.json")
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func Test_ReportSameFormatMultipleDestinations(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	var defaultWriter bytes.Buffer
+
+	ctx := context.Background()
+	report, err := NewReport("snapshot", nil, createTestPolicy(t, ctx), nil, nil, false)
+	require.NoError(t, err)
+
+	p := format.NewTargetParser(JSON, format.Options{}, &defaultWriter, fs)
+	require.NoError(t, report.WriteAll([]string{"json", "json=archive.json", "json=copy.json", "json"}, p))
+
+	stdout := defaultWriter.String()
+	// written only once to the default writer
+	assert.Equal(t, 1, bytes.Count([]byte(stdout), []byte("\n")))
+
+	for _, f := range []string{"archive.json", "copy.json"} {
+		content, err := afero.ReadFile(fs, f)
+		require.NoError(t, err)
+		assert.Equal(t, stdout, string(content))
+	}
+}
+
 func Test_TextReport(t *testing.T) {
 	warnings := []evaluator.Result{
 		{
@@ -845,7 +932,8 @@ func Test_TextReport(t *testing.T) {
 			Metadata: map[string]interface{}{
 				"code": "warning-2",
 			},
-			Message: "Warning 2 message",
+			Message:  "Warning 2 message",
+			Severity: evaluator.SeverityMedium,
 		},
 	}
 	violations := []evaluator.Result{
@@ -862,7 +950,8 @@ func Test_TextReport(t *testing.T) {
 			Metadata: map[string]interface{}{
 				"code": "violation-2",
 			},
-			Message: "Violation 2 message",
+			Message:  "Violation 2 message",
+			Severity: evaluator.SeverityCritical,
 		},
 	}
 	successes := []evaluator.Result{
@@ -998,3 +1087,28 @@ func createTestPolicy(t *testing.T, ctx context.Context) policy.Policy {
 	assert.NoError(t, err)
 	return p
 }
+
+func TestReportWriteAllChecksums(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	var defaultWriter bytes.Buffer
+
+	ctx := context.Background()
+	report, err := NewReport("snapshot", nil, createTestPolicy(t, ctx), nil, nil, false)
+	require.NoError(t, err)
+	report.OutputChecksums = true
+
+	p := format.NewTargetParser(JSON, format.Options{}, &defaultWriter, fs)
+	require.NoError(t, report.WriteAll([]string{"json", "json=report.json", "yaml=out/report.yaml", "json+=reports.jsonl"}, p))
+
+	for _, f := range []string{"report.json", "out/report.yaml"} {
+		content, err := afero.ReadFile(fs, f)
+		require.NoError(t, err)
+		checksum, err := afero.ReadFile(fs, f+OutputChecksumSuffix)
+		require.NoError(t, err)
+		assert.Equal(t, fmt.Sprintf("%x  %s\n", sha256.Sum256(content), path.Base(f)), string(checksum))
+	}
+
+	exists, err := afero.Exists(fs, "reports.jsonl"+OutputChecksumSuffix)
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
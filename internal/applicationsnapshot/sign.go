@@ -0,0 +1,69 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package applicationsnapshot
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+
+	jsoncanonicalizer "github.com/cyberphone/json-canonicalization/go/src/webpki.org/jsoncanonicalizer"
+	cosignSig "github.com/sigstore/cosign/v2/pkg/signature"
+	"github.com/sigstore/sigstore/pkg/signature"
+	"github.com/sigstore/sigstore/pkg/signature/dsse"
+)
+
+const (
+	// OutputPayloadType is the DSSE payload type of the signed outputs
+	OutputPayloadType = "application/vnd.enterprisecontract.output+json"
+	// OutputSignatureSuffix is appended to the name of the output file to name
+	// the file holding its signature
+	OutputSignatureSuffix = ".sig"
+)
+
+// OutputSigner signs the JSON outputs of the report so that the verdict can
+// be verified not to have been altered.
+type OutputSigner struct {
+	signer signature.Signer
+}
+
+// NewOutputSigner creates a signer with the private key, given as a file
+// path, a KMS URI, or a Kubernetes secret reference as accepted by cosign.
+// The password of the key is read from the COSIGN_PASSWORD environment
+// variable.
+func NewOutputSigner(ctx context.Context, keyRef string) (*OutputSigner, error) {
+	s, err := cosignSig.SignerFromKeyRef(ctx, keyRef, func(bool) ([]byte, error) {
+		return []byte(os.Getenv("COSIGN_PASSWORD")), nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to load the output signing key: %w", err)
+	}
+
+	return &OutputSigner{signer: dsse.WrapSigner(s, OutputPayloadType)}, nil
+}
+
+// Sign canonicalizes the JSON output, see RFC 8785, and returns the DSSE
+// envelope with the signature of the canonical output as its payload.
+func (s *OutputSigner) Sign(output []byte) ([]byte, error) {
+	canonical, err := jsoncanonicalizer.Transform(output)
+	if err != nil {
+		return nil, fmt.Errorf("unable to canonicalize the output: %w", err)
+	}
+
+	return s.signer.SignMessage(bytes.NewReader(canonical))
+}
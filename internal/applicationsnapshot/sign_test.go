@@ -0,0 +1,121 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package applicationsnapshot
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/secure-systems-lab/go-securesystemslib/dsse"
+	"github.com/sigstore/cosign/v2/pkg/cosign"
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
+	"github.com/sigstore/sigstore/pkg/signature"
+	sigstoreDSSE "github.com/sigstore/sigstore/pkg/signature/dsse"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/format"
+)
+
+// outputSigner creates a signer with a new key pair, returning the signer and
+// the verifier of the signatures
+func outputSigner(t *testing.T) (*OutputSigner, signature.Verifier) {
+	keys, err := cosign.GenerateKeyPair(func(bool) ([]byte, error) { return []byte("secret"), nil })
+	require.NoError(t, err)
+
+	key := filepath.Join(t.TempDir(), "cosign.key")
+	require.NoError(t, os.WriteFile(key, keys.PrivateBytes, 0600))
+	t.Setenv("COSIGN_PASSWORD", "secret")
+
+	s, err := NewOutputSigner(context.Background(), key)
+	require.NoError(t, err)
+
+	pub, err := cryptoutils.UnmarshalPEMToPublicKey(keys.PublicBytes)
+	require.NoError(t, err)
+	verifier, err := signature.LoadVerifier(pub, crypto.SHA256)
+	require.NoError(t, err)
+
+	return s, sigstoreDSSE.WrapVerifier(verifier)
+}
+
+// payloadOf verifies the envelope and returns its payload
+func payloadOf(t *testing.T, verifier signature.Verifier, envelope []byte) string {
+	require.NoError(t, verifier.VerifySignature(bytes.NewReader(envelope), nil))
+
+	var e dsse.Envelope
+	require.NoError(t, json.Unmarshal(envelope, &e))
+	assert.Equal(t, OutputPayloadType, e.PayloadType)
+
+	payload, err := base64.StdEncoding.DecodeString(e.Payload)
+	require.NoError(t, err)
+
+	return string(payload)
+}
+
+func TestOutputSignerSign(t *testing.T) {
+	s, verifier := outputSigner(t)
+
+	envelope, err := s.Sign([]byte(`{"success": true, "components": [], "key": "k"}` + "\n"))
+	require.NoError(t, err)
+
+	// the payload is the canonical output
+	assert.Equal(t, `{"components":[],"key":"k","success":true}`, payloadOf(t, verifier, envelope))
+
+	_, err = s.Sign([]byte("Success: true"))
+	assert.ErrorContains(t, err, "unable to canonicalize the output")
+}
+
+func TestNewOutputSignerMissingKey(t *testing.T) {
+	_, err := NewOutputSigner(context.Background(), filepath.Join(t.TempDir(), "missing.key"))
+	assert.ErrorContains(t, err, "unable to load the output signing key")
+}
+
+func TestReportWriteAllSigned(t *testing.T) {
+	s, verifier := outputSigner(t)
+
+	fs := afero.NewMemMapFs()
+	var defaultWriter bytes.Buffer
+
+	ctx := context.Background()
+	report, err := NewReport("snapshot", nil, createTestPolicy(t, ctx), nil, nil, false)
+	require.NoError(t, err)
+	report.OutputSigner = s
+
+	p := format.NewTargetParser(JSON, format.Options{}, &defaultWriter, fs)
+	require.NoError(t, report.WriteAll([]string{"json", "json=report.json", "yaml=report.yaml", "json+=reports.jsonl"}, p))
+
+	envelope, err := afero.ReadFile(fs, "report.json"+OutputSignatureSuffix)
+	require.NoError(t, err)
+	content, err := afero.ReadFile(fs, "report.json")
+	require.NoError(t, err)
+	assert.JSONEq(t, string(content), payloadOf(t, verifier, envelope))
+
+	for _, f := range []string{"report.yaml", "reports.jsonl"} {
+		exists, err := afero.Exists(fs, f+OutputSignatureSuffix)
+		require.NoError(t, err)
+		assert.False(t, exists, f)
+	}
+}
@@ -0,0 +1,44 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package applicationsnapshot
+
+// SkippedImage is an image that was not validated, with the machine-readable
+// reason, e.g. SkippedUnchanged, so that runs covering only part of the
+// images can be audited.
+type SkippedImage struct {
+	Name           string `json:"name"`
+	ContainerImage string `json:"containerImage"`
+	Reason         string `json:"reason"`
+}
+
+// skippedImages lists the components that were not validated, see
+// Component.Skipped.
+func skippedImages(components []Component) []SkippedImage {
+	var skipped []SkippedImage
+	for _, c := range components {
+		if c.Skipped == "" {
+			continue
+		}
+		skipped = append(skipped, SkippedImage{
+			Name:           c.Name,
+			ContainerImage: c.ContainerImage,
+			Reason:         c.Skipped,
+		})
+	}
+
+	return skipped
+}
@@ -0,0 +1,37 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package applicationsnapshot
+
+import (
+	"testing"
+
+	app "github.com/konflux-ci/application-api/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSkippedImages(t *testing.T) {
+	assert.Nil(t, skippedImages([]Component{{Success: true}}))
+
+	components := []Component{
+		{SnapshotComponent: app.SnapshotComponent{Name: "a", ContainerImage: "registry/a:tag"}, Success: true},
+		{SnapshotComponent: app.SnapshotComponent{Name: "b", ContainerImage: "registry/b:tag"}, Success: true, Skipped: SkippedUnchanged},
+	}
+
+	assert.Equal(t, []SkippedImage{{Name: "b", ContainerImage: "registry/b:tag", Reason: "unchanged"}}, skippedImages(components))
+}
@@ -0,0 +1,83 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package applicationsnapshot
+
+import (
+	"github.com/enterprise-contract/ec-cli/internal/output"
+)
+
+// Status is the outcome of validating an image.
+type Status string
+
+const (
+	// StatusPass marks images that were evaluated and satisfy the policy
+	StatusPass Status = "pass"
+	// StatusFail marks images that were evaluated and violate the policy
+	StatusFail Status = "fail"
+	// StatusError marks images that could not be evaluated, e.g. the image
+	// could not be fetched or its signatures could not be verified
+	StatusError Status = "error"
+	// StatusSkipped marks images that were not validated, see
+	// Component.Skipped
+	StatusSkipped Status = "skipped"
+)
+
+// status determines the outcome of validating the image of the component.
+func (c Component) status() Status {
+	switch {
+	case c.Skipped != "":
+		return StatusSkipped
+	case c.RateLimited:
+		return StatusError
+	case c.Verification != nil && !verified(*c.Verification):
+		return StatusError
+	case !c.Success:
+		return StatusFail
+	default:
+		return StatusPass
+	}
+}
+
+// AttestationsPresence is "present" or "none" depending on whether the image
+// has any attestations, empty unless attestations are optional.
+func (c Component) AttestationsPresence() string {
+	switch {
+	case c.AttestationsPresent == nil:
+		return ""
+	case *c.AttestationsPresent:
+		return "present"
+	default:
+		return "none"
+	}
+}
+
+// verified returns true if the image could be fetched and its signatures and
+// attestations verified, i.e. the policy could be evaluated.
+func verified(v output.Verification) bool {
+	for _, s := range []output.Stage{v.ImageAccessible, v.ImageSignature, v.AttestationSignature} {
+		if s.Status == output.StageFailed || s.Status == output.StageRateLimited {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Errored returns true if any image of the report could not be evaluated.
+func (r *Report) Errored() bool {
+	return r.Errors > 0
+}
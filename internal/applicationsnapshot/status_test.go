@@ -0,0 +1,98 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package applicationsnapshot
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/enterprise-contract/ec-cli/internal/output"
+)
+
+func TestComponentStatus(t *testing.T) {
+	evaluated := &output.Verification{
+		ImageAccessible:      output.Stage{Status: output.StageOK},
+		ImageSignature:       output.Stage{Status: output.StageOK},
+		AttestationSignature: output.Stage{Status: output.StageOK},
+		AttestationSyntax:    output.Stage{Status: output.StageOK},
+		Policy:               output.Stage{Status: output.StageFailed, Category: output.PolicyViolation},
+	}
+	inaccessible := &output.Verification{
+		ImageAccessible: output.Stage{Status: output.StageFailed, Category: output.ImageInaccessible},
+	}
+	unsigned := &output.Verification{
+		ImageAccessible:      output.Stage{Status: output.StageOK},
+		ImageSignature:       output.Stage{Status: output.StageFailed, Category: output.MissingSignature},
+		AttestationSignature: output.Stage{Status: output.StageFailed, Category: output.MissingAttestation},
+	}
+	unattested := &output.Verification{
+		ImageAccessible:      output.Stage{Status: output.StageOK},
+		ImageSignature:       output.Stage{Status: output.StageOK},
+		AttestationSignature: output.Stage{Status: output.StageSkipped, Category: output.MissingAttestation},
+		Policy:               output.Stage{Status: output.StageOK},
+	}
+
+	cases := []struct {
+		name      string
+		component Component
+		expected  Status
+	}{
+		{name: "pass", component: Component{Success: true}, expected: StatusPass},
+		{name: "fail", component: Component{Verification: evaluated}, expected: StatusFail},
+		{name: "inaccessible", component: Component{Verification: inaccessible}, expected: StatusError},
+		{name: "unsigned", component: Component{Verification: unsigned}, expected: StatusError},
+		{name: "rate limited", component: Component{RateLimited: true}, expected: StatusError},
+		{name: "skipped", component: Component{Success: true, Skipped: SkippedUnchanged}, expected: StatusSkipped},
+		{name: "without attestations allowed", component: Component{Success: true, Verification: unattested}, expected: StatusPass},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.expected, c.component.status())
+		})
+	}
+}
+
+func TestComponentAttestationsPresence(t *testing.T) {
+	present, absent := true, false
+
+	assert.Equal(t, "", Component{}.AttestationsPresence())
+	assert.Equal(t, "present", Component{AttestationsPresent: &present}.AttestationsPresence())
+	assert.Equal(t, "none", Component{AttestationsPresent: &absent}.AttestationsPresence())
+}
+
+func TestReportErrors(t *testing.T) {
+	components := []Component{
+		{Success: true},
+		{Verification: &output.Verification{ImageAccessible: output.Stage{Status: output.StageFailed}}},
+	}
+
+	report, err := NewReport("", components, createTestPolicy(t, context.Background()), nil, nil, false)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, report.Errors)
+	assert.True(t, report.Errored())
+	assert.Equal(t, StatusPass, report.Components[0].Status)
+	assert.Equal(t, StatusError, report.Components[1].Status)
+
+	testReport := report.toAppstudioReport()
+	assert.Equal(t, "ERROR", testReport.Result)
+	assert.Equal(t, 1, testReport.Errors)
+}
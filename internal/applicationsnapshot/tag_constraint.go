@@ -0,0 +1,198 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package applicationsnapshot
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	app "github.com/konflux-ci/application-api/api/v1alpha1"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/mod/semver"
+
+	"github.com/enterprise-contract/ec-cli/internal/utils/oci"
+)
+
+// TagConstraint selects the tag of a repository with the highest semantic
+// version satisfying all of its comparisons, e.g. >=1.2,<2.0.
+type TagConstraint struct {
+	Repository  name.Repository
+	comparisons []comparison
+	given       string
+}
+
+type comparison struct {
+	operator string
+	version  string
+}
+
+// operators of the comparisons, the longer operators first so that they are
+// matched before their prefixes
+var operators = []string{">=", "<=", "!=", ">", "<", "="}
+
+// releaseTag matches the tags that are complete semantic versions, optionally
+// prefixed with v, e.g. 1.2.3 or v1.2.3-rc.1
+var releaseTag = regexp.MustCompile(`^v?[0-9]+\.[0-9]+\.[0-9]+(-[0-9A-Za-z.-]+)?$`)
+
+// ParseTagConstraint parses the tag constraint given as
+// <repository>:<comparison>[,<comparison>...], each comparison an operator,
+// one of >=, <=, !=, >, < or =, followed by a semantic version, possibly
+// partial, e.g. registry.io/repository:>=1.2,<2.0. The = operator can be
+// omitted.
+func ParseTagConstraint(s string) (TagConstraint, error) {
+	// The repository might include the port of the registry
+	slash := strings.LastIndex(s, "/")
+	colon := strings.Index(s[slash+1:], ":")
+	if colon == -1 {
+		return TagConstraint{}, fmt.Errorf("invalid image tag constraint %q, expecting <repository>:<constraint>, e.g. registry.io/repository:>=1.2,<2.0", s)
+	}
+	colon += slash + 1
+
+	repo, err := name.NewRepository(s[:colon])
+	if err != nil {
+		return TagConstraint{}, fmt.Errorf("invalid repository in the image tag constraint %q: %w", s, err)
+	}
+
+	c := TagConstraint{Repository: repo, given: s[colon+1:]}
+	for _, part := range strings.Split(c.given, ",") {
+		part = strings.TrimSpace(part)
+
+		operator := "="
+		for _, o := range operators {
+			if strings.HasPrefix(part, o) {
+				operator = o
+				break
+			}
+		}
+		version := strings.TrimSpace(strings.TrimPrefix(part, operator))
+		if !strings.HasPrefix(version, "v") {
+			version = "v" + version
+		}
+
+		if !semver.IsValid(version) {
+			return TagConstraint{}, fmt.Errorf("invalid comparison %q in the image tag constraint %q, expecting an operator followed by a semantic version", part, s)
+		}
+
+		c.comparisons = append(c.comparisons, comparison{operator: operator, version: semver.Canonical(version)})
+	}
+
+	return c, nil
+}
+
+// String returns the constraint as given.
+func (c TagConstraint) String() string {
+	return c.Repository.Name() + ":" + c.given
+}
+
+// Select returns the tag with the highest semantic version satisfying the
+// constraint, false if there is none. Tags with pre-release versions are
+// selected only when the constraint compares with a pre-release version.
+func (c TagConstraint) Select(tags []string) (string, bool) {
+	prerelease := false
+	for _, cmp := range c.comparisons {
+		if semver.Prerelease(cmp.version) != "" {
+			prerelease = true
+		}
+	}
+
+	var selected, selectedVersion string
+	for _, tag := range tags {
+		if !releaseTag.MatchString(tag) {
+			continue
+		}
+
+		version := tag
+		if !strings.HasPrefix(version, "v") {
+			version = "v" + version
+		}
+
+		if semver.Prerelease(version) != "" && !prerelease {
+			continue
+		}
+
+		if !c.satisfied(version) {
+			continue
+		}
+
+		// The tag given without the v prefix is preferred for the same
+		// version so that the selection does not depend on the order
+		if cmp := semver.Compare(version, selectedVersion); selected == "" || cmp > 0 || (cmp == 0 && tag < selected) {
+			selected, selectedVersion = tag, version
+		}
+	}
+
+	return selected, selected != ""
+}
+
+func (c TagConstraint) satisfied(version string) bool {
+	for _, cmp := range c.comparisons {
+		r := semver.Compare(version, cmp.version)
+		var ok bool
+		switch cmp.operator {
+		case ">=":
+			ok = r >= 0
+		case "<=":
+			ok = r <= 0
+		case "!=":
+			ok = r != 0
+		case ">":
+			ok = r > 0
+		case "<":
+			ok = r < 0
+		default:
+			ok = r == 0
+		}
+		if !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Resolve lists the tags of the repository, selects the tag satisfying the
+// constraint, see Select, and returns the component of the image with the
+// selected tag, named after the tag and pinned to its digest.
+func (c TagConstraint) Resolve(ctx context.Context) (app.SnapshotComponent, error) {
+	client := oci.NewClient(ctx)
+
+	tags, err := client.ListTags(c.Repository)
+	if err != nil {
+		return app.SnapshotComponent{}, fmt.Errorf("unable to list the tags of %s: %w", c.Repository, err)
+	}
+
+	tag, ok := c.Select(tags)
+	if !ok {
+		return app.SnapshotComponent{}, fmt.Errorf("no tag of %s satisfies the constraint %s", c.Repository, c.given)
+	}
+
+	ref := c.Repository.Tag(tag)
+	digest, err := client.ResolveDigest(ref)
+	if err != nil {
+		return app.SnapshotComponent{}, fmt.Errorf("unable to resolve the digest of %s: %w", ref, err)
+	}
+
+	log.Infof("Selected the tag %s, %s, for the image tag constraint %s", ref, digest, c)
+
+	return app.SnapshotComponent{
+		Name:           ref.String(),
+		ContainerImage: c.Repository.Digest(digest).String(),
+	}, nil
+}
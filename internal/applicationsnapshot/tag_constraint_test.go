@@ -0,0 +1,118 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package applicationsnapshot
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	app "github.com/konflux-ci/application-api/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/utils/oci"
+	"github.com/enterprise-contract/ec-cli/internal/utils/oci/fake"
+)
+
+func TestParseTagConstraint(t *testing.T) {
+	c, err := ParseTagConstraint("registry.io:5000/repository/image:>=1.2, <2.0")
+	require.NoError(t, err)
+	assert.Equal(t, "registry.io:5000/repository/image", c.Repository.Name())
+	assert.Equal(t, "registry.io:5000/repository/image:>=1.2, <2.0", c.String())
+
+	cases := []struct {
+		given string
+		err   string
+	}{
+		{given: "registry.io/repository/image", err: `invalid image tag constraint "registry.io/repository/image", expecting <repository>:<constraint>`},
+		{given: "registry.io/Repository:1.0", err: `invalid repository in the image tag constraint "registry.io/Repository:1.0"`},
+		{given: "registry.io/repository:>=latest", err: `invalid comparison ">=latest" in the image tag constraint "registry.io/repository:>=latest"`},
+		{given: "registry.io/repository:>=1.0,", err: `invalid comparison "" in the image tag constraint "registry.io/repository:>=1.0,"`},
+	}
+
+	for _, c := range cases {
+		t.Run(c.given, func(t *testing.T) {
+			_, err := ParseTagConstraint(c.given)
+			assert.ErrorContains(t, err, c.err)
+		})
+	}
+}
+
+func TestTagConstraintSelect(t *testing.T) {
+	tags := []string{"latest", "0.9.0", "1", "1.1.9", "v1.2.0", "1.2.0", "1.10.1", "1.11.0-rc.1", "2.0.0", "sha256-abc.sig"}
+
+	cases := []struct {
+		constraint string
+		expected   string
+	}{
+		{constraint: ">=1.2,<2.0", expected: "1.10.1"},
+		{constraint: ">=1.2,<1.10", expected: "1.2.0"},
+		{constraint: "1.1.9", expected: "1.1.9"},
+		{constraint: "=1.1.9", expected: "1.1.9"},
+		{constraint: ">1", expected: "2.0.0"},
+		{constraint: ">=1.0,!=2.0.0", expected: "1.10.1"},
+		{constraint: ">=1.11.0-rc.0,<2", expected: "1.11.0-rc.1"},
+		{constraint: "<=0.9", expected: "0.9.0"},
+		{constraint: ">2"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.constraint, func(t *testing.T) {
+			tc, err := ParseTagConstraint("registry.io/repository:" + c.constraint)
+			require.NoError(t, err)
+
+			tag, ok := tc.Select(tags)
+			assert.Equal(t, c.expected != "", ok)
+			assert.Equal(t, c.expected, tag)
+		})
+	}
+}
+
+func TestDetermineInputImageTagConstraint(t *testing.T) {
+	digest := "sha256:" + strings.Repeat("a", 64)
+	repo := name.MustParseReference("registry.io/repository/image").Context()
+
+	client := fake.FakeClient{}
+	client.On("ListTags", repo).Return([]string{"1.2.0", "1.3.1", "2.0.0"}, nil)
+	client.On("ResolveDigest", repo.Tag("1.3.1")).Return(digest, nil)
+	client.On("Head", mock.Anything).Return(&v1.Descriptor{MediaType: types.OCIManifestSchema1}, nil)
+	ctx := oci.WithClient(context.Background(), &client)
+
+	got, err := DetermineInputSpec(ctx, Input{ImageTagConstraints: []string{"registry.io/repository/image:>=1.2,<2.0"}})
+	require.NoError(t, err)
+	assert.Equal(t, &app.SnapshotSpec{Components: []app.SnapshotComponent{
+		{Name: "registry.io/repository/image:1.3.1", ContainerImage: "registry.io/repository/image@" + digest},
+	}}, got)
+
+	_, err = DetermineInputSpec(ctx, Input{ImageTagConstraints: []string{"registry.io/repository/image:>=3"}})
+	assert.EqualError(t, err, "no tag of registry.io/repository/image satisfies the constraint >=3")
+
+	failing := fake.FakeClient{}
+	failing.On("ListTags", repo).Return(nil, errors.New("denied"))
+	ctx = oci.WithClient(context.Background(), &failing)
+
+	_, err = DetermineInputSpec(ctx, Input{ImageTagConstraints: []string{"registry.io/repository/image:1"}})
+	assert.EqualError(t, err, "unable to list the tags of registry.io/repository/image: denied")
+}
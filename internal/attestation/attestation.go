@@ -21,6 +21,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
 
 	"github.com/in-toto/in-toto-golang/in_toto"
 	"github.com/sigstore/cosign/v2/pkg/cosign"
@@ -97,6 +98,9 @@ func createEntitySignatures(sig oci.Signature, payload cosign.AttestationPayload
 	var out []signature.EntitySignature
 	for _, s := range payload.Signatures {
 		esNew := es
+		// each of the signatures of the envelope has metadata of its own, see
+		// MarkVerifiedSignatures
+		esNew.Metadata = maps.Clone(es.Metadata)
 		// The Signature and KeyID can come from two locations, the oci.Signature or
 		// the cosign.Signature. In some cases, both are filled in, while in others
 		// only one location contains the value. The discrepancy can be seen when
@@ -182,3 +186,29 @@ func (p provenance) MarshalJSON() ([]byte, error) {
 
 	return json.Marshal(val)
 }
+
+// FromStatement recreates the attestation from the statement, as returned by
+// Statement, and the signatures of the attestation, e.g. when reading the
+// attestation back from a cache.
+func FromStatement(data []byte, signatures []signature.EntitySignature) (Attestation, error) {
+	var header in_toto.StatementHeader
+	if err := json.Unmarshal(data, &header); err != nil {
+		return nil, fmt.Errorf("malformed attestation data: %w", err)
+	}
+
+	if header.PredicateType == PredicateSLSAProvenance {
+		var statement in_toto.ProvenanceStatementSLSA02
+		if err := json.Unmarshal(data, &statement); err != nil {
+			return nil, fmt.Errorf("malformed attestation data: %w", err)
+		}
+
+		return slsaProvenance{statement: statement, data: data, signatures: signatures}, nil
+	}
+
+	var statement in_toto.Statement
+	if err := json.Unmarshal(data, &statement); err != nil {
+		return nil, fmt.Errorf("malformed attestation data: %w", err)
+	}
+
+	return provenance{statement: statement, data: data, signatures: signatures}, nil
+}
@@ -125,3 +125,24 @@ func TestProvenanceFromSignature(t *testing.T) {
 		})
 	}
 }
+
+func TestFromStatement(t *testing.T) {
+	signatures := []signature.EntitySignature{{KeyID: "key-id-1", Signature: "sig-1"}}
+
+	slsa := []byte(`{"_type": "https://in-toto.io/Statement/v0.1", "predicateType": "https://slsa.dev/provenance/v0.2", "subject": [{"name": "image", "digest": {"sha256": "abc"}}], "predicate": {"buildType": "https://tekton.dev/chains/v2"}}`)
+	att, err := FromStatement(slsa, signatures)
+	assert.NoError(t, err)
+	assert.IsType(t, slsaProvenance{}, att)
+	assert.Equal(t, slsa, att.Statement())
+	assert.Equal(t, signatures, att.Signatures())
+	assert.Equal(t, "image", att.Subject()[0].Name)
+
+	other := []byte(`{"_type": "https://in-toto.io/Statement/v0.1", "predicateType": "https://cool-type.example.io/Amazing/v2.0", "predicate": {}}`)
+	att, err = FromStatement(other, signatures)
+	assert.NoError(t, err)
+	assert.IsType(t, provenance{}, att)
+	assert.Equal(t, "https://cool-type.example.io/Amazing/v2.0", att.PredicateType())
+
+	_, err = FromStatement([]byte("{"), nil)
+	assert.ErrorContains(t, err, "malformed attestation data")
+}
@@ -0,0 +1,173 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package attestation
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/secure-systems-lab/go-securesystemslib/dsse"
+	"github.com/sigstore/cosign/v2/pkg/oci"
+	cosignSig "github.com/sigstore/cosign/v2/pkg/signature"
+	sigstoreSig "github.com/sigstore/sigstore/pkg/signature"
+	"github.com/sigstore/sigstore/pkg/signature/options"
+)
+
+const (
+	// SignatureVerifiedMetadata is the metadata of the signatures of an
+	// envelope holding whether the signature verified, "true" or "false"
+	SignatureVerifiedMetadata = "verified"
+	// SignatureSignerMetadata is the metadata of the signatures of an
+	// envelope naming the signer the signature verified with
+	SignatureSignerMetadata = "signer"
+)
+
+// Cosigner is a signer of the DSSE envelopes of the attestations, named by the
+// reference to its key. Co-signers are identified by their keys only: the
+// signatures of a DSSE envelope do not carry the certificates of keyless
+// signers, so those cannot be verified.
+type Cosigner struct {
+	Name     string
+	Verifier sigstoreSig.Verifier
+}
+
+// CosignerOptions configures verifying each of the signatures of the DSSE
+// envelopes of the attestations.
+type CosignerOptions struct {
+	// Cosigners are the signers, in addition to the key or the certificate the
+	// attestation was verified with, whose signatures are counted
+	Cosigners []Cosigner
+	// Required is the number of distinct signers that must have signed each
+	// envelope. With zero an envelope is accepted on its first valid
+	// signature and its signatures are not verified one by one.
+	Required int
+}
+
+const cosignerOptionsKey contextKey = "ec.attestation.cosigners"
+
+// WithCosignerOptions returns a copy of the context requiring the DSSE
+// envelopes of the attestations to be signed by a number of the signers.
+func WithCosignerOptions(ctx context.Context, opts CosignerOptions) context.Context {
+	return context.WithValue(ctx, cosignerOptionsKey, opts)
+}
+
+func cosignerOptions(ctx context.Context) CosignerOptions {
+	if opts, ok := ctx.Value(cosignerOptionsKey).(CosignerOptions); ok {
+		return opts
+	}
+
+	return CosignerOptions{}
+}
+
+// LoadCosigners loads the public keys of the co-signers, each given as a PEM
+// encoded key, a path to a key or a reference to a key, e.g.
+// k8s://namespace/secret.
+func LoadCosigners(ctx context.Context, keyRefs []string) ([]Cosigner, error) {
+	cosigners := make([]Cosigner, 0, len(keyRefs))
+	for _, ref := range keyRefs {
+		var verifier sigstoreSig.Verifier
+		var err error
+		name := ref
+		if strings.Contains(ref, "-----BEGIN PUBLIC KEY-----") {
+			verifier, err = cosignSig.LoadPublicKeyRaw([]byte(ref), crypto.SHA256)
+			name = fmt.Sprintf("key %d", len(cosigners)+1)
+		} else {
+			verifier, err = cosignSig.PublicKeyFromKeyRef(ctx, ref)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("unable to load the co-signer key %s: %w", name, err)
+		}
+		cosigners = append(cosigners, Cosigner{Name: name, Verifier: verifier})
+	}
+
+	return cosigners, nil
+}
+
+// VerifyCosigners verifies each of the signatures of the DSSE envelope of the
+// attestation with the signer the attestation was verified with and with the
+// co-signers, if required by the options in the context. It returns the name
+// of the signer each signature verified with, empty for the signatures that
+// did not verify, and an error if fewer distinct signers than required signed
+// the envelope. No signatures are returned when not required.
+func VerifyCosigners(ctx context.Context, sig oci.Signature, signer Cosigner) ([]string, error) {
+	opts := cosignerOptions(ctx)
+	if opts.Required == 0 {
+		return nil, nil
+	}
+
+	payload, err := payloadFromSig(sig)
+	if err != nil {
+		return nil, err
+	}
+
+	decoded, err := decodedPayload(payload)
+	if err != nil {
+		return nil, err
+	}
+	pae := dsse.PAE(payload.PayloadType, decoded)
+
+	signers := append([]Cosigner{signer}, opts.Cosigners...)
+	verifiedBy := make([]string, len(payload.Signatures))
+	distinct := map[string]bool{}
+	for i, s := range payload.Signatures {
+		raw, err := base64.StdEncoding.DecodeString(s.Sig)
+		if err != nil {
+			continue
+		}
+
+		for _, c := range signers {
+			if c.Verifier == nil {
+				continue
+			}
+			if err := c.Verifier.VerifySignature(bytes.NewReader(raw), bytes.NewReader(pae), options.WithContext(ctx)); err == nil {
+				verifiedBy[i] = c.Name
+				distinct[c.Name] = true
+				break
+			}
+		}
+	}
+
+	if len(distinct) < opts.Required {
+		return verifiedBy, fmt.Errorf("the attestation envelope is signed by %d of the %d required signers", len(distinct), opts.Required)
+	}
+
+	return verifiedBy, nil
+}
+
+// MarkVerifiedSignatures records in the metadata of each of the signatures of
+// the attestation, in the order of the signatures of the envelope, whether it
+// verified, and the signer it verified with, as returned by VerifyCosigners.
+func MarkVerifiedSignatures(att Attestation, verifiedBy []string) {
+	sigs := att.Signatures()
+	if len(sigs) != len(verifiedBy) {
+		return
+	}
+
+	for i, signer := range verifiedBy {
+		if sigs[i].Metadata == nil {
+			continue
+		}
+		sigs[i].Metadata[SignatureVerifiedMetadata] = fmt.Sprint(signer != "")
+		if signer != "" {
+			sigs[i].Metadata[SignatureSignerMetadata] = signer
+		}
+	}
+}
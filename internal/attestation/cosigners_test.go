@@ -0,0 +1,120 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package attestation
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/secure-systems-lab/go-securesystemslib/dsse"
+	"github.com/sigstore/cosign/v2/pkg/cosign"
+	ct "github.com/sigstore/cosign/v2/pkg/types"
+	sigstoreSig "github.com/sigstore/sigstore/pkg/signature"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/signature"
+)
+
+func newSignerVerifier(t *testing.T) sigstoreSig.SignerVerifier {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	sv, err := sigstoreSig.LoadECDSASignerVerifier(key, crypto.SHA256)
+	require.NoError(t, err)
+
+	return sv
+}
+
+// envelope returns an attestation layer with the statement signed by each of
+// the signers
+func envelope(t *testing.T, signers ...sigstoreSig.Signer) mockSignature {
+	statement := []byte(`{"_type": "https://in-toto.io/Statement/v0.1"}`)
+	payload := cosign.AttestationPayload{
+		PayloadType: ct.IntotoPayloadType,
+		PayLoad:     base64.StdEncoding.EncodeToString(statement),
+	}
+	for i, s := range signers {
+		sig, err := s.SignMessage(bytes.NewReader(dsse.PAE(ct.IntotoPayloadType, statement)))
+		require.NoError(t, err)
+		payload.Signatures = append(payload.Signatures, cosign.Signatures{KeyID: string(rune('a' + i)), Sig: base64.StdEncoding.EncodeToString(sig)})
+	}
+	data, err := json.Marshal(payload)
+	require.NoError(t, err)
+
+	sig := mockSignature{&mock.Mock{}}
+	sig.On("MediaType").Return(types.MediaType(ct.DssePayloadType), nil)
+	sig.On("Uncompressed").Return(buffy(string(data)), nil)
+
+	return sig
+}
+
+func TestVerifyCosigners(t *testing.T) {
+	primary := newSignerVerifier(t)
+	cosigner := newSignerVerifier(t)
+	unknown := newSignerVerifier(t)
+
+	signer := Cosigner{Name: "public key", Verifier: primary}
+	ctx := WithCosignerOptions(context.Background(), CosignerOptions{
+		Cosigners: []Cosigner{{Name: "cosigner.pub", Verifier: cosigner}},
+		Required:  2,
+	})
+
+	verifiedBy, err := VerifyCosigners(ctx, envelope(t, primary, cosigner), signer)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"public key", "cosigner.pub"}, verifiedBy)
+
+	verifiedBy, err = VerifyCosigners(ctx, envelope(t, unknown, primary), signer)
+	assert.EqualError(t, err, "the attestation envelope is signed by 1 of the 2 required signers")
+	assert.Equal(t, []string{"", "public key"}, verifiedBy)
+
+	// the signatures of the same signer are counted once
+	_, err = VerifyCosigners(ctx, envelope(t, primary, primary), signer)
+	assert.EqualError(t, err, "the attestation envelope is signed by 1 of the 2 required signers")
+
+	// not verified one by one unless required
+	verifiedBy, err = VerifyCosigners(context.Background(), envelope(t, unknown), signer)
+	assert.NoError(t, err)
+	assert.Nil(t, verifiedBy)
+}
+
+func TestMarkVerifiedSignatures(t *testing.T) {
+	att := provenance{signatures: []signature.EntitySignature{
+		{KeyID: "a", Metadata: map[string]string{}},
+		{KeyID: "b", Metadata: map[string]string{}},
+	}}
+
+	MarkVerifiedSignatures(att, []string{"", "cosigner.pub"})
+	assert.Equal(t, []signature.EntitySignature{
+		{KeyID: "a", Metadata: map[string]string{"verified": "false"}},
+		{KeyID: "b", Metadata: map[string]string{"verified": "true", "signer": "cosigner.pub"}},
+	}, att.Signatures())
+
+	// nothing is recorded unless verified one by one
+	MarkVerifiedSignatures(att, nil)
+	assert.Len(t, att.Signatures()[0].Metadata, 1)
+}
@@ -0,0 +1,172 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package attestation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/in-toto/in-toto-golang/in_toto/slsa_provenance/common"
+	v02 "github.com/in-toto/in-toto-golang/in_toto/slsa_provenance/v0.2"
+	v1 "github.com/in-toto/in-toto-golang/in_toto/slsa_provenance/v1"
+)
+
+// PredicateSLSAProvenanceV1 is the predicate type of the SLSA Provenance v1
+const PredicateSLSAProvenanceV1 = v1.PredicateSLSAProvenance
+
+// Normalizer maps the statement of an attestation to the canonical shape of
+// its predicate, given to the policy alongside the statement.
+type Normalizer func(statement []byte) (any, error)
+
+const normalizationKey contextKey = "ec.attestation.normalization"
+
+var (
+	normalizersMu sync.RWMutex
+	normalizers   = map[string]Normalizer{
+		PredicateSLSAProvenance:   normalizeSLSAProvenance02,
+		PredicateSLSAProvenanceV1: normalizeSLSAProvenanceV1,
+	}
+)
+
+// WithNormalization returns a copy of the context enabling the normalization
+// of the attestations given to the policy.
+func WithNormalization(ctx context.Context, enabled bool) context.Context {
+	return context.WithValue(ctx, normalizationKey, enabled)
+}
+
+// NormalizationEnabled returns true if the attestations given to the policy
+// are to be normalized.
+func NormalizationEnabled(ctx context.Context) bool {
+	enabled, _ := ctx.Value(normalizationKey).(bool)
+
+	return enabled
+}
+
+// RegisterNormalizer registers the normalizer of the attestations of the
+// predicate type, replacing the normalizer registered for it, if any.
+func RegisterNormalizer(predicateType string, n Normalizer) {
+	normalizersMu.Lock()
+	defer normalizersMu.Unlock()
+
+	normalizers[predicateType] = n
+}
+
+// Normalize maps the statement of an attestation to the canonical shape of its
+// predicate type. For predicate types without a registered normalizer it
+// returns nil, the statement is given to the policy unchanged.
+func Normalize(predicateType string, statement []byte) (any, error) {
+	normalizersMu.RLock()
+	n, ok := normalizers[predicateType]
+	normalizersMu.RUnlock()
+
+	if !ok {
+		return nil, nil
+	}
+
+	normalized, err := n(statement)
+	if err != nil {
+		return nil, fmt.Errorf("unable to normalize the %s attestation: %w", predicateType, err)
+	}
+
+	return normalized, nil
+}
+
+// Provenance is the canonical shape of the SLSA Provenance, unifying the field
+// names of the v0.2 and the v1 predicates.
+type Provenance struct {
+	// BuilderID is builder.id in v0.2 and runDetails.builder.id in v1
+	BuilderID string `json:"builderId"`
+	// BuildType is buildType in v0.2 and buildDefinition.buildType in v1
+	BuildType string `json:"buildType"`
+	// InvocationID is metadata.buildInvocationID in v0.2 and
+	// runDetails.metadata.invocationID in v1
+	InvocationID string `json:"invocationId,omitempty"`
+	// StartedOn is metadata.buildStartedOn in v0.2 and
+	// runDetails.metadata.startedOn in v1
+	StartedOn *time.Time `json:"startedOn,omitempty"`
+	// FinishedOn is metadata.buildFinishedOn in v0.2 and
+	// runDetails.metadata.finishedOn in v1
+	FinishedOn *time.Time `json:"finishedOn,omitempty"`
+	// Parameters is invocation.parameters in v0.2 and
+	// buildDefinition.externalParameters in v1
+	Parameters any `json:"parameters,omitempty"`
+	// Materials is materials in v0.2 and buildDefinition.resolvedDependencies
+	// in v1
+	Materials []Material `json:"materials"`
+}
+
+// Material is an artifact the build consumed.
+type Material struct {
+	URI    string           `json:"uri,omitempty"`
+	Digest common.DigestSet `json:"digest,omitempty"`
+}
+
+func normalizeSLSAProvenance02(statement []byte) (any, error) {
+	var s struct {
+		Predicate v02.ProvenancePredicate `json:"predicate"`
+	}
+	if err := json.Unmarshal(statement, &s); err != nil {
+		return nil, err
+	}
+
+	p := Provenance{
+		BuilderID:  s.Predicate.Builder.ID,
+		BuildType:  s.Predicate.BuildType,
+		Parameters: s.Predicate.Invocation.Parameters,
+		Materials:  []Material{},
+	}
+
+	if m := s.Predicate.Metadata; m != nil {
+		p.InvocationID = m.BuildInvocationID
+		p.StartedOn = m.BuildStartedOn
+		p.FinishedOn = m.BuildFinishedOn
+	}
+
+	for _, m := range s.Predicate.Materials {
+		p.Materials = append(p.Materials, Material{URI: m.URI, Digest: m.Digest})
+	}
+
+	return p, nil
+}
+
+func normalizeSLSAProvenanceV1(statement []byte) (any, error) {
+	var s struct {
+		Predicate v1.ProvenancePredicate `json:"predicate"`
+	}
+	if err := json.Unmarshal(statement, &s); err != nil {
+		return nil, err
+	}
+
+	p := Provenance{
+		BuilderID:    s.Predicate.RunDetails.Builder.ID,
+		BuildType:    s.Predicate.BuildDefinition.BuildType,
+		InvocationID: s.Predicate.RunDetails.BuildMetadata.InvocationID,
+		StartedOn:    s.Predicate.RunDetails.BuildMetadata.StartedOn,
+		FinishedOn:   s.Predicate.RunDetails.BuildMetadata.FinishedOn,
+		Parameters:   s.Predicate.BuildDefinition.ExternalParameters,
+		Materials:    []Material{},
+	}
+
+	for _, d := range s.Predicate.BuildDefinition.ResolvedDependencies {
+		p.Materials = append(p.Materials, Material{URI: d.URI, Digest: d.Digest})
+	}
+
+	return p, nil
+}
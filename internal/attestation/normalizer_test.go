@@ -0,0 +1,142 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package attestation
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalize(t *testing.T) {
+	started := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	finished := time.Date(2024, 5, 1, 10, 5, 0, 0, time.UTC)
+
+	expected := Provenance{
+		BuilderID:    "https://tekton.dev/chains/v2",
+		BuildType:    "https://tekton.dev/attestations/chains/pipelinerun@v2",
+		InvocationID: "run-1",
+		StartedOn:    &started,
+		FinishedOn:   &finished,
+		Parameters:   map[string]any{"revision": "main"},
+		Materials: []Material{
+			{URI: "git+https://github.com/org/repo", Digest: map[string]string{"sha1": "abc"}},
+		},
+	}
+
+	cases := []struct {
+		name          string
+		predicateType string
+		statement     string
+		expected      any
+		err           string
+	}{
+		{
+			name:          "SLSA v0.2",
+			predicateType: PredicateSLSAProvenance,
+			statement: `{"predicate": {
+				"builder": {"id": "https://tekton.dev/chains/v2"},
+				"buildType": "https://tekton.dev/attestations/chains/pipelinerun@v2",
+				"invocation": {"parameters": {"revision": "main"}},
+				"metadata": {
+					"buildInvocationID": "run-1",
+					"buildStartedOn": "2024-05-01T10:00:00Z",
+					"buildFinishedOn": "2024-05-01T10:05:00Z"
+				},
+				"materials": [{"uri": "git+https://github.com/org/repo", "digest": {"sha1": "abc"}}]
+			}}`,
+			expected: expected,
+		},
+		{
+			name:          "SLSA v1",
+			predicateType: PredicateSLSAProvenanceV1,
+			statement: `{"predicate": {
+				"buildDefinition": {
+					"buildType": "https://tekton.dev/attestations/chains/pipelinerun@v2",
+					"externalParameters": {"revision": "main"},
+					"resolvedDependencies": [{"uri": "git+https://github.com/org/repo", "digest": {"sha1": "abc"}}]
+				},
+				"runDetails": {
+					"builder": {"id": "https://tekton.dev/chains/v2"},
+					"metadata": {
+						"invocationID": "run-1",
+						"startedOn": "2024-05-01T10:00:00Z",
+						"finishedOn": "2024-05-01T10:05:00Z"
+					}
+				}
+			}}`,
+			expected: expected,
+		},
+		{
+			name:          "SLSA v0.2 without metadata",
+			predicateType: PredicateSLSAProvenance,
+			statement:     `{"predicate": {"builder": {"id": "builder"}}}`,
+			expected:      Provenance{BuilderID: "builder", Materials: []Material{}},
+		},
+		{
+			name:          "unrecognized predicate",
+			predicateType: "https://example.com/custom/v1",
+			statement:     `{"predicate": {}}`,
+		},
+		{
+			name:          "malformed",
+			predicateType: PredicateSLSAProvenanceV1,
+			statement:     `{"predicate": []}`,
+			err:           "unable to normalize the https://slsa.dev/provenance/v1 attestation: json: cannot unmarshal array into Go struct field .predicate of type v1.ProvenancePredicate",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			normalized, err := Normalize(c.predicateType, []byte(c.statement))
+			if c.err != "" {
+				assert.EqualError(t, err, c.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, c.expected, normalized)
+		})
+	}
+}
+
+func TestRegisterNormalizer(t *testing.T) {
+	predicateType := "https://example.com/custom/v1"
+	t.Cleanup(func() {
+		normalizersMu.Lock()
+		defer normalizersMu.Unlock()
+		delete(normalizers, predicateType)
+	})
+
+	RegisterNormalizer(predicateType, func(statement []byte) (any, error) {
+		return map[string]any{"size": len(statement)}, nil
+	})
+
+	normalized, err := Normalize(predicateType, []byte(`{}`))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"size": 2}, normalized)
+}
+
+func TestNormalizationEnabled(t *testing.T) {
+	ctx := context.Background()
+	assert.False(t, NormalizationEnabled(ctx))
+	assert.True(t, NormalizationEnabled(WithNormalization(ctx, true)))
+}
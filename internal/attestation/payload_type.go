@@ -0,0 +1,68 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package attestation
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/sigstore/cosign/v2/pkg/oci"
+	"github.com/sigstore/cosign/v2/pkg/types"
+)
+
+// DefaultPayloadTypes are the DSSE payload types of the attestations accepted
+// when not configured otherwise, i.e. only in-toto statements.
+var DefaultPayloadTypes = []string{types.IntotoPayloadType}
+
+type contextKey string
+
+const payloadTypesKey contextKey = "ec.attestation.payload_types"
+
+// WithAllowedPayloadTypes returns a copy of the context configuring the DSSE
+// payload types of the attestations accepted.
+func WithAllowedPayloadTypes(ctx context.Context, payloadTypes []string) context.Context {
+	return context.WithValue(ctx, payloadTypesKey, payloadTypes)
+}
+
+// AllowedPayloadTypes returns the DSSE payload types of the attestations
+// accepted, the DefaultPayloadTypes unless configured in the context.
+func AllowedPayloadTypes(ctx context.Context) []string {
+	if payloadTypes, ok := ctx.Value(payloadTypesKey).([]string); ok && len(payloadTypes) > 0 {
+		return payloadTypes
+	}
+
+	return DefaultPayloadTypes
+}
+
+// ValidatePayloadType checks that the payload type declared by the DSSE
+// envelope of the attestation is one of the accepted payload types, so that
+// payloads of unexpected types are not parsed as in-toto statements.
+func ValidatePayloadType(ctx context.Context, sig oci.Signature) error {
+	payload, err := payloadFromSig(sig)
+	if err != nil {
+		return err
+	}
+
+	allowed := AllowedPayloadTypes(ctx)
+	if !slices.Contains(allowed, payload.PayloadType) {
+		return fmt.Errorf("the attestation payload type %q is not allowed, expecting one of: %s", payload.PayloadType, strings.Join(allowed, ", "))
+	}
+
+	return nil
+}
@@ -0,0 +1,86 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package attestation
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	ct "github.com/sigstore/cosign/v2/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestValidatePayloadType(t *testing.T) {
+	payload := encode(`{"_type": "https://in-toto.io/Statement/v0.1"}`)
+
+	cases := []struct {
+		name        string
+		payloadType string
+		allowed     []string
+		err         string
+	}{
+		{
+			name:        "in-toto by default",
+			payloadType: ct.IntotoPayloadType,
+		},
+		{
+			name:        "unexpected payload type",
+			payloadType: "application/json",
+			err:         `the attestation payload type "application/json" is not allowed, expecting one of: application/vnd.in-toto+json`,
+		},
+		{
+			name: "no payload type",
+			err:  `the attestation payload type "" is not allowed, expecting one of: application/vnd.in-toto+json`,
+		},
+		{
+			name:        "custom payload type allowed",
+			payloadType: "application/vnd.example+json",
+			allowed:     []string{ct.IntotoPayloadType, "application/vnd.example+json"},
+		},
+		{
+			name:        "in-toto not allowed",
+			payloadType: ct.IntotoPayloadType,
+			allowed:     []string{"application/vnd.example+json"},
+			err:         `the attestation payload type "application/vnd.in-toto+json" is not allowed, expecting one of: application/vnd.example+json`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			sig := mockSignature{&mock.Mock{}}
+			sig.On("MediaType").Return(types.MediaType(ct.DssePayloadType), nil)
+			sig.On("Uncompressed").Return(buffy(fmt.Sprintf(`{"payloadType": %q, "payload": %q}`, c.payloadType, payload)), nil)
+
+			ctx := context.Background()
+			if c.allowed != nil {
+				ctx = WithAllowedPayloadTypes(ctx, c.allowed)
+			}
+
+			err := ValidatePayloadType(ctx, sig)
+			if c.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, c.err)
+			}
+		})
+	}
+}
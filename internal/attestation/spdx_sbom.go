@@ -18,6 +18,7 @@ package attestation
 
 const (
 	PredicateSpdxDocument = "https://spdx.dev/Document"
+	PredicateCycloneDXBOM = "https://cyclonedx.org/bom"
 )
 
 // Todo: More code here in future probably, e.g. for unmarshaling an SPDX
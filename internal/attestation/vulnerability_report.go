@@ -0,0 +1,289 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package attestation
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// PredicateCosignVuln is the predicate type of the vulnerability scan results
+// attested by cosign attest --type vuln, and produced by trivy --format
+// cosign-vuln. The scan result is the native report of the scanner.
+const PredicateCosignVuln = "https://cosign.sigstore.dev/attestation/vuln/v1"
+
+// Normalized vulnerability severities
+const (
+	SeverityCritical = "critical"
+	SeverityHigh     = "high"
+	SeverityMedium   = "medium"
+	SeverityLow      = "low"
+	SeverityNone     = "none"
+	SeverityUnknown  = "unknown"
+)
+
+// Vulnerability is a vulnerability found in a package of the image.
+type Vulnerability struct {
+	ID           string `json:"id"`
+	Package      string `json:"package,omitempty"`
+	Version      string `json:"version,omitempty"`
+	FixedVersion string `json:"fixedVersion,omitempty"`
+	Severity     string `json:"severity"`
+}
+
+// VulnerabilityReport is the normalized structure of the vulnerability scan
+// results of the supported formats. The summary holds the number of the
+// vulnerabilities of each severity, including the severities without any.
+type VulnerabilityReport struct {
+	Scanner         string          `json:"scanner,omitempty"`
+	Vulnerabilities []Vulnerability `json:"vulnerabilities"`
+	Summary         map[string]int  `json:"summary"`
+}
+
+// ParseVulnerabilityReport parses the vulnerability scan results of the
+// statement into the normalized structure. The results of cosign vuln
+// attestations of Trivy and Grype, and CycloneDX VDRs, i.e. CycloneDX BOMs
+// with vulnerabilities, are supported. For other statements it returns nil.
+func ParseVulnerabilityReport(predicateType string, statement []byte) (*VulnerabilityReport, error) {
+	var report *VulnerabilityReport
+	var err error
+	switch predicateType {
+	case PredicateCosignVuln:
+		report, err = parseCosignVuln(statement)
+	case PredicateCycloneDXBOM:
+		report, err = parseCycloneDXVDR(statement)
+	default:
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse the vulnerability report of the %s attestation: %w", predicateType, err)
+	}
+	if report == nil {
+		return nil, nil
+	}
+
+	report.Summary = map[string]int{
+		SeverityCritical: 0,
+		SeverityHigh:     0,
+		SeverityMedium:   0,
+		SeverityLow:      0,
+		SeverityNone:     0,
+		SeverityUnknown:  0,
+	}
+	for _, v := range report.Vulnerabilities {
+		report.Summary[v.Severity]++
+	}
+
+	return report, nil
+}
+
+type cosignVulnStatement struct {
+	Predicate struct {
+		Scanner struct {
+			URI    string          `json:"uri"`
+			Result json.RawMessage `json:"result"`
+		} `json:"scanner"`
+	} `json:"predicate"`
+}
+
+// trivyResult holds the parts of the Trivy JSON report listing the
+// vulnerabilities
+type trivyResult struct {
+	Results []struct {
+		Vulnerabilities []struct {
+			VulnerabilityID  string `json:"VulnerabilityID"`
+			PkgName          string `json:"PkgName"`
+			InstalledVersion string `json:"InstalledVersion"`
+			FixedVersion     string `json:"FixedVersion"`
+			Severity         string `json:"Severity"`
+		} `json:"Vulnerabilities"`
+	} `json:"Results"`
+}
+
+// grypeResult holds the parts of the Grype JSON report listing the
+// vulnerabilities
+type grypeResult struct {
+	Matches []struct {
+		Vulnerability struct {
+			ID       string `json:"id"`
+			Severity string `json:"severity"`
+			Fix      struct {
+				Versions []string `json:"versions"`
+			} `json:"fix"`
+		} `json:"vulnerability"`
+		Artifact struct {
+			Name    string `json:"name"`
+			Version string `json:"version"`
+		} `json:"artifact"`
+	} `json:"matches"`
+}
+
+func parseCosignVuln(statement []byte) (*VulnerabilityReport, error) {
+	var s cosignVulnStatement
+	if err := json.Unmarshal(statement, &s); err != nil {
+		return nil, err
+	}
+
+	var result map[string]json.RawMessage
+	if err := json.Unmarshal(s.Predicate.Scanner.Result, &result); err != nil {
+		return nil, nil
+	}
+
+	report := VulnerabilityReport{Scanner: s.Predicate.Scanner.URI, Vulnerabilities: []Vulnerability{}}
+	switch {
+	case result["Results"] != nil || result["SchemaVersion"] != nil:
+		var trivy trivyResult
+		if err := json.Unmarshal(s.Predicate.Scanner.Result, &trivy); err != nil {
+			return nil, err
+		}
+		for _, r := range trivy.Results {
+			for _, v := range r.Vulnerabilities {
+				report.Vulnerabilities = append(report.Vulnerabilities, Vulnerability{
+					ID:           v.VulnerabilityID,
+					Package:      v.PkgName,
+					Version:      v.InstalledVersion,
+					FixedVersion: v.FixedVersion,
+					Severity:     normalizeSeverity(v.Severity),
+				})
+			}
+		}
+	case result["matches"] != nil:
+		var grype grypeResult
+		if err := json.Unmarshal(s.Predicate.Scanner.Result, &grype); err != nil {
+			return nil, err
+		}
+		for _, m := range grype.Matches {
+			report.Vulnerabilities = append(report.Vulnerabilities, Vulnerability{
+				ID:           m.Vulnerability.ID,
+				Package:      m.Artifact.Name,
+				Version:      m.Artifact.Version,
+				FixedVersion: strings.Join(m.Vulnerability.Fix.Versions, ", "),
+				Severity:     normalizeSeverity(m.Vulnerability.Severity),
+			})
+		}
+	default:
+		return nil, nil
+	}
+
+	return &report, nil
+}
+
+// cycloneDXVDR holds the parts of a CycloneDX BOM listing the vulnerabilities
+// and the components they affect
+type cycloneDXVDR struct {
+	Predicate struct {
+		Components []struct {
+			BOMRef  string `json:"bom-ref"`
+			Name    string `json:"name"`
+			Version string `json:"version"`
+		} `json:"components"`
+		Vulnerabilities *[]struct {
+			ID      string `json:"id"`
+			Ratings []struct {
+				Severity string `json:"severity"`
+			} `json:"ratings"`
+			Affects []struct {
+				Ref string `json:"ref"`
+			} `json:"affects"`
+		} `json:"vulnerabilities"`
+	} `json:"predicate"`
+}
+
+func parseCycloneDXVDR(statement []byte) (*VulnerabilityReport, error) {
+	var s cycloneDXVDR
+	if err := json.Unmarshal(statement, &s); err != nil {
+		return nil, err
+	}
+
+	// A BOM without vulnerabilities is an SBOM
+	if s.Predicate.Vulnerabilities == nil {
+		return nil, nil
+	}
+
+	type component struct{ name, version string }
+	components := make(map[string]component, len(s.Predicate.Components))
+	for _, c := range s.Predicate.Components {
+		components[c.BOMRef] = component{c.Name, c.Version}
+	}
+
+	report := VulnerabilityReport{Vulnerabilities: []Vulnerability{}}
+	for _, v := range *s.Predicate.Vulnerabilities {
+		// The highest of the ratings is the severity of the vulnerability
+		severity := SeverityUnknown
+		for _, r := range v.Ratings {
+			if rated := normalizeSeverity(r.Severity); severityRank(rated) > severityRank(severity) {
+				severity = rated
+			}
+		}
+
+		if len(v.Affects) == 0 {
+			report.Vulnerabilities = append(report.Vulnerabilities, Vulnerability{ID: v.ID, Severity: severity})
+			continue
+		}
+
+		for _, a := range v.Affects {
+			c, ok := components[a.Ref]
+			if !ok {
+				c = component{name: a.Ref}
+			}
+			report.Vulnerabilities = append(report.Vulnerabilities, Vulnerability{
+				ID:       v.ID,
+				Package:  c.name,
+				Version:  c.version,
+				Severity: severity,
+			})
+		}
+	}
+
+	return &report, nil
+}
+
+// normalizeSeverity maps the severities of the supported formats onto the
+// normalized severities
+func normalizeSeverity(severity string) string {
+	switch s := strings.ToLower(severity); s {
+	case SeverityCritical, SeverityHigh, SeverityMedium, SeverityLow, SeverityNone:
+		return s
+	case "moderate":
+		return SeverityMedium
+	case "negligible":
+		return SeverityLow
+	case "info":
+		return SeverityNone
+	default:
+		return SeverityUnknown
+	}
+}
+
+func severityRank(severity string) int {
+	switch severity {
+	case SeverityCritical:
+		return 5
+	case SeverityHigh:
+		return 4
+	case SeverityMedium:
+		return 3
+	case SeverityLow:
+		return 2
+	case SeverityNone:
+		return 1
+	default:
+		return 0
+	}
+}
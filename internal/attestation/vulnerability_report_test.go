@@ -0,0 +1,125 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package attestation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func summary(counts map[string]int) map[string]int {
+	s := map[string]int{"critical": 0, "high": 0, "medium": 0, "low": 0, "none": 0, "unknown": 0}
+	for k, v := range counts {
+		s[k] = v
+	}
+	return s
+}
+
+func TestParseVulnerabilityReport(t *testing.T) {
+	cases := []struct {
+		name          string
+		predicateType string
+		statement     string
+		expected      *VulnerabilityReport
+		err           string
+	}{
+		{
+			name:          "trivy",
+			predicateType: PredicateCosignVuln,
+			statement: `{"predicate":{"scanner":{"uri":"pkg:github/aquasecurity/trivy@0.50.0","result":{
+				"SchemaVersion":2,"Results":[{"Target":"image","Vulnerabilities":[
+					{"VulnerabilityID":"CVE-2024-1","PkgName":"openssl","InstalledVersion":"3.0.1","FixedVersion":"3.0.2","Severity":"CRITICAL"},
+					{"VulnerabilityID":"CVE-2024-2","PkgName":"zlib","InstalledVersion":"1.2","Severity":"LOW"}
+				]},{"Target":"app"}]}}}}`,
+			expected: &VulnerabilityReport{
+				Scanner: "pkg:github/aquasecurity/trivy@0.50.0",
+				Vulnerabilities: []Vulnerability{
+					{ID: "CVE-2024-1", Package: "openssl", Version: "3.0.1", FixedVersion: "3.0.2", Severity: "critical"},
+					{ID: "CVE-2024-2", Package: "zlib", Version: "1.2", Severity: "low"},
+				},
+				Summary: summary(map[string]int{"critical": 1, "low": 1}),
+			},
+		},
+		{
+			name:          "grype",
+			predicateType: PredicateCosignVuln,
+			statement: `{"predicate":{"scanner":{"uri":"pkg:github/anchore/grype@0.74.0","result":{"matches":[
+				{"vulnerability":{"id":"CVE-2024-3","severity":"Negligible","fix":{"versions":["2.0"]}},"artifact":{"name":"bash","version":"1.0"}}
+			]}}}}`,
+			expected: &VulnerabilityReport{
+				Scanner:         "pkg:github/anchore/grype@0.74.0",
+				Vulnerabilities: []Vulnerability{{ID: "CVE-2024-3", Package: "bash", Version: "1.0", FixedVersion: "2.0", Severity: "low"}},
+				Summary:         summary(map[string]int{"low": 1}),
+			},
+		},
+		{
+			name:          "unknown scanner",
+			predicateType: PredicateCosignVuln,
+			statement:     `{"predicate":{"scanner":{"uri":"pkg:github/other/scanner","result":{"findings":[]}}}}`,
+		},
+		{
+			name:          "CycloneDX VDR",
+			predicateType: PredicateCycloneDXBOM,
+			statement: `{"predicate":{
+				"components":[{"bom-ref":"pkg:rpm/openssl@3.0.1","name":"openssl","version":"3.0.1"}],
+				"vulnerabilities":[
+					{"id":"CVE-2024-4","ratings":[{"severity":"medium"},{"severity":"high"}],"affects":[{"ref":"pkg:rpm/openssl@3.0.1"},{"ref":"pkg:rpm/other@1"}]},
+					{"id":"CVE-2024-5"}
+				]}}`,
+			expected: &VulnerabilityReport{
+				Vulnerabilities: []Vulnerability{
+					{ID: "CVE-2024-4", Package: "openssl", Version: "3.0.1", Severity: "high"},
+					{ID: "CVE-2024-4", Package: "pkg:rpm/other@1", Severity: "high"},
+					{ID: "CVE-2024-5", Severity: "unknown"},
+				},
+				Summary: summary(map[string]int{"high": 2, "unknown": 1}),
+			},
+		},
+		{
+			name:          "CycloneDX SBOM",
+			predicateType: PredicateCycloneDXBOM,
+			statement:     `{"predicate":{"components":[{"bom-ref":"pkg:rpm/openssl@3.0.1"}]}}`,
+		},
+		{
+			name:          "other predicate type",
+			predicateType: PredicateSpdxDocument,
+			statement:     `{"predicate":{}}`,
+		},
+		{
+			name:          "malformed",
+			predicateType: PredicateCycloneDXBOM,
+			statement:     `{"predicate":{"vulnerabilities":{}}}`,
+			err:           "unable to parse the vulnerability report of the https://cyclonedx.org/bom attestation",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			report, err := ParseVulnerabilityReport(c.predicateType, []byte(c.statement))
+			if c.err != "" {
+				assert.ErrorContains(t, err, c.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, c.expected, report)
+		})
+	}
+}
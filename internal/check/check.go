@@ -0,0 +1,306 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package check probes the subsystems ec depends on, e.g. the registries, the
+// transparency log or the policy sources, to troubleshoot new setups without
+// validating any image.
+package check
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"text/tabwriter"
+
+	ecc "github.com/enterprise-contract/enterprise-contract-controller/api/v1alpha1"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"github.com/hashicorp/go-multierror"
+	"github.com/sigstore/cosign/v2/cmd/cosign/cli/fulcio"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/enterprise-contract/ec-cli/internal/format"
+	"github.com/enterprise-contract/ec-cli/internal/policy"
+	"github.com/enterprise-contract/ec-cli/internal/policy/source"
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+const (
+	Text = "text"
+	JSON = "json"
+)
+
+// Formats are the formats the report can be written in.
+var Formats = []string{Text, JSON}
+
+// DefaultRekorURL is the transparency log probed when the policy does not
+// configure one.
+const DefaultRekorURL = "https://rekor.sigstore.dev"
+
+// Probe is the outcome of probing a subsystem.
+type Probe struct {
+	Subsystem string `json:"subsystem"`
+	Target    string `json:"target,omitempty"`
+	Passed    bool   `json:"passed"`
+	// Skipped is true if the subsystem is not used with the given
+	// configuration, a skipped probe passes
+	Skipped bool   `json:"skipped,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+func passed(subsystem, target, message string) Probe {
+	return Probe{Subsystem: subsystem, Target: target, Passed: true, Message: message}
+}
+
+func failed(subsystem, target string, err error) Probe {
+	return Probe{Subsystem: subsystem, Target: target, Message: err.Error()}
+}
+
+func skipped(subsystem, message string) Probe {
+	return Probe{Subsystem: subsystem, Passed: true, Skipped: true, Message: message}
+}
+
+// Report holds the outcome of all the probes.
+type Report struct {
+	Probes []Probe `json:"probes"`
+}
+
+// Failed returns the number of probes that failed.
+func (r Report) Failed() int {
+	failed := 0
+	for _, p := range r.Probes {
+		if !p.Passed {
+			failed++
+		}
+	}
+
+	return failed
+}
+
+// WriteAll writes the report to all the given targets, in one of the Formats.
+// The report is written as text to the standard output by default.
+func (r Report) WriteAll(targets []string, p format.TargetParser) (allErrors error) {
+	if len(targets) == 0 {
+		targets = append(targets, Text)
+	}
+
+	parsed, err := p.ParseAll(targets)
+	if err != nil {
+		allErrors = multierror.Append(allErrors, err)
+	}
+
+	for _, target := range parsed {
+		data, err := r.toFormat(target.Format)
+		if err != nil {
+			allErrors = multierror.Append(allErrors, err)
+			continue
+		}
+
+		if !bytes.HasSuffix(data, []byte{'\n'}) {
+			data = append(data, "\n"...)
+		}
+
+		write := target.Write
+		if target.Format == JSON {
+			write = target.WriteJSON
+		}
+		if _, err := write(data); err != nil {
+			allErrors = multierror.Append(allErrors, err)
+		}
+	}
+	return
+}
+
+func (r Report) toFormat(f string) ([]byte, error) {
+	switch f {
+	case JSON:
+		return json.Marshal(r)
+	case Text:
+		var buf bytes.Buffer
+		w := tabwriter.NewWriter(&buf, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "SUBSYSTEM\tTARGET\tRESULT\tMESSAGE")
+		for _, p := range r.Probes {
+			result := "FAIL"
+			switch {
+			case p.Skipped:
+				result = "SKIP"
+			case p.Passed:
+				result = "PASS"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", p.Subsystem, p.Target, result, strings.ReplaceAll(p.Message, "\n", " "))
+		}
+		if err := w.Flush(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	default:
+		return nil, fmt.Errorf("%q is not a valid check format, expecting one of: %s", f, strings.Join(Formats, ", "))
+	}
+}
+
+// Options configures the probes.
+type Options struct {
+	Policy policy.Options
+	// PolicyName names the policy configuration in the report, e.g. the file
+	// name as given, as the policy reference can hold the policy configuration
+	// itself
+	PolicyName string
+	// Registries are the registry hosts to probe, e.g. quay.io
+	Registries []string
+}
+
+// Run runs all the probes. The policy sources, the keys, the transparency log
+// and the Fulcio roots are probed only if the policy configuration loads.
+func Run(ctx context.Context, opts Options) Report {
+	var r Report
+
+	for _, reg := range opts.Registries {
+		r.Probes = append(r.Probes, probeRegistry(ctx, reg))
+	}
+
+	inert, err := policy.NewInertPolicy(ctx, opts.Policy.PolicyRef)
+	if err != nil {
+		r.Probes = append(r.Probes, failed("policy", opts.PolicyName, err))
+		return r
+	}
+	r.Probes = append(r.Probes, passed("policy", opts.PolicyName, "loaded"))
+
+	r.Probes = append(r.Probes, probeSources(ctx, inert.Spec())...)
+
+	p, err := policy.NewPolicy(ctx, opts.Policy)
+	if err != nil {
+		r.Probes = append(r.Probes, failed("keys", "", err))
+		return r
+	}
+	if p.Keyless() {
+		r.Probes = append(r.Probes, passed("keys", "", "keyless verification"))
+		r.Probes = append(r.Probes, probeFulcio())
+	} else {
+		r.Probes = append(r.Probes, passed("keys", "", "public key loaded"))
+		r.Probes = append(r.Probes, skipped("fulcio", "not used when verifying with a public key"))
+	}
+
+	if opts.Policy.IgnoreRekor {
+		r.Probes = append(r.Probes, skipped("rekor", "--ignore-rekor is set"))
+	} else {
+		rekorURL := p.Spec().RekorUrl
+		if rekorURL == "" {
+			rekorURL = DefaultRekorURL
+		}
+		r.Probes = append(r.Probes, probeRekor(ctx, rekorURL))
+	}
+
+	return r
+}
+
+// probeRegistry checks that the registry API of the host can be reached,
+// authenticating with the credentials of the host, if any.
+func probeRegistry(ctx context.Context, host string) Probe {
+	reg, err := name.NewRegistry(host)
+	if err != nil {
+		return failed("registry", host, err)
+	}
+
+	auth, err := authn.DefaultKeychain.Resolve(reg)
+	if err != nil {
+		return failed("registry", host, err)
+	}
+
+	if _, err := transport.NewWithContext(ctx, reg, auth, http.DefaultTransport, nil); err != nil {
+		return failed("registry", host, err)
+	}
+
+	return passed("registry", host, "reachable")
+}
+
+// probeSources fetches each of the policy and data sources into a temporary
+// working directory.
+func probeSources(ctx context.Context, spec ecc.EnterpriseContractPolicySpec) []Probe {
+	fs := utils.FS(ctx)
+	workDir, err := utils.CreateWorkDir(fs)
+	if err != nil {
+		return []Probe{failed("source", "", err)}
+	}
+	defer func() {
+		if err := fs.RemoveAll(workDir); err != nil {
+			log.Debugf("Unable to remove the working directory %s: %v", workDir, err)
+		}
+	}()
+
+	var probes []Probe
+	for _, g := range spec.Sources {
+		var sources []source.PolicySource
+		for _, u := range g.Policy {
+			sources = append(sources, &source.PolicyUrl{Url: u, Kind: source.PolicyKind})
+		}
+		for _, u := range g.Data {
+			sources = append(sources, &source.PolicyUrl{Url: u, Kind: source.DataKind})
+		}
+
+		for _, s := range sources {
+			if _, err := s.GetPolicy(ctx, workDir, false); err != nil {
+				probes = append(probes, failed("source", s.PolicyUrl(), err))
+				continue
+			}
+			probes = append(probes, passed("source", s.PolicyUrl(), "fetched"))
+		}
+	}
+
+	if len(probes) == 0 {
+		probes = append(probes, skipped("source", "the policy has no sources"))
+	}
+
+	return probes
+}
+
+// probeFulcio checks that the Fulcio root and intermediate certificates, used
+// to verify the signing certificates, can be loaded.
+func probeFulcio() Probe {
+	if _, err := fulcio.GetRoots(); err != nil {
+		return failed("fulcio", "", err)
+	}
+
+	if _, err := fulcio.GetIntermediates(); err != nil {
+		return failed("fulcio", "", err)
+	}
+
+	return passed("fulcio", "", "root certificates loaded")
+}
+
+// probeRekor checks that the log info of the Rekor transparency log can be
+// fetched.
+func probeRekor(ctx context.Context, url string) Probe {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(url, "/")+"/api/v1/log", nil)
+	if err != nil {
+		return failed("rekor", url, err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return failed("rekor", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return failed("rekor", url, fmt.Errorf("unexpected response status: %s", resp.Status))
+	}
+
+	return passed("rekor", url, "reachable")
+}
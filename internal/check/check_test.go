@@ -0,0 +1,96 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package check
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/format"
+)
+
+func TestProbeRekor(t *testing.T) {
+	rekor := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/log" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{"treeSize": 1}`))
+	}))
+	defer rekor.Close()
+
+	assert.Equal(t, Probe{Subsystem: "rekor", Target: rekor.URL, Passed: true, Message: "reachable"}, probeRekor(context.Background(), rekor.URL))
+	assert.Equal(t, Probe{Subsystem: "rekor", Target: rekor.URL + "/nope", Message: "unexpected response status: 404 Not Found"}, probeRekor(context.Background(), rekor.URL+"/nope"))
+}
+
+func TestProbeRegistry(t *testing.T) {
+	registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer registry.Close()
+
+	host := strings.TrimPrefix(registry.URL, "http://")
+	assert.Equal(t, Probe{Subsystem: "registry", Target: host, Passed: true, Message: "reachable"}, probeRegistry(context.Background(), host))
+
+	registry.Close()
+	p := probeRegistry(context.Background(), host)
+	assert.False(t, p.Passed)
+	assert.Contains(t, p.Message, "connection refused")
+}
+
+func TestReportWriteAll(t *testing.T) {
+	report := Report{Probes: []Probe{
+		{Subsystem: "policy", Target: "policy.yaml", Passed: true, Message: "loaded"},
+		{Subsystem: "source", Target: "git::https://example.com/policy", Message: "unable to clone"},
+		{Subsystem: "fulcio", Passed: true, Skipped: true, Message: "not used when verifying with a public key"},
+	}}
+	assert.Equal(t, 1, report.Failed())
+
+	fs := afero.NewMemMapFs()
+	var out bytes.Buffer
+	p := format.NewTargetParser(Text, format.Options{}, &out, fs)
+
+	require.NoError(t, report.WriteAll([]string{"text", "json=check.json"}, p))
+	assert.Equal(t, `SUBSYSTEM  TARGET                           RESULT  MESSAGE
+policy     policy.yaml                      PASS    loaded
+source     git::https://example.com/policy  FAIL    unable to clone
+fulcio                                      SKIP    not used when verifying with a public key
+`, out.String())
+
+	j, err := afero.ReadFile(fs, "check.json")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"probes": [
+		{"subsystem": "policy", "target": "policy.yaml", "passed": true, "message": "loaded"},
+		{"subsystem": "source", "target": "git::https://example.com/policy", "passed": false, "message": "unable to clone"},
+		{"subsystem": "fulcio", "passed": true, "skipped": true, "message": "not used when verifying with a public key"}
+	]}`, string(j))
+
+	assert.EqualError(t, report.WriteAll([]string{"yaml"}, p), `1 error occurred:
+	* "yaml" is not a valid check format, expecting one of: text, json
+
+`)
+}
@@ -0,0 +1,243 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package completion provides the shell completion of flag values, e.g. of
+// the output formats or of the collections of the policy sources.
+package completion
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+
+	"github.com/enterprise-contract/ec-cli/internal/opa"
+	"github.com/enterprise-contract/ec-cli/internal/opa/rule"
+	"github.com/enterprise-contract/ec-cli/internal/policy/source"
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+// cacheTTL is how long the rules discovered in a remote policy source are
+// used for completion before the source is fetched again
+const cacheTTL = time.Hour
+
+// Func completes the value of a flag, see cobra.Command.RegisterFlagCompletionFunc
+type Func func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective)
+
+// Register registers the completion of the flag's value, panicking if the
+// flag is not defined, same as with cobra.Command.MarkFlagRequired.
+func Register(cmd *cobra.Command, flag string, f Func) {
+	if err := cmd.RegisterFlagCompletionFunc(flag, f); err != nil {
+		panic(err)
+	}
+}
+
+// Formats completes the output formats. The output formats given as
+// <format>=<path> complete the path once the format is given.
+func Formats(formats []string) Func {
+	return func(_ *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if strings.Contains(toComplete, "=") {
+			return nil, cobra.ShellCompDirectiveDefault
+		}
+
+		return formats, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// Sources returns the policy source URLs to introspect for the completion,
+// typically read from the other flags of the command.
+type Sources func(cmd *cobra.Command) []string
+
+// Collections completes the rule collections found in the policy sources.
+func Collections(sources Sources) Func {
+	return rules(sources, func(r discovered) []string { return r.Collections })
+}
+
+// Namespaces completes the packages, i.e. the namespaces of the rules, found
+// in the policy sources.
+func Namespaces(sources Sources) Func {
+	return rules(sources, func(r discovered) []string { return r.Namespaces })
+}
+
+// discovered are the values found in a policy source
+type discovered struct {
+	Collections []string  `json:"collections"`
+	Namespaces  []string  `json:"namespaces"`
+	Time        time.Time `json:"time"`
+}
+
+func rules(sources Sources, values func(discovered) []string) Func {
+	return func(cmd *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		ctx := cmd.Context()
+		if ctx == nil {
+			ctx = context.Background()
+		}
+
+		seen := map[string]bool{}
+		completions := []string{}
+		for _, url := range sources(cmd) {
+			d, err := discover(ctx, url)
+			if err != nil {
+				log.Debugf("Unable to discover the rules of %s for completion: %v", url, err)
+				continue
+			}
+			for _, v := range values(d) {
+				if !seen[v] {
+					seen[v] = true
+					completions = append(completions, v)
+				}
+			}
+		}
+		sort.Strings(completions)
+
+		return completions, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// discover finds the collections and namespaces of the rules of the policy
+// source. Local directories are inspected in place, the other sources are
+// fetched at most once per cacheTTL.
+func discover(ctx context.Context, url string) (discovered, error) {
+	fs := utils.FS(ctx)
+
+	local := false
+	if info, err := fs.Stat(url); err == nil && info.IsDir() {
+		local = true
+	}
+
+	cacheFile := ""
+	if !local {
+		cacheFile = cachePath(url)
+		if d, ok := readCache(fs, cacheFile); ok {
+			return d, nil
+		}
+	}
+
+	var dir string
+	if local {
+		dir = url
+	} else {
+		workDir, err := utils.CreateWorkDir(fs)
+		if err != nil {
+			return discovered{}, err
+		}
+		defer utils.CleanupWorkDir(fs, workDir)
+
+		s := &source.PolicyUrl{Url: url, Kind: source.PolicyKind}
+		if dir, err = s.GetPolicy(ctx, workDir, false); err != nil {
+			return discovered{}, err
+		}
+	}
+
+	annotations, err := opa.InspectDir(fs, dir)
+	if err != nil {
+		return discovered{}, err
+	}
+
+	collections := map[string]bool{}
+	namespaces := map[string]bool{}
+	for _, a := range annotations {
+		if a.Annotations == nil {
+			continue
+		}
+		info := rule.RuleInfo(a)
+		for _, c := range info.Collections {
+			collections[c] = true
+		}
+		if info.Package != "" {
+			namespaces[info.Package] = true
+		}
+	}
+
+	d := discovered{Collections: keys(collections), Namespaces: keys(namespaces), Time: time.Now()}
+	if cacheFile != "" {
+		writeCache(fs, cacheFile, d)
+	}
+
+	return d, nil
+}
+
+func keys(m map[string]bool) []string {
+	k := make([]string, 0, len(m))
+	for v := range m {
+		k = append(k, v)
+	}
+	sort.Strings(k)
+
+	return k
+}
+
+// cachePath returns the path of the file caching the values discovered in the
+// policy source, empty if caching is disabled or there is no user cache
+// directory.
+func cachePath(url string) string {
+	// if a value was set and it is parsed as false, turn the cache off
+	if v, err := strconv.ParseBool(os.Getenv("EC_CACHE")); err == nil && !v {
+		return ""
+	}
+
+	userCache, err := os.UserCacheDir()
+	if err != nil {
+		log.Debug("unable to find user cache directory")
+		return ""
+	}
+
+	return path.Join(userCache, "ec", "completion", fmt.Sprintf("%x.json", sha256.Sum256([]byte(url))))
+}
+
+func readCache(fs afero.Fs, file string) (discovered, bool) {
+	if file == "" {
+		return discovered{}, false
+	}
+
+	content, err := afero.ReadFile(fs, file)
+	if err != nil {
+		return discovered{}, false
+	}
+
+	var d discovered
+	if err := json.Unmarshal(content, &d); err != nil || time.Since(d.Time) > cacheTTL {
+		return discovered{}, false
+	}
+
+	return d, true
+}
+
+func writeCache(fs afero.Fs, file string, d discovered) {
+	content, err := json.Marshal(d)
+	if err != nil {
+		return
+	}
+
+	if err := fs.MkdirAll(path.Dir(file), 0700); err != nil {
+		log.Debugf("unable to create the completion cache directory: %v", err)
+		return
+	}
+
+	if err := afero.WriteFile(fs, file, content, 0600); err != nil {
+		log.Debugf("unable to write the completion cache: %v", err)
+	}
+}
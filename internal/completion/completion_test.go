@@ -0,0 +1,140 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package completion
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+const pkgRules = `package policy.release.pkg
+
+import rego.v1
+
+# METADATA
+# title: Rule
+# custom:
+#   short_name: rule
+#   collections:
+#   - minimal
+#   - redhat
+deny contains result if {
+	false
+	result := "never"
+}
+`
+
+const otherRules = `package other
+
+import rego.v1
+
+# METADATA
+# title: Other
+# custom:
+#   short_name: other
+#   collections:
+#   - minimal
+warn contains result if {
+	false
+	result := "never"
+}
+`
+
+func command(t *testing.T, fs afero.Fs) *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.SetContext(utils.WithFS(context.Background(), fs))
+
+	return cmd
+}
+
+func TestFormats(t *testing.T) {
+	f := Formats([]string{"json", "yaml"})
+
+	completions, directive := f(nil, nil, "")
+	assert.Equal(t, []string{"json", "yaml"}, completions)
+	assert.Equal(t, cobra.ShellCompDirectiveNoFileComp, directive)
+
+	completions, directive = f(nil, nil, "json=")
+	assert.Nil(t, completions)
+	assert.Equal(t, cobra.ShellCompDirectiveDefault, directive, "the file path is completed")
+}
+
+func TestLocalSources(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/policy/a/rules.rego", []byte(pkgRules), 0644))
+	require.NoError(t, afero.WriteFile(fs, "/policy/b/rules.rego", []byte(otherRules), 0644))
+
+	sources := func(*cobra.Command) []string { return []string{"/policy/a", "/policy/b", "/missing"} }
+
+	completions, directive := Collections(sources)(command(t, fs), nil, "")
+	assert.Equal(t, []string{"minimal", "redhat"}, completions)
+	assert.Equal(t, cobra.ShellCompDirectiveNoFileComp, directive)
+
+	completions, _ = Namespaces(sources)(command(t, fs), nil, "")
+	assert.Equal(t, []string{"other", "policy.release.pkg"}, completions)
+}
+
+func TestRemoteSourcesCached(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", "/cache")
+	t.Setenv("EC_CACHE", "")
+
+	url := "quay.io/enterprise-contract/ec-release-policy:latest"
+	fs := afero.NewMemMapFs()
+	cacheFile := cachePath(url)
+	require.NotEmpty(t, cacheFile)
+	writeCache(fs, cacheFile, discovered{Collections: []string{"cached"}, Namespaces: []string{"cached.pkg"}, Time: time.Now()})
+
+	sources := func(*cobra.Command) []string { return []string{url} }
+
+	completions, _ := Collections(sources)(command(t, fs), nil, "")
+	assert.Equal(t, []string{"cached"}, completions, "the source is not fetched")
+
+	completions, _ = Namespaces(sources)(command(t, fs), nil, "")
+	assert.Equal(t, []string{"cached.pkg"}, completions)
+}
+
+func TestCache(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	_, ok := readCache(fs, "/cache/missing.json")
+	assert.False(t, ok)
+
+	_, ok = readCache(fs, "")
+	assert.False(t, ok)
+
+	writeCache(fs, "/cache/fresh.json", discovered{Collections: []string{"a"}, Time: time.Now()})
+	d, ok := readCache(fs, "/cache/fresh.json")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"a"}, d.Collections)
+
+	writeCache(fs, "/cache/stale.json", discovered{Collections: []string{"a"}, Time: time.Now().Add(-2 * cacheTTL)})
+	_, ok = readCache(fs, "/cache/stale.json")
+	assert.False(t, ok, "stale entries are not used")
+
+	t.Setenv("EC_CACHE", "false")
+	assert.Empty(t, cachePath("example.com/policy"))
+}
@@ -103,14 +103,15 @@ func (r *Report) Write(targetName string, p format.TargetParser) error {
 		if data, err = json.Marshal(r); err != nil {
 			return err
 		}
+		_, err = target.WriteJSON(data)
 	case YAMLReport:
 		if data, err = yaml.Marshal(r); err != nil {
 			return err
 		}
+		_, err = target.Write(data)
 	default:
 		return fmt.Errorf("unexpected report format: %s", target.Format)
 	}
 
-	_, err = target.Write(data)
 	return err
 }
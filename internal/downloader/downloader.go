@@ -58,7 +58,7 @@ func Download(ctx context.Context, destDir string, sourceUrl string, showMsg boo
 		return fmt.Errorf("attempting to download from insecure source: %s", sourceUrl)
 	}
 
-	msg := fmt.Sprintf("Downloading %s to %s", sourceUrl, destDir)
+	msg := fmt.Sprintf("Downloading %s to %s", redactCredentials(sourceUrl), destDir)
 	log.Debug(msg)
 	if showMsg {
 		fmt.Println(msg)
@@ -101,6 +101,14 @@ func Download(ctx context.Context, destDir string, sourceUrl string, showMsg boo
 	return
 }
 
+// matches the password, or token, in the user info of a URL
+var credentials = regexp.MustCompile("(://[^/@:]*):[^/@]*@")
+
+// redactCredentials hides the password, or token, given in the URL.
+func redactCredentials(url string) string {
+	return credentials.ReplaceAllString(url, "$1:***@")
+}
+
 // matches insecure protocols, such as `git::http://...`
 var insecure = regexp.MustCompile("^[A-Za-z0-9]*::http:")
 
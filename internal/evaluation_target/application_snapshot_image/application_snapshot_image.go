@@ -19,16 +19,21 @@ package application_snapshot_image
 import (
 	"bytes"
 	"context"
+	"crypto"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
 	"path"
+	"sort"
+	"time"
 
 	"github.com/google/go-containerregistry/pkg/name"
 	app "github.com/konflux-ci/application-api/api/v1alpha1"
 	"github.com/santhosh-tekuri/jsonschema/v5"
 	"github.com/sigstore/cosign/v2/pkg/cosign"
+	cosignoci "github.com/sigstore/cosign/v2/pkg/oci"
+	sigstoreSig "github.com/sigstore/sigstore/pkg/signature"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/afero"
 
@@ -52,14 +57,37 @@ type ApplicationSnapshotImage struct {
 	reference        name.Reference
 	checkOpts        cosign.CheckOpts
 	signatures       []signature.EntitySignature
+	signingKeys      []signature.SigningKey
+	identities       []signature.Identity
+	signingTimes     []signature.SigningTime
 	configJSON       json.RawMessage
 	parentConfigJSON json.RawMessage
 	parentRef        name.Reference
 	attestations     []attestation.Attestation
+	logEntryTimes    []time.Time
 	Evaluators       []evaluator.Evaluator
 	files            map[string]json.RawMessage
 	component        app.SnapshotComponent
 	snapshot         app.SnapshotSpec
+	// attestationsPresent is included in the policy input only when set, see
+	// SetAttestationsPresent
+	attestationsPresent *bool
+	// signatureRef is the signature artifact the signatures of the image are
+	// verified from, when known, see SetSignatureReference
+	signatureRef *name.Digest
+}
+
+// SetAttestationsPresent includes in the policy input whether the image has
+// any attestations, allowing the policy rules depending on the attestations
+// to be skipped for images without.
+func (a *ApplicationSnapshotImage) SetAttestationsPresent(present bool) {
+	a.attestationsPresent = &present
+}
+
+// SetSignatureReference makes ValidateImageSignature verify the signatures
+// found in the given signature artifact, instead of discovering them.
+func (a *ApplicationSnapshotImage) SetSignatureReference(ref name.Digest) {
+	a.signatureRef = &ref
 }
 
 func (a ApplicationSnapshotImage) GetReference() name.Reference {
@@ -109,7 +137,11 @@ func (a *ApplicationSnapshotImage) SetImageURL(url string) error {
 
 	// Reset internal state relevant to the image
 	a.attestations = []attestation.Attestation{}
+	a.logEntryTimes = []time.Time{}
 	a.signatures = []signature.EntitySignature{}
+	a.signingKeys = []signature.SigningKey{}
+	a.identities = []signature.Identity{}
+	a.signingTimes = []signature.SigningTime{}
 
 	return nil
 }
@@ -140,8 +172,17 @@ func (a *ApplicationSnapshotImage) FetchImageFiles(ctx context.Context) error {
 func (a *ApplicationSnapshotImage) ValidateImageSignature(ctx context.Context) error {
 	// Set the ClaimVerifier on a shallow *copy* of CheckOpts to avoid unexpected side-effects
 	opts := a.checkOpts
-	opts.ClaimVerifier = cosign.SimpleClaimVerifier
-	signatures, _, err := oci.NewClient(ctx).VerifyImageSignatures(a.reference, &opts)
+	opts.ClaimVerifier = signature.ImageDigestClaimVerifier
+
+	client := oci.NewClient(ctx)
+	var signatures []cosignoci.Signature
+	var err error
+	if a.signatureRef != nil {
+		log.Debugf("Verifying the signatures of %s from %s", a.reference, a.signatureRef)
+		signatures, _, err = client.VerifyImageSignaturesFrom(a.reference, *a.signatureRef, &opts)
+	} else {
+		signatures, _, err = client.VerifyImageSignatures(a.reference, &opts)
+	}
 	if err != nil {
 		return err
 	}
@@ -152,6 +193,9 @@ func (a *ApplicationSnapshotImage) ValidateImageSignature(ctx context.Context) e
 			return err
 		}
 		a.signatures = append(a.signatures, es)
+		a.addSigningKey(s, signature.SignedDigest(s))
+		a.addIdentity(s)
+		a.addSigningTime(s)
 	}
 
 	return nil
@@ -171,6 +215,15 @@ func (a *ApplicationSnapshotImage) ValidateAttestationSignature(ctx context.Cont
 	// Extract the signatures from the attestations here in order to also validate that
 	// the signatures do exist in the expected format.
 	for _, sig := range layers {
+		if err := attestation.ValidatePayloadType(ctx, sig); err != nil {
+			return err
+		}
+
+		verifiedBy, err := attestation.VerifyCosigners(ctx, sig, a.attestationSigner(sig))
+		if err != nil {
+			return err
+		}
+
 		att, err := attestation.ProvenanceFromSignature(sig)
 		if err != nil {
 			return fmt.Errorf("unable to parse untyped provenance: %w", err)
@@ -195,16 +248,154 @@ func (a *ApplicationSnapshotImage) ValidateAttestationSignature(ctx context.Cont
 			// similar to how it's done for SLSA above
 			a.attestations = append(a.attestations, att)
 
-		// Todo: CycloneDX format SBOM
+		case attestation.PredicateCycloneDXBOM:
+			// It's a CycloneDX format SBOM
+			a.attestations = append(a.attestations, att)
 
 		default:
 			// It's some other kind of attestation
 			a.attestations = append(a.attestations, att)
 		}
+
+		attestation.MarkVerifiedSignatures(a.attestations[len(a.attestations)-1], verifiedBy)
+		a.logEntryTimes = append(a.logEntryTimes, integratedTime(sig))
+		a.addSigningKey(sig, subjectDigestAlgorithm(att))
+		a.addIdentity(sig)
+		a.addSigningTime(sig)
 	}
 	return nil
 }
 
+// attestationSigner returns the signer the attestation was verified with, the
+// certificate of the signature or the public key of the policy.
+func (a *ApplicationSnapshotImage) attestationSigner(sig cosignoci.Signature) attestation.Cosigner {
+	if cert, err := sig.Cert(); err == nil && cert != nil {
+		verifier, err := sigstoreSig.LoadVerifier(cert.PublicKey, crypto.SHA256)
+		if err != nil {
+			log.Debugf("Unable to load the certificate of the signature: %s", err)
+		}
+		return attestation.Cosigner{Name: "certificate", Verifier: verifier}
+	}
+
+	return attestation.Cosigner{Name: "public key", Verifier: a.checkOpts.SigVerifier}
+}
+
+// addSigningKey records the signing material the signature was verified with,
+// the certificate of the signature or the public key of the policy, for the
+// algorithm of the signed digest. Each distinct signing material is recorded
+// once.
+func (a *ApplicationSnapshotImage) addSigningKey(sig cosignoci.Signature, digest string) {
+	cert, err := sig.Cert()
+	if err != nil {
+		log.Debugf("Unable to read the certificate of the signature: %s", err)
+		return
+	}
+
+	var pub crypto.PublicKey
+	if cert != nil {
+		pub = cert.PublicKey
+	} else if a.checkOpts.SigVerifier != nil {
+		if pub, err = a.checkOpts.SigVerifier.PublicKey(); err != nil {
+			log.Debugf("Unable to read the public key of the signature verifier: %s", err)
+			return
+		}
+	} else {
+		return
+	}
+
+	hash, err := signature.DigestHash(digest)
+	if err != nil {
+		log.Debugf("Unable to determine the signing key: %s", err)
+		return
+	}
+
+	k, err := signature.NewSigningKey(pub, cert, hash)
+	if err != nil {
+		log.Debugf("Unable to determine the signing key: %s", err)
+		return
+	}
+
+	for _, existing := range a.signingKeys {
+		if existing == k {
+			return
+		}
+	}
+	a.signingKeys = append(a.signingKeys, k)
+}
+
+// subjectDigestAlgorithm returns the algorithm of the digest of the first
+// subject of the attestation, the first in alphabetical order if the subject
+// has digests of several algorithms.
+func subjectDigestAlgorithm(att attestation.Attestation) string {
+	subjects := att.Subject()
+	if len(subjects) == 0 {
+		return ""
+	}
+
+	algorithms := make([]string, 0, len(subjects[0].Digest))
+	for algorithm := range subjects[0].Digest {
+		algorithms = append(algorithms, algorithm)
+	}
+	if len(algorithms) == 0 {
+		return ""
+	}
+	sort.Strings(algorithms)
+
+	return algorithms[0]
+}
+
+// addIdentity records the keyless identity the signature was verified with,
+// i.e. the certificate subject and issuer matching the identities of the
+// policy. Each distinct identity is recorded once.
+func (a *ApplicationSnapshotImage) addIdentity(sig cosignoci.Signature) {
+	cert, err := sig.Cert()
+	if err != nil || cert == nil {
+		return
+	}
+
+	id, ok := signature.MatchIdentity(cert, a.checkOpts.Identities)
+	if !ok {
+		return
+	}
+
+	for _, existing := range a.identities {
+		if existing == id {
+			return
+		}
+	}
+	a.identities = append(a.identities, id)
+}
+
+// addSigningTime records the time the validity of the certificate of the
+// signature was checked at, if the signature has a certificate.
+func (a *ApplicationSnapshotImage) addSigningTime(sig cosignoci.Signature) {
+	st, err := signature.NewSigningTime(sig, &a.checkOpts, time.Now())
+	if err != nil {
+		log.Debugf("Unable to determine the signing time of the signature: %s", err)
+		return
+	}
+	if st == nil {
+		return
+	}
+
+	if st.Expired {
+		log.Debugf("The signing certificate expired at %s, accepted as valid at the time of signing %s from %s", st.NotAfter.Format(time.RFC3339), st.Time.Format(time.RFC3339), st.Source)
+	}
+	a.signingTimes = append(a.signingTimes, *st)
+}
+
+// integratedTime returns the time the signature was integrated into the
+// transparency log, or the zero time if the signature has no transparency log
+// entry bundled with it.
+func integratedTime(sig cosignoci.Signature) time.Time {
+	bundle, err := sig.Bundle()
+	if err != nil || bundle == nil {
+		return time.Time{}
+	}
+
+	return time.Unix(bundle.Payload.IntegratedTime, 0).UTC()
+}
+
 // ValidateAttestationSyntax validates the attestations against known JSON
 // schemas, errors out if there are no attestations to check to prevent
 // successful syntax check of no inputs, must invoke
@@ -257,10 +448,35 @@ func (a *ApplicationSnapshotImage) Attestations() []attestation.Attestation {
 	return a.attestations
 }
 
+// AttestationLogEntryTimes returns the time each of the attestations was
+// integrated into the transparency log, in the same order as the attestations.
+// The zero time is returned for attestations without a transparency log entry.
+func (a *ApplicationSnapshotImage) AttestationLogEntryTimes() []time.Time {
+	return a.logEntryTimes
+}
+
 func (a *ApplicationSnapshotImage) Signatures() []signature.EntitySignature {
 	return a.signatures
 }
 
+// SigningKeys returns the distinct signing material the image signatures and
+// the attestations were verified with.
+func (a *ApplicationSnapshotImage) SigningKeys() []signature.SigningKey {
+	return a.signingKeys
+}
+
+// Identities returns the distinct keyless identities the image signatures and
+// the attestations were verified with.
+func (a *ApplicationSnapshotImage) Identities() []signature.Identity {
+	return a.identities
+}
+
+// SigningTimes returns the times the validity of the certificates of the image
+// signatures and the attestations were checked at.
+func (a *ApplicationSnapshotImage) SigningTimes() []signature.SigningTime {
+	return a.signingTimes
+}
+
 func (a *ApplicationSnapshotImage) ResolveDigest(ctx context.Context) (string, error) {
 	digest, err := oci.NewClient(ctx).ResolveDigest(a.reference)
 	if err != nil {
@@ -272,6 +488,12 @@ func (a *ApplicationSnapshotImage) ResolveDigest(ctx context.Context) (string, e
 type attestationData struct {
 	Statement  json.RawMessage             `json:"statement"`
 	Signatures []signature.EntitySignature `json:"signatures,omitempty"`
+	// Vulnerabilities is the normalized vulnerability report of the
+	// attestations of a supported vulnerability report format
+	Vulnerabilities *attestation.VulnerabilityReport `json:"vulnerabilities,omitempty"`
+	// Normalized is the canonical shape of the predicate of the attestation,
+	// when normalization is enabled and the predicate type is recognized
+	Normalized any `json:"normalized,omitempty"`
 }
 
 // MarshalJSON returns a JSON representation of the attestationData. It is customized to take into
@@ -309,6 +531,34 @@ func (a attestationData) MarshalJSON() ([]byte, error) {
 		}
 	}
 
+	if a.Vulnerabilities != nil {
+		_, err = buffy.WriteString(`, "vulnerabilities":`)
+		if err != nil {
+			return nil, fmt.Errorf("write vulnerabilities key: %w", err)
+		}
+		vulnerabilities, err := json.Marshal(a.Vulnerabilities)
+		if err != nil {
+			return nil, fmt.Errorf("marshal json vulnerabilities: %w", err)
+		}
+		if _, err := buffy.Write(vulnerabilities); err != nil {
+			return nil, fmt.Errorf("write vulnerabilities value: %w", err)
+		}
+	}
+
+	if a.Normalized != nil {
+		_, err = buffy.WriteString(`, "normalized":`)
+		if err != nil {
+			return nil, fmt.Errorf("write normalized key: %w", err)
+		}
+		normalized, err := json.Marshal(a.Normalized)
+		if err != nil {
+			return nil, fmt.Errorf("marshal json normalized: %w", err)
+		}
+		if _, err := buffy.Write(normalized); err != nil {
+			return nil, fmt.Errorf("write normalized value: %w", err)
+		}
+	}
+
 	if err := buffy.WriteByte('}'); err != nil {
 		return nil, fmt.Errorf("close json: %w", err)
 	}
@@ -327,8 +577,11 @@ type image struct {
 
 type Input struct {
 	Attestations []attestationData `json:"attestations"`
-	Image        image             `json:"image"`
-	AppSnapshot  app.SnapshotSpec  `json:"snapshot"`
+	// AttestationsPresent tells the policy rules whether the image has any
+	// attestations, only when attestations are optional
+	AttestationsPresent *bool            `json:"attestations_present,omitempty"`
+	Image               image            `json:"image"`
+	AppSnapshot         app.SnapshotSpec `json:"snapshot"`
 }
 
 // WriteInputFile writes the JSON from the attestations to input.json in a random temp dir
@@ -337,14 +590,33 @@ func (a *ApplicationSnapshotImage) WriteInputFile(ctx context.Context) (string,
 
 	var attestations []attestationData
 	for _, a := range a.attestations {
+		// Vulnerability reports of unknown formats are given to the policy
+		// only as they are
+		vulnerabilities, err := attestation.ParseVulnerabilityReport(a.PredicateType(), a.Statement())
+		if err != nil {
+			log.Debugf("Unable to parse the vulnerability report: %s", err)
+		}
+
+		var normalized any
+		if attestation.NormalizationEnabled(ctx) {
+			// Unrecognized predicates are given to the policy only as they are
+			normalized, err = attestation.Normalize(a.PredicateType(), a.Statement())
+			if err != nil {
+				log.Debugf("Unable to normalize the attestation: %s", err)
+			}
+		}
+
 		attestations = append(attestations, attestationData{
-			Statement:  a.Statement(),
-			Signatures: a.Signatures(),
+			Statement:       a.Statement(),
+			Signatures:      a.Signatures(),
+			Vulnerabilities: vulnerabilities,
+			Normalized:      normalized,
 		})
 	}
 
 	input := Input{
-		Attestations: attestations,
+		Attestations:        attestations,
+		AttestationsPresent: a.attestationsPresent,
 		Image: image{
 			Ref:        a.reference.String(),
 			Signatures: a.signatures,
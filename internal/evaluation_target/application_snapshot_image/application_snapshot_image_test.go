@@ -279,6 +279,115 @@ func TestWriteInputFileMultipleAttestations(t *testing.T) {
 	assert.JSONEq(t, string(inputJSON), string(bytes))
 }
 
+func TestWriteInputFileVulnerabilities(t *testing.T) {
+	vuln, err := attestation.FromStatement([]byte(`{
+		"_type": "https://in-toto.io/Statement/v0.1",
+		"predicateType": "https://cosign.sigstore.dev/attestation/vuln/v1",
+		"predicate": {"scanner": {"uri": "pkg:github/aquasecurity/trivy", "result": {"Results": [{"Vulnerabilities": [
+			{"VulnerabilityID": "CVE-2024-1", "PkgName": "openssl", "Severity": "HIGH"}
+		]}]}}}
+	}`), nil)
+	require.NoError(t, err)
+
+	a := ApplicationSnapshotImage{
+		reference:    name.MustParseReference("registry.io/repository/image:tag"),
+		attestations: []attestation.Attestation{vuln, createSimpleAttestation(nil)},
+	}
+
+	ctx := utils.WithFS(context.Background(), afero.NewMemMapFs())
+	_, inputJSON, err := a.WriteInputFile(ctx)
+	require.NoError(t, err)
+
+	var input struct {
+		Attestations []struct {
+			Vulnerabilities *attestation.VulnerabilityReport `json:"vulnerabilities"`
+		} `json:"attestations"`
+	}
+	require.NoError(t, json.Unmarshal(inputJSON, &input))
+	require.Len(t, input.Attestations, 2)
+	require.NotNil(t, input.Attestations[0].Vulnerabilities)
+	assert.Equal(t, []attestation.Vulnerability{{ID: "CVE-2024-1", Package: "openssl", Severity: "high"}}, input.Attestations[0].Vulnerabilities.Vulnerabilities)
+	assert.Equal(t, 1, input.Attestations[0].Vulnerabilities.Summary["high"])
+	assert.Equal(t, 0, input.Attestations[0].Vulnerabilities.Summary["critical"])
+	assert.Nil(t, input.Attestations[1].Vulnerabilities)
+}
+
+func TestWriteInputFileAttestationsPresent(t *testing.T) {
+	a := ApplicationSnapshotImage{
+		reference: name.MustParseReference("registry.io/repository/image:tag"),
+	}
+
+	ctx := utils.WithFS(context.Background(), afero.NewMemMapFs())
+	_, inputJSON, err := a.WriteInputFile(ctx)
+	require.NoError(t, err)
+	assert.NotContains(t, string(inputJSON), "attestations_present")
+
+	a.SetAttestationsPresent(false)
+	_, inputJSON, err = a.WriteInputFile(ctx)
+	require.NoError(t, err)
+
+	var input struct {
+		AttestationsPresent *bool `json:"attestations_present"`
+	}
+	require.NoError(t, json.Unmarshal(inputJSON, &input))
+	require.NotNil(t, input.AttestationsPresent)
+	assert.False(t, *input.AttestationsPresent)
+}
+
+func TestWriteInputFileNormalized(t *testing.T) {
+	provenance, err := attestation.FromStatement([]byte(`{
+		"_type": "https://in-toto.io/Statement/v1",
+		"predicateType": "https://slsa.dev/provenance/v1",
+		"predicate": {
+			"buildDefinition": {"buildType": "https://tekton.dev/chains/v2/slsa"},
+			"runDetails": {"builder": {"id": "https://tekton.dev/chains/v2"}}
+		}
+	}`), nil)
+	require.NoError(t, err)
+
+	other, err := attestation.FromStatement([]byte(`{
+		"_type": "https://in-toto.io/Statement/v1",
+		"predicateType": "https://example.com/custom/v1",
+		"predicate": {"custom": true}
+	}`), nil)
+	require.NoError(t, err)
+
+	a := ApplicationSnapshotImage{
+		reference:    name.MustParseReference("registry.io/repository/image:tag"),
+		attestations: []attestation.Attestation{provenance, other},
+	}
+
+	type input struct {
+		Attestations []struct {
+			Statement  json.RawMessage `json:"statement"`
+			Normalized map[string]any  `json:"normalized"`
+		} `json:"attestations"`
+	}
+
+	ctx := utils.WithFS(context.Background(), afero.NewMemMapFs())
+	_, inputJSON, err := a.WriteInputFile(ctx)
+	require.NoError(t, err)
+
+	var disabled input
+	require.NoError(t, json.Unmarshal(inputJSON, &disabled))
+	require.Len(t, disabled.Attestations, 2)
+	assert.Nil(t, disabled.Attestations[0].Normalized)
+
+	_, inputJSON, err = a.WriteInputFile(attestation.WithNormalization(ctx, true))
+	require.NoError(t, err)
+
+	var enabled input
+	require.NoError(t, json.Unmarshal(inputJSON, &enabled))
+	require.Len(t, enabled.Attestations, 2)
+	assert.Equal(t, map[string]any{
+		"builderId": "https://tekton.dev/chains/v2",
+		"buildType": "https://tekton.dev/chains/v2/slsa",
+		"materials": []any{},
+	}, enabled.Attestations[0].Normalized)
+	assert.JSONEq(t, string(other.Statement()), string(enabled.Attestations[1].Statement))
+	assert.Nil(t, enabled.Attestations[1].Normalized)
+}
+
 func TestNewApplicationSnapshotImage(t *testing.T) {
 	ctx := context.Background()
 
@@ -486,7 +595,13 @@ func TestValidateImageSignatureClaims(t *testing.T) {
 				},
 			},
 			digest: v1.Hash{Algorithm: "sha256", Hex: "dabbad00"},
-			err:    errors.New("invalid or missing digest in claim: sha256:ffbaddD11"),
+			err:    signature.DigestMismatchError{Signed: "sha256:ffbaddD11", Image: "sha256:dabbad00"},
+		},
+		{
+			name:    "missing digest",
+			payload: payload.SimpleContainerImage{},
+			digest:  v1.Hash{Algorithm: "sha256", Hex: "dabbad00"},
+			err:     signature.DigestMismatchError{Image: "sha256:dabbad00"},
 		},
 		{
 			name: "missing annotation",
@@ -537,6 +652,24 @@ func TestValidateImageSignatureClaims(t *testing.T) {
 	}
 }
 
+func TestValidateImageSignatureFromReference(t *testing.T) {
+	ref := name.MustParseReference("registry.io/repository/image:tag")
+	sigRef := name.MustParseReference("registry.io/repository@sha256:aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa").(name.Digest)
+	a := ApplicationSnapshotImage{
+		reference: ref,
+	}
+	a.SetSignatureReference(sigRef)
+
+	c := fake.FakeClient{}
+	ctx := o.WithClient(context.Background(), &c)
+
+	c.On("VerifyImageSignaturesFrom", ref, sigRef, mock.Anything).Return([]oci.Signature{}, false, nil)
+
+	require.NoError(t, a.ValidateImageSignature(ctx))
+	c.AssertNotCalled(t, "VerifyImageSignatures", mock.Anything, mock.Anything)
+	c.AssertExpectations(t)
+}
+
 func TestValidateAttestationSignatureClaims(t *testing.T) {
 	ref := name.MustParseReference("registry.io/repository/image:tag")
 	a := ApplicationSnapshotImage{
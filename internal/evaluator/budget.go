@@ -0,0 +1,148 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package evaluator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/metrics"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+const evaluationBudgetKey contextKey = "ec.evaluator.budget"
+
+// heapMetric is the memory occupied by heap objects, live or not yet swept
+const heapMetric = "/memory/classes/heap/objects:bytes"
+
+// memorySampleInterval is how often the heap is sampled when a memory limit
+// is set
+var memorySampleInterval = 10 * time.Millisecond
+
+// EvaluationBudget bounds the resources a single policy evaluation, i.e. the
+// evaluation of the policy for one image or input, can use. The evaluation is
+// interrupted, via OPA's cancellation of the query, once a limit is exceeded.
+type EvaluationBudget struct {
+	// MemoryLimit is the number of bytes the heap may grow by during the
+	// evaluation, zero for no limit. The heap is shared by all the evaluations
+	// running in parallel, so the growth caused by concurrent evaluations
+	// counts towards the limit of each of them.
+	MemoryLimit int64
+	// Timeout is the time the evaluation may take, zero for no limit. This
+	// bounds evaluations that loop without allocating.
+	Timeout time.Duration
+}
+
+// ResourceLimitError is returned by Evaluate when the evaluation exceeded a
+// limit of the EvaluationBudget.
+type ResourceLimitError struct {
+	// Limit is the limit that was exceeded, memory or time
+	Limit string
+	// Value is the exceeded value of the limit
+	Value string
+}
+
+func (e ResourceLimitError) Error() string {
+	return fmt.Sprintf("policy evaluation exceeded the %s limit of %s", e.Limit, e.Value)
+}
+
+// WithEvaluationBudget returns a copy of the context bounding the resources
+// each policy evaluation can use.
+func WithEvaluationBudget(ctx context.Context, b EvaluationBudget) context.Context {
+	return context.WithValue(ctx, evaluationBudgetKey, b)
+}
+
+func evaluationBudget(ctx context.Context) EvaluationBudget {
+	if b, ok := ctx.Value(evaluationBudgetKey).(EvaluationBudget); ok {
+		return b
+	}
+
+	return EvaluationBudget{}
+}
+
+// withBudget returns a context that is cancelled once the evaluation exceeds
+// the budget, and a function to call when the evaluation has finished that
+// returns the ResourceLimitError, if any limit was exceeded.
+func withBudget(ctx context.Context, b EvaluationBudget) (context.Context, func() error) {
+	if b.MemoryLimit == 0 && b.Timeout == 0 {
+		return ctx, func() error { return nil }
+	}
+
+	ctx, cancel := context.WithCancelCause(ctx)
+
+	var cancelTimeout context.CancelFunc = func() {}
+	if b.Timeout > 0 {
+		ctx, cancelTimeout = context.WithTimeoutCause(ctx, b.Timeout, ResourceLimitError{Limit: "time", Value: b.Timeout.String()})
+	}
+
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	if b.MemoryLimit > 0 {
+		go watchMemory(heapSize(), b.MemoryLimit, cancel, done, stopped)
+	} else {
+		close(stopped)
+	}
+
+	return ctx, func() error {
+		close(done)
+		<-stopped
+		defer cancel(nil)
+		defer cancelTimeout()
+
+		var limitErr ResourceLimitError
+		if errors.As(context.Cause(ctx), &limitErr) {
+			log.Debugf("Policy evaluation interrupted: %s", limitErr)
+			return limitErr
+		}
+
+		return nil
+	}
+}
+
+func heapSize() int64 {
+	sample := []metrics.Sample{{Name: heapMetric}}
+	metrics.Read(sample)
+	if sample[0].Value.Kind() != metrics.KindUint64 {
+		return 0
+	}
+
+	return int64(sample[0].Value.Uint64()) // #nosec G115 -- the heap size fits in int64
+}
+
+// watchMemory cancels the evaluation once the heap has grown by more than the
+// limit from the baseline, until done is closed.
+func watchMemory(baseline, limit int64, cancel context.CancelCauseFunc, done <-chan struct{}, stopped chan<- struct{}) {
+	defer close(stopped)
+
+	ticker := time.NewTicker(memorySampleInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			if heapSize()-baseline > limit {
+				cancel(ResourceLimitError{Limit: "memory", Value: resource.NewQuantity(limit, resource.BinarySI).String()})
+				return
+			}
+		}
+	}
+}
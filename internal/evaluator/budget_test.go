@@ -0,0 +1,122 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package evaluator
+
+import (
+	"context"
+	"os"
+	"path"
+	"runtime"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	ecc "github.com/enterprise-contract/enterprise-contract-controller/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/policy"
+	"github.com/enterprise-contract/ec-cli/internal/policy/source"
+)
+
+func TestWithBudgetUnlimited(t *testing.T) {
+	ctx := context.Background()
+	budgetCtx, finished := withBudget(ctx, EvaluationBudget{})
+	assert.Equal(t, ctx, budgetCtx)
+	assert.NoError(t, finished())
+}
+
+func TestWithBudgetTimeout(t *testing.T) {
+	ctx, finished := withBudget(context.Background(), EvaluationBudget{Timeout: 10 * time.Millisecond})
+	<-ctx.Done()
+	assert.Equal(t, ResourceLimitError{Limit: "time", Value: "10ms"}, finished())
+}
+
+func TestWithBudgetMemory(t *testing.T) {
+	// Collect the garbage of the other tests not to lower the heap size below
+	// the baseline
+	runtime.GC()
+	ctx, finished := withBudget(context.Background(), EvaluationBudget{MemoryLimit: 1024 * 1024})
+
+	allocated := make([]byte, 10*1024*1024)
+	for i := range allocated {
+		allocated[i] = 1
+	}
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "the memory limit did not interrupt the evaluation")
+	}
+	err := finished()
+	assert.Equal(t, ResourceLimitError{Limit: "memory", Value: "1Mi"}, err)
+	assert.EqualError(t, err, "policy evaluation exceeded the memory limit of 1Mi")
+	assert.Len(t, allocated, 10*1024*1024)
+}
+
+func TestWithBudgetWithinLimits(t *testing.T) {
+	ctx, finished := withBudget(context.Background(), EvaluationBudget{MemoryLimit: 1024 * 1024 * 1024, Timeout: time.Minute})
+	assert.NoError(t, finished())
+	assert.ErrorIs(t, ctx.Err(), context.Canceled, "the context is released")
+}
+
+func TestConftestEvaluatorEvaluateBudget(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(path.Join(dir, "inputs"), 0755))
+	require.NoError(t, os.WriteFile(path.Join(dir, "inputs", "data.json"), []byte("{}"), 0600))
+
+	rules, err := rulesArchive(t, fstest.MapFS{
+		"slow.rego": &fstest.MapFile{Data: []byte(`package slow
+
+import rego.v1
+
+# METADATA
+# title: Slow
+# custom:
+#   short_name: slow
+deny contains result if {
+	some i, j
+	numbers.range(1, 100000)[i]
+	numbers.range(1, 100000)[j]
+	i * j < 0
+	result := {"code": "slow.slow", "msg": "Never"}
+}
+`)},
+	})
+	require.NoError(t, err)
+
+	ctx := withCapabilities(context.Background(), testCapabilities)
+
+	config := &mockConfigProvider{}
+	config.On("EffectiveTime").Return(time.Now())
+	config.On("SigstoreOpts").Return(policy.SigstoreOpts{}, nil)
+	config.On("Spec").Return(ecc.EnterpriseContractPolicySpec{})
+
+	evaluator, err := NewConftestEvaluator(ctx, []source.PolicySource{
+		&source.PolicyUrl{
+			Url:  rules,
+			Kind: source.PolicyKind,
+		},
+	}, config, ecc.Source{})
+	require.NoError(t, err)
+
+	ctx = WithEvaluationBudget(ctx, EvaluationBudget{Timeout: 100 * time.Millisecond})
+	_, _, err = evaluator.Evaluate(ctx, EvaluationTarget{Inputs: []string{path.Join(dir, "inputs")}})
+	assert.Equal(t, ResourceLimitError{Limit: "time", Value: "100ms"}, err)
+}
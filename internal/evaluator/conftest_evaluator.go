@@ -177,6 +177,14 @@ const (
 	metadataTitle       = "title"
 )
 
+const (
+	// metadataEnforceAfter is the date before which violations of the rule
+	// are reported as warnings
+	metadataEnforceAfter = "enforce_after"
+	// metadataDemotion is the reason a violation was reported as a warning
+	metadataDemotion = "demotion"
+)
+
 // ConfigProvider is a subset of the policy.Policy interface. Its purpose is to codify which parts
 // of Policy are actually used and to make it easier to use mock in tests.
 type ConfigProvider interface {
@@ -197,24 +205,38 @@ type conftestEvaluator struct {
 	exclude       *Criteria
 	fs            afero.Fs
 	namespace     []string
+	mergeStrategy DataMergeStrategy
+	merger        *dataMerger
+	strictRego    bool
 }
 
 type conftestRunner struct {
 	runner.TestRunner
+	// tracer, if set, receives the evaluation traces of target
+	tracer *tracer
+	target string
+	// parallel evaluates the namespaces concurrently
+	parallel bool
 }
 
 func (r conftestRunner) Run(ctx context.Context, fileList []string) (result []Outcome, data Data, err error) {
-	if log.IsLevelEnabled(log.TraceLevel) {
+	if log.IsLevelEnabled(log.TraceLevel) || r.tracer != nil {
 		r.Trace = true
 	}
 
 	var conftestResult []output.CheckResult
-	conftestResult, err = r.TestRunner.Run(ctx, fileList)
+	conftestResult, err = r.check(ctx, fileList)
 	if err != nil {
 		return
 	}
 
 	for _, res := range conftestResult {
+		if r.tracer != nil {
+			if err = r.tracer.write(r.target, res); err != nil {
+				err = fmt.Errorf("unable to write the evaluation trace: %w", err)
+				return
+			}
+		}
 		if log.IsLevelEnabled(log.TraceLevel) {
 			for _, q := range res.Queries {
 				for _, t := range q.Traces {
@@ -291,6 +313,9 @@ func NewConftestEvaluatorWithNamespace(ctx context.Context, policySources []sour
 		policy:        p,
 		fs:            fs,
 		namespace:     namespace,
+		mergeStrategy: dataMergeStrategy(ctx),
+		merger:        &dataMerger{},
+		strictRego:    strictRego(ctx),
 	}
 
 	c.include, c.exclude = computeIncludeExclude(source, p)
@@ -363,13 +388,25 @@ func (c conftestEvaluator) Evaluate(ctx context.Context, target EvaluationTarget
 	// exist with the same code in two separate sources the collected rule
 	// information is not deterministic
 	rules := policyRules{}
+	// Holds the paths to all data documents in the order the sources have
+	// been configured, starting with the generated configuration, used to
+	// merge the data documents
+	dataPaths := []string{filepath.Join(c.dataDir, "config.json")}
 	// Download all sources
-	for _, s := range c.policySources {
-		dir, err := s.GetPolicy(ctx, c.workDir, false)
-		if err != nil {
-			log.Debugf("Unable to download source from %s!", s.PolicyUrl())
-			// TODO do we want to download other policies instead of erroring out?
-			return nil, nil, err
+	dirs, err := source.GetPolicies(ctx, c.policySources, c.workDir, false)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for i, s := range c.policySources {
+		dir := dirs[i]
+		if dir == "" {
+			// skipped, see source.WithMissingSources
+			continue
+		}
+
+		if s.Subdir() == string(source.DataKind) {
+			dataPaths = append(dataPaths, dir)
 		}
 
 		annotations := []*ast.AnnotationsRef{}
@@ -421,23 +458,37 @@ func (c conftestEvaluator) Evaluate(ctx context.Context, target EvaluationTarget
 			allNamespaces = false
 		}
 
+		mergedDataDir := filepath.Join(c.workDir, "merged_data")
+		if err := c.merger.merge(ctx, dataPaths, mergedDataDir, c.mergeStrategy); err != nil {
+			log.Debug("Unable to merge the data documents!")
+			return nil, nil, err
+		}
+
 		r = &conftestRunner{
-			runner.TestRunner{
-				Data:          []string{c.dataDir},
+			TestRunner: runner.TestRunner{
+				Data:          []string{mergedDataDir},
 				Policy:        []string{c.policyDir},
 				Namespace:     c.namespace,
 				AllNamespaces: allNamespaces,
 				NoFail:        true,
 				Output:        c.outputFormat,
 				Capabilities:  c.CapabilitiesPath(),
+				Strict:        c.strictRego,
 			},
+			tracer:   traceFor(ctx, target.Target),
+			target:   target.Target,
+			parallel: parallelEvaluation(ctx),
 		}
 	}
 
 	log.Debugf("runner: %#v", r)
 	log.Debugf("inputs: %#v", target.Inputs)
 
-	runResults, data, err := r.Run(ctx, target.Inputs)
+	runCtx, finished := withBudget(ctx, evaluationBudget(ctx))
+	runResults, data, err := r.Run(runCtx, target.Inputs)
+	if limitErr := finished(); limitErr != nil {
+		return nil, nil, limitErr
+	}
 	if err != nil {
 		// TODO do we want to evaluate further policies instead of erroring out?
 		return nil, nil, err
@@ -482,6 +533,10 @@ func (c conftestEvaluator) Evaluate(ctx context.Context, target EvaluationTarget
 			if !isResultEffective(failure, effectiveTime) {
 				// TODO: Instead of moving to warnings, create new attribute: "futureViolations"
 				warnings = append(warnings, failure)
+			} else if demotion := resultDemotion(failure, effectiveTime); demotion != "" {
+				log.Debugf("Demoting result failure to warning: %s", demotion)
+				failure.Metadata[metadataDemotion] = demotion
+				warnings = append(warnings, failure)
 			} else {
 				failures = append(failures, failure)
 			}
@@ -650,6 +705,9 @@ func addMetadataToResults(ctx context.Context, r *Result, rule rule.Info) {
 	if rule.EffectiveOn != "" {
 		r.Metadata[metadataEffectiveOn] = rule.EffectiveOn
 	}
+	if rule.EnforceAfter != "" {
+		r.Metadata[metadataEnforceAfter] = rule.EnforceAfter
+	}
 	if rule.Description != "" {
 		r.Metadata[metadataDescription] = rule.Description
 	}
@@ -662,6 +720,7 @@ func addMetadataToResults(ctx context.Context, r *Result, rule rule.Info) {
 	if len(rule.DependsOn) > 0 {
 		r.Metadata[metadataDependsOn] = rule.DependsOn
 	}
+	r.Severity = resultSeverity(*r, rule.Severity)
 
 	// If the rule has been effective for a long time, we'll consider
 	// the effective_on date not relevant and not bother including it
@@ -802,6 +861,33 @@ func isResultEffective(failure Result, now time.Time) bool {
 	return effectiveOn.Before(now)
 }
 
+// resultDemotion returns the reason for reporting the failure as a warning when
+// the rule is not enforced yet at the given time, i.e. the enforce_after date
+// of the rule is after it, or an empty string if the failure is enforced. The
+// date is given either as a date or a RFC3339 timestamp.
+func resultDemotion(failure Result, now time.Time) string {
+	raw, ok := failure.Metadata[metadataEnforceAfter]
+	if !ok {
+		return ""
+	}
+	str, ok := raw.(string)
+	if !ok {
+		log.Warnf("Ignoring non-string %q value %#v", metadataEnforceAfter, raw)
+		return ""
+	}
+	enforceAfter, err := time.Parse(time.RFC3339, str)
+	if err != nil {
+		if enforceAfter, err = time.Parse(time.DateOnly, str); err != nil {
+			log.Warnf("Invalid %q value %q", metadataEnforceAfter, str)
+			return ""
+		}
+	}
+	if !now.Before(enforceAfter) {
+		return ""
+	}
+	return fmt.Sprintf("the rule is not enforced until %s", str)
+}
+
 // isResultIncluded returns whether or not the result should be included or
 // discarded based on the policy configuration.
 func (c conftestEvaluator) isResultIncluded(result Result, target string) bool {
@@ -955,16 +1041,22 @@ func strictCapabilities(ctx context.Context) (string, error) {
 	// to the list which shouldn't match any host but preserves the list after the
 	// JSON dance.
 	capabilities.AllowNet = []string{""}
-	log.Debug("Network access from rego policies disabled")
 
 	builtins := make([]*ast.Builtin, 0, len(capabilities.Builtins))
 	disallowed := sets.NewString(
 		// disallow access to environment variables
 		"opa.runtime",
+	)
+
+	if n, ok := allowedNetworkBuiltins(ctx); ok {
+		capabilities.AllowNet = append(capabilities.AllowNet, n.AllowedHosts...)
+		log.Debugf("Network access from rego policies allowed to: %s", n.AllowedHosts)
+	} else {
 		// disallow external connections. This is a second layer of defense since
 		// AllowNet should prevent external connections in the first place.
-		"http.send", "net.lookup_ip_addr",
-	)
+		disallowed.Insert(networkBuiltins...)
+		log.Debug("Network access from rego policies disabled")
+	}
 	for _, b := range capabilities.Builtins {
 		if !disallowed.Has(b.Name) {
 			builtins = append(builtins, b)
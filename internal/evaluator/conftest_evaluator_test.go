@@ -121,6 +121,24 @@ func TestConftestEvaluatorEvaluateTimeBased(t *testing.T) {
 						"effective_on": "3021-01-01T00:00:00Z",
 					},
 				},
+				{
+					Message: "already enforced",
+					Metadata: map[string]any{
+						"enforce_after": "2021-01-01",
+					},
+				},
+				{
+					Message: "not yet enforced",
+					Metadata: map[string]any{
+						"enforce_after": "3021-01-01",
+					},
+				},
+				{
+					Message: "not yet enforced timestamp",
+					Metadata: map[string]any{
+						"enforce_after": "3021-01-01T00:00:00Z",
+					},
+				},
 			},
 			Warnings: []Result{
 				{
@@ -158,6 +176,12 @@ func TestConftestEvaluatorEvaluateTimeBased(t *testing.T) {
 						"effective_on": true,
 					},
 				},
+				{
+					Message: "already enforced",
+					Metadata: map[string]any{
+						"enforce_after": "2021-01-01",
+					},
+				},
 			},
 			Warnings: []Result{
 				{
@@ -172,6 +196,20 @@ func TestConftestEvaluatorEvaluateTimeBased(t *testing.T) {
 						"effective_on": "3021-01-01T00:00:00Z",
 					},
 				},
+				{
+					Message: "not yet enforced",
+					Metadata: map[string]any{
+						"enforce_after": "3021-01-01",
+						"demotion":      "the rule is not enforced until 3021-01-01",
+					},
+				},
+				{
+					Message: "not yet enforced timestamp",
+					Metadata: map[string]any{
+						"enforce_after": "3021-01-01T00:00:00Z",
+						"demotion":      "the rule is not enforced until 3021-01-01T00:00:00Z",
+					},
+				},
 			},
 			Skipped:    []Result{},
 			Exceptions: []Result{},
@@ -71,6 +71,37 @@ func (c *Criteria) get(key string) []string {
 	return c.defaultItems
 }
 
+// VolatileCriteriaWindow returns the window in which the volatile criteria is
+// effective. A bound that is not set, or cannot be parsed, is returned as the
+// zero time, i.e. the window is unbounded on that end.
+func VolatileCriteriaWindow(c ecc.VolatileCriteria) (from time.Time, until time.Time) {
+	from, err := time.Parse(time.RFC3339, c.EffectiveOn)
+	if err != nil {
+		if c.EffectiveOn != "" {
+			log.Warnf("unable to parse time for criteria %q, was given %q: %v", c.Value, c.EffectiveOn, err)
+		}
+		from = time.Time{}
+	}
+
+	until, err = time.Parse(time.RFC3339, c.EffectiveUntil)
+	if err != nil {
+		if c.EffectiveUntil != "" {
+			log.Warnf("unable to parse time for criteria %q, was given %q: %v", c.Value, c.EffectiveUntil, err)
+		}
+		until = time.Time{}
+	}
+
+	return
+}
+
+// IsVolatileCriteriaEffective returns true if the given time is within the
+// window in which the volatile criteria is effective.
+func IsVolatileCriteriaEffective(c ecc.VolatileCriteria, at time.Time) bool {
+	from, until := VolatileCriteriaWindow(c)
+
+	return (from.IsZero() || !from.After(at)) && (until.IsZero() || !until.Before(at))
+}
+
 func computeIncludeExclude(src ecc.Source, p ConfigProvider) (*Criteria, *Criteria) {
 	include := &Criteria{}
 	exclude := &Criteria{}
@@ -89,21 +120,7 @@ func computeIncludeExclude(src ecc.Source, p ConfigProvider) (*Criteria, *Criter
 		at := p.EffectiveTime()
 		filter := func(items *Criteria, volatileCriteria []ecc.VolatileCriteria) *Criteria {
 			for _, c := range volatileCriteria {
-				from, err := time.Parse(time.RFC3339, c.EffectiveOn)
-				if err != nil {
-					if c.EffectiveOn != "" {
-						log.Warnf("unable to parse time for criteria %q, was given %q: %v", c.Value, c.EffectiveOn, err)
-					}
-					from = at
-				}
-				until, err := time.Parse(time.RFC3339, c.EffectiveUntil)
-				if err != nil {
-					if c.EffectiveUntil != "" {
-						log.Warnf("unable to parse time for criteria %q, was given %q: %v", c.Value, c.EffectiveUntil, err)
-					}
-					until = at
-				}
-				if until.Compare(at) >= 0 && from.Compare(at) <= 0 {
+				if IsVolatileCriteriaEffective(c, at) {
 					items.addItem(c.ImageRef, c.Value)
 				}
 			}
@@ -20,6 +20,9 @@ package evaluator
 
 import (
 	"testing"
+	"time"
+
+	ecc "github.com/enterprise-contract/enterprise-contract-controller/api/v1alpha1"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -223,3 +226,26 @@ func TestGet(t *testing.T) {
 	assert.ElementsMatch(t, expectedDefaultItems, c.get("key2"))
 
 }
+
+func TestIsVolatileCriteriaEffective(t *testing.T) {
+	at := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		name     string
+		criteria ecc.VolatileCriteria
+		expected bool
+	}{
+		{name: "unbounded", expected: true},
+		{name: "within", criteria: ecc.VolatileCriteria{EffectiveOn: "2023-01-01T00:00:00Z", EffectiveUntil: "2025-01-01T00:00:00Z"}, expected: true},
+		{name: "on the bounds", criteria: ecc.VolatileCriteria{EffectiveOn: "2024-01-01T00:00:00Z", EffectiveUntil: "2024-01-01T00:00:00Z"}, expected: true},
+		{name: "expired", criteria: ecc.VolatileCriteria{EffectiveUntil: "2023-12-31T23:59:59Z"}, expected: false},
+		{name: "pending", criteria: ecc.VolatileCriteria{EffectiveOn: "2024-01-01T00:00:01Z"}, expected: false},
+		{name: "invalid bounds", criteria: ecc.VolatileCriteria{EffectiveOn: "tomorrow", EffectiveUntil: "yesterday"}, expected: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.expected, IsVolatileCriteriaEffective(c.criteria, at))
+		})
+	}
+}
@@ -0,0 +1,231 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package evaluator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+// DataMergeStrategy controls how data documents from multiple data sources
+// are combined into the single `data` namespace used during evaluation.
+type DataMergeStrategy string
+
+const (
+	// DeepMerge recursively merges objects from all data documents. When the
+	// same leaf is defined more than once, the value from the last source wins.
+	DeepMerge DataMergeStrategy = "deep"
+	// ReplaceMerge replaces top level keys wholesale, with the value from the
+	// last source winning.
+	ReplaceMerge DataMergeStrategy = "replace"
+)
+
+const dataMergeStrategyKey contextKey = "ec.evaluator.data_merge_strategy"
+
+// DataMergeStrategies lists all the supported data merge strategies.
+var DataMergeStrategies = []string{string(DeepMerge), string(ReplaceMerge)}
+
+// ParseDataMergeStrategy returns the DataMergeStrategy matching the given
+// value, or an error if the value is not a supported strategy.
+func ParseDataMergeStrategy(value string) (DataMergeStrategy, error) {
+	switch s := DataMergeStrategy(value); s {
+	case DeepMerge, ReplaceMerge:
+		return s, nil
+	}
+
+	return "", fmt.Errorf("unsupported data merge strategy %q, expecting one of: %s", value, strings.Join(DataMergeStrategies, ", "))
+}
+
+// WithDataMergeStrategy returns a copy of the context that instructs any
+// evaluator created with it to use the given data merge strategy.
+func WithDataMergeStrategy(ctx context.Context, strategy DataMergeStrategy) context.Context {
+	return context.WithValue(ctx, dataMergeStrategyKey, strategy)
+}
+
+func dataMergeStrategy(ctx context.Context) DataMergeStrategy {
+	if s, ok := ctx.Value(dataMergeStrategyKey).(DataMergeStrategy); ok && s != "" {
+		return s
+	}
+
+	return DeepMerge
+}
+
+// isDataDocument mirrors the file extensions Conftest loads as data documents.
+func isDataDocument(name string) bool {
+	switch filepath.Ext(name) {
+	case ".json", ".yaml", ".yml":
+		return true
+	}
+
+	return false
+}
+
+// dataDocuments returns the paths of all data documents within the given
+// directory, sorted so that the merge order is deterministic. If the given
+// path is a file, it is returned as is.
+func dataDocuments(fs afero.Fs, dir string) ([]string, error) {
+	if isDir, err := afero.IsDir(fs, dir); err != nil {
+		return nil, err
+	} else if !isDir {
+		return []string{dir}, nil
+	}
+
+	var documents []string
+	// The trailing separator makes sure a symlinked directory, as created by
+	// the download cache, is followed.
+	err := afero.Walk(fs, dir+string(os.PathSeparator), func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || !isDataDocument(info.Name()) {
+			return nil
+		}
+		documents = append(documents, path)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(documents)
+
+	return documents, nil
+}
+
+// mergeDataDocuments reads all data documents from the given paths, in order,
+// and merges them according to the strategy into a single document.
+func mergeDataDocuments(ctx context.Context, paths []string, strategy DataMergeStrategy) (map[string]any, error) {
+	fs := utils.FS(ctx)
+	merged := map[string]any{}
+
+	for _, path := range paths {
+		documents, err := dataDocuments(fs, path)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, document := range documents {
+			content, err := afero.ReadFile(fs, document)
+			if err != nil {
+				return nil, err
+			}
+
+			if len(strings.TrimSpace(string(content))) == 0 {
+				continue
+			}
+
+			content, err = utils.ToJSON(content)
+			if err != nil {
+				return nil, fmt.Errorf("unable to parse data document %s: %w", document, err)
+			}
+
+			var doc map[string]any
+			if err := json.Unmarshal(content, &doc); err != nil {
+				return nil, fmt.Errorf("unable to parse data document %s: %w", document, err)
+			}
+
+			switch strategy {
+			case ReplaceMerge:
+				for key, value := range doc {
+					if _, ok := merged[key]; ok {
+						log.Debugf("Data merge conflict at %q, value from %s replaces the previous value", key, document)
+					}
+					merged[key] = value
+				}
+			default:
+				deepMerge(merged, doc, "", document)
+			}
+		}
+	}
+
+	return merged, nil
+}
+
+// deepMerge merges src into dst recursively. Objects are merged key by key,
+// any other value from src overrides the value in dst.
+func deepMerge(dst, src map[string]any, prefix, origin string) {
+	for key, value := range src {
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+
+		existing, ok := dst[key]
+		if !ok {
+			dst[key] = value
+			continue
+		}
+
+		existingObj, existingIsObj := existing.(map[string]any)
+		valueObj, valueIsObj := value.(map[string]any)
+		if existingIsObj && valueIsObj {
+			deepMerge(existingObj, valueObj, path, origin)
+			continue
+		}
+
+		log.Debugf("Data merge conflict at %q, value from %s replaces the previous value", path, origin)
+		dst[key] = value
+	}
+}
+
+// dataMerger makes sure the data documents are merged only once per
+// evaluator, even when multiple images are evaluated concurrently.
+type dataMerger struct {
+	once sync.Once
+	err  error
+}
+
+func (m *dataMerger) merge(ctx context.Context, paths []string, dest string, strategy DataMergeStrategy) error {
+	m.once.Do(func() {
+		m.err = writeMergedData(ctx, paths, dest, strategy)
+	})
+
+	return m.err
+}
+
+// writeMergedData merges the data documents found in the given paths and
+// writes the result as a single data document in dest.
+func writeMergedData(ctx context.Context, paths []string, dest string, strategy DataMergeStrategy) error {
+	merged, err := mergeDataDocuments(ctx, paths, strategy)
+	if err != nil {
+		return err
+	}
+
+	content, err := json.Marshal(merged)
+	if err != nil {
+		return err
+	}
+
+	fs := utils.FS(ctx)
+	if err := fs.MkdirAll(dest, 0755); err != nil {
+		return err
+	}
+
+	log.Debugf("Writing merged data, using the %q strategy, to %s", strategy, dest)
+	return afero.WriteFile(fs, filepath.Join(dest, "data.json"), content, 0444)
+}
@@ -0,0 +1,125 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package evaluator
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+func TestParseDataMergeStrategy(t *testing.T) {
+	s, err := ParseDataMergeStrategy("deep")
+	assert.NoError(t, err)
+	assert.Equal(t, DeepMerge, s)
+
+	s, err = ParseDataMergeStrategy("replace")
+	assert.NoError(t, err)
+	assert.Equal(t, ReplaceMerge, s)
+
+	_, err = ParseDataMergeStrategy("shallow")
+	assert.EqualError(t, err, `unsupported data merge strategy "shallow", expecting one of: deep, replace`)
+}
+
+func TestDataMergeStrategyFromContext(t *testing.T) {
+	assert.Equal(t, DeepMerge, dataMergeStrategy(context.Background()))
+	assert.Equal(t, ReplaceMerge, dataMergeStrategy(WithDataMergeStrategy(context.Background(), ReplaceMerge)))
+}
+
+func TestMergeDataDocuments(t *testing.T) {
+	cases := []struct {
+		name     string
+		strategy DataMergeStrategy
+		expected string
+	}{
+		{
+			name:     "deep",
+			strategy: DeepMerge,
+			expected: `{
+				"config": {"policy": {"when_ns": 1}},
+				"registries": {"allowed": ["registry.io"], "blocked": ["evil.io"]},
+				"base_images": ["ubi9"],
+				"waivers": {"expiry": "2024-01-01"}
+			}`,
+		},
+		{
+			name:     "replace",
+			strategy: ReplaceMerge,
+			expected: `{
+				"config": {"policy": {"when_ns": 1}},
+				"registries": {"blocked": ["evil.io"]},
+				"base_images": ["ubi9"],
+				"waivers": {"expiry": "2024-01-01"}
+			}`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			ctx := utils.WithFS(context.Background(), fs)
+
+			require.NoError(t, afero.WriteFile(fs, "/data/config.json", []byte(`{"config": {"policy": {"when_ns": 1}}}`), 0400))
+			require.NoError(t, afero.WriteFile(fs, "/data/a/registries.yaml", []byte("registries:\n  allowed:\n  - registry.io\n"), 0400))
+			require.NoError(t, afero.WriteFile(fs, "/data/a/base_images.json", []byte(`{"base_images": ["ubi8"]}`), 0400))
+			require.NoError(t, afero.WriteFile(fs, "/data/a/README.md", []byte("not data"), 0400))
+			require.NoError(t, afero.WriteFile(fs, "/data/b/registries.yml", []byte("registries:\n  blocked:\n  - evil.io\n"), 0400))
+			require.NoError(t, afero.WriteFile(fs, "/data/b/nested/more.json", []byte(`{"base_images": ["ubi9"], "waivers": {"expiry": "2024-01-01"}}`), 0400))
+			require.NoError(t, afero.WriteFile(fs, "/data/b/empty.yaml", []byte(""), 0400))
+
+			require.NoError(t, writeMergedData(ctx, []string{"/data/config.json", "/data/a", "/data/b"}, "/merged", c.strategy))
+
+			merged, err := afero.ReadFile(fs, "/merged/data.json")
+			require.NoError(t, err)
+			assert.JSONEq(t, c.expected, string(merged))
+		})
+	}
+}
+
+func TestMergeDataDocumentsLastSourceWins(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	ctx := utils.WithFS(context.Background(), fs)
+
+	require.NoError(t, afero.WriteFile(fs, "/z/data.json", []byte(`{"key": {"value": "first", "other": true}}`), 0400))
+	require.NoError(t, afero.WriteFile(fs, "/a/data.json", []byte(`{"key": {"value": "second"}}`), 0400))
+
+	// the order of the sources is kept, regardless of the directory names
+	merged, err := mergeDataDocuments(ctx, []string{"/z", "/a"}, DeepMerge)
+	require.NoError(t, err)
+
+	expected := map[string]any{}
+	require.NoError(t, json.Unmarshal([]byte(`{"key": {"value": "second", "other": true}}`), &expected))
+	assert.Equal(t, expected, merged)
+}
+
+func TestMergeDataDocumentsInvalid(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	ctx := utils.WithFS(context.Background(), fs)
+
+	require.NoError(t, afero.WriteFile(fs, "/data/data.json", []byte(`["not", "an", "object"]`), 0400))
+
+	_, err := mergeDataDocuments(ctx, []string{"/data"}, DeepMerge)
+	assert.ErrorContains(t, err, "unable to parse data document /data/data.json")
+}
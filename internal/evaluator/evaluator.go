@@ -51,4 +51,7 @@ type Result struct {
 	Message  string                 `json:"msg"`
 	Metadata map[string]interface{} `json:"metadata,omitempty"`
 	Outputs  []string               `json:"outputs,omitempty"`
+	// Severity is the severity level of the result, one of Severities, as
+	// given by the rule, empty if not given
+	Severity string `json:"severity,omitempty"`
 }
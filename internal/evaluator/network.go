@@ -0,0 +1,74 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package evaluator
+
+import (
+	"context"
+	"time"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/topdown"
+)
+
+const networkBuiltinsKey contextKey = "ec.evaluator.network_builtins"
+
+// DefaultNetworkBuiltinsTimeout bounds each call of a network built-in
+// function unless given otherwise
+const DefaultNetworkBuiltinsTimeout = 5 * time.Second
+
+// networkBuiltins are the rego built-in functions reaching out to the network,
+// disabled unless allowed via NetworkBuiltins
+var networkBuiltins = []string{ast.HTTPSend.Name, ast.NetLookupIPAddr.Name}
+
+// NetworkBuiltins allows the policies to use OPA's network built-in functions,
+// e.g. http.send, to fetch data at evaluation time from the allowed hosts.
+type NetworkBuiltins struct {
+	// AllowedHosts are the host names, without a port, the built-in
+	// functions can reach
+	AllowedHosts []string
+	// Timeout bounds each call of a built-in function, regardless of the
+	// timeout requested by the policy
+	Timeout time.Duration
+}
+
+// WithNetworkBuiltins returns a copy of the context allowing the policies to
+// use the network built-in functions.
+func WithNetworkBuiltins(ctx context.Context, n NetworkBuiltins) context.Context {
+	return context.WithValue(ctx, networkBuiltinsKey, n)
+}
+
+func allowedNetworkBuiltins(ctx context.Context) (NetworkBuiltins, bool) {
+	n, ok := ctx.Value(networkBuiltinsKey).(NetworkBuiltins)
+
+	return n, ok
+}
+
+func init() {
+	// The timeout of http.send can be given by the policy in each request, or
+	// globally only via an environment variable read on startup. Calls are
+	// bounded by the timeout of the NetworkBuiltins in the context instead.
+	httpSend := topdown.GetBuiltin(ast.HTTPSend.Name)
+	topdown.RegisterBuiltinFunc(ast.HTTPSend.Name, func(bctx topdown.BuiltinContext, operands []*ast.Term, iter func(*ast.Term) error) error {
+		if n, ok := allowedNetworkBuiltins(bctx.Context); ok && n.Timeout > 0 {
+			ctx, cancel := context.WithTimeout(bctx.Context, n.Timeout)
+			defer cancel()
+			bctx.Context = ctx
+		}
+
+		return httpSend(bctx, operands, iter)
+	})
+}
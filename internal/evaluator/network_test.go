@@ -0,0 +1,89 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package evaluator
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func capabilitiesOf(t *testing.T, ctx context.Context) ast.Capabilities {
+	data, err := strictCapabilities(ctx)
+	require.NoError(t, err)
+
+	var c ast.Capabilities
+	require.NoError(t, json.Unmarshal([]byte(data), &c))
+
+	return c
+}
+
+func hasBuiltin(c ast.Capabilities, name string) bool {
+	for _, b := range c.Builtins {
+		if b.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+func TestStrictCapabilitiesNetworkBuiltins(t *testing.T) {
+	c := capabilitiesOf(t, context.Background())
+	assert.Equal(t, []string{""}, c.AllowNet)
+	assert.False(t, hasBuiltin(c, "http.send"))
+	assert.False(t, hasBuiltin(c, "net.lookup_ip_addr"))
+	assert.False(t, hasBuiltin(c, "opa.runtime"))
+
+	ctx := WithNetworkBuiltins(context.Background(), NetworkBuiltins{AllowedHosts: []string{"allow-list.example.com"}})
+	c = capabilitiesOf(t, ctx)
+	assert.Equal(t, []string{"", "allow-list.example.com"}, c.AllowNet)
+	assert.True(t, hasBuiltin(c, "http.send"))
+	assert.True(t, hasBuiltin(c, "net.lookup_ip_addr"))
+	assert.False(t, hasBuiltin(c, "opa.runtime"))
+}
+
+func TestNetworkBuiltinsTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		<-release
+	}))
+	t.Cleanup(server.Close)
+	t.Cleanup(func() { close(release) })
+
+	query := rego.New(
+		rego.Query(`http.send({"method": "get", "url": input.url, "timeout": "1m"})`),
+		rego.Input(map[string]any{"url": server.URL}),
+		rego.StrictBuiltinErrors(true),
+	)
+
+	ctx := WithNetworkBuiltins(context.Background(), NetworkBuiltins{Timeout: 10 * time.Millisecond})
+	start := time.Now()
+	_, err := query.Eval(ctx)
+	assert.ErrorContains(t, err, "http.send: timed out")
+	assert.Less(t, time.Since(start), time.Minute)
+}
@@ -0,0 +1,89 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package evaluator
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+
+	"github.com/open-policy-agent/conftest/output"
+	conftest "github.com/open-policy-agent/conftest/policy"
+)
+
+const parallelEvaluationKey contextKey = "ec.evaluator.parallel"
+
+// WithParallelEvaluation returns a copy of the context enabling the concurrent
+// evaluation of the policy namespaces of each image or input.
+func WithParallelEvaluation(ctx context.Context, parallel bool) context.Context {
+	return context.WithValue(ctx, parallelEvaluationKey, parallel)
+}
+
+func parallelEvaluation(ctx context.Context) bool {
+	parallel, _ := ctx.Value(parallelEvaluationKey).(bool)
+
+	return parallel
+}
+
+// check runs the conftest checks, evaluating the namespaces concurrently when
+// parallel. Each namespace is evaluated by its own conftest runner, loading its
+// own engine, so no OPA store is shared between the evaluations. The results
+// are in the same order as when evaluating the namespaces serially.
+func (r conftestRunner) check(ctx context.Context, fileList []string) ([]output.CheckResult, error) {
+	if !r.parallel {
+		return r.TestRunner.Run(ctx, fileList)
+	}
+
+	namespaces := r.Namespace
+	if r.AllNamespaces {
+		engine, err := conftest.LoadWithData(r.Policy, r.Data, r.Capabilities, r.Strict)
+		if err != nil {
+			return nil, fmt.Errorf("load: %w", err)
+		}
+		namespaces = engine.Namespaces()
+	}
+
+	results := make([][]output.CheckResult, len(namespaces))
+	errs := make([]error, len(namespaces))
+	slots := make(chan struct{}, runtime.GOMAXPROCS(0))
+	var wg sync.WaitGroup
+	for i, namespace := range namespaces {
+		wg.Add(1)
+		go func(i int, namespace string) {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			runner := r.TestRunner
+			runner.Namespace = []string{namespace}
+			runner.AllNamespaces = false
+			results[i], errs[i] = runner.Run(ctx, fileList)
+		}(i, namespace)
+	}
+	wg.Wait()
+
+	var merged []output.CheckResult
+	for i := range namespaces {
+		if errs[i] != nil {
+			return nil, errs[i]
+		}
+		merged = append(merged, results[i]...)
+	}
+
+	return merged, nil
+}
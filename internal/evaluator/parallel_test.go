@@ -0,0 +1,119 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package evaluator
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	ecc "github.com/enterprise-contract/enterprise-contract-controller/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/policy"
+	"github.com/enterprise-contract/ec-cli/internal/policy/source"
+)
+
+func TestWithParallelEvaluation(t *testing.T) {
+	ctx := context.Background()
+	assert.False(t, parallelEvaluation(ctx))
+	assert.True(t, parallelEvaluation(WithParallelEvaluation(ctx, true)))
+	assert.False(t, parallelEvaluation(WithParallelEvaluation(ctx, false)))
+}
+
+func TestConftestEvaluatorEvaluateParallel(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(path.Join(dir, "inputs"), 0755))
+	require.NoError(t, os.WriteFile(path.Join(dir, "inputs", "data.json"), []byte(`{"value": 42}`), 0600))
+
+	files := fstest.MapFS{}
+	for i := 0; i < 10; i++ {
+		pkg := fmt.Sprintf("pkg%d", i)
+		files[pkg+".rego"] = &fstest.MapFile{Data: []byte(`package ` + pkg + `
+
+import rego.v1
+
+# METADATA
+# title: Denied
+# custom:
+#   short_name: denied
+deny contains result if {
+	input.value == ` + fmt.Sprint(40+i) + `
+	result := {"code": "` + pkg + `.denied", "msg": "Denied"}
+}
+
+# METADATA
+# title: Warned
+# custom:
+#   short_name: warned
+warn contains result if {
+	input.value > ` + fmt.Sprint(i*10) + `
+	result := {"code": "` + pkg + `.warned", "msg": "Warned"}
+}
+`)}
+	}
+
+	rules, err := rulesArchive(t, files)
+	require.NoError(t, err)
+
+	ctx := withCapabilities(context.Background(), testCapabilities)
+
+	config := &mockConfigProvider{}
+	config.On("EffectiveTime").Return(time.Now())
+	config.On("SigstoreOpts").Return(policy.SigstoreOpts{}, nil)
+	config.On("Spec").Return(ecc.EnterpriseContractPolicySpec{})
+
+	evaluator, err := NewConftestEvaluator(ctx, []source.PolicySource{
+		&source.PolicyUrl{
+			Url:  rules,
+			Kind: source.PolicyKind,
+		},
+	}, config, ecc.Source{})
+	require.NoError(t, err)
+
+	target := EvaluationTarget{Inputs: []string{path.Join(dir, "inputs")}}
+
+	serial, serialData, err := evaluator.Evaluate(ctx, target)
+	require.NoError(t, err)
+
+	parallel, parallelData, err := evaluator.Evaluate(WithParallelEvaluation(ctx, true), target)
+	require.NoError(t, err)
+
+	// the order of the namespaces and of the successes is not deterministic
+	// in either case
+	sortSuccesses := func(outcomes []Outcome) {
+		for _, o := range outcomes {
+			sort.Slice(o.Successes, func(i, j int) bool {
+				return o.Successes[i].Metadata[metadataCode].(string) < o.Successes[j].Metadata[metadataCode].(string)
+			})
+		}
+	}
+	sortSuccesses(serial)
+	sortSuccesses(parallel)
+
+	assert.Len(t, parallel, 10)
+	assert.ElementsMatch(t, serial, parallel)
+	assert.Equal(t, serialData, parallelData)
+}
@@ -0,0 +1,66 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package evaluator
+
+import (
+	"context"
+)
+
+// PolicyConfigMetadataKey is the metadata key of the results naming the policy
+// configuration the results were produced with, when validating with multiple
+// policy configurations.
+const PolicyConfigMetadataKey = "policy_config"
+
+// policyConfigEvaluator records the name of the policy configuration in the
+// metadata of the results of the wrapped evaluator.
+type policyConfigEvaluator struct {
+	Evaluator
+	name string
+}
+
+// WithPolicyConfig returns the evaluator recording the name of the policy
+// configuration in the metadata of all the results it produces.
+func WithPolicyConfig(e Evaluator, name string) Evaluator {
+	return policyConfigEvaluator{Evaluator: e, name: name}
+}
+
+func (e policyConfigEvaluator) Evaluate(ctx context.Context, target EvaluationTarget) ([]Outcome, Data, error) {
+	outcomes, data, err := e.Evaluator.Evaluate(ctx, target)
+	if err != nil {
+		return outcomes, data, err
+	}
+
+	for i := range outcomes {
+		o := &outcomes[i]
+		for _, results := range [][]Result{o.Successes, o.Skipped, o.Warnings, o.Failures, o.Exceptions} {
+			e.tag(results)
+		}
+	}
+
+	return outcomes, data, nil
+}
+
+func (e policyConfigEvaluator) tag(results []Result) {
+	for i := range results {
+		metadata := make(map[string]any, len(results[i].Metadata)+1)
+		for k, v := range results[i].Metadata {
+			metadata[k] = v
+		}
+		metadata[PolicyConfigMetadataKey] = e.name
+		results[i].Metadata = metadata
+	}
+}
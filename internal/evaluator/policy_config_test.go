@@ -0,0 +1,56 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package evaluator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type staticEvaluator struct {
+	outcomes []Outcome
+}
+
+func (e staticEvaluator) Evaluate(context.Context, EvaluationTarget) ([]Outcome, Data, error) {
+	return e.outcomes, Data{}, nil
+}
+
+func (staticEvaluator) Destroy() {}
+
+func (staticEvaluator) CapabilitiesPath() string {
+	return ""
+}
+
+func TestWithPolicyConfig(t *testing.T) {
+	original := map[string]any{"code": "test.failure"}
+	e := WithPolicyConfig(staticEvaluator{outcomes: []Outcome{{
+		Failures:  []Result{{Message: "failure", Metadata: original}},
+		Successes: []Result{{Message: "success"}},
+	}}}, "internal")
+
+	outcomes, _, err := e.Evaluate(context.Background(), EvaluationTarget{})
+	require.NoError(t, err)
+	require.Len(t, outcomes, 1)
+	assert.Equal(t, map[string]any{"code": "test.failure", "policy_config": "internal"}, outcomes[0].Failures[0].Metadata)
+	assert.Equal(t, map[string]any{"policy_config": "internal"}, outcomes[0].Successes[0].Metadata)
+	assert.Equal(t, map[string]any{"code": "test.failure"}, original, "the metadata of the results is copied")
+}
@@ -0,0 +1,95 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package evaluator
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	ecc "github.com/enterprise-contract/enterprise-contract-controller/api/v1alpha1"
+
+	"github.com/enterprise-contract/ec-cli/internal/opa"
+	"github.com/enterprise-contract/ec-cli/internal/opa/rule"
+	"github.com/enterprise-contract/ec-cli/internal/policy/source"
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+// CheckRequiredRules fetches the policy sources of the source groups into the
+// work directory and fails if any of the rule codes, given as
+// <package>.<short name>, is not defined by any of them. The fetched sources
+// are cached, they are not fetched again when evaluating the policy.
+func CheckRequiredRules(ctx context.Context, sourceGroups []ecc.Source, workDir string, codes []string) error {
+	if len(codes) == 0 {
+		return nil
+	}
+
+	var sources []source.PolicySource
+	for _, g := range sourceGroups {
+		for _, u := range g.Policy {
+			sources = append(sources, &source.PolicyUrl{Url: u, Kind: source.PolicyKind})
+		}
+	}
+
+	dirs, err := source.GetPolicies(ctx, sources, workDir, false)
+	if err != nil {
+		return err
+	}
+
+	defined := map[string]bool{}
+	fs := utils.FS(ctx)
+	for i, dir := range dirs {
+		if dir == "" {
+			// skipped, see source.WithMissingSources
+			continue
+		}
+
+		annotations, err := opa.InspectDir(fs, dir)
+		if err != nil {
+			return fmt.Errorf("unable to inspect the policy source %s: %w", sources[i].PolicyUrl(), err)
+		}
+
+		for _, a := range annotations {
+			if a.Annotations == nil {
+				continue
+			}
+			if info := rule.RuleInfo(a); info.ShortName != "" {
+				defined[info.Code] = true
+			}
+		}
+	}
+
+	missing := map[string]bool{}
+	for _, c := range codes {
+		if !defined[c] {
+			missing[c] = true
+		}
+	}
+
+	if len(missing) == 0 {
+		return nil
+	}
+
+	m := make([]string, 0, len(missing))
+	for c := range missing {
+		m = append(m, c)
+	}
+	sort.Strings(m)
+
+	return fmt.Errorf("required rule codes not defined by the policy sources: %s", strings.Join(m, ", "))
+}
@@ -0,0 +1,46 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package evaluator
+
+import (
+	"context"
+	"io/fs"
+	"testing"
+
+	ecc "github.com/enterprise-contract/enterprise-contract-controller/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckRequiredRules(t *testing.T) {
+	rego, err := fs.Sub(policies, "__testdir__/simple")
+	require.NoError(t, err)
+
+	rules, err := rulesArchive(t, rego)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	sources := []ecc.Source{{Policy: []string{rules}}}
+
+	assert.NoError(t, CheckRequiredRules(ctx, sources, t.TempDir(), nil))
+	assert.NoError(t, CheckRequiredRules(ctx, sources, t.TempDir(), []string{"a.failure", "b.success"}))
+
+	err = CheckRequiredRules(ctx, sources, t.TempDir(), []string{"c.failure", "a.failure", "a.missing", "c.failure"})
+	assert.EqualError(t, err, "required rule codes not defined by the policy sources: a.missing, c.failure")
+}
@@ -0,0 +1,71 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package evaluator
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Severity levels of the results, from the lowest to the highest
+const (
+	SeverityInfo     = "info"
+	SeverityLow      = "low"
+	SeverityMedium   = "medium"
+	SeverityHigh     = "high"
+	SeverityCritical = "critical"
+)
+
+// Severities lists the supported severity levels, from the lowest to the
+// highest
+var Severities = []string{SeverityInfo, SeverityLow, SeverityMedium, SeverityHigh, SeverityCritical}
+
+const metadataSeverity = "severity"
+
+// ValidateSeverity returns an error if the given severity level is not
+// supported.
+func ValidateSeverity(severity string) error {
+	if !slices.Contains(Severities, severity) {
+		return fmt.Errorf("invalid severity %q, expecting one of: %s", severity, strings.Join(Severities, ", "))
+	}
+
+	return nil
+}
+
+// SeverityAtLeast returns true if the severity of the result, or the given
+// default severity if the result has no supported severity, is at least the
+// given threshold.
+func (r Result) SeverityAtLeast(threshold, defaultSeverity string) bool {
+	severity := slices.Index(Severities, r.Severity)
+	if severity == -1 {
+		severity = slices.Index(Severities, defaultSeverity)
+	}
+
+	return severity >= slices.Index(Severities, threshold)
+}
+
+// resultSeverity determines the severity of the result, the severity set by
+// the rule in the result's metadata takes precedence over the severity
+// annotation of the rule.
+func resultSeverity(r Result, annotated string) string {
+	if severity := ExtractStringFromMetadata(r, metadataSeverity); severity != "" {
+		return strings.ToLower(severity)
+	}
+
+	return annotated
+}
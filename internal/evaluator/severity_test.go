@@ -0,0 +1,109 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package evaluator
+
+import (
+	"context"
+	"os"
+	"path"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	ecc "github.com/enterprise-contract/enterprise-contract-controller/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/policy"
+	"github.com/enterprise-contract/ec-cli/internal/policy/source"
+)
+
+func TestValidateSeverity(t *testing.T) {
+	for _, s := range Severities {
+		assert.NoError(t, ValidateSeverity(s))
+	}
+	assert.EqualError(t, ValidateSeverity("blocker"), `invalid severity "blocker", expecting one of: info, low, medium, high, critical`)
+}
+
+func TestSeverityAtLeast(t *testing.T) {
+	assert.True(t, Result{Severity: SeverityHigh}.SeverityAtLeast(SeverityHigh, SeverityLow))
+	assert.False(t, Result{Severity: SeverityMedium}.SeverityAtLeast(SeverityHigh, SeverityCritical))
+	assert.True(t, Result{}.SeverityAtLeast(SeverityHigh, SeverityCritical), "the default severity applies")
+	assert.False(t, Result{Severity: "unknown"}.SeverityAtLeast(SeverityMedium, SeverityLow), "the default severity applies")
+}
+
+func TestResultSeverity(t *testing.T) {
+	assert.Equal(t, "", resultSeverity(Result{}, ""))
+	assert.Equal(t, SeverityLow, resultSeverity(Result{}, SeverityLow))
+	assert.Equal(t, SeverityHigh, resultSeverity(Result{Metadata: map[string]any{"severity": "High"}}, SeverityLow))
+}
+
+func TestConftestEvaluatorEvaluateSeverity(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(path.Join(dir, "inputs"), 0755))
+	require.NoError(t, os.WriteFile(path.Join(dir, "inputs", "data.json"), []byte("{}"), 0600))
+
+	rules, err := rulesArchive(t, fstest.MapFS{
+		"severity.rego": &fstest.MapFile{Data: []byte(`package severity
+
+import rego.v1
+
+# METADATA
+# title: Annotated
+# custom:
+#   short_name: annotated
+#   severity: critical
+deny contains result if {
+	result := {"code": "severity.annotated", "msg": "Annotated"}
+}
+
+# METADATA
+# title: Unannotated
+# custom:
+#   short_name: unannotated
+warn contains result if {
+	result := {"code": "severity.unannotated", "msg": "Unannotated"}
+}
+`)},
+	})
+	require.NoError(t, err)
+
+	ctx := withCapabilities(context.Background(), testCapabilities)
+
+	config := &mockConfigProvider{}
+	config.On("EffectiveTime").Return(time.Now())
+	config.On("SigstoreOpts").Return(policy.SigstoreOpts{}, nil)
+	config.On("Spec").Return(ecc.EnterpriseContractPolicySpec{})
+
+	evaluator, err := NewConftestEvaluator(ctx, []source.PolicySource{
+		&source.PolicyUrl{
+			Url:  rules,
+			Kind: source.PolicyKind,
+		},
+	}, config, ecc.Source{})
+	require.NoError(t, err)
+
+	results, _, err := evaluator.Evaluate(ctx, EvaluationTarget{Inputs: []string{path.Join(dir, "inputs")}})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	require.Len(t, results[0].Failures, 1)
+	assert.Equal(t, SeverityCritical, results[0].Failures[0].Severity)
+	require.Len(t, results[0].Warnings, 1)
+	assert.Equal(t, "", results[0].Warnings[0].Severity)
+}
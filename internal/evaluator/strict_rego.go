@@ -0,0 +1,38 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package evaluator
+
+import (
+	"context"
+)
+
+const strictRegoKey contextKey = "ec.evaluator.strict_rego"
+
+// WithStrictRego returns a copy of the context that instructs any evaluator
+// created with it to compile the policy rules in OPA's strict mode, failing
+// the evaluation on unused variables and imports, on shadowing the input and
+// data documents, and on deprecated built-in functions. Otherwise the rules
+// are compiled as OPA does by default.
+func WithStrictRego(ctx context.Context, strict bool) context.Context {
+	return context.WithValue(ctx, strictRegoKey, strict)
+}
+
+func strictRego(ctx context.Context) bool {
+	strict, _ := ctx.Value(strictRegoKey).(bool)
+
+	return strict
+}
@@ -0,0 +1,103 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package evaluator
+
+import (
+	"context"
+	"os"
+	"path"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	ecc "github.com/enterprise-contract/enterprise-contract-controller/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/policy"
+	"github.com/enterprise-contract/ec-cli/internal/policy/source"
+)
+
+func TestStrictRegoFromContext(t *testing.T) {
+	assert.False(t, strictRego(context.Background()))
+	assert.True(t, strictRego(WithStrictRego(context.Background(), true)))
+}
+
+func TestConftestEvaluatorStrictRego(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(path.Join(dir, "inputs"), 0755))
+	require.NoError(t, os.WriteFile(path.Join(dir, "inputs", "data.json"), []byte(`{"value": 42}`), 0600))
+
+	// The rule refers to data that is missing, and imports data it does not
+	// use, allowed unless strict
+	rules, err := rulesArchive(t, fstest.MapFS{"optional.rego": &fstest.MapFile{Data: []byte(`package optional
+
+import rego.v1
+
+import data.unused
+
+# METADATA
+# title: Denied
+# custom:
+#   short_name: denied
+deny contains result if {
+	input.value > data.missing.threshold
+	result := {"code": "optional.denied", "msg": "Denied"}
+}
+
+# METADATA
+# title: Warned
+# custom:
+#   short_name: warned
+warn contains result if {
+	input.value == 42
+	result := {"code": "optional.warned", "msg": "Warned"}
+}
+`)}})
+	require.NoError(t, err)
+
+	config := &mockConfigProvider{}
+	config.On("EffectiveTime").Return(time.Now())
+	config.On("SigstoreOpts").Return(policy.SigstoreOpts{}, nil)
+	config.On("Spec").Return(ecc.EnterpriseContractPolicySpec{})
+
+	evaluate := func(ctx context.Context) ([]Outcome, error) {
+		evaluator, err := NewConftestEvaluator(ctx, []source.PolicySource{
+			&source.PolicyUrl{
+				Url:  rules,
+				Kind: source.PolicyKind,
+			},
+		}, config, ecc.Source{})
+		require.NoError(t, err)
+
+		results, _, err := evaluator.Evaluate(ctx, EvaluationTarget{Inputs: []string{path.Join(dir, "inputs")}})
+		return results, err
+	}
+
+	ctx := withCapabilities(context.Background(), testCapabilities)
+
+	results, err := evaluate(ctx)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Empty(t, results[0].Failures)
+	assert.Len(t, results[0].Warnings, 1)
+
+	_, err = evaluate(WithStrictRego(ctx, true))
+	assert.ErrorContains(t, err, "import data.unused unused")
+}
@@ -0,0 +1,133 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package evaluator
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/open-policy-agent/conftest/output"
+)
+
+const traceOptionsKey contextKey = "ec.evaluator.trace"
+
+// TraceOptions scopes the capture of OPA's evaluation trace. As traces are
+// huge, tracing is enabled only when scoped by rule or by image.
+type TraceOptions struct {
+	// Rules are the codes of the rules, e.g. package.rule, or the names of the
+	// packages, whose evaluation is traced. As a package's rules are evaluated
+	// by the same query, the trace covers all the rules of the package. When
+	// empty, the rules of all packages are traced.
+	Rules []string
+	// Images are the images, pinned by digest, or the digests of the images
+	// whose evaluation is traced. When empty, all images are traced.
+	Images []string
+	// Writer receives the traces
+	Writer io.Writer
+}
+
+// Enabled returns true if the trace is scoped by rule or by image.
+func (o TraceOptions) Enabled() bool {
+	return o.Writer != nil && (len(o.Rules) > 0 || len(o.Images) > 0)
+}
+
+// tracer writes the traces of the queries in scope, serializing the writes of
+// the evaluations running in parallel.
+type tracer struct {
+	opts TraceOptions
+	mu   sync.Mutex
+}
+
+// WithTraceOptions returns a copy of the context capturing the evaluation
+// trace of the rules and images in scope.
+func WithTraceOptions(ctx context.Context, opts TraceOptions) context.Context {
+	if !opts.Enabled() {
+		return ctx
+	}
+
+	return context.WithValue(ctx, traceOptionsKey, &tracer{opts: opts})
+}
+
+// traceFor returns the tracer if the evaluation of the target is in scope.
+func traceFor(ctx context.Context, target string) *tracer {
+	t, ok := ctx.Value(traceOptionsKey).(*tracer)
+	if !ok || !t.tracesImage(target) {
+		return nil
+	}
+
+	return t
+}
+
+func (t *tracer) tracesImage(target string) bool {
+	if len(t.opts.Images) == 0 {
+		return true
+	}
+
+	for _, image := range t.opts.Images {
+		if target == image || strings.HasSuffix(target, "@"+image) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (t *tracer) tracesNamespace(namespace string) bool {
+	if len(t.opts.Rules) == 0 {
+		return true
+	}
+
+	for _, rule := range t.opts.Rules {
+		for _, pkg := range []string{rule, rule[:max(strings.LastIndex(rule, "."), 0)]} {
+			if pkg != "" && (namespace == pkg || strings.HasSuffix(namespace, "."+pkg)) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// write writes the traces of the queries of the result in scope.
+func (t *tracer) write(target string, res output.CheckResult) error {
+	if !t.tracesNamespace(res.Namespace) {
+		return nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, q := range res.Queries {
+		if len(q.Traces) == 0 {
+			continue
+		}
+
+		if _, err := fmt.Fprintf(t.opts.Writer, "# Trace of %s for %s\n", q.Query, target); err != nil {
+			return err
+		}
+		for _, line := range q.Traces {
+			if _, err := fmt.Fprintln(t.opts.Writer, line); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,169 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package evaluator
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	ecc "github.com/enterprise-contract/enterprise-contract-controller/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/policy"
+	"github.com/enterprise-contract/ec-cli/internal/policy/source"
+)
+
+const tracedImage = "registry.io/repository/image@sha256:abc"
+
+func TestWithTraceOptionsUnscoped(t *testing.T) {
+	ctx := context.Background()
+	assert.Equal(t, ctx, WithTraceOptions(ctx, TraceOptions{Writer: &bytes.Buffer{}}))
+	assert.Equal(t, ctx, WithTraceOptions(ctx, TraceOptions{Rules: []string{"pkg.rule"}}))
+	assert.Nil(t, traceFor(ctx, tracedImage))
+}
+
+func TestTraceScope(t *testing.T) {
+	cases := []struct {
+		name      string
+		opts      TraceOptions
+		target    string
+		namespace string
+		image     bool
+		rule      bool
+	}{
+		{
+			name:      "rule code",
+			opts:      TraceOptions{Rules: []string{"pkg.rule"}},
+			target:    tracedImage,
+			namespace: "policy.release.pkg",
+			image:     true,
+			rule:      true,
+		},
+		{
+			name:      "package",
+			opts:      TraceOptions{Rules: []string{"pkg"}},
+			namespace: "pkg",
+			image:     true,
+			rule:      true,
+		},
+		{
+			name:      "other package",
+			opts:      TraceOptions{Rules: []string{"pkg.rule"}},
+			namespace: "policy.release.other_pkg",
+			image:     true,
+			rule:      false,
+		},
+		{
+			name:   "image digest",
+			opts:   TraceOptions{Images: []string{"sha256:abc"}},
+			target: tracedImage,
+			image:  true,
+			rule:   true,
+		},
+		{
+			name:   "image reference",
+			opts:   TraceOptions{Images: []string{tracedImage}},
+			target: tracedImage,
+			image:  true,
+			rule:   true,
+		},
+		{
+			name:   "other image",
+			opts:   TraceOptions{Images: []string{"sha256:def"}},
+			target: tracedImage,
+			image:  false,
+			rule:   true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			tr := tracer{opts: c.opts}
+			assert.Equal(t, c.image, tr.tracesImage(c.target))
+			assert.Equal(t, c.rule, tr.tracesNamespace(c.namespace))
+		})
+	}
+}
+
+func TestConftestEvaluatorEvaluateTrace(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(path.Join(dir, "inputs"), 0755))
+	require.NoError(t, os.WriteFile(path.Join(dir, "inputs", "data.json"), []byte(`{"value": 42}`), 0600))
+
+	rule := func(pkg string) *fstest.MapFile {
+		return &fstest.MapFile{Data: []byte(`package ` + pkg + `
+
+import rego.v1
+
+# METADATA
+# title: Rule
+# custom:
+#   short_name: rule
+deny contains result if {
+	input.value == 42
+	result := {"code": "` + pkg + `.rule", "msg": "Denied"}
+}
+`)}
+	}
+
+	rules, err := rulesArchive(t, fstest.MapFS{
+		"traced.rego":   rule("traced"),
+		"untraced.rego": rule("untraced"),
+	})
+	require.NoError(t, err)
+
+	ctx := withCapabilities(context.Background(), testCapabilities)
+
+	config := &mockConfigProvider{}
+	config.On("EffectiveTime").Return(time.Now())
+	config.On("SigstoreOpts").Return(policy.SigstoreOpts{}, nil)
+	config.On("Spec").Return(ecc.EnterpriseContractPolicySpec{})
+
+	evaluator, err := NewConftestEvaluator(ctx, []source.PolicySource{
+		&source.PolicyUrl{
+			Url:  rules,
+			Kind: source.PolicyKind,
+		},
+	}, config, ecc.Source{})
+	require.NoError(t, err)
+
+	target := EvaluationTarget{Inputs: []string{path.Join(dir, "inputs")}, Target: tracedImage}
+
+	trace := bytes.Buffer{}
+	traceCtx := WithTraceOptions(ctx, TraceOptions{Rules: []string{"traced.rule"}, Writer: &trace})
+	results, _, err := evaluator.Evaluate(traceCtx, target)
+	require.NoError(t, err)
+	assert.Len(t, results, 2, "tracing does not change the results")
+
+	assert.Contains(t, trace.String(), "# Trace of data.traced.deny for "+tracedImage)
+	assert.Contains(t, trace.String(), "input.value = 42")
+	assert.NotContains(t, trace.String(), "data.untraced")
+
+	trace.Reset()
+	traceCtx = WithTraceOptions(ctx, TraceOptions{Images: []string{"sha256:def"}, Writer: &trace})
+	_, _, err = evaluator.Evaluate(traceCtx, target)
+	require.NoError(t, err)
+	assert.Empty(t, trace.String())
+}
@@ -78,3 +78,17 @@ func FetchParentImage(ctx context.Context, ref name.Reference) (name.Reference,
 	}
 	return parentRef, nil
 }
+
+// FetchImageLabels retrieves the labels of an image from its OCI registry.
+func FetchImageLabels(ctx context.Context, ref name.Reference) (map[string]string, error) {
+	image, err := oci.NewClient(ctx).Image(ref)
+	if err != nil {
+		return nil, err
+	}
+	configFile, err := image.ConfigFile()
+	if err != nil {
+		return nil, err
+	}
+
+	return configFile.Config.Labels, nil
+}
@@ -0,0 +1,159 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.`
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package format
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"hash"
+	"net/url"
+	"os"
+	"strings"
+
+	"cloud.google.com/go/storage"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	log "github.com/sirupsen/logrus"
+)
+
+// uploader uploads the data to the object with the key in the bucket
+type uploader func(ctx context.Context, bucket, key string, data []byte) error
+
+// uploaders by the scheme of the destination, replaceable in tests
+var uploaders = map[string]uploader{
+	"s3": uploadS3,
+	"gs": uploadGCS,
+}
+
+// objectWriter uploads the output to an object in an S3 or a GCS bucket using
+// the ambient cloud credentials. The object is replaced on each write.
+type objectWriter struct {
+	ctx         context.Context
+	destination string
+	upload      uploader
+	bucket      string
+	key         string
+	// bestEffort logs failures to upload instead of failing
+	bestEffort bool
+	// sum, if set, is the checksum of the bytes uploaded to the object
+	sum hash.Hash
+}
+
+// newObjectWriter returns a writer for a destination given as
+// <scheme>://<bucket>/<key>, false if the destination is not in a bucket.
+func newObjectWriter(ctx context.Context, destination string, bestEffort bool) (*objectWriter, bool, error) {
+	u, err := url.Parse(destination)
+	if err != nil {
+		return nil, false, nil
+	}
+
+	upload, ok := uploaders[u.Scheme]
+	if !ok {
+		return nil, false, nil
+	}
+
+	key := strings.TrimPrefix(u.Path, "/")
+	if u.Host == "" || key == "" || strings.HasSuffix(key, "/") {
+		return nil, true, fmt.Errorf("invalid output destination %q, expecting %s://<bucket>/<object>", destination, u.Scheme)
+	}
+
+	return &objectWriter{ctx: ctx, destination: destination, upload: upload, bucket: u.Host, key: key, bestEffort: bestEffort, sum: sha256.New()}, true, nil
+}
+
+// alongside returns the writer of the object named after the object of this
+// writer with the suffix appended.
+func (w objectWriter) alongside(suffix string) *objectWriter {
+	return &objectWriter{ctx: w.ctx, destination: w.destination + suffix, upload: w.upload, bucket: w.bucket, key: w.key + suffix, bestEffort: w.bestEffort}
+}
+
+func (w objectWriter) Write(data []byte) (int, error) {
+	if err := w.upload(w.ctx, w.bucket, w.key, data); err != nil {
+		if w.bestEffort {
+			log.Warnf("Unable to upload the output to %s: %s", w.destination, err)
+			return len(data), nil
+		}
+		return 0, fmt.Errorf("unable to upload the output to %s: %w", w.destination, err)
+	}
+
+	if w.sum != nil {
+		w.sum.Reset()
+		w.sum.Write(data)
+	}
+
+	return len(data), nil
+}
+
+// uploadS3 uploads to an S3 bucket, or to a bucket of an S3-compatible service
+// at the endpoint set in the AWS_ENDPOINT_URL_S3 or AWS_ENDPOINT_URL
+// environment variable. The region of the bucket is looked up unless
+// configured.
+func uploadS3(ctx context.Context, bucket, key string, data []byte) error {
+	cfg, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	endpoint := os.Getenv("AWS_ENDPOINT_URL_S3")
+	if endpoint == "" {
+		endpoint = os.Getenv("AWS_ENDPOINT_URL")
+	}
+	withEndpoint := func(o *s3.Options) {
+		if endpoint != "" {
+			o.BaseEndpoint = aws.String(endpoint)
+			o.UsePathStyle = true
+		}
+	}
+
+	if cfg.Region == "" {
+		region, err := manager.GetBucketRegion(ctx, s3.NewFromConfig(cfg, withEndpoint, func(o *s3.Options) {
+			o.Region = "us-east-1"
+		}), bucket)
+		if err != nil {
+			return fmt.Errorf("unable to find the region of the bucket %s: %w", bucket, err)
+		}
+		cfg.Region = region
+	}
+
+	_, err = s3.NewFromConfig(cfg, withEndpoint).PutObject(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+		Body:   bytes.NewReader(data),
+	})
+
+	return err
+}
+
+// uploadGCS uploads to a Google Cloud Storage bucket.
+func uploadGCS(ctx context.Context, bucket, key string, data []byte) error {
+	client, err := storage.NewClient(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	w := client.Bucket(bucket).Object(key).NewWriter(ctx)
+	if _, err := w.Write(data); err != nil {
+		_ = w.Close()
+		return err
+	}
+
+	return w.Close()
+}
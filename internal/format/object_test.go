@@ -0,0 +1,145 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.`
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package format
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type upload struct {
+	bucket string
+	key    string
+	data   string
+}
+
+func mockUploaders(t *testing.T, err error) *[]upload {
+	uploads := []upload{}
+	original := uploaders
+	t.Cleanup(func() {
+		uploaders = original
+	})
+
+	u := func(_ context.Context, bucket, key string, data []byte) error {
+		uploads = append(uploads, upload{bucket, key, string(data)})
+		return err
+	}
+	uploaders = map[string]uploader{"s3": u, "gs": u}
+
+	return &uploads
+}
+
+func TestObjectTarget(t *testing.T) {
+	uploads := mockUploaders(t, nil)
+
+	fs := afero.NewMemMapFs()
+	p := NewTargetParser("json", Options{}, &bytes.Buffer{}, fs)
+
+	s3, err := p.Parse("json=s3://bucket/prefix/results.json")
+	require.NoError(t, err)
+	_, err = s3.Write([]byte("{}"))
+	require.NoError(t, err)
+
+	gs, err := p.Parse("yaml=gs://bucket/results.yaml")
+	require.NoError(t, err)
+	_, err = gs.Write([]byte("a: 1"))
+	require.NoError(t, err)
+
+	assert.Equal(t, []upload{
+		{"bucket", "prefix/results.json", "{}"},
+		{"bucket", "results.yaml", "a: 1"},
+	}, *uploads)
+
+	sig, ok := s3.Alongside(".sig")
+	require.True(t, ok)
+	_, err = sig.Write([]byte("signature"))
+	require.NoError(t, err)
+
+	checksum, ok := s3.Checksum()
+	require.True(t, ok)
+	assert.Equal(t, "44136fa355b3678a1146ad16f7e8649e94fb4fc21fe77e8310c060f61caaff8a  results.json\n", checksum)
+
+	assert.Equal(t, upload{"bucket", "prefix/results.json.sig", "signature"}, (*uploads)[2])
+
+	exists, err := afero.Exists(fs, "s3://bucket/prefix/results.json")
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func TestObjectTargetInvalid(t *testing.T) {
+	mockUploaders(t, nil)
+
+	p := NewTargetParser("json", Options{}, &bytes.Buffer{}, afero.NewMemMapFs())
+
+	_, err := p.Parse("json=s3://bucket")
+	assert.EqualError(t, err, `invalid output destination "s3://bucket", expecting s3://<bucket>/<object>`)
+
+	_, err = p.Parse("json=gs://bucket/prefix/")
+	assert.EqualError(t, err, `invalid output destination "gs://bucket/prefix/", expecting gs://<bucket>/<object>`)
+
+	_, err = p.Parse("json+=s3://bucket/results.json")
+	assert.EqualError(t, err, `invalid output destination "s3://bucket/results.json", the output cannot be appended to an object in a bucket`)
+}
+
+func TestObjectTargetUploadFailure(t *testing.T) {
+	mockUploaders(t, errors.New("denied"))
+
+	p := NewTargetParser("json", Options{}, &bytes.Buffer{}, afero.NewMemMapFs())
+
+	target, err := p.Parse("json=s3://bucket/results.json")
+	require.NoError(t, err)
+	_, err = target.Write([]byte("{}"))
+	assert.EqualError(t, err, "unable to upload the output to s3://bucket/results.json: denied")
+
+	target, err = p.Parse("json=s3://bucket/results.json?best-effort=true")
+	require.NoError(t, err)
+	n, err := target.Write([]byte("{}"))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+}
+
+func TestObjectTargetContext(t *testing.T) {
+	original := uploaders
+	t.Cleanup(func() {
+		uploaders = original
+	})
+
+	var uploadCtx context.Context
+	uploaders = map[string]uploader{"s3": func(ctx context.Context, _, _ string, _ []byte) error {
+		uploadCtx = ctx
+		return ctx.Err()
+	}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p := NewTargetParser("json", Options{}, &bytes.Buffer{}, afero.NewMemMapFs()).WithContext(ctx)
+
+	target, err := p.Parse("json=s3://bucket/results.json")
+	require.NoError(t, err)
+
+	cancel()
+	_, err = target.Write([]byte("{}"))
+	assert.EqualError(t, err, "unable to upload the output to s3://bucket/results.json: context canceled")
+	assert.Equal(t, ctx, uploadCtx)
+}
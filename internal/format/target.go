@@ -17,11 +17,22 @@
 package format
 
 import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"hash"
 	"io"
 	"net/url"
+	"os"
+	"path"
+	"path/filepath"
 	"strconv"
 	"strings"
 
+	"github.com/hashicorp/go-multierror"
+	isatty "github.com/mattn/go-isatty"
 	"github.com/spf13/afero"
 )
 
@@ -30,11 +41,19 @@ type Target struct {
 	Format  string
 	Options Options
 	writer  io.Writer
+	// destination identifies where the output is written to, empty for the
+	// default writer
+	destination string
 }
 
 // options that can be configured per Target
 type Options struct {
 	ShowSuccesses bool
+	// JSONCompact disables indenting JSON written to a terminal
+	JSONCompact bool
+	// BestEffort logs failures to upload the output to a bucket instead of
+	// failing
+	BestEffort bool
 }
 
 // mutate parses the given string as URL query parameters and sets the fields
@@ -53,6 +72,22 @@ func (o *Options) mutate(given string) error {
 		}
 	}
 
+	if v := vals.Get("json-compact"); v != "" {
+		if f, err := strconv.ParseBool(v); err == nil {
+			o.JSONCompact = f
+		} else {
+			return err
+		}
+	}
+
+	if v := vals.Get("best-effort"); v != "" {
+		if f, err := strconv.ParseBool(v); err == nil {
+			o.BestEffort = f
+		} else {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -61,17 +96,103 @@ func (t *Target) Write(data []byte) (int, error) {
 	return t.writer.Write(data)
 }
 
+// WriteJSON writes the JSON data, indented when written to a terminal unless
+// compact JSON is requested. Only the whitespace is changed by the indentation.
+func (t *Target) WriteJSON(data []byte) (int, error) {
+	if t.Options.JSONCompact || !isTerminal(t.writer) {
+		return t.Write(data)
+	}
+
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, data, "", "  "); err != nil {
+		return 0, err
+	}
+
+	return t.Write(indented.Bytes())
+}
+
+// Alongside returns a writer of the file, or of the object in a bucket, named
+// after the file or the object the target is written to with the suffix
+// appended, e.g. for a signature of the output. False is returned if the
+// target is not written to a file or a bucket, or is appended to a file.
+func (t *Target) Alongside(suffix string) (io.Writer, bool) {
+	if o, ok := t.writer.(*objectWriter); ok {
+		return o.alongside(suffix), true
+	}
+
+	w, ok := t.writer.(*fileWriter)
+	if !ok || w.append {
+		return nil, false
+	}
+
+	return &fileWriter{path: w.path + suffix, fs: w.fs}, true
+}
+
+// Within returns a writer of the file with the given name within the directory
+// the target is written to, the path given for the target naming a directory
+// rather than a file, e.g. for outputs written as one file per image. False is
+// returned if the target is not written to a file, or is appended to a file.
+func (t *Target) Within(name string) (io.Writer, bool) {
+	w, ok := t.writer.(*fileWriter)
+	if !ok || w.append {
+		return nil, false
+	}
+
+	return &fileWriter{path: filepath.Join(w.path, name), fs: w.fs, mkdir: true}, true
+}
+
+// Checksum returns the SHA-256 checksum of the bytes written to the file, or
+// uploaded to the object in a bucket, the target is written to, in the format
+// of sha256sum: the hex digest and the name of the file or the object. False
+// is returned if the target is not written to a file or a bucket, or is
+// appended to a file.
+func (t *Target) Checksum() (string, bool) {
+	if o, ok := t.writer.(*objectWriter); ok {
+		if o.sum == nil {
+			return "", false
+		}
+		return fmt.Sprintf("%x  %s\n", o.sum.Sum(nil), path.Base(o.key)), true
+	}
+
+	w, ok := t.writer.(*fileWriter)
+	if !ok || w.append || w.sum == nil {
+		return "", false
+	}
+
+	return fmt.Sprintf("%x  %s\n", w.sum.Sum(nil), filepath.Base(w.path)), true
+}
+
+// isTerminal returns true if the writer writes to a terminal, replaceable in
+// tests
+var isTerminal = func(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+
+	return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+}
+
 // TargetParser is responsible for creating Target objects.
 type TargetParser struct {
 	defaultFormat  string
 	defaultWriter  io.Writer
 	defaultOptions Options
 	fs             afero.Fs
+	ctx            context.Context
 }
 
 // NewTargetParser creates a new TargetParser with the given options.
 func NewTargetParser(targetName string, options Options, writer io.Writer, fs afero.Fs) TargetParser {
-	return TargetParser{defaultFormat: targetName, defaultOptions: options, defaultWriter: writer, fs: fs}
+	return TargetParser{defaultFormat: targetName, defaultOptions: options, defaultWriter: writer, fs: fs, ctx: context.Background()}
+}
+
+// WithContext returns a copy of the parser creating targets that upload the
+// output to a bucket within the given context, e.g. of the command, so that
+// the uploads are canceled along with it.
+func (tm TargetParser) WithContext(ctx context.Context) TargetParser {
+	tm.ctx = ctx
+	return tm
 }
 
 // Parse creates a new Target given the provided target name.
@@ -90,28 +211,120 @@ func (tm *TargetParser) Parse(given string) (*Target, error) {
 	var path string
 	target.Format, path, _ = strings.Cut(formatAndPath, "=")
 
+	// Given as <format>+=<path> the output is appended to the file
+	var appending bool
+	target.Format, appending = strings.CutSuffix(target.Format, "+")
+
 	if target.Format == "" {
 		target.Format = tm.defaultFormat
 	}
 
+	// Given as <format>=s3://<bucket>/<object> or <format>=gs://<bucket>/<object>
+	// the output is uploaded to the bucket
 	if path != "" {
-		target.writer = &fileWriter{path: path, fs: tm.fs}
+		w, ok, err := newObjectWriter(tm.ctx, path, target.Options.BestEffort)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			if appending {
+				return nil, fmt.Errorf("invalid output destination %q, the output cannot be appended to an object in a bucket", path)
+			}
+			target.writer = w
+			target.destination = path
+			return &target, nil
+		}
+
+		target.writer = &fileWriter{path: path, fs: tm.fs, append: appending, sum: sha256.New()}
+		target.destination = path
+		if appending {
+			target.destination = "+" + path
+		}
 	}
 
 	return &target, nil
 }
 
-// fileWriter implements a simple Writer wrapper for afero.Fs.
+// ParseAll creates the Targets given the provided target names. Any number of
+// targets can use the same format, each with a different destination, e.g.
+// "json" and "json=archive.json" write the same output to the default writer
+// and to the file. Targets repeating the format, the destination and the
+// options of a previous target are omitted so that the same output is not
+// written twice.
+func (tm *TargetParser) ParseAll(given []string) ([]*Target, error) {
+	type key struct {
+		format      string
+		destination string
+		options     Options
+	}
+
+	var allErrors error
+	seen := map[key]bool{}
+	targets := make([]*Target, 0, len(given))
+	for _, g := range given {
+		target, err := tm.Parse(g)
+		if err != nil {
+			allErrors = multierror.Append(allErrors, err)
+			continue
+		}
+
+		k := key{target.Format, target.destination, target.Options}
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+
+		targets = append(targets, target)
+	}
+
+	return targets, allErrors
+}
+
+// fileWriter implements a simple Writer wrapper for afero.Fs. The file is
+// truncated, unless appending.
 type fileWriter struct {
-	path string
-	fs   afero.Fs
+	path   string
+	fs     afero.Fs
+	append bool
+	// sum, if set, is the checksum of the bytes written to the truncated file
+	sum hash.Hash
+	// mkdir creates the directory of the file, if missing
+	mkdir bool
 }
 
 func (w fileWriter) Write(data []byte) (int, error) {
-	file, err := w.fs.Create(w.path)
+	if w.mkdir {
+		if err := w.fs.MkdirAll(filepath.Dir(w.path), 0755); err != nil {
+			return 0, err
+		}
+	}
+
+	if !w.append {
+		file, err := w.fs.Create(w.path)
+		if err != nil {
+			return 0, err
+		}
+		defer file.Close()
+
+		n, err := file.Write(data)
+		if w.sum != nil {
+			w.sum.Reset()
+			w.sum.Write(data[:n])
+		}
+		return n, err
+	}
+
+	file, err := w.fs.OpenFile(w.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
 	if err != nil {
 		return 0, err
 	}
 	defer file.Close()
-	return file.Write(data)
+
+	n, err := file.Write(data)
+	if err != nil {
+		return n, err
+	}
+
+	// Flushed so that the appended output can be followed, e.g. with tail -f
+	return n, file.Sync()
 }
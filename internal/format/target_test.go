@@ -19,6 +19,8 @@
 package format
 
 import (
+	"bytes"
+	"io"
 	"testing"
 
 	"github.com/spf13/afero"
@@ -38,6 +40,7 @@ func TestTargetParser(t *testing.T) {
 		expectedFormat  string
 		expectedPath    string
 		expectedOptions Options
+		expectedAppend  bool
 		targetName      string
 	}{
 		{name: "all defaults", expectedFormat: defaultFormat, expectedOptions: defaultOptions},
@@ -47,6 +50,9 @@ func TestTargetParser(t *testing.T) {
 		{name: "format and option", expectedFormat: "spam", expectedOptions: Options{ShowSuccesses: true}, targetName: "spam?show-successes=true"},
 		{name: "format no file with option", expectedFormat: "spam", expectedOptions: Options{ShowSuccesses: true}, targetName: "spam=?show-successes=true"},
 		{name: "format with file and option", expectedFormat: "spam", expectedOptions: Options{ShowSuccesses: true}, targetName: "spam=spam.out?show-successes=true", expectedPath: "spam.out"},
+		{name: "compact JSON option", expectedFormat: "spam", expectedOptions: Options{JSONCompact: true}, targetName: "spam?json-compact=true"},
+		{name: "appending to file", expectedFormat: "spam", expectedOptions: defaultOptions, targetName: "spam+=spam.out", expectedPath: "spam.out", expectedAppend: true},
+		{name: "appending to default format", expectedFormat: defaultFormat, expectedOptions: defaultOptions, targetName: "+=spam.out", expectedPath: "spam.out", expectedAppend: true},
 	}
 
 	for _, c := range cases {
@@ -62,6 +68,7 @@ func TestTargetParser(t *testing.T) {
 				assert.Equal(t, defaultWriter, target.writer)
 			} else {
 				assert.Equal(t, c.expectedPath, target.writer.(*fileWriter).path)
+				assert.Equal(t, c.expectedAppend, target.writer.(*fileWriter).append)
 			}
 
 			assert.Equal(t, c.expectedOptions, target.Options)
@@ -69,6 +76,91 @@ func TestTargetParser(t *testing.T) {
 	}
 }
 
+func TestTargetParserParseAll(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	parser := NewTargetParser("default", Options{}, fileWriter{path: "default.out", fs: fs}, fs)
+
+	targets, err := parser.ParseAll([]string{
+		"json",
+		"json=archive.json",
+		"json+=archive.json",
+		"json?show-successes=true",
+		"json",
+		"yaml",
+		"json=archive.json",
+		"json=copy.json",
+	})
+	require.NoError(t, err)
+
+	type parsed struct {
+		Format      string
+		Destination string
+		Options     Options
+	}
+	actual := make([]parsed, 0, len(targets))
+	for _, target := range targets {
+		actual = append(actual, parsed{target.Format, target.destination, target.Options})
+	}
+
+	assert.Equal(t, []parsed{
+		{Format: "json"},
+		{Format: "json", Destination: "archive.json"},
+		{Format: "json", Destination: "+archive.json"},
+		{Format: "json", Options: Options{ShowSuccesses: true}},
+		{Format: "yaml"},
+		{Format: "json", Destination: "copy.json"},
+	}, actual)
+
+	_, err = parser.ParseAll([]string{"json?show-successes=spam", "yaml"})
+	assert.Error(t, err)
+}
+
+func TestTargetWriteJSON(t *testing.T) {
+	terminal := &bytes.Buffer{}
+	original := isTerminal
+	t.Cleanup(func() { isTerminal = original })
+	isTerminal = func(w io.Writer) bool {
+		return w == terminal
+	}
+
+	data := []byte(`{"success":true,"components":[{"name":"a"}]}` + "\n")
+
+	cases := []struct {
+		name     string
+		writer   *bytes.Buffer
+		options  Options
+		expected string
+	}{
+		{
+			name:     "terminal",
+			writer:   terminal,
+			expected: "{\n  \"success\": true,\n  \"components\": [\n    {\n      \"name\": \"a\"\n    }\n  ]\n}\n",
+		},
+		{
+			name:     "terminal compact",
+			writer:   terminal,
+			options:  Options{JSONCompact: true},
+			expected: string(data),
+		},
+		{
+			name:     "not a terminal",
+			writer:   &bytes.Buffer{},
+			expected: string(data),
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			c.writer.Reset()
+			target := Target{Format: "json", Options: c.options, writer: c.writer}
+			_, err := target.WriteJSON(data)
+			require.NoError(t, err)
+			assert.Equal(t, c.expected, c.writer.String())
+			assert.JSONEq(t, string(data), c.writer.String())
+		})
+	}
+}
+
 func TestSimpleFileWriter(t *testing.T) {
 	fs := afero.NewMemMapFs()
 	writer := fileWriter{path: "out", fs: fs}
@@ -78,3 +170,48 @@ func TestSimpleFileWriter(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, "spam", string(actual))
 }
+
+func TestAppendingFileWriter(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "out", []byte("{\"run\":1}\n"), 0644))
+
+	writer := fileWriter{path: "out", fs: fs, append: true}
+	_, err := writer.Write([]byte("{\"run\":2}\n"))
+	assert.NoError(t, err)
+	actual, err := afero.ReadFile(fs, "out")
+	assert.NoError(t, err)
+	assert.Equal(t, "{\"run\":1}\n{\"run\":2}\n", string(actual))
+
+	writer = fileWriter{path: "new", fs: fs, append: true}
+	_, err = writer.Write([]byte("spam"))
+	assert.NoError(t, err)
+	actual, err = afero.ReadFile(fs, "new")
+	assert.NoError(t, err)
+	assert.Equal(t, "spam", string(actual))
+}
+
+func TestTargetChecksum(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	p := NewTargetParser("json", Options{}, &bytes.Buffer{}, fs)
+
+	target, err := p.Parse("json=dir/out.json")
+	require.NoError(t, err)
+	_, err = target.Write([]byte("first"))
+	require.NoError(t, err)
+	_, err = target.Write([]byte("spam"))
+	require.NoError(t, err)
+
+	// sha256sum of "spam", the last bytes written to the file
+	checksum, ok := target.Checksum()
+	assert.True(t, ok)
+	assert.Equal(t, "4e388ab32b10dc8dbc7e28144f552830adc74787c1e2c0824032078a79f227fb  out.json\n", checksum)
+
+	for _, given := range []string{"json", "json+=out.jsonl"} {
+		target, err := p.Parse(given)
+		require.NoError(t, err)
+		_, err = target.Write([]byte("spam"))
+		require.NoError(t, err)
+		_, ok := target.Checksum()
+		assert.False(t, ok, given)
+	}
+}
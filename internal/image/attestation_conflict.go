@@ -0,0 +1,151 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package image
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/enterprise-contract/ec-cli/internal/attestation"
+	"github.com/enterprise-contract/ec-cli/internal/output"
+)
+
+// AttestationConflictOptions configures the built-in attestation conflict
+// check.
+type AttestationConflictOptions struct {
+	// Enabled turns on comparing the key fields of the attestations of the
+	// same predicate type.
+	Enabled bool
+}
+
+const attestationConflictOptionsKey contextKey = "ec.image.attestation_conflict"
+
+// WithAttestationConflictOptions returns a copy of the context instructing
+// ValidateImage to check that the attestations of the same predicate type do
+// not make conflicting claims.
+func WithAttestationConflictOptions(ctx context.Context, opts AttestationConflictOptions) context.Context {
+	return context.WithValue(ctx, attestationConflictOptionsKey, opts)
+}
+
+func attestationConflictOptions(ctx context.Context) AttestationConflictOptions {
+	if opts, ok := ctx.Value(attestationConflictOptionsKey).(AttestationConflictOptions); ok {
+		return opts
+	}
+
+	return AttestationConflictOptions{}
+}
+
+// keyFields extracts the fields of the predicate that the attestations of the
+// same predicate type must agree on.
+type keyFields func(predicateType string, statement []byte) (map[string]string, error)
+
+// conflictKeyFields holds the key fields extractor of each predicate type
+// compared, attestations of other predicate types are not compared.
+var conflictKeyFields = map[string]keyFields{
+	attestation.PredicateSLSAProvenance:   provenanceKeyFields,
+	attestation.PredicateSLSAProvenanceV1: provenanceKeyFields,
+}
+
+func provenanceKeyFields(predicateType string, statement []byte) (map[string]string, error) {
+	n, err := attestation.Normalize(predicateType, statement)
+	if err != nil {
+		return nil, err
+	}
+
+	p, ok := n.(attestation.Provenance)
+	if !ok {
+		return nil, fmt.Errorf("unexpected normalized provenance %T", n)
+	}
+
+	return map[string]string{
+		"builderId": p.BuilderID,
+		"buildType": p.BuildType,
+	}, nil
+}
+
+// checkAttestationConflicts sets the attestation conflict check of the output
+// if enabled.
+func checkAttestationConflicts(ctx context.Context, out *output.Output, attestations []attestation.Attestation) {
+	if !attestationConflictOptions(ctx).Enabled {
+		return
+	}
+
+	conflicts := findAttestationConflicts(attestations)
+
+	var err error
+	if len(conflicts) > 0 {
+		msgs := make([]string, 0, len(conflicts))
+		for _, c := range conflicts {
+			msgs = append(msgs, fmt.Sprintf("%s of %s: %s", c.Field, c.PredicateType, strings.Join(c.Values, ", ")))
+		}
+		err = fmt.Errorf("the attestations disagree on %s", strings.Join(msgs, "; "))
+	}
+
+	out.SetAttestationConflictCheckFromError(conflicts, err)
+}
+
+// findAttestationConflicts returns the key fields with different values in the
+// attestations of the same predicate type, sorted by the predicate type and
+// the field. Empty values are not compared.
+func findAttestationConflicts(attestations []attestation.Attestation) []output.AttestationConflict {
+	type key struct{ predicateType, field string }
+
+	values := map[key][]string{}
+	for _, att := range attestations {
+		predicateType := att.PredicateType()
+		extract, ok := conflictKeyFields[predicateType]
+		if !ok {
+			continue
+		}
+
+		fields, err := extract(predicateType, att.Statement())
+		if err != nil {
+			log.Debugf("Unable to determine the key fields of the %s attestation: %s", predicateType, err)
+			continue
+		}
+
+		for field, value := range fields {
+			k := key{predicateType, field}
+			if value != "" && !slices.Contains(values[k], value) {
+				values[k] = append(values[k], value)
+			}
+		}
+	}
+
+	var conflicts []output.AttestationConflict
+	for k, v := range values {
+		if len(v) < 2 {
+			continue
+		}
+		sort.Strings(v)
+		conflicts = append(conflicts, output.AttestationConflict{PredicateType: k.predicateType, Field: k.field, Values: v})
+	}
+
+	sort.Slice(conflicts, func(i, j int) bool {
+		if conflicts[i].PredicateType != conflicts[j].PredicateType {
+			return conflicts[i].PredicateType < conflicts[j].PredicateType
+		}
+		return conflicts[i].Field < conflicts[j].Field
+	})
+
+	return conflicts
+}
@@ -0,0 +1,66 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package image
+
+import (
+	"context"
+	"testing"
+
+	"github.com/in-toto/in-toto-golang/in_toto/slsa_provenance/common"
+	v02 "github.com/in-toto/in-toto-golang/in_toto/slsa_provenance/v0.2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/attestation"
+	"github.com/enterprise-contract/ec-cli/internal/output"
+)
+
+func TestCheckAttestationConflicts(t *testing.T) {
+	tekton := provenance(t, v02.ProvenancePredicate{
+		Builder:   common.ProvenanceBuilder{ID: "https://tekton.dev/chains/v2"},
+		BuildType: "tekton.dev/v1beta1/TaskRun",
+	})
+	other := provenance(t, v02.ProvenancePredicate{
+		Builder:   common.ProvenanceBuilder{ID: "https://ci.example.com/builder"},
+		BuildType: "tekton.dev/v1beta1/TaskRun",
+	})
+	anonymous := provenance(t, v02.ProvenancePredicate{})
+
+	ctx := context.Background()
+	enabled := WithAttestationConflictOptions(ctx, AttestationConflictOptions{Enabled: true})
+
+	out := &output.Output{}
+	checkAttestationConflicts(ctx, out, []attestation.Attestation{tekton, other})
+	assert.Nil(t, out.AttestationConflictCheck, "the check is disabled by default")
+
+	checkAttestationConflicts(enabled, out, []attestation.Attestation{tekton, anonymous, tekton})
+	require.NotNil(t, out.AttestationConflictCheck)
+	assert.True(t, out.AttestationConflictCheck.Passed)
+	assert.Empty(t, out.AttestationConflicts)
+
+	checkAttestationConflicts(enabled, out, []attestation.Attestation{tekton, other})
+	assert.False(t, out.AttestationConflictCheck.Passed)
+	assert.Equal(t, "Attestation conflict check failed: the attestations disagree on builderId of https://slsa.dev/provenance/v0.2: https://ci.example.com/builder, https://tekton.dev/chains/v2", out.AttestationConflictCheck.Result.Message)
+	assert.Equal(t, []output.AttestationConflict{{
+		PredicateType: v02.PredicateSLSAProvenance,
+		Field:         "builderId",
+		Values:        []string{"https://ci.example.com/builder", "https://tekton.dev/chains/v2"},
+	}}, out.AttestationConflicts)
+	assert.Len(t, out.Violations(), 1)
+}
@@ -0,0 +1,74 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package image
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/enterprise-contract/ec-cli/internal/output"
+)
+
+// Attestation policies, whether images without attestations fail
+const (
+	AttestationsRequired = "required"
+	AttestationsOptional = "optional"
+)
+
+// AttestationPolicies lists the supported attestation policies.
+var AttestationPolicies = []string{AttestationsRequired, AttestationsOptional}
+
+const attestationPolicyKey contextKey = "ec.image.attestation_policy"
+
+// ValidateAttestationPolicy returns an error if the attestation policy is not
+// supported.
+func ValidateAttestationPolicy(policy string) error {
+	if !slices.Contains(AttestationPolicies, policy) {
+		return fmt.Errorf("invalid attestation policy %q, expecting one of: %s", policy, strings.Join(AttestationPolicies, ", "))
+	}
+
+	return nil
+}
+
+// WithAttestationPolicy returns a copy of the context instructing
+// ValidateImage whether images without attestations fail, by default they do.
+// With optional attestations the policy is evaluated against the images
+// without attestations, the policy input telling that there are none.
+func WithAttestationPolicy(ctx context.Context, policy string) context.Context {
+	return context.WithValue(ctx, attestationPolicyKey, policy)
+}
+
+func attestationsOptional(ctx context.Context) bool {
+	policy, _ := ctx.Value(attestationPolicyKey).(string)
+
+	return policy == AttestationsOptional
+}
+
+// allowMissingAttestations passes the attestation signature check of the image
+// if it failed only because the image has no attestations and attestations
+// are optional. Returns true if so.
+func allowMissingAttestations(ctx context.Context, out *output.Output) bool {
+	if !attestationsOptional(ctx) || out.Verification.AttestationSignature.Category != output.MissingAttestation {
+		return false
+	}
+
+	out.SetMissingAttestationsAllowed()
+
+	return true
+}
@@ -0,0 +1,86 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package image
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/enterprise-contract/ec-cli/internal/output"
+)
+
+func TestValidateAttestationPolicy(t *testing.T) {
+	assert.NoError(t, ValidateAttestationPolicy(AttestationsRequired))
+	assert.NoError(t, ValidateAttestationPolicy(AttestationsOptional))
+	assert.EqualError(t, ValidateAttestationPolicy("spam"), `invalid attestation policy "spam", expecting one of: required, optional`)
+}
+
+func TestAllowMissingAttestations(t *testing.T) {
+	cases := []struct {
+		name     string
+		policy   string
+		category string
+		allowed  bool
+	}{
+		{
+			name:     "required by default",
+			category: output.MissingAttestation,
+		},
+		{
+			name:     "required",
+			policy:   AttestationsRequired,
+			category: output.MissingAttestation,
+		},
+		{
+			name:     "optional",
+			policy:   AttestationsOptional,
+			category: output.MissingAttestation,
+			allowed:  true,
+		},
+		{
+			name:     "optional but invalid",
+			policy:   AttestationsOptional,
+			category: output.InvalidAttestation,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctx := context.Background()
+			if c.policy != "" {
+				ctx = WithAttestationPolicy(ctx, c.policy)
+			}
+
+			out := &output.Output{}
+			out.Verification.AttestationSignature = output.Stage{Status: output.StageFailed, Category: c.category}
+
+			assert.Equal(t, c.allowed, allowMissingAttestations(ctx, out))
+			assert.Equal(t, c.allowed, out.AttestationSignatureCheck.Passed)
+			if c.allowed {
+				assert.Equal(t, "No attestations found, allowed as attestations are optional", out.AttestationSignatureCheck.Result.Message)
+				assert.Equal(t, output.Stage{Status: output.StageSkipped, Category: output.MissingAttestation}, out.Verification.AttestationSignature)
+				assert.Empty(t, out.Violations())
+			} else {
+				assert.Equal(t, output.StageFailed, out.Verification.AttestationSignature.Status)
+			}
+		})
+	}
+}
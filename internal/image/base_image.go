@@ -0,0 +1,247 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package image
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/enterprise-contract/ec-cli/internal/attestation"
+	"github.com/enterprise-contract/ec-cli/internal/output"
+	"github.com/enterprise-contract/ec-cli/internal/utils/oci"
+)
+
+// BaseImageOptions configures the built-in base image check.
+type BaseImageOptions struct {
+	// Allowed lists the allowed base images as shell patterns, as supported by
+	// path.Match, matched against the base image reference and its repository.
+	// When empty, the check is disabled.
+	Allowed []string
+}
+
+const baseImageOptionsKey contextKey = "ec.image.base_image"
+
+// WithBaseImageOptions returns a copy of the context instructing ValidateImage
+// to check the base image of each image against the allowed base images.
+func WithBaseImageOptions(ctx context.Context, opts BaseImageOptions) context.Context {
+	return context.WithValue(ctx, baseImageOptionsKey, opts)
+}
+
+func baseImageOptions(ctx context.Context) BaseImageOptions {
+	if opts, ok := ctx.Value(baseImageOptionsKey).(BaseImageOptions); ok {
+		return opts
+	}
+
+	return BaseImageOptions{}
+}
+
+var errNoBaseImage = errors.New("unable to determine the base image")
+
+// slsaMaterials holds the parts of the SLSA Provenance v0.2 predicate listing
+// the materials of the build, and the Tekton Task bundles and step images the
+// build was run with, as recorded by Tekton Chains.
+type slsaMaterials struct {
+	Predicate struct {
+		Materials []struct {
+			URI    string            `json:"uri"`
+			Digest map[string]string `json:"digest"`
+		} `json:"materials"`
+		BuildConfig struct {
+			Tasks []struct {
+				Ref struct {
+					Bundle string `json:"bundle"`
+				} `json:"ref"`
+				Steps []struct {
+					Environment struct {
+						Image string `json:"image"`
+					} `json:"environment"`
+				} `json:"steps"`
+			} `json:"tasks"`
+		} `json:"buildConfig"`
+	} `json:"predicate"`
+}
+
+// buildImages returns the repositories and the digests of the Tekton Task
+// bundles and the step images the build was run with. These are recorded as
+// materials too, but are not the base image.
+func (p slsaMaterials) buildImages() (repositories map[string]bool, digests map[string]bool) {
+	repositories = map[string]bool{}
+	digests = map[string]bool{}
+	add := func(image string) {
+		if image == "" {
+			return
+		}
+		image = strings.TrimPrefix(image, "oci://")
+		if ref, err := name.ParseReference(image); err == nil {
+			repositories[ref.Context().Name()] = true
+		}
+		if _, digest, ok := strings.Cut(image, "@"); ok {
+			digests[digest] = true
+		}
+	}
+
+	for _, t := range p.Predicate.BuildConfig.Tasks {
+		add(t.Ref.Bundle)
+		for _, s := range t.Steps {
+			add(s.Environment.Image)
+		}
+	}
+
+	return
+}
+
+// baseImage returns the container image recorded in the materials that is
+// not a Task bundle or a step image. When more than one such container image
+// is recorded, the base image can not be told apart and none is returned.
+func (p slsaMaterials) baseImage() (string, error) {
+	repositories, digests := p.buildImages()
+
+	var candidates []string
+	for _, m := range p.Predicate.Materials {
+		if !strings.HasPrefix(m.URI, "oci://") {
+			continue
+		}
+
+		image := strings.TrimPrefix(m.URI, "oci://")
+		digest := ""
+		if d, ok := m.Digest["sha256"]; ok {
+			digest = "sha256:" + d
+		}
+		if digests[digest] {
+			continue
+		}
+		if ref, err := name.ParseReference(image); err == nil && repositories[ref.Context().Name()] {
+			continue
+		}
+
+		candidates = append(candidates, withBaseImageDigest(image, digest))
+	}
+
+	switch len(candidates) {
+	case 0:
+		return "", errNoBaseImage
+	case 1:
+		return candidates[0], nil
+	default:
+		return "", fmt.Errorf("%w, the SLSA Provenance records multiple container images: %s", errNoBaseImage, strings.Join(candidates, ", "))
+	}
+}
+
+// DetectBaseImage determines the base image of the image. The base image is
+// taken from the org.opencontainers.image.base.name manifest annotation or
+// image label, and when neither is set from the container image, i.e. the
+// oci:// material, recorded in the SLSA Provenance. Task bundles and step
+// images recorded as materials are not considered, and when more than one
+// container image remains the base image is not determined.
+func DetectBaseImage(ctx context.Context, ref name.Reference, attestations []attestation.Attestation) (string, error) {
+	img, err := oci.NewClient(ctx).Image(ref)
+	if err != nil {
+		return "", err
+	}
+
+	if manifest, err := img.Manifest(); err == nil && manifest.Annotations[oci.BaseImageNameAnnotation] != "" {
+		return withBaseImageDigest(manifest.Annotations[oci.BaseImageNameAnnotation], manifest.Annotations[oci.BaseImageDigestAnnotation]), nil
+	}
+
+	if config, err := img.ConfigFile(); err == nil && config.Config.Labels[oci.BaseImageNameAnnotation] != "" {
+		return withBaseImageDigest(config.Config.Labels[oci.BaseImageNameAnnotation], config.Config.Labels[oci.BaseImageDigestAnnotation]), nil
+	}
+
+	for _, att := range attestations {
+		if att.PredicateType() != attestation.PredicateSLSAProvenance {
+			continue
+		}
+
+		var p slsaMaterials
+		if err := json.Unmarshal(att.Statement(), &p); err != nil {
+			log.Debugf("Unable to parse the SLSA Provenance attestation: %s", err)
+			continue
+		}
+
+		base, err := p.baseImage()
+		if err == errNoBaseImage {
+			// no container image recorded, try the next SLSA Provenance
+			continue
+		}
+
+		return base, err
+	}
+
+	return "", errNoBaseImage
+}
+
+func withBaseImageDigest(base, digest string) string {
+	if digest == "" || strings.Contains(base, "@") {
+		return base
+	}
+
+	return base + "@" + digest
+}
+
+// IsAllowedBaseImage returns true if the base image, or its repository,
+// matches any of the allowed patterns.
+func (o BaseImageOptions) IsAllowedBaseImage(base string) bool {
+	candidates := []string{base}
+	if ref, err := name.ParseReference(base); err == nil {
+		candidates = append(candidates, ref.Context().Name())
+	}
+
+	for _, p := range o.Allowed {
+		for _, c := range candidates {
+			if ok, err := path.Match(p, c); err == nil && ok {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// checkBaseImage sets the base image check of the output if allowed base
+// images are configured.
+func checkBaseImage(ctx context.Context, out *output.Output, attestations []attestation.Attestation) {
+	opts := baseImageOptions(ctx)
+	if len(opts.Allowed) == 0 {
+		return
+	}
+
+	ref, err := name.ParseReference(out.ImageURL)
+	if err != nil {
+		out.SetBaseImageCheckFromError("", fmt.Errorf("unable to parse the image reference %s: %w", out.ImageURL, err))
+		return
+	}
+
+	base, err := DetectBaseImage(ctx, ref, attestations)
+	if err != nil {
+		out.SetBaseImageCheckFromError("", err)
+		return
+	}
+	log.Debugf("Detected base image %s", base)
+
+	if !opts.IsAllowedBaseImage(base) {
+		err = fmt.Errorf("the base image %q is not allowed", base)
+	}
+
+	out.SetBaseImageCheckFromError(base, err)
+}
@@ -0,0 +1,153 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package image
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/in-toto/in-toto-golang/in_toto/slsa_provenance/common"
+	v02 "github.com/in-toto/in-toto-golang/in_toto/slsa_provenance/v0.2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/attestation"
+	"github.com/enterprise-contract/ec-cli/internal/output"
+	"github.com/enterprise-contract/ec-cli/internal/utils/oci"
+	"github.com/enterprise-contract/ec-cli/internal/utils/oci/fake"
+)
+
+func TestDetectBaseImage(t *testing.T) {
+	annotated := mutate.Annotations(empty.Image, map[string]string{
+		oci.BaseImageNameAnnotation:   "registry.io/base/annotated",
+		oci.BaseImageDigestAnnotation: "sha256:aaa",
+	}).(v1.Image)
+
+	labeled, err := mutate.Config(empty.Image, v1.Config{Labels: map[string]string{
+		oci.BaseImageNameAnnotation: "registry.io/base/labeled:1.0",
+	}})
+	require.NoError(t, err)
+
+	withMaterials := []attestation.Attestation{provenance(t, v02.ProvenancePredicate{
+		Materials: []common.ProvenanceMaterial{
+			{URI: "git+https://github.com/org/repo.git", Digest: common.DigestSet{"sha1": "abc"}},
+			{URI: "oci://registry.io/base/material", Digest: common.DigestSet{"sha256": "bbb"}},
+		},
+	})}
+
+	// Tekton Chains records the Task bundles and the step images as materials
+	withBuildImages := []attestation.Attestation{provenance(t, v02.ProvenancePredicate{
+		Materials: []common.ProvenanceMaterial{
+			{URI: "oci://registry.io/tekton/task-buildah", Digest: common.DigestSet{"sha256": "ccc"}},
+			{URI: "oci://registry.io/tekton/buildah", Digest: common.DigestSet{"sha256": "ddd"}},
+			{URI: "oci://registry.io/base/material", Digest: common.DigestSet{"sha256": "bbb"}},
+		},
+		BuildConfig: map[string]any{
+			"tasks": []any{
+				map[string]any{
+					"ref": map[string]any{"bundle": "registry.io/tekton/task-buildah:0.1@sha256:ccc"},
+					"steps": []any{
+						map[string]any{"environment": map[string]any{"image": "oci://registry.io/tekton/buildah@sha256:ddd"}},
+					},
+				},
+			},
+		},
+	})}
+
+	ambiguous := []attestation.Attestation{provenance(t, v02.ProvenancePredicate{
+		Materials: []common.ProvenanceMaterial{
+			{URI: "oci://registry.io/base/one", Digest: common.DigestSet{"sha256": "aaa"}},
+			{URI: "oci://registry.io/base/two", Digest: common.DigestSet{"sha256": "bbb"}},
+		},
+	})}
+
+	cases := []struct {
+		name         string
+		image        v1.Image
+		attestations []attestation.Attestation
+		expected     string
+		err          string
+	}{
+		{name: "annotation", image: annotated, attestations: withMaterials, expected: "registry.io/base/annotated@sha256:aaa"},
+		{name: "label", image: labeled, attestations: withMaterials, expected: "registry.io/base/labeled:1.0"},
+		{name: "materials", image: empty.Image, attestations: withMaterials, expected: "registry.io/base/material@sha256:bbb"},
+		{name: "task bundles and step images", image: empty.Image, attestations: withBuildImages, expected: "registry.io/base/material@sha256:bbb"},
+		{name: "multiple container images", image: empty.Image, attestations: ambiguous, err: "unable to determine the base image, the SLSA Provenance records multiple container images: registry.io/base/one@sha256:aaa, registry.io/base/two@sha256:bbb"},
+		{name: "unknown", image: empty.Image, err: "unable to determine the base image"},
+	}
+
+	ref, err := name.ParseReference(imageRef)
+	require.NoError(t, err)
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			client := fake.FakeClient{}
+			client.On("Image", ref).Return(c.image, nil)
+			ctx := oci.WithClient(context.Background(), &client)
+
+			base, err := DetectBaseImage(ctx, ref, c.attestations)
+			if c.err != "" {
+				assert.EqualError(t, err, c.err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, c.expected, base)
+		})
+	}
+}
+
+func TestIsAllowedBaseImage(t *testing.T) {
+	opts := BaseImageOptions{Allowed: []string{"registry.io/ubi9/*", "registry.io/exact/image"}}
+
+	assert.True(t, opts.IsAllowedBaseImage("registry.io/ubi9/ubi-minimal@sha256:aaa"))
+	assert.True(t, opts.IsAllowedBaseImage("registry.io/exact/image:latest"))
+	assert.False(t, opts.IsAllowedBaseImage("registry.io/other/image"))
+	assert.False(t, opts.IsAllowedBaseImage("registry.io/ubi9/nested/image"))
+}
+
+func TestCheckBaseImage(t *testing.T) {
+	ref, err := name.ParseReference(imageRef)
+	require.NoError(t, err)
+
+	image := mutate.Annotations(empty.Image, map[string]string{
+		oci.BaseImageNameAnnotation: "registry.io/untrusted/base:latest",
+	}).(v1.Image)
+
+	client := fake.FakeClient{}
+	client.On("Image", ref).Return(image, nil)
+	ctx := oci.WithClient(context.Background(), &client)
+
+	out := &output.Output{ImageURL: imageRef}
+	checkBaseImage(ctx, out, nil)
+	assert.Nil(t, out.BaseImageCheck, "the check is disabled by default")
+
+	checkBaseImage(WithBaseImageOptions(ctx, BaseImageOptions{Allowed: []string{"registry.io/trusted/*"}}), out, nil)
+	require.NotNil(t, out.BaseImageCheck)
+	assert.False(t, out.BaseImageCheck.Passed)
+	assert.Equal(t, `Base image check failed: the base image "registry.io/untrusted/base:latest" is not allowed`, out.BaseImageCheck.Result.Message)
+	assert.Equal(t, "registry.io/untrusted/base:latest", out.BaseImage)
+	assert.Len(t, out.Violations(), 1)
+
+	checkBaseImage(WithBaseImageOptions(ctx, BaseImageOptions{Allowed: []string{"registry.io/untrusted/*"}}), out, nil)
+	assert.True(t, out.BaseImageCheck.Passed)
+}
@@ -0,0 +1,131 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package image
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/gobwas/glob"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/enterprise-contract/ec-cli/internal/attestation"
+	"github.com/enterprise-contract/ec-cli/internal/output"
+)
+
+// BuilderIDOptions configures the built-in builder ID check.
+type BuilderIDOptions struct {
+	// Allowed lists the allowed builder IDs as glob patterns, see
+	// CompileBuilderIDPattern. When empty, the check is disabled.
+	Allowed []string
+}
+
+const builderIDOptionsKey contextKey = "ec.image.builder_id"
+
+// WithBuilderIDOptions returns a copy of the context instructing ValidateImage
+// to check the builder ID of the SLSA Provenance of each image against the
+// allowed builder IDs.
+func WithBuilderIDOptions(ctx context.Context, opts BuilderIDOptions) context.Context {
+	return context.WithValue(ctx, builderIDOptionsKey, opts)
+}
+
+func builderIDOptions(ctx context.Context) BuilderIDOptions {
+	if opts, ok := ctx.Value(builderIDOptionsKey).(BuilderIDOptions); ok {
+		return opts
+	}
+
+	return BuilderIDOptions{}
+}
+
+var errNoBuilderID = errors.New("no SLSA Provenance attestation identifies the builder")
+
+// BuilderIDs returns the distinct builder IDs of the verified SLSA Provenance
+// attestations, builder.id of the v0.2 and runDetails.builder.id of the v1
+// predicates.
+func BuilderIDs(attestations []attestation.Attestation) []string {
+	var ids []string
+	for _, att := range attestations {
+		predicateType := att.PredicateType()
+		if predicateType != attestation.PredicateSLSAProvenance && predicateType != attestation.PredicateSLSAProvenanceV1 {
+			continue
+		}
+
+		normalized, err := attestation.Normalize(predicateType, att.Statement())
+		if err != nil {
+			log.Debugf("Unable to parse the SLSA Provenance attestation: %s", err)
+			continue
+		}
+
+		p, ok := normalized.(attestation.Provenance)
+		if !ok {
+			continue
+		}
+
+		if id := p.BuilderID; id != "" && !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+
+	return ids
+}
+
+// CompileBuilderIDPattern compiles the glob pattern of an allowed builder ID.
+// Unlike shell patterns, * and ? also match /, builder IDs being URLs, e.g.
+// https://tekton.dev/chains/* matches https://tekton.dev/chains/v2/nested.
+func CompileBuilderIDPattern(pattern string) (glob.Glob, error) {
+	return glob.Compile(pattern)
+}
+
+// IsAllowedBuilderID returns true if the builder ID matches any of the allowed
+// patterns.
+func (o BuilderIDOptions) IsAllowedBuilderID(id string) bool {
+	for _, p := range o.Allowed {
+		if g, err := CompileBuilderIDPattern(p); err == nil && g.Match(id) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkBuilderID sets the builder ID check of the output if allowed builder
+// IDs are configured. All SLSA Provenance attestations need to be produced by
+// an allowed builder.
+func checkBuilderID(ctx context.Context, out *output.Output, attestations []attestation.Attestation) {
+	opts := builderIDOptions(ctx)
+	if len(opts.Allowed) == 0 {
+		return
+	}
+
+	ids := BuilderIDs(attestations)
+	log.Debugf("Found builder IDs %v", ids)
+
+	var err error
+	if len(ids) == 0 {
+		err = errNoBuilderID
+	}
+	for _, id := range ids {
+		if !opts.IsAllowedBuilderID(id) {
+			err = fmt.Errorf("the builder %q is not allowed", id)
+			break
+		}
+	}
+
+	out.SetBuilderIDCheckFromError(ids, err)
+}
@@ -0,0 +1,95 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package image
+
+import (
+	"context"
+	"testing"
+
+	"github.com/in-toto/in-toto-golang/in_toto/slsa_provenance/common"
+	v02 "github.com/in-toto/in-toto-golang/in_toto/slsa_provenance/v0.2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/attestation"
+	"github.com/enterprise-contract/ec-cli/internal/output"
+)
+
+func TestBuilderIDs(t *testing.T) {
+	tekton := provenance(t, v02.ProvenancePredicate{Builder: common.ProvenanceBuilder{ID: "https://tekton.dev/chains/v2"}})
+	other := provenance(t, v02.ProvenancePredicate{Builder: common.ProvenanceBuilder{ID: "https://ci.example.com/builder"}})
+	anonymous := provenance(t, v02.ProvenancePredicate{})
+
+	assert.Nil(t, BuilderIDs(nil))
+	assert.Nil(t, BuilderIDs([]attestation.Attestation{anonymous}))
+	assert.Equal(t, []string{"https://tekton.dev/chains/v2", "https://ci.example.com/builder"}, BuilderIDs([]attestation.Attestation{tekton, anonymous, other, tekton}))
+
+	v1 := sbom(t, attestation.PredicateSLSAProvenanceV1, map[string]any{
+		"runDetails": map[string]any{"builder": map[string]any{"id": "https://github.com/actions/runner"}},
+	})
+	assert.Equal(t, []string{"https://tekton.dev/chains/v2", "https://github.com/actions/runner"}, BuilderIDs([]attestation.Attestation{tekton, v1}))
+}
+
+func TestIsAllowedBuilderID(t *testing.T) {
+	opts := BuilderIDOptions{Allowed: []string{"https://tekton.dev/chains/*", "https://ci.example.com/builder"}}
+
+	assert.True(t, opts.IsAllowedBuilderID("https://tekton.dev/chains/v2"))
+	assert.True(t, opts.IsAllowedBuilderID("https://ci.example.com/builder"))
+	assert.False(t, opts.IsAllowedBuilderID("https://ci.example.com/other"))
+	assert.True(t, opts.IsAllowedBuilderID("https://tekton.dev/chains/v2/nested"), "* matches across /")
+
+	opts = BuilderIDOptions{Allowed: []string{"https://github.com/*/.github/workflows/release.yaml@refs/tags/v?.*"}}
+	assert.True(t, opts.IsAllowedBuilderID("https://github.com/org/repo/.github/workflows/release.yaml@refs/tags/v1.2.3"))
+	assert.False(t, opts.IsAllowedBuilderID("https://github.com/org/repo/.github/workflows/build.yaml@refs/heads/main"))
+}
+
+func TestCompileBuilderIDPattern(t *testing.T) {
+	_, err := CompileBuilderIDPattern("https://tekton.dev/chains/*")
+	assert.NoError(t, err)
+
+	_, err = CompileBuilderIDPattern("https://tekton.dev/chains/[v")
+	assert.Error(t, err)
+}
+
+func TestCheckBuilderID(t *testing.T) {
+	tekton := provenance(t, v02.ProvenancePredicate{Builder: common.ProvenanceBuilder{ID: "https://tekton.dev/chains/v2"}})
+	other := provenance(t, v02.ProvenancePredicate{Builder: common.ProvenanceBuilder{ID: "https://ci.example.com/builder"}})
+
+	ctx := context.Background()
+	allowed := WithBuilderIDOptions(ctx, BuilderIDOptions{Allowed: []string{"https://tekton.dev/chains/*"}})
+
+	out := &output.Output{}
+	checkBuilderID(ctx, out, []attestation.Attestation{other})
+	assert.Nil(t, out.BuilderIDCheck, "the check is disabled by default")
+
+	checkBuilderID(allowed, out, []attestation.Attestation{tekton})
+	require.NotNil(t, out.BuilderIDCheck)
+	assert.True(t, out.BuilderIDCheck.Passed)
+	assert.Equal(t, []string{"https://tekton.dev/chains/v2"}, out.BuilderIDs)
+
+	checkBuilderID(allowed, out, []attestation.Attestation{tekton, other})
+	assert.False(t, out.BuilderIDCheck.Passed)
+	assert.Equal(t, `Builder ID check failed: the builder "https://ci.example.com/builder" is not allowed`, out.BuilderIDCheck.Result.Message)
+	assert.Equal(t, []string{"https://tekton.dev/chains/v2", "https://ci.example.com/builder"}, out.BuilderIDs)
+	assert.Len(t, out.Violations(), 1)
+
+	checkBuilderID(allowed, out, nil)
+	assert.False(t, out.BuilderIDCheck.Passed)
+	assert.Equal(t, "Builder ID check failed: no SLSA Provenance attestation identifies the builder", out.BuilderIDCheck.Result.Message)
+}
@@ -0,0 +1,57 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package image
+
+import (
+	"context"
+
+	"github.com/enterprise-contract/ec-cli/internal/utils/oci"
+)
+
+// FetchOptions configures how the images are fetched from the registry.
+type FetchOptions struct {
+	// NoMetadataReuse fetches the manifest and the config of an image for each
+	// check reading them, instead of once per image, see
+	// oci.WithImageMetadata. The results are the same either way.
+	NoMetadataReuse bool
+}
+
+const fetchOptionsKey contextKey = "ec.image.fetch"
+
+// WithFetchOptions returns a copy of the context instructing ValidateImage and
+// VerifyImage how to fetch the images.
+func WithFetchOptions(ctx context.Context, opts FetchOptions) context.Context {
+	return context.WithValue(ctx, fetchOptionsKey, opts)
+}
+
+func fetchOptions(ctx context.Context) FetchOptions {
+	if opts, ok := ctx.Value(fetchOptionsKey).(FetchOptions); ok {
+		return opts
+	}
+
+	return FetchOptions{}
+}
+
+// withImageMetadata returns a context fetching the manifest and the config of
+// each image once, unless disabled by the fetch options.
+func withImageMetadata(ctx context.Context) context.Context {
+	if fetchOptions(ctx).NoMetadataReuse {
+		return ctx
+	}
+
+	return oci.WithImageMetadata(ctx)
+}
@@ -0,0 +1,39 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package image
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/enterprise-contract/ec-cli/internal/utils/oci"
+	"github.com/enterprise-contract/ec-cli/internal/utils/oci/fake"
+)
+
+func TestWithImageMetadata(t *testing.T) {
+	client := fake.FakeClient{}
+	ctx := oci.WithClient(context.Background(), &client)
+
+	assert.NotSame(t, &client, oci.NewClient(withImageMetadata(ctx)), "the metadata is reused by default")
+
+	ctx = WithFetchOptions(ctx, FetchOptions{NoMetadataReuse: true})
+	assert.Same(t, &client, oci.NewClient(withImageMetadata(ctx)))
+}
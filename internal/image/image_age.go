@@ -0,0 +1,150 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package image
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+
+	"github.com/enterprise-contract/ec-cli/internal/output"
+	"github.com/enterprise-contract/ec-cli/internal/utils/oci"
+)
+
+const day = 24 * time.Hour
+
+// ImageAgeOptions configures the built-in image age check.
+type ImageAgeOptions struct {
+	// MaxAge is the maximum time allowed since the image was created, as
+	// recorded in the image config. Zero disables the check.
+	MaxAge time.Duration
+}
+
+const imageAgeOptionsKey contextKey = "ec.image.image_age"
+
+// WithImageAgeOptions returns a copy of the context instructing ValidateImage
+// to check that the image was created within the maximum age.
+func WithImageAgeOptions(ctx context.Context, opts ImageAgeOptions) context.Context {
+	return context.WithValue(ctx, imageAgeOptionsKey, opts)
+}
+
+func imageAgeOptions(ctx context.Context) ImageAgeOptions {
+	if opts, ok := ctx.Value(imageAgeOptionsKey).(ImageAgeOptions); ok {
+		return opts
+	}
+
+	return ImageAgeOptions{}
+}
+
+// ParseMaxImageAge parses the maximum image age given as a number of days,
+// e.g. 30d, or as a duration, e.g. 12h.
+func ParseMaxImageAge(s string) (time.Duration, error) {
+	var age time.Duration
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid maximum image age %q, expecting a number of days, e.g. 30d, or a duration, e.g. 12h", s)
+		}
+		age = time.Duration(n) * day
+	} else {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return 0, fmt.Errorf("invalid maximum image age %q, expecting a number of days, e.g. 30d, or a duration, e.g. 12h", s)
+		}
+		age = d
+	}
+
+	if age <= 0 {
+		return 0, fmt.Errorf("the maximum image age %q needs to be positive", s)
+	}
+
+	return age, nil
+}
+
+var errNoImageCreated = errors.New("the image config does not record the time the image was created")
+
+// checkImageAge sets the image age check of the output if a maximum age is
+// configured. The age is measured at the given effective time of the image.
+// Images that do not record the time they were created fail the check, as
+// their age cannot be established.
+func checkImageAge(ctx context.Context, out *output.Output, effectiveTime time.Time) {
+	opts := imageAgeOptions(ctx)
+	if opts.MaxAge == 0 {
+		return
+	}
+
+	created, err := imageCreated(ctx, out.ImageURL)
+	if err != nil {
+		out.SetImageAgeCheckFromError(nil, err)
+		return
+	}
+
+	out.SetImageAgeCheckFromError(&created, verifyImageAge(created, effectiveTime, opts.MaxAge))
+}
+
+func verifyImageAge(created, current time.Time, maxAge time.Duration) error {
+	age := current.Sub(created)
+	if age > maxAge {
+		return fmt.Errorf("the image was created at %s, %s ago, exceeding the allowed %s",
+			created.Format(time.RFC3339), formatAge(age), formatAge(maxAge))
+	}
+
+	return nil
+}
+
+// formatAge formats the duration with the whole days as a number of days, e.g.
+// 30d or 45d3h0m0s.
+func formatAge(d time.Duration) string {
+	d = d.Truncate(time.Second)
+	days, rest := d/day, d%day
+	switch {
+	case days == 0:
+		return rest.String()
+	case rest == 0:
+		return fmt.Sprintf("%dd", days)
+	default:
+		return fmt.Sprintf("%dd%s", days, rest)
+	}
+}
+
+func imageCreated(ctx context.Context, url string) (time.Time, error) {
+	ref, err := name.ParseReference(url)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("unable to parse the image reference %s: %w", url, err)
+	}
+
+	img, err := oci.NewClient(ctx).Image(ref)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("unable to fetch the image %s: %w", url, err)
+	}
+
+	config, err := img.ConfigFile()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("unable to read the config of %s: %w", url, err)
+	}
+
+	if config.Created.IsZero() {
+		return time.Time{}, errNoImageCreated
+	}
+
+	return config.Created.UTC(), nil
+}
@@ -0,0 +1,166 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package image
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/in-toto/in-toto-golang/in_toto"
+	"github.com/in-toto/in-toto-golang/in_toto/slsa_provenance/common"
+	v02 "github.com/in-toto/in-toto-golang/in_toto/slsa_provenance/v0.2"
+	app "github.com/konflux-ci/application-api/api/v1alpha1"
+	cosignoci "github.com/sigstore/cosign/v2/pkg/oci"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/evaluator"
+	"github.com/enterprise-contract/ec-cli/internal/output"
+	"github.com/enterprise-contract/ec-cli/internal/policy"
+	"github.com/enterprise-contract/ec-cli/internal/utils/oci"
+	"github.com/enterprise-contract/ec-cli/internal/utils/oci/fake"
+)
+
+func TestParseMaxImageAge(t *testing.T) {
+	cases := []struct {
+		value    string
+		expected time.Duration
+		err      string
+	}{
+		{value: "30d", expected: 30 * 24 * time.Hour},
+		{value: "12h", expected: 12 * time.Hour},
+		{value: "1d12h", err: `invalid maximum image age "1d12h", expecting a number of days, e.g. 30d, or a duration, e.g. 12h`},
+		{value: "month", err: `invalid maximum image age "month", expecting a number of days, e.g. 30d, or a duration, e.g. 12h`},
+		{value: "0d", err: `the maximum image age "0d" needs to be positive`},
+		{value: "-1h", err: `the maximum image age "-1h" needs to be positive`},
+	}
+
+	for _, c := range cases {
+		t.Run(c.value, func(t *testing.T) {
+			age, err := ParseMaxImageAge(c.value)
+			if c.err != "" {
+				assert.EqualError(t, err, c.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, c.expected, age)
+		})
+	}
+}
+
+func TestFormatAge(t *testing.T) {
+	assert.Equal(t, "30d", formatAge(30*24*time.Hour))
+	assert.Equal(t, "45d3h0m0s", formatAge(45*24*time.Hour+3*time.Hour))
+	assert.Equal(t, "12h0m0s", formatAge(12*time.Hour+time.Millisecond))
+}
+
+func TestCheckImageAge(t *testing.T) {
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	img, err := mutate.CreatedAt(empty.Image, v1.Time{Time: created})
+	require.NoError(t, err)
+
+	ref, err := name.ParseReference(imageRef)
+	require.NoError(t, err)
+
+	client := fake.FakeClient{}
+	client.On("Image", ref).Return(img, nil)
+	ctx := oci.WithClient(context.Background(), &client)
+
+	// The age is measured at the effective time
+	at := created.Add(45*24*time.Hour + 3*time.Hour)
+
+	out := &output.Output{ImageURL: imageRef}
+	checkImageAge(ctx, out, at)
+	assert.Nil(t, out.ImageAgeCheck, "the check is disabled by default")
+
+	checkImageAge(WithImageAgeOptions(ctx, ImageAgeOptions{MaxAge: 30 * 24 * time.Hour}), out, at)
+	require.NotNil(t, out.ImageAgeCheck)
+	assert.False(t, out.ImageAgeCheck.Passed)
+	assert.Equal(t, "Image age check failed: the image was created at 2024-01-01T00:00:00Z, 45d3h0m0s ago, exceeding the allowed 30d", out.ImageAgeCheck.Result.Message)
+	assert.Equal(t, &created, out.ImageCreated)
+	assert.Len(t, out.Violations(), 1)
+
+	checkImageAge(WithImageAgeOptions(ctx, ImageAgeOptions{MaxAge: 60 * 24 * time.Hour}), out, at)
+	assert.True(t, out.ImageAgeCheck.Passed)
+	assert.Equal(t, &created, out.ImageCreated)
+}
+
+func TestCheckImageAgeNotRecorded(t *testing.T) {
+	ref, err := name.ParseReference(imageRef)
+	require.NoError(t, err)
+
+	client := fake.FakeClient{}
+	client.On("Image", ref).Return(empty.Image, nil)
+	ctx := oci.WithClient(context.Background(), &client)
+
+	out := &output.Output{ImageURL: imageRef}
+	checkImageAge(WithImageAgeOptions(ctx, ImageAgeOptions{MaxAge: time.Hour}), out, time.Now())
+	require.NotNil(t, out.ImageAgeCheck)
+	assert.False(t, out.ImageAgeCheck.Passed)
+	assert.Equal(t, "Image age check failed: the image config does not record the time the image was created", out.ImageAgeCheck.Result.Message)
+	assert.Nil(t, out.ImageCreated)
+}
+
+func TestValidateImageAgeAtAttestation(t *testing.T) {
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	finished := created.Add(10 * 24 * time.Hour)
+	img, err := mutate.CreatedAt(empty.Image, v1.Time{Time: created})
+	require.NoError(t, err)
+
+	att := sign(&in_toto.Statement{
+		StatementHeader: in_toto.StatementHeader{
+			Type:          in_toto.StatementInTotoV01,
+			PredicateType: v02.PredicateSLSAProvenance,
+			Subject: []in_toto.Subject{
+				{Name: imageRegistry, Digest: common.DigestSet{"sha256": imageDigest}},
+			},
+		},
+		Predicate: v02.ProvenancePredicate{
+			Metadata: &v02.ProvenanceMetadata{BuildFinishedOn: &finished},
+		},
+	})
+
+	client := fake.FakeClient{}
+	client.On("Head", mock.Anything).Return(&v1.Descriptor{MediaType: types.OCIManifestSchema1}, nil)
+	client.On("Image", refNoTag, mock.Anything).Return(img, nil)
+	client.On("VerifyImageSignatures", refNoTag, mock.Anything).Return([]cosignoci.Signature{validSignature}, true, nil)
+	client.On("VerifyImageAttestations", refNoTag, mock.Anything).Return([]cosignoci.Signature{att}, true, nil)
+	client.On("ResolveDigest", refNoTag).Return("@sha256:"+imageDigest, nil)
+	ctx := oci.WithClient(context.Background(), &client)
+	ctx = WithImageAgeOptions(ctx, ImageAgeOptions{MaxAge: 30 * 24 * time.Hour})
+
+	p, err := policy.NewOfflinePolicy(ctx, policy.AtAttestation)
+	require.NoError(t, err)
+	// Set by the validation of another image sharing the policy
+	p.AttestationTime(created.Add(90 * 24 * time.Hour))
+
+	// Measured when the build of the image finished, 10 days after it was
+	// created, not at the current time or at the time of the other image
+	out, err := ValidateImage(ctx, app.SnapshotComponent{ContainerImage: imageRef}, &app.SnapshotSpec{}, p, []evaluator.Evaluator{}, false)
+	require.NoError(t, err)
+	require.NotNil(t, out.ImageAgeCheck)
+	assert.True(t, out.ImageAgeCheck.Passed, out.ImageAgeCheck.Result)
+}
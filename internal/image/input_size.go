@@ -0,0 +1,74 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package image
+
+import (
+	"context"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/enterprise-contract/ec-cli/internal/output"
+)
+
+// InputSizeOptions configures the built-in policy input size check.
+type InputSizeOptions struct {
+	// MaxSize is the maximum size in bytes of the policy input of an image.
+	// Zero disables the check.
+	MaxSize int64
+}
+
+const inputSizeOptionsKey contextKey = "ec.image.input_size"
+
+// WithInputSizeOptions returns a copy of the context instructing ValidateImage
+// to evaluate the policy only with policy inputs not exceeding the maximum
+// size.
+func WithInputSizeOptions(ctx context.Context, opts InputSizeOptions) context.Context {
+	return context.WithValue(ctx, inputSizeOptionsKey, opts)
+}
+
+func inputSizeOptions(ctx context.Context) InputSizeOptions {
+	if opts, ok := ctx.Value(inputSizeOptionsKey).(InputSizeOptions); ok {
+		return opts
+	}
+
+	return InputSizeOptions{}
+}
+
+// checkInputSize records the size of the policy input in the output, and sets
+// the policy input size check of the output if a maximum size is configured.
+// It returns false if the policy input exceeds the maximum size, in which case
+// the policy is not to be evaluated, e.g. with bloated attestations that would
+// exhaust the memory.
+func checkInputSize(ctx context.Context, out *output.Output, input []byte) bool {
+	size := int64(len(input))
+	log.Debugf("The policy input of %s is %d bytes", out.ImageURL, size)
+	out.PolicyInputSize = &size
+
+	opts := inputSizeOptions(ctx)
+	if opts.MaxSize == 0 {
+		return true
+	}
+
+	var err error
+	if size > opts.MaxSize {
+		err = fmt.Errorf("the policy input of %d bytes exceeds the maximum of %d bytes, the policy was not evaluated", size, opts.MaxSize)
+	}
+	out.SetInputSizeCheckFromError(err)
+
+	return err == nil
+}
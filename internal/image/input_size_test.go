@@ -0,0 +1,52 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package image
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/output"
+)
+
+func TestCheckInputSize(t *testing.T) {
+	input := []byte(`{"attestations":[]}`)
+
+	out := &output.Output{ImageURL: imageRef}
+	assert.True(t, checkInputSize(context.Background(), out, input))
+	assert.Nil(t, out.InputSizeCheck, "the check is disabled by default")
+	require.NotNil(t, out.PolicyInputSize, "the size is always recorded")
+	assert.Equal(t, int64(19), *out.PolicyInputSize)
+
+	ctx := WithInputSizeOptions(context.Background(), InputSizeOptions{MaxSize: 10})
+	assert.False(t, checkInputSize(ctx, out, input))
+	require.NotNil(t, out.InputSizeCheck)
+	assert.False(t, out.InputSizeCheck.Passed)
+	assert.Equal(t, "Policy input size check failed: the policy input of 19 bytes exceeds the maximum of 10 bytes, the policy was not evaluated", out.InputSizeCheck.Result.Message)
+	assert.Equal(t, int64(19), *out.PolicyInputSize)
+	assert.Len(t, out.Violations(), 1)
+
+	ctx = WithInputSizeOptions(context.Background(), InputSizeOptions{MaxSize: 19})
+	assert.True(t, checkInputSize(ctx, out, input))
+	assert.True(t, out.InputSizeCheck.Passed)
+	assert.Equal(t, int64(19), *out.PolicyInputSize)
+}
@@ -0,0 +1,349 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package image
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"github.com/hashicorp/go-multierror"
+	ociremote "github.com/sigstore/cosign/v2/pkg/oci/remote"
+	log "github.com/sirupsen/logrus"
+	"sigs.k8s.io/yaml"
+
+	"github.com/enterprise-contract/ec-cli/internal/format"
+	"github.com/enterprise-contract/ec-cli/internal/utils/oci"
+)
+
+// predicateTypeAnnotation is the annotation cosign sets on the attestation
+// layers with the predicate type of the attestation
+const predicateTypeAnnotation = "predicateType"
+
+// IntrospectionFormats are the formats the introspection can be written in.
+var IntrospectionFormats = []string{"json", "yaml", "text"}
+
+// Introspection describes the manifest of an image and the artifacts attached
+// to it, without evaluating any policy.
+type Introspection struct {
+	// Image is the reference of the image as given
+	Image     string `json:"image,omitempty"`
+	Digest    string `json:"digest,omitempty"`
+	MediaType string `json:"mediaType,omitempty"`
+	// Size in bytes of the manifest, the config and the layers, including the
+	// manifests of an image index
+	Size          int64             `json:"size"`
+	Layers        int               `json:"layers"`
+	Architectures []string          `json:"architectures,omitempty"`
+	Labels        map[string]string `json:"labels,omitempty"`
+	// Manifests holds the introspection of each image of an image index
+	Manifests []Introspection `json:"manifests,omitempty"`
+	// Signatures is the number of signatures attached to the image using the
+	// cosign tag
+	Signatures int `json:"signatures,omitempty"`
+	// Attestations is the number of attestations attached to the image using
+	// the cosign tag
+	Attestations     int      `json:"attestations,omitempty"`
+	AttestationTypes []string `json:"attestationTypes,omitempty"`
+	// Referrers are the artifacts referring to the image found with the OCI
+	// referrers API
+	Referrers []Referrer `json:"referrers,omitempty"`
+	Error     string     `json:"error,omitempty"`
+}
+
+// Referrer is an artifact referring to an image.
+type Referrer struct {
+	Digest       string `json:"digest"`
+	ArtifactType string `json:"artifactType,omitempty"`
+}
+
+// IntrospectionReport holds the introspection of each image.
+type IntrospectionReport struct {
+	Images []Introspection `json:"images"`
+}
+
+// Introspect describes the manifest of the image, and the signatures,
+// attestations and referrers attached to it. Failing to fetch the image is
+// recorded in the introspection.
+func Introspect(ctx context.Context, url string) Introspection {
+	i := Introspection{Image: url}
+
+	ref, err := name.ParseReference(url)
+	if err != nil {
+		i.Error = fmt.Sprintf("unable to parse the image reference: %s", err)
+		return i
+	}
+
+	client := oci.NewClient(ctx)
+	desc, err := client.Head(ref)
+	if err != nil {
+		i.Error = fmt.Sprintf("unable to fetch the descriptor: %s", err)
+		return i
+	}
+
+	digest := ref.Context().Digest(desc.Digest.String())
+	if err := introspectManifest(client, digest, *desc, &i); err != nil {
+		i.Error = err.Error()
+		return i
+	}
+
+	introspectAttached(client, digest, &i)
+
+	return i
+}
+
+func introspectManifest(client oci.Client, ref name.Digest, desc v1.Descriptor, i *Introspection) error {
+	i.Digest = desc.Digest.String()
+	i.MediaType = string(desc.MediaType)
+	i.Size = desc.Size
+
+	if desc.MediaType.IsIndex() {
+		index, err := client.Index(ref)
+		if err != nil {
+			return fmt.Errorf("unable to fetch the image index: %w", err)
+		}
+
+		manifest, err := index.IndexManifest()
+		if err != nil {
+			return fmt.Errorf("unable to read the image index: %w", err)
+		}
+
+		for _, m := range manifest.Manifests {
+			var child Introspection
+			if err := introspectManifest(client, ref.Context().Digest(m.Digest.String()), m, &child); err != nil {
+				return fmt.Errorf("manifest %s: %w", m.Digest, err)
+			}
+			if m.Platform != nil && len(child.Architectures) == 0 {
+				child.Architectures = []string{m.Platform.String()}
+			}
+
+			i.Size += child.Size
+			i.Layers += child.Layers
+			i.Architectures = appendDistinct(i.Architectures, child.Architectures...)
+			i.Manifests = append(i.Manifests, child)
+		}
+
+		return nil
+	}
+
+	img, err := client.Image(ref)
+	if err != nil {
+		return fmt.Errorf("unable to fetch the image: %w", err)
+	}
+
+	manifest, err := img.Manifest()
+	if err != nil {
+		return fmt.Errorf("unable to read the image manifest: %w", err)
+	}
+
+	i.Size += manifest.Config.Size
+	for _, l := range manifest.Layers {
+		i.Size += l.Size
+	}
+	i.Layers = len(manifest.Layers)
+
+	config, err := img.ConfigFile()
+	if err != nil {
+		return fmt.Errorf("unable to read the image config: %w", err)
+	}
+
+	if config.Architecture != "" {
+		i.Architectures = []string{config.Platform().String()}
+	}
+	i.Labels = config.Config.Labels
+
+	return nil
+}
+
+// introspectAttached finds the signatures and the attestations attached using
+// the cosign tags, and the artifacts referring to the image. Artifacts that
+// cannot be found are omitted.
+func introspectAttached(client oci.Client, ref name.Digest, i *Introspection) {
+	if tag, err := ociremote.SignatureTag(ref); err == nil {
+		if layers, err := attachedLayers(client, tag); err == nil {
+			i.Signatures = len(layers)
+		} else {
+			log.Debugf("Unable to find the signatures of %s: %v", ref, err)
+		}
+	}
+
+	if tag, err := ociremote.AttestationTag(ref); err == nil {
+		if layers, err := attachedLayers(client, tag); err == nil {
+			i.Attestations = len(layers)
+			for _, l := range layers {
+				if t := l.Annotations[predicateTypeAnnotation]; t != "" {
+					i.AttestationTypes = appendDistinct(i.AttestationTypes, t)
+				}
+			}
+		} else {
+			log.Debugf("Unable to find the attestations of %s: %v", ref, err)
+		}
+	}
+
+	index, err := client.Referrers(ref)
+	if err != nil {
+		log.Debugf("Unable to find the referrers of %s: %v", ref, err)
+		return
+	}
+
+	manifest, err := index.IndexManifest()
+	if err != nil {
+		log.Debugf("Unable to read the referrers of %s: %v", ref, err)
+		return
+	}
+
+	for _, m := range manifest.Manifests {
+		i.Referrers = append(i.Referrers, Referrer{Digest: m.Digest.String(), ArtifactType: m.ArtifactType})
+	}
+}
+
+// attachedLayers returns the layers of the image with the tag, or no layers if
+// there is no such image.
+func attachedLayers(client oci.Client, tag name.Tag) ([]v1.Descriptor, error) {
+	img, err := client.Image(tag)
+	if err != nil {
+		var terr *transport.Error
+		if errors.As(err, &terr) && terr.StatusCode == http.StatusNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	manifest, err := img.Manifest()
+	if err != nil {
+		return nil, err
+	}
+
+	return manifest.Layers, nil
+}
+
+func appendDistinct(values []string, more ...string) []string {
+	for _, m := range more {
+		if !slices.Contains(values, m) {
+			values = append(values, m)
+		}
+	}
+
+	return values
+}
+
+// WriteAll writes the report to all the given targets, in one of the
+// IntrospectionFormats.
+func (r IntrospectionReport) WriteAll(targets []string, p format.TargetParser) (allErrors error) {
+	if len(targets) == 0 {
+		targets = append(targets, "json")
+	}
+
+	parsed, err := p.ParseAll(targets)
+	if err != nil {
+		allErrors = multierror.Append(allErrors, err)
+	}
+
+	for _, target := range parsed {
+		data, err := r.toFormat(target.Format)
+		if err != nil {
+			allErrors = multierror.Append(allErrors, err)
+			continue
+		}
+
+		if !bytes.HasSuffix(data, []byte{'\n'}) {
+			data = append(data, "\n"...)
+		}
+
+		write := target.Write
+		if target.Format == "json" {
+			write = target.WriteJSON
+		}
+		if _, err := write(data); err != nil {
+			allErrors = multierror.Append(allErrors, err)
+		}
+	}
+	return
+}
+
+func (r IntrospectionReport) toFormat(format string) ([]byte, error) {
+	switch format {
+	case "json":
+		return json.Marshal(r)
+	case "yaml":
+		return yaml.Marshal(r)
+	case "text":
+		var buf bytes.Buffer
+		for n, i := range r.Images {
+			if n > 0 {
+				buf.WriteString("\n")
+			}
+			writeIntrospectionText(&buf, i, "")
+		}
+		return buf.Bytes(), nil
+	default:
+		return nil, fmt.Errorf("%q is not a valid introspection format, expecting one of: %s", format, strings.Join(IntrospectionFormats, ", "))
+	}
+}
+
+func writeIntrospectionText(buf *bytes.Buffer, i Introspection, indent string) {
+	if i.Image != "" {
+		fmt.Fprintf(buf, "%sImage: %s\n", indent, i.Image)
+	}
+	if i.Error != "" {
+		fmt.Fprintf(buf, "%sError: %s\n", indent, i.Error)
+		return
+	}
+	fmt.Fprintf(buf, "%sDigest: %s\n", indent, i.Digest)
+	fmt.Fprintf(buf, "%sMedia Type: %s\n", indent, i.MediaType)
+	fmt.Fprintf(buf, "%sSize: %d bytes\n", indent, i.Size)
+	fmt.Fprintf(buf, "%sLayers: %d\n", indent, i.Layers)
+	if len(i.Architectures) > 0 {
+		fmt.Fprintf(buf, "%sArchitectures: %s\n", indent, strings.Join(i.Architectures, ", "))
+	}
+	if len(i.Labels) > 0 {
+		fmt.Fprintf(buf, "%sLabels:\n", indent)
+		keys := make([]string, 0, len(i.Labels))
+		for k := range i.Labels {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fmt.Fprintf(buf, "%s  %s=%s\n", indent, k, i.Labels[k])
+		}
+	}
+	for _, m := range i.Manifests {
+		fmt.Fprintf(buf, "%sManifest:\n", indent)
+		writeIntrospectionText(buf, m, indent+"  ")
+	}
+	if indent != "" {
+		// signatures and referrers are attached to the image index
+		return
+	}
+	fmt.Fprintf(buf, "Signatures: %d\n", i.Signatures)
+	fmt.Fprintf(buf, "Attestations: %d\n", i.Attestations)
+	if len(i.AttestationTypes) > 0 {
+		fmt.Fprintf(buf, "Attestation Types: %s\n", strings.Join(i.AttestationTypes, ", "))
+	}
+	for _, r := range i.Referrers {
+		fmt.Fprintf(buf, "Referrer: %s %s\n", r.Digest, r.ArtifactType)
+	}
+}
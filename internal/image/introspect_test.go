@@ -0,0 +1,260 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package image
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/format"
+	"github.com/enterprise-contract/ec-cli/internal/utils/oci"
+	"github.com/enterprise-contract/ec-cli/internal/utils/oci/fake"
+)
+
+func introspectionImage(t *testing.T, arch string) v1.Image {
+	img, err := mutate.AppendLayers(empty.Image,
+		static.NewLayer([]byte("layer 1"), types.OCILayer),
+		static.NewLayer([]byte("layer 2"), types.OCILayer),
+	)
+	require.NoError(t, err)
+
+	img, err = mutate.ConfigFile(img, &v1.ConfigFile{
+		OS:           "linux",
+		Architecture: arch,
+		Config:       v1.Config{Labels: map[string]string{"name": "test"}},
+	})
+	require.NoError(t, err)
+
+	return img
+}
+
+func descriptorOf(t *testing.T, img v1.Image) *v1.Descriptor {
+	digest, err := img.Digest()
+	require.NoError(t, err)
+	size, err := img.Size()
+	require.NoError(t, err)
+	mediaType, err := img.MediaType()
+	require.NoError(t, err)
+
+	return &v1.Descriptor{Digest: digest, Size: size, MediaType: mediaType}
+}
+
+func imageSize(t *testing.T, img v1.Image) int64 {
+	manifest, err := img.Manifest()
+	require.NoError(t, err)
+	size := descriptorOf(t, img).Size + manifest.Config.Size
+	for _, l := range manifest.Layers {
+		size += l.Size
+	}
+
+	return size
+}
+
+func refMatching(s string) any {
+	return mock.MatchedBy(func(ref name.Reference) bool {
+		return ref.Name() == s
+	})
+}
+
+type imageIndex = v1.ImageIndex
+
+// referrersIndex is the index returned by the referrers API, the embedded
+// index is aliased not to clash with its ImageIndex method
+type referrersIndex struct {
+	imageIndex
+	manifest *v1.IndexManifest
+}
+
+func (r referrersIndex) IndexManifest() (*v1.IndexManifest, error) {
+	return r.manifest, nil
+}
+
+func TestIntrospectImage(t *testing.T) {
+	img := introspectionImage(t, "amd64")
+	desc := descriptorOf(t, img)
+	digestRef := "registry.local/image@" + desc.Digest.String()
+	tagPrefix := "registry.local/image:sha256-" + desc.Digest.Hex
+
+	sigs, err := mutate.AppendLayers(empty.Image,
+		static.NewLayer([]byte("sig 1"), types.OCILayer),
+		static.NewLayer([]byte("sig 2"), types.OCILayer),
+	)
+	require.NoError(t, err)
+
+	atts, err := mutate.Append(empty.Image, mutate.Addendum{
+		Layer:       static.NewLayer([]byte("att"), types.MediaType("application/vnd.dsse.envelope.v1+json")),
+		Annotations: map[string]string{"predicateType": "https://slsa.dev/provenance/v0.2"},
+	})
+	require.NoError(t, err)
+
+	sbom := v1.Hash{Algorithm: "sha256", Hex: "4e38"}
+	referrers := referrersIndex{manifest: &v1.IndexManifest{
+		Manifests: []v1.Descriptor{{Digest: sbom, ArtifactType: "application/spdx+json"}},
+	}}
+
+	client := fake.FakeClient{}
+	client.On("Head", refMatching("registry.local/image:latest")).Return(desc, nil)
+	client.On("Image", refMatching(digestRef)).Return(img, nil)
+	client.On("Image", refMatching(tagPrefix+".sig")).Return(sigs, nil)
+	client.On("Image", refMatching(tagPrefix+".att")).Return(atts, nil)
+	client.On("Referrers", refMatching(digestRef)).Return(referrers, nil)
+	ctx := oci.WithClient(context.Background(), &client)
+
+	i := Introspect(ctx, "registry.local/image")
+	assert.Equal(t, Introspection{
+		Image:            "registry.local/image",
+		Digest:           desc.Digest.String(),
+		MediaType:        string(types.DockerManifestSchema2),
+		Size:             imageSize(t, img),
+		Layers:           2,
+		Architectures:    []string{"linux/amd64"},
+		Labels:           map[string]string{"name": "test"},
+		Signatures:       2,
+		Attestations:     1,
+		AttestationTypes: []string{"https://slsa.dev/provenance/v0.2"},
+		Referrers:        []Referrer{{Digest: "sha256:4e38", ArtifactType: "application/spdx+json"}},
+	}, i)
+}
+
+func TestIntrospectIndex(t *testing.T) {
+	amd64 := introspectionImage(t, "amd64")
+	arm64 := introspectionImage(t, "arm64")
+	index := mutate.AppendManifests(empty.Index,
+		mutate.IndexAddendum{Add: amd64, Descriptor: v1.Descriptor{Platform: &v1.Platform{OS: "linux", Architecture: "amd64"}}},
+		mutate.IndexAddendum{Add: arm64, Descriptor: v1.Descriptor{Platform: &v1.Platform{OS: "linux", Architecture: "arm64"}}},
+	)
+	digest, err := index.Digest()
+	require.NoError(t, err)
+	size, err := index.Size()
+	require.NoError(t, err)
+	mediaType, err := index.MediaType()
+	require.NoError(t, err)
+
+	notFound := &transport.Error{StatusCode: http.StatusNotFound}
+	client := fake.FakeClient{}
+	client.On("Head", refMatching("registry.local/index:v1")).Return(&v1.Descriptor{Digest: digest, Size: size, MediaType: mediaType}, nil)
+	client.On("Index", refMatching("registry.local/index@"+digest.String())).Return(index, nil)
+	client.On("Image", refMatching("registry.local/index@"+descriptorOf(t, amd64).Digest.String())).Return(amd64, nil)
+	client.On("Image", refMatching("registry.local/index@"+descriptorOf(t, arm64).Digest.String())).Return(arm64, nil)
+	client.On("Image", mock.Anything).Return(nil, notFound)
+	client.On("Referrers", mock.Anything).Return(nil, errors.New("referrers API not supported"))
+	ctx := oci.WithClient(context.Background(), &client)
+
+	i := Introspect(ctx, "registry.local/index:v1")
+	assert.Empty(t, i.Error)
+	assert.Equal(t, digest.String(), i.Digest)
+	assert.Equal(t, size+imageSize(t, amd64)+imageSize(t, arm64), i.Size)
+	assert.Equal(t, 4, i.Layers)
+	assert.Equal(t, []string{"linux/amd64", "linux/arm64"}, i.Architectures)
+	assert.Nil(t, i.Labels)
+	require.Len(t, i.Manifests, 2)
+	assert.Equal(t, map[string]string{"name": "test"}, i.Manifests[1].Labels)
+	assert.Zero(t, i.Signatures)
+	assert.Zero(t, i.Attestations)
+	assert.Empty(t, i.Referrers)
+}
+
+func TestIntrospectInaccessible(t *testing.T) {
+	client := fake.FakeClient{}
+	client.On("Head", mock.Anything).Return(nil, errors.New("UNAUTHORIZED"))
+	ctx := oci.WithClient(context.Background(), &client)
+
+	assert.Equal(t, Introspection{
+		Image: "registry.local/image:tag",
+		Error: "unable to fetch the descriptor: UNAUTHORIZED",
+	}, Introspect(ctx, "registry.local/image:tag"))
+}
+
+func TestIntrospectionReportWriteAll(t *testing.T) {
+	report := IntrospectionReport{Images: []Introspection{
+		{
+			Image:            "registry.local/image:tag",
+			Digest:           "sha256:4e38",
+			MediaType:        string(types.OCIManifestSchema1),
+			Size:             1024,
+			Layers:           2,
+			Architectures:    []string{"linux/amd64"},
+			Labels:           map[string]string{"version": "1", "name": "test"},
+			Signatures:       1,
+			Attestations:     2,
+			AttestationTypes: []string{"https://slsa.dev/provenance/v0.2"},
+		},
+		{Image: "registry.local/other:tag", Error: "unable to fetch the descriptor: UNAUTHORIZED"},
+	}}
+
+	fs := afero.NewMemMapFs()
+	var out bytes.Buffer
+	p := format.NewTargetParser("json", format.Options{}, &out, fs)
+
+	require.NoError(t, report.WriteAll([]string{"text", "json=report.json"}, p))
+	assert.Equal(t, `Image: registry.local/image:tag
+Digest: sha256:4e38
+Media Type: application/vnd.oci.image.manifest.v1+json
+Size: 1024 bytes
+Layers: 2
+Architectures: linux/amd64
+Labels:
+  name=test
+  version=1
+Signatures: 1
+Attestations: 2
+Attestation Types: https://slsa.dev/provenance/v0.2
+
+Image: registry.local/other:tag
+Error: unable to fetch the descriptor: UNAUTHORIZED
+`, out.String())
+
+	j, err := afero.ReadFile(fs, "report.json")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"images": [
+		{
+			"image": "registry.local/image:tag",
+			"digest": "sha256:4e38",
+			"mediaType": "application/vnd.oci.image.manifest.v1+json",
+			"size": 1024,
+			"layers": 2,
+			"architectures": ["linux/amd64"],
+			"labels": {"name": "test", "version": "1"},
+			"signatures": 1,
+			"attestations": 2,
+			"attestationTypes": ["https://slsa.dev/provenance/v0.2"]
+		},
+		{"image": "registry.local/other:tag", "size": 0, "layers": 0, "error": "unable to fetch the descriptor: UNAUTHORIZED"}
+	]}`, string(j))
+
+	assert.EqualError(t, report.WriteAll([]string{"junit"}, p), `1 error occurred:
+	* "junit" is not a valid introspection format, expecting one of: json, yaml, text
+
+`)
+}
@@ -0,0 +1,157 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package image
+
+import (
+	"context"
+	"fmt"
+	"path"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/enterprise-contract/ec-cli/internal/output"
+	"github.com/enterprise-contract/ec-cli/internal/utils/oci"
+)
+
+// MediaTypeOptions configures the built-in media type check. The entries are
+// media types or shell patterns as supported by path.Match, e.g.
+// application/vnd.oci.image.layer.v1.*
+type MediaTypeOptions struct {
+	// Allowed lists the allowed media types. When empty, any media type not
+	// denied is allowed.
+	Allowed []string
+	// Denied lists the media types that are not allowed.
+	Denied []string
+}
+
+func (o MediaTypeOptions) enabled() bool {
+	return len(o.Allowed) > 0 || len(o.Denied) > 0
+}
+
+const mediaTypeOptionsKey contextKey = "ec.image.media_type"
+
+// WithMediaTypeOptions returns a copy of the context instructing ValidateImage
+// to check the media types of the image manifest, config and layers.
+func WithMediaTypeOptions(ctx context.Context, opts MediaTypeOptions) context.Context {
+	return context.WithValue(ctx, mediaTypeOptionsKey, opts)
+}
+
+func mediaTypeOptions(ctx context.Context) MediaTypeOptions {
+	if opts, ok := ctx.Value(mediaTypeOptionsKey).(MediaTypeOptions); ok {
+		return opts
+	}
+
+	return MediaTypeOptions{}
+}
+
+// ValidateMediaType checks a single media type against the options, the
+// element names the part of the image the media type was found on.
+func (o MediaTypeOptions) ValidateMediaType(element string, mediaType types.MediaType) error {
+	if matchesMediaType(o.Denied, mediaType) {
+		return fmt.Errorf("the %s media type %q is denied", element, mediaType)
+	}
+
+	if len(o.Allowed) > 0 && !matchesMediaType(o.Allowed, mediaType) {
+		return fmt.Errorf("the %s media type %q is not allowed", element, mediaType)
+	}
+
+	return nil
+}
+
+func matchesMediaType(patterns []string, mediaType types.MediaType) bool {
+	for _, p := range patterns {
+		if ok, err := path.Match(p, string(mediaType)); err == nil && ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkMediaTypes sets the media type check of the output if allowed or denied
+// media types are configured.
+func checkMediaTypes(ctx context.Context, out *output.Output) {
+	opts := mediaTypeOptions(ctx)
+	if !opts.enabled() {
+		return
+	}
+
+	out.SetMediaTypeCheckFromError(validateMediaTypes(ctx, out.ImageURL, opts))
+}
+
+func validateMediaTypes(ctx context.Context, url string, opts MediaTypeOptions) error {
+	ref, err := name.ParseReference(url)
+	if err != nil {
+		return fmt.Errorf("unable to parse the image reference %s: %w", url, err)
+	}
+
+	client := oci.NewClient(ctx)
+	desc, err := client.Head(ref)
+	if err != nil {
+		return fmt.Errorf("unable to fetch the descriptor of %s: %w", url, err)
+	}
+
+	if err := opts.ValidateMediaType("manifest", desc.MediaType); err != nil {
+		return err
+	}
+
+	if desc.MediaType.IsIndex() {
+		index, err := client.Index(ref)
+		if err != nil {
+			return fmt.Errorf("unable to fetch the image index %s: %w", url, err)
+		}
+
+		manifest, err := index.IndexManifest()
+		if err != nil {
+			return fmt.Errorf("unable to read the image index %s: %w", url, err)
+		}
+
+		for _, m := range manifest.Manifests {
+			if err := opts.ValidateMediaType(fmt.Sprintf("manifest %s", m.Digest), m.MediaType); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	img, err := client.Image(ref)
+	if err != nil {
+		return fmt.Errorf("unable to fetch the image %s: %w", url, err)
+	}
+
+	manifest, err := img.Manifest()
+	if err != nil {
+		return fmt.Errorf("unable to read the image manifest of %s: %w", url, err)
+	}
+
+	if err := opts.ValidateMediaType("config", manifest.Config.MediaType); err != nil {
+		return err
+	}
+
+	for _, l := range manifest.Layers {
+		if err := opts.ValidateMediaType(fmt.Sprintf("layer %s", l.Digest), l.MediaType); err != nil {
+			return err
+		}
+	}
+
+	log.Debugf("The media types of %s are allowed", url)
+
+	return nil
+}
@@ -0,0 +1,76 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package image
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/output"
+	"github.com/enterprise-contract/ec-cli/internal/utils/oci"
+	"github.com/enterprise-contract/ec-cli/internal/utils/oci/fake"
+)
+
+func TestValidateMediaType(t *testing.T) {
+	opts := MediaTypeOptions{
+		Allowed: []string{"application/vnd.oci.image.layer.v1.*"},
+		Denied:  []string{"application/vnd.oci.image.layer.v1.tar"},
+	}
+
+	assert.NoError(t, opts.ValidateMediaType("layer", types.OCILayer))
+	assert.EqualError(t, opts.ValidateMediaType("layer", types.OCIUncompressedLayer), `the layer media type "application/vnd.oci.image.layer.v1.tar" is denied`)
+	assert.EqualError(t, opts.ValidateMediaType("layer", types.DockerLayer), `the layer media type "application/vnd.docker.image.rootfs.diff.tar.gzip" is not allowed`)
+	assert.NoError(t, MediaTypeOptions{}.ValidateMediaType("layer", types.DockerLayer))
+}
+
+func TestCheckMediaTypes(t *testing.T) {
+	img, err := mutate.AppendLayers(mutate.MediaType(empty.Image, types.OCIManifestSchema1), static.NewLayer([]byte("layer"), types.MediaType("application/x-unexpected")))
+	require.NoError(t, err)
+	img = mutate.ConfigMediaType(img, types.OCIConfigJSON)
+
+	ref, err := name.ParseReference(imageRef)
+	require.NoError(t, err)
+
+	client := fake.FakeClient{}
+	client.On("Head", ref).Return(&v1.Descriptor{MediaType: types.OCIManifestSchema1}, nil)
+	client.On("Image", ref).Return(img, nil)
+	ctx := oci.WithClient(context.Background(), &client)
+
+	out := &output.Output{ImageURL: imageRef}
+	checkMediaTypes(ctx, out)
+	assert.Nil(t, out.MediaTypeCheck, "the check is disabled by default")
+
+	checkMediaTypes(WithMediaTypeOptions(ctx, MediaTypeOptions{Allowed: []string{"application/vnd.oci.*"}}), out)
+	require.NotNil(t, out.MediaTypeCheck)
+	assert.False(t, out.MediaTypeCheck.Passed)
+	assert.Regexp(t, `^Media type check failed: the layer sha256:[0-9a-f]+ media type "application/x-unexpected" is not allowed$`, out.MediaTypeCheck.Result.Message)
+	assert.Len(t, out.Violations(), 1)
+
+	checkMediaTypes(WithMediaTypeOptions(ctx, MediaTypeOptions{Denied: []string{"application/vnd.docker.*"}}), out)
+	assert.True(t, out.MediaTypeCheck.Passed)
+}
@@ -0,0 +1,73 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package image
+
+import (
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+)
+
+// registryAliases maps the alternative hostnames of registries to the
+// canonical hostname
+var registryAliases = map[string]string{
+	"docker.io":               name.DefaultRegistry,
+	"registry-1.docker.io":    name.DefaultRegistry,
+	"registry.hub.docker.com": name.DefaultRegistry,
+}
+
+// NormalizeReference canonicalizes the image reference, so that references to
+// the same image compare equal: the registry hostname is lowercased and
+// aliases of Docker Hub are replaced with index.docker.io, the default registry
+// and namespace are added, and the default tag is added unless the reference
+// is pinned by digest. References that cannot be parsed are returned as is.
+func NormalizeReference(url string) string {
+	base, digest, pinned := strings.Cut(url, "@")
+	if pinned {
+		if _, err := v1.NewHash(digest); err != nil {
+			return url
+		}
+	}
+
+	tag, err := name.NewTag(base)
+	if err != nil {
+		return url
+	}
+
+	registry := strings.ToLower(tag.RegistryStr())
+	if alias, ok := registryAliases[registry]; ok {
+		registry = alias
+	}
+
+	repository := tag.RepositoryStr()
+	if registry == name.DefaultRegistry && !strings.Contains(repository, "/") {
+		repository = "library/" + repository
+	}
+
+	normalized := registry + "/" + repository
+	// The tag is explicit if given after the last path component, the port of
+	// the registry is not a tag
+	if explicit := strings.Contains(base[strings.LastIndex(base, "/")+1:], ":"); explicit || !pinned {
+		normalized += ":" + tag.TagStr()
+	}
+	if pinned {
+		normalized += "@" + digest
+	}
+
+	return normalized
+}
@@ -0,0 +1,50 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package image
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeReference(t *testing.T) {
+	digest := "sha256:" + imageDigest
+
+	cases := []struct {
+		url      string
+		expected string
+	}{
+		{url: "busybox", expected: "index.docker.io/library/busybox:latest"},
+		{url: "docker.io/busybox:1.36", expected: "index.docker.io/library/busybox:1.36"},
+		{url: "registry-1.docker.io/library/busybox:1.36", expected: "index.docker.io/library/busybox:1.36"},
+		{url: "docker.io/user/app@" + digest, expected: "index.docker.io/user/app@" + digest},
+		{url: "Registry.IO/ns/app:v1@" + digest, expected: "registry.io/ns/app:v1@" + digest},
+		{url: "localhost:5000/app@" + digest, expected: "localhost:5000/app@" + digest},
+		{url: "localhost:5000/app", expected: "localhost:5000/app:latest"},
+		{url: "registry.io/ns/app@sha256:spam", expected: "registry.io/ns/app@sha256:spam"},
+		{url: "registry.io/ns/APP", expected: "registry.io/ns/APP"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.url, func(t *testing.T) {
+			assert.Equal(t, c.expected, NormalizeReference(c.url))
+		})
+	}
+}
@@ -0,0 +1,134 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package image
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+
+	"github.com/enterprise-contract/ec-cli/internal/output"
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+// OperatorDigestOptions configures the digests an operator denies or allows
+// regardless of the policy.
+type OperatorDigestOptions struct {
+	// Denied lists the digests of the images failed outright
+	Denied []string
+	// Allowed lists the digests of the images passed outright, e.g. to break
+	// the glass in an emergency
+	Allowed []string
+}
+
+const operatorDigestOptionsKey contextKey = "ec.image.operator_digest"
+
+// WithOperatorDigestOptions returns a copy of the context instructing
+// ValidateImage to fail the images with a denied digest, and to pass the
+// images with an allowed digest, without verifying or evaluating them.
+func WithOperatorDigestOptions(ctx context.Context, opts OperatorDigestOptions) context.Context {
+	return context.WithValue(ctx, operatorDigestOptionsKey, opts)
+}
+
+func operatorDigestOptions(ctx context.Context) OperatorDigestOptions {
+	if opts, ok := ctx.Value(operatorDigestOptionsKey).(OperatorDigestOptions); ok {
+		return opts
+	}
+
+	return OperatorDigestOptions{}
+}
+
+// ParseOperatorDigests parses the digests, each given as a digest, e.g.
+// sha256:..., or as a file listing the digests one per line. Empty lines and
+// lines starting with # in the files are ignored.
+func ParseOperatorDigests(ctx context.Context, values []string) ([]string, error) {
+	var digests []string
+	for _, v := range values {
+		if _, err := v1.NewHash(v); err == nil {
+			digests = append(digests, v)
+			continue
+		}
+
+		content, err := afero.ReadFile(utils.FS(ctx), v)
+		if err != nil {
+			return nil, fmt.Errorf("%q is neither a digest nor a readable file: %w", v, err)
+		}
+
+		scanner := bufio.NewScanner(bytes.NewReader(content))
+		for line := 1; scanner.Scan(); line++ {
+			d := strings.TrimSpace(scanner.Text())
+			if d == "" || strings.HasPrefix(d, "#") {
+				continue
+			}
+			if _, err := v1.NewHash(d); err != nil {
+				return nil, fmt.Errorf("%s:%d: invalid digest %q: %w", v, line, d, err)
+			}
+			digests = append(digests, d)
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("unable to read %s: %w", v, err)
+		}
+	}
+
+	return digests, nil
+}
+
+// operatorDecided returns true if the operator denies or allows the image
+// digest.
+func operatorDecided(ctx context.Context, digest string) bool {
+	opts := operatorDigestOptions(ctx)
+
+	return slices.Contains(opts.Denied, digest) || slices.Contains(opts.Allowed, digest)
+}
+
+// checkOperatorDigest sets the operator digest check of the output if the
+// digest of the image is denied or allowed, and returns true if so. A denied
+// digest takes precedence over an allowed one.
+func checkOperatorDigest(ctx context.Context, out *output.Output) bool {
+	opts := operatorDigestOptions(ctx)
+	if len(opts.Denied) == 0 && len(opts.Allowed) == 0 {
+		return false
+	}
+
+	ref, err := name.NewDigest(out.ImageURL)
+	if err != nil {
+		log.Debugf("Unable to determine the digest of %s: %v", out.ImageURL, err)
+		return false
+	}
+	digest := ref.DigestStr()
+
+	switch {
+	case slices.Contains(opts.Denied, digest):
+		log.Warnf("The image %s is denied by the operator", out.ImageURL)
+		out.SetOperatorDigestCheck(output.OperatorDenied, digest)
+	case slices.Contains(opts.Allowed, digest):
+		log.Warnf("The image %s is allowed by the operator, it is neither verified nor evaluated", out.ImageURL)
+		out.SetOperatorDigestCheck(output.OperatorAllowed, digest)
+	default:
+		return false
+	}
+
+	return true
+}
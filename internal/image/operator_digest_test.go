@@ -0,0 +1,118 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package image
+
+import (
+	"context"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/output"
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+func TestParseOperatorDigests(t *testing.T) {
+	digest := "sha256:" + imageDigest
+	other := "sha256:" + "0000000000000000000000000000000000000000000000000000000000000000"
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "denied.txt", []byte("# incident 42\n"+other+"\n\n"), 0o644))
+	require.NoError(t, afero.WriteFile(fs, "bad.txt", []byte("sha256:spam\n"), 0o644))
+	ctx := utils.WithFS(context.Background(), fs)
+
+	digests, err := ParseOperatorDigests(ctx, []string{digest, "denied.txt"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{digest, other}, digests)
+
+	_, err = ParseOperatorDigests(ctx, []string{"bad.txt"})
+	assert.ErrorContains(t, err, `bad.txt:1: invalid digest "sha256:spam"`)
+
+	_, err = ParseOperatorDigests(ctx, []string{"missing.txt"})
+	assert.ErrorContains(t, err, `"missing.txt" is neither a digest nor a readable file`)
+}
+
+func TestCheckOperatorDigest(t *testing.T) {
+	digest := "sha256:" + imageDigest
+	url := imageRegistry + "@" + digest
+
+	cases := []struct {
+		name     string
+		opts     OperatorDigestOptions
+		decided  bool
+		decision string
+		passed   bool
+		message  string
+	}{
+		{
+			name: "not configured",
+		},
+		{
+			name: "other digests",
+			opts: OperatorDigestOptions{Denied: []string{"sha256:0000"}, Allowed: []string{"sha256:1111"}},
+		},
+		{
+			name:     "denied",
+			opts:     OperatorDigestOptions{Denied: []string{digest}},
+			decided:  true,
+			decision: output.OperatorDenied,
+			message:  "Denied by operator: the image digest " + digest + " is on the deny list",
+		},
+		{
+			name:     "allowed",
+			opts:     OperatorDigestOptions{Allowed: []string{digest}},
+			decided:  true,
+			decision: output.OperatorAllowed,
+			passed:   true,
+			message:  "Allowed by operator: the image digest " + digest + " is on the allow list, the image is neither verified nor evaluated",
+		},
+		{
+			name:     "denied takes precedence",
+			opts:     OperatorDigestOptions{Denied: []string{digest}, Allowed: []string{digest}},
+			decided:  true,
+			decision: output.OperatorDenied,
+			message:  "Denied by operator: the image digest " + digest + " is on the deny list",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctx := WithOperatorDigestOptions(context.Background(), c.opts)
+			out := &output.Output{ImageURL: url}
+
+			assert.Equal(t, c.decided, checkOperatorDigest(ctx, out))
+			assert.Equal(t, c.decision, out.OperatorDecision)
+			if !c.decided {
+				assert.Nil(t, out.OperatorDigestCheck)
+				return
+			}
+
+			require.NotNil(t, out.OperatorDigestCheck)
+			assert.Equal(t, c.passed, out.OperatorDigestCheck.Passed)
+			assert.Equal(t, c.message, out.OperatorDigestCheck.Result.Message)
+			if c.passed {
+				assert.Empty(t, out.Violations())
+			} else {
+				assert.Len(t, out.Violations(), 1)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package image
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+
+	"github.com/enterprise-contract/ec-cli/internal/output"
+)
+
+// PinnedDigest is the digest the tag of an image is expected to resolve to.
+type PinnedDigest struct {
+	// Tag is the fully qualified repository and tag of the image
+	Tag    string
+	Digest string
+}
+
+// ParsePinnedDigest parses the pinned digest given as repository:tag@digest.
+// When the tag is omitted, the latest tag is pinned.
+func ParsePinnedDigest(s string) (PinnedDigest, error) {
+	ref, digest, ok := strings.Cut(s, "@")
+	if !ok {
+		return PinnedDigest{}, fmt.Errorf("the pinned digest %q is not in the repository:tag@digest form", s)
+	}
+
+	tag, err := name.NewTag(ref)
+	if err != nil {
+		return PinnedDigest{}, fmt.Errorf("the pinned digest %q has an invalid image reference: %w", s, err)
+	}
+
+	if _, err := v1.NewHash(digest); err != nil {
+		return PinnedDigest{}, fmt.Errorf("the pinned digest %q has an invalid digest: %w", s, err)
+	}
+
+	return PinnedDigest{Tag: tag.Name(), Digest: digest}, nil
+}
+
+// PinnedDigestOptions configures the built-in pinned digest check.
+type PinnedDigestOptions struct {
+	// Pins lists the digests the image tags are pinned to. Images with a tag
+	// not pinned are not checked.
+	Pins []PinnedDigest
+}
+
+const pinnedDigestOptionsKey contextKey = "ec.image.pinned_digest"
+
+// WithPinnedDigestOptions returns a copy of the context instructing
+// ValidateImage to check that the image tags resolve to the pinned digests.
+func WithPinnedDigestOptions(ctx context.Context, opts PinnedDigestOptions) context.Context {
+	return context.WithValue(ctx, pinnedDigestOptionsKey, opts)
+}
+
+func pinnedDigestOptions(ctx context.Context) PinnedDigestOptions {
+	if opts, ok := ctx.Value(pinnedDigestOptionsKey).(PinnedDigestOptions); ok {
+		return opts
+	}
+
+	return PinnedDigestOptions{}
+}
+
+// pinFor returns the tag of the image reference and the digest pinned for it,
+// if any. References by digest only have no tag to be pinned.
+func (o PinnedDigestOptions) pinFor(url string) (name.Tag, *PinnedDigest) {
+	ref, _, byDigest := strings.Cut(url, "@")
+	if byDigest && !strings.Contains(ref[strings.LastIndex(ref, "/")+1:], ":") {
+		return name.Tag{}, nil
+	}
+
+	tag, err := name.NewTag(ref)
+	if err != nil {
+		return name.Tag{}, nil
+	}
+
+	for i := range o.Pins {
+		if o.Pins[i].Tag == tag.Name() {
+			return tag, &o.Pins[i]
+		}
+	}
+
+	return tag, nil
+}
+
+// checkPinnedDigest sets the pinned digest check of the output if a digest is
+// pinned for the tag of the image reference, as given. The pinned digest is
+// compared with the digest of the validated image, i.e. the digest the tag
+// resolved to when the image was validated, so that a tag moved since it was
+// pinned fails the check. A digest given in the image reference also needs to
+// match the pinned one.
+func checkPinnedDigest(ctx context.Context, out *output.Output, url string) {
+	_, pin := pinnedDigestOptions(ctx).pinFor(url)
+	if pin == nil {
+		return
+	}
+
+	result := &output.DigestPin{Tag: pin.Tag, Expected: pin.Digest}
+
+	validated, err := name.NewDigest(out.ImageURL)
+	if err != nil {
+		out.SetPinnedDigestCheckFromError(result, fmt.Errorf("unable to determine the digest of the validated image %s: %w", out.ImageURL, err))
+		return
+	}
+	result.Actual = validated.DigestStr()
+
+	if result.Actual != pin.Digest {
+		if _, err := name.NewDigest(url); err == nil {
+			out.SetPinnedDigestCheckFromError(result, fmt.Errorf("the image digest %s differs from the digest %s pinned for %s", result.Actual, pin.Digest, pin.Tag))
+		} else {
+			out.SetPinnedDigestCheckFromError(result, fmt.Errorf("%s resolves to %s, expected %s", pin.Tag, result.Actual, pin.Digest))
+		}
+		return
+	}
+
+	out.SetPinnedDigestCheckFromError(result, nil)
+}
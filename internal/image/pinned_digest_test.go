@@ -0,0 +1,139 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package image
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/output"
+)
+
+func TestParsePinnedDigest(t *testing.T) {
+	pin, err := ParsePinnedDigest(imageRef)
+	require.NoError(t, err)
+	assert.Equal(t, PinnedDigest{Tag: imageRegistry + ":" + imageTag, Digest: "sha256:" + imageDigest}, pin)
+
+	pin, err = ParsePinnedDigest(imageRegistry + "@sha256:" + imageDigest)
+	require.NoError(t, err)
+	assert.Equal(t, imageRegistry+":latest", pin.Tag)
+
+	_, err = ParsePinnedDigest(imageRegistry + ":" + imageTag)
+	assert.EqualError(t, err, `the pinned digest "registry.example/spam:maps" is not in the repository:tag@digest form`)
+
+	_, err = ParsePinnedDigest(imageRegistry + ":" + imageTag + "@sha256:spam")
+	assert.ErrorContains(t, err, "has an invalid digest")
+
+	_, err = ParsePinnedDigest("Registry/Spam:maps@sha256:" + imageDigest)
+	assert.ErrorContains(t, err, "has an invalid image reference")
+}
+
+func TestCheckPinnedDigest(t *testing.T) {
+	tag := imageRegistry + ":" + imageTag
+	pinned := "sha256:" + imageDigest
+	moved := "sha256:" + "0000000000000000000000000000000000000000000000000000000000000000"
+
+	cases := []struct {
+		name      string
+		url       string
+		pins      []string
+		validated string
+		expected  *output.DigestPin
+		message   string
+	}{
+		{
+			name:      "not pinned",
+			url:       tag,
+			validated: imageRegistry + "@" + pinned,
+		},
+		{
+			name:      "other tag pinned",
+			url:       tag,
+			pins:      []string{imageRegistry + ":other@" + pinned},
+			validated: imageRegistry + "@" + pinned,
+		},
+		{
+			name:      "by digest only",
+			url:       imageRegistry + "@" + pinned,
+			pins:      []string{imageRegistry + "@" + pinned},
+			validated: imageRegistry + "@" + pinned,
+		},
+		{
+			name:      "tag not moved",
+			url:       tag,
+			pins:      []string{tag + "@" + pinned},
+			validated: imageRegistry + "@" + pinned,
+			expected:  &output.DigestPin{Tag: tag, Expected: pinned, Actual: pinned},
+			message:   "Pass",
+		},
+		{
+			name:      "tag moved",
+			url:       tag,
+			pins:      []string{tag + "@" + pinned},
+			validated: imageRegistry + "@" + moved,
+			expected:  &output.DigestPin{Tag: tag, Expected: pinned, Actual: moved},
+			message:   "Pinned digest check failed: " + tag + " resolves to " + moved + ", expected " + pinned,
+		},
+		{
+			name:      "different digest given",
+			url:       tag + "@" + moved,
+			pins:      []string{tag + "@" + pinned},
+			validated: imageRegistry + "@" + moved,
+			expected:  &output.DigestPin{Tag: tag, Expected: pinned, Actual: moved},
+			message:   "Pinned digest check failed: the image digest " + moved + " differs from the digest " + pinned + " pinned for " + tag,
+		},
+		{
+			name:      "validated image without digest",
+			url:       tag,
+			pins:      []string{tag + "@" + pinned},
+			validated: tag,
+			expected:  &output.DigestPin{Tag: tag, Expected: pinned},
+			message:   "Pinned digest check failed: unable to determine the digest of the validated image " + tag,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			opts := PinnedDigestOptions{}
+			for _, p := range c.pins {
+				pin, err := ParsePinnedDigest(p)
+				require.NoError(t, err)
+				opts.Pins = append(opts.Pins, pin)
+			}
+			ctx := WithPinnedDigestOptions(context.Background(), opts)
+
+			out := &output.Output{ImageURL: c.validated}
+			checkPinnedDigest(ctx, out, c.url)
+
+			if c.message == "" {
+				assert.Nil(t, out.PinnedDigestCheck)
+				assert.Nil(t, out.DigestPin)
+				return
+			}
+
+			require.NotNil(t, out.PinnedDigestCheck)
+			assert.Equal(t, c.message == "Pass", out.PinnedDigestCheck.Passed)
+			assert.Contains(t, out.PinnedDigestCheck.Result.Message, c.message)
+			assert.Equal(t, c.expected, out.DigestPin)
+		})
+	}
+}
@@ -26,6 +26,8 @@ import (
 	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/remote"
 	"github.com/hashicorp/go-multierror"
+
+	"github.com/enterprise-contract/ec-cli/internal/utils/oci"
 )
 
 type key string
@@ -85,7 +87,8 @@ func (i ImageReference) resolveDigest(ctx context.Context, opts ...name.Option)
 	if rh, ok := ctx.Value(RemoteHead).(func(name.Reference, ...remote.Option) (*v1.Descriptor, error)); ok {
 		remoteHead = rh
 	}
-	descriptor, err := remoteHead(i.ref, remote.WithAuthFromKeychain(authn.DefaultKeychain))
+	remoteOpts := append([]remote.Option{remote.WithAuthFromKeychain(authn.DefaultKeychain)}, oci.RegistryRewriteOptions(ctx)...)
+	descriptor, err := remoteHead(i.ref, remoteOpts...)
 	if err != nil {
 		return nil, err
 	}
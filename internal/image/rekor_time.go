@@ -0,0 +1,116 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package image
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/enterprise-contract/ec-cli/internal/attestation"
+	"github.com/enterprise-contract/ec-cli/internal/output"
+)
+
+// RekorTimeOptions configures the built-in transparency log time check.
+type RekorTimeOptions struct {
+	// MaxDelta is the maximum time allowed between the time the build finished,
+	// as claimed by the SLSA Provenance, and the time the attestation was
+	// integrated into the transparency log. Zero disables the check.
+	MaxDelta time.Duration
+}
+
+const rekorTimeOptionsKey contextKey = "ec.image.rekor_time"
+
+// WithRekorTimeOptions returns a copy of the context instructing ValidateImage
+// to check that the attestations were integrated into the transparency log
+// close to the time the build finished.
+func WithRekorTimeOptions(ctx context.Context, opts RekorTimeOptions) context.Context {
+	return context.WithValue(ctx, rekorTimeOptionsKey, opts)
+}
+
+func rekorTimeOptions(ctx context.Context) RekorTimeOptions {
+	if opts, ok := ctx.Value(rekorTimeOptionsKey).(RekorTimeOptions); ok {
+		return opts
+	}
+
+	return RekorTimeOptions{}
+}
+
+var errNoBuildFinishedTime = errors.New("no SLSA Provenance attestation with the time the build finished found")
+
+// checkRekorTime sets the transparency log time check of the output if a
+// maximum delta is configured. Each SLSA Provenance attestation claiming the
+// time the build finished needs to be integrated into the transparency log
+// within the maximum delta of that time. The times of the first attestation
+// failing the check, or of the last attestation checked, are recorded.
+func checkRekorTime(ctx context.Context, out *output.Output, attestations []attestation.Attestation, logEntryTimes []time.Time) {
+	opts := rekorTimeOptions(ctx)
+	if opts.MaxDelta == 0 {
+		return
+	}
+
+	var integrated, finished *time.Time
+	err := errNoBuildFinishedTime
+	for i, att := range attestations {
+		if att.PredicateType() != attestation.PredicateSLSAProvenance {
+			continue
+		}
+
+		p, ok := parseSLSAProvenance(att)
+		if !ok || p.FinishedOn == nil {
+			continue
+		}
+
+		f := p.FinishedOn.UTC()
+		finished = &f
+
+		if i >= len(logEntryTimes) || logEntryTimes[i].IsZero() {
+			integrated = nil
+			err = errors.New("the SLSA Provenance attestation has no transparency log entry")
+			break
+		}
+
+		t := logEntryTimes[i]
+		integrated = &t
+
+		if err = verifyRekorTime(t, f, opts.MaxDelta); err != nil {
+			break
+		}
+	}
+
+	out.SetRekorTimeCheckFromError(integrated, finished, err)
+}
+
+func verifyRekorTime(integrated, finished time.Time, maxDelta time.Duration) error {
+	delta := integrated.Sub(finished)
+	log.Debugf("Attestation integrated into the transparency log %s after the build finished", delta)
+
+	if delta > maxDelta {
+		return fmt.Errorf("the attestation was integrated into the transparency log at %s, %s after the build finished at %s, exceeding the allowed %s",
+			integrated.Format(time.RFC3339), delta, finished.Format(time.RFC3339), maxDelta)
+	}
+
+	if delta < -maxDelta {
+		return fmt.Errorf("the attestation was integrated into the transparency log at %s, %s before the build finished at %s, exceeding the allowed %s",
+			integrated.Format(time.RFC3339), -delta, finished.Format(time.RFC3339), maxDelta)
+	}
+
+	return nil
+}
@@ -0,0 +1,116 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package image
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	v02 "github.com/in-toto/in-toto-golang/in_toto/slsa_provenance/v0.2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/attestation"
+	"github.com/enterprise-contract/ec-cli/internal/output"
+)
+
+func TestCheckRekorTime(t *testing.T) {
+	finished := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	withFinished := provenance(t, v02.ProvenancePredicate{
+		Metadata: &v02.ProvenanceMetadata{BuildFinishedOn: &finished},
+	})
+	withoutFinished := provenance(t, v02.ProvenancePredicate{})
+
+	cases := []struct {
+		name       string
+		atts       []attestation.Attestation
+		times      []time.Time
+		integrated *time.Time
+		finished   *time.Time
+		err        string
+	}{
+		{
+			name:       "within the window",
+			atts:       []attestation.Attestation{withFinished},
+			times:      []time.Time{finished.Add(5 * time.Minute)},
+			integrated: ptr(finished.Add(5 * time.Minute)),
+			finished:   &finished,
+		},
+		{
+			name:       "integrated too late",
+			atts:       []attestation.Attestation{withFinished},
+			times:      []time.Time{finished.Add(2 * time.Hour)},
+			integrated: ptr(finished.Add(2 * time.Hour)),
+			finished:   &finished,
+			err:        "Transparency log time check failed: the attestation was integrated into the transparency log at 2024-01-01T14:00:00Z, 2h0m0s after the build finished at 2024-01-01T12:00:00Z, exceeding the allowed 1h0m0s",
+		},
+		{
+			name:       "backdated",
+			atts:       []attestation.Attestation{withFinished},
+			times:      []time.Time{finished.Add(-3 * time.Hour)},
+			integrated: ptr(finished.Add(-3 * time.Hour)),
+			finished:   &finished,
+			err:        "Transparency log time check failed: the attestation was integrated into the transparency log at 2024-01-01T09:00:00Z, 3h0m0s before the build finished at 2024-01-01T12:00:00Z, exceeding the allowed 1h0m0s",
+		},
+		{
+			name:     "no transparency log entry",
+			atts:     []attestation.Attestation{withFinished},
+			times:    []time.Time{{}},
+			finished: &finished,
+			err:      "Transparency log time check failed: the SLSA Provenance attestation has no transparency log entry",
+		},
+		{
+			name:  "no build finished time",
+			atts:  []attestation.Attestation{withoutFinished},
+			times: []time.Time{finished},
+			err:   "Transparency log time check failed: no SLSA Provenance attestation with the time the build finished found",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			out := &output.Output{}
+			ctx := WithRekorTimeOptions(context.Background(), RekorTimeOptions{MaxDelta: time.Hour})
+			checkRekorTime(ctx, out, c.atts, c.times)
+
+			require.NotNil(t, out.RekorTimeCheck)
+			assert.Equal(t, c.integrated, out.RekorIntegratedTime)
+			assert.Equal(t, c.finished, out.BuildFinishedOn)
+			if c.err == "" {
+				assert.True(t, out.RekorTimeCheck.Passed)
+				assert.Empty(t, out.Violations())
+			} else {
+				assert.False(t, out.RekorTimeCheck.Passed)
+				assert.Equal(t, c.err, out.RekorTimeCheck.Result.Message)
+				assert.Equal(t, map[string]any{"code": "builtin.attestation.rekor_time"}, out.RekorTimeCheck.Result.Metadata)
+			}
+		})
+	}
+}
+
+func TestCheckRekorTimeDisabled(t *testing.T) {
+	out := &output.Output{}
+	checkRekorTime(context.Background(), out, nil, nil)
+	assert.Nil(t, out.RekorTimeCheck)
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
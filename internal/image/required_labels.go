@@ -0,0 +1,153 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package image
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+
+	"github.com/enterprise-contract/ec-cli/internal/output"
+	"github.com/enterprise-contract/ec-cli/internal/utils/oci"
+)
+
+// RequiredLabel is a label the image config needs to set. When Pattern is
+// set, the value of the label needs to match it.
+type RequiredLabel struct {
+	Name    string
+	Pattern string
+	value   *regexp.Regexp
+}
+
+// ParseRequiredLabel parses the required label given as key or key=regex. The
+// regular expression needs to match the whole value of the label.
+func ParseRequiredLabel(s string) (RequiredLabel, error) {
+	key, expr, hasValue := strings.Cut(s, "=")
+	if key == "" {
+		return RequiredLabel{}, errors.New("the label name is empty")
+	}
+
+	label := RequiredLabel{Name: key}
+	if !hasValue {
+		return label, nil
+	}
+
+	re, err := regexp.Compile("^(?:" + expr + ")$")
+	if err != nil {
+		return RequiredLabel{}, err
+	}
+	label.Pattern = expr
+	label.value = re
+
+	return label, nil
+}
+
+// RequiredLabelOptions configures the built-in required label check.
+type RequiredLabelOptions struct {
+	// Labels lists the labels the image config needs to set. When empty, the
+	// check is disabled.
+	Labels []RequiredLabel
+}
+
+const requiredLabelOptionsKey contextKey = "ec.image.required_labels"
+
+// WithRequiredLabelOptions returns a copy of the context instructing
+// ValidateImage to check the labels of the image config.
+func WithRequiredLabelOptions(ctx context.Context, opts RequiredLabelOptions) context.Context {
+	return context.WithValue(ctx, requiredLabelOptionsKey, opts)
+}
+
+func requiredLabelOptions(ctx context.Context) RequiredLabelOptions {
+	if opts, ok := ctx.Value(requiredLabelOptionsKey).(RequiredLabelOptions); ok {
+		return opts
+	}
+
+	return RequiredLabelOptions{}
+}
+
+// checkRequiredLabels sets the required label check of the output if required
+// labels are configured.
+func checkRequiredLabels(ctx context.Context, out *output.Output) {
+	opts := requiredLabelOptions(ctx)
+	if len(opts.Labels) == 0 {
+		return
+	}
+
+	labels, err := imageLabels(ctx, out.ImageURL)
+	if err != nil {
+		out.SetRequiredLabelCheckFromError(nil, err)
+		return
+	}
+
+	missing, err := opts.ValidateLabels(labels)
+	out.SetRequiredLabelCheckFromError(missing, err)
+}
+
+// ValidateLabels checks the labels against the required labels. It returns the
+// names of the required labels that are not set, and an error describing the
+// labels that are missing or have a value not matching the expected one.
+func (o RequiredLabelOptions) ValidateLabels(labels map[string]string) ([]string, error) {
+	var missing, mismatched []string
+	for _, l := range o.Labels {
+		value, ok := labels[l.Name]
+		if !ok {
+			missing = append(missing, l.Name)
+			continue
+		}
+
+		if l.value != nil && !l.value.MatchString(value) {
+			mismatched = append(mismatched, fmt.Sprintf("%s=%q does not match %q", l.Name, value, l.Pattern))
+		}
+	}
+
+	var problems []string
+	if len(missing) > 0 {
+		problems = append(problems, "missing labels: "+strings.Join(missing, ", "))
+	}
+	if len(mismatched) > 0 {
+		problems = append(problems, "mismatched labels: "+strings.Join(mismatched, ", "))
+	}
+
+	if len(problems) == 0 {
+		return nil, nil
+	}
+
+	return missing, errors.New(strings.Join(problems, "; "))
+}
+
+func imageLabels(ctx context.Context, url string) (map[string]string, error) {
+	ref, err := name.ParseReference(url)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse the image reference %s: %w", url, err)
+	}
+
+	img, err := oci.NewClient(ctx).Image(ref)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch the image %s: %w", url, err)
+	}
+
+	config, err := img.ConfigFile()
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the config of %s: %w", url, err)
+	}
+
+	return config.Config.Labels, nil
+}
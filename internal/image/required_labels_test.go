@@ -0,0 +1,106 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package image
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/output"
+	"github.com/enterprise-contract/ec-cli/internal/utils/oci"
+	"github.com/enterprise-contract/ec-cli/internal/utils/oci/fake"
+)
+
+func TestParseRequiredLabel(t *testing.T) {
+	l, err := ParseRequiredLabel("maintainer")
+	require.NoError(t, err)
+	assert.Equal(t, RequiredLabel{Name: "maintainer"}, l)
+
+	l, err = ParseRequiredLabel("revision=[0-9a-f]{40}")
+	require.NoError(t, err)
+	assert.Equal(t, "revision", l.Name)
+	assert.Equal(t, "[0-9a-f]{40}", l.Pattern)
+
+	_, err = ParseRequiredLabel("=value")
+	assert.EqualError(t, err, "the label name is empty")
+
+	_, err = ParseRequiredLabel("revision=[")
+	assert.ErrorContains(t, err, "missing closing ]")
+}
+
+func TestValidateLabels(t *testing.T) {
+	labels := make([]RequiredLabel, 0, 3)
+	for _, l := range []string{"maintainer", "source", "revision=[0-9a-f]+"} {
+		r, err := ParseRequiredLabel(l)
+		require.NoError(t, err)
+		labels = append(labels, r)
+	}
+	opts := RequiredLabelOptions{Labels: labels}
+
+	missing, err := opts.ValidateLabels(map[string]string{"maintainer": "me", "source": "here", "revision": "abc123"})
+	assert.NoError(t, err)
+	assert.Empty(t, missing)
+
+	missing, err = opts.ValidateLabels(map[string]string{"maintainer": "me", "revision": "abc123-dirty"})
+	assert.EqualError(t, err, `missing labels: source; mismatched labels: revision="abc123-dirty" does not match "[0-9a-f]+"`)
+	assert.Equal(t, []string{"source"}, missing)
+
+	missing, err = opts.ValidateLabels(nil)
+	assert.EqualError(t, err, "missing labels: maintainer, source, revision")
+	assert.Equal(t, []string{"maintainer", "source", "revision"}, missing)
+}
+
+func TestCheckRequiredLabels(t *testing.T) {
+	img, err := mutate.Config(empty.Image, v1.Config{Labels: map[string]string{"maintainer": "me"}})
+	require.NoError(t, err)
+
+	ref, err := name.ParseReference(imageRef)
+	require.NoError(t, err)
+
+	client := fake.FakeClient{}
+	client.On("Image", ref).Return(img, nil)
+	ctx := oci.WithClient(context.Background(), &client)
+
+	out := &output.Output{ImageURL: imageRef}
+	checkRequiredLabels(ctx, out)
+	assert.Nil(t, out.RequiredLabelCheck, "the check is disabled by default")
+
+	maintainer, err := ParseRequiredLabel("maintainer")
+	require.NoError(t, err)
+	source, err := ParseRequiredLabel("source")
+	require.NoError(t, err)
+
+	checkRequiredLabels(WithRequiredLabelOptions(ctx, RequiredLabelOptions{Labels: []RequiredLabel{maintainer, source}}), out)
+	require.NotNil(t, out.RequiredLabelCheck)
+	assert.False(t, out.RequiredLabelCheck.Passed)
+	assert.Equal(t, "Required label check failed: missing labels: source", out.RequiredLabelCheck.Result.Message)
+	assert.Equal(t, []string{"source"}, out.MissingLabels)
+	assert.Len(t, out.Violations(), 1)
+
+	checkRequiredLabels(WithRequiredLabelOptions(ctx, RequiredLabelOptions{Labels: []RequiredLabel{maintainer}}), out)
+	assert.True(t, out.RequiredLabelCheck.Passed)
+	assert.Empty(t, out.MissingLabels)
+}
@@ -0,0 +1,307 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package image
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"path"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	app "github.com/konflux-ci/application-api/api/v1alpha1"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+
+	"github.com/enterprise-contract/ec-cli/internal/attestation"
+	"github.com/enterprise-contract/ec-cli/internal/evaluator"
+	"github.com/enterprise-contract/ec-cli/internal/output"
+	"github.com/enterprise-contract/ec-cli/internal/policy"
+	"github.com/enterprise-contract/ec-cli/internal/signature"
+	"github.com/enterprise-contract/ec-cli/internal/utils/oci"
+)
+
+// ResultCacheDirEnv is the environment variable holding the directory of the
+// persistent result cache, the cache is disabled when not set. The cached
+// results are trusted as is, the directory must only be writable by trusted
+// users.
+const ResultCacheDirEnv = "EC_CACHE_DIR"
+
+// DefaultResultCacheTTL is the default time the cached results are used for.
+const DefaultResultCacheTTL = 24 * time.Hour
+
+// ResultCache is a persistent cache of the outputs of validating images,
+// keyed by the image digest and by the settings of the validation, i.e.
+// everything else the outcome depends on, like the content digests of the
+// policy sources, the policy configuration and the keys or identities. A
+// change to any of those results in a different key and in the image being
+// validated again.
+type ResultCache struct {
+	fs       afero.Fs
+	dir      string
+	ttl      time.Duration
+	settings string
+	now      func() time.Time
+}
+
+// NewResultCache returns the result cache storing the results within the
+// directory for the given time to live. The settings are hashed into the
+// keys of the cached results.
+func NewResultCache(fs afero.Fs, dir string, ttl time.Duration, settings any) (*ResultCache, error) {
+	j, err := json.Marshal(settings)
+	if err != nil {
+		return nil, fmt.Errorf("unable to determine the settings of the result cache: %w", err)
+	}
+
+	return &ResultCache{
+		fs:       fs,
+		dir:      path.Join(dir, "results"),
+		ttl:      ttl,
+		settings: fmt.Sprintf("%x", sha256.Sum256(j)),
+		now:      time.Now,
+	}, nil
+}
+
+type cachedAttestation struct {
+	Statement  json.RawMessage             `json:"statement"`
+	Signatures []signature.EntitySignature `json:"signatures,omitempty"`
+}
+
+// cachedResult holds the output, including the fields of the output not
+// otherwise serialized.
+type cachedResult struct {
+	Created              time.Time                    `json:"created"`
+	Output               *output.Output               `json:"output"`
+	Attestations         []cachedAttestation          `json:"attestations,omitempty"`
+	ExitCode             int                          `json:"exitCode"`
+	ImageURL             string                       `json:"imageURL"`
+	Detailed             bool                         `json:"detailed"`
+	Data                 []evaluator.Data             `json:"data,omitempty"`
+	PolicyInput          []byte                       `json:"policyInput,omitempty"`
+	Verification         output.Verification          `json:"verification"`
+	SLSALevel            *int                         `json:"slsaLevel,omitempty"`
+	BaseImage            string                       `json:"baseImage,omitempty"`
+	BuilderIDs           []string                     `json:"builderIds,omitempty"`
+	RekorIntegratedTime  *time.Time                   `json:"rekorIntegratedTime,omitempty"`
+	BuildFinishedOn      *time.Time                   `json:"buildFinishedOn,omitempty"`
+	SigningKeys          []signature.SigningKey       `json:"signingKeys,omitempty"`
+	MissingLabels        []string                     `json:"missingLabels,omitempty"`
+	DigestPin            *output.DigestPin            `json:"digestPin,omitempty"`
+	AttestationConflicts []output.AttestationConflict `json:"attestationConflicts,omitempty"`
+	OperatorDecision     string                       `json:"operatorDecision,omitempty"`
+	AttestationsPresent  *bool                        `json:"attestationsPresent,omitempty"`
+	SignerIdentities     []signature.Identity         `json:"signerIdentities,omitempty"`
+	SigningTimes         []signature.SigningTime      `json:"signingTimes,omitempty"`
+	ImageCreated         *time.Time                   `json:"imageCreated,omitempty"`
+	PolicyInputSize      *int64                       `json:"policyInputSize,omitempty"`
+	SubjectDigests       []string                     `json:"subjectDigests,omitempty"`
+}
+
+func (c *ResultCache) key(digest string, comp app.SnapshotComponent, snap *app.SnapshotSpec, detailed bool) (string, error) {
+	j, err := json.Marshal(struct {
+		Settings  string                `json:"settings"`
+		Digest    string                `json:"digest"`
+		Component app.SnapshotComponent `json:"component"`
+		Snapshot  *app.SnapshotSpec     `json:"snapshot"`
+		Detailed  bool                  `json:"detailed"`
+	}{c.settings, digest, comp, snap, detailed})
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%x", sha256.Sum256(j)), nil
+}
+
+func (c *ResultCache) file(key string) string {
+	return path.Join(c.dir, key+".json")
+}
+
+// get returns the cached output for the key, unless it expired.
+func (c *ResultCache) get(key string) (*output.Output, bool) {
+	content, err := afero.ReadFile(c.fs, c.file(key))
+	if err != nil {
+		return nil, false
+	}
+
+	var r cachedResult
+	if err := json.Unmarshal(content, &r); err != nil || r.Output == nil {
+		log.Debugf("Ignoring the malformed cached result %s: %v", c.file(key), err)
+		return nil, false
+	}
+
+	if c.now().Sub(r.Created) > c.ttl {
+		log.Debugf("The cached result %s expired", c.file(key))
+		return nil, false
+	}
+
+	out := r.Output
+	for _, a := range r.Attestations {
+		att, err := attestation.FromStatement(a.Statement, a.Signatures)
+		if err != nil {
+			log.Debugf("Ignoring the cached result %s with a malformed attestation: %v", c.file(key), err)
+			return nil, false
+		}
+		out.Attestations = append(out.Attestations, att)
+	}
+	out.ExitCode = r.ExitCode
+	out.ImageURL = r.ImageURL
+	out.Detailed = r.Detailed
+	out.Data = r.Data
+	out.PolicyInput = r.PolicyInput
+	out.Verification = r.Verification
+	out.SLSALevel = r.SLSALevel
+	out.BaseImage = r.BaseImage
+	out.BuilderIDs = r.BuilderIDs
+	out.RekorIntegratedTime = r.RekorIntegratedTime
+	out.BuildFinishedOn = r.BuildFinishedOn
+	out.SigningKeys = r.SigningKeys
+	out.MissingLabels = r.MissingLabels
+	out.DigestPin = r.DigestPin
+	out.AttestationConflicts = r.AttestationConflicts
+	out.OperatorDecision = r.OperatorDecision
+	out.AttestationsPresent = r.AttestationsPresent
+	out.SignerIdentities = r.SignerIdentities
+	out.SigningTimes = r.SigningTimes
+	out.ImageCreated = r.ImageCreated
+	out.PolicyInputSize = r.PolicyInputSize
+	out.SubjectDigests = r.SubjectDigests
+
+	return out, true
+}
+
+// put stores the output for the key.
+func (c *ResultCache) put(key string, out *output.Output) error {
+	r := cachedResult{
+		Created:              c.now().UTC(),
+		ExitCode:             out.ExitCode,
+		ImageURL:             out.ImageURL,
+		Detailed:             out.Detailed,
+		Data:                 out.Data,
+		PolicyInput:          out.PolicyInput,
+		Verification:         out.Verification,
+		SLSALevel:            out.SLSALevel,
+		BaseImage:            out.BaseImage,
+		BuilderIDs:           out.BuilderIDs,
+		RekorIntegratedTime:  out.RekorIntegratedTime,
+		BuildFinishedOn:      out.BuildFinishedOn,
+		SigningKeys:          out.SigningKeys,
+		MissingLabels:        out.MissingLabels,
+		DigestPin:            out.DigestPin,
+		AttestationConflicts: out.AttestationConflicts,
+		OperatorDecision:     out.OperatorDecision,
+		AttestationsPresent:  out.AttestationsPresent,
+		SignerIdentities:     out.SignerIdentities,
+		SigningTimes:         out.SigningTimes,
+		ImageCreated:         out.ImageCreated,
+		PolicyInputSize:      out.PolicyInputSize,
+		SubjectDigests:       out.SubjectDigests,
+	}
+	for _, a := range out.Attestations {
+		r.Attestations = append(r.Attestations, cachedAttestation{Statement: a.Statement(), Signatures: a.Signatures()})
+	}
+
+	// The attestations are not serialized in full, they are stored separately
+	// to be recreated
+	o := *out
+	o.Attestations = nil
+	r.Output = &o
+
+	content, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	if err := c.fs.MkdirAll(c.dir, 0700); err != nil {
+		return err
+	}
+
+	return afero.WriteFile(c.fs, c.file(key), content, 0600)
+}
+
+// cacheable returns true if the output is the outcome of a complete
+// validation, i.e. the image was accessible and the registry did not rate
+// limit the validation.
+func cacheable(out *output.Output) bool {
+	if out == nil || !out.ImageAccessibleCheck.Passed {
+		return false
+	}
+
+	for _, s := range []output.Stage{out.Verification.ImageAccessible, out.Verification.ImageSignature, out.Verification.AttestationSignature, out.Verification.AttestationSyntax, out.Verification.Policy} {
+		if s.Status == output.StageRateLimited {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Wrap returns the validation function using the cached output of the image,
+// if any, otherwise validating the image and caching the output. The image
+// reference is resolved to the digest to determine the key. Images denied or
+// allowed by the operator bypass the cache.
+func (c *ResultCache) Wrap(validate func(context.Context, app.SnapshotComponent, *app.SnapshotSpec, policy.Policy, []evaluator.Evaluator, bool) (*output.Output, error)) func(context.Context, app.SnapshotComponent, *app.SnapshotSpec, policy.Policy, []evaluator.Evaluator, bool) (*output.Output, error) {
+	return func(ctx context.Context, comp app.SnapshotComponent, snap *app.SnapshotSpec, p policy.Policy, evaluators []evaluator.Evaluator, detailed bool) (*output.Output, error) {
+		digest, err := resolveImageDigest(ctx, comp.ContainerImage)
+		if err != nil {
+			log.Debugf("Not using the result cache for image %s: %v", comp.ContainerImage, err)
+			return validate(ctx, comp, snap, p, evaluators, detailed)
+		}
+
+		// The digests denied or allowed by the operator might be read from
+		// files, whose content is not part of the key, the decision of the
+		// operator is never cached
+		if operatorDecided(ctx, digest) {
+			return validate(ctx, comp, snap, p, evaluators, detailed)
+		}
+
+		key, err := c.key(digest, comp, snap, detailed)
+		if err != nil {
+			log.Debugf("Not using the result cache for image %s: %v", comp.ContainerImage, err)
+			return validate(ctx, comp, snap, p, evaluators, detailed)
+		}
+
+		if out, ok := c.get(key); ok {
+			log.Debugf("Using the cached result of image %s", comp.ContainerImage)
+			out.Policy = p
+			return out, nil
+		}
+
+		out, err := validate(ctx, comp, snap, p, evaluators, detailed)
+		if err == nil && cacheable(out) {
+			if err := c.put(key, out); err != nil {
+				log.Debugf("Unable to cache the result of image %s: %v", comp.ContainerImage, err)
+			}
+		}
+
+		return out, err
+	}
+}
+
+func resolveImageDigest(ctx context.Context, image string) (string, error) {
+	if ref, err := name.NewDigest(image); err == nil {
+		return ref.DigestStr(), nil
+	}
+
+	ref, err := name.ParseReference(image)
+	if err != nil {
+		return "", err
+	}
+
+	return oci.NewClient(ctx).ResolveDigest(ref)
+}
@@ -0,0 +1,178 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package image
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	app "github.com/konflux-ci/application-api/api/v1alpha1"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/attestation"
+	"github.com/enterprise-contract/ec-cli/internal/evaluator"
+	"github.com/enterprise-contract/ec-cli/internal/output"
+	"github.com/enterprise-contract/ec-cli/internal/policy"
+	"github.com/enterprise-contract/ec-cli/internal/signature"
+)
+
+const cachedImage = "registry.io/repository/image@sha256:4e388ab32b10dc8dbc7e28144f552830adc74787c1e2c0824032078a79f227fb"
+
+func TestResultCache(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	c, err := NewResultCache(fs, "/cache", time.Hour, map[string]any{"policyDigest": "sha256:abc"})
+	require.NoError(t, err)
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return now }
+
+	level := 2
+	out := &output.Output{
+		ImageURL:             cachedImage,
+		ImageAccessibleCheck: output.VerificationStatus{Passed: true},
+		PolicyCheck: []evaluator.Outcome{{
+			Failures: []evaluator.Result{{Message: "Failure", Metadata: map[string]any{"code": "test.failure"}}},
+		}},
+		ExitCode:         1,
+		Attestations:     []attestation.Attestation{sbom(t, attestation.PredicateSpdxDocument, map[string]any{"spdxVersion": "SPDX-2.3"})},
+		PolicyInput:      []byte(`{"image":{}}`),
+		SLSALevel:        &level,
+		SigningKeys:      []signature.SigningKey{{Algorithm: "ECDSA-SHA256", KeySize: 256}},
+		BuilderIDs:       []string{"https://tekton.dev/chains/v2"},
+		MissingLabels:    []string{"org.opencontainers.image.source"},
+		DigestPin:        &output.DigestPin{Tag: "registry.local/image:latest", Expected: "sha256:4e38", Actual: "sha256:4e38"},
+		SignerIdentities: []signature.Identity{{Subject: "https://github.com/org/repo/.github/workflows/release.yaml@refs/heads/main", Issuer: "https://token.actions.githubusercontent.com"}},
+		SigningTimes: []signature.SigningTime{{
+			Time:      time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+			Source:    signature.SigningTimeSourceRekor,
+			NotBefore: time.Date(2024, 1, 2, 3, 0, 0, 0, time.UTC),
+			NotAfter:  time.Date(2024, 1, 2, 3, 10, 0, 0, time.UTC),
+			Expired:   true,
+		}},
+	}
+
+	comp := app.SnapshotComponent{Name: "image", ContainerImage: cachedImage}
+	snap := &app.SnapshotSpec{Components: []app.SnapshotComponent{comp}}
+	key, err := c.key("sha256:4e38", comp, snap, false)
+	require.NoError(t, err)
+
+	_, ok := c.get(key)
+	assert.False(t, ok)
+
+	require.NoError(t, c.put(key, out))
+	assert.Len(t, out.Attestations, 1, "the output is not modified")
+
+	cached, ok := c.get(key)
+	require.True(t, ok)
+	assert.Equal(t, out.ImageURL, cached.ImageURL)
+	assert.Equal(t, out.PolicyCheck, cached.PolicyCheck)
+	assert.Equal(t, out.Violations(), cached.Violations())
+	assert.Equal(t, 1, cached.ExitCode)
+	assert.Equal(t, out.PolicyInput, cached.PolicyInput)
+	assert.Equal(t, &level, cached.SLSALevel)
+	assert.Equal(t, out.SigningKeys, cached.SigningKeys)
+	assert.Equal(t, out.BuilderIDs, cached.BuilderIDs)
+	assert.Equal(t, out.MissingLabels, cached.MissingLabels)
+	assert.Equal(t, out.DigestPin, cached.DigestPin)
+	assert.Equal(t, out.SignerIdentities, cached.SignerIdentities)
+	assert.Equal(t, out.SigningTimes, cached.SigningTimes)
+	require.Len(t, cached.Attestations, 1)
+	assert.Equal(t, out.Attestations[0].Statement(), cached.Attestations[0].Statement())
+	assert.Equal(t, attestation.PredicateSpdxDocument, cached.Attestations[0].PredicateType())
+
+	other, err := c.key("sha256:4e38", comp, snap, true)
+	require.NoError(t, err)
+	assert.NotEqual(t, key, other)
+
+	different, err := NewResultCache(fs, "/cache", time.Hour, map[string]any{"policyDigest": "sha256:def"})
+	require.NoError(t, err)
+	otherSettings, err := different.key("sha256:4e38", comp, snap, false)
+	require.NoError(t, err)
+	assert.NotEqual(t, key, otherSettings)
+
+	now = now.Add(2 * time.Hour)
+	_, ok = c.get(key)
+	assert.False(t, ok, "the cached result expired")
+}
+
+func TestResultCacheWrap(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	c, err := NewResultCache(fs, "/cache", time.Hour, nil)
+	require.NoError(t, err)
+
+	calls := 0
+	accessible := true
+	validate := c.Wrap(func(_ context.Context, comp app.SnapshotComponent, _ *app.SnapshotSpec, _ policy.Policy, _ []evaluator.Evaluator, _ bool) (*output.Output, error) {
+		calls++
+		return &output.Output{ImageURL: comp.ContainerImage, ImageAccessibleCheck: output.VerificationStatus{Passed: accessible}}, nil
+	})
+
+	ctx := context.Background()
+	comp := app.SnapshotComponent{Name: "image", ContainerImage: cachedImage}
+	snap := &app.SnapshotSpec{Components: []app.SnapshotComponent{comp}}
+
+	for i := 0; i < 2; i++ {
+		out, err := validate(ctx, comp, snap, nil, nil, false)
+		require.NoError(t, err)
+		assert.Equal(t, cachedImage, out.ImageURL)
+	}
+	assert.Equal(t, 1, calls, "the image is validated once")
+
+	accessible = false
+	other := app.SnapshotComponent{Name: "other", ContainerImage: cachedImage}
+	for i := 0; i < 2; i++ {
+		_, err := validate(ctx, other, snap, nil, nil, false)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 3, calls, "the results of inaccessible images are not cached")
+}
+
+func TestResultCacheWrapOperatorDenied(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	c, err := NewResultCache(fs, "/cache", time.Hour, nil)
+	require.NoError(t, err)
+
+	calls := 0
+	validate := c.Wrap(func(ctx context.Context, comp app.SnapshotComponent, _ *app.SnapshotSpec, _ policy.Policy, _ []evaluator.Evaluator, _ bool) (*output.Output, error) {
+		calls++
+		out := &output.Output{ImageURL: comp.ContainerImage, ImageAccessibleCheck: output.VerificationStatus{Passed: true}}
+		checkOperatorDigest(ctx, out)
+		return out, nil
+	})
+
+	comp := app.SnapshotComponent{Name: "image", ContainerImage: cachedImage}
+	snap := &app.SnapshotSpec{Components: []app.SnapshotComponent{comp}}
+
+	out, err := validate(context.Background(), comp, snap, nil, nil, false)
+	require.NoError(t, err)
+	assert.Empty(t, out.OperatorDecision)
+
+	// The digest is added to the deny file after the pass was cached
+	ctx := WithOperatorDigestOptions(context.Background(), OperatorDigestOptions{
+		Denied: []string{"sha256:4e388ab32b10dc8dbc7e28144f552830adc74787c1e2c0824032078a79f227fb"},
+	})
+	out, err = validate(ctx, comp, snap, nil, nil, false)
+	require.NoError(t, err)
+	assert.Equal(t, output.OperatorDenied, out.OperatorDecision)
+	assert.False(t, out.OperatorDigestCheck.Passed)
+	assert.Equal(t, 2, calls)
+}
@@ -0,0 +1,317 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package image
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/enterprise-contract/ec-cli/internal/attestation"
+	"github.com/enterprise-contract/ec-cli/internal/output"
+	"github.com/enterprise-contract/ec-cli/internal/utils/oci"
+)
+
+// SBOMConsistencyOptions configures the built-in SBOM consistency check.
+type SBOMConsistencyOptions struct {
+	// Enabled turns on cross-referencing the SBOM attestations with the image
+	// manifest.
+	Enabled bool
+}
+
+const sbomConsistencyOptionsKey contextKey = "ec.image.sbom_consistency"
+
+// WithSBOMConsistencyOptions returns a copy of the context instructing
+// ValidateImage to check that the SBOM attestations describe the image they
+// are attached to.
+func WithSBOMConsistencyOptions(ctx context.Context, opts SBOMConsistencyOptions) context.Context {
+	return context.WithValue(ctx, sbomConsistencyOptionsKey, opts)
+}
+
+func sbomConsistencyOptions(ctx context.Context) SBOMConsistencyOptions {
+	if opts, ok := ctx.Value(sbomConsistencyOptionsKey).(SBOMConsistencyOptions); ok {
+		return opts
+	}
+
+	return SBOMConsistencyOptions{}
+}
+
+// sha256Digest matches SHA-256 digests, also when URL encoded as they are
+// within package URLs.
+var sha256Digest = regexp.MustCompile(`sha256(?::|%3[aA])([0-9a-f]{64})`)
+
+// layerIDProperty matches the properties syft records the layers a CycloneDX
+// component was found in with.
+var layerIDProperty = regexp.MustCompile(`^syft:location:\d+:layerID$`)
+
+type externalRef struct {
+	ReferenceType    string `json:"referenceType"`
+	ReferenceLocator string `json:"referenceLocator"`
+}
+
+type property struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// sbomStatement holds the parts of the SPDX and CycloneDX predicates used to
+// determine the image and the layers an SBOM describes.
+type sbomStatement struct {
+	Predicate struct {
+		// SPDX
+		Packages []struct {
+			ExternalRefs []externalRef `json:"externalRefs"`
+		} `json:"packages"`
+		Files []struct {
+			Comment string `json:"comment"`
+		} `json:"files"`
+		// CycloneDX
+		Metadata struct {
+			Component struct {
+				PURL    string `json:"purl"`
+				Version string `json:"version"`
+			} `json:"component"`
+		} `json:"metadata"`
+		Components []struct {
+			Properties []property `json:"properties"`
+		} `json:"components"`
+	} `json:"predicate"`
+}
+
+// sbomReferences are the image digests and the layer digests an SBOM refers
+// to.
+type sbomReferences struct {
+	images []string
+	layers []string
+}
+
+func parseSBOM(predicateType string, statement []byte) (sbomReferences, error) {
+	var s sbomStatement
+	if err := json.Unmarshal(statement, &s); err != nil {
+		return sbomReferences{}, err
+	}
+
+	refs := sbomReferences{}
+	switch predicateType {
+	case attestation.PredicateSpdxDocument:
+		for _, p := range s.Predicate.Packages {
+			for _, r := range p.ExternalRefs {
+				if r.ReferenceType == "purl" && strings.HasPrefix(r.ReferenceLocator, "pkg:oci/") {
+					refs.images = append(refs.images, digestsIn(r.ReferenceLocator)...)
+				}
+			}
+		}
+		for _, f := range s.Predicate.Files {
+			if strings.HasPrefix(f.Comment, "layerID: ") {
+				refs.layers = append(refs.layers, digestsIn(f.Comment)...)
+			}
+		}
+	case attestation.PredicateCycloneDXBOM:
+		c := s.Predicate.Metadata.Component
+		if strings.HasPrefix(c.PURL, "pkg:oci/") {
+			refs.images = append(refs.images, digestsIn(c.PURL)...)
+		}
+		if strings.HasPrefix(c.Version, "sha256:") {
+			refs.images = append(refs.images, digestsIn(c.Version)...)
+		}
+		for _, comp := range s.Predicate.Components {
+			for _, p := range comp.Properties {
+				if layerIDProperty.MatchString(p.Name) {
+					refs.layers = append(refs.layers, digestsIn(p.Value)...)
+				}
+			}
+		}
+	}
+
+	return refs, nil
+}
+
+func digestsIn(s string) []string {
+	matches := sha256Digest.FindAllStringSubmatch(s, -1)
+	digests := make([]string, 0, len(matches))
+	for _, m := range matches {
+		digests = append(digests, "sha256:"+m[1])
+	}
+
+	return digests
+}
+
+var errNoSBOM = errors.New("no SBOM attestation found")
+
+// checkSBOMConsistency sets the SBOM consistency check of the output if
+// enabled. Each SPDX and CycloneDX attestation needs to describe the image
+// by its digest, or to refer only to layers of the image, or both.
+func checkSBOMConsistency(ctx context.Context, out *output.Output, attestations []attestation.Attestation) {
+	if !sbomConsistencyOptions(ctx).Enabled {
+		return
+	}
+
+	out.SetSBOMConsistencyCheckFromError(verifySBOMConsistency(ctx, out.ImageURL, attestations))
+}
+
+func verifySBOMConsistency(ctx context.Context, url string, attestations []attestation.Attestation) error {
+	var sboms []sbomReferences
+	for _, att := range attestations {
+		predicateType := att.PredicateType()
+		if predicateType != attestation.PredicateSpdxDocument && predicateType != attestation.PredicateCycloneDXBOM {
+			continue
+		}
+
+		refs, err := parseSBOM(predicateType, att.Statement())
+		if err != nil {
+			log.Debugf("Unable to parse the SBOM attestation: %s", err)
+			return fmt.Errorf("unable to parse the %s SBOM attestation: %w", predicateType, err)
+		}
+		sboms = append(sboms, refs)
+	}
+
+	if len(sboms) == 0 {
+		return errNoSBOM
+	}
+
+	ref, err := name.NewDigest(url)
+	if err != nil {
+		return fmt.Errorf("unable to parse the image reference %s: %w", url, err)
+	}
+
+	content := imageContent{layers: map[string]bool{}}
+	if err := content.collect(oci.NewClient(ctx), ref, map[string]bool{}); err != nil {
+		return err
+	}
+
+	for _, sbom := range sboms {
+		if err := verifySBOMReferences(content.digests, content.layers, sbom); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// imageContent holds the digests an SBOM may describe the image by and the
+// layers it may refer to. For an image index, those of the image of each
+// platform are included, following nested image indexes, as the SBOM
+// attached to an index can describe any of them.
+type imageContent struct {
+	// digests of the image, or of the index and of its manifests
+	digests []string
+	// layers holds both the digests of the (compressed) layers and the
+	// digests of the uncompressed layers, i.e. the diff IDs, of the images.
+	// SBOM generators use either of them to identify layers.
+	layers map[string]bool
+}
+
+func (c *imageContent) collect(client oci.Client, ref name.Digest, seen map[string]bool) error {
+	if seen[ref.DigestStr()] {
+		return nil
+	}
+	seen[ref.DigestStr()] = true
+	c.digests = append(c.digests, ref.DigestStr())
+
+	desc, err := client.Head(ref)
+	if err != nil {
+		return fmt.Errorf("unable to fetch the image %s: %w", ref, err)
+	}
+
+	if desc.MediaType.IsIndex() {
+		index, err := client.Index(ref)
+		if err != nil {
+			return fmt.Errorf("unable to fetch the image index %s: %w", ref, err)
+		}
+
+		manifest, err := index.IndexManifest()
+		if err != nil {
+			return fmt.Errorf("unable to read the image index %s: %w", ref, err)
+		}
+
+		for _, m := range manifest.Manifests {
+			if !m.MediaType.IsIndex() && !m.MediaType.IsImage() {
+				continue
+			}
+			if err := c.collect(client, ref.Context().Digest(m.Digest.String()), seen); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	img, err := client.Image(ref)
+	if err != nil {
+		return fmt.Errorf("unable to fetch the image %s: %w", ref, err)
+	}
+
+	manifest, err := img.Manifest()
+	if err != nil {
+		return fmt.Errorf("unable to read the manifest of %s: %w", ref, err)
+	}
+
+	config, err := img.ConfigFile()
+	if err != nil {
+		return fmt.Errorf("unable to read the config of %s: %w", ref, err)
+	}
+
+	for _, l := range manifest.Layers {
+		c.layers[l.Digest.String()] = true
+	}
+	for _, d := range config.RootFS.DiffIDs {
+		c.layers[d.String()] = true
+	}
+
+	return nil
+}
+
+// verifySBOMReferences verifies that the SBOM describes the image by one of
+// its digests, the first being the digest of the image, and that it refers
+// only to the given layers.
+func verifySBOMReferences(digests []string, layers map[string]bool, sbom sbomReferences) error {
+	if len(sbom.images) == 0 && len(sbom.layers) == 0 {
+		return errors.New("the SBOM does not refer to the image or any of its layers")
+	}
+
+	if len(sbom.images) > 0 {
+		if !slices.ContainsFunc(sbom.images, func(i string) bool { return slices.Contains(digests, i) }) {
+			return fmt.Errorf("the SBOM describes the image %s, not the image %s", sbom.images[0], digests[0])
+		}
+	}
+
+	unknown := map[string]bool{}
+	for _, l := range sbom.layers {
+		if !layers[l] {
+			unknown[l] = true
+		}
+	}
+	if len(unknown) > 0 {
+		missing := make([]string, 0, len(unknown))
+		for l := range unknown {
+			missing = append(missing, l)
+		}
+		sort.Strings(missing)
+
+		return fmt.Errorf("the SBOM refers to layers not found in the image manifest: %s", strings.Join(missing, ", "))
+	}
+
+	return nil
+}
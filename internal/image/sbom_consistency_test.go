@@ -0,0 +1,255 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package image
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/in-toto/in-toto-golang/in_toto"
+	v02 "github.com/in-toto/in-toto-golang/in_toto/slsa_provenance/v0.2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/attestation"
+	"github.com/enterprise-contract/ec-cli/internal/output"
+	"github.com/enterprise-contract/ec-cli/internal/utils/oci"
+	"github.com/enterprise-contract/ec-cli/internal/utils/oci/fake"
+)
+
+func sbom(t *testing.T, predicateType string, predicate map[string]any) attestation.Attestation {
+	att, err := attestation.ProvenanceFromSignature(sign(&in_toto.Statement{
+		StatementHeader: in_toto.StatementHeader{
+			Type:          in_toto.StatementInTotoV01,
+			PredicateType: predicateType,
+		},
+		Predicate: predicate,
+	}))
+	require.NoError(t, err)
+
+	return att
+}
+
+func spdx(image string, layers ...string) map[string]any {
+	files := make([]any, 0, len(layers))
+	for _, l := range layers {
+		files = append(files, map[string]any{"fileName": "/etc/os-release", "comment": "layerID: " + l})
+	}
+
+	return map[string]any{
+		"packages": []any{
+			map[string]any{
+				"name": "image",
+				"externalRefs": []any{
+					map[string]any{
+						"referenceCategory": "PACKAGE-MANAGER",
+						"referenceType":     "purl",
+						"referenceLocator":  "pkg:oci/spam@" + strings.Replace(image, ":", "%3A", 1) + "?arch=amd64",
+					},
+				},
+			},
+		},
+		"files": files,
+	}
+}
+
+func cyclonedx(image string, layers ...string) map[string]any {
+	properties := make([]any, 0, len(layers))
+	for i, l := range layers {
+		properties = append(properties, map[string]any{"name": fmt.Sprintf("syft:location:%d:layerID", i), "value": l})
+	}
+
+	return map[string]any{
+		"metadata": map[string]any{
+			"component": map[string]any{"name": imageRegistry, "version": image},
+		},
+		"components": []any{
+			map[string]any{"name": "bash", "properties": properties},
+		},
+	}
+}
+
+func TestCheckSBOMConsistency(t *testing.T) {
+	img, err := mutate.AppendLayers(empty.Image, static.NewLayer([]byte("layer"), types.OCILayer))
+	require.NoError(t, err)
+
+	digest, err := img.Digest()
+	require.NoError(t, err)
+	layers, err := img.Layers()
+	require.NoError(t, err)
+	layerDigest, err := layers[0].Digest()
+	require.NoError(t, err)
+	diffID, err := layers[0].DiffID()
+	require.NoError(t, err)
+
+	url := imageRegistry + "@" + digest.String()
+	ref, err := name.ParseReference(url)
+	require.NoError(t, err)
+
+	client := fake.FakeClient{}
+	client.On("Head", ref).Return(&v1.Descriptor{MediaType: types.OCIManifestSchema1}, nil)
+	client.On("Image", ref).Return(img, nil)
+	ctx := oci.WithClient(context.Background(), &client)
+
+	other := "sha256:" + imageDigest
+
+	cases := []struct {
+		name  string
+		atts  []attestation.Attestation
+		error string
+	}{
+		{
+			name:  "no SBOM",
+			atts:  []attestation.Attestation{provenance(t, v02.ProvenancePredicate{})},
+			error: "no SBOM attestation found",
+		},
+		{
+			name: "SPDX",
+			atts: []attestation.Attestation{sbom(t, attestation.PredicateSpdxDocument, spdx(digest.String(), layerDigest.String()))},
+		},
+		{
+			name: "CycloneDX with diff IDs",
+			atts: []attestation.Attestation{sbom(t, attestation.PredicateCycloneDXBOM, cyclonedx(digest.String(), diffID.String()))},
+		},
+		{
+			name: "layers only",
+			atts: []attestation.Attestation{sbom(t, attestation.PredicateSpdxDocument, map[string]any{
+				"files": []any{map[string]any{"comment": "layerID: " + diffID.String()}},
+			})},
+		},
+		{
+			name:  "nothing to verify",
+			atts:  []attestation.Attestation{sbom(t, attestation.PredicateCycloneDXBOM, map[string]any{})},
+			error: "the SBOM does not refer to the image or any of its layers",
+		},
+		{
+			name:  "different image",
+			atts:  []attestation.Attestation{sbom(t, attestation.PredicateSpdxDocument, spdx(other))},
+			error: "the SBOM describes the image " + other + ", not the image " + digest.String(),
+		},
+		{
+			name:  "unknown layer",
+			atts:  []attestation.Attestation{sbom(t, attestation.PredicateCycloneDXBOM, cyclonedx(digest.String(), layerDigest.String(), other))},
+			error: "the SBOM refers to layers not found in the image manifest: " + other,
+		},
+		{
+			name: "any inconsistent SBOM fails",
+			atts: []attestation.Attestation{
+				sbom(t, attestation.PredicateSpdxDocument, spdx(digest.String())),
+				sbom(t, attestation.PredicateCycloneDXBOM, cyclonedx(other)),
+			},
+			error: "the SBOM describes the image " + other + ", not the image " + digest.String(),
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			out := &output.Output{ImageURL: url}
+			checkSBOMConsistency(ctx, out, c.atts)
+			assert.Nil(t, out.SBOMConsistencyCheck, "the check is disabled by default")
+
+			checkSBOMConsistency(WithSBOMConsistencyOptions(ctx, SBOMConsistencyOptions{Enabled: true}), out, c.atts)
+			require.NotNil(t, out.SBOMConsistencyCheck)
+			if c.error == "" {
+				assert.True(t, out.SBOMConsistencyCheck.Passed)
+				assert.Len(t, out.Successes(), 1)
+			} else {
+				assert.False(t, out.SBOMConsistencyCheck.Passed)
+				assert.Equal(t, "SBOM consistency check failed: "+c.error, out.SBOMConsistencyCheck.Result.Message)
+				assert.Equal(t, map[string]any{"code": "builtin.attestation.sbom_consistency"}, out.SBOMConsistencyCheck.Result.Metadata)
+				assert.Len(t, out.Violations(), 1)
+			}
+		})
+	}
+}
+
+func TestCheckSBOMConsistencyNestedIndex(t *testing.T) {
+	client := fake.FakeClient{}
+	platformImage := func(content string) (v1.Image, string, string) {
+		img, err := mutate.AppendLayers(empty.Image, static.NewLayer([]byte(content), types.OCILayer))
+		require.NoError(t, err)
+		digest, err := img.Digest()
+		require.NoError(t, err)
+		layers, err := img.Layers()
+		require.NoError(t, err)
+		layerDigest, err := layers[0].Digest()
+		require.NoError(t, err)
+
+		ref, err := name.ParseReference(imageRegistry + "@" + digest.String())
+		require.NoError(t, err)
+		client.On("Head", ref).Return(&v1.Descriptor{MediaType: types.OCIManifestSchema1}, nil)
+		client.On("Image", ref).Return(img, nil)
+
+		return img, digest.String(), layerDigest.String()
+	}
+	indexOf := func(index v1.ImageIndex) string {
+		digest, err := index.Digest()
+		require.NoError(t, err)
+		ref, err := name.ParseReference(imageRegistry + "@" + digest.String())
+		require.NoError(t, err)
+		client.On("Head", ref).Return(&v1.Descriptor{MediaType: types.OCIImageIndex}, nil)
+		client.On("Index", ref).Return(index, nil)
+
+		return digest.String()
+	}
+
+	amd64, amd64Digest, amd64Layer := platformImage("amd64")
+	arm64, _, arm64Layer := platformImage("arm64")
+
+	// The arm64 image is within an index nested in the top level index
+	nested := mutate.AppendManifests(empty.Index, mutate.IndexAddendum{
+		Add:        arm64,
+		Descriptor: v1.Descriptor{MediaType: types.OCIManifestSchema1, Platform: &v1.Platform{OS: "linux", Architecture: "arm64"}},
+	})
+	indexOf(nested)
+	index := mutate.AppendManifests(empty.Index,
+		mutate.IndexAddendum{
+			Add:        amd64,
+			Descriptor: v1.Descriptor{MediaType: types.OCIManifestSchema1, Platform: &v1.Platform{OS: "linux", Architecture: "amd64"}},
+		},
+		mutate.IndexAddendum{
+			Add:        nested,
+			Descriptor: v1.Descriptor{MediaType: types.OCIImageIndex},
+		},
+	)
+	indexDigest := indexOf(index)
+
+	ctx := WithSBOMConsistencyOptions(oci.WithClient(context.Background(), &client), SBOMConsistencyOptions{Enabled: true})
+	out := &output.Output{ImageURL: imageRegistry + "@" + indexDigest}
+
+	checkSBOMConsistency(ctx, out, []attestation.Attestation{sbom(t, attestation.PredicateSpdxDocument, spdx(indexDigest, amd64Layer, arm64Layer))})
+	require.NotNil(t, out.SBOMConsistencyCheck)
+	assert.True(t, out.SBOMConsistencyCheck.Passed, "the layers of all platforms, including those of nested indexes, are known")
+
+	checkSBOMConsistency(ctx, out, []attestation.Attestation{sbom(t, attestation.PredicateCycloneDXBOM, cyclonedx(amd64Digest, amd64Layer))})
+	assert.True(t, out.SBOMConsistencyCheck.Passed, "the SBOM can describe the image of a platform")
+
+	other := "sha256:" + imageDigest
+	checkSBOMConsistency(ctx, out, []attestation.Attestation{sbom(t, attestation.PredicateSpdxDocument, spdx(indexDigest, other))})
+	assert.False(t, out.SBOMConsistencyCheck.Passed)
+	assert.Equal(t, "SBOM consistency check failed: the SBOM refers to layers not found in the image manifest: "+other, out.SBOMConsistencyCheck.Result.Message)
+}
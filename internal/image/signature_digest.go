@@ -0,0 +1,111 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package image
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+)
+
+// SignatureDigest is the signature artifact the signatures of an image are
+// verified from, instead of discovering them.
+type SignatureDigest struct {
+	// Image is the reference of the image
+	Image string
+	// Reference is the digest reference of the signature artifact
+	Reference name.Digest
+}
+
+// ParseSignatureDigest parses the signature digest given as image=signature,
+// or as signature alone for the default image. The signature is either the
+// digest of the signature artifact within the repository of the image, e.g.
+// sha256:..., or the digest reference of the signature artifact, e.g.
+// registry.io/repository@sha256:....
+func ParseSignatureDigest(s, defaultImage string) (SignatureDigest, error) {
+	img, sig, ok := strings.Cut(s, "=")
+	if !ok {
+		if defaultImage == "" {
+			return SignatureDigest{}, fmt.Errorf("the signature digest %q is not in the image=digest form", s)
+		}
+		img, sig = defaultImage, s
+	}
+
+	ref, err := name.ParseReference(img)
+	if err != nil {
+		return SignatureDigest{}, fmt.Errorf("the signature digest %q has an invalid image reference: %w", s, err)
+	}
+
+	if d, err := name.NewDigest(sig); err == nil {
+		return SignatureDigest{Image: ref.Name(), Reference: d}, nil
+	}
+
+	if _, err := v1.NewHash(sig); err != nil {
+		return SignatureDigest{}, fmt.Errorf("the signature digest %q has an invalid digest: %w", s, err)
+	}
+
+	return SignatureDigest{Image: ref.Name(), Reference: ref.Context().Digest(sig)}, nil
+}
+
+// SignatureDigestOptions configures the signature artifacts the signatures
+// of the images are verified from.
+type SignatureDigestOptions struct {
+	// Digests lists the signature artifacts of the images. The signatures of
+	// other images are discovered using the cosign signature tag.
+	Digests []SignatureDigest
+}
+
+const signatureDigestOptionsKey contextKey = "ec.image.signature_digest"
+
+// WithSignatureDigestOptions returns a copy of the context instructing
+// ValidateImage to verify the signatures of the images from the given
+// signature artifacts.
+func WithSignatureDigestOptions(ctx context.Context, opts SignatureDigestOptions) context.Context {
+	return context.WithValue(ctx, signatureDigestOptionsKey, opts)
+}
+
+func signatureDigestOptions(ctx context.Context) SignatureDigestOptions {
+	if opts, ok := ctx.Value(signatureDigestOptionsKey).(SignatureDigestOptions); ok {
+		return opts
+	}
+
+	return SignatureDigestOptions{}
+}
+
+// signatureFor returns the signature artifact of the image reference, as
+// given, if known.
+func (o SignatureDigestOptions) signatureFor(url string) (name.Digest, bool) {
+	if len(o.Digests) == 0 {
+		return name.Digest{}, false
+	}
+
+	ref, err := name.ParseReference(url)
+	if err != nil {
+		return name.Digest{}, false
+	}
+
+	for _, d := range o.Digests {
+		if d.Image == ref.Name() {
+			return d.Reference, true
+		}
+	}
+
+	return name.Digest{}, false
+}
@@ -0,0 +1,106 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package image
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSignatureDigest(t *testing.T) {
+	digest := "sha256:" + strings.Repeat("a", 64)
+
+	cases := []struct {
+		name         string
+		value        string
+		defaultImage string
+		image        string
+		reference    string
+		err          string
+	}{
+		{
+			name:      "digest in the repository of the image",
+			value:     "registry.io/repository/image:tag=" + digest,
+			image:     "registry.io/repository/image:tag",
+			reference: "registry.io/repository/image@" + digest,
+		},
+		{
+			name:      "digest reference",
+			value:     "registry.io/repository/image:tag=registry.io/signatures@" + digest,
+			image:     "registry.io/repository/image:tag",
+			reference: "registry.io/signatures@" + digest,
+		},
+		{
+			name:         "default image",
+			value:        digest,
+			defaultImage: "registry.io/repository/image",
+			image:        "registry.io/repository/image:latest",
+			reference:    "registry.io/repository/image@" + digest,
+		},
+		{
+			name:  "no image",
+			value: digest,
+			err:   `the signature digest "` + digest + `" is not in the image=digest form`,
+		},
+		{
+			name:  "invalid digest",
+			value: "registry.io/repository/image=sha256:abc",
+			err:   `the signature digest "registry.io/repository/image=sha256:abc" has an invalid digest`,
+		},
+		{
+			name:  "invalid image",
+			value: "Registry.io/Repository=" + digest,
+			err:   "has an invalid image reference",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			sig, err := ParseSignatureDigest(c.value, c.defaultImage)
+			if c.err != "" {
+				assert.ErrorContains(t, err, c.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, c.image, sig.Image)
+			assert.Equal(t, c.reference, sig.Reference.String())
+		})
+	}
+}
+
+func TestSignatureFor(t *testing.T) {
+	digest := "sha256:" + strings.Repeat("a", 64)
+	sig, err := ParseSignatureDigest("registry.io/repository/image="+digest, "")
+	require.NoError(t, err)
+
+	opts := SignatureDigestOptions{Digests: []SignatureDigest{sig}}
+
+	ref, ok := opts.signatureFor("registry.io/repository/image:latest")
+	assert.True(t, ok)
+	assert.Equal(t, "registry.io/repository/image@"+digest, ref.String())
+
+	_, ok = opts.signatureFor("registry.io/repository/other")
+	assert.False(t, ok)
+
+	_, ok = SignatureDigestOptions{}.signatureFor("registry.io/repository/image")
+	assert.False(t, ok)
+}
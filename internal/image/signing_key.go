@@ -0,0 +1,108 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package image
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/enterprise-contract/ec-cli/internal/output"
+	"github.com/enterprise-contract/ec-cli/internal/signature"
+)
+
+// SigningKeyOptions configures the built-in signing key check.
+type SigningKeyOptions struct {
+	// MinRSAKeySize is the minimum size in bits of RSA signing keys. Zero
+	// disables the key size check.
+	MinRSAKeySize int
+	// AllowedAlgorithms lists the allowed signature algorithms, see
+	// signature.SignatureAlgorithms, both of the signatures and of the signing
+	// certificates. When empty, any algorithm is allowed.
+	AllowedAlgorithms []string
+}
+
+// Enabled returns true if any of the signing key checks is configured.
+func (o SigningKeyOptions) Enabled() bool {
+	return o.MinRSAKeySize > 0 || len(o.AllowedAlgorithms) > 0
+}
+
+func (o SigningKeyOptions) isAllowedAlgorithm(algorithm string) bool {
+	if len(o.AllowedAlgorithms) == 0 {
+		return true
+	}
+
+	for _, a := range o.AllowedAlgorithms {
+		if strings.EqualFold(a, algorithm) {
+			return true
+		}
+	}
+
+	return false
+}
+
+const signingKeyOptionsKey contextKey = "ec.image.signing_key"
+
+// WithSigningKeyOptions returns a copy of the context instructing ValidateImage
+// to check the algorithms and the key sizes of the signing material the image
+// signatures and the attestations were verified with.
+func WithSigningKeyOptions(ctx context.Context, opts SigningKeyOptions) context.Context {
+	return context.WithValue(ctx, signingKeyOptionsKey, opts)
+}
+
+func signingKeyOptions(ctx context.Context) SigningKeyOptions {
+	if opts, ok := ctx.Value(signingKeyOptionsKey).(SigningKeyOptions); ok {
+		return opts
+	}
+
+	return SigningKeyOptions{}
+}
+
+// checkSigningKeys sets the signing key check of the output if any of the
+// checks is configured. The first signing material failing the check is
+// reported.
+func checkSigningKeys(ctx context.Context, out *output.Output, keys []signature.SigningKey) {
+	opts := signingKeyOptions(ctx)
+	if !opts.Enabled() {
+		return
+	}
+
+	out.SetSigningKeyCheckFromError(keys, verifySigningKeys(opts, keys))
+}
+
+func verifySigningKeys(opts SigningKeyOptions, keys []signature.SigningKey) error {
+	if len(keys) == 0 {
+		return errors.New("unable to determine the signing material of the signatures")
+	}
+
+	for _, k := range keys {
+		if !opts.isAllowedAlgorithm(k.Algorithm) {
+			return fmt.Errorf("the signature algorithm %s is not allowed", k.Algorithm)
+		}
+
+		if k.CertificateAlgorithm != "" && !opts.isAllowedAlgorithm(k.CertificateAlgorithm) {
+			return fmt.Errorf("the signature algorithm %s of the signing certificate is not allowed", k.CertificateAlgorithm)
+		}
+
+		if opts.MinRSAKeySize > 0 && k.IsRSA() && k.KeySize < opts.MinRSAKeySize {
+			return fmt.Errorf("the RSA key size of %d bits is below the minimum of %d bits", k.KeySize, opts.MinRSAKeySize)
+		}
+	}
+
+	return nil
+}
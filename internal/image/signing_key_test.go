@@ -0,0 +1,84 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package image
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/output"
+	"github.com/enterprise-contract/ec-cli/internal/signature"
+)
+
+func TestVerifySigningKeys(t *testing.T) {
+	ecdsaKey := signature.SigningKey{Algorithm: "ECDSA-SHA256", KeySize: 256, CertificateAlgorithm: "ECDSA-SHA384"}
+	rsaKey := signature.SigningKey{Algorithm: "SHA256-RSA", KeySize: 2048}
+	sha1Cert := signature.SigningKey{Algorithm: "ECDSA-SHA256", KeySize: 256, CertificateAlgorithm: "SHA1-RSA"}
+
+	cases := []struct {
+		name string
+		opts SigningKeyOptions
+		keys []signature.SigningKey
+		err  string
+	}{
+		{name: "any", opts: SigningKeyOptions{MinRSAKeySize: 1024}, keys: []signature.SigningKey{ecdsaKey, rsaKey}},
+		{name: "no keys", opts: SigningKeyOptions{MinRSAKeySize: 1024}, err: "unable to determine the signing material of the signatures"},
+		{name: "small RSA key", opts: SigningKeyOptions{MinRSAKeySize: 3072}, keys: []signature.SigningKey{ecdsaKey, rsaKey}, err: "the RSA key size of 2048 bits is below the minimum of 3072 bits"},
+		{name: "ECDSA key size is not checked", opts: SigningKeyOptions{MinRSAKeySize: 3072}, keys: []signature.SigningKey{ecdsaKey}},
+		{name: "allowed algorithms", opts: SigningKeyOptions{AllowedAlgorithms: []string{"ecdsa-sha256", "ECDSA-SHA384"}}, keys: []signature.SigningKey{ecdsaKey}},
+		{name: "disallowed algorithm", opts: SigningKeyOptions{AllowedAlgorithms: []string{"ECDSA-SHA256", "ECDSA-SHA384"}}, keys: []signature.SigningKey{ecdsaKey, rsaKey}, err: "the signature algorithm SHA256-RSA is not allowed"},
+		{name: "disallowed certificate algorithm", opts: SigningKeyOptions{AllowedAlgorithms: []string{"ECDSA-SHA256"}}, keys: []signature.SigningKey{sha1Cert}, err: "the signature algorithm SHA1-RSA of the signing certificate is not allowed"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := verifySigningKeys(c.opts, c.keys)
+			if c.err != "" {
+				assert.EqualError(t, err, c.err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestCheckSigningKeys(t *testing.T) {
+	keys := []signature.SigningKey{{Algorithm: "SHA1-RSA", KeySize: 1024}}
+
+	out := &output.Output{}
+	checkSigningKeys(context.Background(), out, keys)
+	assert.Nil(t, out.SigningKeyCheck, "the check is disabled by default")
+	assert.Nil(t, out.SigningKeys)
+
+	ctx := WithSigningKeyOptions(context.Background(), SigningKeyOptions{MinRSAKeySize: 1024})
+	checkSigningKeys(ctx, out, keys)
+	require.NotNil(t, out.SigningKeyCheck)
+	assert.True(t, out.SigningKeyCheck.Passed)
+	assert.Equal(t, keys, out.SigningKeys)
+
+	ctx = WithSigningKeyOptions(context.Background(), SigningKeyOptions{MinRSAKeySize: 2048})
+	checkSigningKeys(ctx, out, keys)
+	assert.False(t, out.SigningKeyCheck.Passed)
+	assert.Equal(t, "Signing key check failed: the RSA key size of 1024 bits is below the minimum of 2048 bits", out.SigningKeyCheck.Result.Message)
+	assert.Equal(t, map[string]any{"code": "builtin.image.signing_key"}, out.SigningKeyCheck.Result.Metadata)
+	assert.Len(t, out.Violations(), 1)
+}
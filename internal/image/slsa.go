@@ -0,0 +1,207 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package image
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	v02 "github.com/in-toto/in-toto-golang/in_toto/slsa_provenance/v0.2"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/enterprise-contract/ec-cli/internal/attestation"
+	"github.com/enterprise-contract/ec-cli/internal/output"
+)
+
+// MaxSLSALevel is the highest SLSA level that can be determined.
+const MaxSLSALevel = 4
+
+// SLSAOptions configures the built-in SLSA level check.
+type SLSAOptions struct {
+	// MinLevel is the minimum SLSA level an image needs to meet, zero disables
+	// the check.
+	MinLevel int
+	// BuilderIDs lists the trusted builders. When empty, any builder is trusted.
+	BuilderIDs []string
+}
+
+type contextKey string
+
+const slsaOptionsKey contextKey = "ec.image.slsa"
+
+// WithSLSAOptions returns a copy of the context instructing ValidateImage to
+// determine the SLSA level of each image and to fail images that do not meet
+// the minimum level.
+func WithSLSAOptions(ctx context.Context, opts SLSAOptions) context.Context {
+	return context.WithValue(ctx, slsaOptionsKey, opts)
+}
+
+func slsaOptions(ctx context.Context) SLSAOptions {
+	if opts, ok := ctx.Value(slsaOptionsKey).(SLSAOptions); ok {
+		return opts
+	}
+
+	return SLSAOptions{}
+}
+
+// slsaProvenance holds the parts of the SLSA Provenance used to determine the
+// SLSA level, normalized across the v0.2 and the v1 predicates, see
+// attestation.Normalize. The completeness of the materials and the
+// reproducibility of the build are only recorded by the v0.2 predicate.
+type slsaProvenance struct {
+	attestation.Provenance
+	materialsComplete bool
+	reproducible      bool
+}
+
+// parseSLSAProvenance returns the SLSA Provenance of the attestation, false
+// for other predicate types.
+func parseSLSAProvenance(att attestation.Attestation) (slsaProvenance, bool) {
+	predicateType := att.PredicateType()
+	if predicateType != attestation.PredicateSLSAProvenance && predicateType != attestation.PredicateSLSAProvenanceV1 {
+		return slsaProvenance{}, false
+	}
+
+	normalized, err := attestation.Normalize(predicateType, att.Statement())
+	if err != nil {
+		log.Debugf("Unable to parse the SLSA Provenance attestation: %s", err)
+		return slsaProvenance{}, false
+	}
+
+	provenance, ok := normalized.(attestation.Provenance)
+	if !ok {
+		return slsaProvenance{}, false
+	}
+	p := slsaProvenance{Provenance: provenance}
+
+	if predicateType == attestation.PredicateSLSAProvenance {
+		var s struct {
+			Predicate struct {
+				Metadata *v02.ProvenanceMetadata `json:"metadata"`
+			} `json:"predicate"`
+		}
+		if err := json.Unmarshal(att.Statement(), &s); err == nil && s.Predicate.Metadata != nil {
+			p.materialsComplete = s.Predicate.Metadata.Completeness.Materials
+			p.reproducible = s.Predicate.Metadata.Reproducible
+		}
+	}
+
+	return p, true
+}
+
+// SLSALevel determines the highest SLSA level met by any of the verified SLSA
+// Provenance attestations, v0.2 or v1, along with the reasons a higher level
+// was not met.
+//
+//   - Level 1 requires a provenance attestation.
+//   - Level 2 also requires the provenance to identify the builder.
+//   - Level 3 also requires a trusted builder and an identified build
+//     invocation.
+//   - Level 4 also requires a hermetic and reproducible build. The v1
+//     predicate does not record the build as reproducible, its level is at
+//     most 3.
+func SLSALevel(attestations []attestation.Attestation, opts SLSAOptions) (int, []string) {
+	level := 0
+	reasons := []string{"no SLSA Provenance attestation found"}
+
+	for _, att := range attestations {
+		p, ok := parseSLSAProvenance(att)
+		if !ok {
+			continue
+		}
+
+		l, r := provenanceLevel(p, opts)
+		if l > level || (l == level && len(r) < len(reasons)) {
+			level, reasons = l, r
+		}
+	}
+
+	return level, reasons
+}
+
+func provenanceLevel(p slsaProvenance, opts SLSAOptions) (int, []string) {
+	builderID := p.BuilderID
+	if builderID == "" {
+		return 1, []string{"the provenance does not identify the builder"}
+	}
+
+	// The trusted builders are matched as the allowed builders, see
+	// CompileBuilderIDPattern
+	var reasons []string
+	if trusted := (BuilderIDOptions{Allowed: opts.BuilderIDs}); len(trusted.Allowed) > 0 && !trusted.IsAllowedBuilderID(builderID) {
+		reasons = append(reasons, fmt.Sprintf("the builder %q is not trusted", builderID))
+	}
+	if p.InvocationID == "" {
+		reasons = append(reasons, "the provenance does not identify the build invocation")
+	}
+	if len(reasons) > 0 {
+		return 2, reasons
+	}
+
+	if !isHermetic(p) {
+		reasons = append(reasons, "the build is not hermetic")
+	}
+	if !p.reproducible {
+		reasons = append(reasons, "the build is not reproducible")
+	}
+	if len(reasons) > 0 {
+		return 3, reasons
+	}
+
+	return MaxSLSALevel, nil
+}
+
+// isHermetic considers a build hermetic when the provenance claims the list of
+// materials is complete, or when the build was invoked with the hermetic
+// parameter set, invocation.parameters of the v0.2 and
+// buildDefinition.externalParameters of the v1 predicate.
+func isHermetic(p slsaProvenance) bool {
+	if p.materialsComplete {
+		return true
+	}
+
+	parameters, _ := p.Parameters.(map[string]any)
+	switch v := parameters["hermetic"].(type) {
+	case bool:
+		return v
+	case string:
+		return strings.EqualFold(v, "true")
+	}
+
+	return false
+}
+
+// checkSLSALevel sets the SLSA level check of the output if a minimum SLSA
+// level is required.
+func checkSLSALevel(ctx context.Context, out *output.Output, attestations []attestation.Attestation) {
+	opts := slsaOptions(ctx)
+	if opts.MinLevel == 0 {
+		return
+	}
+
+	level, reasons := SLSALevel(attestations, opts)
+	log.Debugf("Determined SLSA level %d, minimum required level is %d", level, opts.MinLevel)
+
+	var err error
+	if level < opts.MinLevel {
+		err = fmt.Errorf("SLSA level %d does not meet the minimum level %d: %s", level, opts.MinLevel, strings.Join(reasons, ", "))
+	}
+
+	out.SetSLSALevelCheckFromError(level, err)
+}
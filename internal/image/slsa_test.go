@@ -0,0 +1,232 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package image
+
+import (
+	"context"
+	"testing"
+
+	"github.com/in-toto/in-toto-golang/in_toto"
+	"github.com/in-toto/in-toto-golang/in_toto/slsa_provenance/common"
+	v02 "github.com/in-toto/in-toto-golang/in_toto/slsa_provenance/v0.2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/attestation"
+	"github.com/enterprise-contract/ec-cli/internal/output"
+)
+
+func provenance(t *testing.T, predicate v02.ProvenancePredicate) attestation.Attestation {
+	att, err := attestation.SLSAProvenanceFromSignature(sign(&in_toto.Statement{
+		StatementHeader: in_toto.StatementHeader{
+			Type:          in_toto.StatementInTotoV01,
+			PredicateType: v02.PredicateSLSAProvenance,
+			Subject: []in_toto.Subject{
+				{Name: imageRegistry, Digest: common.DigestSet{"sha256": imageDigest}},
+			},
+		},
+		Predicate: predicate,
+	}))
+	require.NoError(t, err)
+
+	return att
+}
+
+func TestSLSALevel(t *testing.T) {
+	identified := v02.ProvenancePredicate{
+		Builder:  common.ProvenanceBuilder{ID: "https://tekton.dev/chains/v2"},
+		Metadata: &v02.ProvenanceMetadata{BuildInvocationID: "build-1"},
+	}
+
+	hermetic := identified
+	hermetic.Metadata = &v02.ProvenanceMetadata{
+		BuildInvocationID: "build-1",
+		Reproducible:      true,
+		Completeness:      v02.ProvenanceComplete{Materials: true},
+	}
+
+	hermeticParam := identified
+	hermeticParam.Invocation = v02.ProvenanceInvocation{Parameters: map[string]any{"hermetic": "true"}}
+	hermeticParam.Metadata = &v02.ProvenanceMetadata{BuildInvocationID: "build-1", Reproducible: true}
+
+	cases := []struct {
+		name     string
+		preds    []v02.ProvenancePredicate
+		opts     SLSAOptions
+		expected int
+		reasons  []string
+	}{
+		{
+			name:     "no provenance",
+			expected: 0,
+			reasons:  []string{"no SLSA Provenance attestation found"},
+		},
+		{
+			name:     "no builder",
+			preds:    []v02.ProvenancePredicate{{}},
+			expected: 1,
+			reasons:  []string{"the provenance does not identify the builder"},
+		},
+		{
+			name:     "untrusted builder",
+			preds:    []v02.ProvenancePredicate{identified},
+			opts:     SLSAOptions{BuilderIDs: []string{"https://trusted.builder"}},
+			expected: 2,
+			reasons:  []string{`the builder "https://tekton.dev/chains/v2" is not trusted`},
+		},
+		{
+			name:     "no build invocation",
+			preds:    []v02.ProvenancePredicate{{Builder: identified.Builder}},
+			expected: 2,
+			reasons:  []string{"the provenance does not identify the build invocation"},
+		},
+		{
+			name:     "trusted builder",
+			preds:    []v02.ProvenancePredicate{identified},
+			opts:     SLSAOptions{BuilderIDs: []string{"https://tekton.dev/chains/v2"}},
+			expected: 3,
+			reasons:  []string{"the build is not hermetic", "the build is not reproducible"},
+		},
+		{
+			name:     "trusted builder pattern",
+			preds:    []v02.ProvenancePredicate{identified},
+			opts:     SLSAOptions{BuilderIDs: []string{"https://tekton.dev/*"}},
+			expected: 3,
+			reasons:  []string{"the build is not hermetic", "the build is not reproducible"},
+		},
+		{
+			name:     "hermetic and reproducible",
+			preds:    []v02.ProvenancePredicate{hermetic},
+			expected: 4,
+		},
+		{
+			name:     "hermetic parameter",
+			preds:    []v02.ProvenancePredicate{hermeticParam},
+			expected: 4,
+		},
+		{
+			name:     "highest level wins",
+			preds:    []v02.ProvenancePredicate{{}, hermetic, identified},
+			expected: 4,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			atts := make([]attestation.Attestation, 0, len(c.preds))
+			for _, p := range c.preds {
+				atts = append(atts, provenance(t, p))
+			}
+
+			level, reasons := SLSALevel(atts, c.opts)
+			assert.Equal(t, c.expected, level)
+			assert.Equal(t, c.reasons, reasons)
+		})
+	}
+}
+
+func TestSLSALevelV1(t *testing.T) {
+	identified := map[string]any{
+		"runDetails": map[string]any{
+			"builder":  map[string]any{"id": "https://tekton.dev/chains/v2"},
+			"metadata": map[string]any{"invocationID": "build-1"},
+		},
+	}
+	hermetic := map[string]any{
+		"buildDefinition": map[string]any{"externalParameters": map[string]any{"hermetic": true}},
+		"runDetails":      identified["runDetails"],
+	}
+
+	cases := []struct {
+		name     string
+		pred     map[string]any
+		opts     SLSAOptions
+		expected int
+		reasons  []string
+	}{
+		{
+			name:     "no builder",
+			pred:     map[string]any{},
+			expected: 1,
+			reasons:  []string{"the provenance does not identify the builder"},
+		},
+		{
+			name: "no build invocation",
+			pred: map[string]any{
+				"runDetails": map[string]any{"builder": map[string]any{"id": "https://tekton.dev/chains/v2"}},
+			},
+			expected: 2,
+			reasons:  []string{"the provenance does not identify the build invocation"},
+		},
+		{
+			name:     "untrusted builder",
+			pred:     identified,
+			opts:     SLSAOptions{BuilderIDs: []string{"https://github.com/*"}},
+			expected: 2,
+			reasons:  []string{`the builder "https://tekton.dev/chains/v2" is not trusted`},
+		},
+		{
+			name:     "trusted builder",
+			pred:     identified,
+			opts:     SLSAOptions{BuilderIDs: []string{"https://tekton.dev/*"}},
+			expected: 3,
+			reasons:  []string{"the build is not hermetic", "the build is not reproducible"},
+		},
+		{
+			name:     "hermetic",
+			pred:     hermetic,
+			expected: 3,
+			reasons:  []string{"the build is not reproducible"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			att := sbom(t, attestation.PredicateSLSAProvenanceV1, c.pred)
+
+			level, reasons := SLSALevel([]attestation.Attestation{att}, c.opts)
+			assert.Equal(t, c.expected, level)
+			assert.Equal(t, c.reasons, reasons)
+		})
+	}
+}
+
+func TestCheckSLSALevel(t *testing.T) {
+	atts := []attestation.Attestation{provenance(t, v02.ProvenancePredicate{
+		Builder: common.ProvenanceBuilder{ID: "https://tekton.dev/chains/v2"},
+	})}
+
+	out := &output.Output{}
+	checkSLSALevel(context.Background(), out, atts)
+	assert.Nil(t, out.SLSALevelCheck, "the check is disabled by default")
+	assert.Nil(t, out.SLSALevel)
+
+	ctx := WithSLSAOptions(context.Background(), SLSAOptions{MinLevel: 2})
+	checkSLSALevel(ctx, out, atts)
+	require.NotNil(t, out.SLSALevelCheck)
+	assert.True(t, out.SLSALevelCheck.Passed)
+	assert.Equal(t, 2, *out.SLSALevel)
+
+	ctx = WithSLSAOptions(context.Background(), SLSAOptions{MinLevel: 3})
+	checkSLSALevel(ctx, out, atts)
+	assert.False(t, out.SLSALevelCheck.Passed)
+	assert.Equal(t, "SLSA level check failed: SLSA level 2 does not meet the minimum level 3: the provenance does not identify the build invocation", out.SLSALevelCheck.Result.Message)
+	assert.Equal(t, map[string]any{"code": "builtin.attestation.slsa_level"}, out.SLSALevelCheck.Result.Metadata)
+	assert.Len(t, out.Violations(), 1)
+}
@@ -0,0 +1,149 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package image
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+
+	"github.com/enterprise-contract/ec-cli/internal/attestation"
+	"github.com/enterprise-contract/ec-cli/internal/output"
+)
+
+// SubjectConsistencyOptions configures the built-in subject consistency
+// check.
+type SubjectConsistencyOptions struct {
+	// Enabled turns on comparing the subject digests of the attestations.
+	Enabled bool
+}
+
+const subjectConsistencyOptionsKey contextKey = "ec.image.subject_consistency"
+
+// WithSubjectConsistencyOptions returns a copy of the context instructing
+// ValidateImage to check that the attestations describe the same subjects.
+func WithSubjectConsistencyOptions(ctx context.Context, opts SubjectConsistencyOptions) context.Context {
+	return context.WithValue(ctx, subjectConsistencyOptionsKey, opts)
+}
+
+func subjectConsistencyOptions(ctx context.Context) SubjectConsistencyOptions {
+	if opts, ok := ctx.Value(subjectConsistencyOptionsKey).(SubjectConsistencyOptions); ok {
+		return opts
+	}
+
+	return SubjectConsistencyOptions{}
+}
+
+// checkSubjectConsistency sets the subject consistency check of the output if
+// enabled. All the attestations, regardless of their predicate type, need to
+// describe the image, e.g. the SLSA Provenance and the SBOM of an image need
+// to come from the same build. Attestations may describe other subjects too,
+// e.g. the image index along with the image of each platform.
+func checkSubjectConsistency(ctx context.Context, out *output.Output, attestations []attestation.Attestation) {
+	if !subjectConsistencyOptions(ctx).Enabled {
+		return
+	}
+
+	var imageDigest string
+	if ref, err := name.NewDigest(out.ImageURL); err == nil {
+		imageDigest = ref.DigestStr()
+	}
+
+	digests, err := compareSubjectDigests(imageDigest, attestations)
+	out.SetSubjectConsistencyCheckFromError(digests, err)
+}
+
+// compareSubjectDigests returns an error if any of the attestations does not
+// have the image digest among its subject digests, or, when the image digest
+// is not known, if the attestations have no subject digest in common. Along
+// with the error all the distinct subject digests found are returned, sorted.
+func compareSubjectDigests(imageDigest string, attestations []attestation.Attestation) ([]string, error) {
+	if len(attestations) == 0 {
+		return nil, nil
+	}
+
+	// the subject digests, of each predicate type, of the attestations not
+	// describing the image
+	byType := map[string][]string{}
+	var distinct []string
+	common := subjectDigests(attestations[0])
+	consistent := true
+	for _, att := range attestations {
+		digests := subjectDigests(att)
+		distinct = appendDistinct(distinct, digests...)
+
+		if imageDigest == "" {
+			common = slices.DeleteFunc(common, func(d string) bool {
+				return !slices.Contains(digests, d)
+			})
+			consistent = len(common) > 0
+			continue
+		}
+
+		if slices.Contains(digests, imageDigest) {
+			continue
+		}
+		consistent = false
+
+		key := strings.Join(digests, ", ")
+		if !slices.Contains(byType[att.PredicateType()], key) {
+			byType[att.PredicateType()] = append(byType[att.PredicateType()], key)
+		}
+	}
+
+	if consistent {
+		return nil, nil
+	}
+
+	sort.Strings(distinct)
+
+	if imageDigest == "" {
+		return distinct, fmt.Errorf("the attestations describe different subjects, no subject digest is common to all of them: %s", strings.Join(distinct, ", "))
+	}
+
+	types := make([]string, 0, len(byType))
+	for t := range byType {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+
+	msgs := make([]string, 0, len(types))
+	for _, t := range types {
+		sort.Strings(byType[t])
+		msgs = append(msgs, fmt.Sprintf("%s: %s", t, strings.Join(byType[t], " | ")))
+	}
+
+	return distinct, fmt.Errorf("the attestations describe different subjects, not including the image digest %s, %s", imageDigest, strings.Join(msgs, "; "))
+}
+
+// subjectDigests returns the digests of the subjects of the attestation as
+// algorithm:value, sorted.
+func subjectDigests(att attestation.Attestation) []string {
+	var digests []string
+	for _, s := range att.Subject() {
+		for algorithm, value := range s.Digest {
+			digests = appendDistinct(digests, algorithm+":"+value)
+		}
+	}
+	sort.Strings(digests)
+
+	return digests
+}
@@ -0,0 +1,97 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package image
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/in-toto/in-toto-golang/in_toto"
+	"github.com/in-toto/in-toto-golang/in_toto/slsa_provenance/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/attestation"
+	"github.com/enterprise-contract/ec-cli/internal/output"
+)
+
+const otherDigest = "0000000000000000000000000000000000000000000000000000000000000000"
+
+func subjectAttestation(t *testing.T, predicateType string, digests ...string) attestation.Attestation {
+	statement := in_toto.Statement{
+		StatementHeader: in_toto.StatementHeader{
+			Type:          in_toto.StatementInTotoV01,
+			PredicateType: predicateType,
+		},
+	}
+	for _, d := range digests {
+		statement.Subject = append(statement.Subject, in_toto.Subject{Name: imageRegistry, Digest: common.DigestSet{"sha256": d}})
+	}
+
+	data, err := json.Marshal(statement)
+	require.NoError(t, err)
+
+	att, err := attestation.FromStatement(data, nil)
+	require.NoError(t, err)
+
+	return att
+}
+
+func TestCheckSubjectConsistency(t *testing.T) {
+	provenance := subjectAttestation(t, attestation.PredicateSLSAProvenance, imageDigest)
+	sbom := subjectAttestation(t, "https://spdx.dev/Document", imageDigest)
+	// e.g. the image index along with the image of each platform
+	indexProvenance := subjectAttestation(t, attestation.PredicateSLSAProvenance, otherDigest, imageDigest)
+	otherSBOM := subjectAttestation(t, "https://spdx.dev/Document", otherDigest)
+
+	ctx := context.Background()
+	enabled := WithSubjectConsistencyOptions(ctx, SubjectConsistencyOptions{Enabled: true})
+
+	out := &output.Output{ImageURL: imageRegistry + "@sha256:" + imageDigest}
+	checkSubjectConsistency(ctx, out, []attestation.Attestation{provenance, otherSBOM})
+	assert.Nil(t, out.SubjectConsistencyCheck, "the check is disabled by default")
+
+	checkSubjectConsistency(enabled, out, []attestation.Attestation{provenance, sbom, indexProvenance})
+	require.NotNil(t, out.SubjectConsistencyCheck)
+	assert.True(t, out.SubjectConsistencyCheck.Passed)
+	assert.Empty(t, out.SubjectDigests)
+
+	checkSubjectConsistency(enabled, out, []attestation.Attestation{provenance, indexProvenance, otherSBOM})
+	assert.False(t, out.SubjectConsistencyCheck.Passed)
+	assert.Equal(t, "Subject consistency check failed: the attestations describe different subjects, "+
+		"not including the image digest sha256:"+imageDigest+", https://spdx.dev/Document: sha256:"+otherDigest,
+		out.SubjectConsistencyCheck.Result.Message)
+	assert.Equal(t, []string{"sha256:" + otherDigest, "sha256:" + imageDigest}, out.SubjectDigests)
+	assert.Len(t, out.Violations(), 1)
+}
+
+func TestCompareSubjectDigestsWithoutImageDigest(t *testing.T) {
+	provenance := subjectAttestation(t, attestation.PredicateSLSAProvenance, imageDigest)
+	indexProvenance := subjectAttestation(t, attestation.PredicateSLSAProvenance, otherDigest, imageDigest)
+	otherSBOM := subjectAttestation(t, "https://spdx.dev/Document", otherDigest)
+
+	digests, err := compareSubjectDigests("", []attestation.Attestation{provenance, indexProvenance})
+	assert.NoError(t, err)
+	assert.Empty(t, digests)
+
+	digests, err = compareSubjectDigests("", []attestation.Attestation{provenance, indexProvenance, otherSBOM})
+	assert.EqualError(t, err, "the attestations describe different subjects, no subject digest is common to all of them: sha256:"+otherDigest+", sha256:"+imageDigest)
+	assert.Equal(t, []string{"sha256:" + otherDigest, "sha256:" + imageDigest}, digests)
+}
@@ -0,0 +1,84 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package image
+
+import (
+	"context"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/enterprise-contract/ec-cli/internal/evaluator"
+	"github.com/enterprise-contract/ec-cli/internal/output"
+	"github.com/enterprise-contract/ec-cli/internal/policy"
+	"github.com/enterprise-contract/ec-cli/internal/telemetry"
+)
+
+// digestOf returns the digest of the image reference, or an empty string
+// if the reference is not pinned by digest.
+func digestOf(url string) string {
+	if i := strings.LastIndex(url, "@"); i != -1 {
+		return url[i+1:]
+	}
+
+	return ""
+}
+
+// endImageSpan ends the span of the validation or verification of an image,
+// successful if the image has no violations.
+func endImageSpan(span trace.Span, out *output.Output, err error) {
+	if out == nil {
+		telemetry.End(span, telemetry.OutcomeError, err)
+		return
+	}
+
+	span.SetAttributes(telemetry.ImageDigest.String(digestOf(out.ImageURL)))
+	telemetry.End(span, telemetry.OutcomeOf(len(out.Violations()) == 0), err)
+}
+
+// policySourceCount returns the number of policy and data sources of the
+// policy.
+func policySourceCount(p policy.Policy) int {
+	count := 0
+	for _, s := range p.Spec().Sources {
+		count += len(s.Policy) + len(s.Data)
+	}
+
+	return count
+}
+
+// evaluate evaluates the policy of the evaluator within its own span,
+// successful if there are no failures.
+func evaluate(ctx context.Context, e evaluator.Evaluator, target evaluator.EvaluationTarget, p policy.Policy) ([]evaluator.Outcome, evaluator.Data, error) {
+	ctx, span := telemetry.Start(ctx, "evaluate policy",
+		telemetry.ImageDigest.String(target.Target),
+		telemetry.PolicySources.Int(policySourceCount(p)),
+	)
+
+	results, data, err := e.Evaluate(ctx, target)
+
+	passed := true
+	for _, r := range results {
+		if len(r.Failures) > 0 {
+			passed = false
+			break
+		}
+	}
+	telemetry.End(span, telemetry.OutcomeOf(passed), err)
+
+	return results, data, err
+}
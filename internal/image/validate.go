@@ -19,6 +19,8 @@ package image
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"sort"
 	"time"
 
@@ -31,20 +33,34 @@ import (
 	"github.com/enterprise-contract/ec-cli/internal/evaluator"
 	"github.com/enterprise-contract/ec-cli/internal/output"
 	"github.com/enterprise-contract/ec-cli/internal/policy"
+	"github.com/enterprise-contract/ec-cli/internal/telemetry"
 )
 
 // ValidateImage executes the required method calls to evaluate a given policy
 // against a given image url.
-func ValidateImage(ctx context.Context, comp app.SnapshotComponent, snap *app.SnapshotSpec, p policy.Policy, evaluators []evaluator.Evaluator, detailed bool) (*output.Output, error) {
+func ValidateImage(ctx context.Context, comp app.SnapshotComponent, snap *app.SnapshotSpec, p policy.Policy, evaluators []evaluator.Evaluator, detailed bool) (out *output.Output, err error) {
 	log.Debugf("Validating image %s", comp.ContainerImage)
 
-	out := &output.Output{ImageURL: comp.ContainerImage, Detailed: detailed, Policy: p}
+	ctx, span := telemetry.Start(ctx, "validate image")
+	defer func() { endImageSpan(span, out, err) }()
+
+	// The checks read the manifest and the config of the image, fetch them
+	// once, see FetchOptions. The evaluators are given the context as is, the
+	// images the policy rules fetch are not kept.
+	evalCtx := ctx
+	ctx = withImageMetadata(ctx)
+
+	out = &output.Output{ImageURL: comp.ContainerImage, Detailed: detailed, Policy: p}
 	a, err := application_snapshot_image.NewApplicationSnapshotImage(ctx, comp, p, *snap)
 	if err != nil {
 		log.Debug("Failed to create application snapshot image!")
 		return nil, err
 	}
 
+	if sig, ok := signatureDigestOptions(ctx).signatureFor(comp.ContainerImage); ok {
+		a.SetSignatureReference(sig)
+	}
+
 	out.SetImageAccessibleCheckFromError(a.ValidateImageAccess(ctx))
 	if !out.ImageAccessibleCheck.Passed {
 		return out, nil
@@ -56,6 +72,18 @@ func ValidateImage(ctx context.Context, comp app.SnapshotComponent, snap *app.Sn
 		out.ImageURL = resolved
 	}
 
+	// The decision of the operator on the digest overrides the policy, the
+	// image is neither verified nor evaluated
+	if checkOperatorDigest(ctx, out) {
+		return out, nil
+	}
+
+	checkPinnedDigest(ctx, out, comp.ContainerImage)
+
+	checkMediaTypes(ctx, out)
+
+	checkRequiredLabels(ctx, out)
+
 	if err := a.FetchImageConfig(ctx); err != nil {
 		log.Debugf("Unable to fetch image config: %s", err)
 	}
@@ -66,28 +94,44 @@ func ValidateImage(ctx context.Context, comp app.SnapshotComponent, snap *app.Sn
 		log.Debugf("Unable to fetch image manifests: %s", err)
 	}
 
-	out.SetImageSignatureCheckFromError(a.ValidateImageSignature(ctx))
-
-	out.SetAttestationSignatureCheckFromError(a.ValidateAttestationSignature(ctx))
-	if !out.AttestationSignatureCheck.Passed {
+	if !verifySignatures(ctx, out, a) {
 		return out, nil
 	}
 
-	out.Signatures = a.Signatures()
+	checkSLSALevel(ctx, out, a.Attestations())
 
-	out.Attestations = a.Attestations()
+	checkBuilderID(ctx, out, a.Attestations())
 
-	out.SetAttestationSyntaxCheckFromError(a.ValidateAttestationSyntax(ctx))
+	checkBaseImage(ctx, out, a.Attestations())
+
+	checkRekorTime(ctx, out, a.Attestations(), a.AttestationLogEntryTimes())
+
+	checkSBOMConsistency(ctx, out, a.Attestations())
 
-	if attestationTime := determineAttestationTime(ctx, a.Attestations()); attestationTime != nil {
+	checkAttestationConflicts(ctx, out, a.Attestations())
+
+	checkSubjectConsistency(ctx, out, a.Attestations())
+
+	attestationTime := determineAttestationTime(ctx, a.Attestations())
+	if attestationTime != nil {
 		p.AttestationTime(*attestationTime)
 	}
 
+	// The policy is shared by the images, the age is measured at the
+	// effective time of this image
+	checkImageAge(ctx, out, p.EffectiveTimeFor(attestationTime))
+
 	att := a.Attestations()
 	attCount := len(att)
 	out.Attestations = att
 	log.Debugf("Found %d attestations", attCount)
-	if attCount == 0 {
+	if attestationsOptional(ctx) {
+		// The policy rules depending on the attestations can tell there are
+		// none, and be skipped
+		present := attCount > 0
+		out.AttestationsPresent = &present
+		a.SetAttestationsPresent(present)
+	} else if attCount == 0 {
 		// This is very much a corner case.
 		out.SetPolicyCheck([]evaluator.Outcome{
 			{
@@ -105,6 +149,10 @@ func ValidateImage(ctx context.Context, comp app.SnapshotComponent, snap *app.Sn
 		return nil, err
 	}
 
+	if !checkInputSize(ctx, out, inputJSON) {
+		return out, nil
+	}
+
 	var allResults []evaluator.Outcome
 
 	for _, e := range evaluators {
@@ -115,9 +163,17 @@ func ValidateImage(ctx context.Context, comp app.SnapshotComponent, snap *app.Sn
 		} else {
 			target.Target = digest
 		}
-		results, data, err := e.Evaluate(ctx, target)
+		results, data, err := evaluate(evalCtx, e, target, p)
 		log.Debug("\n\nRunning conftest policy check\n\n")
 
+		// Exceeding the evaluation budget fails the image, not the whole
+		// validation
+		var limitErr evaluator.ResourceLimitError
+		if errors.As(err, &limitErr) {
+			allResults = append(allResults, resourceLimitOutcome(limitErr))
+			continue
+		}
+
 		if err != nil {
 			log.Debug("Problem running conftest policy check!")
 			return nil, err
@@ -134,7 +190,84 @@ func ValidateImage(ctx context.Context, comp app.SnapshotComponent, snap *app.Sn
 	return out, nil
 }
 
-func resolveAndSetImageUrl(ctx context.Context, url string, asi *application_snapshot_image.ApplicationSnapshotImage) (string, error) {
+// VerifyImage verifies the signature and the attestations of the given image
+// without evaluating any policy. Only the key or the identity, and the Rekor
+// settings, of the policy are used.
+func VerifyImage(ctx context.Context, comp app.SnapshotComponent, p policy.Policy, detailed bool) (out *output.Output, err error) {
+	log.Debugf("Verifying image %s", comp.ContainerImage)
+
+	ctx, span := telemetry.Start(ctx, "verify image")
+	defer func() { endImageSpan(span, out, err) }()
+
+	// the checks read the manifest and the config of the image, fetch them
+	// once, see FetchOptions
+	ctx = withImageMetadata(ctx)
+
+	out = &output.Output{ImageURL: comp.ContainerImage, Detailed: detailed, Policy: p}
+	snap := app.SnapshotSpec{Components: []app.SnapshotComponent{comp}}
+	a, err := application_snapshot_image.NewApplicationSnapshotImage(ctx, comp, p, snap)
+	if err != nil {
+		log.Debug("Failed to create application snapshot image!")
+		return nil, err
+	}
+
+	out.SetImageAccessibleCheckFromError(a.ValidateImageAccess(ctx))
+	if !out.ImageAccessibleCheck.Passed {
+		return out, nil
+	}
+
+	if resolved, err := resolveAndSetImageUrl(ctx, comp.ContainerImage, a); err != nil {
+		return nil, err
+	} else {
+		out.ImageURL = resolved
+	}
+
+	verifySignatures(ctx, out, a)
+
+	return out, nil
+}
+
+// verifySignatures sets the signature checks of the output, returning false if
+// the attestations could not be verified.
+func verifySignatures(ctx context.Context, out *output.Output, a *application_snapshot_image.ApplicationSnapshotImage) bool {
+	ctx, span := telemetry.Start(ctx, "verify signatures", telemetry.ImageDigest.String(digestOf(out.ImageURL)))
+	defer func() {
+		telemetry.End(span, telemetry.OutcomeOf(out.ImageSignatureCheck.Passed && out.AttestationSignatureCheck.Passed), nil)
+	}()
+
+	out.SetImageSignatureCheckFromError(a.ValidateImageSignature(ctx))
+
+	out.SetAttestationSignatureCheckFromError(a.ValidateAttestationSignature(ctx))
+	missing := !out.AttestationSignatureCheck.Passed && allowMissingAttestations(ctx, out)
+	if !out.AttestationSignatureCheck.Passed {
+		return false
+	}
+
+	out.Signatures = a.Signatures()
+	out.SignerIdentities = a.Identities()
+	out.SigningTimes = a.SigningTimes()
+
+	checkSigningKeys(ctx, out, a.SigningKeys())
+
+	out.Attestations = a.Attestations()
+
+	// There are no attestations to check the syntax of
+	if missing {
+		return true
+	}
+
+	out.SetAttestationSyntaxCheckFromError(a.ValidateAttestationSyntax(ctx))
+
+	return true
+}
+
+func resolveAndSetImageUrl(ctx context.Context, url string, asi *application_snapshot_image.ApplicationSnapshotImage) (resolved string, err error) {
+	ctx, span := telemetry.Start(ctx, "resolve digest")
+	defer func() {
+		span.SetAttributes(telemetry.ImageDigest.String(digestOf(resolved)))
+		telemetry.End(span, telemetry.OutcomeSuccess, err)
+	}()
+
 	// Ensure image URL contains a digest to avoid ambiguity in the next
 	// validation steps
 	ref, err := ParseAndResolve(ctx, url)
@@ -149,7 +282,7 @@ func resolveAndSetImageUrl(ctx context.Context, url string, asi *application_sna
 	// such confusion and to further emphasize that the image is only accessed by digest
 	// from this point forward.
 	ref.Tag = ""
-	resolved := ref.String()
+	resolved = ref.String()
 	log.Debugf("Resolved image to %s", resolved)
 
 	if err := asi.SetImageURL(resolved); err != nil {
@@ -216,3 +349,18 @@ func determineAttestationTime(ctx context.Context, attestations []attestation.At
 
 	return &attestationTime
 }
+
+func resourceLimitOutcome(err evaluator.ResourceLimitError) evaluator.Outcome {
+	return evaluator.Outcome{
+		Failures: []evaluator.Result{{
+			Message: fmt.Sprintf("Policy evaluation failed: %s", err),
+			Metadata: map[string]any{
+				"code":        "builtin.policy.resource_limit",
+				"title":       "Policy evaluation within the resource limits",
+				"description": "The evaluation of the policy did not exceed the configured resource limits.",
+				"limit":       err.Limit,
+				"value":       err.Value,
+			},
+		}},
+	}
+}
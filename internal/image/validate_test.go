@@ -236,7 +236,7 @@ func sign(statement *in_toto.Statement) oci.Signature {
 	}
 	payload := base64.StdEncoding.EncodeToString(statementJson)
 	signature, err := static.NewSignature(
-		[]byte(`{"payload":"`+payload+`"}`),
+		[]byte(`{"payloadType":"`+cosignTypes.IntotoPayloadType+`","payload":"`+payload+`"}`),
 		"signature",
 		static.WithLayerMediaType(types.MediaType((cosignTypes.DssePayloadType))),
 	)
@@ -346,3 +346,50 @@ func TestEvaluatorLifecycle(t *testing.T) {
 
 	require.NoError(t, err)
 }
+
+func TestValidateImageResourceLimit(t *testing.T) {
+	client := fake.FakeClient{}
+	client.On("Head", mock.Anything).Return(&v1.Descriptor{MediaType: types.OCIManifestSchema1}, nil)
+	client.On("Image", name.MustParseReference(imageRegistry+"@sha256:"+imageDigest), mock.Anything).Return(empty.Image, nil)
+	client.On("VerifyImageSignatures", refNoTag, mock.Anything).Return([]oci.Signature{validSignature}, true, nil)
+	client.On("VerifyImageAttestations", refNoTag, mock.Anything).Return([]oci.Signature{validAttestation}, true, nil)
+	client.On("ResolveDigest", refNoTag).Return("@sha256:"+imageDigest, nil)
+	ctx := ecoci.WithClient(context.Background(), &client)
+
+	policy, err := policy.NewOfflinePolicy(ctx, policy.Now)
+	require.NoError(t, err)
+
+	e := &mockEvaluator{}
+	e.On("Evaluate", ctx, mock.Anything).Return([]evaluator.Outcome{}, evaluator.Data{}, evaluator.ResourceLimitError{Limit: "memory", Value: "512Mi"})
+
+	out, err := ValidateImage(ctx, app.SnapshotComponent{ContainerImage: imageRef}, &app.SnapshotSpec{}, policy, []evaluator.Evaluator{e}, false)
+	require.NoError(t, err, "exceeding the evaluation budget fails the image, not the validation")
+
+	violations := out.Violations()
+	require.Len(t, violations, 1)
+	assert.Equal(t, "Policy evaluation failed: policy evaluation exceeded the memory limit of 512Mi", violations[0].Message)
+	assert.Equal(t, "builtin.policy.resource_limit", violations[0].Metadata["code"])
+}
+
+func TestVerifyImage(t *testing.T) {
+	client := fake.FakeClient{}
+	client.On("Head", mock.Anything).Return(&v1.Descriptor{MediaType: types.OCIManifestSchema1}, nil)
+	client.On("VerifyImageSignatures", refNoTag, mock.Anything).Return([]oci.Signature{validSignature}, true, nil)
+	client.On("VerifyImageAttestations", refNoTag, mock.Anything).Return([]oci.Signature{validAttestation}, true, nil)
+	client.On("ResolveDigest", refNoTag).Return("@sha256:"+imageDigest, nil)
+	ctx := ecoci.WithClient(context.Background(), &client)
+
+	policy, err := policy.NewOfflinePolicy(ctx, policy.Now)
+	require.NoError(t, err)
+
+	out, err := VerifyImage(ctx, app.SnapshotComponent{ContainerImage: imageRef}, policy, false)
+	require.NoError(t, err)
+
+	assert.Equal(t, imageRegistry+"@sha256:"+imageDigest, out.ImageURL)
+	assert.True(t, out.ImageSignatureCheck.Passed)
+	assert.True(t, out.AttestationSignatureCheck.Passed)
+	assert.True(t, out.AttestationSyntaxCheck.Passed)
+	assert.Empty(t, out.PolicyCheck, "no policy is evaluated")
+	assert.Empty(t, out.Violations())
+	client.AssertNotCalled(t, "Image", mock.Anything)
+}
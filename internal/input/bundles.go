@@ -0,0 +1,248 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package input
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	k8syaml "k8s.io/apimachinery/pkg/util/yaml"
+
+	"github.com/enterprise-contract/ec-cli/internal/evaluator"
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+	"github.com/enterprise-contract/ec-cli/internal/utils/oci"
+)
+
+// ResolvedBundleKey is the key added to each Tekton bundles resolver
+// reference holding the outcome of resolving the bundle reference.
+const ResolvedBundleKey = "resolvedBundle"
+
+// BundleResolutionOptions configures resolving the Tekton bundle references
+// of the inputs before they are evaluated.
+type BundleResolutionOptions struct {
+	// Enabled turns on resolving the tag of each bundle reference to a digest.
+	Enabled bool
+}
+
+type contextKey string
+
+const bundleResolutionOptionsKey contextKey = "ec.input.bundle_resolution"
+
+// WithBundleResolutionOptions returns a copy of the context instructing
+// ValidateInput to resolve the Tekton bundle references of the inputs.
+func WithBundleResolutionOptions(ctx context.Context, opts BundleResolutionOptions) context.Context {
+	return context.WithValue(ctx, bundleResolutionOptionsKey, opts)
+}
+
+func bundleResolutionOptions(ctx context.Context) BundleResolutionOptions {
+	if opts, ok := ctx.Value(bundleResolutionOptionsKey).(BundleResolutionOptions); ok {
+		return opts
+	}
+
+	return BundleResolutionOptions{}
+}
+
+// resolvedBundle is injected next to the params of each bundles resolver
+// reference. Digest is the digest the tag currently resolves to, or the pinned
+// digest if the reference has no tag. A pinned digest that differs from the
+// digest allows rules to flag drift.
+type resolvedBundle struct {
+	Reference    string `json:"reference"`
+	Tag          string `json:"tag,omitempty"`
+	PinnedDigest string `json:"pinnedDigest,omitempty"`
+	Digest       string `json:"digest"`
+}
+
+// resolveBundles resolves the bundle references of the Tekton bundles
+// resolver found in the given input files. Files with bundle references are
+// replaced by copies with the outcome of the resolution injected. Bundle
+// references that cannot be resolved are reported as warnings and are left as
+// they are.
+func resolveBundles(ctx context.Context, files []string) ([]string, []evaluator.Result) {
+	fs := utils.FS(ctx)
+	client := oci.NewClient(ctx)
+
+	resolved := make([]string, 0, len(files))
+	var warnings []evaluator.Result
+	for _, f := range files {
+		content, err := afero.ReadFile(fs, f)
+		if err != nil {
+			log.Debugf("Unable to read %s to resolve the bundle references: %s", f, err)
+			resolved = append(resolved, f)
+			continue
+		}
+
+		docs, err := decodeDocuments(content)
+		if err != nil {
+			log.Debugf("Unable to parse %s to resolve the bundle references: %s", f, err)
+			resolved = append(resolved, f)
+			continue
+		}
+
+		found := false
+		walkBundleRefs(docs, func(ref map[string]any, bundle string) {
+			found = true
+			r, err := resolveBundle(client, bundle)
+			if err != nil {
+				log.Debugf("Unable to resolve the bundle %s: %s", bundle, err)
+				warnings = append(warnings, bundleResolutionWarning(bundle, err))
+				return
+			}
+			ref[ResolvedBundleKey] = r
+		})
+
+		if !found {
+			resolved = append(resolved, f)
+			continue
+		}
+
+		// A file with multiple documents is evaluated as the list of its
+		// documents, the copy holds that list
+		var doc any = docs
+		if len(docs) == 1 {
+			doc = docs[0]
+		}
+
+		data, err := json.Marshal(doc)
+		if err != nil {
+			log.Debugf("Unable to write %s with the resolved bundle references: %s", f, err)
+			resolved = append(resolved, f)
+			continue
+		}
+
+		path, err := utils.WriteTempFile(ctx, string(data), "input-file-")
+		if err != nil {
+			log.Debugf("Unable to write %s with the resolved bundle references: %s", f, err)
+			resolved = append(resolved, f)
+			continue
+		}
+		resolved = append(resolved, path)
+	}
+
+	return resolved, warnings
+}
+
+// decodeDocuments parses all the YAML, or JSON, documents of the content,
+// skipping empty documents.
+func decodeDocuments(content []byte) ([]any, error) {
+	decoder := k8syaml.NewYAMLOrJSONDecoder(bytes.NewReader(content), 4096)
+
+	var docs []any
+	for {
+		var d any
+		if err := decoder.Decode(&d); err != nil {
+			if errors.Is(err, io.EOF) {
+				return docs, nil
+			}
+			return nil, err
+		}
+		if d == nil {
+			continue
+		}
+		docs = append(docs, d)
+	}
+}
+
+// walkBundleRefs calls fn for each bundles resolver reference, i.e. each
+// object with "resolver: bundles" and a "bundle" parameter, within the
+// document.
+func walkBundleRefs(doc any, fn func(ref map[string]any, bundle string)) {
+	switch v := doc.(type) {
+	case map[string]any:
+		if v["resolver"] == "bundles" {
+			if bundle := bundleParam(v["params"]); bundle != "" {
+				fn(v, bundle)
+			}
+		}
+		for _, value := range v {
+			walkBundleRefs(value, fn)
+		}
+	case []any:
+		for _, value := range v {
+			walkBundleRefs(value, fn)
+		}
+	}
+}
+
+func bundleParam(params any) string {
+	list, ok := params.([]any)
+	if !ok {
+		return ""
+	}
+
+	for _, p := range list {
+		if param, ok := p.(map[string]any); ok && param["name"] == "bundle" {
+			if value, ok := param["value"].(string); ok {
+				return value
+			}
+		}
+	}
+
+	return ""
+}
+
+func resolveBundle(client oci.Client, bundle string) (resolvedBundle, error) {
+	r := resolvedBundle{Reference: bundle}
+
+	tagged := bundle
+	if i := strings.Index(bundle, "@"); i != -1 {
+		d, err := name.NewDigest(bundle)
+		if err != nil {
+			return r, err
+		}
+		r.PinnedDigest = d.DigestStr()
+		tagged = bundle[:i]
+	}
+
+	// A reference pinned only by digest has nothing to resolve
+	tag, err := name.NewTag(tagged)
+	if err != nil {
+		return r, err
+	}
+	if r.PinnedDigest != "" && !strings.HasSuffix(tagged, ":"+tag.TagStr()) {
+		r.Digest = r.PinnedDigest
+		return r, nil
+	}
+	r.Tag = tag.TagStr()
+
+	digest, err := client.ResolveDigest(tag)
+	if err != nil {
+		return r, err
+	}
+	r.Digest = digest
+
+	return r, nil
+}
+
+func bundleResolutionWarning(bundle string, err error) evaluator.Result {
+	return evaluator.Result{
+		Message: fmt.Sprintf("Unable to resolve the bundle %s: %s", bundle, err),
+		Metadata: map[string]any{
+			"code":        "builtin.input.bundle_resolution",
+			"title":       "Bundle reference resolution",
+			"description": "The tag of the Tekton bundle reference could be resolved to a digest.",
+		},
+	}
+}
@@ -0,0 +1,184 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package input
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+	"github.com/enterprise-contract/ec-cli/internal/utils/oci"
+	"github.com/enterprise-contract/ec-cli/internal/utils/oci/fake"
+)
+
+const (
+	currentDigest = "sha256:0000000000000000000000000000000000000000000000000000000000000001"
+	pinnedDigest  = "sha256:0000000000000000000000000000000000000000000000000000000000000002"
+	pipeline      = `apiVersion: tekton.dev/v1
+kind: Pipeline
+metadata:
+  name: build
+spec:
+  tasks:
+  - name: floating
+    taskRef:
+      resolver: bundles
+      params:
+      - name: name
+        value: buildah
+      - name: bundle
+        value: registry.io/tasks/buildah:0.1
+  - name: drifted
+    taskRef:
+      resolver: bundles
+      params:
+      - name: bundle
+        value: registry.io/tasks/buildah:0.1@` + pinnedDigest + `
+  - name: pinned
+    taskRef:
+      resolver: bundles
+      params:
+      - name: bundle
+        value: registry.io/tasks/buildah@` + pinnedDigest + `
+  - name: missing
+    taskRef:
+      resolver: bundles
+      params:
+      - name: bundle
+        value: registry.io/tasks/missing:0.1
+  - name: git
+    taskRef:
+      resolver: git
+      params:
+      - name: url
+        value: https://git.io/tasks
+`
+)
+
+func TestResolveBundles(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/pipeline.yaml", []byte(pipeline), 0400))
+	require.NoError(t, afero.WriteFile(fs, "/other.yaml", []byte("kind: Task\n"), 0400))
+
+	client := fake.FakeClient{}
+	client.On("ResolveDigest", name.MustParseReference("registry.io/tasks/buildah:0.1")).Return(currentDigest, nil)
+	client.On("ResolveDigest", name.MustParseReference("registry.io/tasks/missing:0.1")).Return("", errors.New("not found"))
+
+	ctx := utils.WithFS(context.Background(), fs)
+	ctx = oci.WithClient(ctx, &client)
+
+	files, warnings := resolveBundles(ctx, []string{"/pipeline.yaml", "/other.yaml"})
+	require.Len(t, files, 2)
+	assert.NotEqual(t, "/pipeline.yaml", files[0], "the file with bundle references is replaced")
+	assert.Equal(t, "/other.yaml", files[1], "the file without bundle references is kept")
+
+	require.Len(t, warnings, 1)
+	assert.Equal(t, "Unable to resolve the bundle registry.io/tasks/missing:0.1: not found", warnings[0].Message)
+	assert.Equal(t, "builtin.input.bundle_resolution", warnings[0].Metadata["code"])
+
+	content, err := afero.ReadFile(fs, files[0])
+	require.NoError(t, err)
+
+	var doc struct {
+		Spec struct {
+			Tasks []struct {
+				TaskRef map[string]any `json:"taskRef"`
+			} `json:"tasks"`
+		} `json:"spec"`
+	}
+	require.NoError(t, json.Unmarshal(content, &doc))
+
+	resolved := make([]any, 0, len(doc.Spec.Tasks))
+	for _, task := range doc.Spec.Tasks {
+		resolved = append(resolved, task.TaskRef[ResolvedBundleKey])
+	}
+
+	assert.Equal(t, []any{
+		map[string]any{"reference": "registry.io/tasks/buildah:0.1", "tag": "0.1", "digest": currentDigest},
+		map[string]any{"reference": "registry.io/tasks/buildah:0.1@" + pinnedDigest, "tag": "0.1", "pinnedDigest": pinnedDigest, "digest": currentDigest},
+		map[string]any{"reference": "registry.io/tasks/buildah@" + pinnedDigest, "pinnedDigest": pinnedDigest, "digest": pinnedDigest},
+		nil,
+		nil,
+	}, resolved)
+}
+
+func TestResolveBundlesMultipleDocuments(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/pipelines.yaml", []byte("kind: Task\n---\n"+pipeline), 0400))
+
+	client := fake.FakeClient{}
+	client.On("ResolveDigest", name.MustParseReference("registry.io/tasks/buildah:0.1")).Return(currentDigest, nil)
+	client.On("ResolveDigest", name.MustParseReference("registry.io/tasks/missing:0.1")).Return("", errors.New("not found"))
+
+	ctx := utils.WithFS(context.Background(), fs)
+	ctx = oci.WithClient(ctx, &client)
+
+	files, warnings := resolveBundles(ctx, []string{"/pipelines.yaml"})
+	require.Len(t, files, 1)
+	assert.NotEqual(t, "/pipelines.yaml", files[0], "the bundle references of the second document are resolved")
+	require.Len(t, warnings, 1)
+
+	content, err := afero.ReadFile(fs, files[0])
+	require.NoError(t, err)
+
+	var docs []struct {
+		Kind string `json:"kind"`
+		Spec struct {
+			Tasks []struct {
+				TaskRef map[string]any `json:"taskRef"`
+			} `json:"tasks"`
+		} `json:"spec"`
+	}
+	require.NoError(t, json.Unmarshal(content, &docs), "all the documents are kept, as a list")
+	require.Len(t, docs, 2)
+	assert.Equal(t, "Task", docs[0].Kind)
+	assert.Equal(t, map[string]any{"reference": "registry.io/tasks/buildah:0.1", "tag": "0.1", "digest": currentDigest}, docs[1].Spec.Tasks[0].TaskRef[ResolvedBundleKey])
+}
+
+func TestValidateInputResolvingBundles(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/pipeline.yaml", []byte(pipeline), 0400))
+
+	client := fake.FakeClient{}
+	client.On("ResolveDigest", name.MustParseReference("registry.io/tasks/buildah:0.1")).Return(currentDigest, nil)
+	client.On("ResolveDigest", name.MustParseReference("registry.io/tasks/missing:0.1")).Return("", errors.New("not found"))
+
+	ctx := utils.WithFS(context.Background(), fs)
+	ctx = oci.WithClient(ctx, &client)
+
+	inputFile = mockNewPipelineDefinitionFile
+
+	out, err := ValidateInput(ctx, "/pipeline.yaml", nil, false)
+	require.NoError(t, err)
+	assert.Empty(t, out.Warnings(), "no bundles are resolved by default")
+	client.AssertNumberOfCalls(t, "ResolveDigest", 0)
+
+	ctx = WithBundleResolutionOptions(ctx, BundleResolutionOptions{Enabled: true})
+	out, err = ValidateInput(ctx, "/pipeline.yaml", nil, false)
+	require.NoError(t, err)
+	require.Len(t, out.Warnings(), 1)
+	assert.Equal(t, "Unable to resolve the bundle registry.io/tasks/missing:0.1: not found", out.Warnings()[0].Message)
+}
@@ -88,6 +88,8 @@ const (
 	JSON    = "json"
 	YAML    = "yaml"
 	Summary = "summary"
+	// None produces no output, only the exit code of the command is relevant.
+	None = "none"
 )
 
 // WriteReport returns a new instance of Report representing the state of
@@ -122,10 +124,14 @@ func (r Report) WriteAll(targets []string, p format.TargetParser) (allErrors err
 	if len(targets) == 0 {
 		targets = append(targets, JSON)
 	}
-	for _, targetName := range targets {
-		target, err := p.Parse(targetName)
-		if err != nil {
-			allErrors = multierror.Append(allErrors, err)
+
+	parsed, err := p.ParseAll(targets)
+	if err != nil {
+		allErrors = multierror.Append(allErrors, err)
+	}
+
+	for _, target := range parsed {
+		if target.Format == None {
 			continue
 		}
 
@@ -139,7 +145,11 @@ func (r Report) WriteAll(targets []string, p format.TargetParser) (allErrors err
 			data = append(data, "\n"...)
 		}
 
-		if _, err := target.Write(data); err != nil {
+		write := target.Write
+		if target.Format == JSON || target.Format == Summary {
+			write = target.WriteJSON
+		}
+		if _, err := write(data); err != nil {
 			allErrors = multierror.Append(allErrors, err)
 		}
 	}
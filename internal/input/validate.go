@@ -40,6 +40,11 @@ func ValidateInput(ctx context.Context, fpath string, policy policy.Policy, deta
 		return nil, err
 	}
 
+	var warnings []evaluator.Result
+	if bundleResolutionOptions(ctx).Enabled {
+		inputFiles, warnings = resolveBundles(ctx, inputFiles)
+	}
+
 	p, err := inputFile(ctx, inputFiles, policy)
 	if err != nil {
 		log.Debug("Failed to create input!")
@@ -52,6 +57,10 @@ func ValidateInput(ctx context.Context, fpath string, policy policy.Policy, deta
 		return nil, err
 	}
 
+	if len(warnings) > 0 {
+		results = append(results, evaluator.Outcome{FileName: fpath, Warnings: warnings})
+	}
+
 	log.Debug("Conftest policy check complete")
 	return &output.Output{PolicyCheck: results, Detailed: detailed}, nil
 }
@@ -0,0 +1,327 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package opa
+
+import (
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/spf13/afero"
+
+	"github.com/enterprise-contract/ec-cli/internal/opa/rule"
+)
+
+// CoveredPath is a path within the input and the rules referencing it
+type CoveredPath struct {
+	Path  string   `json:"path"`
+	Rules []string `json:"rules"`
+}
+
+// Coverage holds the paths within the input referenced by the deny and warn
+// rules of the policy, directly or through the rules and functions they
+// reference, as determined by statically analyzing the Rego. The variable
+// parts of the paths, e.g. array indexes, are given as [_]. Unreferenced lists
+// the rules referencing no path within the input.
+type Coverage struct {
+	Paths        []CoveredPath `json:"paths"`
+	Unreferenced []string      `json:"unreferenced"`
+}
+
+// InputCoverage analyzes the rego files in the policy directories and returns
+// the coverage of the input by the policy rules.
+func InputCoverage(afs afero.Fs, policyDirs []string) (Coverage, error) {
+	var modules []*ast.Module
+	for _, dir := range policyDirs {
+		paths, contents, err := readRegoFiles(afs, dir)
+		if err != nil {
+			return Coverage{}, err
+		}
+
+		for i := range paths {
+			mod, err := ast.ParseModuleWithOpts(paths[i], contents[i], ast.ParserOptions{ProcessAnnotation: true})
+			if err != nil {
+				return Coverage{}, err
+			}
+			modules = append(modules, mod)
+		}
+	}
+
+	return newCoverage(modules), nil
+}
+
+func newCoverage(modules []*ast.Module) Coverage {
+	// The input paths and the references of all rules with the same path,
+	// e.g. of the definitions of a function
+	var rules []ast.Ref
+	direct := map[string]map[string]bool{}
+	references := map[string][]ast.Ref{}
+	for _, mod := range modules {
+		for _, r := range mod.Rules {
+			path := r.Path()
+			key := path.String()
+			if _, ok := direct[key]; !ok {
+				rules = append(rules, path)
+				direct[key] = map[string]bool{}
+			}
+			for p := range inputReferences(mod, r) {
+				direct[key][p] = true
+			}
+			references[key] = append(references[key], ruleReferences(mod, r)...)
+		}
+	}
+
+	// referenced returns the input paths referenced by the rules the
+	// references are to, and by the rules those reference in turn
+	var referenced func(from ast.Ref, refs []ast.Ref, paths map[string]bool, seen map[string]bool)
+	referenced = func(from ast.Ref, refs []ast.Ref, paths map[string]bool, seen map[string]bool) {
+		for _, ref := range refs {
+			to := ref.StringPrefix()
+			for _, r := range rules {
+				key := r.String()
+				if r.Equal(from) || seen[key] || !(to.HasPrefix(r) || r.HasPrefix(to)) {
+					continue
+				}
+				seen[key] = true
+				for p := range direct[key] {
+					paths[p] = true
+				}
+				referenced(r, references[key], paths, seen)
+			}
+		}
+	}
+
+	covered := map[string]map[string]bool{}
+	unreferenced := map[string]bool{}
+	for _, mod := range modules {
+		for _, r := range mod.Rules {
+			name := r.Head.Ref()[0].String()
+			if !isFailure(name) && !isWarning(name) {
+				continue
+			}
+
+			paths := inputReferences(mod, r)
+			referenced(r.Path(), ruleReferences(mod, r), paths, map[string]bool{})
+
+			ruleName := coverageRuleName(r)
+			if len(paths) == 0 {
+				unreferenced[ruleName] = true
+				continue
+			}
+			for p := range mostSpecific(paths) {
+				if covered[p] == nil {
+					covered[p] = map[string]bool{}
+				}
+				covered[p][ruleName] = true
+			}
+		}
+	}
+
+	c := Coverage{Paths: []CoveredPath{}, Unreferenced: sortedKeys(unreferenced)}
+	for p, rules := range covered {
+		c.Paths = append(c.Paths, CoveredPath{Path: p, Rules: sortedKeys(rules)})
+	}
+	sort.Slice(c.Paths, func(i, j int) bool {
+		return c.Paths[i].Path < c.Paths[j].Path
+	})
+
+	return c
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// coverageRuleName returns the code of the rule, or its path if the rule has
+// no short name
+func coverageRuleName(r *ast.Rule) string {
+	for _, a := range r.Annotations {
+		if info := rule.RuleInfo(&ast.AnnotationsRef{Path: r.Path(), Annotations: a}); info.ShortName != "" {
+			return info.Code
+		}
+	}
+
+	return r.Path().String()
+}
+
+// inputReferences returns the paths within the input referenced by the rule,
+// following the variables bound to paths within the input, e.g. by iterating
+// over them.
+func inputReferences(mod *ast.Module, r *ast.Rule) map[string]bool {
+	imports := map[ast.Var]ast.Ref{}
+	for _, imp := range mod.Imports {
+		path, ok := imp.Path.Value.(ast.Ref)
+		if !ok || !path.HasPrefix(ast.InputRootRef) {
+			continue
+		}
+		alias := imp.Alias
+		if alias == "" {
+			alias = ast.Var(strings.Trim(path[len(path)-1].Value.String(), `"`))
+		}
+		imports[alias] = path
+	}
+
+	bindings := map[ast.Var]ast.Ref{}
+	resolve := func(t *ast.Term) (ast.Ref, bool) {
+		var ref ast.Ref
+		switch v := t.Value.(type) {
+		case ast.Var:
+			ref = ast.Ref{t}
+		case ast.Ref:
+			ref = v
+		default:
+			return nil, false
+		}
+
+		head, ok := ref[0].Value.(ast.Var)
+		if !ok {
+			return nil, false
+		}
+
+		var base ast.Ref
+		switch {
+		case head.Equal(ast.InputRootDocument.Value):
+			base = ast.InputRootRef
+		case bindings[head] != nil:
+			base = bindings[head]
+		case imports[head] != nil:
+			base = imports[head]
+		default:
+			return nil, false
+		}
+
+		path := base.Copy()
+		for _, t := range ref[1:] {
+			if _, ok := t.Value.(ast.String); ok {
+				path = path.Append(t)
+			} else {
+				path = path.Append(ast.VarTerm(ast.Wildcard.Value.String()))
+			}
+		}
+
+		return path, true
+	}
+
+	bind := func(x *ast.Expr) bool {
+		switch t := x.Terms.(type) {
+		case *ast.SomeDecl:
+			// some v in xs, or some k, v in xs
+			for _, s := range t.Symbols {
+				call, ok := s.Value.(ast.Call)
+				if !ok || len(call) < 3 {
+					continue
+				}
+				if path, ok := resolve(call[len(call)-1]); ok {
+					if v, ok := call[len(call)-2].Value.(ast.Var); ok {
+						bindings[v] = path.Append(ast.VarTerm(ast.Wildcard.Value.String()))
+					}
+				}
+			}
+		case *ast.Every:
+			if path, ok := resolve(t.Domain); ok {
+				if v, ok := t.Value.Value.(ast.Var); ok {
+					bindings[v] = path.Append(ast.VarTerm(ast.Wildcard.Value.String()))
+				}
+			}
+		default:
+			if x.IsAssignment() || x.IsEquality() {
+				operands := x.Operands()
+				if v, ok := operands[0].Value.(ast.Var); ok {
+					if path, ok := resolve(operands[1]); ok {
+						bindings[v] = path
+					}
+				}
+			}
+		}
+
+		return false
+	}
+
+	// Twice, so that the variables bound to other variables bound later in
+	// the rule are followed too
+	ast.WalkExprs(r, bind)
+	ast.WalkExprs(r, bind)
+
+	paths := map[string]bool{}
+	var visit func(t *ast.Term) bool
+	visit = func(t *ast.Term) bool {
+		if path, ok := resolve(t); ok {
+			paths[path.String()] = true
+		}
+
+		if ref, isRef := t.Value.(ast.Ref); isRef {
+			// the terms within the brackets might reference the input too
+			for _, e := range ref[1:] {
+				ast.WalkTerms(e, visit)
+			}
+			return true
+		}
+
+		return false
+	}
+	ast.WalkTerms(r, visit)
+
+	return paths
+}
+
+// mostSpecific returns the paths that are not a prefix of another path
+func mostSpecific(paths map[string]bool) map[string]bool {
+	specific := map[string]bool{}
+	for p := range paths {
+		prefix := false
+		for o := range paths {
+			if o != p && (strings.HasPrefix(o, p+".") || strings.HasPrefix(o, p+"[")) {
+				prefix = true
+				break
+			}
+		}
+		if !prefix {
+			specific[p] = true
+		}
+	}
+
+	return specific
+}
+
+// WriteText writes the covered paths, each followed by the rules referencing
+// it, and the rules referencing no path within the input.
+func (c Coverage) WriteText(w io.Writer) error {
+	var b strings.Builder
+	for _, p := range c.Paths {
+		b.WriteString(p.Path + "\n")
+		for _, r := range p.Rules {
+			b.WriteString("  " + r + "\n")
+		}
+	}
+
+	if len(c.Unreferenced) > 0 {
+		b.WriteString("Rules referencing no input:\n")
+		for _, r := range c.Unreferenced {
+			b.WriteString("  " + r + "\n")
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,114 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package opa
+
+import (
+	"bytes"
+	"testing"
+
+	hd "github.com/MakeNowJust/heredoc"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInputCoverage(t *testing.T) {
+	afs := afero.NewMemMapFs()
+
+	require.NoError(t, afero.WriteFile(afs, "/policy/release/provenance.rego", []byte(hd.Doc(`
+		package release.provenance
+
+		import rego.v1
+
+		import data.lib
+
+		# METADATA
+		# title: Build type
+		# custom:
+		#   short_name: build_type
+		deny contains result if {
+			some att in lib.attestations
+			att.statement.predicate.buildType != "tekton"
+			result := lib.result("wrong build type")
+		}
+
+		# METADATA
+		# title: Image reference
+		# custom:
+		#   short_name: image_ref
+		warn contains result if {
+			ref := input.image.ref
+			not startswith(ref, "registry.io/")
+			every label in input.image.config.Labels {
+				label != ""
+			}
+			result := lib.result("unexpected registry")
+		}
+
+		# METADATA
+		# title: Always
+		# custom:
+		#   short_name: always
+		deny contains result if {
+			false
+			result := lib.result("never")
+		}
+	`)), 0644))
+	require.NoError(t, afero.WriteFile(afs, "/policy/lib/lib.rego", []byte(hd.Doc(`
+		package lib
+
+		import rego.v1
+
+		attestations := [att | some att in input.attestations]
+
+		result(msg) := {"msg": msg}
+	`)), 0644))
+	require.NoError(t, afero.WriteFile(afs, "/policy/lib/lib_test.rego", []byte(hd.Doc(`
+		package lib_test
+
+		test_result if {
+			input.ignored
+		}
+	`)), 0644))
+
+	c, err := InputCoverage(afs, []string{"/policy"})
+	require.NoError(t, err)
+
+	assert.Equal(t, Coverage{
+		Paths: []CoveredPath{
+			{Path: "input.attestations[_]", Rules: []string{"provenance.build_type"}},
+			{Path: "input.image.config.Labels[_]", Rules: []string{"provenance.image_ref"}},
+			{Path: "input.image.ref", Rules: []string{"provenance.image_ref"}},
+		},
+		Unreferenced: []string{"provenance.always"},
+	}, c)
+
+	var text bytes.Buffer
+	require.NoError(t, c.WriteText(&text))
+	assert.Equal(t, hd.Doc(`
+		input.attestations[_]
+		  provenance.build_type
+		input.image.config.Labels[_]
+		  provenance.image_ref
+		input.image.ref
+		  provenance.image_ref
+		Rules referencing no input:
+		  provenance.always
+	`), text.String())
+}
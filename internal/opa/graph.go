@@ -0,0 +1,309 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package opa
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/open-policy-agent/opa/ast"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"sigs.k8s.io/yaml"
+)
+
+// ReferenceKind is the kind of the document referenced by a rule
+type ReferenceKind string
+
+const (
+	RuleReference ReferenceKind = "rule"
+	DataReference ReferenceKind = "data"
+)
+
+// Reference is a reference of a rule to another rule or to data
+type Reference struct {
+	From string        `json:"from"`
+	To   string        `json:"to"`
+	Kind ReferenceKind `json:"kind"`
+}
+
+// Graph holds the references of the policy rules to other rules and to data,
+// as determined by statically analyzing the Rego. UnusedData lists the keys of
+// the data documents no rule references.
+type Graph struct {
+	Rules      []string    `json:"rules"`
+	Data       []string    `json:"data"`
+	References []Reference `json:"references"`
+	UnusedData []string    `json:"unusedData"`
+}
+
+// PolicyGraph analyzes the rego files in the policy directories, and the data
+// files in the data directories, and returns the graph of the references of
+// the rules.
+func PolicyGraph(afs afero.Fs, policyDirs, dataDirs []string) (Graph, error) {
+	var modules []*ast.Module
+	for _, dir := range policyDirs {
+		paths, contents, err := readRegoFiles(afs, dir)
+		if err != nil {
+			return Graph{}, err
+		}
+
+		for i := range paths {
+			mod, err := ast.ParseModule(paths[i], contents[i])
+			if err != nil {
+				return Graph{}, err
+			}
+			modules = append(modules, mod)
+		}
+	}
+
+	var dataKeys []ast.Ref
+	for _, dir := range dataDirs {
+		keys, err := readDataKeys(afs, dir)
+		if err != nil {
+			return Graph{}, err
+		}
+		dataKeys = append(dataKeys, keys...)
+	}
+
+	return newGraph(modules, dataKeys), nil
+}
+
+func newGraph(modules []*ast.Module, dataKeys []ast.Ref) Graph {
+	var rules []ast.Ref
+	for _, mod := range modules {
+		for _, r := range mod.Rules {
+			if path := r.Path(); !slices.ContainsFunc(rules, func(rule ast.Ref) bool { return rule.Equal(path) }) {
+				rules = append(rules, path)
+			}
+		}
+	}
+
+	references := map[Reference]bool{}
+	referencedData := map[string]ast.Ref{}
+	for _, mod := range modules {
+		for _, r := range mod.Rules {
+			from := r.Path()
+			for _, ref := range ruleReferences(mod, r) {
+				to := ref.StringPrefix()
+
+				// A reference to a rule, or to a package, i.e. to all its rules
+				referencedRules := 0
+				for _, rule := range rules {
+					if (to.HasPrefix(rule) || rule.HasPrefix(to)) && !rule.Equal(from) {
+						references[Reference{From: from.String(), To: rule.String(), Kind: RuleReference}] = true
+						referencedRules++
+					}
+				}
+				if referencedRules > 0 || slices.ContainsFunc(rules, func(rule ast.Ref) bool { return to.HasPrefix(rule) }) {
+					continue
+				}
+
+				references[Reference{From: from.String(), To: to.String(), Kind: DataReference}] = true
+				referencedData[to.String()] = to
+			}
+		}
+	}
+
+	g := Graph{Rules: []string{}, Data: []string{}, References: []Reference{}, UnusedData: []string{}}
+	for _, r := range rules {
+		g.Rules = append(g.Rules, r.String())
+	}
+	for ref := range references {
+		g.References = append(g.References, ref)
+	}
+	for d := range referencedData {
+		g.Data = append(g.Data, d)
+	}
+
+	for _, key := range dataKeys {
+		used := false
+		for _, d := range referencedData {
+			if d.HasPrefix(key) || key.HasPrefix(d) {
+				used = true
+				break
+			}
+		}
+		if !used && !slices.Contains(g.UnusedData, key.String()) {
+			g.UnusedData = append(g.UnusedData, key.String())
+			g.Data = append(g.Data, key.String())
+		}
+	}
+
+	sort.Strings(g.Rules)
+	sort.Strings(g.Data)
+	sort.Strings(g.UnusedData)
+	sort.Slice(g.References, func(i, j int) bool {
+		if g.References[i].From != g.References[j].From {
+			return g.References[i].From < g.References[j].From
+		}
+		return g.References[i].To < g.References[j].To
+	})
+
+	return g
+}
+
+// ruleReferences returns the references to data within the rule, with the
+// references to imports and to rules of the same package resolved.
+func ruleReferences(mod *ast.Module, r *ast.Rule) []ast.Ref {
+	imports := map[ast.Var]ast.Ref{}
+	for _, imp := range mod.Imports {
+		path, ok := imp.Path.Value.(ast.Ref)
+		if !ok || !path.HasPrefix(ast.DefaultRootRef) {
+			continue
+		}
+		alias := imp.Alias
+		if alias == "" {
+			alias = ast.Var(strings.Trim(path[len(path)-1].Value.String(), `"`))
+		}
+		imports[alias] = path
+	}
+
+	local := map[ast.Var]bool{}
+	for _, rule := range mod.Rules {
+		if v, ok := rule.Head.Ref()[0].Value.(ast.Var); ok {
+			local[v] = true
+		}
+	}
+
+	var refs []ast.Ref
+	visit := func(t *ast.Term) bool {
+		var ref ast.Ref
+		switch v := t.Value.(type) {
+		case ast.Ref:
+			ref = v
+		case ast.Var:
+			// A rule of the same package referenced by its name alone, the
+			// heads of the references are visited as variables too
+			if local[v] {
+				refs = append(refs, mod.Package.Path.Append(t))
+			}
+			return false
+		default:
+			return false
+		}
+
+		head, ok := ref[0].Value.(ast.Var)
+		if !ok {
+			return false
+		}
+
+		switch {
+		case head.Equal(ast.DefaultRootDocument.Value):
+			refs = append(refs, ref)
+		case imports[head] != nil:
+			refs = append(refs, imports[head].Concat(ref[1:]))
+		case local[head]:
+			refs = append(refs, mod.Package.Path.Concat(ref))
+		}
+
+		return false
+	}
+
+	for ; r != nil; r = r.Else {
+		ast.WalkTerms(r.Body, visit)
+		if r.Head.Key != nil {
+			ast.WalkTerms(r.Head.Key, visit)
+		}
+		if r.Head.Value != nil {
+			ast.WalkTerms(r.Head.Value, visit)
+		}
+	}
+
+	return refs
+}
+
+// readDataKeys returns the keys of the data documents in the JSON and YAML
+// files within the directory, the top level keys and the keys nested within
+// them.
+func readDataKeys(afs afero.Fs, dir string) ([]ast.Ref, error) {
+	var keys []ast.Ref
+	err := fs.WalkDir(wrapperFs{afs: afs}, dir, func(path string, d fs.DirEntry, readErr error) error {
+		if readErr != nil {
+			return readErr
+		}
+
+		if d.IsDir() {
+			return nil
+		}
+
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".json", ".yaml", ".yml":
+		default:
+			return nil
+		}
+
+		contents, err := afero.ReadFile(afs, path)
+		if err != nil {
+			return err
+		}
+
+		var data map[string]any
+		if err := yaml.Unmarshal(contents, &data); err != nil {
+			log.Debugf("Ignoring the data file %s: %s", path, err)
+			return nil
+		}
+
+		for k, v := range data {
+			key := ast.DefaultRootRef.Append(ast.StringTerm(k))
+			nested, ok := v.(map[string]any)
+			if !ok || len(nested) == 0 {
+				keys = append(keys, key)
+				continue
+			}
+			for n := range nested {
+				keys = append(keys, key.Append(ast.StringTerm(n)))
+			}
+		}
+
+		return nil
+	})
+
+	return keys, err
+}
+
+// WriteDOT writes the graph in the Graphviz DOT format. The data documents are
+// drawn as boxes, the unused ones in red.
+func (g Graph) WriteDOT(w io.Writer) error {
+	lines := []string{"digraph policy {", "  rankdir=LR;"}
+	for _, r := range g.Rules {
+		lines = append(lines, fmt.Sprintf("  %q;", r))
+	}
+	for _, d := range g.Data {
+		if slices.Contains(g.UnusedData, d) {
+			lines = append(lines, fmt.Sprintf("  %q [shape=box, color=red, label=%q];", d, d+" (unused)"))
+		} else {
+			lines = append(lines, fmt.Sprintf("  %q [shape=box];", d))
+		}
+	}
+	for _, r := range g.References {
+		if r.Kind == DataReference {
+			lines = append(lines, fmt.Sprintf("  %q -> %q [style=dashed];", r.From, r.To))
+		} else {
+			lines = append(lines, fmt.Sprintf("  %q -> %q;", r.From, r.To))
+		}
+	}
+	lines = append(lines, "}")
+
+	_, err := fmt.Fprintln(w, strings.Join(lines, "\n"))
+	return err
+}
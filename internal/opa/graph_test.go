@@ -0,0 +1,118 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package opa
+
+import (
+	"bytes"
+	"testing"
+
+	hd "github.com/MakeNowJust/heredoc"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicyGraph(t *testing.T) {
+	afs := afero.NewMemMapFs()
+
+	require.NoError(t, afero.WriteFile(afs, "/policy/release/registry.rego", []byte(hd.Doc(`
+		package release.registry
+
+		import rego.v1
+
+		import data.lib
+
+		deny contains result if {
+			not allowed
+			result := lib.result("not allowed")
+		}
+
+		allowed if {
+			some prefix in data.rule_data.allowed_registry_prefixes
+			startswith(input.image.ref, prefix)
+		}
+	`)), 0644))
+	require.NoError(t, afero.WriteFile(afs, "/policy/lib/lib.rego", []byte(hd.Doc(`
+		package lib
+
+		import rego.v1
+
+		result(msg) := {"msg": msg, "effective_on": data.config.policy.when_ns}
+	`)), 0644))
+	require.NoError(t, afero.WriteFile(afs, "/policy/lib/lib_test.rego", []byte(hd.Doc(`
+		package lib_test
+
+		test_result if {
+			data.lib.result("x")
+		}
+	`)), 0644))
+	require.NoError(t, afero.WriteFile(afs, "/data/rule_data.yml", []byte(hd.Doc(`
+		rule_data:
+		  allowed_registry_prefixes:
+		    - registry.io/
+		  disallowed_packages: []
+		unused: true
+	`)), 0644))
+
+	g, err := PolicyGraph(afs, []string{"/policy"}, []string{"/data"})
+	require.NoError(t, err)
+
+	assert.Equal(t, Graph{
+		Rules: []string{
+			"data.lib.result",
+			"data.release.registry.allowed",
+			"data.release.registry.deny",
+		},
+		Data: []string{
+			"data.config.policy.when_ns",
+			"data.rule_data.allowed_registry_prefixes",
+			"data.rule_data.disallowed_packages",
+			"data.unused",
+		},
+		References: []Reference{
+			{From: "data.lib.result", To: "data.config.policy.when_ns", Kind: DataReference},
+			{From: "data.release.registry.allowed", To: "data.rule_data.allowed_registry_prefixes", Kind: DataReference},
+			{From: "data.release.registry.deny", To: "data.lib.result", Kind: RuleReference},
+			{From: "data.release.registry.deny", To: "data.release.registry.allowed", Kind: RuleReference},
+		},
+		UnusedData: []string{
+			"data.rule_data.disallowed_packages",
+			"data.unused",
+		},
+	}, g)
+
+	var dot bytes.Buffer
+	require.NoError(t, g.WriteDOT(&dot))
+	assert.Equal(t, hd.Doc(`
+		digraph policy {
+		  rankdir=LR;
+		  "data.lib.result";
+		  "data.release.registry.allowed";
+		  "data.release.registry.deny";
+		  "data.config.policy.when_ns" [shape=box];
+		  "data.rule_data.allowed_registry_prefixes" [shape=box];
+		  "data.rule_data.disallowed_packages" [shape=box, color=red, label="data.rule_data.disallowed_packages (unused)"];
+		  "data.unused" [shape=box, color=red, label="data.unused (unused)"];
+		  "data.lib.result" -> "data.config.policy.when_ns" [style=dashed];
+		  "data.release.registry.allowed" -> "data.rule_data.allowed_registry_prefixes" [style=dashed];
+		  "data.release.registry.deny" -> "data.lib.result";
+		  "data.release.registry.deny" -> "data.release.registry.allowed";
+		}
+	`), dot.String())
+}
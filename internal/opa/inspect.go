@@ -159,6 +159,34 @@ func checkRules(rules []*ast.AnnotationsRef) error {
 
 // Finds all the rego files, inspects each one and returns a list the inspect data
 func InspectDir(afs afero.Fs, dir string) ([]*ast.AnnotationsRef, error) {
+	regoPaths, regoContents, err := readRegoFiles(afs, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	// Inspect all rego files found
+	allAnnotations, err := inspectMultiple(regoPaths, regoContents)
+	if err != nil {
+		return nil, err
+	}
+
+	// Return only interesting rules
+	result, err := interestingRulesOnly(allAnnotations)
+	if err != nil {
+		return nil, err
+	}
+
+	// check for conformance
+	if err := checkRules(result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// readRegoFiles finds all the rego files, other than tests, in the directory
+// and returns their paths, relative to the directory, and their contents
+func readRegoFiles(afs afero.Fs, dir string) ([]string, []string, error) {
 	regoPaths := []string{}
 	regoContents := []string{}
 
@@ -196,33 +224,16 @@ func InspectDir(afs afero.Fs, dir string) ([]*ast.AnnotationsRef, error) {
 		return nil
 	})
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// Ensure that we have actual rules, and a directory without rego files.
 	if len(regoPaths) == 0 {
 		log.Debug("No rego files found after cloning policy url.")
-		return nil, errors.New("no rego files found in policy subdirectory")
+		return nil, nil, errors.New("no rego files found in policy subdirectory")
 	}
 
-	// Inspect all rego files found
-	allAnnotations, err := inspectMultiple(regoPaths, regoContents)
-	if err != nil {
-		return nil, err
-	}
-
-	// Return only interesting rules
-	result, err := interestingRulesOnly(allAnnotations)
-	if err != nil {
-		return nil, err
-	}
-
-	// check for conformance
-	if err := checkRules(result); err != nil {
-		return nil, err
-	}
-
-	return result, nil
+	return regoPaths, regoContents, nil
 }
 
 // wrapperFs turns afero.Fs into fs.FS so it can be used in certain functions
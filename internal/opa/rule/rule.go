@@ -58,6 +58,14 @@ func effectiveOn(a *ast.AnnotationsRef) string {
 	return customAnnotationString(a, "effective_on")
 }
 
+func enforceAfter(a *ast.AnnotationsRef) string {
+	return customAnnotationString(a, "enforce_after")
+}
+
+func severity(a *ast.AnnotationsRef) string {
+	return strings.ToLower(customAnnotationString(a, "severity"))
+}
+
 func solution(a *ast.AnnotationsRef) string {
 	return xrefRegExp.ReplaceAllString(customAnnotationString(a, "solution"), "$1")
 }
@@ -262,8 +270,10 @@ type Info struct {
 	Description      string
 	DocumentationUrl string
 	EffectiveOn      string
+	EnforceAfter     string
 	Kind             RuleKind
 	Package          string
+	Severity         string
 	ShortName        string
 	Solution         string
 	Title            string
@@ -278,9 +288,11 @@ func RuleInfo(a *ast.AnnotationsRef) Info {
 		DependsOn:        dependsOn(a),
 		DocumentationUrl: documentationUrl(a),
 		EffectiveOn:      effectiveOn(a),
+		EnforceAfter:     enforceAfter(a),
 		Solution:         solution(a),
 		Kind:             kind(a),
 		Package:          packageName(a),
+		Severity:         severity(a),
 		ShortName:        shortName(a),
 		Title:            title(a),
 	}
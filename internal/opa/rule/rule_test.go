@@ -302,6 +302,16 @@ func TestEffectiveOn(t *testing.T) {
 	}
 }
 
+func TestEnforceAfter(t *testing.T) {
+	assert.Equal(t, "", enforceAfter(nil))
+	assert.Equal(t, "2024-07-01", enforceAfter(annotationRef(heredoc.Doc(`
+		package a
+		# METADATA
+		# custom:
+		#   enforce_after: '2024-07-01'
+		deny() { true }`))))
+}
+
 func TestSolution(t *testing.T) {
 	cases := []struct {
 		name       string
@@ -341,6 +351,40 @@ func TestSolution(t *testing.T) {
 	}
 }
 
+func TestSeverity(t *testing.T) {
+	cases := []struct {
+		name       string
+		annotation *ast.AnnotationsRef
+		expected   string
+	}{
+		{
+			name: "without severity",
+			annotation: annotationRef(heredoc.Doc(`
+				package a
+				# METADATA
+				# title: title
+				deny() { true }`)),
+			expected: "",
+		},
+		{
+			name: "with severity",
+			annotation: annotationRef(heredoc.Doc(`
+				package a
+				# METADATA
+				# custom:
+				#   severity: Critical
+				deny() { true }`)),
+			expected: "critical",
+		},
+	}
+
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("[%d] - %s", i, c.name), func(t *testing.T) {
+			assert.Equal(t, c.expected, severity(c.annotation))
+		})
+	}
+}
+
 func TestCollections(t *testing.T) {
 	cases := []struct {
 		name       string
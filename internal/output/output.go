@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"io"
 	"sort"
+	"time"
 
 	"github.com/sigstore/cosign/v2/pkg/cosign"
 	log "github.com/sirupsen/logrus"
@@ -29,6 +30,7 @@ import (
 	"github.com/enterprise-contract/ec-cli/internal/evaluator"
 	"github.com/enterprise-contract/ec-cli/internal/policy"
 	"github.com/enterprise-contract/ec-cli/internal/signature"
+	"github.com/enterprise-contract/ec-cli/internal/utils/oci"
 )
 
 const missingSignatureMessage = "No image signatures found matching the given public key. " +
@@ -79,6 +81,20 @@ type Output struct {
 	ImageSignatureCheck       VerificationStatus          `json:"imageSignatureCheck"`
 	AttestationSignatureCheck VerificationStatus          `json:"attestationSignatureCheck"`
 	AttestationSyntaxCheck    VerificationStatus          `json:"attestationSyntaxCheck"`
+	SLSALevelCheck            *VerificationStatus         `json:"slsaLevelCheck,omitempty"`
+	MediaTypeCheck            *VerificationStatus         `json:"mediaTypeCheck,omitempty"`
+	BaseImageCheck            *VerificationStatus         `json:"baseImageCheck,omitempty"`
+	BuilderIDCheck            *VerificationStatus         `json:"builderIdCheck,omitempty"`
+	RekorTimeCheck            *VerificationStatus         `json:"rekorTimeCheck,omitempty"`
+	SBOMConsistencyCheck      *VerificationStatus         `json:"sbomConsistencyCheck,omitempty"`
+	SigningKeyCheck           *VerificationStatus         `json:"signingKeyCheck,omitempty"`
+	RequiredLabelCheck        *VerificationStatus         `json:"requiredLabelCheck,omitempty"`
+	PinnedDigestCheck         *VerificationStatus         `json:"pinnedDigestCheck,omitempty"`
+	AttestationConflictCheck  *VerificationStatus         `json:"attestationConflictCheck,omitempty"`
+	OperatorDigestCheck       *VerificationStatus         `json:"operatorDigestCheck,omitempty"`
+	ImageAgeCheck             *VerificationStatus         `json:"imageAgeCheck,omitempty"`
+	InputSizeCheck            *VerificationStatus         `json:"inputSizeCheck,omitempty"`
+	SubjectConsistencyCheck   *VerificationStatus         `json:"subjectConsistencyCheck,omitempty"`
 	PolicyCheck               []evaluator.Outcome         `json:"policyCheck"`
 	ExitCode                  int                         `json:"-"`
 	Signatures                []signature.EntitySignature `json:"signatures,omitempty"`
@@ -88,6 +104,50 @@ type Output struct {
 	Data                      []evaluator.Data            `json:"-"`
 	Policy                    policy.Policy               `json:"-"`
 	PolicyInput               []byte                      `json:"-"`
+	Verification              Verification                `json:"-"`
+	SLSALevel                 *int                        `json:"-"`
+	BaseImage                 string                      `json:"-"`
+	BuilderIDs                []string                    `json:"-"`
+	RekorIntegratedTime       *time.Time                  `json:"-"`
+	BuildFinishedOn           *time.Time                  `json:"-"`
+	SigningKeys               []signature.SigningKey      `json:"-"`
+	SignerIdentities          []signature.Identity        `json:"-"`
+	SigningTimes              []signature.SigningTime     `json:"-"`
+	MissingLabels             []string                    `json:"-"`
+	DigestPin                 *DigestPin                  `json:"-"`
+	AttestationConflicts      []AttestationConflict       `json:"-"`
+	OperatorDecision          string                      `json:"-"`
+	ImageCreated              *time.Time                  `json:"-"`
+	PolicyInputSize           *int64                      `json:"-"`
+	SubjectDigests            []string                    `json:"-"`
+	// AttestationsPresent is set when attestations are optional, telling if
+	// the image has any attestations
+	AttestationsPresent *bool `json:"-"`
+}
+
+const (
+	// OperatorDenied is the decision of the operator to fail an image
+	// regardless of the policy
+	OperatorDenied = "denied"
+	// OperatorAllowed is the decision of the operator to pass an image
+	// regardless of the policy
+	OperatorAllowed = "allowed"
+)
+
+// AttestationConflict is a key field of the predicate with different values in
+// the verified attestations of the same predicate type.
+type AttestationConflict struct {
+	PredicateType string   `json:"predicateType"`
+	Field         string   `json:"field"`
+	Values        []string `json:"values"`
+}
+
+// DigestPin is the digest the tag of an image was pinned to, and the digest
+// the tag currently resolves to.
+type DigestPin struct {
+	Tag      string `json:"tag"`
+	Expected string `json:"expected"`
+	Actual   string `json:"actual,omitempty"`
 }
 
 // SetImageAccessibleCheck sets the passed and result.message fields of the ImageAccessibleCheck to the given values.
@@ -112,6 +172,7 @@ func (o *Output) SetImageAccessibleCheckFromError(err error) {
 		keepSomeMetadataSingle(*result)
 	}
 	o.ImageAccessibleCheck.Result = result
+	o.Verification.ImageAccessible = newStage(err, ImageInaccessible)
 }
 
 // SetImageSignatureCheck sets the passed and result.message fields of the ImageSignatureCheck to the given values.
@@ -137,6 +198,7 @@ func (o *Output) SetImageSignatureCheckFromError(err error) {
 		keepSomeMetadataSingle(*result)
 	}
 	o.ImageSignatureCheck.Result = result
+	o.Verification.ImageSignature = newStage(err, signatureCategory(err))
 }
 
 // SetAttestationSignatureCheck sets the passed and result.message fields of the AttestationSignatureCheck to the given values.
@@ -162,6 +224,27 @@ func (o *Output) SetAttestationSignatureCheckFromError(err error) {
 		keepSomeMetadataSingle(*result)
 	}
 	o.AttestationSignatureCheck.Result = result
+	o.Verification.AttestationSignature = newStage(err, attestationCategory(err))
+}
+
+// SetMissingAttestationsAllowed passes the AttestationSignatureCheck of an
+// image without attestations when attestations are optional.
+func (o *Output) SetMissingAttestationsAllowed() {
+	metadata := map[string]interface{}{
+		"code":        "builtin.attestation.signature_check",
+		"title":       "Attestation signature check passed",
+		"description": "The image has no attestations, allowed as attestations are optional.",
+	}
+	message := "No attestations found, allowed as attestations are optional"
+	log.Debug(message)
+
+	o.AttestationSignatureCheck.Passed = true
+	result := &evaluator.Result{Message: message, Metadata: metadata}
+	if !o.Detailed {
+		keepSomeMetadataSingle(*result)
+	}
+	o.AttestationSignatureCheck.Result = result
+	o.Verification.AttestationSignature = Stage{Status: StageSkipped, Category: MissingAttestation}
 }
 
 // SetAttestationSyntaxCheck sets the passed and result.message fields of the AttestationSyntaxCheck to the given values.
@@ -187,6 +270,411 @@ func (o *Output) SetAttestationSyntaxCheckFromError(err error) {
 		keepSomeMetadataSingle(*result)
 	}
 	o.AttestationSyntaxCheck.Result = result
+	o.Verification.AttestationSyntax = newStage(err, InvalidAttestationSyntax)
+}
+
+// SetSLSALevelCheckFromError records the determined SLSA level and sets the
+// passed and result.message fields of the SLSALevelCheck to the given values.
+func (o *Output) SetSLSALevelCheckFromError(level int, err error) {
+	metadata := map[string]interface{}{
+		"code":        "builtin.attestation.slsa_level",
+		"title":       "SLSA level check passed",
+		"description": "The SLSA Provenance meets the minimum SLSA level.",
+	}
+	var message string
+
+	check := &VerificationStatus{}
+	if err == nil {
+		check.Passed = true
+		message = "Pass"
+		log.Debug("SLSA level check passed")
+	} else {
+		message = fmt.Sprintf("SLSA level check failed: %s", err)
+		log.Debug(message)
+	}
+	result := &evaluator.Result{Message: message, Metadata: metadata}
+	if !o.Detailed {
+		keepSomeMetadataSingle(*result)
+	}
+	check.Result = result
+	o.SLSALevelCheck = check
+	o.SLSALevel = &level
+}
+
+// SetMediaTypeCheckFromError sets the passed and result.message fields of the
+// MediaTypeCheck to the given values.
+func (o *Output) SetMediaTypeCheckFromError(err error) {
+	metadata := map[string]interface{}{
+		"code":        "builtin.image.media_type",
+		"title":       "Media type check passed",
+		"description": "The media types of the image manifest, config and layers are allowed.",
+	}
+	var message string
+
+	check := &VerificationStatus{}
+	if err == nil {
+		check.Passed = true
+		message = "Pass"
+		log.Debug("Media type check passed")
+	} else {
+		message = fmt.Sprintf("Media type check failed: %s", err)
+		log.Debug(message)
+	}
+	result := &evaluator.Result{Message: message, Metadata: metadata}
+	if !o.Detailed {
+		keepSomeMetadataSingle(*result)
+	}
+	check.Result = result
+	o.MediaTypeCheck = check
+}
+
+// SetBaseImageCheckFromError records the detected base image and sets the
+// passed and result.message fields of the BaseImageCheck to the given values.
+func (o *Output) SetBaseImageCheckFromError(base string, err error) {
+	metadata := map[string]interface{}{
+		"code":        "builtin.image.base_image",
+		"title":       "Base image check passed",
+		"description": "The image is built from an allowed base image.",
+	}
+	var message string
+
+	check := &VerificationStatus{}
+	if err == nil {
+		check.Passed = true
+		message = "Pass"
+		log.Debug("Base image check passed")
+	} else {
+		message = fmt.Sprintf("Base image check failed: %s", err)
+		log.Debug(message)
+	}
+	result := &evaluator.Result{Message: message, Metadata: metadata}
+	if !o.Detailed {
+		keepSomeMetadataSingle(*result)
+	}
+	check.Result = result
+	o.BaseImageCheck = check
+	o.BaseImage = base
+}
+
+// SetBuilderIDCheckFromError records the builder IDs found in the SLSA
+// Provenance and sets the passed and result.message fields of the
+// BuilderIDCheck to the given values.
+func (o *Output) SetBuilderIDCheckFromError(ids []string, err error) {
+	metadata := map[string]interface{}{
+		"code":        "builtin.attestation.builder_id",
+		"title":       "Builder ID check passed",
+		"description": "The image was built by an allowed builder.",
+	}
+	var message string
+
+	check := &VerificationStatus{}
+	if err == nil {
+		check.Passed = true
+		message = "Pass"
+		log.Debug("Builder ID check passed")
+	} else {
+		message = fmt.Sprintf("Builder ID check failed: %s", err)
+		log.Debug(message)
+	}
+	result := &evaluator.Result{Message: message, Metadata: metadata}
+	if !o.Detailed {
+		keepSomeMetadataSingle(*result)
+	}
+	check.Result = result
+	o.BuilderIDCheck = check
+	o.BuilderIDs = ids
+}
+
+// SetRekorTimeCheckFromError records the time the attestation was integrated
+// into the transparency log and the time the build finished, and sets the
+// passed and result.message fields of the RekorTimeCheck to the given values.
+func (o *Output) SetRekorTimeCheckFromError(integrated, finished *time.Time, err error) {
+	metadata := map[string]interface{}{
+		"code":        "builtin.attestation.rekor_time",
+		"title":       "Transparency log time check passed",
+		"description": "The attestation was recorded in the transparency log close to the time the build finished.",
+	}
+	var message string
+
+	check := &VerificationStatus{}
+	if err == nil {
+		check.Passed = true
+		message = "Pass"
+		log.Debug("Transparency log time check passed")
+	} else {
+		message = fmt.Sprintf("Transparency log time check failed: %s", err)
+		log.Debug(message)
+	}
+	result := &evaluator.Result{Message: message, Metadata: metadata}
+	if !o.Detailed {
+		keepSomeMetadataSingle(*result)
+	}
+	check.Result = result
+	o.RekorTimeCheck = check
+	o.RekorIntegratedTime = integrated
+	o.BuildFinishedOn = finished
+}
+
+// SetSBOMConsistencyCheckFromError sets the passed and result.message fields of
+// the SBOMConsistencyCheck to the given values.
+func (o *Output) SetSBOMConsistencyCheckFromError(err error) {
+	metadata := map[string]interface{}{
+		"code":        "builtin.attestation.sbom_consistency",
+		"title":       "SBOM consistency check passed",
+		"description": "The SBOM attestations describe the image and its layers.",
+	}
+	var message string
+
+	check := &VerificationStatus{}
+	if err == nil {
+		check.Passed = true
+		message = "Pass"
+		log.Debug("SBOM consistency check passed")
+	} else {
+		message = fmt.Sprintf("SBOM consistency check failed: %s", err)
+		log.Debug(message)
+	}
+	result := &evaluator.Result{Message: message, Metadata: metadata}
+	if !o.Detailed {
+		keepSomeMetadataSingle(*result)
+	}
+	check.Result = result
+	o.SBOMConsistencyCheck = check
+}
+
+// SetRequiredLabelCheckFromError records the required labels the image config
+// is missing and sets the passed and result.message fields of the
+// RequiredLabelCheck to the given values.
+func (o *Output) SetRequiredLabelCheckFromError(missing []string, err error) {
+	metadata := map[string]interface{}{
+		"code":        "builtin.image.required_labels",
+		"title":       "Required label check passed",
+		"description": "The image config sets all the required labels.",
+	}
+	var message string
+
+	check := &VerificationStatus{}
+	if err == nil {
+		check.Passed = true
+		message = "Pass"
+		log.Debug("Required label check passed")
+	} else {
+		message = fmt.Sprintf("Required label check failed: %s", err)
+		log.Debug(message)
+	}
+	result := &evaluator.Result{Message: message, Metadata: metadata}
+	if !o.Detailed {
+		keepSomeMetadataSingle(*result)
+	}
+	check.Result = result
+	o.RequiredLabelCheck = check
+	o.MissingLabels = missing
+}
+
+// SetImageAgeCheckFromError records the time the image was created and sets
+// the passed and result.message fields of the ImageAgeCheck to the given
+// values.
+func (o *Output) SetImageAgeCheckFromError(created *time.Time, err error) {
+	metadata := map[string]interface{}{
+		"code":        "builtin.image.max_age",
+		"title":       "Image age check passed",
+		"description": "The image was created within the maximum age allowed.",
+	}
+	var message string
+
+	check := &VerificationStatus{}
+	if err == nil {
+		check.Passed = true
+		message = "Pass"
+		log.Debug("Image age check passed")
+	} else {
+		message = fmt.Sprintf("Image age check failed: %s", err)
+		log.Debug(message)
+	}
+	result := &evaluator.Result{Message: message, Metadata: metadata}
+	if !o.Detailed {
+		keepSomeMetadataSingle(*result)
+	}
+	check.Result = result
+	o.ImageAgeCheck = check
+	o.ImageCreated = created
+}
+
+// SetInputSizeCheckFromError sets the passed and result.message fields of the
+// InputSizeCheck to the given values.
+func (o *Output) SetInputSizeCheckFromError(err error) {
+	metadata := map[string]interface{}{
+		"code":        "builtin.policy.input_size",
+		"title":       "Policy input size check passed",
+		"description": "The policy input of the image does not exceed the maximum size.",
+	}
+	var message string
+
+	check := &VerificationStatus{}
+	if err == nil {
+		check.Passed = true
+		message = "Pass"
+		log.Debug("Policy input size check passed")
+	} else {
+		message = fmt.Sprintf("Policy input size check failed: %s", err)
+		log.Debug(message)
+	}
+	result := &evaluator.Result{Message: message, Metadata: metadata}
+	if !o.Detailed {
+		keepSomeMetadataSingle(*result)
+	}
+	check.Result = result
+	o.InputSizeCheck = check
+}
+
+// SetPinnedDigestCheckFromError records the pinned and the resolved digest of
+// the image and sets the passed and result.message fields of the
+// PinnedDigestCheck to the given values.
+func (o *Output) SetPinnedDigestCheckFromError(pin *DigestPin, err error) {
+	metadata := map[string]interface{}{
+		"code":        "builtin.image.pinned_digest",
+		"title":       "Pinned digest check passed",
+		"description": "The image tag resolves to the digest it was pinned to.",
+	}
+	var message string
+
+	check := &VerificationStatus{}
+	if err == nil {
+		check.Passed = true
+		message = "Pass"
+		log.Debug("Pinned digest check passed")
+	} else {
+		message = fmt.Sprintf("Pinned digest check failed: %s", err)
+		log.Debug(message)
+	}
+	result := &evaluator.Result{Message: message, Metadata: metadata}
+	if !o.Detailed {
+		keepSomeMetadataSingle(*result)
+	}
+	check.Result = result
+	o.PinnedDigestCheck = check
+	o.DigestPin = pin
+}
+
+// SetOperatorDigestCheck records the decision of the operator on the image
+// digest, one of OperatorDenied or OperatorAllowed, and sets the
+// OperatorDigestCheck accordingly.
+func (o *Output) SetOperatorDigestCheck(decision string, digest string) {
+	check := &VerificationStatus{}
+	var metadata map[string]interface{}
+	var message string
+	if decision == OperatorAllowed {
+		check.Passed = true
+		metadata = map[string]interface{}{
+			"code":        "builtin.image.operator_allowed",
+			"title":       "Image allowed by operator",
+			"description": "The image digest is allowed by the operator, the image is neither verified nor evaluated.",
+		}
+		message = fmt.Sprintf("Allowed by operator: the image digest %s is on the allow list, the image is neither verified nor evaluated", digest)
+	} else {
+		metadata = map[string]interface{}{
+			"code":        "builtin.image.operator_denied",
+			"title":       "Image denied by operator",
+			"description": "The image digest is denied by the operator regardless of the policy.",
+		}
+		message = fmt.Sprintf("Denied by operator: the image digest %s is on the deny list", digest)
+	}
+	log.Debug(message)
+
+	result := &evaluator.Result{Message: message, Metadata: metadata}
+	if !o.Detailed {
+		keepSomeMetadataSingle(*result)
+	}
+	check.Result = result
+	o.OperatorDigestCheck = check
+	o.OperatorDecision = decision
+}
+
+// SetAttestationConflictCheckFromError records the conflicting claims of the
+// attestations and sets the passed and result.message fields of the
+// AttestationConflictCheck to the given values.
+func (o *Output) SetAttestationConflictCheckFromError(conflicts []AttestationConflict, err error) {
+	metadata := map[string]interface{}{
+		"code":        "builtin.attestation.conflict",
+		"title":       "Attestation conflict check passed",
+		"description": "The attestations of the same predicate type agree on the key fields of the predicate.",
+	}
+	var message string
+
+	check := &VerificationStatus{}
+	if err == nil {
+		check.Passed = true
+		message = "Pass"
+		log.Debug("Attestation conflict check passed")
+	} else {
+		message = fmt.Sprintf("Attestation conflict check failed: %s", err)
+		log.Debug(message)
+	}
+	result := &evaluator.Result{Message: message, Metadata: metadata}
+	if !o.Detailed {
+		keepSomeMetadataSingle(*result)
+	}
+	check.Result = result
+	o.AttestationConflictCheck = check
+	o.AttestationConflicts = conflicts
+}
+
+// SetSubjectConsistencyCheckFromError records the distinct subject digests of
+// the attestations, when they disagree, and sets the passed and
+// result.message fields of the SubjectConsistencyCheck to the given values.
+func (o *Output) SetSubjectConsistencyCheckFromError(digests []string, err error) {
+	metadata := map[string]interface{}{
+		"code":        "builtin.attestation.subject_consistency",
+		"title":       "Subject consistency check passed",
+		"description": "The attestations of the image describe the same subjects.",
+	}
+	var message string
+
+	check := &VerificationStatus{}
+	if err == nil {
+		check.Passed = true
+		message = "Pass"
+		log.Debug("Subject consistency check passed")
+	} else {
+		message = fmt.Sprintf("Subject consistency check failed: %s", err)
+		log.Debug(message)
+	}
+	result := &evaluator.Result{Message: message, Metadata: metadata}
+	if !o.Detailed {
+		keepSomeMetadataSingle(*result)
+	}
+	check.Result = result
+	o.SubjectConsistencyCheck = check
+	o.SubjectDigests = digests
+}
+
+// SetSigningKeyCheckFromError records the signing material the signatures
+// were verified with and sets the passed and result.message fields of the
+// SigningKeyCheck to the given values.
+func (o *Output) SetSigningKeyCheckFromError(keys []signature.SigningKey, err error) {
+	metadata := map[string]interface{}{
+		"code":        "builtin.image.signing_key",
+		"title":       "Signing key check passed",
+		"description": "The image signatures and attestations are signed using allowed algorithms and keys of sufficient size.",
+	}
+	var message string
+
+	check := &VerificationStatus{}
+	if err == nil {
+		check.Passed = true
+		message = "Pass"
+		log.Debug("Signing key check passed")
+	} else {
+		message = fmt.Sprintf("Signing key check failed: %s", err)
+		log.Debug(message)
+	}
+	result := &evaluator.Result{Message: message, Metadata: metadata}
+	if !o.Detailed {
+		keepSomeMetadataSingle(*result)
+	}
+	check.Result = result
+	o.SigningKeyCheck = check
+	o.SigningKeys = keys
 }
 
 // SetPolicyCheck sets the PolicyCheck and ExitCode to the results and exit code of the Results
@@ -209,6 +697,7 @@ func (o *Output) SetPolicyCheck(results []evaluator.Outcome) {
 		}
 	}
 	o.PolicyCheck = results
+	o.Verification.Policy = policyStage(results)
 }
 
 func keepSomeMetadata(results []evaluator.Result) {
@@ -219,7 +708,7 @@ func keepSomeMetadata(results []evaluator.Result) {
 
 func keepSomeMetadataSingle(result evaluator.Result) {
 	for key := range result.Metadata {
-		if key == "code" || key == "effective_on" {
+		if key == "code" || key == "effective_on" || key == "enforce_after" || key == "demotion" || key == evaluator.PolicyConfigMetadataKey {
 			continue
 		}
 		delete(result.Metadata, key)
@@ -242,6 +731,48 @@ func (o Output) Violations() []evaluator.Result {
 	violations = o.ImageAccessibleCheck.addToViolations(violations)
 	violations = o.AttestationSignatureCheck.addToViolations(violations)
 	violations = o.AttestationSyntaxCheck.addToViolations(violations)
+	if o.SLSALevelCheck != nil {
+		violations = o.SLSALevelCheck.addToViolations(violations)
+	}
+	if o.MediaTypeCheck != nil {
+		violations = o.MediaTypeCheck.addToViolations(violations)
+	}
+	if o.BaseImageCheck != nil {
+		violations = o.BaseImageCheck.addToViolations(violations)
+	}
+	if o.BuilderIDCheck != nil {
+		violations = o.BuilderIDCheck.addToViolations(violations)
+	}
+	if o.RekorTimeCheck != nil {
+		violations = o.RekorTimeCheck.addToViolations(violations)
+	}
+	if o.SBOMConsistencyCheck != nil {
+		violations = o.SBOMConsistencyCheck.addToViolations(violations)
+	}
+	if o.SigningKeyCheck != nil {
+		violations = o.SigningKeyCheck.addToViolations(violations)
+	}
+	if o.RequiredLabelCheck != nil {
+		violations = o.RequiredLabelCheck.addToViolations(violations)
+	}
+	if o.PinnedDigestCheck != nil {
+		violations = o.PinnedDigestCheck.addToViolations(violations)
+	}
+	if o.AttestationConflictCheck != nil {
+		violations = o.AttestationConflictCheck.addToViolations(violations)
+	}
+	if o.OperatorDigestCheck != nil {
+		violations = o.OperatorDigestCheck.addToViolations(violations)
+	}
+	if o.ImageAgeCheck != nil {
+		violations = o.ImageAgeCheck.addToViolations(violations)
+	}
+	if o.InputSizeCheck != nil {
+		violations = o.InputSizeCheck.addToViolations(violations)
+	}
+	if o.SubjectConsistencyCheck != nil {
+		violations = o.SubjectConsistencyCheck.addToViolations(violations)
+	}
 	violations = o.addCheckResultsToViolations(violations)
 
 	violations = sortResults(violations)
@@ -269,6 +800,48 @@ func (o Output) Successes() []evaluator.Result {
 	successes = o.ImageSignatureCheck.addToSuccesses(successes)
 	successes = o.AttestationSignatureCheck.addToSuccesses(successes)
 	successes = o.AttestationSyntaxCheck.addToSuccesses(successes)
+	if o.SLSALevelCheck != nil {
+		successes = o.SLSALevelCheck.addToSuccesses(successes)
+	}
+	if o.MediaTypeCheck != nil {
+		successes = o.MediaTypeCheck.addToSuccesses(successes)
+	}
+	if o.BaseImageCheck != nil {
+		successes = o.BaseImageCheck.addToSuccesses(successes)
+	}
+	if o.BuilderIDCheck != nil {
+		successes = o.BuilderIDCheck.addToSuccesses(successes)
+	}
+	if o.RekorTimeCheck != nil {
+		successes = o.RekorTimeCheck.addToSuccesses(successes)
+	}
+	if o.SBOMConsistencyCheck != nil {
+		successes = o.SBOMConsistencyCheck.addToSuccesses(successes)
+	}
+	if o.SigningKeyCheck != nil {
+		successes = o.SigningKeyCheck.addToSuccesses(successes)
+	}
+	if o.RequiredLabelCheck != nil {
+		successes = o.RequiredLabelCheck.addToSuccesses(successes)
+	}
+	if o.PinnedDigestCheck != nil {
+		successes = o.PinnedDigestCheck.addToSuccesses(successes)
+	}
+	if o.AttestationConflictCheck != nil {
+		successes = o.AttestationConflictCheck.addToSuccesses(successes)
+	}
+	if o.OperatorDigestCheck != nil {
+		successes = o.OperatorDigestCheck.addToSuccesses(successes)
+	}
+	if o.ImageAgeCheck != nil {
+		successes = o.ImageAgeCheck.addToSuccesses(successes)
+	}
+	if o.InputSizeCheck != nil {
+		successes = o.InputSizeCheck.addToSuccesses(successes)
+	}
+	if o.SubjectConsistencyCheck != nil {
+		successes = o.SubjectConsistencyCheck.addToSuccesses(successes)
+	}
 
 	successes = sortResults(successes)
 	return successes
@@ -342,7 +915,7 @@ func wrapCosignErrorMessage(err error, checkType string, p policy.Policy) string
 		switch err.(type) {
 		case *cosign.ErrNoMatchingSignatures:
 			return fmt.Sprintf(missingSignatureMessage, err)
-		case *cosign.ErrNoMatchingAttestations:
+		case *cosign.ErrNoMatchingAttestations, *oci.NoAttestationsError:
 			return fmt.Sprintf(missingAttestationMessage, err)
 		}
 	}
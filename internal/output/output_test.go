@@ -21,13 +21,33 @@ package output
 import (
 	"bytes"
 	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"log"
+	"net/http/httptest"
+	"net/url"
 	"reflect"
 	"testing"
 	"unsafe"
 
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
 	"github.com/sigstore/cosign/v2/pkg/cosign"
+	"github.com/sigstore/cosign/v2/pkg/oci/empty"
+	"github.com/sigstore/cosign/v2/pkg/oci/mutate"
+	ociremote "github.com/sigstore/cosign/v2/pkg/oci/remote"
+	"github.com/sigstore/cosign/v2/pkg/oci/static"
+	sigstoresig "github.com/sigstore/sigstore/pkg/signature"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -35,6 +55,7 @@ import (
 	"github.com/enterprise-contract/ec-cli/internal/policy"
 	"github.com/enterprise-contract/ec-cli/internal/signature"
 	"github.com/enterprise-contract/ec-cli/internal/utils"
+	"github.com/enterprise-contract/ec-cli/internal/utils/oci"
 )
 
 func Test_PrintExpectedJSON(t *testing.T) {
@@ -1041,3 +1062,113 @@ func TestSetAttestationSyntaxCheckFromError(t *testing.T) {
 		})
 	}
 }
+
+// cosignErrors returns the errors cosign reports when verifying an image
+// without signatures, an image signed with another key, and an image without
+// attestations.
+func cosignErrors(t *testing.T) (noSignatures error, noMatchingSignatures error, noMatchingAttestations error) {
+	registry := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	t.Cleanup(registry.Close)
+
+	u, err := url.Parse(registry.URL)
+	require.NoError(t, err)
+
+	img, err := random.Image(1024, 1)
+	require.NoError(t, err)
+	ref, err := name.ParseReference(fmt.Sprintf("localhost:%s/image:tag", u.Port()))
+	require.NoError(t, err)
+	require.NoError(t, remote.Push(ref, img))
+
+	newSigner := func() sigstoresig.SignerVerifier {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+		signer, err := sigstoresig.LoadECDSASignerVerifier(key, crypto.SHA256)
+		require.NoError(t, err)
+
+		return signer
+	}
+
+	opts := &cosign.CheckOpts{SigVerifier: newSigner(), IgnoreTlog: true}
+	_, _, noSignatures = cosign.VerifyImageSignatures(context.Background(), ref, opts)
+
+	payload := []byte("payload")
+	sig, err := newSigner().SignMessage(bytes.NewReader(payload))
+	require.NoError(t, err)
+	ociSig, err := static.NewSignature(payload, base64.StdEncoding.EncodeToString(sig))
+	require.NoError(t, err)
+	digest, err := ociremote.ResolveDigest(ref)
+	require.NoError(t, err)
+	se, err := ociremote.SignedEntity(digest)
+	require.NoError(t, err)
+	se, err = mutate.AttachSignatureToEntity(se, ociSig)
+	require.NoError(t, err)
+	require.NoError(t, ociremote.WriteSignatures(digest.Repository, se))
+	_, _, noMatchingSignatures = cosign.VerifyImageSignatures(context.Background(), ref, opts)
+
+	_, _, noMatchingAttestations = cosign.VerifyImageAttestation(context.Background(), empty.Signatures(), v1.Hash{}, opts)
+
+	return
+}
+
+func TestVerification(t *testing.T) {
+	noSignatures, noMatchingSignatures, noMatchingAttestations := cosignErrors(t)
+	require.IsType(t, &cosign.ErrNoSignaturesFound{}, noSignatures)
+	require.IsType(t, &cosign.ErrNoMatchingSignatures{}, noMatchingSignatures)
+	require.IsType(t, &cosign.ErrNoMatchingAttestations{}, noMatchingAttestations)
+
+	o := Output{}
+	o.SetImageAccessibleCheckFromError(nil)
+	o.SetImageSignatureCheckFromError(noSignatures)
+	o.SetAttestationSignatureCheckFromError(errors.New("bad signature"))
+
+	assert.Equal(t, Verification{
+		ImageAccessible:      Stage{Status: StageOK},
+		ImageSignature:       Stage{Status: StageFailed, Category: MissingSignature, Error: "no signatures found"},
+		AttestationSignature: Stage{Status: StageFailed, Category: InvalidAttestation, Error: "bad signature"},
+	}, o.Verification)
+
+	o.SetImageSignatureCheckFromError(noMatchingSignatures)
+	assert.Equal(t, InvalidSignature, o.Verification.ImageSignature.Category)
+
+	o.SetAttestationSignatureCheckFromError(noMatchingAttestations)
+	assert.Equal(t, InvalidAttestation, o.Verification.AttestationSignature.Category)
+
+	o.SetAttestationSignatureCheckFromError(&oci.NoAttestationsError{Err: noMatchingAttestations})
+	assert.Equal(t, MissingAttestation, o.Verification.AttestationSignature.Category)
+
+	o.SetAttestationSyntaxCheckFromError(errors.New("invalid statement"))
+	assert.Equal(t, Stage{Status: StageFailed, Category: InvalidAttestationSyntax, Error: "invalid statement"}, o.Verification.AttestationSyntax)
+
+	o.SetPolicyCheck([]evaluator.Outcome{{Failures: []evaluator.Result{{Message: "one"}, {Message: "two"}}}})
+	assert.Equal(t, Stage{Status: StageFailed, Category: PolicyViolation, Error: "2 policy violation(s) found"}, o.Verification.Policy)
+
+	o.SetPolicyCheck([]evaluator.Outcome{{Successes: []evaluator.Result{{Message: "one"}}}})
+	assert.Equal(t, Stage{Status: StageOK}, o.Verification.Policy)
+}
+
+func TestVerificationJSON(t *testing.T) {
+	o := Output{}
+	o.SetImageAccessibleCheckFromError(errors.New("no such host"))
+
+	j, err := json.Marshal(o.Verification)
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{
+		"imageAccessible": {"status": "failed", "category": "image_inaccessible", "error": "no such host"},
+		"imageSignature": {"status": "skipped"},
+		"attestationSignature": {"status": "skipped"},
+		"attestationSyntax": {"status": "skipped"},
+		"policy": {"status": "skipped"}
+	}`, string(j))
+}
+
+func TestVerificationRateLimited(t *testing.T) {
+	o := Output{}
+	o.SetImageAccessibleCheckFromError(nil)
+	assert.False(t, o.Verification.RateLimited())
+
+	o.SetImageSignatureCheckFromError(errors.New("GET https://index.docker.io/v2/: TOOMANYREQUESTS: You have reached your pull rate limit"))
+	assert.Equal(t, StageRateLimited, o.Verification.ImageSignature.Status)
+	assert.Equal(t, RateLimited, o.Verification.ImageSignature.Category)
+	assert.True(t, o.Verification.RateLimited())
+}
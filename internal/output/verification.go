@@ -0,0 +1,146 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package output
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/sigstore/cosign/v2/pkg/cosign"
+
+	"github.com/enterprise-contract/ec-cli/internal/evaluator"
+	"github.com/enterprise-contract/ec-cli/internal/utils/oci"
+)
+
+// StageStatus is the outcome of a single verification stage.
+type StageStatus string
+
+const (
+	StageOK      StageStatus = "ok"
+	StageFailed  StageStatus = "failed"
+	StageSkipped StageStatus = "skipped"
+	// StageRateLimited marks stages that could not be completed because the
+	// registry rate limited the requests, the validation should be rerun
+	StageRateLimited StageStatus = "rate_limited"
+)
+
+// MarshalText reports stages that were never reached as skipped.
+func (s StageStatus) MarshalText() ([]byte, error) {
+	if s == "" {
+		s = StageSkipped
+	}
+
+	return []byte(s), nil
+}
+
+// Failure categories, allowing to tell apart why a verification stage failed.
+const (
+	ImageInaccessible        = "image_inaccessible"
+	MissingSignature         = "missing_signature"
+	InvalidSignature         = "invalid_signature"
+	MissingAttestation       = "missing_attestation"
+	InvalidAttestation       = "invalid_attestation"
+	InvalidAttestationSyntax = "invalid_attestation_syntax"
+	PolicyViolation          = "policy_violation"
+	RateLimited              = "rate_limited"
+)
+
+// Stage holds the status of a verification stage and, on failure, the
+// category and the error that caused it.
+type Stage struct {
+	Status   StageStatus `json:"status"`
+	Category string      `json:"category,omitempty"`
+	Error    string      `json:"error,omitempty"`
+}
+
+// Verification is a structured diagnosis of the verification of an image,
+// split across the stages the verification goes through.
+type Verification struct {
+	ImageAccessible      Stage `json:"imageAccessible"`
+	ImageSignature       Stage `json:"imageSignature"`
+	AttestationSignature Stage `json:"attestationSignature"`
+	AttestationSyntax    Stage `json:"attestationSyntax"`
+	Policy               Stage `json:"policy"`
+}
+
+func newStage(err error, category string) Stage {
+	if err == nil {
+		return Stage{Status: StageOK}
+	}
+
+	if oci.IsRateLimited(err) {
+		return Stage{Status: StageRateLimited, Category: RateLimited, Error: err.Error()}
+	}
+
+	return Stage{Status: StageFailed, Category: category, Error: err.Error()}
+}
+
+// RateLimited returns true if any of the stages could not be completed because
+// the registry rate limited the requests.
+func (v Verification) RateLimited() bool {
+	for _, s := range []Stage{v.ImageAccessible, v.ImageSignature, v.AttestationSignature, v.AttestationSyntax, v.Policy} {
+		if s.Status == StageRateLimited {
+			return true
+		}
+	}
+
+	return false
+}
+
+// signatureCategory distinguishes missing signatures from signatures that do
+// not verify.
+func signatureCategory(err error) string {
+	var noSignatures *cosign.ErrNoSignaturesFound
+	var tagNotFound *cosign.ErrImageTagNotFound
+	if errors.As(err, &noSignatures) || errors.As(err, &tagNotFound) {
+		return MissingSignature
+	}
+
+	return InvalidSignature
+}
+
+// attestationCategory distinguishes missing attestations from attestations
+// that do not verify. Attestations are missing only if none were found at all,
+// attestations that fail to verify, e.g. signed with another key, are invalid.
+func attestationCategory(err error) string {
+	var noAttestations *oci.NoAttestationsError
+	var tagNotFound *cosign.ErrImageTagNotFound
+	if errors.As(err, &noAttestations) || errors.As(err, &tagNotFound) {
+		return MissingAttestation
+	}
+
+	return InvalidAttestation
+}
+
+// policyStage fails the policy stage when any of the outcomes contains a
+// failure.
+func policyStage(outcomes []evaluator.Outcome) Stage {
+	failures := 0
+	for _, o := range outcomes {
+		failures += len(o.Failures)
+	}
+
+	if failures == 0 {
+		return Stage{Status: StageOK}
+	}
+
+	return Stage{
+		Status:   StageFailed,
+		Category: PolicyViolation,
+		Error:    fmt.Sprintf("%d policy violation(s) found", failures),
+	}
+}
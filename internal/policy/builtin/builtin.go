@@ -0,0 +1,146 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package builtin provides the policy bundle embedded into the ec binary at
+// build time, for use without network access, e.g. in air-gapped
+// environments. See bundle/README.md on how to embed a policy bundle.
+package builtin
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+// Name is the value of the --policy flag and the URL of the policy sources
+// using the embedded policy bundle
+const Name = "builtin"
+
+const (
+	policyDir   = "policy"
+	dataDir     = "data"
+	versionFile = "VERSION"
+)
+
+//go:embed all:bundle
+var embedded embed.FS
+
+// bundle is the embedded policy bundle, replaced in tests
+var bundle fs.FS = mustSub(embedded, "bundle")
+
+func mustSub(f fs.FS, dir string) fs.FS {
+	sub, err := fs.Sub(f, dir)
+	if err != nil {
+		panic(err)
+	}
+
+	return sub
+}
+
+// Available returns true if a policy bundle with policy rules was embedded.
+func Available() bool {
+	return hasFiles(policyDir)
+}
+
+func hasFiles(dir string) bool {
+	found := false
+	_ = fs.WalkDir(bundle, dir, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			found = true
+			return fs.SkipAll
+		}
+		return nil
+	})
+
+	return found
+}
+
+// Version returns the version of the embedded policy bundle, N/A if none was
+// embedded or if it has no version.
+func Version() string {
+	if !Available() {
+		return "N/A"
+	}
+
+	v, err := fs.ReadFile(bundle, versionFile)
+	if err != nil || strings.TrimSpace(string(v)) == "" {
+		return "N/A"
+	}
+
+	return strings.TrimSpace(string(v))
+}
+
+// PolicyConfig returns the policy configuration evaluating the embedded policy
+// bundle, with its data if any.
+func PolicyConfig() (string, error) {
+	if !Available() {
+		return "", errors.New("no policy bundle is embedded in this build of ec")
+	}
+
+	sourceGroup := map[string]any{
+		"name":   Name,
+		"policy": []string{Name},
+	}
+	if hasFiles(dataDir) {
+		sourceGroup["data"] = []string{Name}
+	}
+
+	config, err := json.Marshal(map[string]any{"sources": []any{sourceGroup}})
+	if err != nil {
+		return "", err
+	}
+
+	return string(config), nil
+}
+
+// Extract writes the policy rules, for the policy kind, or the data, for the
+// data kind, of the embedded policy bundle to the destination directory.
+func Extract(afs afero.Fs, kind string, dest string) error {
+	if kind != policyDir && kind != dataDir {
+		return errors.New("the embedded policy bundle provides only policy and data sources")
+	}
+
+	if !hasFiles(kind) {
+		return fmt.Errorf("no %s is embedded in this build of ec", kind)
+	}
+
+	return fs.WalkDir(bundle, kind, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		target := path.Join(dest, strings.TrimPrefix(p, kind))
+		if d.IsDir() {
+			return afs.MkdirAll(target, 0755)
+		}
+
+		content, err := fs.ReadFile(bundle, p)
+		if err != nil {
+			return err
+		}
+
+		return afero.WriteFile(afs, target, content, 0644)
+	})
+}
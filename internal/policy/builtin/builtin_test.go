@@ -0,0 +1,88 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package builtin
+
+import (
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func withBundle(t *testing.T, b fs.FS) {
+	original := bundle
+	t.Cleanup(func() { bundle = original })
+	bundle = b
+}
+
+func TestNotEmbedded(t *testing.T) {
+	assert.False(t, Available(), "only the README is committed")
+	assert.Equal(t, "N/A", Version())
+
+	_, err := PolicyConfig()
+	assert.EqualError(t, err, "no policy bundle is embedded in this build of ec")
+
+	err = Extract(afero.NewMemMapFs(), "policy", "/dest")
+	assert.EqualError(t, err, "no policy is embedded in this build of ec")
+}
+
+func TestEmbedded(t *testing.T) {
+	withBundle(t, fstest.MapFS{
+		"VERSION":                   {Data: []byte("v1.2.3\n")},
+		"policy/release/rules.rego": {Data: []byte("package release")},
+		"data/rule_data.yml":        {Data: []byte("rule_data: {}")},
+	})
+
+	assert.True(t, Available())
+	assert.Equal(t, "v1.2.3", Version())
+
+	config, err := PolicyConfig()
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"sources": [{"name": "builtin", "policy": ["builtin"], "data": ["builtin"]}]}`, config)
+
+	afs := afero.NewMemMapFs()
+	require.NoError(t, Extract(afs, "policy", "/dest/policy"))
+	content, err := afero.ReadFile(afs, "/dest/policy/release/rules.rego")
+	require.NoError(t, err)
+	assert.Equal(t, "package release", string(content))
+
+	require.NoError(t, Extract(afs, "data", "/dest/data"))
+	exists, err := afero.Exists(afs, "/dest/data/rule_data.yml")
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	assert.EqualError(t, Extract(afs, "config", "/dest/config"), "the embedded policy bundle provides only policy and data sources")
+}
+
+func TestEmbeddedWithoutDataAndVersion(t *testing.T) {
+	withBundle(t, fstest.MapFS{
+		"policy/rules.rego": {Data: []byte("package release")},
+	})
+
+	assert.Equal(t, "N/A", Version())
+
+	config, err := PolicyConfig()
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"sources": [{"name": "builtin", "policy": ["builtin"]}]}`, config)
+
+	assert.EqualError(t, Extract(afero.NewMemMapFs(), "data", "/dest"), "no data is embedded in this build of ec")
+}
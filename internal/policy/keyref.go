@@ -0,0 +1,83 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/sigstore/sigstore/pkg/signature/kms"
+	// Register the KMS providers public key references are resolved with
+	_ "github.com/sigstore/sigstore/pkg/signature/kms/aws"
+	_ "github.com/sigstore/sigstore/pkg/signature/kms/azure"
+	_ "github.com/sigstore/sigstore/pkg/signature/kms/gcp"
+	_ "github.com/sigstore/sigstore/pkg/signature/kms/hashivault"
+)
+
+// keyRefProvider describes a provider that public key references, e.g.
+// k8s://namespace/secret, are resolved with.
+type keyRefProvider struct {
+	scheme string
+	name   string
+	// kms is set for providers resolved through the sigstore KMS providers,
+	// which need to be registered in order to be used.
+	kms bool
+}
+
+var keyRefProviders = []keyRefProvider{
+	{scheme: "k8s://", name: "Kubernetes secret"},
+	{scheme: "awskms://", name: "AWS KMS", kms: true},
+	{scheme: "gcpkms://", name: "GCP KMS", kms: true},
+	{scheme: "azurekms://", name: "Azure Key Vault", kms: true},
+	{scheme: "hashivault://", name: "HashiCorp Vault", kms: true},
+	{scheme: "gitlab://", name: "GitLab"},
+	{scheme: "pkcs11:", name: "PKCS #11 token"},
+}
+
+// keyRefProviderFor returns the provider for the given public key reference,
+// if the reference is not a file path.
+func keyRefProviderFor(keyRef string) (keyRefProvider, bool) {
+	for _, p := range keyRefProviders {
+		if strings.HasPrefix(keyRef, p.scheme) {
+			return p, true
+		}
+	}
+
+	return keyRefProvider{}, false
+}
+
+// keyRefError wraps the error of resolving the public key reference with a
+// message identifying the provider used to resolve it. Errors loading the
+// public key from a file are returned as is.
+func keyRefError(keyRef string, err error) error {
+	provider, ok := keyRefProviderFor(keyRef)
+	if !ok {
+		return err
+	}
+
+	var notFound *kms.ProviderNotFoundError
+	if provider.kms && errors.As(err, &notFound) {
+		supported := kms.SupportedProviders()
+		sort.Strings(supported)
+
+		return fmt.Errorf("the %s provider, required for the public key %s, is not available, the available KMS providers are: [%s]", provider.name, keyRef, strings.Join(supported, ", "))
+	}
+
+	return fmt.Errorf("unable to resolve the public key %s using the %s provider: %w", keyRef, provider.name, err)
+}
@@ -0,0 +1,93 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package policy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	ecc "github.com/enterprise-contract/enterprise-contract-controller/api/v1alpha1"
+	"github.com/spf13/afero"
+	"sigs.k8s.io/yaml"
+
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+// DefaultPolicyLabel is the image label selecting the policy sources when the
+// label policies do not name one.
+const DefaultPolicyLabel = "ec.policy"
+
+// ErrNoPolicyLabel is returned when the sources for an image can't be
+// selected using its labels.
+var ErrNoPolicyLabel = errors.New("no policy sources selected by the image labels")
+
+// LabelPolicies maps the values of an image label to the policy sources used
+// to validate the images with that label, e.g.
+//
+//	label: ec.policy
+//	sources:
+//	  frontend:
+//	  - policy:
+//	    - github.com/org/policy//frontend
+//	  backend:
+//	  - policy:
+//	    - github.com/org/policy//backend
+type LabelPolicies struct {
+	Label   string                  `json:"label,omitempty"`
+	Sources map[string][]ecc.Source `json:"sources"`
+}
+
+// ReadLabelPolicies reads the label policies from the given YAML or JSON file.
+func ReadLabelPolicies(ctx context.Context, file string) (*LabelPolicies, error) {
+	content, err := afero.ReadFile(utils.FS(ctx), file)
+	if err != nil {
+		return nil, err
+	}
+
+	var l LabelPolicies
+	if err := yaml.Unmarshal(content, &l); err != nil {
+		return nil, fmt.Errorf("unable to parse the label policies from %s: %w", file, err)
+	}
+
+	if l.Label == "" {
+		l.Label = DefaultPolicyLabel
+	}
+
+	if len(l.Sources) == 0 {
+		return nil, fmt.Errorf("no policy sources defined in the label policies %s", file)
+	}
+
+	return &l, nil
+}
+
+// Select returns the value of the label and the policy sources mapped to it.
+// ErrNoPolicyLabel is returned if the label is not set or if no sources are
+// mapped to its value.
+func (l LabelPolicies) Select(labels map[string]string) (string, []ecc.Source, error) {
+	value, ok := labels[l.Label]
+	if !ok {
+		return "", nil, fmt.Errorf("%w: the image does not have the %s label", ErrNoPolicyLabel, l.Label)
+	}
+
+	sources, ok := l.Sources[value]
+	if !ok {
+		return value, nil, fmt.Errorf("%w: no policy sources for the %s label value %q", ErrNoPolicyLabel, l.Label, value)
+	}
+
+	return value, sources, nil
+}
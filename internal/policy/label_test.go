@@ -0,0 +1,74 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package policy
+
+import (
+	"context"
+	"testing"
+
+	ecc "github.com/enterprise-contract/enterprise-contract-controller/api/v1alpha1"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+func TestReadLabelPolicies(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	ctx := utils.WithFS(context.Background(), fs)
+
+	require.NoError(t, afero.WriteFile(fs, "/labels.yaml", []byte(`
+sources:
+  frontend:
+  - policy: [github.com/org/policy//frontend]
+`), 0400))
+	require.NoError(t, afero.WriteFile(fs, "/empty.yaml", []byte(`label: app.type`), 0400))
+
+	l, err := ReadLabelPolicies(ctx, "/labels.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, &LabelPolicies{
+		Label:   DefaultPolicyLabel,
+		Sources: map[string][]ecc.Source{"frontend": {{Policy: []string{"github.com/org/policy//frontend"}}}},
+	}, l)
+
+	_, err = ReadLabelPolicies(ctx, "/empty.yaml")
+	assert.EqualError(t, err, "no policy sources defined in the label policies /empty.yaml")
+
+	_, err = ReadLabelPolicies(ctx, "/missing.yaml")
+	assert.Error(t, err)
+}
+
+func TestLabelPoliciesSelect(t *testing.T) {
+	frontend := []ecc.Source{{Name: "frontend"}}
+	l := LabelPolicies{Label: DefaultPolicyLabel, Sources: map[string][]ecc.Source{"frontend": frontend}}
+
+	value, sources, err := l.Select(map[string]string{"ec.policy": "frontend"})
+	assert.NoError(t, err)
+	assert.Equal(t, "frontend", value)
+	assert.Equal(t, frontend, sources)
+
+	_, _, err = l.Select(map[string]string{"other": "frontend"})
+	assert.ErrorIs(t, err, ErrNoPolicyLabel)
+	assert.EqualError(t, err, "no policy sources selected by the image labels: the image does not have the ec.policy label")
+
+	_, _, err = l.Select(map[string]string{"ec.policy": "backend"})
+	assert.ErrorIs(t, err, ErrNoPolicyLabel)
+	assert.EqualError(t, err, `no policy sources selected by the image labels: no policy sources for the ec.policy label value "backend"`)
+}
@@ -24,6 +24,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"regexp"
 	"strings"
 	"time"
 
@@ -38,9 +39,12 @@ import (
 	"github.com/sigstore/sigstore/pkg/cryptoutils"
 	sigstoreSig "github.com/sigstore/sigstore/pkg/signature"
 	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
 	"sigs.k8s.io/yaml"
 
 	"github.com/enterprise-contract/ec-cli/internal/kubernetes"
+	"github.com/enterprise-contract/ec-cli/internal/policy/source"
+	"github.com/enterprise-contract/ec-cli/internal/utils"
 )
 
 const (
@@ -83,6 +87,7 @@ type Policy interface {
 	WithSpec(spec ecc.EnterpriseContractPolicySpec) Policy
 	Spec() ecc.EnterpriseContractPolicySpec
 	EffectiveTime() time.Time
+	EffectiveTimeFor(attestationTime *time.Time) time.Time
 	AttestationTime(time.Time)
 	Identity() cosign.Identity
 	Keyless() bool
@@ -97,6 +102,7 @@ type policy struct {
 	attestationTime *time.Time
 	identity        cosign.Identity
 	ignoreRekor     bool
+	tsaCertChain    string
 }
 
 // PublicKeyPEM returns the PublicKey in PEM format.
@@ -161,6 +167,9 @@ type Options struct {
 	PolicyRef     string
 	PublicKey     string
 	RekorURL      string
+	// TSACertChain is the path to the PEM encoded certificate chain of the
+	// trusted timestamp authority
+	TSACertChain string
 }
 
 // NewOfflinePolicy construct and return a new instance of Policy that is used
@@ -249,6 +258,7 @@ func NewPolicy(ctx context.Context, opts Options) (Policy, error) {
 	}
 
 	p.ignoreRekor = opts.IgnoreRekor
+	p.tsaCertChain = opts.TSACertChain
 
 	if opts.PublicKey != "" && opts.PublicKey != p.PublicKey {
 		p.PublicKey = opts.PublicKey
@@ -288,7 +298,37 @@ func NewPolicy(ctx context.Context, opts Options) (Policy, error) {
 	return &p, nil
 }
 
+// DefaultSourcesName names the source group holding the default policy
+// sources given by the environment, see source.DefaultSources.
+const DefaultSourcesName = "default-sources"
+
+// loadPolicy reads the policy configuration and merges in the default sources
+// given by the environment, if any. The default data sources are appended to
+// the data sources of every source group, after the data sources of the group.
+// The default policy sources are added as the first source group, using the
+// default data sources.
 func (p *policy) loadPolicy(ctx context.Context, policyRef string) error {
+	if err := p.readPolicy(ctx, policyRef); err != nil {
+		return err
+	}
+
+	policies, data := source.DefaultSources()
+	if len(data) > 0 {
+		log.Debugf("Adding the default data sources %v to every source group", data)
+		for i := range p.Sources {
+			p.Sources[i].Data = source.MergeSources(p.Sources[i].Data, data)
+		}
+	}
+
+	if len(policies) > 0 {
+		log.Debugf("Adding the default policy sources %v", policies)
+		p.Sources = append([]ecc.Source{{Name: DefaultSourcesName, Policy: policies, Data: data}}, p.Sources...)
+	}
+
+	return nil
+}
+
+func (p *policy) readPolicy(ctx context.Context, policyRef string) error {
 	if policyRef == "" {
 		log.Debug("Using an empty EnterpriseContractPolicy")
 		// Default to an empty policy instead of returning an error because the required
@@ -383,6 +423,24 @@ func (p policy) EffectiveTime() time.Time {
 	return *p.effectiveTime
 }
 
+// EffectiveTimeFor returns the effective time for an image with the given
+// attestation time, nil if none was determined. Unlike EffectiveTime, it does
+// not depend on the attestation time last set on the policy, which is shared
+// by all the images validated with it.
+func (p policy) EffectiveTimeFor(attestationTime *time.Time) time.Time {
+	if p.choosenTime != AtAttestation {
+		return p.EffectiveTime()
+	}
+
+	if attestationTime == nil {
+		now := now().UTC()
+		log.Debugf("No attestation time determined using current time: %s", now.Format(time.RFC3339))
+		return now
+	}
+
+	return *attestationTime
+}
+
 func isNow(choosenTime string) bool {
 	return strings.EqualFold(choosenTime, Now)
 }
@@ -432,7 +490,7 @@ func checkOpts(ctx context.Context, p *policy) (*cosign.CheckOpts, error) {
 	} else {
 		log.Debug("Using keyless workflow")
 		log.Debugf("TUF_ROOT=%s", os.Getenv("TUF_ROOT"))
-		opts.Identities = []cosign.Identity{p.identity}
+		opts.Identities = identities(p.identity)
 
 		// Get Fulcio certificates
 		if opts.RootCerts, err = fulcio.GetRoots(); err != nil {
@@ -449,6 +507,13 @@ func checkOpts(ctx context.Context, p *policy) (*cosign.CheckOpts, error) {
 		log.Debug("Retrieved Rekor public keys")
 	}
 
+	if p.tsaCertChain != "" {
+		if err := tsaCertificates(ctx, p.tsaCertChain, &opts); err != nil {
+			return nil, err
+		}
+		log.Debug("Loaded the timestamp authority certificates")
+	}
+
 	opts.IgnoreTlog = p.ignoreRekor
 
 	if !opts.IgnoreTlog {
@@ -480,6 +545,42 @@ func checkOpts(ctx context.Context, p *policy) (*cosign.CheckOpts, error) {
 	return &opts, nil
 }
 
+// tsaCertificates sets the certificates of the trusted timestamp authority
+// from the PEM encoded certificate chain in the file. The chain holds at most
+// one certificate of the timestamp authority, followed by the intermediate and
+// the root certificates.
+func tsaCertificates(ctx context.Context, file string, opts *cosign.CheckOpts) error {
+	content, err := afero.ReadFile(utils.FS(ctx), file)
+	if err != nil {
+		return fmt.Errorf("unable to read the timestamp authority certificate chain: %w", err)
+	}
+
+	certs, err := cryptoutils.UnmarshalCertificatesFromPEM(content)
+	if err != nil {
+		return fmt.Errorf("unable to parse the timestamp authority certificate chain: %w", err)
+	}
+
+	for _, c := range certs {
+		switch {
+		case !c.IsCA:
+			if opts.TSACertificate != nil {
+				return errors.New("the timestamp authority certificate chain must contain at most one timestamp authority certificate")
+			}
+			opts.TSACertificate = c
+		case c.CheckSignatureFrom(c) == nil:
+			opts.TSARootCertificates = append(opts.TSARootCertificates, c)
+		default:
+			opts.TSAIntermediateCertificates = append(opts.TSAIntermediateCertificates, c)
+		}
+	}
+
+	if len(opts.TSARootCertificates) == 0 {
+		return errors.New("the timestamp authority certificate chain contains no root certificate")
+	}
+
+	return nil
+}
+
 type signatureClient interface {
 	publicKeyFromKeyRef(context.Context, string) (sigstoreSig.Verifier, error)
 }
@@ -521,7 +622,7 @@ func signatureVerifier(ctx context.Context, p *policy) (sigstoreSig.Verifier, er
 
 	verifier, err := newSignatureClient(ctx).publicKeyFromKeyRef(ctx, publicKey)
 	if err != nil {
-		return nil, err
+		return nil, keyRefError(publicKey, err)
 	}
 	return verifier, nil
 }
@@ -539,9 +640,48 @@ func validateIdentity(identity cosign.Identity) error {
 			"certificate identity must be provided for keyless workflow"))
 	}
 
+	if _, err := regexp.Compile(identity.IssuerRegExp); err != nil {
+		errs = multierror.Append(errs, fmt.Errorf(
+			"invalid certificate OIDC issuer regular expression: %w", err))
+	}
+
+	if _, err := regexp.Compile(identity.SubjectRegExp); err != nil {
+		errs = multierror.Append(errs, fmt.Errorf(
+			"invalid certificate identity regular expression: %w", err))
+	}
+
 	return errs
 }
 
+// identities returns the identities cosign accepts the signing certificates
+// of. cosign only uses the regular expression when both the exact value and
+// the regular expression are given, here matching either of them suffices.
+func identities(identity cosign.Identity) []cosign.Identity {
+	issuers := []cosign.Identity{{Issuer: identity.Issuer, IssuerRegExp: identity.IssuerRegExp}}
+	if identity.Issuer != "" && identity.IssuerRegExp != "" {
+		issuers = []cosign.Identity{{Issuer: identity.Issuer}, {IssuerRegExp: identity.IssuerRegExp}}
+	}
+
+	subjects := []cosign.Identity{{Subject: identity.Subject, SubjectRegExp: identity.SubjectRegExp}}
+	if identity.Subject != "" && identity.SubjectRegExp != "" {
+		subjects = []cosign.Identity{{Subject: identity.Subject}, {SubjectRegExp: identity.SubjectRegExp}}
+	}
+
+	ids := make([]cosign.Identity, 0, len(issuers)*len(subjects))
+	for _, i := range issuers {
+		for _, s := range subjects {
+			ids = append(ids, cosign.Identity{
+				Issuer:        i.Issuer,
+				IssuerRegExp:  i.IssuerRegExp,
+				Subject:       s.Subject,
+				SubjectRegExp: s.SubjectRegExp,
+			})
+		}
+	}
+
+	return ids
+}
+
 func validatePolicyConfig(policyConfig string) error {
 	policySchema, err := jsonschema.CompileString("schema.json", ecc.Schema)
 	if err != nil {
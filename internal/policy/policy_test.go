@@ -22,7 +22,10 @@ import (
 	"context"
 	"crypto"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 	"time"
@@ -33,12 +36,15 @@ import (
 	"github.com/sigstore/cosign/v2/pkg/cosign"
 	cosignSig "github.com/sigstore/cosign/v2/pkg/signature"
 	sigstoreSig "github.com/sigstore/sigstore/pkg/signature"
+	"github.com/sigstore/sigstore/pkg/signature/kms"
+	"github.com/spf13/afero"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/yaml"
 
 	"github.com/enterprise-contract/ec-cli/internal/kubernetes"
+	"github.com/enterprise-contract/ec-cli/internal/signature"
 	"github.com/enterprise-contract/ec-cli/internal/utils"
 )
 
@@ -366,6 +372,14 @@ func TestCheckOpts(t *testing.T) {
 				Issuer: "my-issuer",
 			},
 		},
+		{
+			name: "keyless invalid subject regexp",
+			err:  "invalid certificate identity regular expression",
+			identity: cosign.Identity{
+				Issuer:        "my-issuer",
+				SubjectRegExp: "my-subject-(",
+			},
+		},
 		{
 			name:      "keyless missing issuer in ECP",
 			err:       "certificate OIDC issuer must be provided for keyless workflow",
@@ -438,6 +452,37 @@ func TestCheckOpts(t *testing.T) {
 	}
 }
 
+func TestTSACertificates(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	ctx := utils.WithFS(context.Background(), fs)
+
+	chain := signature.ParseSigstoreChainCert()
+	require.NoError(t, afero.WriteFile(fs, "chain.pem", append(append([]byte{}, signature.ChainguardReleaseCert...), signature.SigstoreChainCert...), 0644))
+	require.NoError(t, afero.WriteFile(fs, "roots.pem", signature.SigstoreChainCert, 0644))
+	require.NoError(t, afero.WriteFile(fs, "leaves.pem", append(append([]byte{}, signature.ChainguardReleaseCert...), signature.ChainguardReleaseCert...), 0644))
+	require.NoError(t, afero.WriteFile(fs, "leaf.pem", signature.ChainguardReleaseCert, 0644))
+	require.NoError(t, afero.WriteFile(fs, "invalid.pem", []byte("spam"), 0644))
+
+	opts := cosign.CheckOpts{}
+	require.NoError(t, tsaCertificates(ctx, "chain.pem", &opts))
+	assert.Equal(t, signature.ParseChainguardReleaseCert(), opts.TSACertificate)
+	assert.Equal(t, chain[:1], opts.TSAIntermediateCertificates)
+	assert.Equal(t, chain[1:], opts.TSARootCertificates)
+
+	opts = cosign.CheckOpts{}
+	require.NoError(t, tsaCertificates(ctx, "roots.pem", &opts))
+	assert.Nil(t, opts.TSACertificate)
+
+	for file, expected := range map[string]string{
+		"missing.pem": "unable to read the timestamp authority certificate chain",
+		"invalid.pem": "unable to parse the timestamp authority certificate chain",
+		"leaves.pem":  "at most one timestamp authority certificate",
+		"leaf.pem":    "contains no root certificate",
+	} {
+		assert.ErrorContains(t, tsaCertificates(ctx, file, &cosign.CheckOpts{}), expected, file)
+	}
+}
+
 func TestPublicKeyPEM(t *testing.T) {
 	cases := []struct {
 		name              string
@@ -565,6 +610,17 @@ func TestIdentity(t *testing.T) {
 	}
 }
 
+func TestIdentities(t *testing.T) {
+	assert.Equal(t, []cosign.Identity{{Issuer: "i", SubjectRegExp: "s.*"}}, identities(cosign.Identity{Issuer: "i", SubjectRegExp: "s.*"}))
+
+	assert.Equal(t, []cosign.Identity{
+		{Issuer: "i", Subject: "s"},
+		{Issuer: "i", SubjectRegExp: "s.*"},
+		{IssuerRegExp: "i.*", Subject: "s"},
+		{IssuerRegExp: "i.*", SubjectRegExp: "s.*"},
+	}, identities(cosign.Identity{Issuer: "i", IssuerRegExp: "i.*", Subject: "s", SubjectRegExp: "s.*"}))
+}
+
 func TestParseEffectiveTime(t *testing.T) {
 	_, err := parseEffectiveTime("")
 	assert.ErrorContains(t, err, "invalid policy time argument")
@@ -654,6 +710,31 @@ func TestEffectiveTimeAttestationAllowMutation(t *testing.T) {
 	assert.Equal(t, attestation, p.EffectiveTime())
 }
 
+func TestEffectiveTimeFor(t *testing.T) {
+	then := now
+	t.Cleanup(func() {
+		now = then
+	})
+
+	epoch := time.Unix(0, 0).UTC()
+	now = func() time.Time { return epoch }
+
+	p, err := NewOfflinePolicy(context.Background(), AtAttestation)
+	require.NoError(t, err)
+
+	// The attestation time set for another image is not used
+	p.AttestationTime(time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC))
+	attestation := time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, attestation, p.EffectiveTimeFor(&attestation))
+	assert.Equal(t, epoch, p.EffectiveTimeFor(nil))
+
+	given := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	p, err = NewOfflinePolicy(context.Background(), given.Format(time.RFC3339))
+	require.NoError(t, err)
+	assert.Equal(t, given, p.EffectiveTimeFor(&attestation))
+	assert.Equal(t, given, p.EffectiveTimeFor(nil))
+}
+
 func toJson(policy any) string {
 	newInline, err := json.Marshal(policy)
 	if err != nil {
@@ -819,3 +900,148 @@ func TestSigstoreOpts(t *testing.T) {
 		})
 	}
 }
+
+type failingCosignClient struct {
+	err error
+}
+
+func (c *failingCosignClient) publicKeyFromKeyRef(context.Context, string) (sigstoreSig.Verifier, error) {
+	return nil, c.err
+}
+
+func TestPublicKeyReferenceErrors(t *testing.T) {
+	cases := []struct {
+		name      string
+		publicKey string
+		err       error
+		expected  string
+	}{
+		{
+			name:      "file",
+			publicKey: "/path/to/cosign.pub",
+			err:       errors.New("open /path/to/cosign.pub: no such file or directory"),
+			expected:  "open /path/to/cosign.pub: no such file or directory",
+		},
+		{
+			name:      "kubernetes secret",
+			publicKey: "k8s://test/cosign-public-key",
+			err:       errors.New(`secrets "cosign-public-key" not found`),
+			expected:  `unable to resolve the public key k8s://test/cosign-public-key using the Kubernetes secret provider: secrets "cosign-public-key" not found`,
+		},
+		{
+			name:      "vault",
+			publicKey: "hashivault://my-key",
+			err:       errors.New("permission denied"),
+			expected:  "unable to resolve the public key hashivault://my-key using the HashiCorp Vault provider: permission denied",
+		},
+		{
+			name:      "unavailable kms provider",
+			publicKey: "awskms:///arn:aws:kms:us-east-1:111122223333:key/1234",
+			err:       fmt.Errorf("loading key: %w", &kms.ProviderNotFoundError{}),
+			expected:  "the AWS KMS provider, required for the public key awskms:///arn:aws:kms:us-east-1:111122223333:key/1234, is not available",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctx := withSignatureClient(context.Background(), &failingCosignClient{err: c.err})
+
+			_, err := NewPolicy(ctx, Options{
+				PublicKey:     c.publicKey,
+				EffectiveTime: Now,
+			})
+			assert.ErrorContains(t, err, c.expected)
+		})
+	}
+}
+
+func TestKMSProvidersRegistered(t *testing.T) {
+	for _, p := range keyRefProviders {
+		if p.kms {
+			assert.Contains(t, kms.SupportedProviders(), p.scheme)
+		}
+	}
+}
+
+func TestPublicKeyFromKMS(t *testing.T) {
+	vault := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/transit/keys/my-key" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"data": map[string]any{
+				"keys":           map[string]any{"1": map[string]any{"public_key": utils.TestPublicKey}},
+				"latest_version": 1,
+			},
+		})
+	}))
+	t.Cleanup(vault.Close)
+	t.Setenv("VAULT_ADDR", vault.URL)
+	t.Setenv("VAULT_TOKEN", "token")
+	utils.SetTestRekorPublicKey(t)
+
+	p, err := NewPolicy(context.Background(), Options{
+		PublicKey:     "hashivault://my-key",
+		EffectiveTime: Now,
+	})
+	require.NoError(t, err)
+
+	pem, err := p.PublicKeyPEM()
+	require.NoError(t, err)
+	assert.Equal(t, strings.TrimSpace(utils.TestPublicKey), strings.TrimSpace(string(pem)))
+}
+
+func TestDefaultSources(t *testing.T) {
+	t.Setenv("EC_DEFAULT_POLICY_SOURCES", "oci::registry.io/baseline:latest, git::https://git.io/baseline//policy")
+	t.Setenv("EC_DEFAULT_DATA_SOURCES", "git::https://git.io/baseline//data")
+
+	p, err := NewInertPolicy(context.Background(), `{"sources": [
+		{"name": "release", "policy": ["oci::registry.io/release:latest"], "data": ["oci::registry.io/release-data:latest"]},
+		{"name": "extra", "policy": ["oci::registry.io/extra:latest"], "data": ["git::https://git.io/baseline//data"]}
+	]}`)
+	require.NoError(t, err)
+	assert.Equal(t, []ecc.Source{
+		{
+			Name:   DefaultSourcesName,
+			Policy: []string{"oci::registry.io/baseline:latest", "git::https://git.io/baseline//policy"},
+			Data:   []string{"git::https://git.io/baseline//data"},
+		},
+		{
+			Name:   "release",
+			Policy: []string{"oci::registry.io/release:latest"},
+			Data:   []string{"oci::registry.io/release-data:latest", "git::https://git.io/baseline//data"},
+		},
+		{
+			Name:   "extra",
+			Policy: []string{"oci::registry.io/extra:latest"},
+			Data:   []string{"git::https://git.io/baseline//data"},
+		},
+	}, p.Spec().Sources)
+
+	// the default sources apply without a policy configuration
+	p, err = NewInertPolicy(context.Background(), "")
+	require.NoError(t, err)
+	assert.Len(t, p.Spec().Sources, 1)
+}
+
+func TestDefaultDataSourcesOnly(t *testing.T) {
+	t.Setenv("EC_DEFAULT_POLICY_SOURCES", "")
+	t.Setenv("EC_DEFAULT_DATA_SOURCES", "git::https://git.io/baseline//data")
+
+	p, err := NewInertPolicy(context.Background(), `{"sources": [{"name": "release", "policy": ["oci::registry.io/release:latest"]}]}`)
+	require.NoError(t, err)
+	assert.Equal(t, []ecc.Source{
+		{
+			Name:   "release",
+			Policy: []string{"oci::registry.io/release:latest"},
+			Data:   []string{"git::https://git.io/baseline//data"},
+		},
+	}, p.Spec().Sources)
+
+	// no source group is added for the default data sources alone
+	p, err = NewInertPolicy(context.Background(), "")
+	require.NoError(t, err)
+	assert.Empty(t, p.Spec().Sources)
+}
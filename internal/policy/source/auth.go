@@ -0,0 +1,227 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package source
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/bgentry/go-netrc/netrc"
+	getter "github.com/hashicorp/go-getter"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+// gitTokenUser is the user name sent along the token, accepted by GitLab and
+// GitHub for tokens
+const gitTokenUser = "oauth2"
+
+var gitTokenFile string
+
+// AddGitTokenFileFlag adds the flag giving the file holding the token used to
+// fetch the git sources over HTTPS.
+func AddGitTokenFileFlag(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringVar(&gitTokenFile, "git-token-file", "", "path to the file holding the token used to fetch git policy and data sources over HTTPS, ~/.netrc is used for the hosts listed in it")
+}
+
+// forcedPrefix matches the getter forced by the source URL, e.g. git::https://
+var forcedPrefix = regexp.MustCompile(`^([A-Za-z0-9]+)::(.+)$`)
+
+// forcedGetter splits the source URL into the forced getter, if any, and the
+// URL.
+func forcedGetter(src string) (string, string) {
+	if m := forcedPrefix.FindStringSubmatch(src); m != nil {
+		return m[1], m[2]
+	}
+
+	return "", src
+}
+
+// credentials are the user and the password, or token, for fetching a source
+// from the host. The credentials are never put in the URL of the source, where
+// they would show in the arguments of git and in the configuration of the
+// cloned repository. They are passed in the environment of the git processes
+// cloning the source, see cloneWithCredentials, and sent in the requests of
+// the sources fetched over HTTPS, see fetchThroughHTTPCache.
+type credentials struct {
+	host     string
+	user     string
+	password string
+	// git is set for git sources
+	git bool
+}
+
+// authorization is the value of the Authorization header sending the
+// credentials.
+func (c credentials) authorization() string {
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(c.user+":"+c.password))
+}
+
+// authenticate returns the credentials for the host of the source, if any.
+// The credentials are taken from the netrc file, or for git sources, from the
+// token file given by --git-token-file. There are no credentials for sources
+// fetched over other protocols than HTTPS, or with credentials already in the
+// URL.
+func authenticate(ctx context.Context, src string) (*credentials, error) {
+	detected, err := getter.Detect(src, ".", getter.Detectors)
+	if err != nil {
+		return nil, nil
+	}
+
+	forced, raw := forcedGetter(detected)
+	if forced != "" && forced != "git" {
+		return nil, nil
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme != "https" || u.User != nil {
+		return nil, nil
+	}
+
+	creds, err := netrcCredentials(utils.FS(ctx), u.Host)
+	if err != nil {
+		return nil, err
+	}
+
+	if creds == nil && forced == "git" && gitTokenFile != "" {
+		token, err := readGitToken(utils.FS(ctx), gitTokenFile)
+		if err != nil {
+			return nil, err
+		}
+		creds = &credentials{user: gitTokenUser, password: token}
+	}
+
+	if creds == nil {
+		return nil, nil
+	}
+
+	// Only plain files and archives are fetched over HTTPS with the
+	// credentials, see httpSource
+	if forced == "" && !httpSource(src) {
+		log.Warnf("Not using the credentials for source %s, credentials are only supported for git sources and for plain files and archives fetched over HTTPS", src)
+		return nil, nil
+	}
+
+	log.Debugf("Using credentials of user %s for source %s", creds.user, src)
+	creds.host = u.Host
+	creds.git = forced == "git"
+
+	return creds, nil
+}
+
+// netrcCredentials returns the credentials for the host from the netrc file
+// given by the NETRC environment variable, or ~/.netrc.
+func netrcCredentials(fs afero.Fs, host string) (*credentials, error) {
+	file := os.Getenv("NETRC")
+	if file == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, nil
+		}
+		file = filepath.Join(home, ".netrc")
+	}
+
+	content, err := afero.ReadFile(fs, file)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("unable to read the netrc file %s: %w", file, err)
+	}
+
+	n, err := netrc.Parse(bytes.NewReader(content))
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse the netrc file %s: %w", file, err)
+	}
+
+	m := n.FindMachine(host)
+	if m == nil {
+		return nil, nil
+	}
+
+	return &credentials{user: m.Login, password: m.Password}, nil
+}
+
+func readGitToken(fs afero.Fs, file string) (string, error) {
+	content, err := afero.ReadFile(fs, file)
+	if err != nil {
+		return "", fmt.Errorf("unable to read the git token file: %w", err)
+	}
+
+	token := strings.TrimSpace(string(content))
+	if token == "" {
+		return "", fmt.Errorf("the git token file %s is empty", file)
+	}
+
+	return token, nil
+}
+
+// authRequired lists the messages of git and of the HTTP clients when the
+// source requires credentials
+var authRequired = []string{
+	"Authentication failed",
+	"authentication required",
+	"could not read Username",
+	"terminal prompts disabled",
+	"401 Unauthorized",
+	"bad response code: 401",
+}
+
+// downloadError explains errors of sources requiring credentials when none
+// were found, and redacts the credentials from the error otherwise.
+func downloadError(src string, creds *credentials, err error) error {
+	if creds != nil {
+		return redactedError{err: err, secret: creds.password}
+	}
+
+	for _, m := range authRequired {
+		if strings.Contains(err.Error(), m) {
+			return fmt.Errorf("source %s requires authentication, but no credentials were found for it, provide them with --git-token-file or in ~/.netrc: %w", src, err)
+		}
+	}
+
+	return err
+}
+
+// redactedError hides the secret in the message of the error, e.g. when the
+// message includes a URL the credentials were given in
+type redactedError struct {
+	err    error
+	secret string
+}
+
+func (e redactedError) Error() string {
+	// The secret might be escaped in the URL
+	escaped := strings.TrimPrefix(url.UserPassword("", e.secret).String(), ":")
+
+	return strings.NewReplacer(e.secret, "***", escaped, "***").Replace(e.err.Error())
+}
+
+func (e redactedError) Unwrap() error {
+	return e.err
+}
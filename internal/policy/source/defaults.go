@@ -0,0 +1,78 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package source
+
+import (
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	// DefaultPolicySourcesEnv lists the policy sources, comma separated,
+	// evaluated in addition to the policy sources given otherwise
+	DefaultPolicySourcesEnv = "EC_DEFAULT_POLICY_SOURCES"
+	// DefaultDataSourcesEnv lists the data sources, comma separated, used in
+	// addition to the data sources given otherwise
+	DefaultDataSourcesEnv = "EC_DEFAULT_DATA_SOURCES"
+)
+
+var noDefaultSources bool
+
+// AddNoDefaultSourcesFlag adds the flag opting out of the default sources
+// given by the environment.
+func AddNoDefaultSourcesFlag(cmd *cobra.Command) {
+	cmd.PersistentFlags().BoolVar(&noDefaultSources, "no-default-sources", false, "ignore the default policy and data sources given by the "+DefaultPolicySourcesEnv+" and "+DefaultDataSourcesEnv+" environment variables")
+}
+
+// DefaultSources returns the policy and data source urls given by the
+// DefaultPolicySourcesEnv and DefaultDataSourcesEnv environment variables,
+// unless opted out with --no-default-sources.
+func DefaultSources() (policy []string, data []string) {
+	if noDefaultSources {
+		return nil, nil
+	}
+
+	return splitSources(os.Getenv(DefaultPolicySourcesEnv)), splitSources(os.Getenv(DefaultDataSourcesEnv))
+}
+
+// MergeSources appends the default source urls to the given source urls,
+// skipping any default already given. The given sources keep their order and
+// come first.
+func MergeSources(urls []string, defaults []string) []string {
+	merged := append([]string{}, urls...)
+	for _, d := range defaults {
+		if !slices.Contains(merged, d) {
+			merged = append(merged, d)
+		}
+	}
+
+	return merged
+}
+
+func splitSources(value string) []string {
+	var urls []string
+	for _, u := range strings.Split(value, ",") {
+		if u = strings.TrimSpace(u); u != "" {
+			urls = append(urls, u)
+		}
+	}
+
+	return urls
+}
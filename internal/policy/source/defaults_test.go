@@ -0,0 +1,50 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package source
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultSources(t *testing.T) {
+	policies, data := DefaultSources()
+	assert.Nil(t, policies)
+	assert.Nil(t, data)
+
+	t.Setenv(DefaultPolicySourcesEnv, "oci::registry.io/baseline:latest,, git::https://git.io/baseline//policy ")
+	t.Setenv(DefaultDataSourcesEnv, "git::https://git.io/baseline//data")
+
+	policies, data = DefaultSources()
+	assert.Equal(t, []string{"oci::registry.io/baseline:latest", "git::https://git.io/baseline//policy"}, policies)
+	assert.Equal(t, []string{"git::https://git.io/baseline//data"}, data)
+
+	noDefaultSources = true
+	t.Cleanup(func() { noDefaultSources = false })
+	policies, data = DefaultSources()
+	assert.Nil(t, policies)
+	assert.Nil(t, data)
+}
+
+func TestMergeSources(t *testing.T) {
+	assert.Equal(t, []string{"a", "b", "c"}, MergeSources([]string{"a", "b"}, []string{"b", "c"}))
+	assert.Equal(t, []string{"c"}, MergeSources(nil, []string{"c"}))
+	assert.Equal(t, []string{"a"}, MergeSources([]string{"a"}, nil))
+}
@@ -0,0 +1,280 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package source
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	ecc "github.com/enterprise-contract/enterprise-contract-controller/api/v1alpha1"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+)
+
+// contentDigests holds the content digest of each source url fetched through
+// the download cache, the content of the work directory is then fixed. Local
+// directories used in place can change at any time, their digests are only
+// known once copied, see snapshotLocalPolicy.
+var contentDigests sync.Map
+
+var sha256Digest = regexp.MustCompile(`^sha256:[0-9a-f]{64}$`)
+
+// contentDigest computes the digest of the files within the directory, the
+// SHA-256 of the sorted list of the relative path and the SHA-256 of the
+// content of each file. The .git directory is not part of the content.
+func contentDigest(fs afero.Fs, dir string) (string, error) {
+	var lines []string
+	err := afero.Walk(fs, dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if info.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		content, err := afero.ReadFile(fs, path)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		lines = append(lines, fmt.Sprintf("%x  %s\n", sha256.Sum256(content), filepath.ToSlash(rel)))
+
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	sort.Strings(lines)
+	h := sha256.New()
+	for _, l := range lines {
+		h.Write([]byte(l))
+	}
+
+	return fmt.Sprintf("sha256:%x", h.Sum(nil)), nil
+}
+
+// recordContentDigest computes and records the content digest of the source
+// fetched to dir.
+func recordContentDigest(fs afero.Fs, sourceUrl string, dir string) {
+	digest, err := contentDigest(fs, dir)
+	if err != nil {
+		log.Debugf("Unable to compute the content digest of %s: %v", sourceUrl, err)
+		return
+	}
+
+	log.Debugf("Content digest of %s is %s", sourceUrl, digest)
+	contentDigests.Store(sourceUrl, digest)
+}
+
+// ContentDigest returns the content digest of the given source url once it has
+// been fetched, or an empty string.
+func ContentDigest(sourceUrl string) string {
+	if d, ok := contentDigests.Load(sourceUrl); ok {
+		return d.(string)
+	}
+
+	return ""
+}
+
+// AggregateDigest returns the digest of the content digests of all the given
+// source urls, regardless of their order.
+func AggregateDigest(sourceUrls []string) (string, error) {
+	seen := map[string]bool{}
+	var lines []string
+	for _, u := range sourceUrls {
+		if seen[u] {
+			continue
+		}
+		seen[u] = true
+
+		digest := ContentDigest(u)
+		if digest == "" {
+			return "", fmt.Errorf("the content digest of the policy source %s is not known", u)
+		}
+		lines = append(lines, fmt.Sprintf("%s  %s\n", digest, u))
+	}
+
+	sort.Strings(lines)
+	h := sha256.New()
+	for _, l := range lines {
+		h.Write([]byte(l))
+	}
+
+	return fmt.Sprintf("sha256:%x", h.Sum(nil)), nil
+}
+
+// ExpectedDigests are the expected content digests of the policy sources.
+type ExpectedDigests struct {
+	// Aggregate is the expected digest of all policy sources, see
+	// AggregateDigest
+	Aggregate string
+	// Sources are the expected content digests by source url
+	Sources map[string]string
+}
+
+// ParseExpectedDigests parses the expected digests given either as <digest>,
+// the aggregate digest of all sources, or as <url>=<digest>, the digest of
+// the given source.
+func ParseExpectedDigests(values []string) (ExpectedDigests, error) {
+	expected := ExpectedDigests{Sources: map[string]string{}}
+	for _, v := range values {
+		sourceUrl, digest := "", v
+		// The url might contain =, e.g. in the query
+		if i := strings.LastIndex(v, "="); i != -1 {
+			sourceUrl, digest = v[:i], v[i+1:]
+		}
+
+		if !sha256Digest.MatchString(digest) {
+			return ExpectedDigests{}, fmt.Errorf("invalid expected policy digest %q, expecting sha256:<hex> or <url>=sha256:<hex>", v)
+		}
+
+		if sourceUrl == "" {
+			if expected.Aggregate != "" && expected.Aggregate != digest {
+				return ExpectedDigests{}, fmt.Errorf("conflicting expected policy digests %s and %s", expected.Aggregate, digest)
+			}
+			expected.Aggregate = digest
+			continue
+		}
+
+		if existing, ok := expected.Sources[sourceUrl]; ok && existing != digest {
+			return ExpectedDigests{}, fmt.Errorf("conflicting expected policy digests %s and %s of %s", existing, digest, sourceUrl)
+		}
+		expected.Sources[sourceUrl] = digest
+	}
+
+	return expected, nil
+}
+
+// Empty returns true if no digests are expected.
+func (e ExpectedDigests) Empty() bool {
+	return e.Aggregate == "" && len(e.Sources) == 0
+}
+
+// Check verifies that the fetched sources have the expected content digests,
+// the aggregate digest being computed over the given source urls. The actual
+// digests are reported on mismatch.
+func (e ExpectedDigests) Check(sourceUrls []string) error {
+	used := map[string]bool{}
+	for _, u := range sourceUrls {
+		used[u] = true
+	}
+
+	urls := make([]string, 0, len(e.Sources))
+	for u := range e.Sources {
+		urls = append(urls, u)
+	}
+	sort.Strings(urls)
+
+	for _, u := range urls {
+		if !used[u] {
+			return fmt.Errorf("the policy source %s with an expected digest is not a source of the policy", u)
+		}
+		actual := ContentDigest(u)
+		if actual == "" {
+			return fmt.Errorf("the content digest of the policy source %s is not known", u)
+		}
+		if actual != e.Sources[u] {
+			return fmt.Errorf("the content digest of the policy source %s is %s, expected %s", u, actual, e.Sources[u])
+		}
+	}
+
+	if e.Aggregate != "" {
+		actual, err := AggregateDigest(sourceUrls)
+		if err != nil {
+			return err
+		}
+		if actual != e.Aggregate {
+			return fmt.Errorf("the aggregate content digest of the policy sources is %s, expected %s", actual, e.Aggregate)
+		}
+	}
+
+	return nil
+}
+
+// Verify fetches the policy and data sources of the source groups into the
+// work directory and checks their content digests. The fetched sources are
+// cached, they are not fetched again when evaluating the policy.
+func (e ExpectedDigests) Verify(ctx context.Context, sourceGroups []ecc.Source, workDir string) error {
+	urls, err := fetchSources(ctx, sourceGroups, workDir)
+	if err != nil {
+		return err
+	}
+
+	return e.Check(urls)
+}
+
+// FetchAggregateDigest fetches the policy and data sources of the source
+// groups into the work directory and returns their aggregate content digest,
+// see AggregateDigest. The fetched sources are cached, they are not fetched
+// again when evaluating the policy.
+func FetchAggregateDigest(ctx context.Context, sourceGroups []ecc.Source, workDir string) (string, error) {
+	urls, err := fetchSources(ctx, sourceGroups, workDir)
+	if err != nil {
+		return "", err
+	}
+
+	return AggregateDigest(urls)
+}
+
+// fetchSources fetches the policy and data sources of the source groups,
+// returning their urls. Local directories are copied, so that the content
+// digested is the content evaluated.
+func fetchSources(ctx context.Context, sourceGroups []ecc.Source, workDir string) ([]string, error) {
+	ctx = withSnapshotLocal(ctx)
+
+	var sources []PolicySource
+	var urls []string
+	for _, g := range sourceGroups {
+		for _, u := range g.Policy {
+			sources = append(sources, &PolicyUrl{Url: u, Kind: PolicyKind})
+			urls = append(urls, u)
+		}
+		for _, u := range g.Data {
+			sources = append(sources, &PolicyUrl{Url: u, Kind: DataKind})
+			urls = append(urls, u)
+		}
+	}
+
+	dirs, err := GetPolicies(ctx, sources, workDir, false)
+	if err != nil {
+		return nil, err
+	}
+
+	// skipped sources have no content to digest
+	fetched := urls[:0]
+	for i, u := range urls {
+		if dirs[i] != "" {
+			fetched = append(fetched, u)
+		}
+	}
+
+	return fetched, nil
+}
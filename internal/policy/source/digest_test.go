@@ -0,0 +1,151 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package source
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	ecc "github.com/enterprise-contract/enterprise-contract-controller/api/v1alpha1"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+func TestContentDigest(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/a/policy/rules.rego", []byte("package a"), 0644))
+	require.NoError(t, afero.WriteFile(fs, "/b/policy/rules.rego", []byte("package a"), 0644))
+	require.NoError(t, afero.WriteFile(fs, "/b/.git/HEAD", []byte("ref: refs/heads/main"), 0644))
+	require.NoError(t, afero.WriteFile(fs, "/c/policy/rules.rego", []byte("package c"), 0644))
+	require.NoError(t, afero.WriteFile(fs, "/d/other/rules.rego", []byte("package a"), 0644))
+
+	a, err := contentDigest(fs, "/a")
+	require.NoError(t, err)
+	assert.Regexp(t, `^sha256:[0-9a-f]{64}$`, a)
+
+	b, err := contentDigest(fs, "/b")
+	require.NoError(t, err)
+	assert.Equal(t, a, b, "the .git directory is not part of the content")
+
+	c, err := contentDigest(fs, "/c")
+	require.NoError(t, err)
+	assert.NotEqual(t, a, c, "the content differs")
+
+	d, err := contentDigest(fs, "/d")
+	require.NoError(t, err)
+	assert.NotEqual(t, a, d, "the paths differ")
+}
+
+func TestParseExpectedDigests(t *testing.T) {
+	digest := "sha256:" + strings.Repeat("a", 64)
+	other := "sha256:" + strings.Repeat("b", 64)
+
+	expected, err := ParseExpectedDigests([]string{digest, "git::https://example.com/policy?ref=main=" + other})
+	require.NoError(t, err)
+	assert.Equal(t, ExpectedDigests{Aggregate: digest, Sources: map[string]string{"git::https://example.com/policy?ref=main": other}}, expected)
+	assert.False(t, expected.Empty())
+
+	expected, err = ParseExpectedDigests(nil)
+	require.NoError(t, err)
+	assert.True(t, expected.Empty())
+
+	_, err = ParseExpectedDigests([]string{"example.com/policy?ref=main"})
+	assert.EqualError(t, err, `invalid expected policy digest "example.com/policy?ref=main", expecting sha256:<hex> or <url>=sha256:<hex>`)
+
+	_, err = ParseExpectedDigests([]string{digest, other})
+	assert.EqualError(t, err, "conflicting expected policy digests "+digest+" and "+other)
+
+	_, err = ParseExpectedDigests([]string{"/policy=" + digest, "/policy=" + other})
+	assert.EqualError(t, err, "conflicting expected policy digests "+digest+" and "+other+" of /policy")
+}
+
+func TestVerifyExpectedDigests(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/policy/rules.rego", []byte("package a"), 0644))
+	require.NoError(t, afero.WriteFile(fs, "/data/data.yaml", []byte("a: 1"), 0644))
+
+	// The in-memory file system does not support symlinks, the local
+	// directories are copied instead
+	dl := mockDownloader{}
+	dl.On("Download", mock.Anything, mock.Anything, false).Return(nil)
+	ctx := utils.WithFS(usingDownloader(context.Background(), &dl), fs)
+
+	sources := []ecc.Source{{Policy: []string{"/policy"}, Data: []string{"/data"}}}
+	wrong := "sha256:" + strings.Repeat("0", 64)
+
+	err := ExpectedDigests{Sources: map[string]string{"/policy": wrong}}.Verify(ctx, sources, "/work")
+	require.Error(t, err)
+	actual := ContentDigest("/policy")
+	assert.Regexp(t, `^sha256:[0-9a-f]{64}$`, actual)
+	assert.EqualError(t, err, "the content digest of the policy source /policy is "+actual+", expected "+wrong)
+
+	assert.NoError(t, ExpectedDigests{Sources: map[string]string{"/policy": actual}}.Verify(ctx, sources, "/work"))
+
+	aggregate, err := AggregateDigest([]string{"/data", "/policy"})
+	require.NoError(t, err)
+	assert.NoError(t, ExpectedDigests{Aggregate: aggregate}.Verify(ctx, sources, "/work"), "the order of the sources does not matter")
+
+	err = ExpectedDigests{Aggregate: wrong}.Verify(ctx, sources, "/work")
+	assert.EqualError(t, err, "the aggregate content digest of the policy sources is "+aggregate+", expected "+wrong)
+
+	err = ExpectedDigests{Sources: map[string]string{"/other": wrong}}.Verify(ctx, sources, "/work")
+	assert.EqualError(t, err, "the policy source /other with an expected digest is not a source of the policy")
+}
+
+func TestVerifyExpectedDigestsLocalSnapshot(t *testing.T) {
+	t.Cleanup(ClearDownloadCache)
+	ctx := utils.WithFS(context.Background(), afero.NewOsFs())
+
+	policyDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(policyDir, "main.rego"), []byte("package main"), 0600))
+	sources := []ecc.Source{{Policy: []string{policyDir}}}
+
+	digest, err := FetchAggregateDigest(ctx, sources, t.TempDir())
+	require.NoError(t, err)
+	assert.NotEmpty(t, ContentDigest(policyDir))
+
+	// changes made after the digest was computed are not evaluated
+	require.NoError(t, os.WriteFile(filepath.Join(policyDir, "main.rego"), []byte("package changed"), 0600))
+
+	p := &PolicyUrl{Url: policyDir, Kind: PolicyKind}
+	dest, err := p.GetPolicy(ctx, t.TempDir(), false)
+	require.NoError(t, err)
+	content, err := os.ReadFile(filepath.Join(dest, "main.rego"))
+	require.NoError(t, err)
+	assert.Equal(t, "package main", string(content))
+
+	again, err := FetchAggregateDigest(ctx, sources, t.TempDir())
+	require.NoError(t, err)
+	assert.Equal(t, digest, again)
+
+	// once the cache is cleared, e.g. between the runs of --watch-policy, the
+	// changes are picked up
+	ClearDownloadCache()
+	assert.Empty(t, ContentDigest(policyDir))
+	changed, err := FetchAggregateDigest(ctx, sources, t.TempDir())
+	require.NoError(t, err)
+	assert.NotEqual(t, digest, changed)
+}
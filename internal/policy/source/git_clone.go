@@ -0,0 +1,134 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package source
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+
+	getter "github.com/hashicorp/go-getter"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+// runGit runs git with the entries added to the environment of ec. The
+// environment of ec is not changed, the entries are seen only by the git
+// process. Replaced in tests.
+var runGit = func(ctx context.Context, env []string, args ...string) error {
+	cmd := exec.CommandContext(ctx, "git", args...) /* #nosec */
+	cmd.Env = append(os.Environ(), env...)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git %s failed: %w: %s", args[0], err, out)
+	}
+
+	return nil
+}
+
+// gitCredentialsEnv returns the GIT_CONFIG_COUNT, GIT_CONFIG_KEY_<n> and
+// GIT_CONFIG_VALUE_<n> environment entries configuring git to send the
+// credentials in the Authorization header of the requests to the host. The
+// entries follow the configuration already given in the environment of ec,
+// if any.
+func gitCredentialsEnv(creds *credentials) ([]string, error) {
+	n := 0
+	if count := os.Getenv("GIT_CONFIG_COUNT"); count != "" {
+		var err error
+		if n, err = strconv.Atoi(count); err != nil {
+			return nil, fmt.Errorf("invalid GIT_CONFIG_COUNT %q: %w", count, err)
+		}
+	}
+
+	return []string{
+		fmt.Sprintf("GIT_CONFIG_KEY_%d=http.https://%s/.extraHeader", n, creds.host),
+		fmt.Sprintf("GIT_CONFIG_VALUE_%d=Authorization: %s", n, creds.authorization()),
+		fmt.Sprintf("GIT_CONFIG_COUNT=%d", n+1),
+	}, nil
+}
+
+// cloneWithCredentials clones the git source requiring credentials and copies
+// its content, or the content of its subdirectory, to dest. The source is
+// cloned here rather than by the downloader, so that the credentials are
+// given only to the git processes cloning it, see gitCredentialsEnv. The ref
+// and depth query parameters are supported as by the downloader.
+func cloneWithCredentials(ctx context.Context, source, dest string, creds *credentials) error {
+	detected, err := getter.Detect(source, ".", getter.Detectors)
+	if err != nil {
+		return err
+	}
+	_, raw := forcedGetter(detected)
+	repo, subdir := getter.SourceDirSubdir(raw)
+
+	u, err := url.Parse(repo)
+	if err != nil {
+		return err
+	}
+	q := u.Query()
+	ref := q.Get("ref")
+	depth := 0
+	if d := q.Get("depth"); d != "" {
+		if depth, err = strconv.Atoi(d); err != nil {
+			return fmt.Errorf("invalid depth %q of source %s: %w", d, source, err)
+		}
+	}
+	q.Del("ref")
+	q.Del("depth")
+	u.RawQuery = q.Encode()
+
+	env, err := gitCredentialsEnv(creds)
+	if err != nil {
+		return err
+	}
+
+	fs := utils.FS(ctx)
+	tmp, err := afero.TempDir(fs, "", "ec-git-")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = fs.RemoveAll(tmp)
+	}()
+
+	args := []string{"clone"}
+	if depth > 0 {
+		args = append(args, "--depth", strconv.Itoa(depth))
+		if ref != "" {
+			args = append(args, "--branch", ref)
+		}
+	}
+	args = append(args, "--", u.String(), tmp)
+
+	log.Debugf("Cloning %s with credentials to %s", u, tmp)
+	if err := runGit(ctx, env, args...); err != nil {
+		return err
+	}
+
+	// A shallow clone is already at the ref
+	if depth == 0 && ref != "" {
+		if err := runGit(ctx, env, "-C", tmp, "checkout", ref); err != nil {
+			return err
+		}
+	}
+
+	return copyDirectory(fs, filepath.Join(tmp, subdir), dest)
+}
@@ -0,0 +1,214 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package source
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+
+	getter "github.com/hashicorp/go-getter"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+const httpCacheKey key = 2
+
+const (
+	// httpCacheMetadataFile holds the validators of the cached copy of a source
+	httpCacheMetadataFile = "metadata.json"
+	// httpCacheContentDir holds the cached copy of a source, named after the
+	// base name of the URL path, e.g. policy.tar.gz
+	httpCacheContentDir = "content"
+)
+
+// WithHTTPCache returns a copy of the context instructing GetPolicy to keep a
+// copy of the sources fetched over HTTPS in the directory. Subsequent fetches
+// are conditional requests, using the ETag and the Last-Modified validators of
+// the cached copy, reusing it when the source has not been modified.
+func WithHTTPCache(ctx context.Context, dir string) context.Context {
+	return context.WithValue(ctx, httpCacheKey, dir)
+}
+
+func httpCacheDir(ctx context.Context) string {
+	dir, _ := ctx.Value(httpCacheKey).(string)
+	return dir
+}
+
+// httpCacheEntry describes the cached copy of a source
+type httpCacheEntry struct {
+	URL          string `json:"url"`
+	File         string `json:"file"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+}
+
+func (e httpCacheEntry) path(dir string) string {
+	return path.Join(dir, httpCacheContentDir, e.File)
+}
+
+// httpClient performs the requests of the sources fetched over HTTPS,
+// replaceable in tests
+var httpClient = http.DefaultClient
+
+// httpSource returns true if the source is fetched over HTTPS as a plain
+// file or archive. Sources with a forced getter, e.g. git::https://, or with
+// query parameters interpreted by the getter, e.g. archive= or checksum=, are
+// not.
+func httpSource(src string) bool {
+	detected, err := getter.Detect(src, ".", getter.Detectors)
+	if err != nil {
+		return false
+	}
+
+	if forced, _ := forcedGetter(detected); forced != "" {
+		return false
+	}
+
+	u, err := url.Parse(detected)
+	if err != nil {
+		return false
+	}
+
+	return u.Scheme == "https" && u.RawQuery == "" && path.Base(u.Path) != "/" && path.Base(u.Path) != "."
+}
+
+// fetchThroughHTTPCache fetches the source into the cache directory, using a
+// conditional request if a copy is cached, and returns the path of the cached
+// copy. The credentials, if any, are sent in the request.
+func fetchThroughHTTPCache(ctx context.Context, dir, source string, creds *credentials) (string, error) {
+	fs := utils.FS(ctx)
+	entryDir := path.Join(dir, fmt.Sprintf("%x", sha256.Sum256([]byte(source))))
+	entry := readHTTPCacheEntry(fs, entryDir)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, source, nil)
+	if err != nil {
+		return "", err
+	}
+	if creds != nil {
+		req.Header.Set("Authorization", creds.authorization())
+	}
+
+	if entry != nil {
+		if entry.ETag != "" {
+			req.Header.Set("If-None-Match", entry.ETag)
+		}
+		if entry.LastModified != "" {
+			req.Header.Set("If-Modified-Since", entry.LastModified)
+		}
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("unable to fetch %s: %w", source, err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && entry != nil:
+		log.Debugf("Source %s not modified, using the cached copy", source)
+		return entry.path(entryDir), nil
+	case resp.StatusCode != http.StatusOK:
+		return "", fmt.Errorf("unable to fetch %s: %s", source, resp.Status)
+	}
+
+	entry = &httpCacheEntry{
+		URL:          source,
+		File:         path.Base(req.URL.Path),
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}
+	if err := writeHTTPCacheEntry(fs, entryDir, entry, resp.Body); err != nil {
+		return "", fmt.Errorf("unable to cache %s: %w", source, err)
+	}
+	log.Debugf("Cached source %s, ETag: %q, Last-Modified: %q", source, entry.ETag, entry.LastModified)
+
+	return entry.path(entryDir), nil
+}
+
+// readHTTPCacheEntry returns the cached copy in the directory, or nil if there
+// is none.
+func readHTTPCacheEntry(fs afero.Fs, dir string) *httpCacheEntry {
+	content, err := afero.ReadFile(fs, path.Join(dir, httpCacheMetadataFile))
+	if err != nil {
+		return nil
+	}
+
+	var entry httpCacheEntry
+	if err := json.Unmarshal(content, &entry); err != nil {
+		log.Debugf("Ignoring the invalid HTTP cache entry in %s: %v", dir, err)
+		return nil
+	}
+
+	if entry.ETag == "" && entry.LastModified == "" {
+		return nil
+	}
+
+	if _, err := fs.Stat(entry.path(dir)); err != nil {
+		return nil
+	}
+
+	return &entry
+}
+
+// writeHTTPCacheEntry stores the content and the metadata of the cached copy.
+// The content is written to a temporary file first so that a partially
+// written copy is never used.
+func writeHTTPCacheEntry(fs afero.Fs, dir string, entry *httpCacheEntry, content io.Reader) error {
+	if err := fs.MkdirAll(path.Join(dir, httpCacheContentDir), 0755); err != nil {
+		return err
+	}
+
+	// The metadata is removed first, in case the content is not written
+	// completely
+	if err := fs.Remove(path.Join(dir, httpCacheMetadataFile)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	file := entry.path(dir)
+	tmp := file + ".tmp"
+	f, err := fs.Create(tmp)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, content); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := fs.Rename(tmp, file); err != nil {
+		return err
+	}
+
+	metadata, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	return afero.WriteFile(fs, path.Join(dir, httpCacheMetadataFile), metadata, 0644)
+}
@@ -0,0 +1,185 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package source
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+func TestHTTPSource(t *testing.T) {
+	cases := map[string]bool{
+		"https://example.com/policy.tar.gz":             true,
+		"https://example.com/policy/rules.rego":         true,
+		"https://example.com/policy.tar.gz?archive=zip": false,
+		"http://example.com/policy.tar.gz":              false,
+		"git::https://example.com/policy.git":           false,
+		"github.com/org/policy":                         false,
+		"oci::registry.io/policy:latest":                false,
+		"https://example.com/":                          false,
+		"./policy":                                      false,
+	}
+
+	for src, expected := range cases {
+		assert.Equal(t, expected, httpSource(src), src)
+	}
+}
+
+// policyServer serves the content with the ETag and Last-Modified validators,
+// recording the conditional request headers it receives
+type policyServer struct {
+	content      string
+	etag         string
+	lastModified string
+	requests     []http.Header
+}
+
+func (s *policyServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.requests = append(s.requests, r.Header.Clone())
+	if s.etag != "" && r.Header.Get("If-None-Match") == s.etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	if s.etag == "" && s.lastModified != "" && r.Header.Get("If-Modified-Since") == s.lastModified {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	if s.etag != "" {
+		w.Header().Set("ETag", s.etag)
+	}
+	if s.lastModified != "" {
+		w.Header().Set("Last-Modified", s.lastModified)
+	}
+	_, _ = w.Write([]byte(s.content))
+}
+
+func usingTestServer(t *testing.T, handler http.Handler) string {
+	server := httptest.NewTLSServer(handler)
+	t.Cleanup(server.Close)
+
+	client := httpClient
+	t.Cleanup(func() { httpClient = client })
+	httpClient = server.Client()
+
+	return server.URL
+}
+
+func TestFetchThroughHTTPCacheETag(t *testing.T) {
+	server := &policyServer{content: "v1", etag: `"v1"`}
+	source := usingTestServer(t, server) + "/policy.tar.gz"
+
+	fs := afero.NewMemMapFs()
+	ctx := utils.WithFS(context.Background(), fs)
+
+	cached, err := fetchThroughHTTPCache(ctx, "/cache", source, nil)
+	require.NoError(t, err)
+	assert.Regexp(t, `^/cache/[0-9a-f]{64}/content/policy.tar.gz$`, cached)
+	content, err := afero.ReadFile(fs, cached)
+	require.NoError(t, err)
+	assert.Equal(t, "v1", string(content))
+
+	// Not modified, the cached copy is reused
+	again, err := fetchThroughHTTPCache(ctx, "/cache", source, nil)
+	require.NoError(t, err)
+	assert.Equal(t, cached, again)
+	assert.Equal(t, `"v1"`, server.requests[1].Get("If-None-Match"))
+
+	// Modified, the cached copy is replaced
+	server.content, server.etag = "v2", `"v2"`
+	_, err = fetchThroughHTTPCache(ctx, "/cache", source, nil)
+	require.NoError(t, err)
+	content, err = afero.ReadFile(fs, cached)
+	require.NoError(t, err)
+	assert.Equal(t, "v2", string(content))
+
+	assert.Len(t, server.requests, 3)
+	assert.Empty(t, server.requests[0].Get("If-None-Match"))
+}
+
+func TestFetchThroughHTTPCacheLastModified(t *testing.T) {
+	server := &policyServer{content: "v1", lastModified: "Mon, 01 Jan 2024 00:00:00 GMT"}
+	source := usingTestServer(t, server) + "/rules.rego"
+
+	ctx := utils.WithFS(context.Background(), afero.NewMemMapFs())
+
+	cached, err := fetchThroughHTTPCache(ctx, "/cache", source, nil)
+	require.NoError(t, err)
+
+	again, err := fetchThroughHTTPCache(ctx, "/cache", source, nil)
+	require.NoError(t, err)
+	assert.Equal(t, cached, again)
+	assert.Equal(t, "Mon, 01 Jan 2024 00:00:00 GMT", server.requests[1].Get("If-Modified-Since"))
+	assert.Empty(t, server.requests[1].Get("If-None-Match"))
+}
+
+func TestFetchThroughHTTPCacheWithoutValidators(t *testing.T) {
+	server := &policyServer{content: "v1"}
+	source := usingTestServer(t, server) + "/rules.rego"
+
+	ctx := utils.WithFS(context.Background(), afero.NewMemMapFs())
+
+	_, err := fetchThroughHTTPCache(ctx, "/cache", source, nil)
+	require.NoError(t, err)
+	_, err = fetchThroughHTTPCache(ctx, "/cache", source, nil)
+	require.NoError(t, err)
+
+	// Without validators the requests are not conditional
+	assert.Empty(t, server.requests[1].Get("If-None-Match"))
+	assert.Empty(t, server.requests[1].Get("If-Modified-Since"))
+}
+
+func TestFetchThroughHTTPCacheError(t *testing.T) {
+	source := usingTestServer(t, http.NotFoundHandler()) + "/policy.tar.gz"
+
+	ctx := utils.WithFS(context.Background(), afero.NewMemMapFs())
+
+	_, err := fetchThroughHTTPCache(ctx, "/cache", source, nil)
+	assert.EqualError(t, err, "unable to fetch "+source+": 404 Not Found")
+}
+
+func TestGetPolicyThroughHTTPCache(t *testing.T) {
+	server := &policyServer{content: "v1", etag: `"v1"`}
+	source := usingTestServer(t, server) + "/cached-policy.tar.gz"
+
+	// The policy is downloaded from the cached copy
+	dl := mockDownloader{}
+	dl.On("Download", mock.Anything, mock.MatchedBy(func(src string) bool {
+		return regexp.MustCompile(`^/cache/[0-9a-f]{64}/content/cached-policy.tar.gz$`).MatchString(src)
+	}), false).Return(nil)
+
+	ctx := utils.WithFS(usingDownloader(context.Background(), &dl), afero.NewMemMapFs())
+	ctx = WithHTTPCache(ctx, "/cache")
+
+	p := PolicyUrl{Url: source, Kind: PolicyKind}
+	_, err := p.GetPolicy(ctx, "/tmp/ec-work-1234", false)
+	require.NoError(t, err)
+
+	mock.AssertExpectationsForObjects(t, &dl)
+	assert.Len(t, server.requests, 1)
+}
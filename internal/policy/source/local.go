@@ -0,0 +1,263 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package source
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+// localDirectory returns the absolute path of the given source url if it
+// refers to an existing directory on the local filesystem.
+func localDirectory(ctx context.Context, sourceUrl string) (string, bool) {
+	if !SourceIsFile(sourceUrl) {
+		return "", false
+	}
+
+	dir := strings.TrimPrefix(strings.TrimPrefix(sourceUrl, "file::"), "file://")
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", false
+	}
+
+	exists, err := afero.DirExists(utils.FS(ctx), dir)
+	if err != nil || !exists {
+		return "", false
+	}
+
+	return dir, true
+}
+
+// errRegoFound stops the walk on the first rego file found, afero.Walk does
+// not support filepath.SkipAll.
+var errRegoFound = errors.New("rego file found")
+
+// hasRegoFiles returns true if there is at least one rego file within the
+// given directory.
+func hasRegoFiles(fs afero.Fs, dir string) (bool, error) {
+	err := afero.Walk(fs, dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() && filepath.Ext(info.Name()) == ".rego" {
+			return errRegoFound
+		}
+		return nil
+	})
+	if errors.Is(err, errRegoFound) {
+		return true, nil
+	}
+
+	return false, err
+}
+
+// LocalPolicyDirectory returns the absolute path of the given source url if
+// it refers to an existing local directory containing rego files.
+func LocalPolicyDirectory(ctx context.Context, sourceUrl string) (string, bool) {
+	dir, ok := localDirectory(ctx, sourceUrl)
+	if !ok {
+		return "", false
+	}
+
+	if ok, err := hasRegoFiles(utils.FS(ctx), dir); err != nil || !ok {
+		return "", false
+	}
+
+	return dir, true
+}
+
+// getLocalPolicy makes the policy from the local directory available in the
+// work directory without copying it. The directory is symlinked in place so
+// that any change to its content is seen by subsequent evaluations.
+func getLocalPolicy(ctx context.Context, s PolicySource, workDir string, dir string, dl func(string, string) error) (string, error) {
+	fs := utils.FS(ctx)
+
+	if err := checkLocalDirectory(fs, s, dir); err != nil {
+		return "", err
+	}
+
+	dest := uniqueDestination(workDir, s.Subdir(), s.PolicyUrl())
+
+	symlinkableFS, ok := fs.(afero.Symlinker)
+	if !ok {
+		log.Debugf("Filesystem does not support symlinking: %q, downloading %s instead", fs.Name(), dir)
+		return dest, dl(s.PolicyUrl(), dest)
+	}
+
+	if err := fs.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return "", err
+	}
+
+	log.Debugf("Using the local directory %s in place, symlinked to %s", dir, dest)
+	if err := symlinkableFS.SymlinkIfPossible(dir, dest); err != nil {
+		return "", err
+	}
+
+	return dest, nil
+}
+
+// checkLocalDirectory verifies that a local policy directory contains at least
+// one rego file.
+func checkLocalDirectory(fs afero.Fs, s PolicySource, dir string) error {
+	if s.Subdir() != string(PolicyKind) {
+		return nil
+	}
+
+	if ok, err := hasRegoFiles(fs, dir); err != nil {
+		return err
+	} else if !ok {
+		return fmt.Errorf("no rego files found in the local policy directory %s", dir)
+	}
+
+	return nil
+}
+
+const snapshotLocalKey key = 4
+
+// withSnapshotLocal makes GetPolicy copy local directories into the work
+// directory instead of using them in place, see snapshotLocalPolicy.
+func withSnapshotLocal(ctx context.Context) context.Context {
+	return context.WithValue(ctx, snapshotLocalKey, true)
+}
+
+func snapshotLocal(ctx context.Context) bool {
+	snapshot, _ := ctx.Value(snapshotLocalKey).(bool)
+	return snapshot
+}
+
+// snapshotLocalPolicy copies the local directory into the work directory
+// through the download cache. Used when the content digest of the directory
+// is verified, the content digested is then the content evaluated, regardless
+// of any changes made to the local directory in the meantime. Subsequent
+// fetches use the same copy until the download cache is cleared.
+func snapshotLocalPolicy(ctx context.Context, s PolicySource, workDir string, dir string) (string, error) {
+	fs := utils.FS(ctx)
+
+	if err := checkLocalDirectory(fs, s, dir); err != nil {
+		return "", err
+	}
+
+	return getPolicyThroughCache(ctx, s, workDir, func(_ string, dest string) error {
+		log.Debugf("Copying the local directory %s to %s", dir, dest)
+		return copyDirectory(fs, dir, dest)
+	})
+}
+
+// copyDirectory copies the files within the directory to dest, excluding the
+// .git directory as it is not part of the content digest.
+func copyDirectory(fs afero.Fs, dir string, dest string) error {
+	return afero.Walk(fs, dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dest, rel)
+
+		if info.IsDir() {
+			if info.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return fs.MkdirAll(target, 0755)
+		}
+
+		content, err := afero.ReadFile(fs, path)
+		if err != nil {
+			return err
+		}
+
+		return afero.WriteFile(fs, target, content, 0400)
+	})
+}
+
+// fingerprint computes a digest over the names, sizes and modification times
+// of all files within the given directories.
+func fingerprint(fs afero.Fs, dirs []string) (string, error) {
+	h := sha256.New()
+	for _, dir := range dirs {
+		err := afero.Walk(fs, dir, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() {
+				return nil
+			}
+			fmt.Fprintf(h, "%s:%d:%d\n", path, info.Size(), info.ModTime().UnixNano())
+			return nil
+		})
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
+
+// WatchLocalSources blocks until the content of any of the given sources that
+// refer to a local directory changes, checking for changes on every interval.
+// An error is returned if none of the sources refers to a local directory, or
+// when the context is done.
+func WatchLocalSources(ctx context.Context, sources []PolicySource, interval time.Duration) error {
+	var dirs []string
+	for _, s := range sources {
+		if dir, ok := localDirectory(ctx, s.PolicyUrl()); ok {
+			dirs = append(dirs, dir)
+		}
+	}
+
+	if len(dirs) == 0 {
+		return errors.New("none of the policy sources refer to a local directory that can be watched")
+	}
+
+	fs := utils.FS(ctx)
+	initial, err := fingerprint(fs, dirs)
+	if err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			current, err := fingerprint(fs, dirs)
+			if err != nil {
+				return err
+			}
+			if current != initial {
+				return nil
+			}
+		}
+	}
+}
@@ -0,0 +1,141 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package source
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+func TestGetLocalPolicy(t *testing.T) {
+	fs := afero.NewOsFs()
+	ctx := utils.WithFS(context.Background(), fs)
+
+	policyDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(policyDir, "main.rego"), []byte("package main"), 0600))
+	workDir := t.TempDir()
+
+	// no downloader is configured in the context, the directory must be used in place
+	p := &PolicyUrl{Url: policyDir, Kind: PolicyKind}
+	dest, err := p.GetPolicy(ctx, workDir, false)
+	require.NoError(t, err)
+
+	target, err := os.Readlink(dest)
+	require.NoError(t, err)
+	assert.Equal(t, policyDir, target)
+
+	// changes are visible through the work directory without fetching again
+	require.NoError(t, os.WriteFile(filepath.Join(policyDir, "other.rego"), []byte("package other"), 0600))
+	assert.FileExists(t, filepath.Join(dest, "other.rego"))
+}
+
+func TestGetLocalPolicyWithoutRego(t *testing.T) {
+	fs := afero.NewOsFs()
+	ctx := utils.WithFS(context.Background(), fs)
+
+	policyDir := t.TempDir()
+
+	p := &PolicyUrl{Url: policyDir, Kind: PolicyKind}
+	_, err := p.GetPolicy(ctx, t.TempDir(), false)
+	assert.EqualError(t, err, "no rego files found in the local policy directory "+policyDir)
+
+	// data directories do not need to contain rego files
+	d := &PolicyUrl{Url: policyDir, Kind: DataKind}
+	_, err = d.GetPolicy(ctx, t.TempDir(), false)
+	assert.NoError(t, err)
+}
+
+func TestLocalPolicyDirectory(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	ctx := utils.WithFS(context.Background(), fs)
+
+	require.NoError(t, afero.WriteFile(fs, "/policy/lib/main.rego", []byte("package main"), 0400))
+	require.NoError(t, afero.WriteFile(fs, "/config/policy.yaml", []byte("sources: []"), 0400))
+
+	dir, ok := LocalPolicyDirectory(ctx, "/policy")
+	assert.True(t, ok)
+	assert.Equal(t, "/policy", dir)
+
+	dir, ok = LocalPolicyDirectory(ctx, "file::/policy")
+	assert.True(t, ok)
+	assert.Equal(t, "/policy", dir)
+
+	_, ok = LocalPolicyDirectory(ctx, "/config")
+	assert.False(t, ok)
+
+	_, ok = LocalPolicyDirectory(ctx, "/missing")
+	assert.False(t, ok)
+
+	_, ok = LocalPolicyDirectory(ctx, "github.com/org/repo")
+	assert.False(t, ok)
+}
+
+func TestWatchLocalSources(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	ctx := utils.WithFS(context.Background(), fs)
+
+	require.NoError(t, afero.WriteFile(fs, "/policy/main.rego", []byte("package main"), 0600))
+	sources := []PolicySource{
+		&PolicyUrl{Url: "github.com/org/repo", Kind: PolicyKind},
+		&PolicyUrl{Url: "/policy", Kind: PolicyKind},
+	}
+
+	changed := make(chan error)
+	go func() {
+		changed <- WatchLocalSources(ctx, sources, 10*time.Millisecond)
+	}()
+
+	// give the watch a chance to compute the initial state
+	time.Sleep(50 * time.Millisecond)
+	require.NoError(t, afero.WriteFile(fs, "/policy/other.rego", []byte("package other"), 0600))
+
+	select {
+	case err := <-changed:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("change was not detected")
+	}
+}
+
+func TestWatchLocalSourcesCancelled(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	ctx, cancel := context.WithCancel(utils.WithFS(context.Background(), fs))
+
+	require.NoError(t, afero.WriteFile(fs, "/policy/main.rego", []byte("package main"), 0600))
+	cancel()
+
+	err := WatchLocalSources(ctx, []PolicySource{&PolicyUrl{Url: "/policy", Kind: PolicyKind}}, time.Millisecond)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestWatchLocalSourcesWithoutLocal(t *testing.T) {
+	ctx := utils.WithFS(context.Background(), afero.NewMemMapFs())
+
+	err := WatchLocalSources(ctx, []PolicySource{&PolicyUrl{Url: "github.com/org/repo", Kind: PolicyKind}}, time.Millisecond)
+	assert.EqualError(t, err, "none of the policy sources refer to a local directory that can be watched")
+}
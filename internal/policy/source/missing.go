@@ -0,0 +1,146 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package source
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+)
+
+const (
+	// MissingSourceError fails when a source cannot be fetched, the default
+	MissingSourceError = "error"
+	// MissingSourceWarn excludes the sources that do not exist or are not
+	// reachable from the evaluation, logging a warning instead
+	MissingSourceWarn = "warn"
+
+	missingSourceKey key = 1
+)
+
+// MissingSourceModes are the accepted values of the missing source mode.
+var MissingSourceModes = []string{MissingSourceError, MissingSourceWarn}
+
+// SkippedSource is a source that could not be fetched and was excluded from
+// the evaluation.
+type SkippedSource struct {
+	Url    string `json:"url"`
+	Reason string `json:"reason"`
+}
+
+// skippedSources holds the error of each source url that was skipped.
+var skippedSources sync.Map
+
+// ValidateMissingSourceMode returns an error if the mode is not one of the
+// MissingSourceModes.
+func ValidateMissingSourceMode(mode string) error {
+	if slices.Contains(MissingSourceModes, mode) {
+		return nil
+	}
+
+	return fmt.Errorf("invalid missing source mode %q, expecting one of: %s", mode, strings.Join(MissingSourceModes, ", "))
+}
+
+// WithMissingSources sets how the sources that cannot be fetched are handled,
+// one of the MissingSourceModes.
+func WithMissingSources(ctx context.Context, mode string) context.Context {
+	return context.WithValue(ctx, missingSourceKey, mode)
+}
+
+func missingSourcesWarn(ctx context.Context) bool {
+	mode, _ := ctx.Value(missingSourceKey).(string)
+	return mode == MissingSourceWarn
+}
+
+// missingSourceMessages are the messages of the errors of the downloaders
+// reporting a source that does not exist, or a host that is not reachable.
+var missingSourceMessages = []string{
+	"404 not found",
+	"bad response code: 404",
+	"could not resolve host",
+	"connection refused",
+	"no such host",
+	"repository not found",
+	"' not found",
+	"does not exist",
+}
+
+// isMissingSource returns true if the source could not be fetched because it
+// does not exist, or its host is not reachable. Other errors, e.g. failing to
+// authenticate, are not degraded to warnings.
+func isMissingSource(err error) bool {
+	if errors.Is(err, os.ErrNotExist) {
+		return true
+	}
+
+	var dnsErr *net.DNSError
+	var opErr *net.OpError
+	if errors.As(err, &dnsErr) || errors.As(err, &opErr) {
+		return true
+	}
+
+	var transportErr *transport.Error
+	if errors.As(err, &transportErr) {
+		return transportErr.StatusCode == http.StatusNotFound
+	}
+
+	msg := strings.ToLower(err.Error())
+	for _, m := range missingSourceMessages {
+		if strings.Contains(msg, m) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ResetSkippedSources forgets the sources skipped so far, e.g. between the
+// runs of --watch-policy.
+func ResetSkippedSources() {
+	skippedSources.Range(func(key, _ any) bool {
+		skippedSources.Delete(key)
+		return true
+	})
+}
+
+func recordSkippedSource(sourceUrl string, err error) {
+	skippedSources.Store(sourceUrl, err.Error())
+}
+
+// SkippedSources returns the sources that were excluded from the evaluation
+// because they could not be fetched, sorted by url.
+func SkippedSources() []SkippedSource {
+	var skipped []SkippedSource
+	skippedSources.Range(func(k, v any) bool {
+		skipped = append(skipped, SkippedSource{Url: k.(string), Reason: v.(string)})
+		return true
+	})
+	sort.Slice(skipped, func(i, j int) bool {
+		return skipped[i].Url < skipped[j].Url
+	})
+
+	return skipped
+}
@@ -0,0 +1,81 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package source
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/go-git/go-git/v5"
+	log "github.com/sirupsen/logrus"
+)
+
+// revisions holds the resolved revision of each downloaded source url.
+var revisions sync.Map
+
+var commitSHA = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+// pinnedRevision returns the revision the source url is pinned to, either a
+// git commit SHA provided via the ref query parameter, or an OCI digest.
+func pinnedRevision(sourceUrl string) string {
+	if i := strings.LastIndex(sourceUrl, "@sha256:"); i != -1 {
+		return sourceUrl[i+1:]
+	}
+
+	if i := strings.Index(sourceUrl, "?"); i != -1 {
+		if q, err := url.ParseQuery(sourceUrl[i+1:]); err == nil && commitSHA.MatchString(q.Get("ref")) {
+			return q.Get("ref")
+		}
+	}
+
+	return ""
+}
+
+// recordRevision determines the revision of the source downloaded to dest,
+// preferring the revision the url is pinned to, and falls back to the HEAD
+// commit of the git repository in dest, if any.
+func recordRevision(sourceUrl string, dest string) {
+	revision := pinnedRevision(sourceUrl)
+
+	if revision == "" && SourceIsGit(sourceUrl) {
+		if r, err := git.PlainOpen(dest); err == nil {
+			if head, err := r.Head(); err == nil {
+				revision = head.Hash().String()
+			}
+		}
+	}
+
+	if revision == "" {
+		log.Debugf("Unable to determine the revision of %s", sourceUrl)
+		return
+	}
+
+	revisions.Store(sourceUrl, revision)
+}
+
+// Revision returns the resolved revision, i.e. git commit SHA or OCI digest,
+// of the given source url once it has been downloaded. An empty string is
+// returned if the revision could not be determined.
+func Revision(sourceUrl string) string {
+	if r, ok := revisions.Load(sourceUrl); ok {
+		return r.(string)
+	}
+
+	return pinnedRevision(sourceUrl)
+}
@@ -32,10 +32,13 @@ import (
 	"time"
 
 	ecc "github.com/enterprise-contract/enterprise-contract-controller/api/v1alpha1"
+	"github.com/hashicorp/go-multierror"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/afero"
 
 	"github.com/enterprise-contract/ec-cli/internal/downloader"
+	"github.com/enterprise-contract/ec-cli/internal/policy/builtin"
+	"github.com/enterprise-contract/ec-cli/internal/telemetry"
 	"github.com/enterprise-contract/ec-cli/internal/utils"
 )
 
@@ -73,6 +76,21 @@ type PolicyUrl struct {
 // downloadCache is a concurrent map used to cache downloaded files.
 var downloadCache sync.Map
 
+// ClearDownloadCache forgets the sources downloaded so far, and their content
+// digests. The sources are downloaded to the work directories of the
+// evaluators, once those are destroyed, e.g. between the runs of
+// --watch-policy, the sources need to be downloaded again.
+func ClearDownloadCache() {
+	downloadCache.Range(func(key, _ any) bool {
+		downloadCache.Delete(key)
+		return true
+	})
+	contentDigests.Range(func(key, _ any) bool {
+		contentDigests.Delete(key)
+		return true
+	})
+}
+
 func getPolicyThroughCache(ctx context.Context, s PolicySource, workDir string, dl func(string, string) error) (string, error) {
 	sourceUrl := s.PolicyUrl()
 	dest := uniqueDestination(workDir, s.Subdir(), sourceUrl)
@@ -84,7 +102,12 @@ func getPolicyThroughCache(ctx context.Context, s PolicySource, workDir string,
 		log.Debugf("Download cache miss: %s", sourceUrl)
 		// Checkout policy repo into work directory.
 		log.Debugf("Downloading policy files from source url %s to destination %s", sourceUrl, dest)
-		return dest, dl(sourceUrl, dest)
+		if err := dl(sourceUrl, dest); err != nil {
+			return dest, err
+		}
+		recordRevision(sourceUrl, dest)
+		recordContentDigest(utils.FS(ctx), sourceUrl, dest)
+		return dest, nil
 	}))
 
 	d, err := dfn.(func() (string, error))()
@@ -121,11 +144,74 @@ func getPolicyThroughCache(ctx context.Context, s PolicySource, workDir string,
 // GetPolicies clones the repository for a given PolicyUrl
 func (p *PolicyUrl) GetPolicy(ctx context.Context, workDir string, showMsg bool) (string, error) {
 	dl := func(source string, dest string) error {
+		creds, err := authenticate(ctx, source)
+		if err != nil {
+			return err
+		}
+
+		fetched := source
+		switch dir := httpCacheDir(ctx); {
+		case creds != nil && creds.git:
+			if err := cloneWithCredentials(ctx, source, dest, creds); err != nil {
+				return downloadError(source, creds, err)
+			}
+
+			return nil
+		case creds != nil || (dir != "" && httpSource(source)):
+			// Sources fetched over HTTPS are kept in the HTTP cache, if
+			// enabled, and downloaded from the cached copy. Sources requiring
+			// credentials are always fetched here, sending the credentials
+			// in the request.
+			if dir == "" {
+				fs := utils.FS(ctx)
+				tmp, err := afero.TempDir(fs, "", "ec-source-")
+				if err != nil {
+					return err
+				}
+				defer func() {
+					_ = fs.RemoveAll(tmp)
+				}()
+				dir = tmp
+			}
+			cached, err := fetchThroughHTTPCache(ctx, dir, source, creds)
+			if err != nil {
+				return downloadError(source, creds, err)
+			}
+			fetched = cached
+		}
+
 		x := ctx.Value(DownloaderFuncKey)
 		if dl, ok := x.(downloaderFunc); ok {
-			return dl.Download(ctx, dest, source, showMsg)
+			err = dl.Download(ctx, dest, fetched, showMsg)
+		} else {
+			err = downloader.Download(ctx, dest, fetched, showMsg)
+		}
+		if err != nil {
+			return downloadError(source, creds, err)
 		}
-		return downloader.Download(ctx, dest, source, showMsg)
+
+		return nil
+	}
+
+	// The embedded policy bundle is extracted, no download is needed
+	if p.Url == builtin.Name {
+		dest := uniqueDestination(workDir, p.Subdir(), p.Url)
+		log.Debugf("Extracting the embedded policy bundle to %s", dest)
+		if err := builtin.Extract(utils.FS(ctx), string(p.Kind), dest); err != nil {
+			return "", err
+		}
+		recordContentDigest(utils.FS(ctx), p.Url, dest)
+		return dest, nil
+	}
+
+	// A local directory is used in place, bypassing the download cache, so
+	// that changes to its content are always picked up. Unless its content
+	// digest is verified, then the copy digested is used.
+	if dir, ok := localDirectory(ctx, p.Url); ok {
+		if _, snapshot := downloadCache.Load(p.Url); snapshot || snapshotLocal(ctx) {
+			return snapshotLocalPolicy(ctx, p, workDir, dir)
+		}
+		return getLocalPolicy(ctx, p, workDir, dir, dl)
 	}
 
 	return getPolicyThroughCache(ctx, p, workDir, dl)
@@ -203,3 +289,90 @@ func FetchPolicySources(s ecc.Source) ([]PolicySource, error) {
 
 	return policySources, nil
 }
+
+// DefaultFetchConcurrency bounds the number of policy sources fetched at the
+// same time, unless set with WithFetchConcurrency.
+const DefaultFetchConcurrency = 4
+
+const fetchConcurrencyKey key = 3
+
+// WithFetchConcurrency bounds the number of policy sources fetched at the same
+// time by GetPolicies.
+func WithFetchConcurrency(ctx context.Context, n int) context.Context {
+	return context.WithValue(ctx, fetchConcurrencyKey, n)
+}
+
+func fetchConcurrency(ctx context.Context) int {
+	if n, ok := ctx.Value(fetchConcurrencyKey).(int); ok && n > 0 {
+		return n
+	}
+
+	return DefaultFetchConcurrency
+}
+
+// GetPolicies fetches all the given policy sources concurrently into the work
+// directory. The returned directories are in the same order as the sources,
+// regardless of the order the fetches complete in. Errors from all sources
+// that could not be fetched are aggregated, unless the missing sources are
+// only warned about, see WithMissingSources, in which case the directory of
+// each such source is empty.
+func GetPolicies(ctx context.Context, sources []PolicySource, workDir string, showMsg bool) (dirs []string, err error) {
+	ctx, span := telemetry.Start(ctx, "fetch sources", telemetry.PolicySources.Int(len(sources)))
+	defer func() { telemetry.End(span, telemetry.OutcomeSuccess, err) }()
+
+	type result struct {
+		index int
+		dir   string
+		err   error
+	}
+
+	jobs := make(chan int, len(sources))
+	results := make(chan result, len(sources))
+
+	numWorkers := min(fetchConcurrency(ctx), len(sources))
+	for i := 0; i < numWorkers; i++ {
+		go func() {
+			for j := range jobs {
+				s := sources[j]
+				dir, err := s.GetPolicy(ctx, workDir, showMsg)
+				if err != nil {
+					log.Debugf("Unable to download source from %s!", s.PolicyUrl())
+				}
+				results <- result{index: j, dir: dir, err: err}
+			}
+		}()
+	}
+
+	for i := range sources {
+		jobs <- i
+	}
+	close(jobs)
+
+	dirs = make([]string, len(sources))
+	errs := make([]error, len(sources))
+	for range sources {
+		r := <-results
+		dirs[r.index] = r.dir
+		errs[r.index] = r.err
+	}
+
+	warn := missingSourcesWarn(ctx)
+	var allErrors error
+	for i, err := range errs {
+		if err == nil {
+			continue
+		}
+		if warn && isMissingSource(err) {
+			log.Warnf("Skipping the source %s, it could not be fetched: %v", sources[i].PolicyUrl(), err)
+			recordSkippedSource(sources[i].PolicyUrl(), err)
+			dirs[i] = ""
+			continue
+		}
+		allErrors = multierror.Append(allErrors, err)
+	}
+	if allErrors != nil {
+		return nil, allErrors
+	}
+
+	return dirs, nil
+}
@@ -21,13 +21,19 @@ package source
 import (
 	"context"
 	"errors"
+	"fmt"
+	"net"
+	"net/http"
 	"os"
 	"path"
 	"path/filepath"
 	"regexp"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	ecc "github.com/enterprise-contract/enterprise-contract-controller/api/v1alpha1"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
 	"github.com/spf13/afero"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -103,6 +109,18 @@ func TestGetPolicy(t *testing.T) {
 	}
 }
 
+func TestGetBuiltinPolicy(t *testing.T) {
+	p := PolicyUrl{Url: "builtin", Kind: PolicyKind}
+
+	// Nothing is downloaded, the mock downloader fails on any call
+	dl := mockDownloader{}
+	ctx := utils.WithFS(usingDownloader(context.Background(), &dl), afero.NewMemMapFs())
+
+	_, err := p.GetPolicy(ctx, "/tmp/ec-work-1234", false)
+	assert.EqualError(t, err, "no policy is embedded in this build of ec")
+	mock.AssertExpectationsForObjects(t, &dl)
+}
+
 func TestInlineDataSource(t *testing.T) {
 	s := InlineData([]byte("some data"))
 
@@ -199,11 +217,7 @@ func (mockPolicySource) Subdir() string {
 
 func TestGetPolicyThroughCache(t *testing.T) {
 	test := func(t *testing.T, fs afero.Fs, expectedDownloads int) {
-		downloadCache.Range(func(key, _ any) bool {
-			downloadCache.Delete(key)
-
-			return true
-		})
+		ClearDownloadCache()
 
 		ctx := utils.WithFS(context.Background(), fs)
 
@@ -257,3 +271,200 @@ func TestGetPolicyThroughCache(t *testing.T) {
 		test(t, afero.NewMemMapFs(), 2)
 	})
 }
+
+func TestClearDownloadCache(t *testing.T) {
+	ClearDownloadCache()
+
+	fs := afero.NewMemMapFs()
+	ctx := utils.WithFS(context.Background(), fs)
+
+	invocations := 0
+	dl := func(source, dest string) error {
+		invocations++
+		return fs.MkdirAll(dest, 0755)
+	}
+
+	s1, err := getPolicyThroughCache(ctx, &mockPolicySource{}, "/workdir1", dl)
+	require.NoError(t, err)
+	require.NoError(t, fs.RemoveAll("/workdir1"))
+
+	// Once the work directory is removed the source is downloaded again
+	ClearDownloadCache()
+	s2, err := getPolicyThroughCache(ctx, &mockPolicySource{}, "/workdir2", dl)
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, invocations)
+	assert.NotEqual(t, s1, s2)
+	exists, err := afero.DirExists(fs, s2)
+	require.NoError(t, err)
+	assert.True(t, exists)
+}
+
+func TestRevision(t *testing.T) {
+	sha := "4f2f9a0b6b3c8d1e5a7f9c2b4d6e8f0a1b3c5d7e"
+
+	assert.Equal(t, sha, Revision("github.com/org/repo//policy?ref="+sha))
+	assert.Equal(t, "sha256:4a2c", Revision("quay.io/org/policy:latest@sha256:4a2c"))
+	assert.Equal(t, "", Revision("github.com/org/repo//policy?ref=main"))
+	assert.Equal(t, "", Revision("quay.io/org/policy:latest"))
+
+	recordRevision("github.com/org/recorded", t.TempDir())
+	assert.Equal(t, "", Revision("github.com/org/recorded"))
+
+	revisions.Store("github.com/org/recorded", sha)
+	t.Cleanup(func() { revisions.Delete("github.com/org/recorded") })
+	assert.Equal(t, sha, Revision("github.com/org/recorded"))
+}
+
+func TestGetPolicies(t *testing.T) {
+	urls := []string{
+		"https://example.com/user/concurrent-1.git",
+		"https://example.com/user/concurrent-2.git",
+		"https://example.com/user/concurrent-3.git",
+		"https://example.com/user/concurrent-4.git",
+		"https://example.com/user/concurrent-5.git",
+		"https://example.com/user/concurrent-6.git",
+	}
+
+	dl := mockDownloader{}
+	sources := make([]PolicySource, 0, len(urls))
+	for _, u := range urls {
+		sources = append(sources, &PolicyUrl{Url: u, Kind: PolicyKind})
+		dl.On("Download", mock.Anything, u, false).Return(nil)
+	}
+
+	ctx := usingDownloader(utils.WithFS(context.Background(), afero.NewMemMapFs()), &dl)
+	dirs, err := GetPolicies(ctx, sources, "/tmp/ec-work-1234", false)
+	require.NoError(t, err)
+	require.Len(t, dirs, len(urls))
+
+	// the directories are in the order of the sources
+	for i, call := range dl.Calls {
+		dest := call.Arguments.Get(0).(string)
+		url := call.Arguments.Get(1).(string)
+		for j, u := range urls {
+			if u == url {
+				assert.Equal(t, dirs[j], dest, "call %d", i)
+			}
+		}
+	}
+
+	mock.AssertExpectationsForObjects(t, &dl)
+}
+
+func TestGetPoliciesFetchConcurrency(t *testing.T) {
+	var running, maxRunning atomic.Int32
+	dl := mockDownloader{}
+	sources := make([]PolicySource, 0, 3)
+	for i := 1; i <= 3; i++ {
+		u := fmt.Sprintf("https://example.com/user/serial-%d.git", i)
+		sources = append(sources, &PolicyUrl{Url: u, Kind: PolicyKind})
+		dl.On("Download", mock.Anything, u, false).Return(nil).Run(func(mock.Arguments) {
+			n := running.Add(1)
+			defer running.Add(-1)
+			for m := maxRunning.Load(); n > m && !maxRunning.CompareAndSwap(m, n); m = maxRunning.Load() {
+			}
+			time.Sleep(10 * time.Millisecond)
+		})
+	}
+
+	ctx := usingDownloader(utils.WithFS(context.Background(), afero.NewMemMapFs()), &dl)
+	ctx = WithFetchConcurrency(ctx, 1)
+	_, err := GetPolicies(ctx, sources, "/tmp/ec-work-1234", false)
+	require.NoError(t, err)
+
+	assert.Equal(t, int32(1), maxRunning.Load())
+	mock.AssertExpectationsForObjects(t, &dl)
+}
+
+func TestGetPoliciesAggregatesErrors(t *testing.T) {
+	dl := mockDownloader{}
+	dl.On("Download", mock.Anything, "https://example.com/user/unreachable-1.git", false).Return(errors.New("unreachable 1"))
+	dl.On("Download", mock.Anything, "https://example.com/user/reachable.git", false).Return(nil)
+	dl.On("Download", mock.Anything, "https://example.com/user/unreachable-2.git", false).Return(errors.New("unreachable 2"))
+
+	sources := []PolicySource{
+		&PolicyUrl{Url: "https://example.com/user/unreachable-1.git", Kind: PolicyKind},
+		&PolicyUrl{Url: "https://example.com/user/reachable.git", Kind: PolicyKind},
+		&PolicyUrl{Url: "https://example.com/user/unreachable-2.git", Kind: PolicyKind},
+	}
+
+	ctx := usingDownloader(utils.WithFS(context.Background(), afero.NewMemMapFs()), &dl)
+	_, err := GetPolicies(ctx, sources, "/tmp/ec-work-1234", false)
+	assert.ErrorContains(t, err, "unreachable 1")
+	assert.ErrorContains(t, err, "unreachable 2")
+
+	mock.AssertExpectationsForObjects(t, &dl)
+}
+
+func TestGetPoliciesMissingSourcesWarn(t *testing.T) {
+	dl := mockDownloader{}
+	dl.On("Download", mock.Anything, "https://example.com/user/missing.git", false).Return(errors.New("404 Not Found"))
+	dl.On("Download", mock.Anything, "https://example.com/user/present.git", false).Return(nil)
+
+	sources := []PolicySource{
+		&PolicyUrl{Url: "https://example.com/user/missing.git", Kind: PolicyKind},
+		&PolicyUrl{Url: "https://example.com/user/present.git", Kind: PolicyKind},
+	}
+
+	ctx := usingDownloader(utils.WithFS(context.Background(), afero.NewMemMapFs()), &dl)
+	ctx = WithMissingSources(ctx, MissingSourceWarn)
+	dirs, err := GetPolicies(ctx, sources, "/tmp/ec-work-1234", false)
+	require.NoError(t, err)
+	require.Len(t, dirs, 2)
+	assert.Empty(t, dirs[0])
+	assert.NotEmpty(t, dirs[1])
+
+	assert.Contains(t, SkippedSources(), SkippedSource{Url: "https://example.com/user/missing.git", Reason: "404 Not Found"})
+
+	ResetSkippedSources()
+	assert.Empty(t, SkippedSources())
+
+	mock.AssertExpectationsForObjects(t, &dl)
+}
+
+func TestGetPoliciesMissingSourcesWarnAuthenticationFailure(t *testing.T) {
+	dl := mockDownloader{}
+	dl.On("Download", mock.Anything, "https://example.com/user/private.git", false).Return(errors.New("401 Unauthorized"))
+
+	sources := []PolicySource{
+		&PolicyUrl{Url: "https://example.com/user/private.git", Kind: PolicyKind},
+	}
+
+	ctx := usingDownloader(utils.WithFS(context.Background(), afero.NewMemMapFs()), &dl)
+	ctx = WithMissingSources(ctx, MissingSourceWarn)
+	_, err := GetPolicies(ctx, sources, "/tmp/ec-work-1234", false)
+	assert.ErrorContains(t, err, "401 Unauthorized")
+	assert.NotContains(t, SkippedSources(), SkippedSource{Url: "https://example.com/user/private.git", Reason: "401 Unauthorized"})
+
+	mock.AssertExpectationsForObjects(t, &dl)
+}
+
+func TestIsMissingSource(t *testing.T) {
+	cases := []struct {
+		err     error
+		missing bool
+	}{
+		{err: os.ErrNotExist, missing: true},
+		{err: fmt.Errorf("fetching: %w", &net.DNSError{Err: "no such host", Name: "example.com"}), missing: true},
+		{err: &transport.Error{StatusCode: http.StatusNotFound}, missing: true},
+		{err: &transport.Error{StatusCode: http.StatusUnauthorized}},
+		{err: errors.New("error downloading 'https://example.com/policy.tar.gz': bad response code: 404"), missing: true},
+		{err: errors.New("fatal: repository 'https://example.com/org/repo.git/' not found"), missing: true},
+		{err: errors.New("remote: Repository not found."), missing: true},
+		{err: errors.New("fatal: Authentication failed for 'https://example.com/org/repo.git/'")},
+		{err: errors.New("error downloading 'https://example.com/policy.tar.gz': bad response code: 403")},
+	}
+
+	for _, c := range cases {
+		t.Run(c.err.Error(), func(t *testing.T) {
+			assert.Equal(t, c.missing, isMissingSource(c.err))
+		})
+	}
+}
+
+func TestValidateMissingSourceMode(t *testing.T) {
+	assert.NoError(t, ValidateMissingSourceMode(MissingSourceError))
+	assert.NoError(t, ValidateMissingSourceMode(MissingSourceWarn))
+	assert.EqualError(t, ValidateMissingSourceMode("ignore"), `invalid missing source mode "ignore", expecting one of: error, warn`)
+}
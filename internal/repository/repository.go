@@ -0,0 +1,229 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.`
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package repository gathers the metadata of a Git repository at a given
+// revision into the input evaluated by the source code policies.
+package repository
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"golang.org/x/exp/slices"
+)
+
+// codeOwnersLocations lists the supported locations of the CODEOWNERS file in
+// the order they are looked up.
+var codeOwnersLocations = []string{"CODEOWNERS", ".github/CODEOWNERS", ".gitlab/CODEOWNERS", "docs/CODEOWNERS"}
+
+const gitAttributesFile = ".gitattributes"
+
+// Metadata is the metadata of a Git repository at a given revision.
+type Metadata struct {
+	Repo       string          `json:"repo"`
+	Ref        string          `json:"ref"`
+	Commit     Commit          `json:"commit"`
+	Files      []string        `json:"files"`
+	Attributes []GitAttributes `json:"attributes"`
+	CodeOwners *CodeOwners     `json:"codeowners,omitempty"`
+}
+
+// Commit describes the commit the revision resolves to.
+type Commit struct {
+	SHA       string    `json:"sha"`
+	Parents   []string  `json:"parents"`
+	Author    Signature `json:"author"`
+	Committer Signature `json:"committer"`
+	Message   string    `json:"message"`
+	Signed    bool      `json:"signed"`
+}
+
+// Signature identifies the author or the committer of a commit.
+type Signature struct {
+	Name  string    `json:"name"`
+	Email string    `json:"email"`
+	When  time.Time `json:"when"`
+}
+
+// GitAttributes are the attributes of the files matching the pattern, as
+// declared in a .gitattributes file. Set attributes have the value "true",
+// unset attributes the value "false" and unspecified attributes the value
+// "unspecified".
+type GitAttributes struct {
+	File       string            `json:"file"`
+	Pattern    string            `json:"pattern"`
+	Attributes map[string]string `json:"attributes"`
+}
+
+// CodeOwners holds the rules of the CODEOWNERS file.
+type CodeOwners struct {
+	File  string          `json:"file"`
+	Rules []CodeOwnerRule `json:"rules"`
+}
+
+// CodeOwnerRule lists the owners of the files matching the pattern.
+type CodeOwnerRule struct {
+	Pattern string   `json:"pattern"`
+	Owners  []string `json:"owners"`
+}
+
+// Collect gathers the metadata of the Git repository at the given path, or any
+// of its parent directories, at the given revision, e.g. HEAD, a branch, a tag
+// or a commit SHA.
+func Collect(repo, ref string) (*Metadata, error) {
+	r, err := git.PlainOpenWithOptions(repo, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return nil, fmt.Errorf("unable to open the Git repository at %s: %w", repo, err)
+	}
+
+	hash, err := r.ResolveRevision(plumbing.Revision(ref))
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve the revision %q: %w", ref, err)
+	}
+
+	commit, err := r.CommitObject(*hash)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the commit %s: %w", hash, err)
+	}
+
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the tree of the commit %s: %w", hash, err)
+	}
+
+	m := Metadata{
+		Repo:       repo,
+		Ref:        ref,
+		Commit:     newCommit(commit),
+		Files:      []string{},
+		Attributes: []GitAttributes{},
+	}
+
+	codeOwners := map[string]string{}
+	err = tree.Files().ForEach(func(f *object.File) error {
+		m.Files = append(m.Files, f.Name)
+
+		isAttributes := path.Base(f.Name) == gitAttributesFile
+		isCodeOwners := slices.Contains(codeOwnersLocations, f.Name)
+		if !isAttributes && !isCodeOwners {
+			return nil
+		}
+
+		contents, err := f.Contents()
+		if err != nil {
+			return fmt.Errorf("unable to read %s: %w", f.Name, err)
+		}
+
+		if isAttributes {
+			m.Attributes = append(m.Attributes, parseGitAttributes(f.Name, contents)...)
+		} else {
+			codeOwners[f.Name] = contents
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(m.Files)
+	sort.SliceStable(m.Attributes, func(i, j int) bool {
+		return m.Attributes[i].File < m.Attributes[j].File
+	})
+
+	for _, location := range codeOwnersLocations {
+		if contents, ok := codeOwners[location]; ok {
+			m.CodeOwners = &CodeOwners{File: location, Rules: parseCodeOwners(contents)}
+			break
+		}
+	}
+
+	return &m, nil
+}
+
+func newCommit(c *object.Commit) Commit {
+	parents := make([]string, 0, len(c.ParentHashes))
+	for _, p := range c.ParentHashes {
+		parents = append(parents, p.String())
+	}
+
+	return Commit{
+		SHA:       c.Hash.String(),
+		Parents:   parents,
+		Author:    Signature{Name: c.Author.Name, Email: c.Author.Email, When: c.Author.When.UTC()},
+		Committer: Signature{Name: c.Committer.Name, Email: c.Committer.Email, When: c.Committer.When.UTC()},
+		Message:   c.Message,
+		Signed:    c.PGPSignature != "",
+	}
+}
+
+// parseGitAttributes parses the contents of the .gitattributes file at the
+// given path, ignoring blank lines, comments and macro definitions.
+func parseGitAttributes(file, contents string) []GitAttributes {
+	var attributes []GitAttributes
+	for _, line := range strings.Split(contents, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") || strings.HasPrefix(fields[0], "[attr]") {
+			continue
+		}
+
+		attrs := map[string]string{}
+		for _, a := range fields[1:] {
+			switch {
+			case strings.HasPrefix(a, "-"):
+				attrs[a[1:]] = "false"
+			case strings.HasPrefix(a, "!"):
+				attrs[a[1:]] = "unspecified"
+			default:
+				if name, value, ok := strings.Cut(a, "="); ok {
+					attrs[name] = value
+				} else {
+					attrs[a] = "true"
+				}
+			}
+		}
+
+		attributes = append(attributes, GitAttributes{File: file, Pattern: fields[0], Attributes: attrs})
+	}
+
+	return attributes
+}
+
+// parseCodeOwners parses the contents of a CODEOWNERS file, ignoring blank
+// lines, comments and section headings.
+func parseCodeOwners(contents string) []CodeOwnerRule {
+	rules := []CodeOwnerRule{}
+	for _, line := range strings.Split(contents, "\n") {
+		if i := strings.Index(line, " #"); i >= 0 {
+			line = line[:i]
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") || strings.HasPrefix(fields[0], "[") || strings.HasPrefix(fields[0], "^[") {
+			continue
+		}
+
+		rules = append(rules, CodeOwnerRule{Pattern: fields[0], Owners: fields[1:]})
+	}
+
+	return rules
+}
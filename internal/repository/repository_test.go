@@ -0,0 +1,128 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.`
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package repository
+
+import (
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var when = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// commit creates a repository in the given directory, unless it exists, and
+// commits the given files to it.
+func commit(t *testing.T, dir string, files map[string]string) string {
+	r, err := git.PlainOpen(dir)
+	if err == git.ErrRepositoryNotExists {
+		r, err = git.PlainInit(dir, false)
+	}
+	require.NoError(t, err)
+
+	w, err := r.Worktree()
+	require.NoError(t, err)
+
+	for name, content := range files {
+		require.NoError(t, os.MkdirAll(path.Dir(path.Join(dir, name)), 0755))
+		require.NoError(t, os.WriteFile(path.Join(dir, name), []byte(content), 0600))
+		_, err := w.Add(name)
+		require.NoError(t, err)
+	}
+
+	sig := &object.Signature{Name: "Alice", Email: "alice@example.com", When: when}
+	hash, err := w.Commit("Add files", &git.CommitOptions{Author: sig, Committer: sig})
+	require.NoError(t, err)
+
+	return hash.String()
+}
+
+func TestCollect(t *testing.T) {
+	dir := t.TempDir()
+
+	first := commit(t, dir, map[string]string{
+		"README.md": "# Hello",
+	})
+	second := commit(t, dir, map[string]string{
+		".gitattributes":      "# comment\n*.go text eol=lf\n*.png -text !diff\n[attr]binary -diff\n",
+		"docs/.gitattributes": "*.adoc linguist-documentation\n",
+		".github/CODEOWNERS":  "# owners\n* @org/maintainers\n/docs/ @org/writers @alice # docs\n",
+		"docs/CODEOWNERS":     "* @org/ignored\n",
+	})
+
+	m, err := Collect(dir, "HEAD")
+	require.NoError(t, err)
+
+	assert.Equal(t, &Metadata{
+		Repo: dir,
+		Ref:  "HEAD",
+		Commit: Commit{
+			SHA:       second,
+			Parents:   []string{first},
+			Author:    Signature{Name: "Alice", Email: "alice@example.com", When: when},
+			Committer: Signature{Name: "Alice", Email: "alice@example.com", When: when},
+			Message:   "Add files",
+		},
+		Files: []string{".gitattributes", ".github/CODEOWNERS", "README.md", "docs/.gitattributes", "docs/CODEOWNERS"},
+		Attributes: []GitAttributes{
+			{File: ".gitattributes", Pattern: "*.go", Attributes: map[string]string{"text": "true", "eol": "lf"}},
+			{File: ".gitattributes", Pattern: "*.png", Attributes: map[string]string{"text": "false", "diff": "unspecified"}},
+			{File: "docs/.gitattributes", Pattern: "*.adoc", Attributes: map[string]string{"linguist-documentation": "true"}},
+		},
+		CodeOwners: &CodeOwners{
+			File: ".github/CODEOWNERS",
+			Rules: []CodeOwnerRule{
+				{Pattern: "*", Owners: []string{"@org/maintainers"}},
+				{Pattern: "/docs/", Owners: []string{"@org/writers", "@alice"}},
+			},
+		},
+	}, m)
+
+	m, err = Collect(path.Join(dir, "docs"), first)
+	require.NoError(t, err)
+	assert.Equal(t, first, m.Commit.SHA)
+	assert.Empty(t, m.Commit.Parents)
+	assert.Equal(t, []string{"README.md"}, m.Files)
+	assert.Empty(t, m.Attributes)
+	assert.Nil(t, m.CodeOwners)
+}
+
+func TestCollectErrors(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := Collect(dir, "HEAD")
+	assert.ErrorContains(t, err, "unable to open the Git repository at "+dir)
+
+	commit(t, dir, map[string]string{"README.md": "# Hello"})
+
+	_, err = Collect(dir, "nope")
+	assert.ErrorContains(t, err, `unable to resolve the revision "nope"`)
+}
+
+func TestParseCodeOwnersSections(t *testing.T) {
+	assert.Equal(t, []CodeOwnerRule{
+		{Pattern: "*.go", Owners: []string{"@backend"}},
+		{Pattern: "README.md", Owners: []string{}},
+	}, parseCodeOwners("[Backend]\n*.go @backend\n^[Optional]\nREADME.md\n"))
+}
@@ -0,0 +1,90 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.`
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+
+	"github.com/spf13/afero"
+
+	"github.com/enterprise-contract/ec-cli/internal/evaluator"
+	"github.com/enterprise-contract/ec-cli/internal/output"
+	"github.com/enterprise-contract/ec-cli/internal/policy"
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+	"github.com/enterprise-contract/ec-cli/internal/validate"
+)
+
+// Namespace is the policy namespace evaluated against the metadata of the
+// repository.
+const Namespace = "source.main"
+
+var newConftestEvaluator = evaluator.NewConftestEvaluatorWithNamespace
+
+// ValidateSource evaluates the policy rules in the source.main namespace of
+// each policy source against the metadata of the Git repository at the given
+// path at the given revision.
+func ValidateSource(ctx context.Context, repo, ref string, p policy.Policy, detailed bool) (*output.Output, error) {
+	m, err := Collect(repo, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	inputDir, inputPath, inputJSON, err := writeInputFile(ctx, m)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = utils.FS(ctx).RemoveAll(inputDir)
+	}()
+
+	results, err := validate.EvaluateNamespaces(ctx, newConftestEvaluator, p, []string{Namespace}, []string{inputPath})
+	if err != nil {
+		return nil, err
+	}
+
+	out := &output.Output{Detailed: detailed, PolicyInput: inputJSON}
+	out.SetPolicyCheck(results)
+
+	return out, nil
+}
+
+// writeInputFile writes the metadata to the input.json file in a new temporary
+// directory, returning the directory, to be removed by the caller, and the
+// path of the file.
+func writeInputFile(ctx context.Context, m *Metadata) (string, string, []byte, error) {
+	inputJSON, err := json.Marshal(m)
+	if err != nil {
+		return "", "", nil, fmt.Errorf("input to JSON: %w", err)
+	}
+
+	fs := utils.FS(ctx)
+	inputDir, err := afero.TempDir(fs, "", "ecp_input.")
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	inputPath := path.Join(inputDir, "input.json")
+	if err := afero.WriteFile(fs, inputPath, inputJSON, 0644); err != nil {
+		_ = fs.RemoveAll(inputDir)
+		return "", "", nil, fmt.Errorf("write input to file: %w", err)
+	}
+
+	return inputDir, inputPath, inputJSON, nil
+}
@@ -0,0 +1,103 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.`
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	ecc "github.com/enterprise-contract/enterprise-contract-controller/api/v1alpha1"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/evaluator"
+	"github.com/enterprise-contract/ec-cli/internal/policy"
+	"github.com/enterprise-contract/ec-cli/internal/policy/source"
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+type mockEvaluator struct {
+	inputs   []string
+	contents [][]byte
+}
+
+func (e *mockEvaluator) Evaluate(ctx context.Context, target evaluator.EvaluationTarget) ([]evaluator.Outcome, evaluator.Data, error) {
+	e.inputs = target.Inputs
+	for _, i := range target.Inputs {
+		content, err := afero.ReadFile(utils.FS(ctx), i)
+		if err != nil {
+			return nil, nil, err
+		}
+		e.contents = append(e.contents, content)
+	}
+
+	return []evaluator.Outcome{{
+		Namespace: Namespace,
+		Failures:  []evaluator.Result{{Message: "No CODEOWNERS file", Metadata: map[string]any{"code": "source.main.codeowners", "title": "CODEOWNERS"}}},
+	}}, nil, nil
+}
+
+func (e *mockEvaluator) Destroy() {}
+
+func (e *mockEvaluator) CapabilitiesPath() string {
+	return ""
+}
+
+func TestValidateSource(t *testing.T) {
+	dir := t.TempDir()
+	sha := commit(t, dir, map[string]string{"README.md": "# Hello"})
+
+	fs := afero.NewMemMapFs()
+	ctx := utils.WithFS(context.Background(), fs)
+
+	e := &mockEvaluator{}
+	var namespaces []string
+	newConftestEvaluator = func(_ context.Context, _ []source.PolicySource, _ evaluator.ConfigProvider, _ ecc.Source, namespace []string) (evaluator.Evaluator, error) {
+		namespaces = namespace
+		return e, nil
+	}
+	t.Cleanup(func() {
+		newConftestEvaluator = evaluator.NewConftestEvaluatorWithNamespace
+	})
+
+	p, err := policy.NewInputPolicy(ctx, `{"sources":[{"policy":["github.com/org/policy"]}]}`, policy.Now)
+	require.NoError(t, err)
+
+	out, err := ValidateSource(ctx, dir, "HEAD", p, false)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"source.main"}, namespaces)
+	assert.Equal(t, []evaluator.Result{{Message: "No CODEOWNERS file", Metadata: map[string]any{"code": "source.main.codeowners"}}}, out.Violations())
+
+	require.Len(t, e.contents, 1)
+	input := e.contents[0]
+	assert.Equal(t, out.PolicyInput, input)
+
+	exists, err := afero.Exists(fs, filepath.Dir(e.inputs[0]))
+	require.NoError(t, err)
+	assert.False(t, exists, "the input directory is removed")
+
+	var m Metadata
+	require.NoError(t, json.Unmarshal(input, &m))
+	assert.Equal(t, sha, m.Commit.SHA)
+	assert.Equal(t, []string{"README.md"}, m.Files)
+}
@@ -0,0 +1,72 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package signature
+
+import (
+	"crypto/x509"
+	"regexp"
+
+	"github.com/sigstore/cosign/v2/pkg/cosign"
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
+)
+
+// Identity is the keyless identity a signature was verified with, i.e. the
+// subject alternative name and the OIDC issuer of the signing certificate.
+type Identity struct {
+	Subject string `json:"subject"`
+	Issuer  string `json:"issuer"`
+}
+
+// MatchIdentity returns the identity of the certificate matching the first of
+// the expected identities, matched as cosign does. It returns false if none
+// of the expected identities match.
+func MatchIdentity(cert *x509.Certificate, identities []cosign.Identity) (Identity, bool) {
+	issuer := (&cosign.CertExtensions{Cert: cert}).GetIssuer()
+	sans := cryptoutils.GetSubjectAlternateNames(cert)
+
+	for _, identity := range identities {
+		if !matches(identity.Issuer, identity.IssuerRegExp, issuer) {
+			continue
+		}
+
+		for _, san := range sans {
+			if matches(identity.Subject, identity.SubjectRegExp, san) {
+				return Identity{Subject: san, Issuer: issuer}, true
+			}
+		}
+
+		if identity.Subject == "" && identity.SubjectRegExp == "" && len(sans) > 0 {
+			return Identity{Subject: sans[0], Issuer: issuer}, true
+		}
+	}
+
+	return Identity{}, false
+}
+
+// matches checks the value against the regular expression if given, or
+// against the exact value otherwise. Without either, any value matches.
+func matches(exact, expr, value string) bool {
+	switch {
+	case expr != "":
+		re, err := regexp.Compile(expr)
+		return err == nil && re.MatchString(value)
+	case exact != "":
+		return exact == value
+	default:
+		return true
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package signature
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+
+	"github.com/sigstore/cosign/v2/pkg/cosign"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchIdentity(t *testing.T) {
+	p, _ := pem.Decode(ChainguardReleaseCert)
+	cert, err := x509.ParseCertificate(p.Bytes)
+	require.NoError(t, err)
+
+	const (
+		subject = "https://github.com/chainguard-images/images/.github/workflows/release.yaml@refs/heads/main"
+		issuer  = "https://token.actions.githubusercontent.com"
+	)
+	expected := Identity{Subject: subject, Issuer: issuer}
+
+	cases := []struct {
+		name       string
+		identities []cosign.Identity
+		ok         bool
+	}{
+		{name: "exact", identities: []cosign.Identity{{Subject: subject, Issuer: issuer}}, ok: true},
+		{name: "regexp", identities: []cosign.Identity{{SubjectRegExp: `^https://github\.com/chainguard-images/`, IssuerRegExp: `githubusercontent`}}, ok: true},
+		{name: "any of", identities: []cosign.Identity{{Subject: "someone", Issuer: issuer}, {SubjectRegExp: "release", Issuer: issuer}}, ok: true},
+		{name: "subject mismatch", identities: []cosign.Identity{{Subject: "someone", Issuer: issuer}}},
+		{name: "issuer mismatch", identities: []cosign.Identity{{Subject: subject, IssuerRegExp: "gitlab"}}},
+		{name: "none"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			id, ok := MatchIdentity(cert, c.identities)
+			assert.Equal(t, c.ok, ok)
+			if c.ok {
+				assert.Equal(t, expected, id)
+			} else {
+				assert.Equal(t, Identity{}, id)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package signature
+
+import (
+	"encoding/json"
+	"fmt"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/sigstore/cosign/v2/pkg/cosign"
+	"github.com/sigstore/cosign/v2/pkg/oci"
+	"github.com/sigstore/sigstore/pkg/signature/payload"
+)
+
+// DigestMismatchError is returned when the digest in the signature payload is
+// not the digest of the image being verified, e.g. when a valid signature made
+// over a different image is attached to the image.
+type DigestMismatchError struct {
+	// Signed is the digest in the signature payload, empty if missing
+	Signed string
+	// Image is the digest of the image being verified
+	Image string
+}
+
+func (e DigestMismatchError) Error() string {
+	if e.Signed == "" {
+		return fmt.Sprintf("the signature payload has no image digest, expected the digest of the verified image %s", e.Image)
+	}
+
+	return fmt.Sprintf("the signature payload is for the image digest %s, not for the digest of the verified image %s", e.Signed, e.Image)
+}
+
+// ImageDigestClaimVerifier verifies that the critical.image.docker-manifest-digest
+// of the signature payload is the digest of the image being verified, failing
+// with DigestMismatchError if not, and that the payload has the annotations,
+// see cosign.SimpleClaimVerifier.
+func ImageDigestClaimVerifier(sig oci.Signature, imageDigest v1.Hash, annotations map[string]any) error {
+	p, err := sig.Payload()
+	if err != nil {
+		return err
+	}
+
+	var ss payload.SimpleContainerImage
+	if err := json.Unmarshal(p, &ss); err != nil {
+		return fmt.Errorf("unable to parse the signature payload: %w", err)
+	}
+
+	if signed := ss.Critical.Image.DockerManifestDigest; signed != imageDigest.String() {
+		return DigestMismatchError{Signed: signed, Image: imageDigest.String()}
+	}
+
+	return cosign.SimpleClaimVerifier(sig, imageDigest, annotations)
+}
+
+// SignedDigest returns the critical.image.docker-manifest-digest of the
+// signature payload, or an empty string if the payload is not a simple signing
+// payload.
+func SignedDigest(sig oci.Signature) string {
+	p, err := sig.Payload()
+	if err != nil {
+		return ""
+	}
+
+	var ss payload.SimpleContainerImage
+	if err := json.Unmarshal(p, &ss); err != nil {
+		return ""
+	}
+
+	return ss.Critical.Image.DockerManifestDigest
+}
@@ -0,0 +1,77 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package signature
+
+import (
+	"encoding/json"
+	"testing"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/sigstore/cosign/v2/pkg/oci/static"
+	"github.com/sigstore/sigstore/pkg/signature/payload"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImageDigestClaimVerifier(t *testing.T) {
+	digest := v1.Hash{Algorithm: "sha256", Hex: "dabbad00"}
+
+	cases := []struct {
+		name   string
+		signed string
+		err    string
+	}{
+		{name: "match", signed: "sha256:dabbad00"},
+		{name: "mismatch", signed: "sha256:ffbadd11", err: "the signature payload is for the image digest sha256:ffbadd11, not for the digest of the verified image sha256:dabbad00"},
+		{name: "missing", err: "the signature payload has no image digest, expected the digest of the verified image sha256:dabbad00"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			p, err := json.Marshal(payload.SimpleContainerImage{
+				Critical: payload.Critical{Image: payload.Image{DockerManifestDigest: c.signed}},
+			})
+			require.NoError(t, err)
+
+			sig, err := static.NewSignature(p, "signature")
+			require.NoError(t, err)
+
+			err = ImageDigestClaimVerifier(sig, digest, nil)
+			if c.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, c.err)
+			}
+		})
+	}
+}
+
+func TestSignedDigest(t *testing.T) {
+	p, err := json.Marshal(payload.SimpleContainerImage{
+		Critical: payload.Critical{Image: payload.Image{DockerManifestDigest: "sha512:dabbad00"}},
+	})
+	require.NoError(t, err)
+	sig, err := static.NewSignature(p, "signature")
+	require.NoError(t, err)
+	assert.Equal(t, "sha512:dabbad00", SignedDigest(sig))
+
+	sig, err = static.NewSignature([]byte(`{"payloadType":"application/vnd.in-toto+json"}`), "signature")
+	require.NoError(t, err)
+	assert.Empty(t, SignedDigest(sig))
+}
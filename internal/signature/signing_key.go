@@ -0,0 +1,109 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package signature
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"fmt"
+	"strings"
+)
+
+// SigningKey describes the signing material a signature was verified with.
+type SigningKey struct {
+	// Algorithm of the signature, named as the x509 signature algorithms, e.g.
+	// ECDSA-SHA256
+	Algorithm string `json:"algorithm"`
+	// KeySize in bits, the size of the modulus for RSA keys and the size of the
+	// curve for ECDSA keys
+	KeySize int `json:"keySize"`
+	// CertificateAlgorithm is the algorithm the signing certificate was signed
+	// with by its issuer, if a certificate was used
+	CertificateAlgorithm string `json:"certificateAlgorithm,omitempty"`
+}
+
+// signatureAlgorithms are the signature algorithms of RSA and ECDSA keys by
+// the hash of the signed digest.
+var signatureAlgorithms = map[crypto.Hash]struct{ rsa, ecdsa x509.SignatureAlgorithm }{
+	crypto.SHA256: {x509.SHA256WithRSA, x509.ECDSAWithSHA256},
+	crypto.SHA384: {x509.SHA384WithRSA, x509.ECDSAWithSHA384},
+	crypto.SHA512: {x509.SHA512WithRSA, x509.ECDSAWithSHA512},
+}
+
+// DigestHash returns the hash of the algorithm of the digest, e.g. SHA-256 for
+// sha256:<hex>. The algorithm can be given alone, e.g. sha256. SHA-256, the
+// default of cosign, is returned if the digest is empty.
+func DigestHash(digest string) (crypto.Hash, error) {
+	algorithm, _, _ := strings.Cut(digest, ":")
+	switch strings.ToLower(algorithm) {
+	case "", "sha256":
+		return crypto.SHA256, nil
+	case "sha384":
+		return crypto.SHA384, nil
+	case "sha512":
+		return crypto.SHA512, nil
+	default:
+		return 0, fmt.Errorf("unsupported digest algorithm %q", algorithm)
+	}
+}
+
+// NewSigningKey describes the signing material of the public key, and of the
+// certificate holding it if any, for signatures over digests of the given
+// hash, see DigestHash.
+func NewSigningKey(pub crypto.PublicKey, cert *x509.Certificate, hash crypto.Hash) (SigningKey, error) {
+	algorithms, ok := signatureAlgorithms[hash]
+	if !ok {
+		return SigningKey{}, fmt.Errorf("unsupported digest hash %s", hash)
+	}
+
+	var k SigningKey
+	switch p := pub.(type) {
+	case *rsa.PublicKey:
+		k = SigningKey{Algorithm: algorithms.rsa.String(), KeySize: p.N.BitLen()}
+	case *ecdsa.PublicKey:
+		k = SigningKey{Algorithm: algorithms.ecdsa.String(), KeySize: p.Curve.Params().BitSize}
+	case ed25519.PublicKey:
+		k = SigningKey{Algorithm: x509.PureEd25519.String(), KeySize: ed25519.PublicKeySize * 8}
+	default:
+		return SigningKey{}, fmt.Errorf("unsupported public key type %T", pub)
+	}
+
+	if cert != nil {
+		k.CertificateAlgorithm = cert.SignatureAlgorithm.String()
+	}
+
+	return k, nil
+}
+
+// IsRSA returns true if the signing key is a RSA key.
+func (k SigningKey) IsRSA() bool {
+	return strings.HasSuffix(k.Algorithm, "-RSA") || strings.HasSuffix(k.Algorithm, "-RSAPSS")
+}
+
+// SignatureAlgorithms are the names of the signature algorithms known to the
+// x509 package.
+func SignatureAlgorithms() []string {
+	var names []string
+	for a := x509.MD2WithRSA; a <= x509.PureEd25519; a++ {
+		names = append(names, a.String())
+	}
+
+	return names
+}
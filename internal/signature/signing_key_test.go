@@ -0,0 +1,111 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package signature
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSigningKey(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+	ed25519Key, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	cases := []struct {
+		name     string
+		key      crypto.PublicKey
+		hash     crypto.Hash
+		expected SigningKey
+		rsa      bool
+	}{
+		{name: "RSA", key: &rsaKey.PublicKey, hash: crypto.SHA256, expected: SigningKey{Algorithm: "SHA256-RSA", KeySize: 1024}, rsa: true},
+		{name: "RSA SHA-512", key: &rsaKey.PublicKey, hash: crypto.SHA512, expected: SigningKey{Algorithm: "SHA512-RSA", KeySize: 1024}, rsa: true},
+		{name: "ECDSA", key: &ecdsaKey.PublicKey, hash: crypto.SHA256, expected: SigningKey{Algorithm: "ECDSA-SHA256", KeySize: 384}},
+		{name: "ECDSA SHA-384", key: &ecdsaKey.PublicKey, hash: crypto.SHA384, expected: SigningKey{Algorithm: "ECDSA-SHA384", KeySize: 384}},
+		{name: "Ed25519", key: ed25519Key, hash: crypto.SHA512, expected: SigningKey{Algorithm: "Ed25519", KeySize: 256}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			k, err := NewSigningKey(c.key, nil, c.hash)
+			require.NoError(t, err)
+			assert.Equal(t, c.expected, k)
+			assert.Equal(t, c.rsa, k.IsRSA())
+		})
+	}
+
+	_, err = NewSigningKey("key", nil, crypto.SHA256)
+	assert.EqualError(t, err, "unsupported public key type string")
+
+	_, err = NewSigningKey(&rsaKey.PublicKey, nil, crypto.SHA1)
+	assert.EqualError(t, err, "unsupported digest hash SHA-1")
+}
+
+func TestDigestHash(t *testing.T) {
+	cases := []struct {
+		digest   string
+		expected crypto.Hash
+		err      string
+	}{
+		{digest: "", expected: crypto.SHA256},
+		{digest: "sha256:4e388ab32b10dc8dbc7e28144f552830adc74787c1e2c0824032078a79f227fb", expected: crypto.SHA256},
+		{digest: "sha384", expected: crypto.SHA384},
+		{digest: "sha512:abc", expected: crypto.SHA512},
+		{digest: "md5:abc", err: `unsupported digest algorithm "md5"`},
+	}
+
+	for _, c := range cases {
+		t.Run(c.digest, func(t *testing.T) {
+			h, err := DigestHash(c.digest)
+			if c.err != "" {
+				assert.EqualError(t, err, c.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, c.expected, h)
+		})
+	}
+}
+
+func TestNewSigningKeyFromCertificate(t *testing.T) {
+	cert := ParseChainguardReleaseCert()
+
+	k, err := NewSigningKey(cert.PublicKey, cert, crypto.SHA256)
+	require.NoError(t, err)
+	assert.Equal(t, SigningKey{Algorithm: "ECDSA-SHA256", KeySize: 256, CertificateAlgorithm: "ECDSA-SHA384"}, k)
+}
+
+func TestSignatureAlgorithms(t *testing.T) {
+	algorithms := SignatureAlgorithms()
+	assert.Contains(t, algorithms, "SHA1-RSA")
+	assert.Contains(t, algorithms, "ECDSA-SHA256")
+	assert.Contains(t, algorithms, "Ed25519")
+}
@@ -0,0 +1,100 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package signature
+
+import (
+	"time"
+
+	"github.com/sigstore/cosign/v2/pkg/cosign"
+	"github.com/sigstore/cosign/v2/pkg/oci"
+)
+
+const (
+	// SigningTimeSourceTimestamp is the source of the signing time taken from
+	// the trusted RFC3161 timestamp of the signature
+	SigningTimeSourceTimestamp = "timestamp"
+	// SigningTimeSourceRekor is the source of the signing time taken from the
+	// time the signature was integrated into the transparency log
+	SigningTimeSourceRekor = "rekor"
+	// SigningTimeSourceNow is the source of the signing time when neither a
+	// trusted timestamp nor a transparency log entry are available
+	SigningTimeSourceNow = "now"
+)
+
+// SigningTime describes the time the validity of the signing certificate was
+// checked at. A certificate valid at the time of signing remains acceptable
+// once expired, provided a trusted timestamp or the transparency log proves
+// the time of signing, otherwise the certificate needs to be valid now.
+type SigningTime struct {
+	// Time the validity of the certificate was checked at
+	Time time.Time `json:"time"`
+	// Source of the time, one of timestamp, rekor or now
+	Source string `json:"source"`
+	// NotBefore is the start of the validity window of the certificate
+	NotBefore time.Time `json:"notBefore"`
+	// NotAfter is the end of the validity window of the certificate
+	NotAfter time.Time `json:"notAfter"`
+	// Expired is true if the certificate has expired since the time of
+	// signing
+	Expired bool `json:"expired,omitempty"`
+}
+
+// NewSigningTime determines the time the validity of the certificate of the
+// verified signature was checked at, the same way cosign does: the time of the
+// trusted RFC3161 timestamp, or the time the signature was integrated into the
+// transparency log, or the current time. The current time is also reported for
+// signatures looked up in the transparency log, lacking the bundle holding the
+// time of the entry. Returns nil if the signature has no certificate.
+func NewSigningTime(sig oci.Signature, co *cosign.CheckOpts, now time.Time) (*SigningTime, error) {
+	cert, err := sig.Cert()
+	if err != nil || cert == nil {
+		return nil, err
+	}
+
+	st := SigningTime{
+		Time:      now.UTC(),
+		Source:    SigningTimeSourceNow,
+		NotBefore: cert.NotBefore.UTC(),
+		NotAfter:  cert.NotAfter.UTC(),
+		Expired:   now.After(cert.NotAfter),
+	}
+
+	if co.TSARootCertificates != nil {
+		ts, err := cosign.VerifyRFC3161Timestamp(sig, co)
+		if err != nil {
+			return nil, err
+		}
+		if ts != nil {
+			st.Time = ts.Time.UTC()
+			st.Source = SigningTimeSourceTimestamp
+			return &st, nil
+		}
+	}
+
+	if !co.IgnoreTlog {
+		bundle, err := sig.Bundle()
+		if err != nil {
+			return nil, err
+		}
+		if bundle != nil {
+			st.Time = time.Unix(bundle.Payload.IntegratedTime, 0).UTC()
+			st.Source = SigningTimeSourceRekor
+		}
+	}
+
+	return &st, nil
+}
@@ -0,0 +1,120 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package signature
+
+import (
+	"crypto/x509"
+	"testing"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/sigstore/cosign/v2/pkg/cosign"
+	"github.com/sigstore/cosign/v2/pkg/cosign/bundle"
+	"github.com/sigstore/cosign/v2/pkg/oci/static"
+	cosignTypes "github.com/sigstore/cosign/v2/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSigningTime(t *testing.T) {
+	notBefore := time.Date(2023, 6, 7, 3, 14, 12, 0, time.UTC)
+	notAfter := time.Date(2023, 6, 7, 3, 24, 12, 0, time.UTC)
+	integrated := time.Date(2023, 6, 7, 3, 14, 13, 0, time.UTC)
+
+	withCert := []static.Option{
+		static.WithLayerMediaType(types.MediaType((cosignTypes.DssePayloadType))),
+		static.WithCertChain(ChainguardReleaseCert, SigstoreChainCert),
+	}
+	withBundle := append(withCert, static.WithBundle(&bundle.RekorBundle{
+		Payload: bundle.RekorPayload{IntegratedTime: integrated.Unix()},
+	}))
+
+	cases := []struct {
+		name     string
+		options  []static.Option
+		co       cosign.CheckOpts
+		now      time.Time
+		expected *SigningTime
+	}{
+		{
+			name: "no certificate",
+			now:  integrated,
+		},
+		{
+			name:    "transparency log",
+			options: withBundle,
+			now:     time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			expected: &SigningTime{
+				Time:      integrated,
+				Source:    SigningTimeSourceRekor,
+				NotBefore: notBefore,
+				NotAfter:  notAfter,
+				Expired:   true,
+			},
+		},
+		{
+			name:    "transparency log ignored",
+			options: withBundle,
+			co:      cosign.CheckOpts{IgnoreTlog: true},
+			now:     integrated,
+			expected: &SigningTime{
+				Time:      integrated,
+				Source:    SigningTimeSourceNow,
+				NotBefore: notBefore,
+				NotAfter:  notAfter,
+			},
+		},
+		{
+			name:    "no transparency log entry",
+			options: withCert,
+			now:     notAfter.Add(time.Second),
+			expected: &SigningTime{
+				Time:      notAfter.Add(time.Second),
+				Source:    SigningTimeSourceNow,
+				NotBefore: notBefore,
+				NotAfter:  notAfter,
+				Expired:   true,
+			},
+		},
+		{
+			name:    "no timestamp",
+			options: withBundle,
+			co:      cosign.CheckOpts{TSARootCertificates: []*x509.Certificate{ParseChainguardReleaseCert()}},
+			now:     time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			expected: &SigningTime{
+				Time:      integrated,
+				Source:    SigningTimeSourceRekor,
+				NotBefore: notBefore,
+				NotAfter:  notAfter,
+				Expired:   true,
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			sig, err := static.NewSignature([]byte(`image`), "signature", c.options...)
+			require.NoError(t, err)
+
+			st, err := NewSigningTime(sig, &c.co, c.now)
+			require.NoError(t, err)
+			assert.Equal(t, c.expected, st)
+		})
+	}
+}
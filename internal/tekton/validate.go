@@ -0,0 +1,142 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package tekton validates standalone Tekton resources, Tasks and StepActions,
+// against the policy rules in the namespace of their kind.
+package tekton
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	k8syaml "k8s.io/apimachinery/pkg/util/yaml"
+
+	"github.com/enterprise-contract/ec-cli/internal/evaluator"
+	"github.com/enterprise-contract/ec-cli/internal/output"
+	"github.com/enterprise-contract/ec-cli/internal/policy"
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+	"github.com/enterprise-contract/ec-cli/internal/validate"
+)
+
+const (
+	TaskKind       = "Task"
+	StepActionKind = "StepAction"
+)
+
+// namespaces are the policy namespaces evaluated against the resources of each
+// kind
+var namespaces = map[string]string{
+	TaskKind:       "task.main",
+	StepActionKind: "stepaction.main",
+}
+
+var newConftestEvaluator = evaluator.NewConftestEvaluatorWithNamespace
+
+// Namespace returns the policy namespace evaluated against the resources of
+// the given kind.
+func Namespace(kind string) string {
+	return namespaces[kind]
+}
+
+// ValidateResource evaluates the policy rules in the namespace of the kind
+// against each resource within the file. A file with multiple YAML documents
+// is split, each document is a separate input to the evaluation and must be a
+// resource of the given kind.
+func ValidateResource(ctx context.Context, fpath string, kind string, p policy.Policy, detailed bool) (*output.Output, error) {
+	namespace := Namespace(kind)
+	if namespace == "" {
+		return nil, fmt.Errorf("unsupported Tekton resource kind %q", kind)
+	}
+
+	log.Debugf("Validating %s resources in %q", kind, fpath)
+	fs := utils.FS(ctx)
+	content, err := afero.ReadFile(fs, fpath)
+	if err != nil {
+		return nil, err
+	}
+
+	documents, err := splitDocuments(content)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %w", fpath, err)
+	}
+	if len(documents) == 0 {
+		return nil, fmt.Errorf("no %s found in %s", kind, fpath)
+	}
+
+	inputDir, err := afero.TempDir(fs, "", "ecp_input.")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = fs.RemoveAll(inputDir)
+	}()
+
+	inputs := make([]string, 0, len(documents))
+	for i, d := range documents {
+		if k, _ := d["kind"].(string); k != kind {
+			return nil, fmt.Errorf("document %d of %s is of kind %q, expecting %s", i+1, fpath, k, kind)
+		}
+
+		inputJSON, err := json.Marshal(d)
+		if err != nil {
+			return nil, fmt.Errorf("input to JSON: %w", err)
+		}
+
+		inputPath := path.Join(inputDir, fmt.Sprintf("document-%d.json", i+1))
+		if err := afero.WriteFile(fs, inputPath, inputJSON, 0644); err != nil {
+			return nil, fmt.Errorf("write input to file: %w", err)
+		}
+		inputs = append(inputs, inputPath)
+	}
+
+	results, err := validate.EvaluateNamespaces(ctx, newConftestEvaluator, p, []string{namespace}, inputs)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &output.Output{Detailed: detailed}
+	out.SetPolicyCheck(results)
+
+	return out, nil
+}
+
+// splitDocuments parses the YAML, or JSON, documents of the content, skipping
+// empty documents.
+func splitDocuments(content []byte) ([]map[string]any, error) {
+	decoder := k8syaml.NewYAMLOrJSONDecoder(bytes.NewReader(content), 4096)
+
+	var documents []map[string]any
+	for {
+		var d map[string]any
+		if err := decoder.Decode(&d); err != nil {
+			if errors.Is(err, io.EOF) {
+				return documents, nil
+			}
+			return nil, err
+		}
+		if len(d) == 0 {
+			continue
+		}
+		documents = append(documents, d)
+	}
+}
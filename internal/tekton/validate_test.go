@@ -0,0 +1,148 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package tekton
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	hd "github.com/MakeNowJust/heredoc"
+	ecc "github.com/enterprise-contract/enterprise-contract-controller/api/v1alpha1"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/evaluator"
+	"github.com/enterprise-contract/ec-cli/internal/policy"
+	"github.com/enterprise-contract/ec-cli/internal/policy/source"
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+type mockEvaluator struct {
+	inputs   []string
+	contents [][]byte
+}
+
+func (e *mockEvaluator) Evaluate(ctx context.Context, target evaluator.EvaluationTarget) ([]evaluator.Outcome, evaluator.Data, error) {
+	e.inputs = target.Inputs
+	for _, i := range target.Inputs {
+		content, err := afero.ReadFile(utils.FS(ctx), i)
+		if err != nil {
+			return nil, nil, err
+		}
+		e.contents = append(e.contents, content)
+	}
+
+	return []evaluator.Outcome{{
+		Namespace: "task.main",
+		Failures:  []evaluator.Result{{Message: "Step image not pinned", Metadata: map[string]any{"code": "task.main.pinned_images", "title": "Pinned images"}}},
+	}}, nil, nil
+}
+
+func (e *mockEvaluator) Destroy() {}
+
+func (e *mockEvaluator) CapabilitiesPath() string {
+	return ""
+}
+
+func setUp(t *testing.T) (context.Context, afero.Fs, *mockEvaluator, *[]string) {
+	fs := afero.NewMemMapFs()
+	ctx := utils.WithFS(context.Background(), fs)
+
+	e := &mockEvaluator{}
+	var namespaces []string
+	newConftestEvaluator = func(_ context.Context, _ []source.PolicySource, _ evaluator.ConfigProvider, _ ecc.Source, namespace []string) (evaluator.Evaluator, error) {
+		namespaces = namespace
+		return e, nil
+	}
+	t.Cleanup(func() {
+		newConftestEvaluator = evaluator.NewConftestEvaluatorWithNamespace
+	})
+
+	return ctx, fs, e, &namespaces
+}
+
+func TestValidateResource(t *testing.T) {
+	ctx, fs, e, namespaces := setUp(t)
+
+	require.NoError(t, afero.WriteFile(fs, "/tasks.yaml", []byte(hd.Doc(`
+		---
+		apiVersion: tekton.dev/v1
+		kind: Task
+		metadata:
+		  name: build
+		---
+		---
+		apiVersion: tekton.dev/v1
+		kind: Task
+		metadata:
+		  name: test
+	`)), 0644))
+
+	p, err := policy.NewInputPolicy(ctx, `{"sources":[{"policy":["github.com/org/policy"]}]}`, policy.Now)
+	require.NoError(t, err)
+
+	out, err := ValidateResource(ctx, "/tasks.yaml", TaskKind, p, false)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"task.main"}, *namespaces)
+	assert.Equal(t, []evaluator.Result{{Message: "Step image not pinned", Metadata: map[string]any{"code": "task.main.pinned_images"}}}, out.Violations())
+
+	require.Len(t, e.contents, 2)
+	for i, name := range []string{"build", "test"} {
+		assert.JSONEq(t, `{"apiVersion":"tekton.dev/v1","kind":"Task","metadata":{"name":"`+name+`"}}`, string(e.contents[i]))
+	}
+
+	exists, err := afero.Exists(fs, filepath.Dir(e.inputs[0]))
+	require.NoError(t, err)
+	assert.False(t, exists, "the input directory is removed")
+}
+
+func TestValidateResourceErrors(t *testing.T) {
+	cases := []struct {
+		name    string
+		content string
+		kind    string
+		err     string
+	}{
+		{name: "unsupported kind", content: "kind: Pipeline", kind: "Pipeline", err: `unsupported Tekton resource kind "Pipeline"`},
+		{name: "empty", content: "---\n", kind: TaskKind, err: "no Task found in /resource.yaml"},
+		{name: "invalid", content: "kind: [", kind: TaskKind, err: "unable to parse /resource.yaml"},
+		{
+			name:    "different kind",
+			content: "kind: StepAction\n---\nkind: Task\n",
+			kind:    StepActionKind,
+			err:     `document 2 of /resource.yaml is of kind "Task", expecting StepAction`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctx, fs, _, _ := setUp(t)
+			require.NoError(t, afero.WriteFile(fs, "/resource.yaml", []byte(c.content), 0644))
+
+			p, err := policy.NewInputPolicy(ctx, `{"sources":[{"policy":["github.com/org/policy"]}]}`, policy.Now)
+			require.NoError(t, err)
+
+			_, err = ValidateResource(ctx, "/resource.yaml", c.kind, p, false)
+			assert.ErrorContains(t, err, c.err)
+		})
+	}
+}
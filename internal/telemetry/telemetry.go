@@ -0,0 +1,136 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package telemetry instruments the major phases of the validation with
+// OpenTelemetry spans, exported via OTLP when an endpoint is configured.
+package telemetry
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/enterprise-contract/ec-cli"
+
+// Attributes of the spans
+const (
+	ImageDigest   = attribute.Key("ec.image.digest")
+	PolicySources = attribute.Key("ec.policy.sources")
+	Outcome       = attribute.Key("ec.outcome")
+)
+
+// Outcomes of the spans
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+	OutcomeError   = "error"
+)
+
+// Init configures the export of the spans to the OTLP endpoint, given either
+// as a URL, e.g. http://collector:4317, or as host:port. Without an endpoint
+// the spans are exported only if configured with the OTEL_EXPORTER_OTLP_*
+// environment variables, which also apply to the rest of the exporter
+// settings, e.g. headers or TLS. When the TRACEPARENT environment variable is
+// set the spans are part of that trace. The returned function flushes and
+// stops the export.
+func Init(ctx context.Context, endpoint string) (context.Context, func(context.Context) error, error) {
+	noop := func(context.Context) error { return nil }
+
+	if strings.EqualFold(os.Getenv("OTEL_SDK_DISABLED"), "true") || (endpoint == "" && !endpointFromEnv()) {
+		return ctx, noop, nil
+	}
+
+	var opts []otlptracegrpc.Option
+	switch {
+	case strings.Contains(endpoint, "://"):
+		opts = append(opts, otlptracegrpc.WithEndpointURL(endpoint))
+	case endpoint != "":
+		opts = append(opts, otlptracegrpc.WithEndpoint(endpoint))
+	}
+
+	exporter, err := otlptracegrpc.New(ctx, opts...)
+	if err != nil {
+		return ctx, noop, err
+	}
+
+	res, err := resource.New(ctx,
+		resource.WithAttributes(semconv.ServiceName("ec")),
+		resource.WithTelemetrySDK(),
+		// OTEL_SERVICE_NAME and OTEL_RESOURCE_ATTRIBUTES take precedence
+		resource.WithFromEnv(),
+	)
+	if err != nil {
+		return ctx, noop, err
+	}
+
+	provider := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter), sdktrace.WithResource(res))
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	if parent := os.Getenv("TRACEPARENT"); parent != "" {
+		ctx = otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier{"traceparent": parent})
+	}
+
+	return ctx, provider.Shutdown, nil
+}
+
+func endpointFromEnv() bool {
+	return os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "" || os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") != ""
+}
+
+// Start starts a span with the given name and attributes. Unless Init
+// configured the export, the span is not recorded and the context is
+// returned as is.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+	if !span.IsRecording() {
+		return ctx, span
+	}
+
+	return spanCtx, span
+}
+
+// End ends the span with the outcome, which is OutcomeError if err is not
+// nil.
+func End(span trace.Span, outcome string, err error) {
+	if err != nil {
+		outcome = OutcomeError
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.SetAttributes(Outcome.String(outcome))
+	span.End()
+}
+
+// OutcomeOf returns OutcomeSuccess if passed, OutcomeFailure otherwise.
+func OutcomeOf(passed bool) string {
+	if passed {
+		return OutcomeSuccess
+	}
+
+	return OutcomeFailure
+}
@@ -0,0 +1,81 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func TestInitWithoutEndpoint(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "")
+
+	ctx := context.Background()
+	got, shutdown, err := Init(ctx, "")
+	require.NoError(t, err)
+	assert.Equal(t, ctx, got)
+	assert.NoError(t, shutdown(ctx))
+}
+
+func TestInitWithTraceParent(t *testing.T) {
+	t.Setenv("TRACEPARENT", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	t.Cleanup(func() { otel.SetTracerProvider(noop.NewTracerProvider()) })
+
+	ctx, shutdown, err := Init(context.Background(), "http://localhost:4317")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = shutdown(context.Background()) })
+
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", trace.SpanContextFromContext(ctx).TraceID().String())
+}
+
+func TestSpans(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(noop.NewTracerProvider()) })
+
+	ctx, parent := Start(context.Background(), "parent", PolicySources.Int(2))
+	_, child := Start(ctx, "child", ImageDigest.String("sha256:4e38"))
+	End(child, OutcomeOf(false), nil)
+	End(parent, OutcomeSuccess, errors.New("kaboom"))
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+
+	assert.Equal(t, "child", spans[0].Name())
+	assert.Equal(t, parent.SpanContext().SpanID(), spans[0].Parent().SpanID())
+	assert.Contains(t, spans[0].Attributes(), ImageDigest.String("sha256:4e38"))
+	assert.Contains(t, spans[0].Attributes(), Outcome.String(OutcomeFailure))
+
+	assert.Equal(t, "parent", spans[1].Name())
+	assert.Contains(t, spans[1].Attributes(), PolicySources.Int(2))
+	assert.Contains(t, spans[1].Attributes(), Outcome.String(OutcomeError))
+	assert.Equal(t, codes.Error, spans[1].Status().Code)
+	assert.Equal(t, "kaboom", spans[1].Status().Description)
+}
@@ -177,7 +177,12 @@ func setColorEnabled(flagNoColor, flagForceColor bool) bool {
 		return true
 	}
 	// Use color if we're in a terminal that can presumably display it
-	return isatty.IsTerminal(os.Stdout.Fd()) || isatty.IsCygwinTerminal(os.Stdout.Fd())
+	return IsTerminal(os.Stdout)
+}
+
+// IsTerminal returns true if the file is a terminal.
+func IsTerminal(f *os.File) bool {
+	return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
 }
 
 func anyEnvSet(varNames []string) bool {
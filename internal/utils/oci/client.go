@@ -18,12 +18,12 @@ package oci
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path"
 	"strconv"
 	"sync"
-	"time"
 
 	"github.com/google/go-containerregistry/pkg/authn"
 	"github.com/google/go-containerregistry/pkg/name"
@@ -38,8 +38,10 @@ import (
 
 // imageRefTransport is used to inject the type of transport to use with the
 // remote.WithTransport function. By default, remote.DefaultTransport is
-// equivalent to http.DefaultTransport, with a reduced timeout and keep-alive
-var imageRefTransport = remote.WithTransport(remote.DefaultTransport)
+// equivalent to http.DefaultTransport, with a reduced timeout and keep-alive.
+// It is wrapped to retry requests rejected by rate limiting registries, or
+// failing transiently.
+var imageRefTransport = remote.WithTransport(newRetryTransport(remote.DefaultTransport))
 
 type contextKey string
 
@@ -68,29 +70,32 @@ func initCache() cache.Cache {
 }
 
 func createRemoteOptions(ctx context.Context) []remote.Option {
-	backoff := remote.Backoff{
-		Duration: 1.0 * time.Second,
-		Factor:   2.0,
-		Jitter:   0.1,
-		Steps:    3,
-	}
-
-	return []remote.Option{
+	opts := []remote.Option{
 		imageRefTransport,
 		remote.WithContext(ctx),
 		remote.WithAuthFromKeychain(authn.DefaultKeychain),
-		remote.WithRetryBackoff(backoff),
+		// The requests are retried by the transport, within the retry budget,
+		// see newRetryTransport
+		remote.WithRetryStatusCodes(),
+		remote.WithRetryPredicate(func(error) bool { return false }),
 	}
+
+	// Options given later take precedence, replacing the transport and the
+	// keychain when redirecting to mirrors
+	return append(opts, RegistryRewriteOptions(ctx)...)
 }
 
 type Client interface {
 	VerifyImageSignatures(name.Reference, *cosign.CheckOpts) ([]oci.Signature, bool, error)
+	VerifyImageSignaturesFrom(name.Reference, name.Digest, *cosign.CheckOpts) ([]oci.Signature, bool, error)
 	VerifyImageAttestations(name.Reference, *cosign.CheckOpts) ([]oci.Signature, bool, error)
 	Head(name.Reference) (*v1.Descriptor, error)
 	ResolveDigest(name.Reference) (string, error)
 	Image(name.Reference) (v1.Image, error)
 	Layer(name.Digest) (v1.Layer, error)
 	Index(name.Reference) (v1.ImageIndex, error)
+	Referrers(name.Digest) (v1.ImageIndex, error)
+	ListTags(name.Repository) ([]string, error)
 }
 
 func WithClient(ctx context.Context, client Client) context.Context {
@@ -122,9 +127,110 @@ func (c *defaultClient) VerifyImageSignatures(ref name.Reference, opts *cosign.C
 	return cosign.VerifyImageSignatures(c.ctx, ref, opts)
 }
 
+// VerifyImageSignaturesFrom verifies the signatures of the image found in the
+// given signature artifact, instead of discovering them using the cosign
+// signature tag of the image.
+func (c *defaultClient) VerifyImageSignaturesFrom(ref name.Reference, signatures name.Digest, opts *cosign.CheckOpts) ([]oci.Signature, bool, error) {
+	if opts.RootCerts == nil && opts.SigVerifier == nil {
+		return nil, false, errors.New("one of verifier or root certs is required")
+	}
+
+	opts.RegistryClientOpts = append(opts.RegistryClientOpts, ociremote.WithRemoteOptions(c.opts...))
+
+	digest, err := ociremote.ResolveDigest(ref, opts.RegistryClientOpts...)
+	if err != nil {
+		return nil, false, err
+	}
+	h, err := v1.NewHash(digest.Identifier())
+	if err != nil {
+		return nil, false, err
+	}
+
+	sigs, err := ociremote.Signatures(signatures, opts.RegistryClientOpts...)
+	if err != nil {
+		return nil, false, fmt.Errorf("unable to fetch the signatures from %s: %w", signatures, err)
+	}
+	sl, err := sigs.Get()
+	if err != nil {
+		return nil, false, fmt.Errorf("unable to read the signatures from %s: %w", signatures, err)
+	}
+	if len(sl) == 0 {
+		return nil, false, fmt.Errorf("no signatures found in %s", signatures)
+	}
+
+	var checked []oci.Signature
+	var bundleVerified bool
+	var errs []error
+	for _, sig := range sl {
+		verified, err := cosign.VerifyImageSignature(c.ctx, sig, h, opts)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		bundleVerified = bundleVerified || verified
+		checked = append(checked, sig)
+	}
+
+	if len(checked) == 0 {
+		return nil, false, fmt.Errorf("no matching signatures found in %s: %w", signatures, errors.Join(errs...))
+	}
+
+	return checked, bundleVerified, nil
+}
+
+// NoAttestationsError is returned when the image has no attestations at all,
+// as opposed to having attestations none of which verify. Cosign reports both
+// with the same error, which is wrapped.
+type NoAttestationsError struct {
+	Err error
+}
+
+func (e *NoAttestationsError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *NoAttestationsError) Unwrap() error {
+	return e.Err
+}
+
 func (c *defaultClient) VerifyImageAttestations(ref name.Reference, opts *cosign.CheckOpts) ([]oci.Signature, bool, error) {
 	opts.RegistryClientOpts = append(opts.RegistryClientOpts, ociremote.WithRemoteOptions(c.opts...))
-	return cosign.VerifyImageAttestations(c.ctx, ref, opts)
+	atts, bundleVerified, err := cosign.VerifyImageAttestations(c.ctx, ref, opts)
+
+	var noMatching *cosign.ErrNoMatchingAttestations
+	if errors.As(err, &noMatching) {
+		if found, ferr := hasAttestations(ref, opts); ferr == nil && !found {
+			return nil, false, &NoAttestationsError{Err: err}
+		}
+	}
+
+	return atts, bundleVerified, err
+}
+
+// hasAttestations returns true if any attestations are attached to the image
+// using the cosign attestation tag, regardless of whether they verify.
+func hasAttestations(ref name.Reference, opts *cosign.CheckOpts) (bool, error) {
+	digest, err := ociremote.ResolveDigest(ref, opts.RegistryClientOpts...)
+	if err != nil {
+		return false, err
+	}
+
+	tag, err := ociremote.AttestationTag(digest, opts.RegistryClientOpts...)
+	if err != nil {
+		return false, err
+	}
+
+	atts, err := ociremote.Signatures(tag, opts.RegistryClientOpts...)
+	if err != nil {
+		return false, err
+	}
+
+	sl, err := atts.Get()
+	if err != nil {
+		return false, err
+	}
+
+	return len(sl) > 0, nil
 }
 
 func (c *defaultClient) Head(ref name.Reference) (*v1.Descriptor, error) {
@@ -194,3 +300,24 @@ func (c *defaultClient) Index(ref name.Reference) (v1.ImageIndex, error) {
 
 	return index, nil
 }
+
+// Referrers returns the index of the artifacts referring to the image, e.g.
+// signatures or SBOMs, using the OCI referrers API or the fallback tag.
+func (c *defaultClient) Referrers(ref name.Digest) (v1.ImageIndex, error) {
+	index, err := remote.Referrers(ref, c.opts...)
+	if err != nil {
+		return nil, fmt.Errorf("fetching referrers: %w", err)
+	}
+
+	return index, nil
+}
+
+// ListTags returns the tags of the repository.
+func (c *defaultClient) ListTags(repo name.Repository) ([]string, error) {
+	tags, err := remote.List(repo, c.opts...)
+	if err != nil {
+		return nil, fmt.Errorf("listing tags: %w", err)
+	}
+
+	return tags, nil
+}
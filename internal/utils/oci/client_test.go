@@ -21,6 +21,11 @@ package oci
 import (
 	"bytes"
 	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -28,6 +33,7 @@ import (
 	"net/url"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-containerregistry/pkg/authn"
 	"github.com/google/go-containerregistry/pkg/name"
@@ -35,6 +41,13 @@ import (
 	"github.com/google/go-containerregistry/pkg/v1/random"
 	"github.com/google/go-containerregistry/pkg/v1/remote"
 	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/sigstore/cosign/v2/pkg/cosign"
+	"github.com/sigstore/cosign/v2/pkg/oci/mutate"
+	ociremote "github.com/sigstore/cosign/v2/pkg/oci/remote"
+	"github.com/sigstore/cosign/v2/pkg/oci/static"
+	ctypes "github.com/sigstore/cosign/v2/pkg/types"
+	"github.com/sigstore/sigstore/pkg/signature"
+	"github.com/sigstore/sigstore/pkg/signature/dsse"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -62,10 +75,14 @@ func TestCreateRemoteOptions(t *testing.T) {
 			wantErr: true,
 		},
 	}
+	original := wait
+	t.Cleanup(func() { wait = original })
+	wait = func(context.Context, time.Duration) error { return nil }
+
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			if tt.wantRetry {
-				imageRefTransport = remote.WithTransport(&mocks.HttpTransportTimeoutFailure{})
+				imageRefTransport = remote.WithTransport(newRetryTransport(&mocks.HttpTransportTimeoutFailure{}))
 			} else if tt.wantErr {
 				imageRefTransport = remote.WithTransport(&mocks.HttpTransportMockFailure{})
 			} else {
@@ -211,3 +228,79 @@ func TestScopedAuth(t *testing.T) {
 		})
 	}
 }
+
+func TestVerifyImageAttestationsMissingOrInvalid(t *testing.T) {
+	registry := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	t.Cleanup(registry.Close)
+
+	u, err := url.Parse(registry.URL)
+	require.NoError(t, err)
+
+	push := func(repository string) name.Reference {
+		img, err := random.Image(1024, 1)
+		require.NoError(t, err)
+
+		ref, err := name.ParseReference(fmt.Sprintf("localhost:%s/%s:tag", u.Port(), repository))
+		require.NoError(t, err)
+		require.NoError(t, remote.Push(ref, img))
+
+		return ref
+	}
+
+	newSigner := func() signature.SignerVerifier {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+		signer, err := signature.LoadECDSASignerVerifier(key, crypto.SHA256)
+		require.NoError(t, err)
+
+		return signer
+	}
+
+	attest := func(ref name.Reference, signer signature.Signer) {
+		statement := []byte(`{"_type":"https://in-toto.io/Statement/v0.1","predicateType":"https://example.com/predicate","subject":[],"predicate":{}}`)
+		envelope, err := dsse.WrapSigner(signer, ctypes.IntotoPayloadType).SignMessage(bytes.NewReader(statement))
+		require.NoError(t, err)
+
+		att, err := static.NewAttestation(envelope)
+		require.NoError(t, err)
+
+		digest, err := ociremote.ResolveDigest(ref)
+		require.NoError(t, err)
+		se, err := ociremote.SignedEntity(digest)
+		require.NoError(t, err)
+		se, err = mutate.AttachAttestationToEntity(se, att)
+		require.NoError(t, err)
+		require.NoError(t, ociremote.WriteAttestations(digest.Repository, se))
+	}
+
+	verifier := newSigner()
+
+	cases := []struct {
+		name    string
+		ref     name.Reference
+		missing bool
+	}{
+		{name: "no attestations", ref: push("missing"), missing: true},
+		{name: "signed with another key", ref: func() name.Reference {
+			ref := push("forged")
+			attest(ref, newSigner())
+			return ref
+		}()},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			client := defaultClient{ctx: context.Background()}
+			_, _, err := client.VerifyImageAttestations(c.ref, &cosign.CheckOpts{
+				SigVerifier: verifier,
+				IgnoreTlog:  true,
+			})
+
+			var noMatching *cosign.ErrNoMatchingAttestations
+			assert.ErrorAs(t, err, &noMatching)
+
+			var noAttestations *NoAttestationsError
+			assert.Equal(t, c.missing, errors.As(err, &noAttestations))
+		})
+	}
+}
@@ -92,6 +92,15 @@ func (m *FakeClient) VerifyImageSignatures(ref name.Reference, opts *cosign.Chec
 	return sigs, args.Bool(1), args.Error(2)
 }
 
+func (m *FakeClient) VerifyImageSignaturesFrom(ref name.Reference, signatures name.Digest, opts *cosign.CheckOpts) ([]cosignoci.Signature, bool, error) {
+	args := m.Called(ref, signatures, opts)
+	var sigs []cosignoci.Signature
+	if maybeSigs, ok := args.Get(0).([]cosignoci.Signature); ok {
+		sigs = maybeSigs
+	}
+	return sigs, args.Bool(1), args.Error(2)
+}
+
 func (m *FakeClient) VerifyImageAttestations(ref name.Reference, opts *cosign.CheckOpts) ([]cosignoci.Signature, bool, error) {
 	args := m.Called(ref, opts)
 	var sigs []cosignoci.Signature
@@ -143,3 +152,21 @@ func (m *FakeClient) Index(ref name.Reference) (v1.ImageIndex, error) {
 	}
 	return index, args.Error(1)
 }
+
+func (m *FakeClient) Referrers(ref name.Digest) (v1.ImageIndex, error) {
+	args := m.Called(ref)
+	var index v1.ImageIndex
+	if maybeIndex, ok := args.Get(0).(v1.ImageIndex); ok {
+		index = maybeIndex
+	}
+	return index, args.Error(1)
+}
+
+func (m *FakeClient) ListTags(repo name.Repository) ([]string, error) {
+	args := m.Called(repo)
+	var tags []string
+	if maybeTags, ok := args.Get(0).([]string); ok {
+		tags = maybeTags
+	}
+	return tags, args.Error(1)
+}
@@ -0,0 +1,83 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package oci
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+)
+
+// WithImageMetadata returns a context with a client that fetches the
+// descriptor and the image of each reference at most once, e.g. for the
+// checks of an image, which read the manifest and the config of the same
+// image. The image fetched holds only the manifest, the config blob is
+// fetched when first read, and the layers only when read, e.g. to extract
+// files from the image. Failed requests are not remembered.
+func WithImageMetadata(ctx context.Context) context.Context {
+	client := NewClient(ctx)
+	if _, ok := client.(*metadataClient); ok {
+		return ctx
+	}
+
+	return WithClient(ctx, &metadataClient{Client: client})
+}
+
+type metadataClient struct {
+	Client
+	mu          sync.Mutex
+	descriptors map[string]*v1.Descriptor
+	images      map[string]v1.Image
+}
+
+func (c *metadataClient) Head(ref name.Reference) (*v1.Descriptor, error) {
+	return remember(&c.mu, &c.descriptors, ref, c.Client.Head)
+}
+
+func (c *metadataClient) Image(ref name.Reference) (v1.Image, error) {
+	return remember(&c.mu, &c.images, ref, c.Client.Image)
+}
+
+// remember returns the value fetched for the reference before, or fetches it.
+// The lock is not held while fetching, concurrent fetches of the same
+// reference are redundant but harmless.
+func remember[T any](mu *sync.Mutex, values *map[string]T, ref name.Reference, fetch func(name.Reference) (T, error)) (T, error) {
+	key := ref.Name()
+
+	mu.Lock()
+	v, ok := (*values)[key]
+	mu.Unlock()
+	if ok {
+		return v, nil
+	}
+
+	v, err := fetch(ref)
+	if err != nil {
+		return v, err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if *values == nil {
+		*values = map[string]T{}
+	}
+	(*values)[key] = v
+
+	return v, nil
+}
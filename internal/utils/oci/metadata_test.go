@@ -0,0 +1,86 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package oci
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingClient counts the requests, failing the first request of each kind
+type countingClient struct {
+	Client
+	heads, images int
+}
+
+func (c *countingClient) Head(name.Reference) (*v1.Descriptor, error) {
+	c.heads++
+	if c.heads == 1 {
+		return nil, errors.New("unavailable")
+	}
+	return &v1.Descriptor{Size: 1}, nil
+}
+
+func (c *countingClient) Image(name.Reference) (v1.Image, error) {
+	c.images++
+	if c.images == 1 {
+		return nil, errors.New("unavailable")
+	}
+	return empty.Image, nil
+}
+
+func TestWithImageMetadata(t *testing.T) {
+	counting := &countingClient{}
+	ctx := WithImageMetadata(WithClient(context.Background(), counting))
+	// nested calls keep the fetched metadata
+	ctx = WithImageMetadata(ctx)
+	client := NewClient(ctx)
+
+	ref := name.MustParseReference("registry.local/image:tag")
+	other := name.MustParseReference("registry.local/other:tag")
+
+	_, err := client.Head(ref)
+	assert.Error(t, err)
+	_, err = client.Image(ref)
+	assert.Error(t, err)
+
+	for i := 0; i < 3; i++ {
+		desc, err := client.Head(ref)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), desc.Size)
+
+		img, err := client.Image(ref)
+		require.NoError(t, err)
+		assert.Equal(t, empty.Image, img)
+	}
+	// the failed requests are retried, the successful ones are not repeated
+	assert.Equal(t, 2, counting.heads)
+	assert.Equal(t, 2, counting.images)
+
+	_, err = client.Image(other)
+	require.NoError(t, err)
+	assert.Equal(t, 3, counting.images)
+}
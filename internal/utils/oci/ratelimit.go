@@ -0,0 +1,221 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package oci
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// maxRetries is the number of times a failed request is retried before
+	// giving up
+	maxRetries = 3
+	// maxRetryAfter caps the delay requested by the registry via the
+	// Retry-After header
+	maxRetryAfter = 60 * time.Second
+	// defaultRetryDelay is the initial delay when the registry does not
+	// provide the Retry-After header, doubled on each retry
+	defaultRetryDelay = 1 * time.Second
+)
+
+// retryStatusCodes are the responses, besides rate limiting, retried as
+// transient failures of the registry, as go-containerregistry would
+var retryStatusCodes = []int{
+	http.StatusRequestTimeout,
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+	499, // nginx-specific, client closed request
+	522, // Cloudflare-specific, connection timeout
+}
+
+// wait blocks for the given duration or until the context is done, replaced in
+// tests to avoid waiting
+var wait = func(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// retryTransport retries requests the registry responded to with HTTP 429
+// Too Many Requests, honoring the delay given by the Retry-After header, and
+// requests failing due to transient failures of the registry or the network.
+// It is the only retry layer: go-containerregistry is configured not to retry
+// any responses, see createRemoteOptions, and network failures are returned
+// as retriedError so they are not retried again.
+type retryTransport struct {
+	inner http.RoundTripper
+}
+
+func newRetryTransport(inner http.RoundTripper) http.RoundTripper {
+	return &retryTransport{inner: inner}
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		resp, err := t.inner.RoundTrip(req)
+		delay, retry := retryDelay(resp, err, attempt)
+		if !retry || attempt >= maxRetries {
+			return resp, retried(err)
+		}
+
+		// The request given is not modified, requests with a body are retried
+		// using a copy with the body recreated
+		if req.Body != nil && req.Body != http.NoBody {
+			if req.GetBody == nil {
+				return resp, retried(err)
+			}
+			body, berr := req.GetBody()
+			if berr != nil {
+				return resp, retried(err)
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+
+		// The delay is taken from the retry budget shared by all requests
+		if !retryBudget(req.Context()).take(delay) {
+			return resp, retried(err)
+		}
+
+		if resp != nil {
+			resp.Body.Close()
+		}
+
+		if err != nil {
+			log.Warnf("Request to %s failed: %s, retrying in %s", req.URL.Host, err, delay)
+		} else if resp.StatusCode == http.StatusTooManyRequests {
+			log.Warnf("Rate limited by %s, retrying in %s", req.URL.Host, delay)
+		} else {
+			log.Warnf("Request to %s failed with %s, retrying in %s", req.URL.Host, resp.Status, delay)
+		}
+		if err := wait(req.Context(), delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// retryDelay returns the delay before retrying the request with the given
+// outcome, and false if it is not to be retried.
+func retryDelay(resp *http.Response, err error, attempt int) (time.Duration, bool) {
+	backoff := defaultRetryDelay << attempt
+
+	switch {
+	case err != nil:
+		return backoff, retryableError(err)
+	case resp.StatusCode == http.StatusTooManyRequests:
+		if delay := retryAfter(resp.Header.Get("Retry-After"), time.Now()); delay > 0 {
+			return delay, true
+		}
+		return backoff, true
+	case slices.Contains(retryStatusCodes, resp.StatusCode):
+		return backoff, true
+	}
+
+	return 0, false
+}
+
+// retryableError returns true for the network failures go-containerregistry
+// would retry.
+func retryableError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var temporary interface{ Temporary() bool }
+	if errors.As(err, &temporary) && temporary.Temporary() {
+		return true
+	}
+
+	return errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) || errors.Is(err, syscall.EPIPE) || errors.Is(err, syscall.ECONNRESET) || errors.Is(err, net.ErrClosed)
+}
+
+// retriedError is a network failure of a request retried already. It hides
+// the cause from go-containerregistry, which would retry it again otherwise.
+type retriedError struct {
+	err error
+}
+
+func (e *retriedError) Error() string {
+	return e.err.Error()
+}
+
+func retried(err error) error {
+	if err == nil || !retryableError(err) {
+		return err
+	}
+
+	return &retriedError{err: err}
+}
+
+// retryAfter parses the value of the Retry-After header, given either in
+// seconds or as an HTTP date, returning zero if the value is missing or
+// invalid.
+func retryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+
+	var delay time.Duration
+	if seconds, err := strconv.Atoi(value); err == nil {
+		delay = time.Duration(seconds) * time.Second
+	} else if at, err := http.ParseTime(value); err == nil {
+		delay = at.Sub(now)
+	}
+
+	if delay < 0 {
+		return 0
+	}
+
+	return min(delay, maxRetryAfter)
+}
+
+// IsRateLimited returns true if the error was caused by the registry rate
+// limiting the requests.
+func IsRateLimited(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var terr *transport.Error
+	if errors.As(err, &terr) && terr.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+
+	// Not all errors are wrapped, e.g. some returned by cosign
+	msg := err.Error()
+	return strings.Contains(msg, string(transport.TooManyRequestsErrorCode)) || strings.Contains(msg, "429 Too Many Requests")
+}
@@ -0,0 +1,194 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package oci
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, time.Duration(0), retryAfter("", now))
+	assert.Equal(t, time.Duration(0), retryAfter("soon", now))
+	assert.Equal(t, 5*time.Second, retryAfter("5", now))
+	assert.Equal(t, maxRetryAfter, retryAfter("3600", now))
+	assert.Equal(t, 10*time.Second, retryAfter(now.Add(10*time.Second).Format(http.TimeFormat), now))
+	assert.Equal(t, time.Duration(0), retryAfter(now.Add(-10*time.Second).Format(http.TimeFormat), now))
+}
+
+func TestRetryTransportRateLimited(t *testing.T) {
+	var delays []time.Duration
+	original := wait
+	t.Cleanup(func() { wait = original })
+	wait = func(_ context.Context, d time.Duration) error {
+		delays = append(delays, d)
+		return nil
+	}
+
+	cases := []struct {
+		name       string
+		limited    int
+		retryAfter string
+		status     int
+		delays     []time.Duration
+	}{
+		{name: "not limited", status: http.StatusOK},
+		{name: "honors Retry-After", limited: 2, retryAfter: "7", status: http.StatusOK, delays: []time.Duration{7 * time.Second, 7 * time.Second}},
+		{name: "backs off without Retry-After", limited: 2, status: http.StatusOK, delays: []time.Duration{time.Second, 2 * time.Second}},
+		{name: "gives up", limited: 10, retryAfter: "1", status: http.StatusTooManyRequests, delays: []time.Duration{time.Second, time.Second, time.Second}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			delays = nil
+			requests := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				requests++
+				if requests <= c.limited {
+					if c.retryAfter != "" {
+						w.Header().Set("Retry-After", c.retryAfter)
+					}
+					w.WriteHeader(http.StatusTooManyRequests)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+			t.Cleanup(server.Close)
+
+			resp, err := (&http.Client{Transport: newRetryTransport(http.DefaultTransport)}).Get(server.URL)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.Equal(t, c.status, resp.StatusCode)
+			assert.Equal(t, c.delays, delays)
+		})
+	}
+}
+
+func TestRetryTransportTransientFailures(t *testing.T) {
+	var delays []time.Duration
+	original := wait
+	t.Cleanup(func() { wait = original })
+	wait = func(_ context.Context, d time.Duration) error {
+		delays = append(delays, d)
+		return nil
+	}
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(server.Close)
+
+	ref, err := name.ParseReference(server.Listener.Addr().String() + "/image:tag")
+	require.NoError(t, err)
+
+	// go-containerregistry does not retry the retried requests again
+	_, err = remote.Head(ref, remote.WithTransport(newRetryTransport(http.DefaultTransport)), remote.WithRetryStatusCodes())
+	assert.Error(t, err)
+	assert.Equal(t, maxRetries+1, requests)
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second, 4 * time.Second}, delays)
+
+	delays = nil
+	failures := 0
+	inner := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		failures++
+		return nil, syscall.ECONNRESET
+	})
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+	_, err = newRetryTransport(inner).RoundTrip(req)
+	assert.EqualError(t, err, syscall.ECONNRESET.Error())
+	assert.False(t, errors.Is(err, syscall.ECONNRESET), "the retried failure is not retried again")
+	assert.Equal(t, maxRetries+1, failures)
+	assert.Len(t, delays, maxRetries)
+}
+
+func TestRetryTransportDoesNotModifyRequest(t *testing.T) {
+	original := wait
+	t.Cleanup(func() { wait = original })
+	wait = func(context.Context, time.Duration) error { return nil }
+
+	var bodies []string
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		b, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(b))
+		if requests == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	req, err := http.NewRequest(http.MethodPut, server.URL, strings.NewReader("body"))
+	require.NoError(t, err)
+	body := req.Body
+
+	resp, err := newRetryTransport(http.DefaultTransport).RoundTrip(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, []string{"body", "body"}, bodies)
+	assert.True(t, req.Body == body, "the given request is not modified")
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestIsRateLimited(t *testing.T) {
+	assert.False(t, IsRateLimited(nil))
+	assert.False(t, IsRateLimited(errors.New("something else")))
+	assert.True(t, IsRateLimited(fmt.Errorf("wrapped: %w", &transport.Error{StatusCode: http.StatusTooManyRequests})))
+	assert.True(t, IsRateLimited(errors.New("GET https://index.docker.io/v2/: TOOMANYREQUESTS: You have reached your pull rate limit")))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	t.Cleanup(server.Close)
+
+	ref, err := name.ParseReference(server.Listener.Addr().String() + "/image:tag")
+	require.NoError(t, err)
+
+	_, err = remote.Head(ref, remote.WithTransport(http.DefaultTransport))
+	assert.True(t, IsRateLimited(err))
+}
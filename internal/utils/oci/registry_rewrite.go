@@ -0,0 +1,150 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package oci
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	log "github.com/sirupsen/logrus"
+)
+
+const registryRewritesContextKey contextKey = "ec.oci.registryRewrites"
+
+// RegistryRewrites maps the upstream registry hosts to the hosts of the
+// mirrors, e.g. pull-through caches, the images are fetched from.
+type RegistryRewrites map[string]string
+
+// ParseRegistryRewrites parses the rewrite rules given as upstream=mirror, the
+// upstream and the mirror being registry hosts, optionally with a port.
+func ParseRegistryRewrites(rules []string) (RegistryRewrites, error) {
+	rewrites := RegistryRewrites{}
+	for _, rule := range rules {
+		upstream, mirror, ok := strings.Cut(rule, "=")
+		if !ok || upstream == "" || mirror == "" {
+			return nil, fmt.Errorf("invalid registry rewrite %q, expecting upstream=mirror", rule)
+		}
+
+		upstreamRegistry, err := registryHost(upstream)
+		if err != nil {
+			return nil, fmt.Errorf("invalid upstream registry of the registry rewrite %q: %w", rule, err)
+		}
+		mirrorRegistry, err := registryHost(mirror)
+		if err != nil {
+			return nil, fmt.Errorf("invalid mirror registry of the registry rewrite %q: %w", rule, err)
+		}
+
+		if existing, ok := rewrites[upstreamRegistry]; ok && existing != mirrorRegistry {
+			return nil, fmt.Errorf("conflicting registry rewrites of %s to %s and %s", upstream, existing, mirrorRegistry)
+		}
+		rewrites[upstreamRegistry] = mirrorRegistry
+	}
+
+	return rewrites, nil
+}
+
+// registryHost validates and normalizes the registry host, e.g. docker.io is
+// normalized to index.docker.io as requested by the client
+func registryHost(host string) (string, error) {
+	if strings.Contains(host, "/") {
+		return "", fmt.Errorf("expecting a registry host without a path, got %q", host)
+	}
+
+	registry, err := name.NewRegistry(host, name.StrictValidation)
+	if err != nil {
+		return "", err
+	}
+
+	return registry.RegistryStr(), nil
+}
+
+// WithRegistryRewrites redirects the fetches of images, their signatures and
+// attestations from the upstream registries to the mirrors. The image
+// references are not changed, so the upstream references are reported and
+// used when looking up the signatures and attestations.
+func WithRegistryRewrites(ctx context.Context, rewrites RegistryRewrites) context.Context {
+	return context.WithValue(ctx, registryRewritesContextKey, rewrites)
+}
+
+// RegistryRewriteOptions returns the options redirecting the requests to the
+// mirrors, for use with the remote package, nothing if no registry rewrites
+// were configured.
+func RegistryRewriteOptions(ctx context.Context) []remote.Option {
+	rewrites, ok := ctx.Value(registryRewritesContextKey).(RegistryRewrites)
+	if !ok || len(rewrites) == 0 {
+		return nil
+	}
+
+	return []remote.Option{
+		remote.WithTransport(newRegistryRewriteTransport(rewrites, newRetryTransport(remote.DefaultTransport))),
+		remote.WithAuthFromKeychain(&registryRewriteKeychain{rewrites: rewrites, inner: authn.DefaultKeychain}),
+	}
+}
+
+// registryRewriteTransport sends the requests made to the upstream registries
+// to the mirrors. It is the innermost transport, so authentication and the
+// handling of responses see the upstream registry.
+type registryRewriteTransport struct {
+	rewrites RegistryRewrites
+	inner    http.RoundTripper
+}
+
+func newRegistryRewriteTransport(rewrites RegistryRewrites, inner http.RoundTripper) http.RoundTripper {
+	return &registryRewriteTransport{rewrites: rewrites, inner: inner}
+}
+
+func (t *registryRewriteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	mirror, ok := t.rewrites[req.URL.Host]
+	if !ok {
+		return t.inner.RoundTrip(req)
+	}
+
+	log.Tracef("Fetching %s from the mirror %s", req.URL, mirror)
+	// The request must not be modified by the transport
+	rewritten := req.Clone(req.Context())
+	rewritten.URL.Host = mirror
+	rewritten.Host = mirror
+
+	return t.inner.RoundTrip(rewritten)
+}
+
+// registryRewriteKeychain uses the credentials of the mirror when accessing
+// the upstream registry, the credentials of the upstream registry are never
+// sent to the mirror.
+type registryRewriteKeychain struct {
+	rewrites RegistryRewrites
+	inner    authn.Keychain
+}
+
+func (k *registryRewriteKeychain) Resolve(target authn.Resource) (authn.Authenticator, error) {
+	mirror, ok := k.rewrites[target.RegistryStr()]
+	if !ok {
+		return k.inner.Resolve(target)
+	}
+
+	registry, err := name.NewRegistry(mirror)
+	if err != nil {
+		return nil, err
+	}
+
+	return k.inner.Resolve(registry)
+}
@@ -0,0 +1,126 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package oci
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRegistryRewrites(t *testing.T) {
+	cases := []struct {
+		name     string
+		rules    []string
+		expected RegistryRewrites
+		err      string
+	}{
+		{name: "none", expected: RegistryRewrites{}},
+		{
+			name:     "rewrites",
+			rules:    []string{"quay.io=mirror.example.com", "docker.io=mirror.example.com:5000", "quay.io=mirror.example.com"},
+			expected: RegistryRewrites{"quay.io": "mirror.example.com", "index.docker.io": "mirror.example.com:5000"},
+		},
+		{name: "missing mirror", rules: []string{"quay.io="}, err: `invalid registry rewrite "quay.io=", expecting upstream=mirror`},
+		{name: "missing separator", rules: []string{"quay.io"}, err: `invalid registry rewrite "quay.io", expecting upstream=mirror`},
+		{name: "path", rules: []string{"quay.io=mirror.example.com/quay"}, err: `invalid mirror registry of the registry rewrite "quay.io=mirror.example.com/quay": expecting a registry host without a path`},
+		{name: "invalid host", rules: []string{"quay io=mirror.example.com"}, err: `invalid upstream registry of the registry rewrite "quay io=mirror.example.com"`},
+		{name: "conflict", rules: []string{"quay.io=a.example.com", "quay.io=b.example.com"}, err: "conflicting registry rewrites of quay.io to a.example.com and b.example.com"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			rewrites, err := ParseRegistryRewrites(c.rules)
+			if c.err != "" {
+				assert.ErrorContains(t, err, c.err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, c.expected, rewrites)
+		})
+	}
+}
+
+type recordingTransport struct {
+	hosts []string
+}
+
+func (r *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	r.hosts = append(r.hosts, req.URL.Host+" "+req.Host)
+
+	return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Request: req}, nil
+}
+
+func TestRegistryRewriteTransport(t *testing.T) {
+	inner := &recordingTransport{}
+	transport := newRegistryRewriteTransport(RegistryRewrites{"quay.io": "mirror.example.com"}, inner)
+
+	req, err := http.NewRequest(http.MethodGet, "https://quay.io/v2/org/name/manifests/sha256-abc.sig", nil)
+	require.NoError(t, err)
+	_, err = transport.RoundTrip(req)
+	require.NoError(t, err)
+	assert.Equal(t, "quay.io", req.URL.Host, "the request is not modified")
+
+	req, err = http.NewRequest(http.MethodGet, "https://registry.io/v2/org/name/manifests/latest", nil)
+	require.NoError(t, err)
+	_, err = transport.RoundTrip(req)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"mirror.example.com mirror.example.com", "registry.io registry.io"}, inner.hosts)
+}
+
+type recordingKeychain struct {
+	registries []string
+}
+
+func (r *recordingKeychain) Resolve(target authn.Resource) (authn.Authenticator, error) {
+	r.registries = append(r.registries, target.RegistryStr())
+
+	return authn.Anonymous, nil
+}
+
+func TestRegistryRewriteKeychain(t *testing.T) {
+	inner := &recordingKeychain{}
+	keychain := &registryRewriteKeychain{rewrites: RegistryRewrites{"index.docker.io": "mirror.example.com"}, inner: inner}
+
+	for _, ref := range []string{"docker.io/library/image:latest", "quay.io/org/name:latest"} {
+		r, err := name.ParseReference(ref)
+		require.NoError(t, err)
+		_, err = keychain.Resolve(r.Context())
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, []string{"mirror.example.com", "quay.io"}, inner.registries)
+}
+
+func TestRegistryRewriteOptions(t *testing.T) {
+	ctx := context.Background()
+	assert.Empty(t, RegistryRewriteOptions(ctx))
+	assert.Len(t, createRemoteOptions(ctx), 5)
+
+	ctx = WithRegistryRewrites(ctx, RegistryRewrites{"quay.io": "mirror.example.com"})
+	assert.Len(t, RegistryRewriteOptions(ctx), 2)
+	assert.Len(t, createRemoteOptions(ctx), 7)
+}
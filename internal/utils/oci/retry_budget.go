@@ -0,0 +1,93 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package oci
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const retryBudgetContextKey contextKey = "ec.oci.retry-budget"
+
+// RetryBudget is the cumulative delay the retries of all requests can wait
+// for. It is shared by all requests of a run, so that a sustained outage fails
+// the run promptly rather than each request retrying in isolation.
+type RetryBudget struct {
+	mu        sync.Mutex
+	budget    time.Duration
+	remaining time.Duration
+	exhausted bool
+}
+
+// NewRetryBudget creates a retry budget of the given cumulative delay.
+func NewRetryBudget(budget time.Duration) *RetryBudget {
+	return &RetryBudget{budget: budget, remaining: budget}
+}
+
+// WithRetryBudget sets the retry budget shared by the requests made with the
+// context.
+func WithRetryBudget(ctx context.Context, b *RetryBudget) context.Context {
+	return context.WithValue(ctx, retryBudgetContextKey, b)
+}
+
+func retryBudget(ctx context.Context) *RetryBudget {
+	if b, ok := ctx.Value(retryBudgetContextKey).(*RetryBudget); ok {
+		return b
+	}
+
+	return nil
+}
+
+// take reserves the delay of a retry from the budget, returning false if the
+// budget does not allow for it. Once a retry is refused the budget is
+// exhausted and no further retries are allowed.
+func (b *RetryBudget) take(delay time.Duration) bool {
+	if b == nil {
+		return true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.exhausted {
+		return false
+	}
+
+	if delay > b.remaining {
+		b.exhausted = true
+		log.Warnf("The retry budget of %s is exhausted, failed requests are no longer retried", b.budget)
+		return false
+	}
+
+	b.remaining -= delay
+	return true
+}
+
+// Exhausted returns true if a retry was refused because the budget was spent.
+func (b *RetryBudget) Exhausted() bool {
+	if b == nil {
+		return false
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.exhausted
+}
@@ -0,0 +1,125 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package oci
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryBudget(t *testing.T) {
+	b := NewRetryBudget(5 * time.Second)
+
+	assert.True(t, b.take(2*time.Second))
+	assert.True(t, b.take(3*time.Second))
+	assert.False(t, b.Exhausted())
+
+	assert.False(t, b.take(time.Second))
+	assert.True(t, b.Exhausted())
+	// No further retries once exhausted, however short
+	assert.False(t, b.take(0))
+
+	var unlimited *RetryBudget
+	assert.True(t, unlimited.take(time.Hour))
+	assert.False(t, unlimited.Exhausted())
+}
+
+func TestRateLimitTransportRetryBudget(t *testing.T) {
+	var delays []time.Duration
+	original := wait
+	t.Cleanup(func() { wait = original })
+	wait = func(_ context.Context, d time.Duration) error {
+		delays = append(delays, d)
+		return nil
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Retry-After", "2")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	t.Cleanup(server.Close)
+
+	b := NewRetryBudget(5 * time.Second)
+	ctx := WithRetryBudget(context.Background(), b)
+	client := http.Client{Transport: newRetryTransport(http.DefaultTransport)}
+
+	get := func() int {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+		require.NoError(t, err)
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, http.StatusTooManyRequests, get())
+	assert.Equal(t, []time.Duration{2 * time.Second, 2 * time.Second}, delays)
+	assert.True(t, b.Exhausted())
+
+	// The budget is shared, subsequent requests are not retried
+	delays = nil
+	assert.Equal(t, http.StatusTooManyRequests, get())
+	assert.Nil(t, delays)
+}
+
+func TestRetryTransportRetryBudgetTransientFailures(t *testing.T) {
+	var delays []time.Duration
+	original := wait
+	t.Cleanup(func() { wait = original })
+	wait = func(_ context.Context, d time.Duration) error {
+		delays = append(delays, d)
+		return nil
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(server.Close)
+
+	b := NewRetryBudget(2 * time.Second)
+	ctx := WithRetryBudget(context.Background(), b)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+	resp, err := newRetryTransport(http.DefaultTransport).RoundTrip(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, []time.Duration{time.Second}, delays)
+	assert.True(t, b.Exhausted())
+
+	// Network failures are budgeted likewise
+	delays = nil
+	req, err = http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+	_, err = newRetryTransport(roundTripFunc(func(*http.Request) (*http.Response, error) {
+		return nil, syscall.ECONNRESET
+	})).RoundTrip(req)
+	assert.Error(t, err)
+	assert.Nil(t, delays)
+}
@@ -86,9 +86,9 @@ func RenderFromTemplatesWithGlob(input any, main string, glob []string, efs embe
 
 func passWarnFailChooser(color string, choices []string) string {
 	switch strings.ToLower(color) {
-	case "violation", "fail", "red":
+	case "violation", "fail", "red", "critical", "high":
 		return choices[0]
-	case "warning", "warn", "yellow":
+	case "warning", "warn", "yellow", "medium", "low":
 		return choices[1]
 	case "success", "pass", "green":
 		return choices[2]
@@ -0,0 +1,56 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit || integration
+
+// The contents of this file are meant to assist in writing unit tests. It requires the "unit" build
+// tag which is not included when building the ec binary.
+package utils
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/yaml"
+)
+
+// AssertOutputEqual decodes the expected and the actual output, in either JSON
+// or YAML format, into values of type T and asserts that they're structurally
+// equal. Key order, whitespace and the choice between JSON and YAML do not
+// matter. On mismatch the failure message contains a diff of the decoded
+// values. Fields unknown to T fail the assertion, so that fields added to the
+// output, or renamed, are not silently ignored. Use a struct type, e.g.
+// applicationsnapshot.Report, or map[string]any to compare any output.
+func AssertOutputEqual[T any](t testing.TB, expected, actual string, msgAndArgs ...any) bool {
+	t.Helper()
+
+	var e, a T
+	if err := yaml.UnmarshalStrict([]byte(expected), &e); err != nil {
+		return assert.Fail(t, "unable to decode the expected output: "+err.Error(), msgAndArgs...)
+	}
+	if err := yaml.UnmarshalStrict([]byte(actual), &a); err != nil {
+		return assert.Fail(t, "unable to decode the actual output: "+err.Error(), msgAndArgs...)
+	}
+
+	// Unexported fields are never decoded, comparing them is safe
+	if diff := cmp.Diff(e, a, cmp.Exporter(func(reflect.Type) bool { return true })); diff != "" {
+		return assert.Fail(t, "output mismatch (-expected +actual):\n"+diff, msgAndArgs...)
+	}
+
+	return true
+}
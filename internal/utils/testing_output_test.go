@@ -0,0 +1,64 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package utils
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingT struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recordingT) Helper() {}
+
+func (r *recordingT) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+type sample struct {
+	Name  string   `json:"name"`
+	Items []string `json:"items"`
+}
+
+func TestAssertOutputEqual(t *testing.T) {
+	AssertOutputEqual[sample](t, `{"name": "a", "items": ["first", "second"]}`, `{"items":["first","second"],"name":"a"}`)
+	AssertOutputEqual[sample](t, `{"name": "a", "items": ["first", "second"]}`, "name: a\nitems:\n- first\n- second\n")
+	AssertOutputEqual[map[string]any](t, `{"name": "a", "nested": {"b": 1}}`, "nested:\n  b: 1\nname: a\n")
+
+	r := &recordingT{TB: t}
+	assert.False(t, AssertOutputEqual[sample](r, `{"name": "a"}`, `{"name": "b"}`))
+	assert.Len(t, r.errors, 1)
+	assert.Contains(t, r.errors[0], "output mismatch (-expected +actual)")
+	assert.Contains(t, r.errors[0], `Name:  "a"`)
+	assert.Contains(t, r.errors[0], `Name:  "b"`)
+
+	r = &recordingT{TB: t}
+	assert.False(t, AssertOutputEqual[sample](r, `{"name": "a"}`, `{`))
+	assert.Contains(t, r.errors[0], "unable to decode the actual output")
+
+	r = &recordingT{TB: t}
+	assert.False(t, AssertOutputEqual[sample](r, `{"name": "a"}`, `{"name": "a", "unknown": true}`))
+	assert.Contains(t, r.errors[0], "unable to decode the actual output")
+	assert.Contains(t, r.errors[0], `unknown field "unknown"`)
+}
@@ -0,0 +1,63 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.`
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package validate
+
+import (
+	"context"
+
+	ecc "github.com/enterprise-contract/enterprise-contract-controller/api/v1alpha1"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/enterprise-contract/ec-cli/internal/evaluator"
+	"github.com/enterprise-contract/ec-cli/internal/policy"
+	"github.com/enterprise-contract/ec-cli/internal/policy/source"
+)
+
+// NewEvaluatorFunc creates the evaluator of a policy source group restricted
+// to the given namespaces, see evaluator.NewConftestEvaluatorWithNamespace.
+type NewEvaluatorFunc func(context.Context, []source.PolicySource, evaluator.ConfigProvider, ecc.Source, []string) (evaluator.Evaluator, error)
+
+// EvaluateNamespaces evaluates the policy rules in the given namespaces of each
+// policy source group against the input files.
+func EvaluateNamespaces(ctx context.Context, newEvaluator NewEvaluatorFunc, p policy.Policy, namespaces []string, inputs []string) ([]evaluator.Outcome, error) {
+	var results []evaluator.Outcome
+	for _, sourceGroup := range p.Spec().Sources {
+		policySources, err := source.FetchPolicySources(sourceGroup)
+		if err != nil {
+			log.Debugf("Failed to fetch policy source group '%s'!", sourceGroup.Name)
+			return nil, err
+		}
+
+		e, err := newEvaluator(ctx, policySources, p, sourceGroup, namespaces)
+		if err != nil {
+			log.Debug("Failed to initialize the conftest evaluator!")
+			return nil, err
+		}
+
+		outcomes, _, err := e.Evaluate(ctx, evaluator.EvaluationTarget{Inputs: inputs})
+		e.Destroy()
+		if err != nil {
+			log.Debug("Problem running conftest policy check!")
+			return nil, err
+		}
+		results = append(results, outcomes...)
+	}
+
+	log.Debug("Conftest policy check complete")
+
+	return results, nil
+}
@@ -18,17 +18,25 @@ package validate
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/afero"
 
+	"github.com/enterprise-contract/ec-cli/internal/policy/builtin"
 	"github.com/enterprise-contract/ec-cli/internal/policy/source"
 	"github.com/enterprise-contract/ec-cli/internal/utils"
 )
 
 // Determine policyConfig
 func GetPolicyConfig(ctx context.Context, policyConfiguration string) (string, error) {
+	// The embedded policy bundle is used with no network access
+	if policyConfiguration == builtin.Name {
+		log.Debug("Using the embedded policy bundle")
+		return builtin.PolicyConfig()
+	}
+
 	// If policyConfiguration is not detected as a file and is detected as a git URL,
 	// or if policyConfiguration is an https URL try to download a config file from
 	// the provided source. If successful we read its contents and return it.
@@ -57,6 +65,17 @@ func GetPolicyConfig(ctx context.Context, policyConfiguration string) (string, e
 		// we read its contents and return it.
 		log.Debugf("Loading %s as policy configuration", policyConfiguration)
 		return ReadFile(ctx, policyConfiguration)
+	} else if dir, ok := source.LocalPolicyDirectory(ctx, policyConfiguration); ok {
+		// If policyConfiguration is a local directory containing rego files, we
+		// use it as the only policy source, read in place from the disk.
+		log.Debugf("Using the local directory %s as policy source", dir)
+		config, err := json.Marshal(map[string]any{
+			"sources": []map[string]any{{"policy": []string{dir}}},
+		})
+		if err != nil {
+			return "", err
+		}
+		return string(config), nil
 	}
 
 	// If policyConfiguration is not a file path, git url, or https url,
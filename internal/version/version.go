@@ -25,6 +25,8 @@ import (
 	"time"
 
 	"github.com/hako/durafmt"
+
+	"github.com/enterprise-contract/ec-cli/internal/policy/builtin"
 )
 
 // Version of the `ec` CLI, set at build time to git id
@@ -88,6 +90,7 @@ func ComputeInfo() (*VersionInfo, error) {
 	info.Components = append(info.Components, dependencyVersion("Rekor", "github.com/sigstore/rekor", buildInfo.Deps))
 	info.Components = append(info.Components, dependencyVersion("Tekton Pipeline", "github.com/tektoncd/pipeline", buildInfo.Deps))
 	info.Components = append(info.Components, dependencyVersion("Kubernetes Client", "k8s.io/api", buildInfo.Deps))
+	info.Components = append(info.Components, ComponentInfo{Name: "Builtin Policy", Version: builtin.Version()})
 
 	return &info, nil
 }
@@ -89,6 +89,7 @@ func TestComputeInfo(t *testing.T) {
 			{Name: "Rekor", Version: "v6"},
 			{Name: "Tekton Pipeline", Version: "v7"},
 			{Name: "Kubernetes Client", Version: "v8"},
+			{Name: "Builtin Policy", Version: "N/A"},
 		},
 	}, vi)
 }